@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// capturer is a reverse proxy to the collector receiver recording the proxied requests
+// until either the maximum number of requests or bytes is recorded.
+type capturer struct {
+	start       time.Time
+	out         io.Writer
+	proxy       *httputil.ReverseProxy
+	now         func() time.Time
+	maxRequests int
+	maxBytes    int64
+	requests    int
+	bytes       int64
+	full        bool
+	mu          sync.Mutex
+}
+
+func newCapturer(target *url.URL, out io.Writer, maxRequests int, maxBytes int64) *capturer {
+	c := &capturer{
+		out:         out,
+		proxy:       httputil.NewSingleHostReverseProxy(target),
+		now:         time.Now,
+		maxRequests: maxRequests,
+		maxBytes:    maxBytes,
+	}
+	c.start = c.now()
+	return c
+}
+
+func (c *capturer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_ = r.Body.Close()
+	c.capture(r, body)
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	c.proxy.ServeHTTP(w, r)
+}
+
+// capture records the request if the limits aren't reached, returning whether it was recorded.
+func (c *capturer) capture(r *http.Request, body []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.requests >= c.maxRequests || c.bytes+int64(len(body)) > c.maxBytes {
+		if !c.full {
+			c.full = true
+			log.Printf("capture limit reached after %d requests and %d bytes, requests are still proxied", c.requests, c.bytes)
+		}
+		return false
+	}
+	line, err := json.Marshal(newRecord(r, body, c.now().Sub(c.start)))
+	if err != nil {
+		log.Printf("failed to record request: %v", err)
+		return false
+	}
+	if _, err = c.out.Write(append(line, '\n')); err != nil {
+		log.Printf("failed to record request: %v", err)
+		return false
+	}
+	c.requests++
+	c.bytes += int64(len(body))
+	return true
+}
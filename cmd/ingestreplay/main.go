@@ -0,0 +1,146 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program ingestreplay captures the requests sent to collector receivers and replays them,
+// to reproduce ingest issues locally.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+const usage = `Usage:
+  ingestreplay capture --listen <address> --target <receiver url> --output <dir> [--max-requests <n>] [--max-bytes <n>]
+  ingestreplay replay --input <dir> --target <receiver url> [--speed <factor>] [--header "<name>: <value>"]...
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	switch args[0] {
+	case "capture":
+		return runCapture(ctx, args[1:])
+	case "replay":
+		return runReplay(ctx, args[1:])
+	default:
+		return errors.New(usage)
+	}
+}
+
+func runCapture(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("capture", flag.ContinueOnError)
+	listen := flagSet.String("listen", "localhost:19292", "the address to receive the requests to capture on")
+	target := flagSet.String("target", "", "the url of the collector receiver the requests are proxied to, e.g. http://localhost:19291")
+	output := flagSet.String("output", "", "the directory to write the captured requests to")
+	maxRequests := flagSet.Int("max-requests", 1000, "the maximum number of requests to capture")
+	maxBytes := flagSet.Int64("max-bytes", 100<<20, "the maximum size of the captured request bodies")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if *target == "" || *output == "" {
+		return errors.New(usage)
+	}
+	targetURL, err := url.Parse(*target)
+	if err != nil {
+		return fmt.Errorf("invalid target: %w", err)
+	}
+
+	if err = os.MkdirAll(*output, 0o700); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(filepath.Join(*output, recordsFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           newCapturer(targetURL, out, *maxRequests, *maxBytes),
+		ReadHeaderTimeout: 20 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.Printf("capturing requests on %s proxied to %s in %s", *listen, *target, out.Name())
+	if err = server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func runReplay(ctx context.Context, args []string) error {
+	flagSet := flag.NewFlagSet("replay", flag.ContinueOnError)
+	input := flagSet.String("input", "", "the directory of the captured requests")
+	target := flagSet.String("target", "", "the url of the collector receiver to replay the requests to")
+	speed := flagSet.Float64("speed", 1, "the replay speed relative to the capture, 0 replays the requests as fast as possible")
+	headers := flagSet.StringArray("header", nil, `headers overriding the captured ones, e.g. to replace scrubbed tokens: "X-SF-Token: <token>"`)
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" || *target == "" || *speed < 0 {
+		return errors.New(usage)
+	}
+	targetURL, err := url.Parse(*target)
+	if err != nil {
+		return fmt.Errorf("invalid target: %w", err)
+	}
+	overrides := http.Header{}
+	for _, header := range *headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q, expected <name>: <value>", header)
+		}
+		overrides.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	in, err := os.Open(filepath.Join(*input, recordsFile))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	records, err := readRecords(in)
+	if err != nil {
+		return err
+	}
+
+	failed, err := newReplayer(targetURL, overrides, *speed).replay(ctx, records)
+	if err != nil {
+		return err
+	}
+	log.Printf("replayed %d requests to %s, %d failed", len(records), *target, failed)
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedRequest struct {
+	header http.Header
+	path   string
+	query  string
+	body   string
+}
+
+func newTestReceiver(t *testing.T) (*url.URL, func() []receivedRequest) {
+	var received []receivedRequest
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedRequest{header: r.Header, path: r.URL.Path, query: r.URL.RawQuery, body: string(body)})
+		mu.Unlock()
+		if r.URL.Path == "/rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return u, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
+
+func TestCaptureAndReplay(t *testing.T) {
+	collector, received := newTestReceiver(t)
+	var records bytes.Buffer
+	c := newCapturer(collector, &records, 2, 1024)
+	now := c.start
+	c.now = func() time.Time { return now }
+	proxy := httptest.NewServer(c)
+	defer proxy.Close()
+
+	send := func(path, body string) {
+		req, err := http.NewRequest(http.MethodPost, proxy.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("X-SF-Token", "secret")
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	send("/metrics?access_token=secret&source=test", "first")
+	now = now.Add(2 * time.Second)
+	send("/services/collector", "second")
+	send("/metrics", "not captured")
+
+	// all requests are proxied to the collector
+	require.Len(t, received(), 3)
+	assert.Equal(t, "first", received()[0].body)
+	assert.Equal(t, "secret", received()[0].header.Get("X-SF-Token"))
+
+	recs, err := readRecords(&records)
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+	assert.Equal(t, "/metrics", recs[0].Path)
+	assert.Equal(t, "access_token=%3Credacted%3E&source=test", recs[0].Query)
+	assert.Equal(t, []string{redacted}, recs[0].Headers["X-Sf-Token"])
+	assert.Equal(t, "application/x-protobuf", recs[0].Headers.Get("Content-Type"))
+	assert.Equal(t, []byte("first"), recs[0].Body)
+	assert.Equal(t, time.Duration(0), recs[0].Offset)
+	assert.Equal(t, 2*time.Second, recs[1].Offset)
+
+	replayTarget, replayed := newTestReceiver(t)
+	rp := newReplayer(replayTarget, http.Header{"X-Sf-Token": {"replay"}}, 2)
+	var slept []time.Duration
+	rp.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	recs = append(recs, record{Method: http.MethodPost, Path: "/rejected", Offset: 2 * time.Second})
+	failed, err := rp.replay(context.Background(), recs)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, []time.Duration{time.Second}, slept)
+
+	require.Len(t, replayed(), 3)
+	assert.Equal(t, "/services/collector", replayed()[1].path)
+	assert.Equal(t, "second", replayed()[1].body)
+	assert.Equal(t, "replay", replayed()[0].header.Get("X-SF-Token"))
+	assert.Equal(t, "access_token=%3Credacted%3E&source=test", replayed()[0].query)
+}
+
+func TestRunUsage(t *testing.T) {
+	assert.ErrorContains(t, run(nil), "Usage:")
+	assert.ErrorContains(t, run([]string{"unknown"}), "Usage:")
+	assert.ErrorContains(t, run([]string{"capture", "--target", "http://localhost:19291"}), "Usage:")
+	assert.ErrorContains(t, run([]string{"replay", "--input", t.TempDir(), "--target", "http://localhost:19291", "--header", "invalid"}), "invalid header")
+}
@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// recordsFile is the file of the capture directory holding the recorded requests, one json
+// object per line.
+const recordsFile = "requests.jsonl"
+
+const redacted = "<redacted>"
+
+// secretFragments identify the headers and query parameters whose values are scrubbed from records.
+var secretFragments = []string{"auth", "token", "key", "secret", "password", "cookie", "credential"}
+
+// record is a captured request.
+type record struct {
+	// Offset is the time since the start of the capture the request was received at.
+	Offset  time.Duration `json:"offset"`
+	Method  string        `json:"method"`
+	Path    string        `json:"path"`
+	Query   string        `json:"query,omitempty"`
+	Headers http.Header   `json:"headers"`
+	// Body is the raw, possibly compressed, body of the request.
+	Body []byte `json:"body"`
+}
+
+// newRecord returns the record of the request with the secrets of its headers and query scrubbed.
+func newRecord(r *http.Request, body []byte, offset time.Duration) record {
+	headers := http.Header{}
+	for name, values := range r.Header {
+		if isSecret(name) {
+			values = []string{redacted}
+		}
+		headers[name] = values
+	}
+	query := r.URL.Query()
+	for name := range query {
+		if isSecret(name) {
+			query.Set(name, redacted)
+		}
+	}
+	return record{
+		Offset:  offset,
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   query.Encode(),
+		Headers: headers,
+		Body:    body,
+	}
+}
+
+func isSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, fragment := range secretFragments {
+		if strings.Contains(name, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// request returns the request replaying the record to the target, with the overriding headers.
+func (rec record) request(target *url.URL, overrides http.Header) (*http.Request, error) {
+	u := *target
+	u.Path = strings.TrimSuffix(u.Path, "/") + rec.Path
+	u.RawQuery = rec.Query
+	req, err := http.NewRequest(rec.Method, u.String(), bytes.NewReader(rec.Body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range rec.Headers {
+		req.Header[name] = values
+	}
+	for name, values := range overrides {
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+func readRecords(r io.Reader) ([]record, error) {
+	var records []record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,88 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// replayer sends recorded requests to a collector receiver, preserving their relative
+// timing scaled by speed. A speed of zero sends them as fast as possible.
+type replayer struct {
+	client    *http.Client
+	target    *url.URL
+	overrides http.Header
+	sleep     func(context.Context, time.Duration) error
+	speed     float64
+}
+
+func newReplayer(target *url.URL, overrides http.Header, speed float64) *replayer {
+	return &replayer{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		target:    target,
+		overrides: overrides,
+		sleep:     sleep,
+		speed:     speed,
+	}
+}
+
+// replay sends the records, returning the number of requests that failed or weren't accepted.
+func (rp *replayer) replay(ctx context.Context, records []record) (int, error) {
+	var failed int
+	var elapsed time.Duration
+	for i, rec := range records {
+		if rp.speed > 0 && rec.Offset > elapsed {
+			wait := time.Duration(float64(rec.Offset-elapsed) / rp.speed)
+			if err := rp.sleep(ctx, wait); err != nil {
+				return failed, err
+			}
+			elapsed = rec.Offset
+		}
+		req, err := rec.request(rp.target, rp.overrides)
+		if err != nil {
+			return failed, fmt.Errorf("invalid record %d: %w", i+1, err)
+		}
+		resp, err := rp.client.Do(req.WithContext(ctx))
+		if err != nil {
+			failed++
+			log.Printf("request %d %s %s failed: %v", i+1, rec.Method, rec.Path, err)
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			failed++
+			log.Printf("request %d %s %s returned %s", i+1, rec.Method, rec.Path, resp.Status)
+		}
+	}
+	return failed, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	knownFilesKey = "file_input.knownFiles"
+	// missingSinceKey stores when the files of checkpoints were first found missing
+	// so that their checkpoints can be pruned once the retention period elapses.
+	missingSinceKey = "migratecheckpoint.missingSince"
+	filePathAttr    = "log.file.path"
+)
+
+// Compactor prunes the checkpoints of files that have been deleted or replaced for
+// longer than the retention period from file_storage DBs and compacts them.
+type Compactor struct {
+	now func() time.Time
+	// CheckpointPath is a glob of the file_storage DBs to compact.
+	CheckpointPath string
+	Retention      time.Duration
+	// DryRun reports what would be pruned without modifying the DBs.
+	DryRun bool
+}
+
+// CompactionReport describes the checkpoints of a single DB.
+type CompactionReport struct {
+	DB string
+	// Pruned are the paths of files whose checkpoints were (or, in dry-run mode, would be) removed.
+	Pruned []string
+	// Retained are the paths of missing files whose checkpoints are kept until the retention period elapses.
+	Retained []string
+	Kept     int
+}
+
+func (r CompactionReport) String() string {
+	return fmt.Sprintf("%s: %d checkpoints kept, %d pruned, %d of missing files retained",
+		r.DB, r.Kept, len(r.Pruned), len(r.Retained))
+}
+
+// Run compacts every DB matched by CheckpointPath, logging a report for each of them.
+func (c *Compactor) Run() error {
+	matches, err := filepath.Glob(c.CheckpointPath)
+	if err != nil {
+		return err
+	}
+	var errs error
+	for _, match := range matches {
+		report, err := c.CompactDB(match)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", match, err))
+			continue
+		}
+		prefix := ""
+		if c.DryRun {
+			prefix = "[dry-run] "
+		}
+		log.Printf("%s%s", prefix, report)
+		for _, path := range report.Pruned {
+			log.Printf("%s  pruned %s", prefix, path)
+		}
+	}
+	return errs
+}
+
+// CompactDB prunes the checkpoints of the DB at path and compacts it.
+func (c *Compactor) CompactDB(path string) (CompactionReport, error) {
+	report := CompactionReport{DB: path}
+	client, err := newClient(path, time.Second)
+	if err != nil {
+		return report, err
+	}
+
+	kept, pruned, missingSince, err := c.prune(client, &report)
+	if err != nil || c.DryRun || len(pruned) == 0 && missingSince == nil {
+		client.Close()
+		return report, err
+	}
+
+	var knownFiles bytes.Buffer
+	enc := json.NewEncoder(&knownFiles)
+	if err = enc.Encode(len(kept)); err != nil {
+		client.Close()
+		return report, err
+	}
+	for _, checkpoint := range kept {
+		if err = enc.Encode(checkpoint); err != nil {
+			client.Close()
+			return report, err
+		}
+	}
+	missingSinceJSON, err := json.Marshal(missingSince)
+	if err != nil {
+		client.Close()
+		return report, err
+	}
+	err = client.Batch(SetOperation(knownFilesKey, knownFiles.Bytes()), SetOperation(missingSinceKey, missingSinceJSON))
+	if closeErr := client.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || len(pruned) == 0 {
+		return report, err
+	}
+	// bbolt doesn't release the pages of deleted data so the DB must be rewritten to shrink
+	return report, compactFile(path)
+}
+
+// prune splits the known files checkpoints into those to keep and those to prune, returning
+// the updated missing since times. Checkpoints without a file path are always kept.
+func (c *Compactor) prune(client *fileStorageClient, report *CompactionReport) (kept, pruned []json.RawMessage, missingSince map[string]time.Time, err error) {
+	knownFiles, err := client.Get(knownFilesKey)
+	if err != nil || knownFiles == nil {
+		return nil, nil, nil, err
+	}
+	checkpoints, err := decodeKnownFiles(knownFiles)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("decoding %s: %w", knownFilesKey, err)
+	}
+
+	previous := map[string]time.Time{}
+	if missingSinceJSON, _ := client.Get(missingSinceKey); missingSinceJSON != nil {
+		if err = json.Unmarshal(missingSinceJSON, &previous); err != nil {
+			return nil, nil, nil, fmt.Errorf("decoding %s: %w", missingSinceKey, err)
+		}
+	}
+
+	now := c.now()
+	missingSince = map[string]time.Time{}
+	for _, checkpoint := range checkpoints {
+		var reader Reader
+		if err = json.Unmarshal(checkpoint, &reader); err != nil {
+			return nil, nil, nil, fmt.Errorf("decoding checkpoint: %w", err)
+		}
+		path, ok := reader.FileAttributes[filePathAttr].(string)
+		if !ok || fileMatches(path, reader.Fingerprint) {
+			kept = append(kept, checkpoint)
+			continue
+		}
+		since, ok := previous[path]
+		if !ok {
+			since = now
+		}
+		if now.Sub(since) >= c.Retention {
+			pruned = append(pruned, checkpoint)
+			report.Pruned = append(report.Pruned, path)
+			continue
+		}
+		missingSince[path] = since
+		kept = append(kept, checkpoint)
+		report.Retained = append(report.Retained, path)
+	}
+	report.Kept = len(kept)
+	sort.Strings(report.Pruned)
+	sort.Strings(report.Retained)
+	return kept, pruned, missingSince, nil
+}
+
+// decodeKnownFiles decodes the known files checkpoint, a json encoded count followed by
+// that many json encoded readers. The readers are left encoded so any fields unknown to
+// this program are preserved.
+func decodeKnownFiles(knownFiles []byte) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(knownFiles))
+	var count int
+	if err := dec.Decode(&count); err != nil {
+		return nil, err
+	}
+	checkpoints := make([]json.RawMessage, 0, count)
+	for i := 0; i < count; i++ {
+		var checkpoint json.RawMessage
+		if err := dec.Decode(&checkpoint); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, nil
+}
+
+// fileMatches returns whether the file at path still exists and starts with the fingerprint,
+// i.e. hasn't been deleted or replaced by another file.
+func fileMatches(path string, fp *Fingerprint) bool {
+	current, err := getFingerPrint(path)
+	if err != nil {
+		return false
+	}
+	return fp == nil || bytes.HasPrefix(current.FirstBytes, fp.FirstBytes)
+}
+
+// compactFile rewrites the DB at path without its free pages.
+func compactFile(path string) error {
+	tmpPath := path + ".compacting"
+	src, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	dst, err := bbolt.Open(tmpPath, 0600, &bbolt.Options{Timeout: time.Second, NoSync: true})
+	if err != nil {
+		src.Close()
+		return err
+	}
+	err = bbolt.Compact(dst, src, 0)
+	src.Close()
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func runCompaction() {
+	retentionDays, err := parseRetentionDays(getEnv("COMPACT_RETENTION_DAYS", "7"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	compactor := &Compactor{
+		now:            time.Now,
+		CheckpointPath: getEnv("COMPACT_CHECKPOINT_PATH", "/var/lib/otel_pos/receiver_filelog_*"),
+		Retention:      time.Duration(retentionDays) * 24 * time.Hour,
+		DryRun:         getEnv("COMPACT_DRY_RUN", "false") == "true",
+	}
+	if err = compactor.Run(); err != nil {
+		log.Fatalf("error compacting checkpoints: %v", err)
+	}
+	log.Println("Checkpoint compaction completed")
+}
+
+func parseRetentionDays(value string) (int, error) {
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("COMPACT_RETENTION_DAYS must be a non-negative number of days, got %q", value)
+	}
+	return days, nil
+}
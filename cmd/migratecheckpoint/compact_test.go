@@ -0,0 +1,136 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCheckpointDB(t *testing.T) string {
+	existing, err := getFingerPrint(filepath.Join("testdata", "loggen.log"))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "receiver_filelog_")
+	client, err := newClient(path, time.Second)
+	require.NoError(t, err)
+	buf := syncLastPollFiles([]*Reader{
+		{
+			Fingerprint:    existing,
+			FileAttributes: map[string]any{filePathAttr: filepath.Join("testdata", "loggen.log")},
+			Offset:         10,
+		},
+		{
+			// the fingerprint of a file since replaced by another one
+			Fingerprint:    &Fingerprint{FirstBytes: []byte("rotated")},
+			FileAttributes: map[string]any{filePathAttr: filepath.Join("testdata", "calico_node.log")},
+			Offset:         20,
+		},
+		{
+			Fingerprint:    &Fingerprint{FirstBytes: []byte("deleted")},
+			FileAttributes: map[string]any{filePathAttr: filepath.Join("testdata", "deleted.log")},
+			Offset:         30,
+		},
+		{
+			Fingerprint:    &Fingerprint{FirstBytes: []byte("unknown path")},
+			FileAttributes: map[string]any{},
+			Offset:         40,
+		},
+	})
+	require.NoError(t, client.Set(knownFilesKey, buf.Bytes()))
+	require.NoError(t, client.Close())
+	return path
+}
+
+func knownFileOffsets(t *testing.T, path string) []int64 {
+	client, err := newClient(path, time.Second)
+	require.NoError(t, err)
+	defer client.Close()
+	knownFiles, err := client.Get(knownFilesKey)
+	require.NoError(t, err)
+	checkpoints, err := decodeKnownFiles(knownFiles)
+	require.NoError(t, err)
+	var offsets []int64
+	for _, checkpoint := range checkpoints {
+		var reader Reader
+		require.NoError(t, json.Unmarshal(checkpoint, &reader))
+		offsets = append(offsets, reader.Offset)
+	}
+	return offsets
+}
+
+func TestCompactDBWithoutRetention(t *testing.T) {
+	path := newTestCheckpointDB(t)
+	compactor := &Compactor{now: time.Now}
+
+	report, err := compactor.CompactDB(path)
+	require.NoError(t, err)
+	assert.Equal(t, CompactionReport{
+		DB:     path,
+		Pruned: []string{filepath.Join("testdata", "calico_node.log"), filepath.Join("testdata", "deleted.log")},
+		Kept:   2,
+	}, report)
+	assert.Equal(t, []int64{10, 40}, knownFileOffsets(t, path))
+}
+
+func TestCompactDBRetention(t *testing.T) {
+	path := newTestCheckpointDB(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	compactor := &Compactor{now: func() time.Time { return now }, Retention: 7 * 24 * time.Hour}
+
+	report, err := compactor.CompactDB(path)
+	require.NoError(t, err)
+	assert.Empty(t, report.Pruned)
+	assert.Len(t, report.Retained, 2)
+	assert.Equal(t, 4, report.Kept)
+
+	// the missing since times are persisted across runs
+	now = now.Add(6 * 24 * time.Hour)
+	report, err = compactor.CompactDB(path)
+	require.NoError(t, err)
+	assert.Empty(t, report.Pruned)
+	assert.Equal(t, []int64{10, 20, 30, 40}, knownFileOffsets(t, path))
+
+	now = now.Add(24 * time.Hour)
+	report, err = compactor.CompactDB(path)
+	require.NoError(t, err)
+	assert.Len(t, report.Pruned, 2)
+	assert.Empty(t, report.Retained)
+	assert.Equal(t, []int64{10, 40}, knownFileOffsets(t, path))
+}
+
+func TestCompactDBDryRun(t *testing.T) {
+	path := newTestCheckpointDB(t)
+	compactor := &Compactor{now: time.Now, DryRun: true}
+
+	report, err := compactor.CompactDB(path)
+	require.NoError(t, err)
+	assert.Len(t, report.Pruned, 2)
+	assert.Equal(t, []int64{10, 20, 30, 40}, knownFileOffsets(t, path))
+}
+
+func TestParseRetentionDays(t *testing.T) {
+	days, err := parseRetentionDays("30")
+	require.NoError(t, err)
+	assert.Equal(t, 30, days)
+
+	_, err = parseRetentionDays("-1")
+	require.EqualError(t, err, `COMPACT_RETENTION_DAYS must be a non-negative number of days, got "-1"`)
+}
@@ -41,6 +41,13 @@ type operation struct {
 
 type Operation *operation
 
+func GetOperation(key string) Operation {
+	return &operation{
+		Key:  key,
+		Type: Get,
+	}
+}
+
 func SetOperation(key string, value []byte) Operation {
 	return &operation{
 		Key:   key,
@@ -76,6 +83,15 @@ func newClient(filePath string, timeout time.Duration) (*fileStorageClient, erro
 	return &fileStorageClient{db}, nil
 }
 
+// Get will retrieve data from storage that corresponds to the specified key
+func (c *fileStorageClient) Get(key string) ([]byte, error) {
+	op := GetOperation(key)
+	if err := c.Batch(op); err != nil {
+		return nil, err
+	}
+	return op.Value, nil
+}
+
 // Set will store data. The data can be retrieved using the same key
 func (c *fileStorageClient) Set(key string, value []byte) error {
 	return c.Batch(SetOperation(key, value))
@@ -93,7 +109,11 @@ func (c *fileStorageClient) Batch(ops ...Operation) error {
 		for _, op := range ops {
 			switch op.Type {
 			case Get:
-				op.Value = bucket.Get([]byte(op.Key))
+				// the output of Bucket.Get is only valid within a transaction, so it must be copied
+				if value := bucket.Get([]byte(op.Key)); value != nil {
+					op.Value = make([]byte, len(value))
+					copy(op.Value, value)
+				}
 			case Set:
 				err = bucket.Put([]byte(op.Key), op.Value)
 			case Delete:
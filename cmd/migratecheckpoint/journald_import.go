@@ -0,0 +1,147 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	journaldCursorKey = "journald_input.lastReadCursor"
+
+	cursorFormatFluentd = "fluentd"
+	cursorFormatText    = "text"
+)
+
+// cursorFields are the fields of the cursors journalctl and sd_journal_get_cursor return.
+var cursorFields = []string{"s", "i", "b", "m", "t", "x"}
+
+// JournaldImporter imports the journald cursor saved by another log agent into the
+// file_storage DB of a journald receiver, so the receiver resumes where the agent stopped.
+type JournaldImporter struct {
+	// CursorPath is the file the agent saved its cursor to.
+	CursorPath string
+	// Format is the format of the cursor file, fluentd or text, detected from its content if empty.
+	Format string
+	// CheckpointPath is the file_storage DB of the journald receiver.
+	CheckpointPath string
+	// Overwrite replaces a cursor the receiver already saved.
+	Overwrite bool
+}
+
+// Run imports the cursor, and returns whether it was imported.
+func (i *JournaldImporter) Run() (bool, error) {
+	content, err := os.ReadFile(i.CursorPath)
+	if err != nil {
+		return false, err
+	}
+	cursor, err := parseCursorFile(content, i.Format)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", i.CursorPath, err)
+	}
+
+	client, err := newClient(i.CheckpointPath, time.Second)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+	if !i.Overwrite {
+		existing, err := client.Get(journaldCursorKey)
+		if err != nil {
+			return false, err
+		}
+		if len(existing) > 0 {
+			return false, nil
+		}
+	}
+	return true, client.Set(journaldCursorKey, []byte(cursor))
+}
+
+// parseCursorFile returns the cursor of the JSON pos file of Fluentd's systemd input, or of a
+// text file only holding the cursor, like the checkpoint.txt file of Vector's journald source.
+func parseCursorFile(content []byte, format string) (string, error) {
+	content = bytes.TrimSpace(content)
+	if format == "" {
+		format = cursorFormatText
+		if bytes.HasPrefix(content, []byte("{")) {
+			format = cursorFormatFluentd
+		}
+	}
+
+	var cursor string
+	switch format {
+	case cursorFormatFluentd:
+		var pos journaldCursor
+		if err := json.Unmarshal(content, &pos); err != nil {
+			return "", fmt.Errorf("invalid fluentd pos file: %w", err)
+		}
+		cursor = pos.Cursor
+	case cursorFormatText:
+		cursor = string(content)
+	default:
+		return "", fmt.Errorf("unsupported cursor format %q, must be %s or %s", format, cursorFormatFluentd, cursorFormatText)
+	}
+	return cursor, validateCursor(cursor)
+}
+
+// validateCursor checks that the cursor has the fields of journald cursors, so a corrupted
+// file isn't passed to journalctl, which fails to start with an invalid cursor.
+func validateCursor(cursor string) error {
+	if cursor == "" {
+		return errors.New("cursor is empty")
+	}
+	fields := map[string]bool{}
+	for _, field := range strings.Split(cursor, ";") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return fmt.Errorf("invalid cursor %q", cursor)
+		}
+		fields[key] = true
+	}
+	for _, field := range cursorFields {
+		if !fields[field] {
+			return fmt.Errorf("invalid cursor %q: %s field is missing", cursor, field)
+		}
+	}
+	return nil
+}
+
+func runJournaldImport() {
+	importer := &JournaldImporter{
+		CursorPath:     getEnv("JOURNALD_IMPORT_CURSOR_PATH", ""),
+		Format:         getEnv("JOURNALD_IMPORT_FORMAT", ""),
+		CheckpointPath: getEnv("JOURNALD_IMPORT_CHECKPOINT_PATH", "/var/lib/otel_pos/receiver_journald_"),
+		Overwrite:      getEnv("JOURNALD_IMPORT_OVERWRITE", "false") == "true",
+	}
+	if importer.CursorPath == "" {
+		log.Fatal("JOURNALD_IMPORT_CURSOR_PATH must be set")
+	}
+	imported, err := importer.Run()
+	if err != nil {
+		log.Fatalf("error importing journald cursor: %v", err)
+	}
+	if !imported {
+		log.Printf("%s already has a journald cursor, not importing %s", importer.CheckpointPath, importer.CursorPath)
+		return
+	}
+	log.Println("Journald cursor import completed")
+}
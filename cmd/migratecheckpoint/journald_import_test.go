@@ -0,0 +1,106 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCursor = "s=739ad463348b4ceca5a9e69c95a3c93f;i=4ece7;b=6c7c6013a8f34e0a9a7e4b1ff1d3c1d0;m=264f67f7e;t=5e9b2c0fd5b5a;x=3bcd3a2c2e1c4f4d"
+
+func storedCursor(t *testing.T, path string) string {
+	client, err := newClient(path, time.Second)
+	require.NoError(t, err)
+	defer client.Close()
+	cursor, err := client.Get(journaldCursorKey)
+	require.NoError(t, err)
+	return string(cursor)
+}
+
+func TestJournaldImport(t *testing.T) {
+	dir := t.TempDir()
+	fluentdPos := filepath.Join(dir, "journald.pos.json")
+	require.NoError(t, os.WriteFile(fluentdPos, []byte(`{"journal":"`+testCursor+`"}`), 0600))
+	vectorCheckpoint := filepath.Join(dir, "checkpoint.txt")
+	require.NoError(t, os.WriteFile(vectorCheckpoint, []byte("s=0;i=1;b=2;m=3;t=4;x=5\n"), 0600))
+	checkpointPath := filepath.Join(dir, "receiver_journald_")
+
+	importer := &JournaldImporter{CursorPath: fluentdPos, CheckpointPath: checkpointPath}
+	imported, err := importer.Run()
+	require.NoError(t, err)
+	assert.True(t, imported)
+	assert.Equal(t, testCursor, storedCursor(t, checkpointPath))
+
+	// the cursor saved by the receiver is kept unless overwritten
+	importer.CursorPath = vectorCheckpoint
+	imported, err = importer.Run()
+	require.NoError(t, err)
+	assert.False(t, imported)
+	assert.Equal(t, testCursor, storedCursor(t, checkpointPath))
+
+	importer.Overwrite = true
+	imported, err = importer.Run()
+	require.NoError(t, err)
+	assert.True(t, imported)
+	assert.Equal(t, "s=0;i=1;b=2;m=3;t=4;x=5", storedCursor(t, checkpointPath))
+}
+
+func TestParseCursorFile(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		content     string
+		format      string
+		expectedErr string
+	}{
+		{name: "fluentd", content: `{"journal":"` + testCursor + `"}`},
+		{name: "fluentd format", content: `{"journal":"` + testCursor + `"}`, format: cursorFormatFluentd},
+		{name: "text", content: testCursor + "\n"},
+		{name: "text format", content: testCursor, format: cursorFormatText},
+		{
+			name:        "invalid json",
+			content:     `{"journal":`,
+			expectedErr: "invalid fluentd pos file: unexpected end of JSON input",
+		},
+		{name: "empty", content: `{}`, expectedErr: "cursor is empty"},
+		{name: "not a cursor", content: "latest", expectedErr: `invalid cursor "latest"`},
+		{
+			name:        "missing field",
+			content:     "s=0;i=1;b=2;m=3;t=4",
+			expectedErr: `invalid cursor "s=0;i=1;b=2;m=3;t=4": x field is missing`,
+		},
+		{
+			name:        "unsupported format",
+			content:     testCursor,
+			format:      "vector",
+			expectedErr: `unsupported cursor format "vector", must be fluentd or text`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := parseCursorFile([]byte(tt.content), tt.format)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCursor, cursor)
+		})
+	}
+}
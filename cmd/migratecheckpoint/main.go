@@ -151,6 +151,15 @@ func (m *Migrator) MigrateJournaldPos(matches []string) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "compact" {
+		runCompaction()
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-journald" {
+		runJournaldImport()
+		return
+	}
+
 	containerLogPathFluentd := getEnv("CONTAINER_LOG_PATH_FLUENTD", "/var/log/splunk-fluentd-containers.log.pos")
 	containerLogPathOtel := getEnv("CONTAINER_LOG_PATH_OTEL", "/var/lib/otel_pos/receiver_filelog_")
 
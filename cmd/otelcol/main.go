@@ -29,9 +29,13 @@ import (
 	"go.opentelemetry.io/collector/otelcol"
 	"go.uber.org/zap"
 
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
 	"github.com/signalfx/splunk-otel-collector/internal/components"
+	"github.com/signalfx/splunk-otel-collector/internal/configcmd"
 	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
 	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
+	"github.com/signalfx/splunk-otel-collector/internal/loglevel"
+	"github.com/signalfx/splunk-otel-collector/internal/logrotation"
 	"github.com/signalfx/splunk-otel-collector/internal/settings"
 	"github.com/signalfx/splunk-otel-collector/internal/version"
 )
@@ -44,6 +48,31 @@ func runFromCmdLine(args []string) {
 	// TODO: Use same format as the collector
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 
+	if len(args) > 1 && args[1] == configcmd.Command {
+		if err := configcmd.Run(args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 1 && args[1] == verifyBundleCommand {
+		if err := runVerifyBundle(args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 1 && args[1] == replayDeadLettersCommand {
+		if err := runReplayDeadLetters(args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 1 && args[1] == testReceiverCommand {
+		if err := runTestReceiver(args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	collectorSettings, err := settings.New(args[1:])
 	if err != nil {
 		// Exit if --help flag was supplied and usage help was displayed.
@@ -58,7 +87,15 @@ func runFromCmdLine(args []string) {
 		Version: version.Version,
 	}
 
+	if err = logrotation.Register(); err != nil {
+		log.Fatalf("failed registering the rotating log file sink: %v", err)
+	}
+
 	configServer := configconverter.NewConfigServer()
+	logLevels := loglevel.New(os.Getenv(loglevel.ChangesEnabledEnvVar) == "true")
+	configServer.Handle(loglevel.Path, logLevels)
+	admin.Register(configServer.AdminRoutes()...)
+	admin.Register(admin.Route{Pattern: loglevel.Path, Operation: admin.OperationLogLevel, Handler: logLevels.AdminHandler()})
 
 	confMapConverterFactories := collectorSettings.ConfMapConverterFactories()
 	dryRun := configconverter.NewDryRun(collectorSettings.IsDryRun(), confMapConverterFactories)
@@ -74,8 +111,9 @@ func runFromCmdLine(args []string) {
 	}
 
 	serviceSettings := otelcol.CollectorSettings{
-		BuildInfo: info,
-		Factories: components.Get,
+		BuildInfo:      info,
+		Factories:      components.Get,
+		LoggingOptions: []zap.Option{logLevels.WrapCore()},
 		ConfigProviderSettings: otelcol.ConfigProviderSettings{
 			ResolverSettings: confmap.ResolverSettings{
 				URIs:               collectorSettings.ResolverURIs(),
@@ -0,0 +1,159 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/signalfx/splunk-otel-collector/internal/connector/deadletterconnector"
+)
+
+const replayDeadLettersCommand = "replay-dead-letters"
+
+// runReplayDeadLetters sends the batches of the dead letter files of a deadletter connector to an OTLP/HTTP
+// receiver, e.g. once the destination the batches failed to be exported to is reachable again. Replayed
+// batches are removed from the files unless --keep is set, so the batches that failed again can be replayed
+// later.
+func runReplayDeadLetters(args []string, out io.Writer) error {
+	flagSet := flag.NewFlagSet(replayDeadLettersCommand, flag.ContinueOnError)
+	dir := flagSet.String("directory", "", "the dead letter directory of the deadletter connector")
+	endpoint := flagSet.String("endpoint", "http://localhost:4318", "the OTLP/HTTP endpoint to send the batches to")
+	headers := flagSet.StringArray("header", nil, "a header of the requests, e.g. X-SF-Token=<token>, can be repeated")
+	keep := flagSet.Bool("keep", false, "keep the replayed batches in the dead letter files")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("--directory must be specified")
+	}
+	header := http.Header{}
+	for _, h := range *headers {
+		name, value, ok := strings.Cut(h, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid header %q, expected <name>=<value>", h)
+		}
+		header.Add(name, value)
+	}
+
+	files, err := deadletterconnector.ListFiles(*dir)
+	if err != nil {
+		return err
+	}
+	r := &deadLetterReplayer{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		endpoint: strings.TrimSuffix(*endpoint, "/"),
+		header:   header,
+		keep:     *keep,
+	}
+	var replayed, failed int
+	for _, f := range files {
+		fileReplayed, fileFailed, err := r.replayFile(context.Background(), f)
+		if err != nil {
+			return fmt.Errorf("failed to replay %q: %w", f.Path, err)
+		}
+		replayed += fileReplayed
+		failed += fileFailed
+	}
+	if _, err = fmt.Fprintf(out, "replayed %d batches from %d files\n", replayed, len(files)); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d batches failed to replay", failed)
+	}
+	return nil
+}
+
+type deadLetterReplayer struct {
+	client   *http.Client
+	header   http.Header
+	endpoint string
+	keep     bool
+}
+
+// replayFile sends the batches of a file, and rewrites it with the batches that failed, or removes it if none did.
+func (r *deadLetterReplayer) replayFile(ctx context.Context, f deadletterconnector.File) (int, int, error) {
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var replayed int
+	var failedBatches [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for scanner.Scan() {
+		batch := scanner.Bytes()
+		if len(batch) == 0 {
+			continue
+		}
+		if err = r.send(ctx, f.Signal, batch); err != nil {
+			log.Printf("%s: %v", f.Path, err)
+			failedBatches = append(failedBatches, batch)
+			continue
+		}
+		replayed++
+	}
+	if err = scanner.Err(); err != nil {
+		return replayed, len(failedBatches), err
+	}
+	switch {
+	case r.keep:
+	case len(failedBatches) == 0:
+		err = os.Remove(f.Path)
+	case replayed > 0:
+		err = writeFileAtomically(f.Path, append(bytes.Join(failedBatches, []byte{'\n'}), '\n'))
+	}
+	return replayed, len(failedBatches), err
+}
+
+func (r *deadLetterReplayer) send(ctx context.Context, signal string, batch []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint+"/v1/"+signal, bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL, resp.Status)
+	}
+	return nil
+}
+
+func writeFileAtomically(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,89 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunReplayDeadLetters(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "token", r.Header.Get("X-SF-Token"))
+		if strings.Contains(string(body), "rejected") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, r.URL.Path+" "+string(body))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	logsFile := filepath.Join(dir, "logs-1705752000000000000.json")
+	tracesFile := filepath.Join(dir, "traces-1705751000000000000.json")
+	require.NoError(t, os.WriteFile(logsFile, []byte("{\"log\":1}\n{\"rejected\":2}\n{\"log\":3}\n"), 0o600))
+	require.NoError(t, os.WriteFile(tracesFile, []byte("{\"span\":1}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated.json"), []byte("{}\n"), 0o600))
+
+	var out bytes.Buffer
+	args := []string{"--directory", dir, "--endpoint", server.URL, "--header", "X-SF-Token=token"}
+	require.EqualError(t, runReplayDeadLetters(args, &out), "1 batches failed to replay")
+	assert.Equal(t, "replayed 3 batches from 2 files\n", out.String())
+	assert.Equal(t, []string{
+		`/v1/traces {"span":1}`,
+		`/v1/logs {"log":1}`,
+		`/v1/logs {"log":3}`,
+	}, received)
+
+	// the replayed batches are removed, and the failed ones kept
+	assert.NoFileExists(t, tracesFile)
+	content, err := os.ReadFile(logsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"rejected\":2}\n", string(content))
+	assert.FileExists(t, filepath.Join(dir, "unrelated.json"))
+}
+
+func TestRunReplayDeadLettersKeep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	metricsFile := filepath.Join(dir, "metrics-1705752000000000000.json")
+	require.NoError(t, os.WriteFile(metricsFile, []byte("{\"metric\":1}\n"), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, runReplayDeadLetters([]string{"--directory", dir, "--endpoint", server.URL, "--keep"}, &out))
+	assert.Equal(t, "replayed 1 batches from 1 files\n", out.String())
+	assert.FileExists(t, metricsFile)
+}
+
+func TestRunReplayDeadLettersInvalidArgs(t *testing.T) {
+	var out bytes.Buffer
+	require.EqualError(t, runReplayDeadLetters(nil, &out), "--directory must be specified")
+	require.EqualError(t, runReplayDeadLetters([]string{"--directory", t.TempDir(), "--header", "token"}, &out),
+		`invalid header "token", expected <name>=<value>`)
+}
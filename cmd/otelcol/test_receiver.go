@@ -0,0 +1,283 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	flag "github.com/spf13/pflag"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/metric"
+	mnoop "go.opentelemetry.io/otel/metric/noop"
+	tnoop "go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/signalfx/splunk-otel-collector/internal/components"
+	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
+	"github.com/signalfx/splunk-otel-collector/internal/settings"
+	"github.com/signalfx/splunk-otel-collector/internal/version"
+)
+
+const testReceiverCommand = "test-receiver"
+
+// runTestReceiver runs a single receiver of a config, without its pipelines, printing the batches it emits
+// as OTLP JSON lines until the duration elapses or the command is interrupted. This allows checking the
+// credentials and the reachability of the targets of a receiver without running the collector.
+func runTestReceiver(args []string, out io.Writer) error {
+	flagSet := flag.NewFlagSet(testReceiverCommand, flag.ContinueOnError)
+	configs := flagSet.StringArray("config", nil, "a config file or uri, as for the collector, can be repeated")
+	sets := flagSet.StringArray("set", nil, "a config property to set, as for the collector, can be repeated")
+	receiverID := flagSet.String("receiver", "", "the id of the receiver to run, e.g. smartagent/postgresql")
+	duration := flagSet.Duration("duration", 30*time.Second, "the duration to run the receiver for")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	var id component.ID
+	if err := id.UnmarshalText([]byte(*receiverID)); err != nil {
+		return fmt.Errorf("invalid --receiver %q: %w", *receiverID, err)
+	}
+	if *duration <= 0 {
+		return errors.New("--duration must be positive")
+	}
+
+	conf, err := resolveTestReceiverConfig(*configs, *sets)
+	if err != nil {
+		return err
+	}
+	factories, err := components.Get()
+	if err != nil {
+		return err
+	}
+	factory, ok := factories.Receivers[id.Type()]
+	if !ok {
+		return fmt.Errorf("unknown receiver type %q", id.Type())
+	}
+
+	logger, err := newTestReceiverLogger()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = logger.Sync() }()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ctx, cancel = context.WithTimeout(ctx, *duration)
+	defer cancel()
+	return newReceiverTester(out, logger).run(ctx, factory, id, conf)
+}
+
+// resolveTestReceiverConfig resolves the config the same way the collector does, with its config sources
+// and converters.
+func resolveTestReceiverConfig(configs, sets []string) (*confmap.Conf, error) {
+	var args []string
+	for _, c := range configs {
+		args = append(args, "--config", c)
+	}
+	for _, s := range sets {
+		args = append(args, "--set", s)
+	}
+	collectorSettings, err := settings.New(args)
+	if err != nil {
+		return nil, err
+	}
+
+	configSourceProvider := configsource.New(zap.NewNop(), nil)
+	var providerFactories []confmap.ProviderFactory
+	for _, pf := range collectorSettings.ConfMapProviderFactories() {
+		providerFactories = append(providerFactories, configSourceProvider.Wrap(pf))
+	}
+	resolver, err := confmap.NewResolver(confmap.ResolverSettings{
+		URIs:               collectorSettings.ResolverURIs(),
+		ProviderFactories:  providerFactories,
+		ConverterFactories: collectorSettings.ConfMapConverterFactories(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	conf, err := resolver.Resolve(context.Background())
+	return conf, multierr.Append(err, resolver.Shutdown(context.Background()))
+}
+
+func newTestReceiverLogger() (*zap.Logger, error) {
+	cfg := zap.NewProductionConfig()
+	cfg.Encoding = "console"
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	return cfg.Build()
+}
+
+// receiverTester runs a receiver, writing the batches it emits to out.
+type receiverTester struct {
+	out        io.Writer
+	logger     *zap.Logger
+	writeErr   error
+	mu         sync.Mutex
+	dataPoints int
+	spans      int
+	logRecords int
+}
+
+func newReceiverTester(out io.Writer, logger *zap.Logger) *receiverTester {
+	return &receiverTester{out: out, logger: logger}
+}
+
+// run creates the receiver of each signal its type supports from its config, and runs them until the
+// context is done.
+func (t *receiverTester) run(ctx context.Context, factory receiver.Factory, id component.ID, conf *confmap.Conf) error {
+	cfg, err := receiverConfig(factory, id, conf)
+	if err != nil {
+		return err
+	}
+	set := receiver.Settings{
+		ID: id,
+		TelemetrySettings: component.TelemetrySettings{
+			Logger:               t.logger.With(zap.String("kind", "receiver"), zap.String("name", id.String())),
+			TracerProvider:       tnoop.NewTracerProvider(),
+			LeveledMeterProvider: func(configtelemetry.Level) metric.MeterProvider { return mnoop.NewMeterProvider() },
+		},
+		BuildInfo: component.BuildInfo{Command: "otelcol", Version: version.Version},
+	}
+
+	var receivers []component.Component
+	create := func(rcv component.Component, err error) error {
+		if errors.Is(err, pipeline.ErrSignalNotSupported) {
+			return nil
+		}
+		if err == nil {
+			receivers = append(receivers, rcv)
+		}
+		return err
+	}
+	if factory.TracesStability() != component.StabilityLevelUndefined {
+		next, _ := consumer.NewTraces(t.consumeTraces)
+		if err = create(factory.CreateTraces(ctx, set, cfg, next)); err != nil {
+			return fmt.Errorf("failed to create the traces receiver: %w", err)
+		}
+	}
+	if factory.MetricsStability() != component.StabilityLevelUndefined {
+		next, _ := consumer.NewMetrics(t.consumeMetrics)
+		if err = create(factory.CreateMetrics(ctx, set, cfg, next)); err != nil {
+			return fmt.Errorf("failed to create the metrics receiver: %w", err)
+		}
+	}
+	if factory.LogsStability() != component.StabilityLevelUndefined {
+		next, _ := consumer.NewLogs(t.consumeLogs)
+		if err = create(factory.CreateLogs(ctx, set, cfg, next)); err != nil {
+			return fmt.Errorf("failed to create the logs receiver: %w", err)
+		}
+	}
+	if len(receivers) == 0 {
+		return fmt.Errorf("receiver %q supports no signal", id)
+	}
+
+	host := &testReceiverHost{}
+	var started []component.Component
+	for _, rcv := range receivers {
+		if err = rcv.Start(ctx, host); err != nil {
+			err = fmt.Errorf("failed to start the receiver: %w", err)
+			break
+		}
+		started = append(started, rcv)
+	}
+	if err == nil {
+		<-ctx.Done()
+	}
+	for _, rcv := range started {
+		err = multierr.Append(err, rcv.Shutdown(context.Background()))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, printErr := fmt.Fprintf(t.out, "received %d metric data points, %d spans and %d log records from %s\n",
+		t.dataPoints, t.spans, t.logRecords, id)
+	return multierr.Combine(err, t.writeErr, printErr)
+}
+
+// receiverConfig returns the config of the receiver, applied on top of the default config of its type.
+func receiverConfig(factory receiver.Factory, id component.ID, conf *confmap.Conf) (component.Config, error) {
+	receivers, err := conf.Sub("receivers")
+	if err != nil {
+		return nil, err
+	}
+	if !receivers.IsSet(id.String()) {
+		return nil, fmt.Errorf("receiver %q isn't configured", id)
+	}
+	sub, err := receivers.Sub(id.String())
+	if err != nil {
+		return nil, err
+	}
+	cfg := factory.CreateDefaultConfig()
+	if err = sub.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("error reading receiver %q config: %w", id, err)
+	}
+	if err = component.ValidateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("invalid receiver %q config: %w", id, err)
+	}
+	return cfg, nil
+}
+
+func (t *receiverTester) consumeTraces(_ context.Context, td ptrace.Traces) error {
+	batch, err := (&ptrace.JSONMarshaler{}).MarshalTraces(td)
+	return t.write(&t.spans, td.SpanCount(), batch, err)
+}
+
+func (t *receiverTester) consumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	batch, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(md)
+	return t.write(&t.dataPoints, md.DataPointCount(), batch, err)
+}
+
+func (t *receiverTester) consumeLogs(_ context.Context, ld plog.Logs) error {
+	batch, err := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+	return t.write(&t.logRecords, ld.LogRecordCount(), batch, err)
+}
+
+// write writes a marshaled batch to the output, one per line. Failing to write a batch is reported once
+// the receiver stopped rather than to the receiver, since its handling of consumer errors isn't tested.
+func (t *receiverTester) write(count *int, items int, batch []byte, err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*count += items
+	if err == nil {
+		_, err = t.out.Write(append(batch, '\n'))
+	}
+	if t.writeErr == nil {
+		t.writeErr = err
+	}
+	return nil
+}
+
+// testReceiverHost is the host of the tested receiver, without the extensions of the config.
+type testReceiverHost struct{}
+
+func (*testReceiverHost) GetExtensions() map[component.ID]component.Component {
+	return nil
+}
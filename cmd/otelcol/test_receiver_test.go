@@ -0,0 +1,121 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+type testReceiverConfig struct {
+	Metric   string `mapstructure:"metric"`
+	StartErr bool   `mapstructure:"start_err"`
+}
+
+func (c *testReceiverConfig) Validate() error {
+	if c.Metric == "" {
+		return errors.New("metric must be specified")
+	}
+	return nil
+}
+
+type testMetricsReceiver struct {
+	next consumer.Metrics
+	cfg  *testReceiverConfig
+}
+
+func (r *testMetricsReceiver) Start(ctx context.Context, _ component.Host) error {
+	if r.cfg.StartErr {
+		return errors.New("connection refused")
+	}
+	md := pmetric.NewMetrics()
+	dps := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints()
+	md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).SetName(r.cfg.Metric)
+	dps.AppendEmpty().SetIntValue(1)
+	dps.AppendEmpty().SetIntValue(2)
+	return r.next.ConsumeMetrics(ctx, md)
+}
+
+func (r *testMetricsReceiver) Shutdown(context.Context) error {
+	return nil
+}
+
+func newTestReceiverFactory() receiver.Factory {
+	return receiver.NewFactory(component.MustNewType("test"),
+		func() component.Config { return &testReceiverConfig{} },
+		receiver.WithMetrics(func(_ context.Context, _ receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+			return &testMetricsReceiver{cfg: cfg.(*testReceiverConfig), next: next}, nil
+		}, component.StabilityLevelDevelopment))
+}
+
+func TestReceiverTester(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{
+			"test/postgresql": map[string]any{"metric": "postgres_up"},
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var out bytes.Buffer
+	require.NoError(t, newReceiverTester(&out, zap.NewNop()).run(ctx, newTestReceiverFactory(), component.MustNewIDWithName("test", "postgresql"), conf))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	md, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics([]byte(lines[0]))
+	require.NoError(t, err)
+	assert.Equal(t, "postgres_up", md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
+	assert.Equal(t, "received 2 metric data points, 0 spans and 0 log records from test/postgresql", lines[1])
+}
+
+func TestReceiverTesterErrors(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{
+			"test":        map[string]any{},
+			"test/broken": map[string]any{"metric": "postgres_up", "start_err": true},
+			"test/typo":   map[string]any{"metrc": "postgres_up"},
+		},
+	})
+	factory := newTestReceiverFactory()
+	tester := newReceiverTester(&bytes.Buffer{}, zap.NewNop())
+
+	err := tester.run(context.Background(), factory, component.MustNewIDWithName("test", "other"), conf)
+	assert.EqualError(t, err, `receiver "test/other" isn't configured`)
+	err = tester.run(context.Background(), factory, component.MustNewID("test"), conf)
+	assert.EqualError(t, err, `invalid receiver "test" config: metric must be specified`)
+	err = tester.run(context.Background(), factory, component.MustNewIDWithName("test", "typo"), conf)
+	assert.ErrorContains(t, err, `error reading receiver "test/typo" config`)
+	err = tester.run(context.Background(), factory, component.MustNewIDWithName("test", "broken"), conf)
+	assert.EqualError(t, err, "failed to start the receiver: connection refused")
+}
+
+func TestRunTestReceiverInvalidArgs(t *testing.T) {
+	var out bytes.Buffer
+	assert.ErrorContains(t, runTestReceiver(nil, &out), `invalid --receiver ""`)
+	assert.EqualError(t, runTestReceiver([]string{"--receiver", "smartagent/postgresql", "--duration", "0s"}, &out),
+		"--duration must be positive")
+}
@@ -0,0 +1,40 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/extension/smartagentextension"
+)
+
+const verifyBundleCommand = "verify-bundle"
+
+// runVerifyBundle validates the agent bundle against its manifest, e.g. after extracting it.
+func runVerifyBundle(args []string, out io.Writer) error {
+	flagSet := flag.NewFlagSet(verifyBundleCommand, flag.ContinueOnError)
+	bundleDir := flagSet.String("bundle-dir", smartagentextension.DefaultBundleDir(), "the agent bundle directory to verify")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if err := smartagentextension.VerifyBundle(*bundleDir); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "agent bundle %q is valid\n", *bundleDir)
+	return err
+}
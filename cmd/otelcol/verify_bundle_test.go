@@ -0,0 +1,46 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/extension/smartagentextension"
+)
+
+func TestRunVerifyBundle(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.db"), []byte("types"), 0o600))
+	manifest := strings.Repeat("0", 64) + "  ./types.db\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, smartagentextension.BundleManifest), []byte(manifest), 0o600))
+
+	var out bytes.Buffer
+	err := runVerifyBundle([]string{"--bundle-dir", dir}, &out)
+	require.ErrorContains(t, err, "./types.db: checksum mismatch")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, smartagentextension.BundleManifest)))
+	assert.ErrorIs(t, runVerifyBundle([]string{"--bundle-dir", dir}, &out), smartagentextension.ErrNoBundleManifest)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, smartagentextension.BundleManifest), nil, 0o600))
+	require.NoError(t, runVerifyBundle([]string{"--bundle-dir", dir}, &out))
+	assert.Equal(t, "agent bundle \""+dir+"\" is valid\n", out.String())
+}
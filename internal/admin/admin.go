@@ -0,0 +1,68 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admin holds the runtime operation endpoints served by the admin extension, under a
+// single server authorizing each operation separately.
+package admin
+
+import (
+	"net/http"
+	"sync"
+)
+
+// The operations of the admin endpoints. Tokens are granted the read or write permission of
+// each operation, e.g. "log_level:write".
+const (
+	OperationBrownout     = "brownout"
+	OperationDiagnostics  = "diagnostics"
+	OperationFeatureGates = "feature_gates"
+	OperationLogLevel     = "log_level"
+	OperationPipelines    = "pipelines"
+)
+
+// Operations are all the operations of the admin endpoints.
+var Operations = []string{OperationBrownout, OperationDiagnostics, OperationFeatureGates, OperationLogLevel, OperationPipelines}
+
+// Route is an admin endpoint. GET and HEAD requests require the read permission of its
+// operation, and other requests its write permission.
+type Route struct {
+	Handler   http.Handler
+	Pattern   string
+	Operation string
+}
+
+// Provider is implemented by the extensions whose endpoints are served by the admin extension.
+type Provider interface {
+	AdminRoutes() []Route
+}
+
+var (
+	registered []Route
+	mu         sync.Mutex
+)
+
+// Register registers the admin endpoints of the collector that aren't provided by an extension,
+// like the log level endpoint. It must be called before the collector starts.
+func Register(routes ...Route) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, routes...)
+}
+
+// Registered returns the registered admin endpoints.
+func Registered() []Route {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Route(nil), registered...)
+}
@@ -21,13 +21,14 @@ import (
 )
 
 const (
-	EndpointIDAttr     = "discovery.endpoint.id"
-	ObserverIDAttr     = "discovery.observer.id"
-	ReceiverConfigAttr = "discovery.receiver.config"
-	ReceiverNameAttr   = "discovery.receiver.name"
-	ReceiverTypeAttr   = "discovery.receiver.type"
-	StatusAttr         = "discovery.status"
-	MessageAttr        = "discovery.message"
+	EndpointIDAttr      = "discovery.endpoint.id"
+	ObserverIDAttr      = "discovery.observer.id"
+	ReceiverConfigAttr  = "discovery.receiver.config"
+	EvaluatedConfigAttr = "discovery.receiver.evaluated_config"
+	ReceiverNameAttr    = "discovery.receiver.name"
+	ReceiverTypeAttr    = "discovery.receiver.type"
+	StatusAttr          = "discovery.status"
+	MessageAttr         = "discovery.message"
 
 	OtelEntityTypeAttr        = "otel.entity.type"
 	OtelEntityAttributesAttr  = "otel.entity.attributes"
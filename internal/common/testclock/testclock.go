@@ -0,0 +1,26 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testclock provides a clock advanced by tests, for the components reading the time
+// through a now function.
+package testclock
+
+import "time"
+
+// New returns a now function returning the time pointed to by the returned pointer, initially
+// start, so tests can advance it.
+func New(start time.Time) (func() time.Time, *time.Time) {
+	now := start
+	return func() time.Time { return now }, &now
+}
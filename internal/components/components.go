@@ -129,10 +129,55 @@ import (
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
 	"go.uber.org/multierr"
 
+	"github.com/signalfx/splunk-otel-collector/internal/connector/circuitbreakerconnector"
+	"github.com/signalfx/splunk-otel-collector/internal/connector/contentroutingconnector"
+	"github.com/signalfx/splunk-otel-collector/internal/connector/deadletterconnector"
+	"github.com/signalfx/splunk-otel-collector/internal/connector/fanoutconnector"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/adaptivecompressionextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/adminextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/brownoutextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/containerdobserver"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/egressextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/featuregatesextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/gcpmonitoringencodingextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/inventoryextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/kerberosextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/loadbalancingextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/persistentackextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/pipelinepauseextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/quotaextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/realmfailoverextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/seriescacheextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/spiffeextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/spilloverstorageextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/statusextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/tokenmeteringextension"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/azureresourceidprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/brownoutprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/cardinalitylimitprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/clockskewprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/dedupprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/deltatocumulativeprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/hecmappingprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/histogramrebucketprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/logmetricsprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/piiredactionprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/pipelinepauseprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/quotaprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/semconvprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/sizebatchprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/wineventlogprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/cloudwatchmetricstreamsreceiver"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/lightprometheusreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/networkflowreceiver"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/scriptedinputsreceiver"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/snmptrapreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/splunks2sreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/splunksyslogreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/tlscertificatesreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/windowsperfcounterslegacyreceiver"
 	"github.com/signalfx/splunk-otel-collector/pkg/extension/smartagentextension"
 	"github.com/signalfx/splunk-otel-collector/pkg/processor/timestampprocessor"
 	"github.com/signalfx/splunk-otel-collector/pkg/receiver/smartagentreceiver"
@@ -142,19 +187,38 @@ func Get() (otelcol.Factories, error) {
 	var errs []error
 	extensions, err := extension.MakeFactoryMap(
 		ackextension.NewFactory(),
+		adaptivecompressionextension.NewFactory(),
+		adminextension.NewFactory(),
 		basicauthextension.NewFactory(),
+		brownoutextension.NewFactory(),
+		containerdobserver.NewFactory(),
 		dockerobserver.NewFactory(),
 		ecsobserver.NewFactory(),
 		ecstaskobserver.NewFactory(),
+		egressextension.NewFactory(),
+		featuregatesextension.NewFactory(),
 		filestorage.NewFactory(),
+		gcpmonitoringencodingextension.NewFactory(),
 		headerssetterextension.NewFactory(),
 		healthcheckextension.NewFactory(),
 		hostobserver.NewFactory(),
 		httpforwarderextension.NewFactory(),
+		inventoryextension.NewFactory(),
 		k8sobserver.NewFactory(),
+		kerberosextension.NewFactory(),
+		loadbalancingextension.NewFactory(),
 		oauth2clientauthextension.NewFactory(),
+		persistentackextension.NewFactory(),
+		pipelinepauseextension.NewFactory(),
 		pprofextension.NewFactory(),
+		quotaextension.NewFactory(),
+		realmfailoverextension.NewFactory(),
+		seriescacheextension.NewFactory(),
 		smartagentextension.NewFactory(),
+		spiffeextension.NewFactory(),
+		spilloverstorageextension.NewFactory(),
+		statusextension.NewFactory(),
+		tokenmeteringextension.NewFactory(),
 		zpagesextension.NewFactory(),
 	)
 	if err != nil {
@@ -173,6 +237,7 @@ func Get() (otelcol.Factories, error) {
 		carbonreceiver.NewFactory(),
 		chronyreceiver.NewFactory(),
 		cloudfoundryreceiver.NewFactory(),
+		cloudwatchmetricstreamsreceiver.NewFactory(),
 		collectdreceiver.NewFactory(),
 		discoveryreceiver.NewFactory(),
 		elasticsearchreceiver.NewFactory(),
@@ -195,6 +260,7 @@ func Get() (otelcol.Factories, error) {
 		mongodbatlasreceiver.NewFactory(),
 		mongodbreceiver.NewFactory(),
 		mysqlreceiver.NewFactory(),
+		networkflowreceiver.NewFactory(),
 		nginxreceiver.NewFactory(),
 		nopreceiver.NewFactory(),
 		oracledbreceiver.NewFactory(),
@@ -210,20 +276,25 @@ func Get() (otelcol.Factories, error) {
 		signalfxgatewayprometheusremotewritereceiver.NewFactory(),
 		simpleprometheusreceiver.NewFactory(),
 		smartagentreceiver.NewFactory(),
+		snmptrapreceiver.NewFactory(),
 		solacereceiver.NewFactory(),
 		splunkenterprisereceiver.NewFactory(),
 		splunkhecreceiver.NewFactory(),
+		splunks2sreceiver.NewFactory(),
+		splunksyslogreceiver.NewFactory(),
 		sqlqueryreceiver.NewFactory(),
 		sqlserverreceiver.NewFactory(),
 		sshcheckreceiver.NewFactory(),
 		statsdreceiver.NewFactory(),
 		syslogreceiver.NewFactory(),
 		tcplogreceiver.NewFactory(),
+		tlscertificatesreceiver.NewFactory(),
 		udplogreceiver.NewFactory(),
 		vcenterreceiver.NewFactory(),
 		wavefrontreceiver.NewFactory(),
 		windowseventlogreceiver.NewFactory(),
 		windowsperfcountersreceiver.NewFactory(),
+		windowsperfcounterslegacyreceiver.NewFactory(),
 		zipkinreceiver.NewFactory(),
 	)
 	if err != nil {
@@ -250,30 +321,49 @@ func Get() (otelcol.Factories, error) {
 
 	processors, err := processor.MakeFactoryMap(
 		attributesprocessor.NewFactory(),
+		azureresourceidprocessor.NewFactory(),
 		batchprocessor.NewFactory(),
+		brownoutprocessor.NewFactory(),
+		cardinalitylimitprocessor.NewFactory(),
+		clockskewprocessor.NewFactory(),
 		cumulativetodeltaprocessor.NewFactory(),
+		dedupprocessor.NewFactory(),
+		deltatocumulativeprocessor.NewFactory(),
 		filterprocessor.NewFactory(),
 		groupbyattrsprocessor.NewFactory(),
+		hecmappingprocessor.NewFactory(),
+		histogramrebucketprocessor.NewFactory(),
 		k8sattributesprocessor.NewFactory(),
+		logmetricsprocessor.NewFactory(),
 		logstransformprocessor.NewFactory(),
 		memorylimiterprocessor.NewFactory(),
 		metricstransformprocessor.NewFactory(),
+		piiredactionprocessor.NewFactory(),
+		pipelinepauseprocessor.NewFactory(),
 		probabilisticsamplerprocessor.NewFactory(),
+		quotaprocessor.NewFactory(),
 		redactionprocessor.NewFactory(),
 		resourcedetectionprocessor.NewFactory(),
 		resourceprocessor.NewFactory(),
 		routingprocessor.NewFactory(),
+		semconvprocessor.NewFactory(),
+		sizebatchprocessor.NewFactory(),
 		spanprocessor.NewFactory(),
 		tailsamplingprocessor.NewFactory(),
 		timestampprocessor.NewFactory(),
 		transformprocessor.NewFactory(),
+		wineventlogprocessor.NewFactory(),
 	)
 	if err != nil {
 		errs = append(errs, err)
 	}
 
 	connectors, err := connector.MakeFactoryMap(
+		circuitbreakerconnector.NewFactory(),
+		contentroutingconnector.NewFactory(),
 		countconnector.NewFactory(),
+		deadletterconnector.NewFactory(),
+		fanoutconnector.NewFactory(),
 		forwardconnector.NewFactory(),
 		routingconnector.NewFactory(),
 		spanmetricsconnector.NewFactory(),
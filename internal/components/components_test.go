@@ -27,19 +27,39 @@ import (
 func TestDefaultComponents(t *testing.T) {
 	expectedExtensions := []string{
 		"ack",
+		"adaptive_compression",
+		"admin",
 		"basicauth",
+		"brownout",
+		"cardinality_limit",
+		"containerd_observer",
 		"docker_observer",
 		"ecs_observer",
 		"ecs_task_observer",
+		"egress",
+		"feature_gates",
 		"file_storage",
+		"gcp_monitoring_encoding",
+		"grpc_load_balancing",
 		"headers_setter",
 		"health_check",
 		"host_observer",
 		"http_forwarder",
+		"inventory",
 		"k8s_observer",
+		"kerberos",
 		"oauth2client",
+		"persistent_ack",
+		"pipeline_pause",
 		"pprof",
+		"quota",
+		"realm_failover",
+		"series_cache",
 		"smartagent",
+		"spiffe",
+		"spillover_storage",
+		"status",
+		"token_metering",
 		"zpages",
 	}
 	expectedReceivers := []string{
@@ -54,6 +74,7 @@ func TestDefaultComponents(t *testing.T) {
 		"carbon",
 		"chrony",
 		"cloudfoundry",
+		"cloudwatch_metric_streams",
 		"collectd",
 		"discovery",
 		"elasticsearch",
@@ -76,6 +97,7 @@ func TestDefaultComponents(t *testing.T) {
 		"mongodb",
 		"mongodbatlas",
 		"mysql",
+		"network_flow",
 		"nginx",
 		"nop",
 		"oracledb",
@@ -91,41 +113,60 @@ func TestDefaultComponents(t *testing.T) {
 		"signalfx",
 		"signalfxgatewayprometheusremotewrite",
 		"smartagent",
+		"snmp_trap",
 		"solace",
 		"splunkenterprise",
 		"splunk_hec",
+		"splunk_s2s",
+		"splunk_syslog",
 		"sqlquery",
 		"sqlserver",
 		"sshcheck",
 		"statsd",
 		"syslog",
 		"tcplog",
+		"tls_certificates",
 		"udplog",
 		"vcenter",
 		"wavefront",
 		"windowseventlog",
 		"windowsperfcounters",
+		"windowsperfcounters_legacy",
 		"zipkin",
 	}
 	expectedProcessors := []string{
 		"attributes",
+		"azure_resource_id",
 		"batch",
+		"brownout",
+		"clockskew",
 		"cumulativetodelta",
+		"dedup",
+		"delta_to_cumulative",
 		"filter",
 		"groupbyattrs",
+		"hec_mapping",
+		"histogram_rebucket",
 		"k8sattributes",
+		"log_metrics",
 		"logstransform",
 		"memory_limiter",
 		"metricstransform",
+		"pii_redaction",
+		"pipeline_pause",
 		"probabilistic_sampler",
+		"quota",
 		"redaction",
 		"resource",
 		"resourcedetection",
 		"routing",
+		"semconv",
+		"size_batch",
 		"span",
 		"tail_sampling",
 		"timestamp",
 		"transform",
+		"wineventlog",
 	}
 	expectedExporters := []string{
 		"awss3",
@@ -142,7 +183,11 @@ func TestDefaultComponents(t *testing.T) {
 		"splunk_hec",
 	}
 	expectedConnectors := []string{
+		"circuit_breaker",
+		"content_routing",
 		"count",
+		"deadletter",
+		"fanout",
 		"forward",
 		"routing",
 		"spanmetrics",
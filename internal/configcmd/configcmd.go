@@ -0,0 +1,238 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configcmd implements the "config" subcommand reading and editing the keys of
+// collector config files, preserving their comments, for installers and administrators.
+package configcmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/internal/settings"
+)
+
+const usage = `Usage:
+  otelcol config get [--config <path>] <key>
+  otelcol config set [--config <path>] <key> <value>
+
+Keys are "::" delimited paths of the config, e.g. "exporters::signalfx::realm".
+Values are parsed as YAML, e.g. "true" is a boolean and "[a, b]" a sequence.
+The config path defaults to the SPLUNK_CONFIG environment variable or the
+default agent config.
+`
+
+// Command is the name of the subcommand, i.e. "otelcol config ...".
+const Command = "config"
+
+// Run runs the "config" subcommand with the arguments following it, writing the
+// values it gets to out.
+func Run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	action := args[0]
+
+	flagSet := flag.NewFlagSet(Command+" "+action, flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	configPath := flagSet.String("config", defaultConfigPath(), "the config file to read or edit")
+	if err := flagSet.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w\n%s", err, usage)
+	}
+	positional := flagSet.Args()
+
+	switch {
+	case action == "get" && len(positional) == 1:
+		value, err := Get(*configPath, positional[0])
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(out, value)
+		return err
+	case action == "set" && len(positional) == 2:
+		return Set(*configPath, positional[0], positional[1])
+	default:
+		return errors.New(usage)
+	}
+}
+
+// Get returns the value of the key in the config file, scalars as their plain value and
+// other nodes as YAML.
+func Get(path, key string) (string, error) {
+	_, doc, err := readDocument(path)
+	if err != nil {
+		return "", err
+	}
+	node, err := lookup(doc.Content[0], splitKey(key), 0, false)
+	if err != nil {
+		return "", err
+	}
+	if node == nil {
+		return "", fmt.Errorf("key %q not found in %q", key, path)
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value + "\n", nil
+	}
+	return encode(node)
+}
+
+// Set sets the value of the key in the config file, creating any missing maps along its path.
+// Only the lines of the key are edited: the formatting and comments of the rest of the file,
+// and the comment of the key's line, are preserved.
+func Set(path, key, value string) error {
+	content, doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	var valueDoc yaml.Node
+	if err = yaml.Unmarshal([]byte(value), &valueDoc); err != nil {
+		return fmt.Errorf("invalid value %q: %w", value, err)
+	}
+	newNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	if len(valueDoc.Content) > 0 {
+		newNode = valueDoc.Content[0]
+	}
+
+	e := newEditor(content, doc)
+	switch err = e.set(doc, splitKey(key), newNode); {
+	case err == nil:
+		return writeFile(path, e.bytes())
+	case !errors.Is(err, errNotBlockMapping):
+		return err
+	}
+
+	// flow style documents are encoded again as a whole
+	node, err := lookup(doc.Content[0], splitKey(key), 0, true)
+	if err != nil {
+		return err
+	}
+	newNode.HeadComment, newNode.LineComment, newNode.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = *newNode
+
+	encoded, err := encode(doc)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, []byte(encoded))
+}
+
+func defaultConfigPath() string {
+	if path, ok := os.LookupEnv(settings.ConfigEnvVar); ok && path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		return settings.DefaultAgentConfigWindows
+	}
+	return settings.DefaultAgentConfigLinux
+}
+
+func splitKey(key string) []string {
+	return strings.Split(key, confmap.KeyDelimiter)
+}
+
+// readDocument returns the content of the config file and its document.
+func readDocument(path string) ([]byte, *yaml.Node, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config %q: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		// empty file
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	return content, &doc, nil
+}
+
+// lookup returns the value node of the keys following from in the node, or nil if it doesn't
+// exist and create is false. Otherwise, missing keys are added with null values.
+func lookup(node *yaml.Node, keys []string, from int, create bool) (*yaml.Node, error) {
+	for i := from; i < len(keys); i++ {
+		key := keys[i]
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!null" && create {
+			node.Kind, node.Tag, node.Value = yaml.MappingNode, "!!map", ""
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%q is not a map", strings.Join(keys[:i], confmap.KeyDelimiter))
+		}
+		var value *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				value = node.Content[j+1]
+				break
+			}
+		}
+		if value == nil {
+			if !create {
+				return nil, nil
+			}
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		}
+		node = value
+	}
+	return node, nil
+}
+
+func encode(node *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeFile replaces the file by renaming a temporary file with the content, so the config is
+// never left partially written, preserving the file mode.
+func writeFile(path string, content []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,179 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configcmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `# Splunk agent config
+exporters:
+  signalfx:
+    # the access token of the organization
+    access_token: "${SPLUNK_ACCESS_TOKEN}"
+    realm: us0 # default realm
+service:
+  pipelines:
+    metrics:
+      exporters: [signalfx]
+`
+
+func writeTestConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "agent_config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+	return path
+}
+
+func TestGet(t *testing.T) {
+	path := writeTestConfig(t)
+
+	value, err := Get(path, "exporters::signalfx::realm")
+	require.NoError(t, err)
+	assert.Equal(t, "us0\n", value)
+
+	value, err = Get(path, "service::pipelines::metrics")
+	require.NoError(t, err)
+	assert.Equal(t, "exporters: [signalfx]\n", value)
+
+	_, err = Get(path, "exporters::otlp")
+	assert.EqualError(t, err, `key "exporters::otlp" not found in "`+path+`"`)
+
+	_, err = Get(path, "exporters::signalfx::realm::value")
+	assert.EqualError(t, err, `"exporters::signalfx::realm" is not a map`)
+}
+
+func TestSet(t *testing.T) {
+	path := writeTestConfig(t)
+
+	require.NoError(t, Set(path, "exporters::signalfx::realm", "eu0"))
+	require.NoError(t, Set(path, "exporters::signalfx::sync_host_metadata", "true"))
+	require.NoError(t, Set(path, "extensions::health_check::endpoint", "localhost:13133"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# Splunk agent config
+exporters:
+  signalfx:
+    # the access token of the organization
+    access_token: "${SPLUNK_ACCESS_TOKEN}"
+    realm: eu0 # default realm
+    sync_host_metadata: true
+service:
+  pipelines:
+    metrics:
+      exporters: [signalfx]
+extensions:
+  health_check:
+    endpoint: localhost:13133
+`, string(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	assert.EqualError(t, Set(path, "exporters::signalfx::realm::value", "x"), `"exporters::signalfx::realm" is not a map`)
+	assert.ErrorContains(t, Set(path, "exporters::signalfx::realm", "[a"), "invalid value")
+}
+
+func TestRun(t *testing.T) {
+	path := writeTestConfig(t)
+	var out bytes.Buffer
+
+	require.NoError(t, Run([]string{"set", "--config", path, "exporters::signalfx::realm", "us1"}, &out))
+	require.NoError(t, Run([]string{"get", "--config", path, "exporters::signalfx::realm"}, &out))
+	assert.Equal(t, "us1\n", out.String())
+
+	t.Setenv("SPLUNK_CONFIG", path)
+	out.Reset()
+	require.NoError(t, Run([]string{"get", "exporters::signalfx::realm"}, &out))
+	assert.Equal(t, "us1\n", out.String())
+
+	assert.ErrorContains(t, Run(nil, &out), "Usage:")
+	assert.ErrorContains(t, Run([]string{"get"}, &out), "Usage:")
+	assert.ErrorContains(t, Run([]string{"unset", "key"}, &out), "Usage:")
+	assert.ErrorContains(t, Run([]string{"get", "--unknown", "key"}, &out), "unknown flag")
+}
+
+func TestSetPreservesFormatting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent_config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`receivers:
+    otlp:
+        protocols:
+            grpc:
+
+
+exporters:
+    signalfx: {realm: us0, access_token: 'abc'}   # flow
+    debug: ~
+service:
+    pipelines:
+        metrics:
+            receivers:
+            - otlp
+            exporters: ["signalfx"]
+`), 0o600))
+
+	require.NoError(t, Set(path, "exporters::signalfx::realm", "eu0"))
+	require.NoError(t, Set(path, "exporters::debug::verbosity", "detailed"))
+	require.NoError(t, Set(path, "service::pipelines::metrics::receivers", "[otlp, prometheus]"))
+	require.NoError(t, Set(path, "receivers::otlp::protocols::http", "{endpoint: 'localhost:4318'}"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `receivers:
+    otlp:
+        protocols:
+            grpc:
+            http: {endpoint: 'localhost:4318'}
+
+
+exporters:
+    signalfx: {realm: eu0, access_token: 'abc'}   # flow
+    debug:
+        verbosity: detailed
+service:
+    pipelines:
+        metrics:
+            receivers: [otlp, prometheus]
+            exporters: ["signalfx"]
+`, string(content))
+
+	require.NoError(t, Set(path, "service::pipelines::metrics::exporters", "- signalfx\n- debug"))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `
+            receivers: [otlp, prometheus]
+            exporters:
+                - signalfx
+                - debug
+`)
+}
+
+func TestSetPreservesLineEndings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent_config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("exporters:\r\n  signalfx:\r\n    realm: us0\r\n"), 0o600))
+
+	require.NoError(t, Set(path, "exporters::signalfx::realm", "eu0"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "exporters:\r\n  signalfx:\r\n    realm: eu0\r\n", string(content))
+}
@@ -0,0 +1,286 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configcmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+)
+
+// editor splices the edits of a config into the lines of its file, so everything but the
+// edited key, including blank lines, indentation, quoting and comments, is left as is.
+type editor struct {
+	newline string
+	lines   []string
+	// indent is the indentation step of the file's block mappings.
+	indent int
+}
+
+func newEditor(content []byte, doc *yaml.Node) *editor {
+	e := &editor{newline: "\n", indent: 2}
+	if bytes.Contains(content, []byte("\r\n")) {
+		e.newline = "\r\n"
+	}
+	text := strings.TrimSuffix(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	if text != "" {
+		e.lines = strings.Split(text, "\n")
+	}
+	e.indent = detectIndent(doc.Content[0], e.indent)
+	return e
+}
+
+func (e *editor) bytes() []byte {
+	return []byte(strings.Join(e.lines, e.newline) + e.newline)
+}
+
+// set sets the value of the key path, adding the missing keys to their block mapping.
+// Flow mappings along the path are rendered again as a whole.
+func (e *editor) set(doc *yaml.Node, keys []string, value *yaml.Node) error {
+	parent := doc.Content[0]
+	if parent.Line == 0 {
+		// the file is empty or only has comments
+		e.lines = append(e.lines, e.renderKeys(0, keys, value)...)
+		return nil
+	}
+	if parent.Kind != yaml.MappingNode || parent.Style&yaml.FlowStyle != 0 {
+		return errNotBlockMapping
+	}
+	var parentKey *yaml.Node
+	for i, key := range keys {
+		keyNode, valueNode := find(parent, key)
+		switch {
+		case keyNode == nil:
+			return e.insert(parentKey, parent, keys[i:], value)
+		case i == len(keys)-1:
+			return e.replace(keyNode, valueNode, value)
+		case valueNode.Kind == yaml.MappingNode && valueNode.Style&yaml.FlowStyle != 0:
+			node, err := lookup(valueNode, keys, i+1, true)
+			if err != nil {
+				return err
+			}
+			*node = *value
+			return e.replace(keyNode, valueNode, valueNode)
+		case valueNode.Kind == yaml.MappingNode:
+			parentKey, parent = keyNode, valueNode
+		case isNull(valueNode):
+			return e.insert(keyNode, valueNode, keys[i+1:], value)
+		default:
+			return fmt.Errorf("%q is not a map", strings.Join(keys[:i+1], confmap.KeyDelimiter))
+		}
+	}
+	return nil
+}
+
+var errNotBlockMapping = errors.New("the config isn't a block mapping")
+
+// replace replaces the value of the key, keeping the comment of its line.
+func (e *editor) replace(keyNode, valueNode, value *yaml.Node) error {
+	keyLine, keyIndent := keyNode.Line-1, keyNode.Column-1
+	colon := keyColon(e.lines[keyLine], keyNode)
+	if colon < 0 {
+		return fmt.Errorf("failed to locate the key %q", keyNode.Value)
+	}
+	// the value ends on the key's line unless it continues on the lines of its block
+	end := e.blockEnd(keyLine, keyIndent, valueNode.Kind == yaml.SequenceNode)
+	if valueNode.Line-1 == keyLine && e.onlyBlankOrComments(keyLine+1, end) {
+		end = keyLine
+	}
+	line := e.lines[keyLine]
+	e.lines = splice(e.lines, keyLine, end+1, e.renderValue(line[:colon], lineComment(line, colon), keyIndent, value))
+	return nil
+}
+
+// insert adds the keys to the block mapping, or null value, of the parent key or to the root
+// mapping if the parent key is nil.
+func (e *editor) insert(parentKey, parent *yaml.Node, keys []string, value *yaml.Node) error {
+	var indent, after int
+	switch {
+	case parentKey == nil:
+		if len(parent.Content) > 0 {
+			indent = parent.Content[0].Column - 1
+		}
+		after = len(e.lines) - 1
+		for after >= 0 && strings.TrimSpace(e.lines[after]) == "" {
+			after--
+		}
+	case isNull(parent):
+		keyLine := parentKey.Line - 1
+		line := e.lines[keyLine]
+		colon := keyColon(line, parentKey)
+		if colon < 0 {
+			return fmt.Errorf("failed to locate the key %q", parentKey.Value)
+		}
+		// drop an explicit null like "~"
+		e.lines[keyLine] = line[:colon] + lineComment(line, colon)
+		indent, after = parentKey.Column-1+e.indent, keyLine
+	default:
+		indent = parent.Content[0].Column - 1
+		after = e.blockEnd(parentKey.Line-1, parentKey.Column-1, false)
+	}
+	e.lines = splice(e.lines, after+1, after+1, e.renderKeys(indent, keys, value))
+	return nil
+}
+
+// blockEnd returns the last line of the block value of the key on the line, including the
+// comments indented within the block but not the blank lines and comments following it.
+func (e *editor) blockEnd(keyLine, keyIndent int, sequence bool) int {
+	end := keyLine
+	for i := keyLine + 1; i < len(e.lines); i++ {
+		trimmed := strings.TrimSpace(e.lines[i])
+		indent := len(e.lines[i]) - len(strings.TrimLeft(e.lines[i], " "))
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			if indent > keyIndent {
+				end = i
+			}
+		case indent > keyIndent,
+			// block sequences may be indented like their key
+			sequence && indent == keyIndent && (trimmed == "-" || strings.HasPrefix(trimmed, "- ")):
+			end = i
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+func (e *editor) onlyBlankOrComments(from, to int) bool {
+	for i := from; i <= to; i++ {
+		if trimmed := strings.TrimSpace(e.lines[i]); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// renderKeys renders the nested keys with the value of the last one.
+func (e *editor) renderKeys(indent int, keys []string, value *yaml.Node) []string {
+	var lines []string
+	for _, key := range keys[:len(keys)-1] {
+		lines = append(lines, strings.Repeat(" ", indent)+e.render(&yaml.Node{Kind: yaml.ScalarNode, Value: key})[0]+":")
+		indent += e.indent
+	}
+	key := e.render(&yaml.Node{Kind: yaml.ScalarNode, Value: keys[len(keys)-1]})[0]
+	return append(lines, e.renderValue(strings.Repeat(" ", indent)+key+":", "", indent, value)...)
+}
+
+// renderValue renders the value of a key on the lines following the prefix, the key's line up
+// to its colon. Scalars and flow collections are rendered on the key's line.
+func (e *editor) renderValue(prefix, comment string, keyIndent int, value *yaml.Node) []string {
+	rendered := e.render(value)
+	if value.Kind == yaml.ScalarNode || value.Kind == yaml.AliasNode || value.Style&yaml.FlowStyle != 0 || len(value.Content) == 0 {
+		lines := []string{prefix + " " + rendered[0] + comment}
+		for _, line := range rendered[1:] {
+			lines = append(lines, strings.Repeat(" ", keyIndent)+line)
+		}
+		return lines
+	}
+	lines := []string{prefix + comment}
+	for _, line := range rendered {
+		lines = append(lines, strings.Repeat(" ", keyIndent+e.indent)+line)
+	}
+	return lines
+}
+
+func (e *editor) render(node *yaml.Node) []string {
+	// the comments of the key's line are kept instead
+	uncommented := *node
+	uncommented.HeadComment, uncommented.LineComment, uncommented.FootComment = "", "", ""
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(e.indent)
+	// nodes are always encodable
+	_ = encoder.Encode(&uncommented)
+	_ = encoder.Close()
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// detectIndent returns the indentation step of the first nested block mapping.
+func detectIndent(node *yaml.Node, fallback int) int {
+	if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 {
+		return fallback
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.MappingNode && value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0 {
+			if step := value.Content[0].Column - key.Column; step > 0 {
+				return step
+			}
+		}
+	}
+	return fallback
+}
+
+func find(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// keyColon returns the index following the colon of the key on the line, or -1.
+func keyColon(line string, keyNode *yaml.Node) int {
+	// quoted keys are at least as long as their value
+	start := keyNode.Column - 1 + len(keyNode.Value)
+	if start > len(line) {
+		return -1
+	}
+	i := strings.Index(line[start:], ":")
+	if i < 0 {
+		return -1
+	}
+	return start + i + 1
+}
+
+// lineComment returns the comment of the line following the index, with the spaces leading it.
+func lineComment(line string, from int) string {
+	var quote byte
+	for i := from; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == from || line[i-1] == ' ' || line[i-1] == '\t'):
+			start := i
+			for start > from && (line[start-1] == ' ' || line[start-1] == '\t') {
+				start--
+			}
+			if start == i {
+				return " " + line[i:]
+			}
+			return line[start:]
+		}
+	}
+	return ""
+}
+
+func splice(lines []string, from, to int, replacement []string) []string {
+	return append(lines[:from], append(replacement, lines[to:]...)...)
+}
@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+const (
+	apmREDMetricsConnector = "spanmetrics/splunk_apm"
+	apmREDMetricsPipeline  = "metrics/splunk_apm"
+)
+
+var apmREDMetricsFG = featuregate.GlobalRegistry().MustRegister(
+	"splunk.apmREDMetrics",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, RED metrics matching the Splunk APM Monitoring MetricSets are computed from the spans of all traces pipelines."),
+	featuregate.WithRegisterFromVersion("v0.112.0"),
+)
+
+// apmREDMetricsBuckets are the histogram bucket upper bounds of the Monitoring MetricSets
+// duration distributions.
+var apmREDMetricsBuckets = []any{
+	"1ms", "2ms", "5ms", "10ms", "25ms", "50ms", "75ms", "100ms", "250ms", "500ms",
+	"750ms", "1s", "2500ms", "5s", "7500ms", "10s", "30s", "60s",
+}
+
+// apmREDMetricsDimensions are the dimensions of the Monitoring MetricSets in addition to the
+// service.name, span.name, span.kind and status.code dimensions of every spanmetrics metric.
+var apmREDMetricsDimensions = []any{
+	map[string]any{"name": "deployment.environment"},
+	map[string]any{"name": "http.method"},
+	map[string]any{"name": "http.request.method"},
+}
+
+func apmREDMetricsConnectorConfig() map[string]any {
+	return map[string]any{
+		"aggregation_temporality": "AGGREGATION_TEMPORALITY_CUMULATIVE",
+		"metrics_flush_interval":  "10s",
+		"dimensions":              apmREDMetricsDimensions,
+		"histogram": map[string]any{
+			"unit": "ms",
+			"explicit": map[string]any{
+				"buckets": apmREDMetricsBuckets,
+			},
+		},
+		"resource_metrics_key_attributes": []any{"service.name", "telemetry.sdk.language", "telemetry.sdk.name"},
+	}
+}
+
+// SetupAPMREDMetrics computes the RED metrics of the Splunk APM Monitoring MetricSets from the spans of
+// all traces pipelines when the splunk.apmREDMetrics feature gate is enabled. A spanmetrics/splunk_apm
+// connector with the Monitoring MetricSets dimensions and histogram buckets is added to the exporters of
+// every traces pipeline, and its metrics are sent to all signalfx exporters by a metrics/splunk_apm
+// pipeline. A spanmetrics/splunk_apm connector that is already configured is used as is.
+func SetupAPMREDMetrics(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !apmREDMetricsFG.IsEnabled() {
+		return nil
+	}
+
+	out := in.ToStringMap()
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	var tracesPipelines []string
+	for id := range pipelines {
+		if typ, _, _ := strings.Cut(id, "/"); typ == "traces" {
+			tracesPipelines = append(tracesPipelines, id)
+		}
+	}
+	if len(tracesPipelines) == 0 {
+		return nil
+	}
+	if _, ok := pipelines[apmREDMetricsPipeline]; ok {
+		return errors.New("splunk.apmREDMetrics: service::pipelines::" + apmREDMetricsPipeline + " must not be configured")
+	}
+
+	exporters, _ := out["exporters"].(map[string]any)
+	var signalfxExporters []any
+	for id := range exporters {
+		if typ, _, _ := strings.Cut(id, "/"); typ == "signalfx" {
+			signalfxExporters = append(signalfxExporters, id)
+		}
+	}
+	if len(signalfxExporters) == 0 {
+		return errors.New("splunk.apmREDMetrics: a signalfx exporter must be configured")
+	}
+	sort.Slice(signalfxExporters, func(i, j int) bool {
+		return signalfxExporters[i].(string) < signalfxExporters[j].(string)
+	})
+
+	connectors, _ := out["connectors"].(map[string]any)
+	if connectors == nil {
+		connectors = map[string]any{}
+		out["connectors"] = connectors
+	}
+	if _, ok := connectors[apmREDMetricsConnector]; !ok {
+		connectors[apmREDMetricsConnector] = apmREDMetricsConnectorConfig()
+	}
+
+	for _, id := range tracesPipelines {
+		pipeline, _ := pipelines[id].(map[string]any)
+		if pipeline == nil {
+			continue
+		}
+		pipelineExporters, _ := pipeline["exporters"].([]any)
+		pipeline["exporters"] = append(pipelineExporters, apmREDMetricsConnector)
+	}
+	pipelines[apmREDMetricsPipeline] = map[string]any{
+		"receivers": []any{apmREDMetricsConnector},
+		"exporters": signalfxExporters,
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+func enableAPMREDMetrics(t *testing.T) {
+	require.NoError(t, featuregate.GlobalRegistry().Set(apmREDMetricsFG.ID(), true))
+	t.Cleanup(func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(apmREDMetricsFG.ID(), false))
+	})
+}
+
+func TestSetupAPMREDMetrics(t *testing.T) {
+	enableAPMREDMetrics(t)
+
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "traces.yaml", expected: "traces_expected.yaml"},
+		{input: "custom_connector.yaml", expected: "custom_connector_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf("testdata/apm_red_metrics/" + tt.expected)
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf("testdata/apm_red_metrics/" + tt.input)
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupAPMREDMetrics(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupAPMREDMetricsDisabled(t *testing.T) {
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/apm_red_metrics/traces.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/apm_red_metrics/traces.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, SetupAPMREDMetrics(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+}
+
+func TestSetupAPMREDMetricsInvalid(t *testing.T) {
+	enableAPMREDMetrics(t)
+
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "no_signalfx_exporter.yaml",
+			expectedErr: "splunk.apmREDMetrics: a signalfx exporter must be configured",
+		},
+		{
+			input:       "pipeline_exists.yaml",
+			expectedErr: "splunk.apmREDMetrics: service::pipelines::metrics/splunk_apm must not be configured",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf("testdata/apm_red_metrics/" + tt.input)
+			require.NoError(t, err)
+			require.EqualError(t, SetupAPMREDMetrics(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
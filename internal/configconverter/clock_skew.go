@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	clockSkewKey       = "splunk_clock_skew"
+	clockSkewProcessor = "clockskew/splunk"
+)
+
+// SetupClockSkew applies the distribution level `splunk_clock_skew` settings and removes them from
+// the config. A clockskew/splunk processor with the settings is added to every pipeline after its
+// memory_limiter processors, so the timestamps of all ingested telemetry are corrected.
+func SetupClockSkew(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(clockSkewKey) {
+		return nil
+	}
+
+	out := in.ToStringMap()
+	settings := out[clockSkewKey]
+	delete(out, clockSkewKey)
+	if _, ok := settings.(map[string]any); !ok && settings != nil {
+		return fmt.Errorf("%s must be a map", clockSkewKey)
+	}
+
+	processors := ensureMap(out, "processors")
+	if _, ok := processors[clockSkewProcessor]; ok {
+		return fmt.Errorf("%s: processors::%s must not be configured", clockSkewKey, clockSkewProcessor)
+	}
+	processors[clockSkewProcessor] = settings
+
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	for id, p := range pipelines {
+		pipeline, _ := p.(map[string]any)
+		if pipeline == nil {
+			continue
+		}
+		pipelineProcessors, err := processorsOf(pipeline)
+		if err != nil {
+			return fmt.Errorf("%s: invalid processors of pipeline %q: %w", clockSkewKey, id, err)
+		}
+		pipeline["processors"] = insertAfterMemoryLimiters(pipelineProcessors, clockSkewProcessor)
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// processorsOf returns the processors of the pipeline.
+func processorsOf(pipeline map[string]any) ([]any, error) {
+	if pipeline["processors"] == nil {
+		return nil, nil
+	}
+	return toAnySlice(pipeline["processors"])
+}
+
+// insertAfterMemoryLimiters inserts the processor after the leading memory_limiter processors,
+// which should reject data before it's processed.
+func insertAfterMemoryLimiters(processors []any, processor string) []any {
+	i := 0
+	for ; i < len(processors); i++ {
+		id, _ := processors[i].(string)
+		if typ, _, _ := strings.Cut(id, "/"); typ != "memory_limiter" {
+			break
+		}
+	}
+	inserted := make([]any, 0, len(processors)+1)
+	inserted = append(inserted, processors[:i]...)
+	inserted = append(inserted, processor)
+	return append(inserted, processors[i:]...)
+}
@@ -0,0 +1,79 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupClockSkew(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "clock_skew.yaml", expected: "clock_skew_expected.yaml"},
+		{input: "defaults.yaml", expected: "defaults_expected.yaml"},
+		// configs without splunk_clock_skew are unchanged
+		{input: "clock_skew_expected.yaml", expected: "clock_skew_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "clock_skew", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "clock_skew", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupClockSkew(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupClockSkewInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "already_configured.yaml",
+			expectedErr: "splunk_clock_skew: processors::clockskew/splunk must not be configured",
+		},
+		{
+			input:       "invalid.yaml",
+			expectedErr: "splunk_clock_skew must be a map",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "clock_skew", tt.input))
+			require.NoError(t, err)
+			require.EqualError(t, SetupClockSkew(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
+
+func TestInsertAfterMemoryLimiters(t *testing.T) {
+	assert.Equal(t, []any{"clockskew/splunk"}, insertAfterMemoryLimiters(nil, "clockskew/splunk"))
+	assert.Equal(t,
+		[]any{"memory_limiter", "memory_limiter/2", "clockskew/splunk", "batch", "memory_limiter/3"},
+		insertAfterMemoryLimiters([]any{"memory_limiter", "memory_limiter/2", "batch", "memory_limiter/3"}, "clockskew/splunk"))
+}
@@ -33,6 +33,8 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 	"gopkg.in/yaml.v2"
 
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
+	configsourcereferences "github.com/signalfx/splunk-otel-collector/internal/configsource"
 	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/configsource"
 )
 
@@ -43,13 +45,15 @@ const (
 	defaultConfigServerEndpoint = "localhost:" + defaultConfigServerPort
 	effectivePath               = "/debug/configz/effective"
 	initialPath                 = "/debug/configz/initial"
+	referencesPath              = "/debug/configz/references"
 )
 
 type ConfigType int
 
 const (
-	initialConfig   ConfigType = 1
-	effectiveConfig ConfigType = 2
+	initialConfig    ConfigType = 1
+	effectiveConfig  ConfigType = 2
+	referencesReport ConfigType = 3
 )
 
 var _ confmap.Converter = (*ConfigServer)(nil)
@@ -60,6 +64,7 @@ type ConfigServer struct {
 	initial        map[string]any
 	effective      map[string]any
 	server         *http.Server
+	mux            *http.ServeMux
 	serverCount    atomic.Int64
 	serverShutdown sync.WaitGroup
 	initialMutex   sync.RWMutex
@@ -85,6 +90,10 @@ func NewConfigServer() *ConfigServer {
 	effectiveHandleFunc := cs.muxHandleFunc(effectiveConfig)
 	mux.HandleFunc(effectivePath, effectiveHandleFunc)
 
+	referencesHandleFunc := cs.muxHandleFunc(referencesReport)
+	mux.HandleFunc(referencesPath, referencesHandleFunc)
+
+	cs.mux = mux
 	cs.server = &http.Server{
 		ReadHeaderTimeout: 20 * time.Second,
 		Handler:           mux,
@@ -92,6 +101,22 @@ func NewConfigServer() *ConfigServer {
 	return cs
 }
 
+// Handle registers an additional debug handler for the pattern. It must be called before the
+// server is started.
+func (cs *ConfigServer) Handle(pattern string, handler http.Handler) {
+	cs.mux.Handle(pattern, handler)
+}
+
+// AdminRoutes returns the config endpoints served by the admin extension, regardless of
+// whether the config server is enabled.
+func (cs *ConfigServer) AdminRoutes() []admin.Route {
+	return []admin.Route{
+		{Pattern: initialPath, Operation: admin.OperationDiagnostics, Handler: http.HandlerFunc(cs.muxHandleFunc(initialConfig))},
+		{Pattern: effectivePath, Operation: admin.OperationDiagnostics, Handler: http.HandlerFunc(cs.muxHandleFunc(effectiveConfig))},
+		{Pattern: referencesPath, Operation: admin.OperationDiagnostics, Handler: http.HandlerFunc(cs.muxHandleFunc(referencesReport))},
+	}
+}
+
 // Convert is intended to be called as the final service confmap.Converter,
 // which registers the service config before being finally resolved and unmarshalled.
 func (cs *ConfigServer) Convert(_ context.Context, conf *confmap.Conf) error {
@@ -184,15 +209,28 @@ func (cs *ConfigServer) muxHandleFunc(configType ConfigType) func(http.ResponseW
 		}
 
 		var configYAML []byte
-		if configType == initialConfig {
+		switch configType {
+		case initialConfig:
 			configYAML, _ = yaml.Marshal(cs.getInitial())
-		} else {
+		case referencesReport:
+			configYAML, _ = yaml.Marshal(cs.referenceReports())
+		default:
 			configYAML, _ = yaml.Marshal(simpleRedact(cs.getEffective()))
 		}
 		_, _ = writer.Write(configYAML)
 	}
 }
 
+// referenceReports returns the config source reference report of each retrieved config by scheme.
+func (cs *ConfigServer) referenceReports() map[string]configsourcereferences.ReferenceReport {
+	effective := cs.getEffective()
+	reports := map[string]configsourcereferences.ReferenceReport{}
+	for scheme, initial := range cs.getInitial() {
+		reports[scheme] = configsourcereferences.NewReferenceReport(cast.ToStringMap(initial), effective)
+	}
+	return reports
+}
+
 func simpleRedact(config map[string]any) map[string]any {
 	redactedConfig := make(map[string]any)
 	for k, v := range config {
@@ -30,6 +30,7 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 	"gopkg.in/yaml.v2"
 
+	configsourcereferences "github.com/signalfx/splunk-otel-collector/internal/configsource"
 	"github.com/signalfx/splunk-otel-collector/tests/testutils"
 )
 
@@ -177,6 +178,20 @@ func TestConfigServer_Serve(t *testing.T) {
 	// Test for the pages to be actually valid YAML files.
 	assertValidYAMLPages(t, map[string]any{"scheme": initial}, "/debug/configz/initial")
 	assertValidYAMLPages(t, effective, "/debug/configz/effective")
+
+	resp, err := http.Get("http://" + defaultConfigServerEndpoint + "/debug/configz/references")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, resp.Body.Close())
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	respBytes, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var reports map[string]configsourcereferences.ReferenceReport
+	require.NoError(t, yaml.Unmarshal(respBytes, &reports))
+	unset := configsourcereferences.Reference{Key: "map::password", Source: "env", URI: "env:ENV_VAR"}
+	assert.Equal(t, []configsourcereferences.Reference{unset}, reports["scheme"].References)
+	assert.Equal(t, []configsourcereferences.Reference{unset}, reports["scheme"].Unresolved)
 }
 
 func assertValidYAMLPages(t *testing.T, expected map[string]any, path string) {
@@ -0,0 +1,160 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"log"
+	"regexp"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// ContainerEnvironment describes the container the collector runs in, if any.
+type ContainerEnvironment struct {
+	// HostRootPath is where the host's root filesystem is mounted in the container,
+	// or empty if it isn't.
+	HostRootPath string
+	InContainer  bool
+}
+
+// hostScrapers report the host's processes, which can't be observed from a container
+// without the host's root filesystem.
+var hostScrapers = []string{"process", "processes"}
+
+// containerExcludeMountPoints and containerExcludeFSTypes exclude the container runtime's
+// and virtual filesystems from the filesystem scraper.
+var (
+	containerExcludeMountPoints = map[string]any{
+		"mount_points": []any{
+			"/dev/*", "/proc/*", "/sys/*", "/run/containerd/*", "/run/k3s/containerd/*",
+			"/var/lib/containerd/*", "/var/lib/docker/*", "/var/lib/kubelet/*", "/snap/*",
+		},
+		"match_type": "regexp",
+	}
+	containerExcludeFSTypes = map[string]any{
+		"fs_types": []any{
+			"autofs", "binfmt_misc", "bpf", "cgroup", "cgroup2", "configfs", "debugfs", "devpts", "devtmpfs",
+			"fusectl", "hugetlbfs", "iso9660", "mqueue", "nsfs", "overlay", "proc", "procfs", "pstore",
+			"rpc_pipefs", "securityfs", "selinuxfs", "squashfs", "sysfs", "tracefs",
+		},
+		"match_type": "strict",
+	}
+)
+
+// ContainerDefaults returns a converter adjusting the hostmetrics receivers' defaults when the
+// collector runs in a container:
+// - `root_path` is set to the host's root filesystem mount, if any.
+// - Without the host's root filesystem, the process and processes scrapers are removed since
+// they would only report the container's processes.
+// - The filesystem scraper excludes the container runtime's and virtual filesystems.
+// Explicitly configured settings are never changed.
+func ContainerDefaults(env ContainerEnvironment) func(context.Context, *confmap.Conf) error {
+	return func(_ context.Context, in *confmap.Conf) error {
+		if in == nil || !env.InContainer {
+			return nil
+		}
+
+		receivers, err := in.Sub("receivers")
+		if err != nil {
+			return nil // Ignore invalid config. Rely on the config validation to catch this.
+		}
+		hostmetricsRE := regexp.MustCompile(`^hostmetrics(/.+)?$`)
+		out := map[string]any{}
+		for name, receiverCfg := range receivers.ToStringMap() {
+			if !hostmetricsRE.MatchString(name) {
+				continue
+			}
+			cfg, ok := receiverCfg.(map[string]any)
+			if !ok {
+				continue
+			}
+			if applyContainerDefaults(name, cfg, env) {
+				out[name] = cfg
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+
+		// removed scrapers must not be merged back in, so the receivers are replaced
+		conf := in.ToStringMap()
+		allReceivers := conf["receivers"].(map[string]any)
+		for name, cfg := range out {
+			allReceivers[name] = cfg
+		}
+		*in = *confmap.NewFromStringMap(conf)
+		return nil
+	}
+}
+
+// applyContainerDefaults updates the receiver config and returns whether it changed.
+func applyContainerDefaults(name string, cfg map[string]any, env ContainerEnvironment) bool {
+	changed := false
+	if _, ok := cfg["root_path"]; !ok && env.HostRootPath != "" {
+		cfg["root_path"] = env.HostRootPath
+		log.Printf("[INFO] Set `receivers` -> `%s` -> `root_path` to the host's root filesystem mount %q.", name, env.HostRootPath)
+		changed = true
+	}
+
+	scrapers, ok := cfg["scrapers"].(map[string]any)
+	if !ok {
+		return changed
+	}
+	if _, ok = cfg["root_path"]; !ok {
+		for _, scraper := range hostScrapers {
+			if _, ok = scrapers[scraper]; ok {
+				delete(scrapers, scraper)
+				log.Printf("[INFO] Removed the `%s` scraper from `receivers` -> `%s` since the host's root filesystem "+
+					"isn't mounted in the container.", scraper, name)
+				changed = true
+			}
+		}
+	}
+	if fs, ok := scrapers["filesystem"]; ok {
+		fsCfg, _ := fs.(map[string]any)
+		if fsCfg == nil {
+			fsCfg = map[string]any{}
+		}
+		if setFilesystemExclusions(fsCfg) {
+			scrapers["filesystem"] = fsCfg
+			changed = true
+		}
+	}
+	return changed
+}
+
+// setFilesystemExclusions sets the filesystem scraper's exclusions unless any filtering is configured.
+func setFilesystemExclusions(fsCfg map[string]any) bool {
+	for _, key := range []string{"include_devices", "exclude_devices", "include_fs_types", "exclude_fs_types", "include_mount_points", "exclude_mount_points"} {
+		if _, ok := fsCfg[key]; ok {
+			return false
+		}
+	}
+	fsCfg["exclude_mount_points"] = copyMap(containerExcludeMountPoints)
+	fsCfg["exclude_fs_types"] = copyMap(containerExcludeFSTypes)
+	return true
+}
+
+func copyMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if s, ok := v.([]any); ok {
+			v = append([]any(nil), s...)
+		}
+		out[k] = v
+	}
+	return out
+}
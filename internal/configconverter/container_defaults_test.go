@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestContainerDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantOutput string
+		env        ContainerEnvironment
+	}{
+		{
+			name:       "host",
+			wantOutput: "testdata/container_defaults/hostmetrics.yaml",
+		},
+		{
+			name:       "container_with_hostfs",
+			env:        ContainerEnvironment{InContainer: true, HostRootPath: "/hostfs"},
+			wantOutput: "testdata/container_defaults/hostmetrics_hostfs_expected.yaml",
+		},
+		{
+			name:       "container_without_hostfs",
+			env:        ContainerEnvironment{InContainer: true},
+			wantOutput: "testdata/container_defaults/hostmetrics_no_hostfs_expected.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(tt.wantOutput)
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf("testdata/container_defaults/hostmetrics.yaml")
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, ContainerDefaults(tt.env)(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
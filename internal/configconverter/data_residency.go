@@ -0,0 +1,161 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/egress"
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
+)
+
+const (
+	dataResidencyKey       = "splunk_data_residency"
+	dataResidencyProcessor = "resource/data_residency"
+	// defaultDataResidencyAttribute is the resource attribute the residency is stamped as by default.
+	defaultDataResidencyAttribute = "data.residency"
+)
+
+type dataResidencyConfig struct {
+	Residency     string   `mapstructure:"residency"`
+	Attribute     string   `mapstructure:"attribute"`
+	AllowedRealms []string `mapstructure:"allowed_realms"`
+	AllowedHosts  []string `mapstructure:"allowed_hosts"`
+}
+
+// SetupDataResidency applies the distribution level `splunk_data_residency` settings and removes them
+// from the config. A resource/data_residency processor stamping the residency as a resource attribute is
+// added to every pipeline after its memory_limiter processors. With allowed_realms or allowed_hosts, the
+// exporters sending to other realms or hosts, directly or through a proxy, fail the config validation.
+func SetupDataResidency(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(dataResidencyKey) {
+		return nil
+	}
+
+	cfg := dataResidencyConfig{Attribute: defaultDataResidencyAttribute}
+	residencySettings, err := in.Sub(dataResidencyKey)
+	if err != nil {
+		return err
+	}
+	if err = residencySettings.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", dataResidencyKey, err)
+	}
+	if cfg.Residency == "" {
+		return fmt.Errorf("%s::residency must not be empty", dataResidencyKey)
+	}
+	if cfg.Attribute == "" {
+		return fmt.Errorf("%s::attribute must not be empty", dataResidencyKey)
+	}
+
+	out := in.ToStringMap()
+	delete(out, dataResidencyKey)
+
+	if len(cfg.AllowedRealms) > 0 || len(cfg.AllowedHosts) > 0 {
+		if err = checkDataResidency(cfg, out); err != nil {
+			return err
+		}
+	}
+
+	processors := ensureMap(out, "processors")
+	if _, ok := processors[dataResidencyProcessor]; ok {
+		return fmt.Errorf("%s: processors::%s must not be configured", dataResidencyKey, dataResidencyProcessor)
+	}
+	processors[dataResidencyProcessor] = map[string]any{
+		"attributes": []any{
+			map[string]any{"key": cfg.Attribute, "value": cfg.Residency, "action": "upsert"},
+		},
+	}
+
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	for id, p := range pipelines {
+		pipeline, _ := p.(map[string]any)
+		if pipeline == nil {
+			continue
+		}
+		pipelineProcessors, err := processorsOf(pipeline)
+		if err != nil {
+			return fmt.Errorf("%s: invalid processors of pipeline %q: %w", dataResidencyKey, id, err)
+		}
+		pipeline["processors"] = insertAfterMemoryLimiters(pipelineProcessors, dataResidencyProcessor)
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// checkDataResidency returns an error for the first exporter endpoint or proxy, including the proxies of
+// the HTTP_PROXY and HTTPS_PROXY environment variables, outside the allowed realms and hosts.
+func checkDataResidency(cfg dataResidencyConfig, out map[string]any) error {
+	allowlist, err := egress.NewAllowlist(cfg.AllowedHosts)
+	if err != nil {
+		return fmt.Errorf("%s::allowed_hosts: %w", dataResidencyKey, err)
+	}
+	allows := func(host string) bool {
+		if allowlist.Allows(host) {
+			return true
+		}
+		r, ok := realm.FromHost(host)
+		return ok && slices.Contains(cfg.AllowedRealms, r)
+	}
+
+	exporters, _ := out["exporters"].(map[string]any)
+	ids := make([]string, 0, len(exporters))
+	for id := range exporters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		expCfg, _ := exporters[id].(map[string]any)
+		for _, endpoint := range exporterEndpoints(expCfg) {
+			host, err := endpointHost(endpoint)
+			if err != nil {
+				return fmt.Errorf("%s: invalid endpoint of exporter %q: %w", dataResidencyKey, id, err)
+			}
+			if !allows(host) {
+				return fmt.Errorf("%s: exporter %q endpoint host %q violates the %q residency policy", dataResidencyKey, id, host, cfg.Residency)
+			}
+		}
+		if proxyURL, ok := expCfg["proxy_url"].(string); ok && proxyURL != "" {
+			host, err := endpointHost(proxyURL)
+			if err != nil {
+				return fmt.Errorf("%s: invalid proxy_url of exporter %q: %w", dataResidencyKey, id, err)
+			}
+			if !allows(host) {
+				return fmt.Errorf("%s: exporter %q proxy host %q violates the %q residency policy", dataResidencyKey, id, host, cfg.Residency)
+			}
+		}
+	}
+	for _, env := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy"} {
+		proxyURL := os.Getenv(env)
+		if proxyURL == "" {
+			continue
+		}
+		host, err := endpointHost(proxyURL)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", dataResidencyKey, env, err)
+		}
+		if !allows(host) {
+			return fmt.Errorf("%s: %s host %q violates the %q residency policy", dataResidencyKey, env, host, cfg.Residency)
+		}
+	}
+	return nil
+}
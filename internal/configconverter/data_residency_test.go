@@ -0,0 +1,90 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupDataResidency(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "data_residency.yaml", expected: "data_residency_expected.yaml"},
+		{input: "tagging_only.yaml", expected: "tagging_only_expected.yaml"},
+		{input: "data_residency_expected.yaml", expected: "data_residency_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf("testdata/data_residency/" + tt.expected)
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf("testdata/data_residency/" + tt.input)
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupDataResidency(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupDataResidencyInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "disallowed_realm.yaml",
+			expectedErr: `splunk_data_residency: exporter "signalfx/us" endpoint host "ingest.us1.signalfx.com" violates the "eu" residency policy`,
+		},
+		{
+			input:       "disallowed_host.yaml",
+			expectedErr: `splunk_data_residency: exporter "otlphttp" endpoint host "otlp.example.com" violates the "eu" residency policy`,
+		},
+		{
+			input:       "disallowed_proxy.yaml",
+			expectedErr: `splunk_data_residency: exporter "signalfx" proxy host "proxy.example.com" violates the "eu" residency policy`,
+		},
+		{
+			input:       "no_residency.yaml",
+			expectedErr: "splunk_data_residency::residency must not be empty",
+		},
+		{
+			input:       "configured_processor.yaml",
+			expectedErr: "splunk_data_residency: processors::resource/data_residency must not be configured",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf("testdata/data_residency/" + tt.input)
+			require.NoError(t, err)
+			require.EqualError(t, SetupDataResidency(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
+
+func TestSetupDataResidencyProxyEnv(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+	cfgMap, err := confmaptest.LoadConf("testdata/data_residency/data_residency.yaml")
+	require.NoError(t, err)
+	require.EqualError(t, SetupDataResidency(context.Background(), cfgMap),
+		`splunk_data_residency: HTTPS_PROXY host "proxy.example.com" violates the "eu" residency policy`)
+}
@@ -0,0 +1,159 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/egress"
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
+)
+
+const (
+	egressKey       = "splunk_egress"
+	egressExtension = "egress/splunk"
+)
+
+type egressConfig struct {
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+}
+
+// SetupEgress applies the distribution level `splunk_egress` settings and removes them from the
+// config. An `egress/splunk` extension, started before all other extensions, restricts the HTTP and
+// gRPC clients of all components to the hosts of allowed_hosts while the config runs, and exporters
+// configured with endpoints or proxies outside of them, like proxies of the HTTP_PROXY and HTTPS_PROXY
+// environment variables `splunk_proxy` sets, are reported when the config is loaded rather than when
+// they first send data.
+func SetupEgress(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(egressKey) {
+		return nil
+	}
+
+	var cfg egressConfig
+	egressSettings, err := in.Sub(egressKey)
+	if err != nil {
+		return err
+	}
+	if err = egressSettings.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", egressKey, err)
+	}
+	if len(cfg.AllowedHosts) == 0 {
+		return fmt.Errorf("%s::allowed_hosts must not be empty", egressKey)
+	}
+	allowlist, err := egress.NewAllowlist(cfg.AllowedHosts)
+	if err != nil {
+		return fmt.Errorf("%s::allowed_hosts: %w", egressKey, err)
+	}
+
+	out := in.ToStringMap()
+	delete(out, egressKey)
+
+	exporters, _ := out["exporters"].(map[string]any)
+	ids := make([]string, 0, len(exporters))
+	for id := range exporters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		expCfg, _ := exporters[id].(map[string]any)
+		for _, endpoint := range exporterEndpoints(expCfg) {
+			host, err := endpointHost(endpoint)
+			if err != nil {
+				return fmt.Errorf("%s: invalid endpoint of exporter %q: %w", egressKey, id, err)
+			}
+			if !allowlist.Allows(host) {
+				return fmt.Errorf("%s: exporter %q endpoint host %q isn't in allowed_hosts", egressKey, id, host)
+			}
+		}
+		if proxyURL, ok := expCfg["proxy_url"].(string); ok && proxyURL != "" {
+			host, err := endpointHost(proxyURL)
+			if err != nil {
+				return fmt.Errorf("%s: invalid proxy_url of exporter %q: %w", egressKey, id, err)
+			}
+			if !allowlist.Allows(host) {
+				return fmt.Errorf("%s: exporter %q proxy host %q isn't in allowed_hosts", egressKey, id, host)
+			}
+		}
+	}
+	for _, env := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy"} {
+		proxyURL := os.Getenv(env)
+		if proxyURL == "" {
+			continue
+		}
+		host, err := endpointHost(proxyURL)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", egressKey, env, err)
+		}
+		if !allowlist.Allows(host) {
+			return fmt.Errorf("%s: %s host %q isn't in allowed_hosts", egressKey, env, host)
+		}
+	}
+
+	hosts := make([]any, 0, len(cfg.AllowedHosts))
+	for _, host := range cfg.AllowedHosts {
+		hosts = append(hosts, host)
+	}
+	ensureMap(out, "extensions")[egressExtension] = map[string]any{"allowed_hosts": hosts}
+	service := ensureMap(out, "service")
+	serviceExtensions, _ := service["extensions"].([]any)
+	// the restriction must be in place before other extensions create clients
+	service["extensions"] = append([]any{egressExtension}, serviceExtensions...)
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// exporterEndpoints returns the configured and realm derived endpoints of an exporter.
+func exporterEndpoints(expCfg map[string]any) []string {
+	var endpoints []string
+	for _, key := range []string{"endpoint", "ingest_url", "api_url"} {
+		if endpoint, ok := expCfg[key].(string); ok && endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if r, ok := expCfg["realm"].(string); ok && r != "" {
+		realmEndpoints := realm.ForRealm(r)
+		if _, ok = expCfg["ingest_url"]; !ok {
+			endpoints = append(endpoints, realmEndpoints.Ingest)
+		}
+		if _, ok = expCfg["api_url"]; !ok {
+			endpoints = append(endpoints, realmEndpoints.API)
+		}
+	}
+	return endpoints
+}
+
+// endpointHost returns the host of a URL or host:port endpoint.
+func endpointHost(endpoint string) (string, error) {
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", err
+		}
+		return u.Hostname(), nil
+	}
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return host, nil
+	}
+	return endpoint, nil
+}
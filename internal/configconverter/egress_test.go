@@ -0,0 +1,102 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupEgress(t *testing.T) {
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/egress/egress_expected.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/egress/egress.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, SetupEgress(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+}
+
+func TestSetupEgressNoop(t *testing.T) {
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/egress/egress_expected.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/egress/egress_expected.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, SetupEgress(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+}
+
+func TestSetupEgressInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "disallowed_exporter.yaml",
+			expectedErr: `splunk_egress: exporter "signalfx" endpoint host "api.us1.signalfx.com" isn't in allowed_hosts`,
+		},
+		{
+			input: "invalid_host.yaml",
+			expectedErr: `splunk_egress::allowed_hosts: invalid allowed host "https://ingest.us0.signalfx.com": ` +
+				"must be a host name, a *. prefixed domain, an IP address or a CIDR range",
+		},
+		{
+			input:       "no_hosts.yaml",
+			expectedErr: "splunk_egress::allowed_hosts must not be empty",
+		},
+		{
+			input:       "disallowed_proxy.yaml",
+			expectedErr: `splunk_egress: exporter "signalfx" proxy host "proxy.example.com" isn't in allowed_hosts`,
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf("testdata/egress/" + tt.input)
+			require.NoError(t, err)
+			require.EqualError(t, SetupEgress(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
+
+func TestSetupEgressDisallowedProxyEnv(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+	cfgMap, err := confmaptest.LoadConf("testdata/egress/egress.yaml")
+	require.NoError(t, err)
+	require.EqualError(t, SetupEgress(context.Background(), cfgMap),
+		`splunk_egress: HTTPS_PROXY host "proxy.example.com" isn't in allowed_hosts`)
+}
+
+func TestEndpointHost(t *testing.T) {
+	for endpoint, expected := range map[string]string{
+		"https://ingest.us0.signalfx.com/v2/trace": "ingest.us0.signalfx.com",
+		"http://[::1]:4318":                        "::1",
+		"otlp.example.com:4317":                    "otlp.example.com",
+		"otlp.example.com":                         "otlp.example.com",
+	} {
+		host, err := endpointHost(endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, expected, host, endpoint)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	gpuKey         = "splunk_gpu"
+	gpuName        = "gpu"
+	gpuPipeline    = "metrics/" + gpuName
+	gpuReceiver    = "prometheus_simple/" + gpuName
+	gpuProcessor   = "metricstransform/" + gpuName
+	gpuEndpointEnv = "SPLUNK_DCGM_EXPORTER_ENDPOINT"
+)
+
+// gpuMetricNames maps the default metrics of the NVIDIA DCGM exporter to the Smart Agent style
+// names the GPU dashboards and detectors use.
+var gpuMetricNames = map[string]string{
+	"DCGM_FI_DEV_GPU_UTIL":                 "gpu.utilization",
+	"DCGM_FI_DEV_MEM_COPY_UTIL":            "gpu.memory.utilization",
+	"DCGM_FI_DEV_ENC_UTIL":                 "gpu.encoder.utilization",
+	"DCGM_FI_DEV_DEC_UTIL":                 "gpu.decoder.utilization",
+	"DCGM_FI_DEV_FB_USED":                  "gpu.memory.used",
+	"DCGM_FI_DEV_FB_FREE":                  "gpu.memory.free",
+	"DCGM_FI_DEV_GPU_TEMP":                 "gpu.temperature",
+	"DCGM_FI_DEV_MEMORY_TEMP":              "gpu.memory.temperature",
+	"DCGM_FI_DEV_POWER_USAGE":              "gpu.power_usage",
+	"DCGM_FI_DEV_TOTAL_ENERGY_CONSUMPTION": "gpu.energy_consumption",
+	"DCGM_FI_DEV_SM_CLOCK":                 "gpu.clock.sm",
+	"DCGM_FI_DEV_MEM_CLOCK":                "gpu.clock.memory",
+	"DCGM_FI_DEV_XID_ERRORS":               "gpu.xid_errors",
+	"DCGM_FI_DEV_PCIE_REPLAY_COUNTER":      "gpu.pcie.replays",
+}
+
+type gpuConfig struct {
+	Endpoint           string   `mapstructure:"endpoint"`
+	CollectionInterval string   `mapstructure:"collection_interval"`
+	Exporters          []string `mapstructure:"exporters"`
+	Processors         []string `mapstructure:"processors"`
+	// DCGMNames keeps the metric names of the DCGM exporter instead of the Smart Agent style ones.
+	DCGMNames bool `mapstructure:"dcgm_names"`
+}
+
+// SetupGPU applies the distribution level `splunk_gpu` preset and removes it from the config. It
+// scrapes the NVIDIA DCGM exporter of the host, localhost:9400 by default, from a metrics/gpu
+// pipeline exporting to the configured exporters, or all signalfx exporters, renaming the DCGM
+// metrics to their Smart Agent style names. Setting the SPLUNK_DCGM_EXPORTER_ENDPOINT environment
+// variable enables the preset without a `splunk_gpu` block, and sets the endpoint of one without
+// its own.
+func SetupGPU(_ context.Context, in *confmap.Conf) error {
+	envEndpoint := os.Getenv(gpuEndpointEnv)
+	if in == nil || (!in.IsSet(gpuKey) && envEndpoint == "") {
+		return nil
+	}
+
+	cfg := gpuConfig{Endpoint: envEndpoint, CollectionInterval: "10s"}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "localhost:9400"
+	}
+	preset, err := in.Sub(gpuKey)
+	if err != nil {
+		return err
+	}
+	if err = preset.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", gpuKey, err)
+	}
+
+	out := in.ToStringMap()
+	delete(out, gpuKey)
+
+	service := ensureMap(out, "service")
+	pipelines := ensureMap(service, "pipelines")
+	if _, ok := pipelines[gpuPipeline]; ok {
+		return errors.New(gpuKey + ": service::pipelines::" + gpuPipeline + " must not be configured")
+	}
+	exporters, err := presetExporters(gpuKey, cfg.Exporters, out)
+	if err != nil {
+		return err
+	}
+
+	receivers := ensureMap(out, "receivers")
+	if _, ok := receivers[gpuReceiver]; ok {
+		return fmt.Errorf("%s: receivers::%s must not be configured", gpuKey, gpuReceiver)
+	}
+	receivers[gpuReceiver] = map[string]any{
+		"endpoint":            cfg.Endpoint,
+		"collection_interval": cfg.CollectionInterval,
+	}
+
+	var pipelineProcessors []any
+	if !cfg.DCGMNames {
+		processors := ensureMap(out, "processors")
+		if _, ok := processors[gpuProcessor]; ok {
+			return fmt.Errorf("%s: processors::%s must not be configured", gpuKey, gpuProcessor)
+		}
+		processors[gpuProcessor] = map[string]any{"transforms": gpuMetricTransforms()}
+		pipelineProcessors = append(pipelineProcessors, gpuProcessor)
+	}
+	for _, p := range cfg.Processors {
+		pipelineProcessors = append(pipelineProcessors, p)
+	}
+
+	pipeline := map[string]any{
+		"receivers": []any{gpuReceiver},
+		"exporters": exporters,
+	}
+	if len(pipelineProcessors) > 0 {
+		pipeline["processors"] = pipelineProcessors
+	}
+	pipelines[gpuPipeline] = pipeline
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// gpuMetricTransforms returns the metricstransform processor transforms renaming the DCGM metrics.
+func gpuMetricTransforms() []any {
+	names := make([]string, 0, len(gpuMetricNames))
+	for name := range gpuMetricNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	transforms := make([]any, 0, len(names))
+	for _, name := range names {
+		transforms = append(transforms, map[string]any{
+			"include":  name,
+			"action":   "update",
+			"new_name": gpuMetricNames[name],
+		})
+	}
+	return transforms
+}
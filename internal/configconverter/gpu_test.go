@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupGPU(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+		endpoint string
+	}{
+		{input: "gpu.yaml", expected: "gpu_expected.yaml"},
+		{input: "dcgm_names.yaml", expected: "dcgm_names_expected.yaml"},
+		{input: "env.yaml", expected: "env_expected.yaml", endpoint: "10.0.0.1:9400"},
+		// the endpoint of the splunk_gpu block has precedence over the environment variable
+		{input: "gpu.yaml", expected: "gpu_expected.yaml", endpoint: "10.0.0.1:9400"},
+		// configs without splunk_gpu are unchanged
+		{input: "env.yaml", expected: "env.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Setenv(gpuEndpointEnv, tt.endpoint)
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "gpu", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "gpu", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupGPU(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupGPUInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "invalid.yaml",
+			expectedErr: "invalid splunk_gpu config",
+		},
+		{
+			input:       "no_exporter.yaml",
+			expectedErr: "splunk_gpu: exporters must be set if no signalfx exporter is configured",
+		},
+		{
+			input:       "pipeline_exists.yaml",
+			expectedErr: "splunk_gpu: service::pipelines::metrics/gpu must not be configured",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Setenv(gpuEndpointEnv, "")
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "gpu", tt.input))
+			require.NoError(t, err)
+			require.ErrorContains(t, SetupGPU(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
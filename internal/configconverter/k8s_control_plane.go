@@ -0,0 +1,303 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/k8sserviceaccount"
+)
+
+const (
+	k8sControlPlaneKey       = "splunk_k8s_control_plane"
+	k8sControlPlaneName      = "k8s_control_plane"
+	k8sControlPlanePipeline  = "metrics/" + k8sControlPlaneName
+	k8sControlPlaneObserver  = "k8s_observer/" + k8sControlPlaneName
+	k8sControlPlaneKubelet   = "kubeletstats/" + k8sControlPlaneName
+	k8sControlPlaneAPIServer = "prometheus_simple/k8s_api_server"
+	k8sControlPlaneCreator   = "receiver_creator/" + k8sControlPlaneName
+)
+
+var (
+	defaultK8sGetenv = os.Getenv
+	// serviceAccountDir and k8sGetenv are overridden in tests.
+	serviceAccountDir = k8sserviceaccount.DefaultDir
+	k8sGetenv         = defaultK8sGetenv
+)
+
+type k8sControlPlaneConfig struct {
+	NodeName           string                      `mapstructure:"node_name"`
+	CollectionInterval string                      `mapstructure:"collection_interval"`
+	Exporters          []string                    `mapstructure:"exporters"`
+	Processors         []string                    `mapstructure:"processors"`
+	Kubelet            k8sControlPlaneTargetConfig `mapstructure:"kubelet"`
+	APIServer          k8sControlPlaneTargetConfig `mapstructure:"api_server"`
+	ControllerManager  k8sControlPlaneTargetConfig `mapstructure:"controller_manager"`
+	Scheduler          k8sControlPlaneTargetConfig `mapstructure:"scheduler"`
+	Etcd               k8sControlPlaneEtcdConfig   `mapstructure:"etcd"`
+}
+
+// k8sControlPlaneTargetConfig is a scraped control plane component. The kubelet endpoint is
+// derived from the node name and port and the API server one from the pod environment unless
+// set. The components discovered as pods are scraped on their port.
+type k8sControlPlaneTargetConfig struct {
+	Endpoint           string `mapstructure:"endpoint"`
+	Port               int    `mapstructure:"port"`
+	Enabled            bool   `mapstructure:"enabled"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+type k8sControlPlaneEtcdConfig struct {
+	CAFile   string `mapstructure:"ca_file"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	Port     int    `mapstructure:"port"`
+	Enabled  bool   `mapstructure:"enabled"`
+}
+
+// defaultK8sControlPlaneConfig scrapes the kubelet of the node and the control plane pods
+// running on it. The API server is scraped by a single collector, usually the cluster
+// receiver deployment, so it's disabled by default. The kubelet, controller manager and
+// scheduler serving certificates are self-signed unless the cluster is set up otherwise,
+// and the etcd client certificates are where kubeadm creates them.
+func defaultK8sControlPlaneConfig() k8sControlPlaneConfig {
+	return k8sControlPlaneConfig{
+		NodeName:           k8sGetenv("K8S_NODE_NAME"),
+		CollectionInterval: "10s",
+		Kubelet:            k8sControlPlaneTargetConfig{Enabled: true, Port: 10250, InsecureSkipVerify: true},
+		APIServer:          k8sControlPlaneTargetConfig{},
+		ControllerManager:  k8sControlPlaneTargetConfig{Enabled: true, Port: 10257, InsecureSkipVerify: true},
+		Scheduler:          k8sControlPlaneTargetConfig{Enabled: true, Port: 10259, InsecureSkipVerify: true},
+		Etcd: k8sControlPlaneEtcdConfig{
+			Enabled:  true,
+			Port:     2379,
+			CAFile:   "/etc/kubernetes/pki/etcd/ca.crt",
+			CertFile: "/etc/kubernetes/pki/etcd/healthcheck-client.crt",
+			KeyFile:  "/etc/kubernetes/pki/etcd/healthcheck-client.key",
+		},
+	}
+}
+
+// SetupK8sControlPlane applies the distribution level `splunk_k8s_control_plane` preset and removes
+// it from the config. It scrapes the kubelet stats of the node and the metrics of the API server,
+// controller manager, scheduler and etcd with the credentials of the collector's service account,
+// from a metrics/k8s_control_plane pipeline exporting to the configured exporters, or all signalfx
+// exporters. The control plane components other than the API server are discovered as the pods
+// of the node named by node_name, or the K8S_NODE_NAME environment variable, with a k8s_observer
+// and a receiver_creator, so the preset can be enabled for every node of a cluster.
+func SetupK8sControlPlane(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(k8sControlPlaneKey) {
+		return nil
+	}
+
+	cfg := defaultK8sControlPlaneConfig()
+	preset, err := in.Sub(k8sControlPlaneKey)
+	if err != nil {
+		return err
+	}
+	if err = preset.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", k8sControlPlaneKey, err)
+	}
+
+	out := in.ToStringMap()
+	delete(out, k8sControlPlaneKey)
+
+	creds, err := k8sserviceaccount.Load(serviceAccountDir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", k8sControlPlaneKey, err)
+	}
+	discovered := cfg.ControllerManager.Enabled || cfg.Scheduler.Enabled || cfg.Etcd.Enabled
+	if cfg.NodeName == "" && (cfg.Kubelet.Enabled || discovered) {
+		return fmt.Errorf("%s: node_name or the K8S_NODE_NAME environment variable must be set", k8sControlPlaneKey)
+	}
+
+	service, _ := out["service"].(map[string]any)
+	if service == nil {
+		service = map[string]any{}
+		out["service"] = service
+	}
+	pipelines, _ := service["pipelines"].(map[string]any)
+	if pipelines == nil {
+		pipelines = map[string]any{}
+		service["pipelines"] = pipelines
+	}
+	if _, ok := pipelines[k8sControlPlanePipeline]; ok {
+		return errors.New(k8sControlPlaneKey + ": service::pipelines::" + k8sControlPlanePipeline + " must not be configured")
+	}
+	exporters, err := presetExporters(k8sControlPlaneKey, cfg.Exporters, out)
+	if err != nil {
+		return err
+	}
+
+	receivers := ensureMap(out, "receivers")
+	var pipelineReceivers []any
+	if cfg.Kubelet.Enabled {
+		endpoint := cfg.Kubelet.Endpoint
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("%s:%d", cfg.NodeName, cfg.Kubelet.Port)
+		}
+		receivers[k8sControlPlaneKubelet] = map[string]any{
+			"auth_type":            "serviceAccount",
+			"collection_interval":  cfg.CollectionInterval,
+			"endpoint":             endpoint,
+			"insecure_skip_verify": cfg.Kubelet.InsecureSkipVerify,
+		}
+		pipelineReceivers = append(pipelineReceivers, k8sControlPlaneKubelet)
+	}
+	if cfg.APIServer.Enabled {
+		endpoint := cfg.APIServer.Endpoint
+		if endpoint == "" {
+			if endpoint, err = k8sserviceaccount.APIServerEndpoint(k8sGetenv); err != nil {
+				return fmt.Errorf("%s: %w", k8sControlPlaneKey, err)
+			}
+		}
+		receivers[k8sControlPlaneAPIServer] = serviceAccountScrapeConfig(endpoint, cfg.CollectionInterval, map[string]any{
+			"ca_file":              creds.CAFile,
+			"insecure_skip_verify": cfg.APIServer.InsecureSkipVerify,
+		})
+		pipelineReceivers = append(pipelineReceivers, k8sControlPlaneAPIServer)
+	}
+	if discovered {
+		receivers[k8sControlPlaneCreator] = map[string]any{
+			"watch_observers": []any{k8sControlPlaneObserver},
+			"receivers":       cfg.discoveredReceivers(creds),
+		}
+		pipelineReceivers = append(pipelineReceivers, k8sControlPlaneCreator)
+
+		ensureMap(out, "extensions")[k8sControlPlaneObserver] = map[string]any{
+			"auth_type":     "serviceAccount",
+			"node":          cfg.NodeName,
+			"observe_pods":  true,
+			"observe_nodes": false,
+		}
+		serviceExtensions, _ := service["extensions"].([]any)
+		service["extensions"] = append(serviceExtensions, k8sControlPlaneObserver)
+	}
+	if len(pipelineReceivers) == 0 {
+		return fmt.Errorf("%s: at least one control plane component must be enabled", k8sControlPlaneKey)
+	}
+
+	pipeline := map[string]any{
+		"receivers": pipelineReceivers,
+		"exporters": exporters,
+	}
+	if len(cfg.Processors) > 0 {
+		pipeline["processors"], _ = toAnySlice(cfg.Processors)
+	}
+	pipelines[k8sControlPlanePipeline] = pipeline
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// discoveredReceivers returns the receiver_creator receivers of the control plane components
+// running as static pods labeled with their component name, as kubeadm and most distributions
+// deploy them.
+func (cfg k8sControlPlaneConfig) discoveredReceivers(creds k8sserviceaccount.Credentials) map[string]any {
+	receivers := map[string]any{}
+	for _, target := range []struct {
+		name      string
+		component string
+		config    k8sControlPlaneTargetConfig
+	}{
+		{name: "controller_manager", component: "kube-controller-manager", config: cfg.ControllerManager},
+		{name: "scheduler", component: "kube-scheduler", config: cfg.Scheduler},
+	} {
+		if !target.config.Enabled {
+			continue
+		}
+		tls := map[string]any{"insecure_skip_verify": target.config.InsecureSkipVerify}
+		if !target.config.InsecureSkipVerify {
+			tls["ca_file"] = creds.CAFile
+		}
+		receivers["prometheus_simple/"+target.name] = map[string]any{
+			"rule":   podRule(target.component),
+			"config": serviceAccountScrapeConfig(fmt.Sprintf("`endpoint`:%d", target.config.Port), cfg.CollectionInterval, tls),
+		}
+	}
+	if cfg.Etcd.Enabled {
+		receivers["prometheus_simple/etcd"] = map[string]any{
+			"rule": podRule("etcd"),
+			"config": map[string]any{
+				"endpoint":            fmt.Sprintf("`endpoint`:%d", cfg.Etcd.Port),
+				"collection_interval": cfg.CollectionInterval,
+				"tls": map[string]any{
+					"insecure":  false,
+					"ca_file":   cfg.Etcd.CAFile,
+					"cert_file": cfg.Etcd.CertFile,
+					"key_file":  cfg.Etcd.KeyFile,
+				},
+			},
+		}
+	}
+	return receivers
+}
+
+func podRule(component string) string {
+	return fmt.Sprintf(`type == "pod" && labels["component"] == %q`, component)
+}
+
+// serviceAccountScrapeConfig returns the prometheus_simple config scraping an https endpoint
+// with the service account bearer token.
+func serviceAccountScrapeConfig(endpoint, collectionInterval string, tls map[string]any) map[string]any {
+	tls["insecure"] = false
+	return map[string]any{
+		"endpoint":            endpoint,
+		"collection_interval": collectionInterval,
+		"use_service_account": true,
+		"tls":                 tls,
+	}
+}
+
+// presetExporters returns the configured exporters of a preset pipeline, or all signalfx
+// exporters if none are.
+func presetExporters(presetKey string, configured []string, out map[string]any) ([]any, error) {
+	exporters, _ := out["exporters"].(map[string]any)
+	if len(configured) > 0 {
+		for _, id := range configured {
+			if _, ok := exporters[id]; !ok {
+				return nil, fmt.Errorf("%s::exporters contains unknown exporter %q", presetKey, id)
+			}
+		}
+		return toAnySlice(configured)
+	}
+	var signalfxExporters []string
+	for id := range exporters {
+		if typ, _, _ := strings.Cut(id, "/"); typ == "signalfx" {
+			signalfxExporters = append(signalfxExporters, id)
+		}
+	}
+	if len(signalfxExporters) == 0 {
+		return nil, fmt.Errorf("%s: exporters must be set if no signalfx exporter is configured", presetKey)
+	}
+	sort.Strings(signalfxExporters)
+	return toAnySlice(signalfxExporters)
+}
+
+func ensureMap(out map[string]any, key string) map[string]any {
+	m, _ := out[key].(map[string]any)
+	if m == nil {
+		m = map[string]any{}
+		out[key] = m
+	}
+	return m
+}
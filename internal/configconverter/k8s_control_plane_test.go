@@ -0,0 +1,130 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/k8sserviceaccount"
+)
+
+func setupK8sEnvironment(t *testing.T, env map[string]string) {
+	serviceAccountDir = "testdata/k8s_control_plane/serviceaccount"
+	k8sGetenv = func(key string) string { return env[key] }
+	t.Cleanup(func() {
+		serviceAccountDir = k8sserviceaccount.DefaultDir
+		k8sGetenv = defaultK8sGetenv
+	})
+}
+
+func TestSetupK8sControlPlane(t *testing.T) {
+	setupK8sEnvironment(t, map[string]string{
+		"K8S_NODE_NAME":           "node-1",
+		"KUBERNETES_SERVICE_HOST": "10.96.0.1",
+		"KUBERNETES_SERVICE_PORT": "443",
+	})
+
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "control_plane.yaml", expected: "control_plane_expected.yaml"},
+		{input: "api_server.yaml", expected: "api_server_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf("testdata/k8s_control_plane/" + tt.expected)
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf("testdata/k8s_control_plane/" + tt.input)
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupK8sControlPlane(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupK8sControlPlaneNoop(t *testing.T) {
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/k8s_control_plane/control_plane_expected.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/k8s_control_plane/control_plane_expected.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, SetupK8sControlPlane(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+}
+
+func TestSetupK8sControlPlaneInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		env         map[string]string
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "control_plane.yaml",
+			expectedErr: "splunk_k8s_control_plane: node_name or the K8S_NODE_NAME environment variable must be set",
+		},
+		{
+			env:         map[string]string{"K8S_NODE_NAME": "node-1"},
+			input:       "api_server.yaml",
+			expectedErr: "splunk_k8s_control_plane: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set",
+		},
+		{
+			env:         map[string]string{"K8S_NODE_NAME": "node-1"},
+			input:       "no_exporter.yaml",
+			expectedErr: "splunk_k8s_control_plane: exporters must be set if no signalfx exporter is configured",
+		},
+		{
+			env:         map[string]string{"K8S_NODE_NAME": "node-1"},
+			input:       "unknown_exporter.yaml",
+			expectedErr: `splunk_k8s_control_plane::exporters contains unknown exporter "signalfx/other"`,
+		},
+		{
+			env:         map[string]string{"K8S_NODE_NAME": "node-1"},
+			input:       "pipeline_exists.yaml",
+			expectedErr: "splunk_k8s_control_plane: service::pipelines::metrics/k8s_control_plane must not be configured",
+		},
+		{
+			input:       "all_disabled.yaml",
+			expectedErr: "splunk_k8s_control_plane: at least one control plane component must be enabled",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			setupK8sEnvironment(t, tt.env)
+			cfgMap, err := confmaptest.LoadConf("testdata/k8s_control_plane/" + tt.input)
+			require.NoError(t, err)
+			require.EqualError(t, SetupK8sControlPlane(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
+
+func TestSetupK8sControlPlaneWithoutServiceAccount(t *testing.T) {
+	setupK8sEnvironment(t, map[string]string{"K8S_NODE_NAME": "node-1"})
+	serviceAccountDir = t.TempDir()
+
+	cfgMap, err := confmaptest.LoadConf("testdata/k8s_control_plane/control_plane.yaml")
+	require.NoError(t, err)
+	require.ErrorContains(t, SetupK8sControlPlane(context.Background(), cfgMap), "splunk_k8s_control_plane: service account file unavailable")
+}
@@ -0,0 +1,216 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
+)
+
+const (
+	mirrorKey  = "splunk_mirror"
+	mirrorName = "splunk_mirror"
+)
+
+// mirrorEndpointKeys are the exporter settings whose realm endpoints are mirrored to the mirror realm.
+var mirrorEndpointKeys = []string{"endpoint", "ingest_url", "api_url", "traces_endpoint", "metrics_endpoint", "logs_endpoint"}
+
+type mirrorConfig struct {
+	Realm       string   `mapstructure:"realm"`
+	AccessToken string   `mapstructure:"access_token"`
+	Pipelines   []string `mapstructure:"pipelines"`
+	Exporters   []string `mapstructure:"exporters"`
+}
+
+// SetupMirror applies the distribution level `splunk_mirror` settings and removes them from the config.
+// The Splunk exporters of each of the pipelines are mirrored by an exporter with the same settings,
+// sending to the mirror realm with the mirror access token through its own sending queue. The pipeline
+// then exports to a fanout connector, sending to a pipeline with the original exporters, whose failures
+// are returned to the receivers, and to a pipeline with the mirror exporters, whose failures are only
+// logged. This keeps the mirrored exporters in sync with the original ones during org migrations.
+func SetupMirror(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(mirrorKey) {
+		return nil
+	}
+
+	var cfg mirrorConfig
+	mirrorSettings, err := in.Sub(mirrorKey)
+	if err != nil {
+		return err
+	}
+	if err = mirrorSettings.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", mirrorKey, err)
+	}
+	if cfg.AccessToken == "" {
+		return fmt.Errorf("%s::access_token must not be empty", mirrorKey)
+	}
+	if len(cfg.Pipelines) == 0 {
+		return fmt.Errorf("%s::pipelines must not be empty", mirrorKey)
+	}
+	mirrored := map[string]struct{}{}
+	for _, id := range cfg.Exporters {
+		mirrored[id] = struct{}{}
+	}
+
+	out := in.ToStringMap()
+	delete(out, mirrorKey)
+	exporters, _ := out["exporters"].(map[string]any)
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	connectors := ensureMap(out, "connectors")
+
+	for _, id := range cfg.Pipelines {
+		pipeline, _ := pipelines[id].(map[string]any)
+		if pipeline == nil {
+			return fmt.Errorf("%s: pipeline %q isn't configured", mirrorKey, id)
+		}
+		pipelineExporters, err := toAnySlice(pipeline["exporters"])
+		if err != nil {
+			return fmt.Errorf("%s: invalid exporters of pipeline %q: %w", mirrorKey, id, err)
+		}
+		var mirrorExporters []any
+		for _, e := range pipelineExporters {
+			expID, _ := e.(string)
+			typ, _, _ := strings.Cut(expID, "/")
+			if _, ok := httpExporterTypes[typ]; !ok {
+				continue
+			}
+			if _, ok := mirrored[expID]; len(mirrored) > 0 && !ok {
+				continue
+			}
+			mirrorID := mirrorExporterID(expID)
+			if _, ok := exporters[mirrorID]; !ok {
+				expCfg, _ := exporters[expID].(map[string]any)
+				mirrorCfg, err := mirrorExporterConfig(typ, expCfg, cfg)
+				if err != nil {
+					return fmt.Errorf("%s: exporter %q: %w", mirrorKey, expID, err)
+				}
+				exporters[mirrorID] = mirrorCfg
+			}
+			mirrorExporters = append(mirrorExporters, mirrorID)
+		}
+		if len(mirrorExporters) == 0 {
+			return fmt.Errorf("%s: pipeline %q has no exporter to mirror", mirrorKey, id)
+		}
+
+		signal, name, _ := strings.Cut(id, "/")
+		suffix := mirrorName
+		if name != "" {
+			suffix = name + "_" + mirrorName
+		}
+		primaryPipeline := signal + "/" + suffix + "_primary"
+		mirrorPipeline := signal + "/" + suffix
+		connector := "fanout/" + mirrorName + "_" + strings.ReplaceAll(id, "/", "_")
+		for _, p := range []string{primaryPipeline, mirrorPipeline} {
+			if _, ok := pipelines[p]; ok {
+				return fmt.Errorf("%s: service::pipelines::%s must not be configured", mirrorKey, p)
+			}
+		}
+		connectors[connector] = map[string]any{
+			"pipelines":           []any{primaryPipeline},
+			"secondary_pipelines": []any{mirrorPipeline},
+		}
+		pipelines[primaryPipeline] = map[string]any{
+			"receivers": []any{connector},
+			"exporters": pipelineExporters,
+		}
+		pipelines[mirrorPipeline] = map[string]any{
+			"receivers": []any{connector},
+			"exporters": mirrorExporters,
+		}
+		pipeline["exporters"] = []any{connector}
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// mirrorExporterID returns the ID of the exporter mirroring the exporter, e.g. signalfx/splunk_mirror
+// for signalfx and signalfx/us0_splunk_mirror for signalfx/us0.
+func mirrorExporterID(id string) string {
+	typ, name, _ := strings.Cut(id, "/")
+	if name == "" {
+		return typ + "/" + mirrorName
+	}
+	return typ + "/" + name + "_" + mirrorName
+}
+
+// mirrorExporterConfig returns the config of the exporter sending to the mirror realm with the
+// mirror access token. Endpoints that aren't realm endpoints can't be mirrored to another realm.
+func mirrorExporterConfig(typ string, expCfg map[string]any, cfg mirrorConfig) (map[string]any, error) {
+	mirrorCfg, _ := deepCopy(expCfg).(map[string]any)
+	if mirrorCfg == nil {
+		mirrorCfg = map[string]any{}
+	}
+	if cfg.Realm != "" {
+		if _, ok := mirrorCfg["realm"]; ok {
+			mirrorCfg["realm"] = cfg.Realm
+		}
+		for _, key := range mirrorEndpointKeys {
+			endpoint, _ := mirrorCfg[key].(string)
+			if endpoint == "" {
+				continue
+			}
+			mirrorEndpoint, ok := realm.ReplaceEndpointRealm(endpoint, cfg.Realm)
+			if !ok {
+				return nil, fmt.Errorf("%s %q isn't a realm endpoint", key, endpoint)
+			}
+			mirrorCfg[key] = mirrorEndpoint
+		}
+	}
+	switch typ {
+	case "otlphttp":
+		headers, _ := mirrorCfg["headers"].(map[string]any)
+		if headers == nil {
+			headers = map[string]any{}
+			mirrorCfg["headers"] = headers
+		}
+		headers["X-SF-Token"] = cfg.AccessToken
+	case "splunk_hec":
+		mirrorCfg["token"] = cfg.AccessToken
+	default:
+		mirrorCfg["access_token"] = cfg.AccessToken
+	}
+	queue, _ := mirrorCfg["sending_queue"].(map[string]any)
+	if queue == nil {
+		queue = map[string]any{}
+		mirrorCfg["sending_queue"] = queue
+	}
+	queue["enabled"] = true
+	return mirrorCfg, nil
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = deepCopy(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = deepCopy(e)
+		}
+		return out
+	}
+	return v
+}
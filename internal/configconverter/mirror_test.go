@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupMirror(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "mirror.yaml", expected: "mirror_expected.yaml"},
+		{input: "same_realm.yaml", expected: "same_realm_expected.yaml"},
+		// configs without splunk_mirror are unchanged
+		{input: "mirror_expected.yaml", expected: "mirror_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "mirror", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "mirror", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupMirror(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupMirrorInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "not_realm_endpoint.yaml",
+			expectedErr: `splunk_mirror: exporter "otlphttp": endpoint "http://gateway:4318" isn't a realm endpoint`,
+		},
+		{
+			input:       "no_exporter.yaml",
+			expectedErr: `splunk_mirror: pipeline "logs" has no exporter to mirror`,
+		},
+		{
+			input:       "unknown_pipeline.yaml",
+			expectedErr: `splunk_mirror: pipeline "metrics/missing" isn't configured`,
+		},
+		{
+			input:       "no_token.yaml",
+			expectedErr: "splunk_mirror::access_token must not be empty",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "mirror", tt.input))
+			require.NoError(t, err)
+			require.EqualError(t, SetupMirror(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
+
+func TestMirrorExporterID(t *testing.T) {
+	assert.Equal(t, "signalfx/splunk_mirror", mirrorExporterID("signalfx"))
+	assert.Equal(t, "signalfx/us0_splunk_mirror", mirrorExporterID("signalfx/us0"))
+}
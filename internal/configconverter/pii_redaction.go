@@ -0,0 +1,86 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+const piiRedactionProcessor = "pii_redaction/splunk"
+
+var piiRedactionFG = featuregate.GlobalRegistry().MustRegister(
+	"splunk.piiRedaction",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, credit card numbers, email addresses and tokens are redacted from the log records of all logs pipelines."),
+	featuregate.WithRegisterFromVersion("v0.112.0"),
+)
+
+// SetupPIIRedaction adds a pii_redaction/splunk processor to every logs pipeline after its
+// memory_limiter processors when the splunk.piiRedaction feature gate is enabled. The processor
+// has the default settings unless a pii_redaction/splunk processor is already configured, e.g.
+// with custom detectors. Pipelines that already have the processor are left as is.
+func SetupPIIRedaction(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !piiRedactionFG.IsEnabled() {
+		return nil
+	}
+
+	out := in.ToStringMap()
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	added := false
+	for id, p := range pipelines {
+		if typ, _, _ := strings.Cut(id, "/"); typ != "logs" {
+			continue
+		}
+		pipeline, _ := p.(map[string]any)
+		if pipeline == nil {
+			continue
+		}
+		pipelineProcessors, err := processorsOf(pipeline)
+		if err != nil {
+			return fmt.Errorf("splunk.piiRedaction: invalid processors of pipeline %q: %w", id, err)
+		}
+		if contains(pipelineProcessors, piiRedactionProcessor) {
+			continue
+		}
+		pipeline["processors"] = insertAfterMemoryLimiters(pipelineProcessors, piiRedactionProcessor)
+		added = true
+	}
+	if !added {
+		return nil
+	}
+
+	processors := ensureMap(out, "processors")
+	if _, ok := processors[piiRedactionProcessor]; !ok {
+		processors[piiRedactionProcessor] = nil
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+func contains(s []any, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+func enablePIIRedaction(t *testing.T) {
+	require.NoError(t, featuregate.GlobalRegistry().Set(piiRedactionFG.ID(), true))
+	t.Cleanup(func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(piiRedactionFG.ID(), false))
+	})
+}
+
+func TestSetupPIIRedaction(t *testing.T) {
+	enablePIIRedaction(t)
+
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "logs.yaml", expected: "logs_expected.yaml"},
+		{input: "custom_processor.yaml", expected: "custom_processor_expected.yaml"},
+		{input: "logs_expected.yaml", expected: "logs_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf("testdata/pii_redaction/" + tt.expected)
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf("testdata/pii_redaction/" + tt.input)
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupPIIRedaction(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupPIIRedactionDisabled(t *testing.T) {
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/pii_redaction/logs.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/pii_redaction/logs.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, SetupPIIRedaction(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+}
+
+func TestSetupPIIRedactionInvalid(t *testing.T) {
+	enablePIIRedaction(t)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/pii_redaction/invalid_processors.yaml")
+	require.NoError(t, err)
+	require.EqualError(t, SetupPIIRedaction(context.Background(), cfgMap),
+		`splunk.piiRedaction: invalid processors of pipeline "logs": unexpected form string`)
+}
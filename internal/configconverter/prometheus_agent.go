@@ -0,0 +1,127 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	prometheusAgentKey  = "splunk_prometheus_agent"
+	prometheusAgentName = "splunk_prometheus_agent"
+)
+
+type prometheusAgentConfig struct {
+	// Global are the global settings of the Prometheus config.
+	Global map[string]any `mapstructure:"global"`
+	// ConfigFile is the path of a Prometheus config file whose global settings and scrape configs are used.
+	ConfigFile string `mapstructure:"config_file"`
+	// ScrapeConfigs are the scrape configs of the Prometheus config.
+	ScrapeConfigs []any `mapstructure:"scrape_configs"`
+	// Processors are the processors of the pipeline of the scraped metrics.
+	Processors []any `mapstructure:"processors"`
+	// Exporters are the exporters of the pipeline of the scraped metrics.
+	Exporters []any `mapstructure:"exporters"`
+}
+
+// SetupPrometheusAgent applies the distribution level `splunk_prometheus_agent` settings and removes them
+// from the config. The scrape configs of a Prometheus Agent, inline or read from its config file, are
+// scraped by a prometheus/splunk_prometheus_agent receiver, with the Prometheus service discovery and
+// relabeling, and the scraped metrics are sent to the exporters by a metrics/splunk_prometheus_agent
+// pipeline. This lets the collector replace Prometheus Agents remote writing to it. The other sections
+// of the config file, like remote_write and rule_files, are ignored.
+func SetupPrometheusAgent(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(prometheusAgentKey) {
+		return nil
+	}
+
+	var cfg prometheusAgentConfig
+	settings, err := in.Sub(prometheusAgentKey)
+	if err != nil {
+		return err
+	}
+	if err = settings.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", prometheusAgentKey, err)
+	}
+	if len(cfg.Exporters) == 0 {
+		return fmt.Errorf("%s::exporters must not be empty", prometheusAgentKey)
+	}
+	switch {
+	case cfg.ConfigFile != "" && (len(cfg.ScrapeConfigs) > 0 || cfg.Global != nil):
+		return fmt.Errorf("%s: config_file can't be specified with global or scrape_configs", prometheusAgentKey)
+	case cfg.ConfigFile != "":
+		if cfg.Global, cfg.ScrapeConfigs, err = readPrometheusConfigFile(cfg.ConfigFile); err != nil {
+			return fmt.Errorf("%s: %w", prometheusAgentKey, err)
+		}
+	}
+	if len(cfg.ScrapeConfigs) == 0 {
+		return fmt.Errorf("%s: no scrape_configs specified", prometheusAgentKey)
+	}
+
+	out := in.ToStringMap()
+	delete(out, prometheusAgentKey)
+	receiverID := "prometheus/" + prometheusAgentName
+	pipelineID := "metrics/" + prometheusAgentName
+	receivers := ensureMap(out, "receivers")
+	if _, ok := receivers[receiverID]; ok {
+		return fmt.Errorf("%s: receivers::%s must not be configured", prometheusAgentKey, receiverID)
+	}
+	pipelines := ensureMap(ensureMap(out, "service"), "pipelines")
+	if _, ok := pipelines[pipelineID]; ok {
+		return fmt.Errorf("%s: service::pipelines::%s must not be configured", prometheusAgentKey, pipelineID)
+	}
+
+	promConfig := map[string]any{"scrape_configs": cfg.ScrapeConfigs}
+	if cfg.Global != nil {
+		promConfig["global"] = cfg.Global
+	}
+	receivers[receiverID] = map[string]any{"config": promConfig}
+	pipeline := map[string]any{
+		"receivers": []any{receiverID},
+		"exporters": cfg.Exporters,
+	}
+	if len(cfg.Processors) > 0 {
+		pipeline["processors"] = cfg.Processors
+	}
+	pipelines[pipelineID] = pipeline
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// readPrometheusConfigFile returns the global settings and scrape configs of a Prometheus config file.
+func readPrometheusConfigFile(path string) (map[string]any, []any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config_file: %w", err)
+	}
+	var promConfig struct {
+		Global            map[string]any `yaml:"global"`
+		ScrapeConfigs     []any          `yaml:"scrape_configs"`
+		ScrapeConfigFiles []string       `yaml:"scrape_config_files"`
+	}
+	if err = yaml.Unmarshal(content, &promConfig); err != nil {
+		return nil, nil, fmt.Errorf("invalid config_file %q: %w", path, err)
+	}
+	if len(promConfig.ScrapeConfigFiles) > 0 {
+		return nil, nil, fmt.Errorf("config_file %q: scrape_config_files aren't supported", path)
+	}
+	return promConfig.Global, promConfig.ScrapeConfigs, nil
+}
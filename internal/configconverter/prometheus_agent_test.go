@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupPrometheusAgent(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "config_file.yaml", expected: "config_file_expected.yaml"},
+		{input: "inline.yaml", expected: "inline_expected.yaml"},
+		// configs without splunk_prometheus_agent are unchanged
+		{input: "inline_expected.yaml", expected: "inline_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "prometheus_agent", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "prometheus_agent", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupPrometheusAgent(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupPrometheusAgentInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "no_exporters.yaml",
+			expectedErr: "splunk_prometheus_agent::exporters must not be empty",
+		},
+		{
+			input:       "both.yaml",
+			expectedErr: "splunk_prometheus_agent: config_file can't be specified with global or scrape_configs",
+		},
+		{
+			input:       "no_scrape_configs.yaml",
+			expectedErr: "splunk_prometheus_agent: no scrape_configs specified",
+		},
+		{
+			input:       "missing_file.yaml",
+			expectedErr: "splunk_prometheus_agent: failed to read config_file: open testdata/prometheus_agent/missing.yml: no such file or directory",
+		},
+		{
+			input:       "scrape_config_files.yaml",
+			expectedErr: `splunk_prometheus_agent: config_file "testdata/prometheus_agent/scrape_config_files.yml": scrape_config_files aren't supported`,
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "prometheus_agent", tt.input))
+			require.NoError(t, err)
+			require.EqualError(t, SetupPrometheusAgent(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
+)
+
+const proxyKey = "splunk_proxy"
+
+// httpExporterTypes are the exporter types whose clients are configured with
+// confighttp.ClientConfig and accept a proxy_url.
+var httpExporterTypes = map[string]struct{}{
+	"otlphttp":   {},
+	"sapm":       {},
+	"signalfx":   {},
+	"splunk_hec": {},
+}
+
+var (
+	defaultSetenv = os.Setenv
+	// setenv is overridden in tests.
+	setenv = defaultSetenv
+)
+
+type proxyConfig struct {
+	Exporters  map[string]exporterProxyConfig `mapstructure:"exporters"`
+	HTTPProxy  string                         `mapstructure:"http_proxy"`
+	HTTPSProxy string                         `mapstructure:"https_proxy"`
+	NoProxy    string                         `mapstructure:"no_proxy"`
+}
+
+type exporterProxyConfig struct {
+	ProxyURL string `mapstructure:"proxy_url"`
+	Disabled bool   `mapstructure:"disabled"`
+}
+
+// SetupProxy applies the distribution level `splunk_proxy` settings and removes them from the config.
+// Every confighttp based exporter without its own proxy_url has one set from http_proxy or https_proxy,
+// depending on its endpoint's scheme, unless its endpoint host matches no_proxy. Entries in
+// `splunk_proxy::exporters` replace the proxy of individual exporters or disable it. The settings are
+// also exported as the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, which configgrpc
+// clients and other components without a proxy setting resolve their proxy from.
+func SetupProxy(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(proxyKey) {
+		return nil
+	}
+
+	var cfg proxyConfig
+	proxySettings, err := in.Sub(proxyKey)
+	if err != nil {
+		return err
+	}
+	if err = proxySettings.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", proxyKey, err)
+	}
+
+	out := in.ToStringMap()
+	delete(out, proxyKey)
+
+	exporters, _ := out["exporters"].(map[string]any)
+	for id := range cfg.Exporters {
+		if _, ok := exporters[id]; !ok {
+			return fmt.Errorf("%s::exporters contains unknown exporter %q", proxyKey, id)
+		}
+		if !isHTTPExporter(id) {
+			return fmt.Errorf("%s::exporters: exporter %q doesn't support a proxy_url", proxyKey, id)
+		}
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  cfg.HTTPProxy,
+		HTTPSProxy: cfg.HTTPSProxy,
+		NoProxy:    cfg.NoProxy,
+	}).ProxyFunc()
+	for id, exporter := range exporters {
+		if !isHTTPExporter(id) {
+			continue
+		}
+		expCfg, _ := exporter.(map[string]any)
+		if expCfg == nil {
+			expCfg = map[string]any{}
+		}
+		if _, ok := expCfg["proxy_url"]; ok {
+			continue
+		}
+		proxyURL, err := exporterProxyURL(expCfg, cfg.Exporters[id], proxyFunc)
+		if err != nil {
+			return fmt.Errorf("failed determining the proxy of exporter %q: %w", id, err)
+		}
+		if proxyURL != "" {
+			expCfg["proxy_url"] = proxyURL
+			exporters[id] = expCfg
+		}
+	}
+
+	for env, val := range map[string]string{
+		"HTTP_PROXY":  cfg.HTTPProxy,
+		"HTTPS_PROXY": cfg.HTTPSProxy,
+		"NO_PROXY":    cfg.NoProxy,
+	} {
+		if val == "" {
+			continue
+		}
+		if err = setenv(env, val); err != nil {
+			return fmt.Errorf("failed setting %s: %w", env, err)
+		}
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+func isHTTPExporter(id string) bool {
+	typ, _, _ := strings.Cut(id, "/")
+	_, ok := httpExporterTypes[typ]
+	return ok
+}
+
+func exporterProxyURL(expCfg map[string]any, override exporterProxyConfig, proxyFunc func(*url.URL) (*url.URL, error)) (string, error) {
+	switch {
+	case override.Disabled:
+		return "", nil
+	case override.ProxyURL != "":
+		return override.ProxyURL, nil
+	}
+	endpoint, err := exporterEndpoint(expCfg)
+	if err != nil {
+		return "", err
+	}
+	proxy, err := proxyFunc(endpoint)
+	if err != nil || proxy == nil {
+		return "", err
+	}
+	return proxy.String(), nil
+}
+
+// exporterEndpoint returns the endpoint the exporter sends data to. Endpoints without a scheme
+// and exporters without a known endpoint are treated as https.
+func exporterEndpoint(expCfg map[string]any) (*url.URL, error) {
+	endpoint, _ := expCfg["endpoint"].(string)
+	if endpoint == "" {
+		endpoint, _ = expCfg["ingest_url"].(string)
+	}
+	if r, ok := expCfg["realm"].(string); ok && endpoint == "" && r != "" {
+		endpoint = realm.ForRealm(r).Ingest
+	}
+	if endpoint == "" {
+		return &url.URL{Scheme: "https"}, nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	return url.Parse(endpoint)
+}
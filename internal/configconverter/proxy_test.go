@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func recordSetenv(t *testing.T) map[string]string {
+	env := map[string]string{}
+	setenv = func(key, value string) error {
+		env[key] = value
+		return nil
+	}
+	t.Cleanup(func() { setenv = defaultSetenv })
+	return env
+}
+
+func TestSetupProxy(t *testing.T) {
+	env := recordSetenv(t)
+
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/proxy/proxy_expected.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/proxy/proxy.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, SetupProxy(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+	assert.Equal(t, map[string]string{
+		"HTTP_PROXY":  "http://proxy.internal:3128",
+		"HTTPS_PROXY": "http://secure-proxy.internal:3129",
+		"NO_PROXY":    ".corp.example.com,10.0.0.0/8",
+	}, env)
+}
+
+func TestSetupProxyNoop(t *testing.T) {
+	env := recordSetenv(t)
+
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/proxy/no_proxy_settings.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/proxy/no_proxy_settings.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, SetupProxy(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+	assert.Empty(t, env)
+}
+
+func TestSetupProxyInvalidOverrides(t *testing.T) {
+	recordSetenv(t)
+
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "testdata/proxy/unknown_exporter.yaml",
+			expectedErr: `splunk_proxy::exporters contains unknown exporter "otlphttp/missing"`,
+		},
+		{
+			input:       "testdata/proxy/grpc_exporter.yaml",
+			expectedErr: `splunk_proxy::exporters: exporter "otlp" doesn't support a proxy_url`,
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(tt.input)
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.EqualError(t, SetupProxy(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"runtime"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrotation"
+)
+
+// goos is the operating system the log output paths are converted for.
+var goos = runtime.GOOS
+
+var logOutputPathsKeys = []string{
+	"service::telemetry::logs::output_paths",
+	"service::telemetry::logs::error_output_paths",
+}
+
+// RotateWindowsLogFiles rewrites the plain file paths of the collector's own log output paths to
+// rotated log files on Windows, which has no logrotate to keep them from filling the disk.
+// Standard streams and output paths with a URL scheme, including already rotated ones, are left as is.
+func RotateWindowsLogFiles(_ context.Context, in *confmap.Conf) error {
+	if in == nil {
+		return fmt.Errorf("cannot RotateWindowsLogFiles on nil *confmap.Conf")
+	}
+	if goos != "windows" {
+		return nil
+	}
+
+	for _, key := range logOutputPathsKeys {
+		paths, ok := in.Get(key).([]any)
+		if !ok {
+			continue // Ignore invalid output paths, as they will be caught by the config validation.
+		}
+		changed := false
+		for i, p := range paths {
+			path, ok := logFilePath(p)
+			if !ok {
+				continue
+			}
+			rotated, err := logrotation.URL(path)
+			if err != nil {
+				return fmt.Errorf("failed converting log output path %q: %w", path, err)
+			}
+			paths[i] = rotated
+			changed = true
+			log.Printf("[INFO] Log output path %q is rotated when it reaches %d MiB, keeping %d rotated files for up to %d days. "+
+				"Set it to %q with other settings to change them.",
+				path, logrotation.DefaultMaxSizeMiB, logrotation.DefaultMaxBackups, logrotation.DefaultMaxAgeDays, rotated)
+		}
+		if !changed {
+			continue
+		}
+		if err := in.Merge(confmap.NewFromStringMap(map[string]any{key: paths})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logFilePath returns the path of the output path if it's a plain file, like zap interprets it.
+func logFilePath(outputPath any) (string, bool) {
+	path, ok := outputPath.(string)
+	if !ok || path == "stdout" || path == "stderr" {
+		return "", false
+	}
+	if filepath.IsAbs(path) {
+		return path, true
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case u.Scheme == "":
+		return path, true
+	case len(u.Scheme) == 1:
+		// Windows drive letter
+		return path, true
+	case u.Scheme == "file" && u.Host == "":
+		return filepath.FromSlash(u.Path), true
+	}
+	return "", false
+}
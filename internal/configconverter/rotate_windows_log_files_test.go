@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestRotateWindowsLogFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test output paths are unix paths")
+	}
+	tests := []struct {
+		name       string
+		goos       string
+		wantOutput string
+	}{
+		{
+			name:       "linux",
+			goos:       "linux",
+			wantOutput: "testdata/rotate_windows_log_files/config.yaml",
+		},
+		{
+			name:       "windows",
+			goos:       "windows",
+			wantOutput: "testdata/rotate_windows_log_files/expected.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultGOOS := goos
+			goos = tt.goos
+			t.Cleanup(func() { goos = defaultGOOS })
+
+			expectedCfgMap, err := confmaptest.LoadConf(tt.wantOutput)
+			require.NoError(t, err)
+
+			cfgMap, err := confmaptest.LoadConf("testdata/rotate_windows_log_files/config.yaml")
+			require.NoError(t, err)
+
+			require.NoError(t, RotateWindowsLogFiles(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
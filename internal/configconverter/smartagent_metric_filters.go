@@ -0,0 +1,421 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+var smartagentReceiverRE = regexp.MustCompile(`^smartagent(/.+)?$`)
+
+// agentMetricFilter is a Smart Agent agent.yaml metricsToExclude or metricsToInclude entry.
+type agentMetricFilter struct {
+	Dimensions  map[string]any `mapstructure:"dimensions"`
+	MetricName  string         `mapstructure:"metricName"`
+	MonitorType string         `mapstructure:"monitorType"`
+	MetricNames []string       `mapstructure:"metricNames"`
+	Negated     bool           `mapstructure:"negated"`
+}
+
+// TranslateSmartAgentMetricFilters rewrites the agent.yaml-style metricsToExclude and metricsToInclude
+// filters of smartagent receivers into an equivalent filter processor prepended to the receiver's metrics
+// pipelines. Filters are only translated when every metrics pipeline using the receiver has no other
+// receivers, since the processor can't tell which receiver produced a datapoint. Otherwise the filters
+// are left in place and applied by the receiver itself. Each translation, or the reason it was skipped,
+// is logged.
+func TranslateSmartAgentMetricFilters(_ context.Context, in *confmap.Conf) error {
+	if in == nil {
+		return fmt.Errorf("cannot TranslateSmartAgentMetricFilters on nil *confmap.Conf")
+	}
+
+	conf := in.ToStringMap()
+	receivers, ok := conf["receivers"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	service, _ := conf["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+
+	changed := false
+	for _, name := range sortedKeys(receivers) {
+		if !smartagentReceiverRE.MatchString(name) {
+			continue
+		}
+		cfg, ok := receivers[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		excludes, hasExcludes := cfg["metricsToExclude"]
+		includes, hasIncludes := cfg["metricsToInclude"]
+		if !hasExcludes && !hasIncludes {
+			continue
+		}
+		receiverPipelines := metricsPipelinesWithReceiver(pipelines, name)
+		if len(receiverPipelines) == 0 {
+			continue // Unused receivers are left to the config validation.
+		}
+
+		monitorType, _ := cfg["type"].(string)
+		condition, err := agentFiltersCondition(excludes, includes, monitorType)
+		if err == nil {
+			err = checkSingleReceiver(pipelines, receiverPipelines, name)
+		}
+		processorName := "filter/" + strings.ReplaceAll(name, "/", "_")
+		processors, _ := conf["processors"].(map[string]any)
+		if _, exists := processors[processorName]; err == nil && exists {
+			err = fmt.Errorf("processor `%s` is already configured", processorName)
+		}
+		if err != nil {
+			log.Printf("[WARNING] `receivers` -> `%s` metricsToExclude and metricsToInclude filters weren't translated "+
+				"to a filter processor and are applied by the receiver: %v", name, err)
+			continue
+		}
+
+		delete(cfg, "metricsToExclude")
+		delete(cfg, "metricsToInclude")
+		changed = true
+		if condition == "" {
+			log.Printf("[INFO] Removed `receivers` -> `%s` metricsToExclude and metricsToInclude filters "+
+				"since none of them apply to the %q monitor.", name, monitorType)
+			continue
+		}
+
+		if processors == nil {
+			processors = map[string]any{}
+			conf["processors"] = processors
+		}
+		processors[processorName] = map[string]any{
+			"error_mode": "ignore",
+			"metrics": map[string]any{
+				"datapoint": []any{condition},
+			},
+		}
+		for _, pipelineName := range receiverPipelines {
+			pipeline := pipelines[pipelineName].(map[string]any)
+			pipelineProcessors, _ := pipeline["processors"].([]any)
+			pipeline["processors"] = append([]any{processorName}, pipelineProcessors...)
+		}
+		log.Printf("[INFO] Translated `receivers` -> `%s` metricsToExclude and metricsToInclude filters to the `%s` "+
+			"processor in the %s pipelines, dropping datapoints matching: %s",
+			name, processorName, strings.Join(receiverPipelines, ", "), condition)
+	}
+
+	if changed {
+		// removed filters must not be merged back in, so the config is replaced
+		*in = *confmap.NewFromStringMap(conf)
+	}
+	return nil
+}
+
+// metricsPipelinesWithReceiver returns the sorted names of the metrics pipelines using the receiver.
+func metricsPipelinesWithReceiver(pipelines map[string]any, receiver string) []string {
+	var names []string
+	for name, p := range pipelines {
+		if name != "metrics" && !strings.HasPrefix(name, "metrics/") {
+			continue
+		}
+		pipeline, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		receivers, _ := pipeline["receivers"].([]any)
+		for _, r := range receivers {
+			if r == receiver {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func checkSingleReceiver(pipelines map[string]any, names []string, receiver string) error {
+	for _, name := range names {
+		receivers, _ := pipelines[name].(map[string]any)["receivers"].([]any)
+		for _, r := range receivers {
+			if r != receiver {
+				return fmt.Errorf("the %s pipeline has other receivers", name)
+			}
+		}
+	}
+	return nil
+}
+
+// agentFiltersCondition returns the OTTL datapoint condition matching the datapoints the Smart Agent would
+// drop for the filters: those matching any exclude filter but no include filter. An empty condition is
+// returned if no exclude filter applies to the monitor type.
+func agentFiltersCondition(excludes, includes any, monitorType string) (string, error) {
+	excludeConditions, err := agentFilterConditions(excludes, monitorType)
+	if err != nil {
+		return "", fmt.Errorf("invalid metricsToExclude: %w", err)
+	}
+	includeConditions, err := agentFilterConditions(includes, monitorType)
+	if err != nil {
+		return "", fmt.Errorf("invalid metricsToInclude: %w", err)
+	}
+	if len(excludeConditions) == 0 {
+		return "", nil
+	}
+	condition := anyCondition(excludeConditions)
+	if len(includeConditions) > 0 {
+		condition += " and not (" + strings.Join(includeConditions, " or ") + ")"
+	}
+	return condition, nil
+}
+
+func agentFilterConditions(filters any, monitorType string) ([]string, error) {
+	if filters == nil {
+		return nil, nil
+	}
+	entries, ok := filters.([]any)
+	if !ok {
+		return nil, errors.New("must be a list of filters")
+	}
+	var conditions []string
+	for i, entry := range entries {
+		entryMap, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("filter %d must be a map", i)
+		}
+		var filter agentMetricFilter
+		if err := confmap.NewFromStringMap(entryMap).Unmarshal(&filter); err != nil {
+			return nil, fmt.Errorf("filter %d: %w", i, err)
+		}
+		// filters scoped to another monitor type never match
+		if filter.MonitorType != "" && filter.MonitorType != monitorType {
+			continue
+		}
+		condition, err := agentFilterCondition(filter)
+		if err != nil {
+			return nil, fmt.Errorf("filter %d: %w", i, err)
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+func agentFilterCondition(filter agentMetricFilter) (string, error) {
+	metricNames := filter.MetricNames
+	if filter.MetricName != "" {
+		metricNames = append(metricNames, filter.MetricName)
+	}
+	if len(metricNames) == 0 && len(filter.Dimensions) == 0 {
+		return "", errors.New("must have at least one metric or dimension defined on it")
+	}
+
+	var parts []string
+	if len(metricNames) > 0 {
+		condition, err := stringMatchCondition("metric.name", metricNames)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, condition)
+	}
+	if len(filter.Dimensions) > 0 {
+		conditions, err := dimensionsConditions(filter.Dimensions)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, conditions...)
+	}
+
+	condition := strings.Join(parts, " and ")
+	switch {
+	case filter.Negated:
+		condition = "not (" + condition + ")"
+	case len(parts) > 1:
+		condition = "(" + condition + ")"
+	}
+	return condition, nil
+}
+
+// dimensionsConditions returns the conditions matching the attribute of each dimension. Dimensions
+// with a "?" suffix also match datapoints without the attribute.
+func dimensionsConditions(dimensions map[string]any) ([]string, error) {
+	var parts []string
+	for _, key := range sortedKeys(dimensions) {
+		var values []string
+		switch v := dimensions[key].(type) {
+		case string:
+			values = []string{v}
+		case []any:
+			for _, value := range v {
+				values = append(values, fmt.Sprintf("%v", value))
+			}
+		default:
+			return nil, fmt.Errorf("dimension %q should be either a string or string list", key)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("dimension %q has no values", key)
+		}
+
+		attribute, optional := strings.CutSuffix(key, "?")
+		// The Smart Agent stops matching at the first missing optional dimension,
+		// which only has a deterministic equivalent when it's the sole dimension.
+		if optional && len(dimensions) > 1 {
+			return nil, fmt.Errorf("optional dimension %q can't be combined with other dimensions", key)
+		}
+		target := "attributes[" + strconv.Quote(attribute) + "]"
+		condition, err := stringMatchCondition(target, values)
+		if err != nil {
+			return nil, err
+		}
+		if optional {
+			condition = "(" + target + " == nil or " + condition + ")"
+		}
+		parts = append(parts, condition)
+	}
+	return parts, nil
+}
+
+// stringMatchCondition matches targets matching any of the non-negated patterns and none of
+// the negated ones, as the Smart Agent's overridable string filters do.
+func stringMatchCondition(target string, patterns []string) (string, error) {
+	var positive, negative []string
+	for _, p := range patterns {
+		pattern, negated := strings.CutPrefix(p, "!")
+		condition, err := patternCondition(target, pattern)
+		if err != nil {
+			return "", err
+		}
+		if negated {
+			negative = append(negative, condition)
+		} else {
+			positive = append(positive, condition)
+		}
+	}
+	if len(positive) == 0 {
+		return "false", nil
+	}
+	condition := anyCondition(positive)
+	if len(negative) > 0 {
+		condition = "(" + condition + " and not (" + strings.Join(negative, " or ") + "))"
+	}
+	return condition, nil
+}
+
+// patternCondition matches the target against a static value, a /regexp/, or a glob.
+func patternCondition(target, pattern string) (string, error) {
+	switch {
+	case len(pattern) > 2 && pattern[0] == '/' && pattern[len(pattern)-1] == '/':
+		re := pattern[1 : len(pattern)-1]
+		if _, err := regexp.Compile(re); err != nil {
+			return "", err
+		}
+		return "IsMatch(" + target + ", " + strconv.Quote(re) + ")", nil
+	case strings.ContainsAny(pattern, "*?[]{}!"):
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		return "IsMatch(" + target + ", " + strconv.Quote(re) + ")", nil
+	default:
+		return target + " == " + strconv.Quote(pattern), nil
+	}
+}
+
+// globToRegexp converts a separator-less glob to an anchored regular expression.
+func globToRegexp(glob string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	depth := 0
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 == len(glob) {
+				return "", errors.New("trailing escape")
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", errors.New("unterminated character class")
+			}
+			class := glob[i+1 : i+1+end]
+			sb.WriteString("[")
+			if rest, negated := strings.CutPrefix(class, "!"); negated {
+				sb.WriteString("^")
+				class = rest
+			}
+			for j := 0; j < len(class); j++ {
+				if class[j] == '-' {
+					sb.WriteByte('-')
+					continue
+				}
+				sb.WriteString(regexp.QuoteMeta(class[j : j+1]))
+			}
+			sb.WriteString("]")
+			i += end + 1
+		case '{':
+			depth++
+			sb.WriteString("(?:")
+		case '}':
+			if depth == 0 {
+				return "", errors.New("unmatched '}'")
+			}
+			depth--
+			sb.WriteString(")")
+		case ',':
+			if depth > 0 {
+				sb.WriteString("|")
+			} else {
+				sb.WriteString(",")
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	if depth != 0 {
+		return "", errors.New("unterminated '{'")
+	}
+	sb.WriteString("$")
+	re := sb.String()
+	if _, err := regexp.Compile(re); err != nil {
+		return "", err
+	}
+	return re, nil
+}
+
+// anyCondition joins the conditions with "or", grouped if there's more than one.
+func anyCondition(conditions []string) string {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, " or ") + ")"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
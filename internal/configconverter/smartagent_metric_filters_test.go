@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestTranslateSmartAgentMetricFilters(t *testing.T) {
+	expectedCfgMap, err := confmaptest.LoadConf("testdata/smartagent_metric_filters/expected.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, expectedCfgMap)
+
+	cfgMap, err := confmaptest.LoadConf("testdata/smartagent_metric_filters/config.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	require.NoError(t, TranslateSmartAgentMetricFilters(context.Background(), cfgMap))
+	assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+}
+
+func TestAgentFilterCondition(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		filter      agentMetricFilter
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "static and regexp names",
+			filter:   agentMetricFilter{MetricNames: []string{"a.b", "/^c+$/"}},
+			expected: `(metric.name == "a.b" or IsMatch(metric.name, "^c+$"))`,
+		},
+		{
+			name:     "globs",
+			filter:   agentMetricFilter{MetricName: "{cpu,disk}.[!a-c]?"},
+			expected: `IsMatch(metric.name, "^(?:cpu|disk)\\.[^a-c].$")`,
+		},
+		{
+			name:     "only negated names never match",
+			filter:   agentMetricFilter{MetricName: "!a"},
+			expected: "false",
+		},
+		{
+			name:     "negated filter",
+			filter:   agentMetricFilter{MetricName: "a", Dimensions: map[string]any{"host": "h1"}, Negated: true},
+			expected: `not (metric.name == "a" and attributes["host"] == "h1")`,
+		},
+		{
+			name:     "optional dimension",
+			filter:   agentMetricFilter{Dimensions: map[string]any{"env?": []any{"dev", "!dev-1"}}},
+			expected: `(attributes["env"] == nil or (attributes["env"] == "dev" and not (attributes["env"] == "dev-1")))`,
+		},
+		{
+			name:        "optional dimension with others",
+			filter:      agentMetricFilter{Dimensions: map[string]any{"env?": "dev", "host": "h1"}},
+			expectedErr: `optional dimension "env?" can't be combined with other dimensions`,
+		},
+		{
+			name:        "empty",
+			filter:      agentMetricFilter{},
+			expectedErr: "must have at least one metric or dimension defined on it",
+		},
+		{
+			name:        "invalid glob",
+			filter:      agentMetricFilter{MetricName: "a{b"},
+			expectedErr: `invalid glob "a{b": unterminated '{'`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := agentFilterCondition(tt.filter)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, condition)
+		})
+	}
+}
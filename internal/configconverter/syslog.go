@@ -0,0 +1,105 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	syslogKey         = "splunk_syslog"
+	syslogReceiver    = "splunk_syslog"
+	syslogEndpointEnv = "SPLUNK_SYSLOG_ENDPOINT"
+)
+
+// SetupSyslog applies the distribution level `splunk_syslog` settings and removes them from the config.
+// A splunk_syslog receiver with the remaining settings is added to the `pipelines`, all logs pipelines
+// by default. Setting the SPLUNK_SYSLOG_ENDPOINT environment variable enables the receiver without a
+// `splunk_syslog` block, and sets the endpoint of one without its own.
+func SetupSyslog(_ context.Context, in *confmap.Conf) error {
+	endpoint := os.Getenv(syslogEndpointEnv)
+	if in == nil || (!in.IsSet(syslogKey) && endpoint == "") {
+		return nil
+	}
+
+	out := in.ToStringMap()
+	settings, ok := out[syslogKey].(map[string]any)
+	if !ok && out[syslogKey] != nil {
+		return fmt.Errorf("%s must be a map", syslogKey)
+	}
+	delete(out, syslogKey)
+	receiverSettings := map[string]any{}
+	for k, v := range settings {
+		receiverSettings[k] = v
+	}
+	if _, ok := receiverSettings["endpoint"]; !ok && endpoint != "" {
+		receiverSettings["endpoint"] = endpoint
+	}
+
+	var pipelineIDs []any
+	if p, ok := receiverSettings["pipelines"]; ok {
+		var err error
+		if pipelineIDs, err = toAnySlice(p); err != nil {
+			return fmt.Errorf("%s::pipelines must be a list", syslogKey)
+		}
+		delete(receiverSettings, "pipelines")
+	}
+
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	if len(pipelineIDs) == 0 {
+		for id := range pipelines {
+			if typ, _, _ := strings.Cut(id, "/"); typ == "logs" {
+				pipelineIDs = append(pipelineIDs, id)
+			}
+		}
+		sort.Slice(pipelineIDs, func(i, j int) bool { return pipelineIDs[i].(string) < pipelineIDs[j].(string) })
+		if len(pipelineIDs) == 0 {
+			return fmt.Errorf("%s: no logs pipeline is configured", syslogKey)
+		}
+	}
+
+	receivers := ensureMap(out, "receivers")
+	if _, ok := receivers[syslogReceiver]; ok {
+		return fmt.Errorf("%s: receivers::%s must not be configured", syslogKey, syslogReceiver)
+	}
+	if len(receiverSettings) > 0 {
+		receivers[syslogReceiver] = receiverSettings
+	} else {
+		receivers[syslogReceiver] = nil
+	}
+
+	for _, p := range pipelineIDs {
+		id, _ := p.(string)
+		pipeline, _ := pipelines[id].(map[string]any)
+		if typ, _, _ := strings.Cut(id, "/"); typ != "logs" || pipeline == nil {
+			return fmt.Errorf("%s: logs pipeline %q isn't configured", syslogKey, id)
+		}
+		pipelineReceivers, err := receiversOf(pipeline)
+		if err != nil {
+			return fmt.Errorf("%s: invalid receivers of pipeline %q: %w", syslogKey, id, err)
+		}
+		pipeline["receivers"] = append(pipelineReceivers, syslogReceiver)
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
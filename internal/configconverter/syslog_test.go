@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupSyslog(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+		endpoint string
+	}{
+		{input: "syslog.yaml", expected: "syslog_expected.yaml"},
+		{input: "defaults.yaml", expected: "defaults_expected.yaml"},
+		{input: "env.yaml", expected: "env_expected.yaml", endpoint: "0.0.0.0:514"},
+		// the endpoint of the splunk_syslog block has precedence over the environment variable
+		{input: "syslog.yaml", expected: "syslog_expected.yaml", endpoint: "0.0.0.0:514"},
+		// configs without splunk_syslog are unchanged
+		{input: "env.yaml", expected: "env.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Setenv(syslogEndpointEnv, tt.endpoint)
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "syslog", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "syslog", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupSyslog(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupSyslogInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "already_configured.yaml",
+			expectedErr: "splunk_syslog: receivers::splunk_syslog must not be configured",
+		},
+		{
+			input:       "invalid.yaml",
+			expectedErr: "splunk_syslog must be a map",
+		},
+		{
+			input:       "no_logs_pipeline.yaml",
+			expectedErr: "splunk_syslog: no logs pipeline is configured",
+		},
+		{
+			input:       "unknown_pipeline.yaml",
+			expectedErr: `splunk_syslog: logs pipeline "metrics" isn't configured`,
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "syslog", tt.input))
+			require.NoError(t, err)
+			require.EqualError(t, SetupSyslog(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
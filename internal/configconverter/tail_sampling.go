@@ -0,0 +1,184 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	tailSamplingKey  = "splunk_tail_sampling"
+	tailSamplingName = "splunk_tail_sampling"
+)
+
+type tailSamplingConfig struct {
+	// Resolver is the resolver of the loadbalancing exporter finding the gateway replicas.
+	Resolver map[string]any `mapstructure:"resolver"`
+	// Receiver are the OTLP gRPC settings of the receiver of the spans forwarded by the replicas.
+	Receiver map[string]any `mapstructure:"receiver"`
+	// Exporter are the OTLP settings of the loadbalancing exporter forwarding the spans.
+	Exporter map[string]any `mapstructure:"exporter"`
+	// Sampling are the tail_sampling processor settings replacing the default ones.
+	Sampling map[string]any `mapstructure:"sampling"`
+	// Pipeline is the sampled traces pipeline.
+	Pipeline string `mapstructure:"pipeline"`
+}
+
+// tailSamplingDefaults are the tail_sampling processor settings tuned for Splunk APM: all the traces
+// with errors or slow spans are kept, so they can be troubleshot, and a tenth of the others.
+func tailSamplingDefaults() map[string]any {
+	return map[string]any{
+		"decision_wait": "10s",
+		"num_traces":    100000,
+		"policies": []any{
+			map[string]any{
+				"name":        "errors",
+				"type":        "status_code",
+				"status_code": map[string]any{"status_codes": []any{"ERROR"}},
+			},
+			map[string]any{
+				"name":    "slow",
+				"type":    "latency",
+				"latency": map[string]any{"threshold_ms": 5000},
+			},
+			map[string]any{
+				"name":          "probabilistic",
+				"type":          "probabilistic",
+				"probabilistic": map[string]any{"sampling_percentage": 10},
+			},
+		},
+	}
+}
+
+// SetupTailSampling applies the distribution level `splunk_tail_sampling` settings and removes them
+// from the config. Tail sampling decisions need all the spans of a trace, which horizontally scaled
+// gateways receive in part each, so the spans of the sampled pipeline are forwarded by a
+// loadbalancing/splunk_tail_sampling exporter to the gateway replica owning their trace by the hash
+// of its trace ID. Each replica receives the spans of the traces it owns with an
+// otlp/splunk_tail_sampling receiver, and samples them with a tail_sampling/splunk_tail_sampling
+// processor in a traces/splunk_tail_sampling pipeline exporting to the original exporters. The
+// spanmetrics connectors of the pipeline, like the one computing RED metrics, keep receiving all the
+// spans.
+func SetupTailSampling(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(tailSamplingKey) {
+		return nil
+	}
+
+	cfg := tailSamplingConfig{Pipeline: "traces"}
+	settings, err := in.Sub(tailSamplingKey)
+	if err != nil {
+		return err
+	}
+	if err = settings.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", tailSamplingKey, err)
+	}
+	if len(cfg.Resolver) == 0 {
+		return fmt.Errorf("%s::resolver must be specified", tailSamplingKey)
+	}
+
+	out := in.ToStringMap()
+	delete(out, tailSamplingKey)
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	pipeline, _ := pipelines[cfg.Pipeline].(map[string]any)
+	if pipeline == nil {
+		return fmt.Errorf("%s: pipeline %q isn't configured", tailSamplingKey, cfg.Pipeline)
+	}
+	signal, name, _ := strings.Cut(cfg.Pipeline, "/")
+	if signal != "traces" {
+		return fmt.Errorf("%s: pipeline %q isn't a traces pipeline", tailSamplingKey, cfg.Pipeline)
+	}
+	suffix := tailSamplingName
+	if name != "" {
+		suffix = name + "_" + tailSamplingName
+	}
+	exporterID := "loadbalancing/" + tailSamplingName
+	receiverID := "otlp/" + tailSamplingName
+	processorID := "tail_sampling/" + tailSamplingName
+	sampledPipeline := "traces/" + suffix
+
+	exporters := ensureMap(out, "exporters")
+	receivers := ensureMap(out, "receivers")
+	processors := ensureMap(out, "processors")
+	for _, component := range []struct {
+		components map[string]any
+		kind       string
+		id         string
+	}{
+		{components: exporters, kind: "exporters", id: exporterID},
+		{components: receivers, kind: "receivers", id: receiverID},
+		{components: processors, kind: "processors", id: processorID},
+		{components: pipelines, kind: "service::pipelines", id: sampledPipeline},
+	} {
+		if _, ok := component.components[component.id]; ok {
+			return fmt.Errorf("%s: %s::%s must not be configured", tailSamplingKey, component.kind, component.id)
+		}
+	}
+
+	pipelineExporters, err := toAnySlice(pipeline["exporters"])
+	if err != nil {
+		return fmt.Errorf("%s: invalid exporters of pipeline %q: %w", tailSamplingKey, cfg.Pipeline, err)
+	}
+	unsampled := []any{exporterID}
+	var sampled []any
+	for _, e := range pipelineExporters {
+		id, _ := e.(string)
+		if typ, _, _ := strings.Cut(id, "/"); typ == "spanmetrics" {
+			unsampled = append(unsampled, e)
+		} else {
+			sampled = append(sampled, e)
+		}
+	}
+	if len(sampled) == 0 {
+		return fmt.Errorf("%s: pipeline %q has no exporter to send the sampled traces to", tailSamplingKey, cfg.Pipeline)
+	}
+
+	protocol := cfg.Exporter
+	if protocol == nil {
+		protocol = map[string]any{"tls": map[string]any{"insecure": true}}
+	}
+	exporters[exporterID] = map[string]any{
+		"routing_key": "traceID",
+		"protocol":    map[string]any{"otlp": protocol},
+		"resolver":    cfg.Resolver,
+	}
+	grpc := cfg.Receiver
+	if grpc == nil {
+		grpc = map[string]any{}
+	}
+	if _, ok := grpc["endpoint"]; !ok {
+		grpc["endpoint"] = "0.0.0.0:4319"
+	}
+	receivers[receiverID] = map[string]any{"protocols": map[string]any{"grpc": grpc}}
+	sampling := tailSamplingDefaults()
+	for key, value := range cfg.Sampling {
+		sampling[key] = value
+	}
+	processors[processorID] = sampling
+
+	pipeline["exporters"] = unsampled
+	pipelines[sampledPipeline] = map[string]any{
+		"receivers":  []any{receiverID},
+		"processors": []any{processorID},
+		"exporters":  sampled,
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupTailSampling(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "tail_sampling.yaml", expected: "tail_sampling_expected.yaml"},
+		{input: "custom.yaml", expected: "custom_expected.yaml"},
+		// configs without splunk_tail_sampling are unchanged
+		{input: "tail_sampling_expected.yaml", expected: "tail_sampling_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "tail_sampling", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "tail_sampling", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupTailSampling(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupTailSamplingInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "no_resolver.yaml",
+			expectedErr: "splunk_tail_sampling::resolver must be specified",
+		},
+		{
+			input:       "unknown_pipeline.yaml",
+			expectedErr: `splunk_tail_sampling: pipeline "traces/missing" isn't configured`,
+		},
+		{
+			input:       "not_traces.yaml",
+			expectedErr: `splunk_tail_sampling: pipeline "metrics" isn't a traces pipeline`,
+		},
+		{
+			input:       "no_exporter.yaml",
+			expectedErr: `splunk_tail_sampling: pipeline "traces" has no exporter to send the sampled traces to`,
+		},
+		{
+			input:       "conflict.yaml",
+			expectedErr: "splunk_tail_sampling: processors::tail_sampling/splunk_tail_sampling must not be configured",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "tail_sampling", tt.input))
+			require.NoError(t, err)
+			require.EqualError(t, SetupTailSampling(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
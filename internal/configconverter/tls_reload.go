@@ -0,0 +1,106 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const tlsReloadKey = "splunk_tls_reload"
+
+// tlsFileKeys are the configtls settings of certificate files, whose TLS configs are reloaded.
+var tlsFileKeys = []string{"cert_file", "key_file", "ca_file", "client_ca_file"}
+
+type tlsReloadConfig struct {
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// SetupTLSReload applies the distribution level `splunk_tls_reload` settings and removes them from
+// the config. The reload_interval of the setting is set on the TLS configs of all receivers,
+// exporters and extensions with certificate files that don't set their own, so rotated
+// certificates and keys are loaded without restarting the collector, and client CA files of
+// servers are reloaded when they change.
+func SetupTLSReload(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(tlsReloadKey) {
+		return nil
+	}
+
+	var cfg tlsReloadConfig
+	reloadSettings, err := in.Sub(tlsReloadKey)
+	if err != nil {
+		return err
+	}
+	if err = reloadSettings.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", tlsReloadKey, err)
+	}
+	if cfg.ReloadInterval <= 0 {
+		return fmt.Errorf("%s::reload_interval must be positive", tlsReloadKey)
+	}
+
+	out := in.ToStringMap()
+	delete(out, tlsReloadKey)
+	for _, kind := range []string{"receivers", "exporters", "extensions"} {
+		components, _ := out[kind].(map[string]any)
+		for _, component := range components {
+			setTLSReloadInterval(component, cfg.ReloadInterval.String())
+		}
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// setTLSReloadInterval sets the reload interval of the tls configs found in value, like the
+// tls config of a gRPC protocol of the otlp receiver.
+func setTLSReloadInterval(value any, interval string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if tls, ok := child.(map[string]any); ok && key == "tls" {
+				setReloadInterval(tls, interval)
+				continue
+			}
+			setTLSReloadInterval(child, interval)
+		}
+	case []any:
+		for _, child := range v {
+			setTLSReloadInterval(child, interval)
+		}
+	}
+}
+
+func setReloadInterval(tls map[string]any, interval string) {
+	hasFiles := false
+	for _, key := range tlsFileKeys {
+		if file, _ := tls[key].(string); file != "" {
+			hasFiles = true
+		}
+	}
+	if !hasFiles {
+		return
+	}
+	if _, ok := tls["reload_interval"]; !ok {
+		tls["reload_interval"] = interval
+	}
+	if clientCAFile, _ := tls["client_ca_file"].(string); clientCAFile != "" {
+		if _, ok := tls["client_ca_file_reload"]; !ok {
+			tls["client_ca_file_reload"] = true
+		}
+	}
+}
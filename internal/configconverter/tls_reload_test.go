@@ -0,0 +1,71 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupTLSReload(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "tls_reload.yaml", expected: "tls_reload_expected.yaml"},
+		// configs without splunk_tls_reload are unchanged
+		{input: "tls_reload_expected.yaml", expected: "tls_reload_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "tls_reload", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "tls_reload", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupTLSReload(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupTLSReloadInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "invalid_interval.yaml",
+			expectedErr: "splunk_tls_reload::reload_interval must be positive",
+		},
+		{
+			input:       "invalid.yaml",
+			expectedErr: "invalid splunk_tls_reload config: decoding failed due to the following error(s):\n\nerror decoding 'reload_interval': time: invalid duration \"soon\"",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "tls_reload", tt.input))
+			require.NoError(t, err)
+			require.EqualError(t, SetupTLSReload(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
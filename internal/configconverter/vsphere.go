@@ -0,0 +1,135 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	vsphereKey            = "splunk_vsphere"
+	vsphereName           = "vsphere"
+	vspherePipeline       = "metrics/" + vsphereName
+	vsphereReceiver       = "vcenter/" + vsphereName
+	vsphereFilter         = "filter/" + vsphereName
+	vsphereDatacenterAttr = "vcenter.datacenter.name"
+)
+
+type vsphereConfig struct {
+	TLS                map[string]any `mapstructure:"tls"`
+	Endpoint           string         `mapstructure:"endpoint"`
+	Username           string         `mapstructure:"username"`
+	Password           string         `mapstructure:"password"`
+	CollectionInterval string         `mapstructure:"collection_interval"`
+	Datacenters        []string       `mapstructure:"datacenters"`
+	Exporters          []string       `mapstructure:"exporters"`
+	Processors         []string       `mapstructure:"processors"`
+}
+
+// SetupVSphere applies the distribution level `splunk_vsphere` preset and removes it from the config.
+// It scrapes the vCenter server of the endpoint with a vcenter receiver, from a metrics/vsphere pipeline
+// exporting to the configured exporters, or all signalfx exporters. The credentials are usually
+// references to config sources like vault, resolved before the preset is applied. The metrics of the
+// datacenters other than the configured ones, if any, are dropped by a filter processor.
+func SetupVSphere(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(vsphereKey) {
+		return nil
+	}
+
+	cfg := vsphereConfig{CollectionInterval: "2m"}
+	preset, err := in.Sub(vsphereKey)
+	if err != nil {
+		return err
+	}
+	if err = preset.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("invalid %s config: %w", vsphereKey, err)
+	}
+	if cfg.Endpoint == "" || cfg.Username == "" || cfg.Password == "" {
+		return errors.New(vsphereKey + ": endpoint, username and password must be set")
+	}
+
+	out := in.ToStringMap()
+	delete(out, vsphereKey)
+
+	service := ensureMap(out, "service")
+	pipelines := ensureMap(service, "pipelines")
+	if _, ok := pipelines[vspherePipeline]; ok {
+		return errors.New(vsphereKey + ": service::pipelines::" + vspherePipeline + " must not be configured")
+	}
+	exporters, err := presetExporters(vsphereKey, cfg.Exporters, out)
+	if err != nil {
+		return err
+	}
+
+	receivers := ensureMap(out, "receivers")
+	if _, ok := receivers[vsphereReceiver]; ok {
+		return fmt.Errorf("%s: receivers::%s must not be configured", vsphereKey, vsphereReceiver)
+	}
+	receiver := map[string]any{
+		"endpoint":            cfg.Endpoint,
+		"username":            cfg.Username,
+		"password":            cfg.Password,
+		"collection_interval": cfg.CollectionInterval,
+	}
+	if cfg.TLS != nil {
+		receiver["tls"] = cfg.TLS
+	}
+	receivers[vsphereReceiver] = receiver
+
+	var pipelineProcessors []any
+	if len(cfg.Datacenters) > 0 {
+		processors := ensureMap(out, "processors")
+		if _, ok := processors[vsphereFilter]; ok {
+			return fmt.Errorf("%s: processors::%s must not be configured", vsphereKey, vsphereFilter)
+		}
+		processors[vsphereFilter] = map[string]any{
+			"error_mode": "ignore",
+			"metrics": map[string]any{
+				"metric": []any{vsphereDatacenterCondition(cfg.Datacenters)},
+			},
+		}
+		pipelineProcessors = append(pipelineProcessors, vsphereFilter)
+	}
+	for _, p := range cfg.Processors {
+		pipelineProcessors = append(pipelineProcessors, p)
+	}
+
+	pipeline := map[string]any{
+		"receivers": []any{vsphereReceiver},
+		"exporters": exporters,
+	}
+	if len(pipelineProcessors) > 0 {
+		pipeline["processors"] = pipelineProcessors
+	}
+	pipelines[vspherePipeline] = pipeline
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// vsphereDatacenterCondition returns the filter processor condition matching the metrics of the
+// datacenters other than the given ones.
+func vsphereDatacenterCondition(datacenters []string) string {
+	conditions := make([]string, 0, len(datacenters))
+	for _, datacenter := range datacenters {
+		conditions = append(conditions, fmt.Sprintf("resource.attributes[%q] == %q", vsphereDatacenterAttr, datacenter))
+	}
+	return "not (" + strings.Join(conditions, " or ") + ")"
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupVSphere(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "vsphere.yaml", expected: "vsphere_expected.yaml"},
+		{input: "defaults.yaml", expected: "defaults_expected.yaml"},
+		// configs without splunk_vsphere are unchanged
+		{input: "defaults_expected.yaml", expected: "defaults_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "vsphere", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "vsphere", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupVSphere(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupVSphereInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "invalid.yaml",
+			expectedErr: "invalid splunk_vsphere config",
+		},
+		{
+			input:       "missing_credentials.yaml",
+			expectedErr: "splunk_vsphere: endpoint, username and password must be set",
+		},
+		{
+			input:       "unknown_exporter.yaml",
+			expectedErr: `splunk_vsphere::exporters contains unknown exporter "signalfx/other"`,
+		},
+		{
+			input:       "receiver_exists.yaml",
+			expectedErr: "splunk_vsphere: receivers::vcenter/vsphere must not be configured",
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "vsphere", tt.input))
+			require.NoError(t, err)
+			require.ErrorContains(t, SetupVSphere(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
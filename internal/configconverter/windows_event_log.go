@@ -0,0 +1,152 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	windowsEventLogKey       = "splunk_windows_event_log"
+	windowsEventLogProcessor = "wineventlog/splunk"
+)
+
+var (
+	windowsEventLogDefaultChannels = []any{"Application", "System", "Security"}
+	nonAlphanumeric                = regexp.MustCompile("[^a-z0-9]+")
+)
+
+// SetupWindowsEventLog applies the distribution level `splunk_windows_event_log` settings and removes them
+// from the config. A windowseventlog/splunk_<channel> receiver is added for each of the `channels` to the
+// `pipelines`, all logs pipelines by default. The receivers don't render the messages of events, which is
+// expensive for publishers like the Security auditing, and a wineventlog/splunk processor with the remaining
+// settings renders them from its cached message templates and maps the events to their sourcetypes instead.
+func SetupWindowsEventLog(_ context.Context, in *confmap.Conf) error {
+	if in == nil || !in.IsSet(windowsEventLogKey) {
+		return nil
+	}
+
+	out := in.ToStringMap()
+	settings, ok := out[windowsEventLogKey].(map[string]any)
+	if !ok && out[windowsEventLogKey] != nil {
+		return fmt.Errorf("%s must be a map", windowsEventLogKey)
+	}
+	delete(out, windowsEventLogKey)
+	processorSettings := map[string]any{}
+	for k, v := range settings {
+		processorSettings[k] = v
+	}
+
+	channels := windowsEventLogDefaultChannels
+	if c, ok := processorSettings["channels"]; ok {
+		var err error
+		if channels, err = toAnySlice(c); err != nil || len(channels) == 0 {
+			return fmt.Errorf("%s::channels must be a non-empty list", windowsEventLogKey)
+		}
+		delete(processorSettings, "channels")
+	}
+	var pipelineIDs []any
+	if p, ok := processorSettings["pipelines"]; ok {
+		var err error
+		if pipelineIDs, err = toAnySlice(p); err != nil {
+			return fmt.Errorf("%s::pipelines must be a list", windowsEventLogKey)
+		}
+		delete(processorSettings, "pipelines")
+	}
+	storage, _ := processorSettings["storage"].(string)
+	delete(processorSettings, "storage")
+
+	service, _ := out["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	if len(pipelineIDs) == 0 {
+		for id := range pipelines {
+			if typ, _, _ := strings.Cut(id, "/"); typ == "logs" {
+				pipelineIDs = append(pipelineIDs, id)
+			}
+		}
+		sort.Slice(pipelineIDs, func(i, j int) bool { return pipelineIDs[i].(string) < pipelineIDs[j].(string) })
+		if len(pipelineIDs) == 0 {
+			return fmt.Errorf("%s: no logs pipeline is configured", windowsEventLogKey)
+		}
+	}
+
+	receivers := ensureMap(out, "receivers")
+	var receiverIDs []any
+	for _, c := range channels {
+		channel, _ := c.(string)
+		if channel == "" {
+			return fmt.Errorf("%s::channels must not contain empty channels", windowsEventLogKey)
+		}
+		id := "windowseventlog/splunk_" + strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(channel), "_"), "_")
+		if _, ok := receivers[id]; ok {
+			return fmt.Errorf("%s: receivers::%s must not be configured", windowsEventLogKey, id)
+		}
+		receiver := map[string]any{
+			"channel":                 channel,
+			"start_at":                "end",
+			"suppress_rendering_info": true,
+		}
+		if storage != "" {
+			receiver["storage"] = storage
+		}
+		receivers[id] = receiver
+		receiverIDs = append(receiverIDs, id)
+	}
+
+	processors := ensureMap(out, "processors")
+	if _, ok := processors[windowsEventLogProcessor]; ok {
+		return fmt.Errorf("%s: processors::%s must not be configured", windowsEventLogKey, windowsEventLogProcessor)
+	}
+	if len(processorSettings) > 0 {
+		processors[windowsEventLogProcessor] = processorSettings
+	} else {
+		processors[windowsEventLogProcessor] = nil
+	}
+
+	for _, p := range pipelineIDs {
+		id, _ := p.(string)
+		pipeline, _ := pipelines[id].(map[string]any)
+		if typ, _, _ := strings.Cut(id, "/"); typ != "logs" || pipeline == nil {
+			return fmt.Errorf("%s: logs pipeline %q isn't configured", windowsEventLogKey, id)
+		}
+		pipelineReceivers, err := receiversOf(pipeline)
+		if err != nil {
+			return fmt.Errorf("%s: invalid receivers of pipeline %q: %w", windowsEventLogKey, id, err)
+		}
+		pipeline["receivers"] = append(pipelineReceivers, receiverIDs...)
+		pipelineProcessors, err := processorsOf(pipeline)
+		if err != nil {
+			return fmt.Errorf("%s: invalid processors of pipeline %q: %w", windowsEventLogKey, id, err)
+		}
+		pipeline["processors"] = insertAfterMemoryLimiters(pipelineProcessors, windowsEventLogProcessor)
+	}
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
+
+// receiversOf returns the receivers of the pipeline.
+func receiversOf(pipeline map[string]any) ([]any, error) {
+	if pipeline["receivers"] == nil {
+		return nil, nil
+	}
+	return toAnySlice(pipeline["receivers"])
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestSetupWindowsEventLog(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "windows_event_log.yaml", expected: "windows_event_log_expected.yaml"},
+		{input: "defaults.yaml", expected: "defaults_expected.yaml"},
+		// configs without splunk_windows_event_log are unchanged
+		{input: "windows_event_log_expected.yaml", expected: "windows_event_log_expected.yaml"},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expectedCfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "windows_event_log", tt.expected))
+			require.NoError(t, err)
+			require.NotNil(t, expectedCfgMap)
+
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "windows_event_log", tt.input))
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			require.NoError(t, SetupWindowsEventLog(context.Background(), cfgMap))
+			assert.Equal(t, expectedCfgMap.ToStringMap(), cfgMap.ToStringMap())
+		})
+	}
+}
+
+func TestSetupWindowsEventLogInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input:       "already_configured.yaml",
+			expectedErr: "splunk_windows_event_log: receivers::windowseventlog/splunk_system must not be configured",
+		},
+		{
+			input:       "invalid.yaml",
+			expectedErr: "splunk_windows_event_log must be a map",
+		},
+		{
+			input:       "no_logs_pipeline.yaml",
+			expectedErr: "splunk_windows_event_log: no logs pipeline is configured",
+		},
+		{
+			input:       "unknown_pipeline.yaml",
+			expectedErr: `splunk_windows_event_log: logs pipeline "metrics" isn't configured`,
+		},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			cfgMap, err := confmaptest.LoadConf(filepath.Join("testdata", "windows_event_log", tt.input))
+			require.NoError(t, err)
+			require.EqualError(t, SetupWindowsEventLog(context.Background(), cfgMap), tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"fmt"
+
+	"github.com/knadh/koanf/maps"
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+// DocumentParam is the invocation parameter, e.g. "${zookeeper:/otel/receivers?document=true}", requesting
+// hierarchical config sources to resolve a node and all its descendants as a single YAML document.
+const DocumentParam = "document"
+
+// IsDocumentRequested returns whether the invocation parameters request a whole document.
+func IsDocumentRequested(params *confmap.Conf) bool {
+	if params == nil {
+		return false
+	}
+	return cast.ToBool(params.Get(DocumentParam))
+}
+
+// NewDocument parses the YAML value of a node and merges the documents of its children under
+// their names, overriding any conflicting keys of the parent. Nodes with children must have an
+// empty value or a YAML map as value.
+func NewDocument(value string, children map[string]any) (any, error) {
+	var doc any
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %w", err)
+	}
+	if m, ok := doc.(map[any]any); ok {
+		docMap := cast.ToStringMap(m)
+		maps.IntfaceKeysToStrings(docMap)
+		doc = docMap
+	}
+	if len(children) == 0 {
+		return doc, nil
+	}
+
+	var docMap map[string]any
+	switch d := doc.(type) {
+	case nil:
+		docMap = map[string]any{}
+	case map[string]any:
+		docMap = d
+	default:
+		return nil, fmt.Errorf("cannot merge children into a document of type %T", doc)
+	}
+	conf := confmap.NewFromStringMap(docMap)
+	if err := conf.Merge(confmap.NewFromStringMap(children)); err != nil {
+		return nil, err
+	}
+	return conf.ToStringMap(), nil
+}
@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestIsDocumentRequested(t *testing.T) {
+	assert.False(t, IsDocumentRequested(nil))
+	assert.False(t, IsDocumentRequested(confmap.NewFromStringMap(map[string]any{"other": true})))
+	assert.True(t, IsDocumentRequested(confmap.NewFromStringMap(map[string]any{"document": true})))
+	assert.True(t, IsDocumentRequested(confmap.NewFromStringMap(map[string]any{"document": "true"})))
+}
+
+func TestNewDocument(t *testing.T) {
+	doc, err := NewDocument("endpoint: localhost:4317", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"endpoint": "localhost:4317"}, doc)
+
+	doc, err = NewDocument("scalar", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "scalar", doc)
+
+	doc, err = NewDocument("otlp:\n  protocols:\n    grpc:\n      endpoint: localhost:4317\n", map[string]any{
+		"otlp":       map[string]any{"protocols": map[string]any{"http": map[string]any{"endpoint": "localhost:4318"}}},
+		"prometheus": map[string]any{"config": map[string]any{"scrape_interval": "10s"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"otlp": map[string]any{"protocols": map[string]any{
+			"grpc": map[string]any{"endpoint": "localhost:4317"},
+			"http": map[string]any{"endpoint": "localhost:4318"},
+		}},
+		"prometheus": map[string]any{"config": map[string]any{"scrape_interval": "10s"}},
+	}, doc)
+
+	doc, err = NewDocument("", map[string]any{"child": "value"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"child": "value"}, doc)
+
+	_, err = NewDocument("[a, b]", map[string]any{"child": "value"})
+	assert.EqualError(t, err, "cannot merge children into a document of type []interface {}")
+
+	_, err = NewDocument("key: [", nil)
+	assert.ErrorContains(t, err, "failed to unmarshal document")
+}
@@ -18,6 +18,8 @@ package etcd2configsource
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 
 	"go.etcd.io/etcd/client/v2"
 	"go.uber.org/atomic"
@@ -58,7 +60,13 @@ type MockKeysAPI struct {
 	activeWatcher *MockWatcher
 }
 
-func (k *MockKeysAPI) Get(_ context.Context, key string, _ *client.GetOptions) (*client.Response, error) {
+func (k *MockKeysAPI) Get(_ context.Context, key string, opts *client.GetOptions) (*client.Response, error) {
+	if opts != nil && opts.Recursive {
+		if node := k.node(key); node != nil {
+			return &client.Response{Node: node}, nil
+		}
+		return nil, errors.New("not found")
+	}
 	if v, ok := k.db[key]; ok {
 		return &client.Response{
 			Node: &client.Node{
@@ -69,6 +77,28 @@ func (k *MockKeysAPI) Get(_ context.Context, key string, _ *client.GetOptions) (
 	return nil, errors.New("not found")
 }
 
+// node returns the node of the key with all its descendants, keys with descendants being directories.
+func (k *MockKeysAPI) node(key string) *client.Node {
+	children := map[string]struct{}{}
+	for dbKey := range k.db {
+		if rest, ok := strings.CutPrefix(dbKey, key+"/"); ok {
+			children[key+"/"+strings.Split(rest, "/")[0]] = struct{}{}
+		}
+	}
+	if len(children) == 0 {
+		if v, ok := k.db[key]; ok {
+			return &client.Node{Key: key, Value: v}
+		}
+		return nil
+	}
+	node := &client.Node{Key: key, Dir: true}
+	for child := range children {
+		node.Nodes = append(node.Nodes, k.node(child))
+	}
+	sort.Slice(node.Nodes, func(i, j int) bool { return node.Nodes[i].Key < node.Nodes[j].Key })
+	return node
+}
+
 func (k *MockKeysAPI) Watcher(string, *client.WatcherOptions) client.Watcher {
 	return k.activeWatcher
 }
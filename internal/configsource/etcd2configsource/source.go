@@ -18,6 +18,8 @@ package etcd2configsource
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -59,7 +61,11 @@ func newConfigSource(cfg *Config, logger *zap.Logger) (configsource.ConfigSource
 	}, nil
 }
 
-func (s *etcd2ConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+func (s *etcd2ConfigSource) Retrieve(ctx context.Context, selector string, params *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if configsource.IsDocumentRequested(params) {
+		return s.retrieveDocument(ctx, selector, watcher)
+	}
+
 	resp, err := s.kapi.Get(ctx, selector, nil)
 	if err != nil {
 		return nil, err
@@ -67,12 +73,49 @@ func (s *etcd2ConfigSource) Retrieve(ctx context.Context, selector string, _ *co
 	if watcher == nil {
 		return confmap.NewRetrieved(resp.Node.Value)
 	}
-	return confmap.NewRetrieved(resp.Node.Value, confmap.WithRetrievedClose(s.newWatcher(selector, resp.Node.ModifiedIndex, watcher)))
+	return confmap.NewRetrieved(resp.Node.Value, confmap.WithRetrievedClose(s.newWatcher(selector, &client.WatcherOptions{AfterIndex: resp.Node.ModifiedIndex}, watcher)))
+}
+
+// retrieveDocument returns the YAML document of the node merged with the documents of all its
+// descendants, watching for updates of any of them.
+func (s *etcd2ConfigSource) retrieveDocument(ctx context.Context, selector string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	resp, err := s.kapi.Get(ctx, selector, &client.GetOptions{Recursive: true})
+	if err != nil {
+		return nil, err
+	}
+	doc, err := nodeDocument(resp.Node)
+	if err != nil {
+		return nil, err
+	}
+	if watcher == nil {
+		return confmap.NewRetrieved(doc)
+	}
+	return confmap.NewRetrieved(doc, confmap.WithRetrievedClose(s.newWatcher(selector, &client.WatcherOptions{AfterIndex: resp.Index, Recursive: true}, watcher)))
+}
+
+// nodeDocument returns the document of a node, directories being a map of their children by name.
+func nodeDocument(node *client.Node) (any, error) {
+	children := map[string]any{}
+	for _, child := range node.Nodes {
+		childDoc, err := nodeDocument(child)
+		if err != nil {
+			return nil, err
+		}
+		children[path.Base(child.Key)] = childDoc
+	}
+	if node.Dir && len(children) == 0 {
+		return map[string]any{}, nil
+	}
+	doc, err := configsource.NewDocument(node.Value, children)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document at %q: %w", node.Key, err)
+	}
+	return doc, nil
 }
 
-func (s *etcd2ConfigSource) newWatcher(selector string, index uint64, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
+func (s *etcd2ConfigSource) newWatcher(selector string, opts *client.WatcherOptions, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
 	watchCtx, cancel := context.WithCancel(context.Background())
-	watcher := s.kapi.Watcher(selector, &client.WatcherOptions{AfterIndex: index})
+	watcher := s.kapi.Watcher(selector, opts)
 	ebo := backoff.NewExponentialBackOff()
 	ebo.MaxElapsedTime = maxBackoffTime
 
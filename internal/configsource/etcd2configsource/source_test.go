@@ -118,3 +118,28 @@ func TestWatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionRetrieveDocument(t *testing.T) {
+	kapi := &MockKeysAPI{
+		db: map[string]string{
+			"/otel/receivers/otlp":        "protocols:\n  grpc:\n",
+			"/otel/receivers/hostmetrics": "collection_interval: 10s",
+			"/otel/invalid":               "key: [",
+		},
+	}
+	source := &etcd2ConfigSource{logger: zap.NewNop(), kapi: kapi}
+	params := confmap.NewFromStringMap(map[string]any{"document": true})
+
+	retrieved, err := source.Retrieve(context.Background(), "/otel/receivers", params, nil)
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"otlp":        map[string]any{"protocols": map[string]any{"grpc": nil}},
+		"hostmetrics": map[string]any{"collection_interval": "10s"},
+	}, val)
+
+	retrieved, err = source.Retrieve(context.Background(), "/otel/invalid", params, nil)
+	assert.ErrorContains(t, err, `invalid document at "/otel/invalid": failed to unmarshal document`)
+	assert.Nil(t, retrieved)
+}
@@ -0,0 +1,188 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// componentKinds are the top level config keys whose entries are components enabled by the service section.
+var componentKinds = map[string]bool{
+	"receivers":  true,
+	"processors": true,
+	"exporters":  true,
+	"connectors": true,
+	"extensions": true,
+}
+
+// Reference is an invocation of a config source, confmap provider or environment variable
+// in the value of a config key.
+type Reference struct {
+	// Key is the config key whose value contains the invocation, e.g. "exporters::signalfx::access_token".
+	Key string `yaml:"key"`
+	// Source is the invoked config source or provider, e.g. "vault" or "env".
+	Source string `yaml:"source"`
+	// URI is the invocation without its delimiters, e.g. "vault:secret/data/kafka#password".
+	URI string `yaml:"uri"`
+}
+
+// ReferenceReport relates config keys to the config sources, confmap providers and environment
+// variables they consume, to help find unresolved references and unused secrets.
+type ReferenceReport struct {
+	// References are all references of the config.
+	References []Reference `yaml:"references"`
+	// Unresolved are the references to environment variables that aren't set and have no default,
+	// which resolve to an empty value.
+	Unresolved []Reference `yaml:"unresolved"`
+	// UnusedReferences are the references in the config of components that aren't enabled by the
+	// service section, whose values are fetched but never used.
+	UnusedReferences []Reference `yaml:"unused_references"`
+	// UnusedConfigSources are the declared config sources that aren't referenced.
+	UnusedConfigSources []string `yaml:"unused_config_sources"`
+}
+
+// FindReferences returns the references of the unresolved config ordered by key, following the
+// same expansion syntax as ResolveWithConfigSources.
+func FindReferences(conf map[string]any) []Reference {
+	var references []Reference
+	for k, v := range conf {
+		if k == configSourcesKey {
+			continue
+		}
+		references = appendReferences(references, k, v)
+	}
+	sort.SliceStable(references, func(i, j int) bool {
+		if references[i].Key != references[j].Key {
+			return references[i].Key < references[j].Key
+		}
+		return references[i].URI < references[j].URI
+	})
+	return references
+}
+
+func appendReferences(references []Reference, key string, value any) []Reference {
+	switch v := value.(type) {
+	case string:
+		return appendStringReferences(references, key, v)
+	case []any:
+		for _, item := range v {
+			references = appendReferences(references, key, item)
+		}
+	case map[string]any:
+		for k, item := range v {
+			references = appendReferences(references, key+confmap.KeyDelimiter+k, item)
+		}
+	case map[any]any:
+		for k, item := range cast.ToStringMap(v) {
+			references = appendReferences(references, key+confmap.KeyDelimiter+k, item)
+		}
+	}
+	return references
+}
+
+// appendStringReferences scans the string the way resolveStringValue expands it.
+func appendStringReferences(references []Reference, key, s string) []Reference {
+	for j := 0; j < len(s); j++ {
+		if s[j] != expandPrefixChar || j+1 >= len(s) {
+			continue
+		}
+		var content, cfgSrcName string
+		var w int
+		switch {
+		case s[j+1] == '{':
+			content, w, cfgSrcName = getBracketedExpandableContent(s, j+1)
+		case 'a' <= s[j+1] && s[j+1] <= 'z' || 'A' <= s[j+1] && s[j+1] <= 'Z':
+			content, w, cfgSrcName = getBareExpandableContent(s, j+1)
+		default:
+			// $$ escaping and other characters aren't expanded
+			j++
+			continue
+		}
+		if content != "" {
+			if cfgSrcName == "" {
+				cfgSrcName = "env"
+				content = "env:" + content
+			}
+			references = append(references, Reference{Key: key, Source: cfgSrcName, URI: content})
+		}
+		j += w
+	}
+	return references
+}
+
+// NewReferenceReport returns the reference report of the unresolved config, with the components
+// enabled by the service section of the effective config.
+func NewReferenceReport(conf, effective map[string]any) ReferenceReport {
+	report := ReferenceReport{References: FindReferences(conf)}
+
+	referenced := map[string]bool{}
+	enabled := enabledComponents(effective)
+	for _, reference := range report.References {
+		referenced[reference.Source] = true
+		if reference.Source == "env" && !envIsSet(reference.URI) {
+			report.Unresolved = append(report.Unresolved, reference)
+		}
+		parts := strings.SplitN(reference.Key, confmap.KeyDelimiter, 3)
+		if componentKinds[parts[0]] && len(parts) > 1 && !enabled[parts[0]+confmap.KeyDelimiter+parts[1]] {
+			report.UnusedReferences = append(report.UnusedReferences, reference)
+		}
+	}
+
+	if sources, ok := conf[configSourcesKey].(map[string]any); ok {
+		for name := range sources {
+			if !referenced[name] {
+				report.UnusedConfigSources = append(report.UnusedConfigSources, name)
+			}
+		}
+		sort.Strings(report.UnusedConfigSources)
+	}
+	return report
+}
+
+// envIsSet returns whether the environment variable of an "env:NAME[:-default]" invocation is set
+// or has a default.
+func envIsSet(uri string) bool {
+	name := strings.TrimPrefix(uri, "env:")
+	if _, _, hasDefault := strings.Cut(name, ":-"); hasDefault {
+		return true
+	}
+	_, ok := os.LookupEnv(name)
+	return ok
+}
+
+// enabledComponents returns the "<kind>::<id>" keys of the components enabled by the service section.
+func enabledComponents(effective map[string]any) map[string]bool {
+	enabled := map[string]bool{}
+	service := cast.ToStringMap(effective["service"])
+	for _, id := range cast.ToStringSlice(service["extensions"]) {
+		enabled["extensions"+confmap.KeyDelimiter+id] = true
+	}
+	for _, p := range cast.ToStringMap(service["pipelines"]) {
+		pipeline := cast.ToStringMap(p)
+		for _, kind := range []string{"receivers", "processors", "exporters"} {
+			for _, id := range cast.ToStringSlice(pipeline[kind]) {
+				enabled[kind+confmap.KeyDelimiter+id] = true
+				// connectors are both the exporter and receiver of pipelines
+				enabled["connectors"+confmap.KeyDelimiter+id] = true
+			}
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindReferences(t *testing.T) {
+	conf := map[string]any{
+		"config_sources": map[string]any{
+			"vault": map[string]any{"endpoint": "$VAULT_ADDR"},
+		},
+		"exporters": map[string]any{
+			"signalfx": map[string]any{
+				"access_token": "${vault:secret/data/signalfx#token}",
+				"api_url":      "https://api.${SPLUNK_REALM}.signalfx.com",
+				"headers":      []any{"$$NOT_EXPANDED", "$X_HEADER"},
+			},
+		},
+		"receivers": map[string]any{
+			"include": "${include:/etc/receivers.yaml}",
+		},
+	}
+	assert.Equal(t, []Reference{
+		{Key: "exporters::signalfx::access_token", Source: "vault", URI: "vault:secret/data/signalfx#token"},
+		{Key: "exporters::signalfx::api_url", Source: "env", URI: "env:SPLUNK_REALM"},
+		{Key: "exporters::signalfx::headers", Source: "env", URI: "env:X_HEADER"},
+		{Key: "receivers::include", Source: "include", URI: "include:/etc/receivers.yaml"},
+	}, FindReferences(conf))
+}
+
+func TestNewReferenceReport(t *testing.T) {
+	t.Setenv("SET_VAR", "value")
+	conf := map[string]any{
+		"config_sources": map[string]any{
+			"vault":     map[string]any{},
+			"vault/old": map[string]any{},
+		},
+		"receivers": map[string]any{
+			"otlp":  map[string]any{"endpoint": "${env:SET_VAR}"},
+			"kafka": map[string]any{"password": "${vault:secret/data/kafka#password}"},
+		},
+		"exporters": map[string]any{
+			"otlp": map[string]any{"endpoint": "${env:UNSET_VAR}", "timeout": "${env:UNSET_TIMEOUT:-5s}"},
+		},
+	}
+	effective := map[string]any{
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"otlp"},
+				},
+			},
+		},
+	}
+
+	report := NewReferenceReport(conf, effective)
+	assert.Len(t, report.References, 4)
+	assert.Equal(t, []Reference{
+		{Key: "exporters::otlp::endpoint", Source: "env", URI: "env:UNSET_VAR"},
+	}, report.Unresolved)
+	assert.Equal(t, []Reference{
+		{Key: "receivers::kafka::password", Source: "vault", URI: "vault:secret/data/kafka#password"},
+	}, report.UnusedReferences)
+	assert.Equal(t, []string{"vault/old"}, report.UnusedConfigSources)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/cast"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
 )
 
 const (
@@ -79,10 +80,40 @@ func SettingsFromConf(ctx context.Context, conf *confmap.Conf, factories Factori
 }
 
 func sourceSettings(ctx context.Context, v *confmap.Conf, factories Factories, confmapProviders map[string]confmap.Provider) (map[string]Settings, error) {
+	// The config sources of preceding factories are built first, so that the settings of the
+	// other config sources can reference them.
+	precedingSettings, err := resolveSourceSettings(ctx, v, factories, confmapProviders, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	preceding, err := BuildConfigSources(ctx, precedingSettings, zap.NewNop(), factories)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := resolveSourceSettings(ctx, v, factories, confmapProviders, preceding, false)
+	if err != nil {
+		return nil, err
+	}
+	for fullName, cfgSrcSettings := range precedingSettings {
+		settings[fullName] = cfgSrcSettings
+	}
+	return settings, nil
+}
+
+// resolveSourceSettings resolves and loads the settings of the config sources of preceding
+// factories, or of the other factories, with the given config sources.
+func resolveSourceSettings(
+	ctx context.Context,
+	v *confmap.Conf,
+	factories Factories,
+	confmapProviders map[string]confmap.Provider,
+	configSources map[string]ConfigSource,
+	preceding bool,
+) (map[string]Settings, error) {
 	splitMap := map[string]any{}
 	for _, key := range v.AllKeys() {
-		if strings.HasPrefix(key, configSourcesKey) {
-			value, _, err := resolveConfigValue(ctx, make(map[string]ConfigSource), confmapProviders, v.Get(key), nil)
+		if strings.HasPrefix(key, configSourcesKey) && isPrecedingSettingsKey(key, factories) == preceding {
+			value, _, err := resolveConfigValue(ctx, configSources, confmapProviders, v.Get(key), nil)
 			if err != nil {
 				return nil, err
 			}
@@ -97,6 +128,18 @@ func sourceSettings(ctx context.Context, v *confmap.Conf, factories Factories, c
 	return loadSettings(settingsMap.ToStringMap(), factories)
 }
 
+// isPrecedingSettingsKey returns whether the key is in the settings of a config source of a
+// preceding factory.
+func isPrecedingSettingsKey(key string, factories Factories) bool {
+	fullName, _, _ := strings.Cut(strings.TrimPrefix(key, configSourcesKey+confmap.KeyDelimiter), confmap.KeyDelimiter)
+	componentID := component.ID{}
+	if err := componentID.UnmarshalText([]byte(fullName)); err != nil {
+		return false
+	}
+	_, ok := factories[componentID.Type()].(PrecedingFactory)
+	return ok
+}
+
 func escapeDollarSigns(val any) any {
 	switch v := val.(type) {
 	case string:
@@ -27,6 +27,7 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.uber.org/zap"
 )
 
 func TestConfigSourceParser(t *testing.T) {
@@ -34,6 +35,7 @@ func TestConfigSourceParser(t *testing.T) {
 
 	testFactories := Factories{
 		component.MustNewType("tstcfgsrc"): &MockCfgSrcFactory{},
+		component.MustNewType("tstvars"):   &mockPrecedingCfgSrcFactory{},
 	}
 	tests := []struct {
 		factories        Factories
@@ -83,6 +85,27 @@ func TestConfigSourceParser(t *testing.T) {
 			factories: testFactories,
 			wantErr:   "config source \"tstcfgsrc\" not found",
 		},
+		{
+			name:      "preceding_cfgsrc",
+			file:      "preceding_cfgsrc",
+			factories: testFactories,
+			expectedSettings: map[string]Settings{
+				"tstvars": &MockCfgSrcSettings{
+					SourceSettings: NewSourceSettings(component.MustNewID("tstvars")),
+				},
+				"tstcfgsrc": &MockCfgSrcSettings{
+					SourceSettings: NewSourceSettings(component.MustNewID("tstcfgsrc")),
+					Endpoint:       "preceding_endpoint",
+					Token:          "some_token",
+				},
+			},
+		},
+		{
+			name:      "preceding_cfgsrc_cannot_use_cfgsrc",
+			file:      "preceding_cfgsrc_use_cfgsrc",
+			factories: testFactories,
+			wantErr:   "config source \"tstcfgsrc\" not found",
+		},
 		{
 			name:      "bad_name",
 			file:      "bad_name",
@@ -143,3 +166,29 @@ func TestConfigSourceParser(t *testing.T) {
 		})
 	}
 }
+
+// mockPrecedingCfgSrcFactory creates config sources retrieving a fixed endpoint, which the settings
+// of the other config sources can reference.
+type mockPrecedingCfgSrcFactory struct{}
+
+var _ PrecedingFactory = (*mockPrecedingCfgSrcFactory)(nil)
+
+func (m *mockPrecedingCfgSrcFactory) Type() component.Type {
+	return component.MustNewType("tstvars")
+}
+
+func (m *mockPrecedingCfgSrcFactory) CreateDefaultConfig() Settings {
+	return &MockCfgSrcSettings{
+		SourceSettings: NewSourceSettings(component.MustNewID("tstvars")),
+	}
+}
+
+func (m *mockPrecedingCfgSrcFactory) CreateConfigSource(context.Context, Settings, *zap.Logger) (ConfigSource, error) {
+	return &TestConfigSource{
+		ValueMap: map[string]valueEntry{
+			"endpoint": {Value: "preceding_endpoint"},
+		},
+	}, nil
+}
+
+func (m *mockPrecedingCfgSrcFactory) PrecedesOtherConfigSources() {}
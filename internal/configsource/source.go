@@ -79,6 +79,16 @@ type Factory interface {
 	Type() component.Type
 }
 
+// PrecedingFactory is a Factory of configuration sources built before the settings of the other
+// configuration sources are resolved, so that these settings can reference them, e.g. variables
+// shared by the settings of several configuration sources. The settings of preceding configuration
+// sources can't reference other configuration sources.
+type PrecedingFactory interface {
+	Factory
+	// PrecedesOtherConfigSources is a marker method.
+	PrecedesOtherConfigSources()
+}
+
 // Factories maps the type of a ConfigSource to the respective factory object.
 type Factories map[component.Type]Factory
 
@@ -0,0 +1,28 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package varconfigsource
+
+import "github.com/signalfx/splunk-otel-collector/internal/configsource"
+
+// Config holds the configuration for the creation of variable config source objects.
+type Config struct {
+	// Defaults specify the variables used if they aren't defined in the variables file, e.g. the
+	// values shared by most hosts.
+	Defaults map[string]any `mapstructure:"defaults"`
+	// File is the path of the YAML file defining the variables as top-level keys.
+	File string `mapstructure:"file"`
+
+	configsource.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+}
@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package varconfigsource
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+const (
+	// The "type" of variable config sources in configuration.
+	typeStr = "var"
+)
+
+type varFactory struct{}
+
+var _ configsource.PrecedingFactory = (*varFactory)(nil)
+
+func (f *varFactory) Type() component.Type {
+	return component.MustNewType(typeStr)
+}
+
+func (f *varFactory) CreateDefaultConfig() configsource.Settings {
+	return &Config{
+		SourceSettings: configsource.NewSourceSettings(component.MustNewID(typeStr)),
+	}
+}
+
+func (f *varFactory) CreateConfigSource(_ context.Context, settings configsource.Settings, _ *zap.Logger) (configsource.ConfigSource, error) {
+	return newConfigSource(settings.(*Config))
+}
+
+// PrecedesOtherConfigSources makes variables available to the settings of other config sources.
+func (f *varFactory) PrecedesOtherConfigSources() {}
+
+// NewFactory creates a factory for variable ConfigSource objects.
+func NewFactory() configsource.Factory {
+	return &varFactory{}
+}
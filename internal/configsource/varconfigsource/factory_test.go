@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package varconfigsource
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+func TestVarConfigSourceFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.MustNewType("var"), factory.Type())
+	assert.Implements(t, (*configsource.PrecedingFactory)(nil), factory)
+	tests := []struct {
+		config  *Config
+		name    string
+		wantErr string
+	}{
+		{
+			name:   "no_file",
+			config: &Config{Defaults: map[string]any{"k0": "v0"}},
+		},
+		{
+			name:   "file",
+			config: &Config{File: path.Join("testdata", "vars.yaml")},
+		},
+		{
+			name:    "missing_file",
+			config:  &Config{File: path.Join("testdata", "missing.yaml")},
+			wantErr: "failed to read the variables file",
+		},
+		{
+			name:    "not_a_mapping",
+			config:  &Config{File: path.Join("testdata", "list.yaml")},
+			wantErr: `variables file "testdata/list.yaml" must be a YAML mapping of variables to their values`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), tt.config, zap.NewNop())
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, actual)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, actual)
+		})
+	}
+}
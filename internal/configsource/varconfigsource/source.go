@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package varconfigsource
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource"
+)
+
+// Private error types to help with testability.
+type (
+	errInvalidRetrieveParams struct{ error }
+	errUndefinedVar          struct{ error }
+)
+
+type retrieveParams struct {
+	// Optional is used to change the default behavior when a variable requested via the config
+	// source is not defined. By default the value of this field is 'false' which will cause an
+	// error if the specified variable is not defined. Set it to 'true' to ignore undefined
+	// variables.
+	Optional bool `mapstructure:"optional"`
+}
+
+// varConfigSource retrieves the variables of its file, read once when it's created.
+type varConfigSource struct {
+	vars     map[string]any
+	defaults map[string]any
+	file     string
+}
+
+func newConfigSource(cfg *Config) (configsource.ConfigSource, error) {
+	source := &varConfigSource{vars: map[string]any{}, defaults: cfg.Defaults, file: cfg.File}
+	if cfg.File == "" {
+		return source, nil
+	}
+	content, err := os.ReadFile(cfg.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the variables file: %w", err)
+	}
+	retrieved, err := confmap.NewRetrievedFromYAML(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the variables file %q: %w", cfg.File, err)
+	}
+	raw, err := retrieved.AsRaw()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the variables file %q: %w", cfg.File, err)
+	}
+	switch vars := raw.(type) {
+	case nil:
+	case map[string]any:
+		source.vars = vars
+	default:
+		return nil, fmt.Errorf("variables file %q must be a YAML mapping of variables to their values", cfg.File)
+	}
+	return source, nil
+}
+
+func (v *varConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	actualParams := retrieveParams{}
+	if paramsConfigMap != nil {
+		paramsParser := confmap.NewFromStringMap(paramsConfigMap.ToStringMap())
+		if err := paramsParser.Unmarshal(&actualParams); err != nil {
+			return nil, &errInvalidRetrieveParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+
+	if value, ok := v.vars[selector]; ok {
+		return confmap.NewRetrieved(value)
+	}
+	value, ok := v.defaults[selector]
+	if !ok && !actualParams.Optional {
+		return nil, &errUndefinedVar{fmt.Errorf("var %q is required but not defined in %q and not present on defaults", selector, v.file)}
+	}
+	return confmap.NewRetrieved(value)
+}
@@ -0,0 +1,102 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package varconfigsource
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestVarConfigSource_Retrieve(t *testing.T) {
+	source, err := newConfigSource(&Config{
+		File:     path.Join("testdata", "vars.yaml"),
+		Defaults: map[string]any{"hostclass": "web", "log_level": "info"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		params   map[string]any
+		expected any
+		wantErr  error
+		name     string
+		selector string
+	}{
+		{
+			name:     "from_file",
+			selector: "hostclass",
+			expected: "db",
+		},
+		{
+			name:     "yaml_fragment",
+			selector: "processes",
+			expected: map[string]any{"postgres": map[string]any{"min": 1}},
+		},
+		{
+			name:     "list",
+			selector: "log_paths",
+			expected: []any{"/var/log/postgresql/*.log"},
+		},
+		{
+			name:     "from_defaults",
+			selector: "log_level",
+			expected: "info",
+		},
+		{
+			name:     "missing_not_required",
+			selector: "undefined",
+			params: map[string]any{
+				"optional": true,
+			},
+			expected: nil,
+		},
+		{
+			name: "invalid_param",
+			params: map[string]any{
+				"unknow_params_field": true,
+			},
+			wantErr: &errInvalidRetrieveParams{},
+		},
+		{
+			name:     "missing_required",
+			selector: "undefined",
+			wantErr:  &errUndefinedVar{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r, err := source.Retrieve(ctx, tt.selector, confmap.NewFromStringMap(tt.params), nil)
+			if tt.wantErr != nil {
+				assert.Nil(t, r)
+				require.IsType(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, r)
+
+			val, err := r.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+
+			assert.NoError(t, r.Close(ctx))
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const scopeName = "github.com/signalfx/splunk-otel-collector/internal/configsource/vaultconfigsource"
+
+// sharedClients holds the authenticated clients by endpoint and authentication, so config sources
+// reading different paths of the same Vault server authenticate only once.
+var sharedClients = struct {
+	clients map[string]*sharedClient
+	mu      sync.Mutex
+}{clients: map[string]*sharedClient{}}
+
+// secretRead is the result of reading a path, done is closed once it is available.
+type secretRead struct {
+	done   chan struct{}
+	secret *api.Secret
+	err    error
+}
+
+// sharedClient is an authenticated Vault client reading each registered path once per config
+// resolution. The first read of a path reads all the pending registered paths of its mount
+// concurrently, so the secrets referenced by a config are fetched in a single round trip.
+type sharedClient struct {
+	client        *api.Client
+	auth          Authentication
+	fetchDuration metric.Float64Histogram
+	reads         map[string]*secretRead
+	pending       map[string]struct{}
+	mu            sync.Mutex
+}
+
+// getSharedClient returns the client for the endpoint and authentication, authenticating it on first use.
+func getSharedClient(endpoint string, auth Authentication) (*sharedClient, error) {
+	authKey, err := json.Marshal(auth)
+	if err != nil {
+		return nil, err
+	}
+	key := endpoint + string(authKey)
+
+	sharedClients.mu.Lock()
+	defer sharedClients.mu.Unlock()
+	if sc, ok := sharedClients.clients[key]; ok {
+		return sc, nil
+	}
+	sc, err := newSharedClient(endpoint, auth)
+	if err != nil {
+		return nil, err
+	}
+	sharedClients.clients[key] = sc
+	return sc, nil
+}
+
+func newSharedClient(endpoint string, auth Authentication) (*sharedClient, error) {
+	// Client doesn't connect on creation and can't be closed. Keeping the same instance
+	// for all sessions is ok.
+	client, err := api.NewClient(&api.Config{
+		Address: endpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := getClientToken(client, auth)
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(token)
+
+	fetchDuration, err := otel.GetMeterProvider().Meter(scopeName).Float64Histogram(
+		"vault_config_source.fetch.duration",
+		metric.WithDescription("Duration of the reads of Vault secrets by the vault config source"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sharedClient{
+		client:        client,
+		auth:          auth,
+		fetchDuration: fetchDuration,
+		reads:         map[string]*secretRead{},
+		pending:       map[string]struct{}{},
+	}, nil
+}
+
+// register declares a path to be read by a config source. Any previous read of the path is
+// discarded so config sources created for a new config resolution read up-to-date secrets.
+func (sc *sharedClient) register(path string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.reads, path)
+	sc.pending[path] = struct{}{}
+}
+
+// read returns the secret at the path, reading it along with the other pending paths of its mount.
+func (sc *sharedClient) read(path string) (*api.Secret, error) {
+	sc.mu.Lock()
+	r, ok := sc.reads[path]
+	if !ok {
+		batch := map[string]*secretRead{}
+		sc.pending[path] = struct{}{}
+		for p := range sc.pending {
+			if mount(p) != mount(path) {
+				continue
+			}
+			batch[p] = &secretRead{done: make(chan struct{})}
+			sc.reads[p] = batch[p]
+			delete(sc.pending, p)
+		}
+		r = batch[path]
+		for p, pr := range batch {
+			go func(p string, pr *secretRead) {
+				pr.secret, pr.err = sc.readPath(p)
+				close(pr.done)
+			}(p, pr)
+		}
+	}
+	sc.mu.Unlock()
+
+	<-r.done
+	return r.secret, r.err
+}
+
+// readPath reads the path, authenticating again if the token was rejected.
+func (sc *sharedClient) readPath(path string) (*api.Secret, error) {
+	start := time.Now()
+	secret, err := sc.client.Logical().Read(path)
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden && sc.auth.Token == nil {
+		// the token of the shared client may have expired since it was authenticated.
+		var token string
+		if token, err = getClientToken(sc.client, sc.auth); err == nil {
+			sc.client.SetToken(token)
+			secret, err = sc.client.Logical().Read(path)
+		}
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	sc.fetchDuration.Record(context.Background(), time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("mount", mount(path)),
+		attribute.String("outcome", outcome),
+	))
+
+	if err != nil {
+		return nil, &errClientRead{err}
+	}
+
+	// Invalid path does not return error but a nil secret.
+	if secret == nil {
+		return nil, &errNilSecret{fmt.Errorf("no secret found at %q", path)}
+	}
+
+	// Incorrect path for v2 return nil data and warnings.
+	if secret.Data == nil {
+		return nil, &errNilSecretData{fmt.Errorf("no data at %q warnings: %v", path, secret.Warnings)}
+	}
+	return secret, nil
+}
+
+// mount returns the first segment of the path, i.e. the mount of its secrets engine.
+func mount(path string) string {
+	m, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return m
+}
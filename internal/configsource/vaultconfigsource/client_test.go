@@ -0,0 +1,125 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVaultServer(t *testing.T) (*httptest.Server, map[string]int, *sync.Mutex) {
+	reads := map[string]int{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v1/")
+		mu.Lock()
+		reads[path]++
+		mu.Unlock()
+		if strings.HasPrefix(path, "missing/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"path": "` + path + `"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, reads, &mu
+}
+
+func TestSharedClientBatchesReadsPerMount(t *testing.T) {
+	server, reads, mu := newTestVaultServer(t)
+	testToken := "token"
+	auth := Authentication{Token: &testToken}
+
+	sc, err := getSharedClient(server.URL, auth)
+	require.NoError(t, err)
+	other, err := getSharedClient(server.URL, auth)
+	require.NoError(t, err)
+	assert.Same(t, sc, other)
+
+	sc.register("secret/data/kafka")
+	sc.register("secret/data/kafka")
+	sc.register("secret/data/signalfx")
+	sc.register("kv/my-secret")
+
+	secret, err := sc.read("secret/data/kafka")
+	require.NoError(t, err)
+	assert.Equal(t, "secret/data/kafka", secret.Data["path"])
+
+	mu.Lock()
+	// the pending path of the same mount was read along with the requested one.
+	assert.Equal(t, map[string]int{"secret/data/kafka": 1, "secret/data/signalfx": 1}, reads)
+	mu.Unlock()
+
+	secret, err = sc.read("secret/data/signalfx")
+	require.NoError(t, err)
+	assert.Equal(t, "secret/data/signalfx", secret.Data["path"])
+	secret, err = sc.read("kv/my-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "kv/my-secret", secret.Data["path"])
+
+	_, err = sc.read("missing/secret")
+	assert.IsType(t, &errNilSecret{}, err)
+
+	mu.Lock()
+	assert.Equal(t, map[string]int{"secret/data/kafka": 1, "secret/data/signalfx": 1, "kv/my-secret": 1, "missing/secret": 1}, reads)
+	mu.Unlock()
+
+	// registering the path again, as done by the config sources of a new config resolution, reads it again.
+	sc.register("secret/data/kafka")
+	_, err = sc.read("secret/data/kafka")
+	require.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, 2, reads["secret/data/kafka"])
+	mu.Unlock()
+}
+
+func TestConfigSourcesShareClient(t *testing.T) {
+	server, reads, mu := newTestVaultServer(t)
+	testToken := "token"
+	cfg := func(path string) *Config {
+		return &Config{
+			Endpoint:       server.URL,
+			Authentication: &Authentication{Token: &testToken},
+			Path:           path,
+			PollInterval:   time.Minute,
+		}
+	}
+
+	kafka, err := newConfigSource(cfg("secret/data/kafka"), nil)
+	require.NoError(t, err)
+	signalfx, err := newConfigSource(cfg("secret/data/signalfx"), nil)
+	require.NoError(t, err)
+	assert.Same(t, kafka.(*vaultConfigSource).shared, signalfx.(*vaultConfigSource).shared)
+
+	require.NoError(t, kafka.(*vaultConfigSource).readSecret())
+	require.NoError(t, signalfx.(*vaultConfigSource).readSecret())
+	mu.Lock()
+	assert.Equal(t, map[string]int{"secret/data/kafka": 1, "secret/data/signalfx": 1}, reads)
+	mu.Unlock()
+}
+
+func TestMount(t *testing.T) {
+	assert.Equal(t, "secret", mount("secret/data/kafka"))
+	assert.Equal(t, "secret", mount("/secret/data/kafka"))
+	assert.Equal(t, "kv", mount("kv"))
+}
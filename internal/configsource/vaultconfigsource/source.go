@@ -43,6 +43,7 @@ type (
 // vaultConfigSource implements the configprovider.Session interface.
 type vaultConfigSource struct {
 	logger *zap.Logger
+	shared *sharedClient
 	client *api.Client
 	secret *api.Secret
 
@@ -52,29 +53,20 @@ type vaultConfigSource struct {
 }
 
 func newConfigSource(cfg *Config, logger *zap.Logger) (configsource.ConfigSource, error) {
-	// Client doesn't connect on creation and can't be closed. Keeping the same instance
-	// for all sessions is ok.
-	client, err := api.NewClient(&api.Config{
-		Address: cfg.Endpoint,
-	})
-	if err != nil {
-		return nil, err
+	if cfg.PollInterval <= 0 {
+		return nil, errInvalidPollInterval
 	}
 
-	token, err := getClientToken(client, *cfg.Authentication)
+	shared, err := getSharedClient(cfg.Endpoint, *cfg.Authentication)
 	if err != nil {
 		return nil, err
 	}
-
-	client.SetToken(token)
-
-	if cfg.PollInterval <= 0 {
-		return nil, errInvalidPollInterval
-	}
+	shared.register(cfg.Path)
 
 	return &vaultConfigSource{
 		logger:       logger,
-		client:       client,
+		shared:       shared,
+		client:       shared.client,
 		path:         cfg.Path,
 		pollInterval: cfg.PollInterval,
 	}, nil
@@ -126,21 +118,10 @@ func (v *vaultConfigSource) Retrieve(_ context.Context, selector string, _ *conf
 // readSecret reads the secret from the vaultConfigSource path and if successful
 // it stores the secret on the vaultConfigSource secret field.
 func (v *vaultConfigSource) readSecret() error {
-	secret, err := v.client.Logical().Read(v.path)
+	secret, err := v.shared.read(v.path)
 	if err != nil {
-		return &errClientRead{err}
-	}
-
-	// Invalid path does not return error but a nil secret.
-	if secret == nil {
-		return &errNilSecret{fmt.Errorf("no secret found at %q", v.path)}
-	}
-
-	// Incorrect path for v2 return nil data and warnings.
-	if secret.Data == nil {
-		return &errNilSecretData{fmt.Errorf("no data at %q warnings: %v", v.path, secret.Warnings)}
+		return err
 	}
-
 	v.secret = secret
 	return nil
 }
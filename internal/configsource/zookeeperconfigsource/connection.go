@@ -26,6 +26,7 @@ import (
 // the connection in tests.
 type zkConnection interface {
 	GetW(string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+	ChildrenW(string) ([]string, *zk.Stat, <-chan zk.Event, error)
 
 	Close()
 }
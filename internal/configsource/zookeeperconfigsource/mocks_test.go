@@ -18,6 +18,8 @@ package zookeeperconfigsource
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/go-zookeeper/zk"
 )
@@ -47,6 +49,24 @@ func (m *mockConnection) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, er
 	return nil, nil, nil, fmt.Errorf("value not found")
 }
 
+func (m *mockConnection) ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	if _, ok := m.db[key]; !ok {
+		return nil, nil, nil, fmt.Errorf("value not found")
+	}
+	children := map[string]struct{}{}
+	for k := range m.db {
+		if rest, ok := strings.CutPrefix(k, key+"/"); ok {
+			children[strings.Split(rest, "/")[0]] = struct{}{}
+		}
+	}
+	var names []string
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, &zk.Stat{}, nil, nil
+}
+
 func (m *mockConnection) Close() {
 	close(m.watcherCh)
 	m.watcherCh = nil
@@ -19,6 +19,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
+	"sync"
 	"time"
 
 	"github.com/go-zookeeper/zk"
@@ -46,50 +48,75 @@ func newZkConfigSource(connect connectFunc) *zkConfigSource {
 	}
 }
 
-func (s *zkConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+func (s *zkConfigSource) Retrieve(ctx context.Context, selector string, params *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
 	conn, err := s.connect(ctx)
 	if err != nil {
 		return nil, err
 	}
+
+	if configsource.IsDocumentRequested(params) {
+		var watchChs []<-chan zk.Event
+		doc, err := getDocument(conn, selector, &watchChs)
+		if err != nil {
+			return nil, err
+		}
+		return newRetrieved(doc, conn, watchChs, watcher)
+	}
+
 	value, _, watchCh, err := conn.GetW(selector)
 	if err != nil {
 		return nil, err
 	}
+	return newRetrieved(string(value), conn, []<-chan zk.Event{watchCh}, watcher)
+}
+
+// getDocument returns the YAML document of the node merged with the documents of all its
+// descendants, appending the watch channels of every visited node to watchChs.
+func getDocument(conn zkConnection, node string, watchChs *[]<-chan zk.Event) (any, error) {
+	value, _, watchCh, err := conn.GetW(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %q: %w", node, err)
+	}
+	names, _, childrenWatchCh, err := conn.ChildrenW(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children of %q: %w", node, err)
+	}
+	*watchChs = append(*watchChs, watchCh, childrenWatchCh)
+
+	children := map[string]any{}
+	for _, name := range names {
+		child, err := getDocument(conn, path.Join(node, name), watchChs)
+		if err != nil {
+			return nil, err
+		}
+		children[name] = child
+	}
+	doc, err := configsource.NewDocument(string(value), children)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document at %q: %w", node, err)
+	}
+	return doc, nil
+}
 
+func newRetrieved(value any, conn zkConnection, watchChs []<-chan zk.Event, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
 	closeCh := make(chan struct{})
-	startWatcher(watchCh, closeCh, watcher)
-	return confmap.NewRetrieved(string(value), confmap.WithRetrievedClose(func(_ context.Context) error {
+	if watcher != nil {
+		// report only the first event of any watched node, the config is retrieved again upon it.
+		var once sync.Once
+		watchOnce := func(event *confmap.ChangeEvent) {
+			once.Do(func() { watcher(event) })
+		}
+		for _, watchCh := range watchChs {
+			startWatcher(watchCh, closeCh, watchOnce)
+		}
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(func(_ context.Context) error {
 		close(closeCh)
 		conn.Close()
 		return nil
 	}))
 }
 
-func startWatcher(watchCh <-chan zk.Event, closeCh <-chan struct{}, watcher confmap.WatcherFunc) {
-	go func() {
-		select {
-		case <-closeCh:
-			return
-		case e, ok := <-watchCh:
-			if !ok {
-				// Channel close without any event, connection must have been closed.
-				return
-			}
-			if e.Err != nil {
-				watcher(&confmap.ChangeEvent{Error: e.Err})
-			}
-
-			switch e.Type {
-			case zk.EventNodeCreated, zk.EventNodeDataChanged, zk.EventNodeChildrenChanged:
-				// EventNodeCreated should never happen but we cover it for completeness.
-				watcher(&confmap.ChangeEvent{})
-				return
-			}
-			watcher(&confmap.ChangeEvent{Error: fmt.Errorf("zookeeper watcher stopped")})
-		}
-	}()
-}
-
 // newConnectFunc returns a new function that can be used to establish and return a connection
 // to a zookeeper cluster. Every function returned by newConnectFunc will return the same
 // underlying connection until it is lost.
@@ -116,3 +116,29 @@ func TestWatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionRetrieveDocument(t *testing.T) {
+	conn := newMockConnection(map[string]string{
+		"/otel":                       "",
+		"/otel/receivers":             "otlp:\n  protocols:\n    grpc:\n",
+		"/otel/receivers/hostmetrics": "collection_interval: 10s",
+		"/otel/invalid":               "[a, b]",
+		"/otel/invalid/child":         "value",
+	})
+	source := newZkConfigSource(newMockConnectFunc(conn))
+	params := confmap.NewFromStringMap(map[string]any{"document": true})
+
+	retrieved, err := source.Retrieve(context.Background(), "/otel/receivers", params, nil)
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"otlp":        map[string]any{"protocols": map[string]any{"grpc": nil}},
+		"hostmetrics": map[string]any{"collection_interval": "10s"},
+	}, val)
+	assert.NoError(t, retrieved.Close(context.Background()))
+
+	retrieved, err = source.Retrieve(context.Background(), "/otel/invalid", params, nil)
+	assert.EqualError(t, err, `invalid document at "/otel/invalid": cannot merge children into a document of type []interface {}`)
+	assert.Nil(t, retrieved)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/envvarconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/etcd2configsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/includeconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/varconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/vaultconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/zookeeperconfigsource"
 )
@@ -36,6 +37,7 @@ var configSourceFactories = func() configsource.Factories {
 	for _, f := range []configsource.Factory{
 		envvarconfigsource.NewFactory(),
 		includeconfigsource.NewFactory(),
+		varconfigsource.NewFactory(),
 		vaultconfigsource.NewFactory(),
 		zookeeperconfigsource.NewFactory(),
 		etcd2configsource.NewFactory(),
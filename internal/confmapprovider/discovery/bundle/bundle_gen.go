@@ -16,23 +16,35 @@
 
 // These are the discovery config component generating statements.
 // In order to update run go generate -tags bundle.d ./...
+//go:generate discoverybundler --render --template bundle.d/extensions/containerd-observer.discovery.yaml.tmpl
+//go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/extensions -t bundle.d/extensions/containerd-observer.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/extensions/docker-observer.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/extensions -t bundle.d/extensions/docker-observer.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/extensions/host-observer.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/extensions -t bundle.d/extensions/host-observer.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/extensions/k8s-observer.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/extensions -t bundle.d/extensions/k8s-observer.discovery.yaml.tmpl
+//go:generate discoverybundler --render --template bundle.d/extensions/podman-observer.discovery.yaml.tmpl
+//go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/extensions -t bundle.d/extensions/podman-observer.discovery.yaml.tmpl
 
 //go:generate discoverybundler --render --template bundle.d/receivers/apache.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/apache.discovery.yaml.tmpl
+//go:generate discoverybundler --render --template bundle.d/receivers/haproxy.discovery.yaml.tmpl
+//go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/haproxy.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/receivers/jmx-cassandra.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/jmx-cassandra.discovery.yaml.tmpl
+//go:generate discoverybundler --render --template bundle.d/receivers/jmx-kafka.discovery.yaml.tmpl
+//go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/jmx-kafka.discovery.yaml.tmpl
+//go:generate discoverybundler --render --template bundle.d/receivers/jmx-tomcat.discovery.yaml.tmpl
+//go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/jmx-tomcat.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/receivers/kafkametrics.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/kafkametrics.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/receivers/mongodb.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/mongodb.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/receivers/mysql.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/mysql.discovery.yaml.tmpl
+//go:generate discoverybundler --render --template bundle.d/receivers/nginx.discovery.yaml.tmpl
+//go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/nginx.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/receivers/oracledb.discovery.yaml.tmpl
 //go:generate discoverybundler --render --commented --dir ../../../../cmd/otelcol/config/collector/config.d.linux/receivers -t bundle.d/receivers/oracledb.discovery.yaml.tmpl
 //go:generate discoverybundler --render --template bundle.d/receivers/postgresql.discovery.yaml.tmpl
@@ -28,10 +28,14 @@ func TestBundleDir(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []string{
 		"bundle.d/receivers/apache.discovery.yaml",
+		"bundle.d/receivers/haproxy.discovery.yaml",
 		"bundle.d/receivers/jmx-cassandra.discovery.yaml",
+		"bundle.d/receivers/jmx-kafka.discovery.yaml",
+		"bundle.d/receivers/jmx-tomcat.discovery.yaml",
 		"bundle.d/receivers/kafkametrics.discovery.yaml",
 		"bundle.d/receivers/mongodb.discovery.yaml",
 		"bundle.d/receivers/mysql.discovery.yaml",
+		"bundle.d/receivers/nginx.discovery.yaml",
 		"bundle.d/receivers/oracledb.discovery.yaml",
 		"bundle.d/receivers/postgresql.discovery.yaml",
 		"bundle.d/receivers/rabbitmq.discovery.yaml",
@@ -45,8 +49,10 @@ func TestBundleDir(t *testing.T) {
 	extensions, err := fs.Glob(BundledFS, "bundle.d/extensions/*.discovery.yaml")
 	require.NoError(t, err)
 	require.Equal(t, []string{
+		"bundle.d/extensions/containerd-observer.discovery.yaml",
 		"bundle.d/extensions/docker-observer.discovery.yaml",
 		"bundle.d/extensions/host-observer.discovery.yaml",
 		"bundle.d/extensions/k8s-observer.discovery.yaml",
+		"bundle.d/extensions/podman-observer.discovery.yaml",
 	}, extensions)
 }
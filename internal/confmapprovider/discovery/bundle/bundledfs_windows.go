@@ -22,14 +22,20 @@ import (
 
 // BundledFS is the in-executable filesystem that contains all bundled discovery config.d components.
 
+//go:embed bundle.d/extensions/containerd-observer.discovery.yaml
 //go:embed bundle.d/extensions/docker-observer.discovery.yaml
 //go:embed bundle.d/extensions/host-observer.discovery.yaml
 //go:embed bundle.d/extensions/k8s-observer.discovery.yaml
+//go:embed bundle.d/extensions/podman-observer.discovery.yaml
 //go:embed bundle.d/receivers/apache.discovery.yaml
+//go:embed bundle.d/receivers/haproxy.discovery.yaml
 //go:embed bundle.d/receivers/jmx-cassandra.discovery.yaml
+//go:embed bundle.d/receivers/jmx-kafka.discovery.yaml
+//go:embed bundle.d/receivers/jmx-tomcat.discovery.yaml
 //go:embed bundle.d/receivers/kafkametrics.discovery.yaml
 //go:embed bundle.d/receivers/mongodb.discovery.yaml
 //go:embed bundle.d/receivers/mysql.discovery.yaml
+//go:embed bundle.d/receivers/nginx.discovery.yaml
 //go:embed bundle.d/receivers/oracledb.discovery.yaml
 //go:embed bundle.d/receivers/postgresql.discovery.yaml
 //go:embed bundle.d/receivers/rabbitmq.discovery.yaml
@@ -23,19 +23,25 @@ var (
 	// If they are desired for !windows BundledFS inclusion (and a default linux conf.d entry), ensure they are included
 	// in Components.Linux. If desired in windows BundledFS, ensure they are included in Components.Windows.
 	extensions = []string{
+		"containerd-observer",
 		"docker-observer",
 		"host-observer",
 		"k8s-observer",
+		"podman-observer",
 	}
 	// These are receivers that must match corresponding bundle.d/receivers/<NAME>.discovery.yaml.tmpl files
 	// If they are desired for !windows BundledFS inclusion (and a default linux conf.d entry), ensure they are included
 	// in Components.Linux. If desired in windows BundledFS, ensure they are included in Components.Windows.
 	receivers = []string{
 		"apache",
+		"haproxy",
 		"jmx-cassandra",
+		"jmx-kafka",
+		"jmx-tomcat",
 		"kafkametrics",
 		"mongodb",
 		"mysql",
+		"nginx",
 		"oracledb",
 		"postgresql",
 		"rabbitmq",
@@ -68,10 +74,14 @@ var (
 		Windows: func() map[string]struct{} {
 			windows := map[string]struct{}{
 				"apache":                {},
+				"haproxy":               {},
 				"jmx-cassandra":         {},
+				"jmx-kafka":             {},
+				"jmx-tomcat":            {},
 				"kafkametrics":          {},
 				"mongodb":               {},
 				"mysql":                 {},
+				"nginx":                 {},
 				"oracledb":              {},
 				"postgresql":            {},
 				"rabbitmq":              {},
@@ -51,6 +51,7 @@ import (
 	"github.com/signalfx/splunk-otel-collector/internal/components"
 	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery/internal"
 	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery/properties"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/containerdobserver"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver"
 	"github.com/signalfx/splunk-otel-collector/internal/version"
 )
@@ -381,6 +382,14 @@ func (d *discoverer) discoveryReceiversConfigs(cfg *Config) (map[string]any, err
 				return nil, fmt.Errorf("failed obtaining receivers properties config: %w", err)
 			}
 		}
+		var defaultResourceAttributes map[string]any
+		if d.propertiesConf.IsSet(properties.ResourceAttributesType) {
+			resourceAttributesConf, err := d.propertiesConf.Sub(properties.ResourceAttributesType)
+			if err != nil {
+				return nil, fmt.Errorf("failed obtaining resource attributes properties config: %w", err)
+			}
+			defaultResourceAttributes = resourceAttributesConf.ToStringMap()
+		}
 		for receiverID, receiver := range cfg.ReceiversToDiscover {
 			if ok, updErr := d.updateReceiverForObserver(receiverID, receiver, observerID); updErr != nil {
 				return nil, updErr
@@ -418,6 +427,7 @@ func (d *discoverer) discoveryReceiversConfigs(cfg *Config) (map[string]any, err
 			if !enabled {
 				continue
 			}
+			addDefaultResourceAttributes(receiverEntry, defaultResourceAttributes)
 
 			d.addUnexpandedReceiverConfig(receiverID, observerID, receiverEntry)
 			receiversSection[receiverID.String()] = receiverEntry
@@ -430,6 +440,26 @@ func (d *discoverer) discoveryReceiversConfigs(cfg *Config) (map[string]any, err
 	return discoveryReceiversConfigs, nil
 }
 
+// addDefaultResourceAttributes adds the resource_attributes discovery properties to the receiver entry
+// without overriding any resource attributes it already sets.
+func addDefaultResourceAttributes(receiverEntry, defaults map[string]any) {
+	if len(defaults) == 0 {
+		return
+	}
+	resourceAttributes := map[string]any{}
+	if existing, ok := receiverEntry["resource_attributes"].(map[string]any); ok {
+		for k, v := range existing {
+			resourceAttributes[k] = v
+		}
+	}
+	for k, v := range defaults {
+		if _, ok := resourceAttributes[k]; !ok {
+			resourceAttributes[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	receiverEntry["resource_attributes"] = resourceAttributes
+}
+
 func (d *discoverer) prepareObserverConfigs(cfg *Config) {
 	for _, observerID := range cfg.observersForDiscoveryMode() {
 		if err := d.prepareObserverConfig(observerID, cfg); err != nil {
@@ -483,22 +513,44 @@ func (d *discoverer) prepareObserverConfig(observerID component.ID, cfg *Config)
 	return nil
 }
 
+// ruleFallbackTypes are the observer types whose endpoints have the same form as another type's,
+// so receivers without rules for them are discovered with the other type's rules.
+var ruleFallbackTypes = map[component.Type]component.Type{
+	component.MustNewType("containerd_observer"): component.MustNewType("docker_observer"),
+}
+
+// ruleObserverID returns the observer ID whose rule and config the receiver is discovered with by
+// the observer: its own, that of its type if it's a named observer (e.g. docker_observer/podman),
+// or that of its fallback type.
+func ruleObserverID(receiver ReceiverToDiscoverEntry, observerID component.ID) (component.ID, bool) {
+	candidates := []component.ID{observerID, component.NewID(observerID.Type())}
+	if fallback, ok := ruleFallbackTypes[observerID.Type()]; ok {
+		candidates = append(candidates, component.NewID(fallback))
+	}
+	for _, candidate := range candidates {
+		if _, ok := receiver.Rule[candidate]; ok {
+			return candidate, true
+		}
+	}
+	return observerID, false
+}
+
 func (d *discoverer) updateReceiverForObserver(receiverID component.ID, receiver ReceiverToDiscoverEntry, observerID component.ID) (bool, error) {
-	observerRule, hasRule := receiver.Rule[observerID]
+	ruleID, hasRule := ruleObserverID(receiver, observerID)
 	if !hasRule {
 		d.logger.Debug(fmt.Sprintf("disregarding %q without a %q rule", receiverID, observerID))
 		return false, nil
 	}
-	receiver.Entry["rule"] = observerRule
+	receiver.Entry["rule"] = receiver.Rule[ruleID]
 
 	var defaultConfig map[string]any
 	defaultConfig, hasDefault := receiver.Config[defaultType]
 	if hasDefault {
 		receiver.Entry["config"] = defaultConfig
 	}
-	observerConfigBlock, hasObserverConfigBlock := receiver.Config[observerID]
+	observerConfigBlock, hasObserverConfigBlock := receiver.Config[ruleID]
 	if !hasObserverConfigBlock && !hasDefault {
-		d.logger.Debug(fmt.Sprintf("disregarding %q without a default and %q config", receiverID, observerID))
+		d.logger.Debug(fmt.Sprintf("disregarding %q without a default and %q config", receiverID, ruleID))
 		return false, nil
 	}
 	if hasObserverConfigBlock {
@@ -515,10 +567,11 @@ func (d *discoverer) updateReceiverForObserver(receiverID component.ID, receiver
 
 func factoryForObserverType(extType component.Type) (otelcolextension.Factory, error) {
 	factories := map[component.Type]otelcolextension.Factory{
-		component.MustNewType("docker_observer"):   dockerobserver.NewFactory(),
-		component.MustNewType("host_observer"):     hostobserver.NewFactory(),
-		component.MustNewType("k8s_observer"):      k8sobserver.NewFactory(),
-		component.MustNewType("ecs_task_observer"): ecstaskobserver.NewFactory(),
+		component.MustNewType("containerd_observer"): containerdobserver.NewFactory(),
+		component.MustNewType("docker_observer"):     dockerobserver.NewFactory(),
+		component.MustNewType("host_observer"):       hostobserver.NewFactory(),
+		component.MustNewType("k8s_observer"):        k8sobserver.NewFactory(),
+		component.MustNewType("ecs_task_observer"):   ecstaskobserver.NewFactory(),
 	}
 
 	ef, ok := factories[extType]
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 
@@ -86,3 +87,66 @@ func TestDetermineCurrentStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestAddDefaultResourceAttributes(t *testing.T) {
+	entry := map[string]any{
+		"rule": `type == "port"`,
+		"resource_attributes": map[string]any{
+			"team": "receiver-team",
+		},
+	}
+	addDefaultResourceAttributes(entry, map[string]any{
+		"deployment.environment": "production",
+		"team":                   "default-team",
+	})
+	require.Equal(t, map[string]any{
+		"rule": `type == "port"`,
+		"resource_attributes": map[string]any{
+			"deployment.environment": "production",
+			"team":                   "receiver-team",
+		},
+	}, entry)
+
+	entry = map[string]any{"rule": `type == "port"`}
+	addDefaultResourceAttributes(entry, nil)
+	require.Equal(t, map[string]any{"rule": `type == "port"`}, entry)
+}
+
+func TestRuleObserverID(t *testing.T) {
+	dockerObserver := component.MustNewID("docker_observer")
+	podmanObserver := component.MustNewIDWithName("docker_observer", "podman")
+	containerdObserver := component.MustNewID("containerd_observer")
+	hostObserver := component.MustNewID("host_observer")
+
+	receiver := ReceiverToDiscoverEntry{Rule: map[component.ID]string{
+		dockerObserver: `type == "container"`,
+		hostObserver:   `type == "hostport"`,
+	}}
+	for _, tt := range []struct {
+		observerID component.ID
+		expectedID component.ID
+		hasRule    bool
+	}{
+		{observerID: dockerObserver, expectedID: dockerObserver, hasRule: true},
+		{observerID: podmanObserver, expectedID: dockerObserver, hasRule: true},
+		{observerID: containerdObserver, expectedID: dockerObserver, hasRule: true},
+		{observerID: component.MustNewIDWithName("host_observer", "other"), expectedID: hostObserver, hasRule: true},
+		{observerID: component.MustNewID("k8s_observer"), expectedID: component.MustNewID("k8s_observer")},
+	} {
+		t.Run(tt.observerID.String(), func(t *testing.T) {
+			id, hasRule := ruleObserverID(receiver, tt.observerID)
+			require.Equal(t, tt.hasRule, hasRule)
+			require.Equal(t, tt.expectedID, id)
+		})
+	}
+
+	// an observer's own rule takes precedence
+	receiver.Rule[podmanObserver] = `type == "container" and name == "podman"`
+	receiver.Rule[containerdObserver] = `type == "container" and name == "containerd"`
+	id, hasRule := ruleObserverID(receiver, podmanObserver)
+	require.True(t, hasRule)
+	require.Equal(t, podmanObserver, id)
+	id, hasRule = ruleObserverID(receiver, containerdObserver)
+	require.True(t, hasRule)
+	require.Equal(t, containerdObserver, id)
+}
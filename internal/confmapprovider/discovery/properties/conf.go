@@ -33,9 +33,10 @@ type File struct {
 }
 
 type Mapping struct {
-	Receivers  map[string]Entry `mapstructure:"receivers"`
-	Extensions map[string]Entry `mapstructure:"extensions"`
-	Unknown    map[any]any      `mapstructure:",remain"`
+	Receivers          map[string]Entry `mapstructure:"receivers"`
+	Extensions         map[string]Entry `mapstructure:"extensions"`
+	ResourceAttributes map[string]any   `mapstructure:"resource_attributes"`
+	Unknown            map[any]any      `mapstructure:",remain"`
 }
 
 type Entry struct {
@@ -103,6 +104,15 @@ func LoadConf(raw map[string]any) (properties *confmap.Conf, warning, fatal erro
 		}
 	}
 
+	var resourceAttributes []string
+	for attr := range file.ResourceAttributes {
+		resourceAttributes = append(resourceAttributes, attr)
+	}
+	sort.Strings(resourceAttributes)
+	for _, attr := range resourceAttributes {
+		props = append(props, propTuple{key: fmt.Sprintf("%s%s", resourceAttributesPrefixS, attr), value: file.ResourceAttributes[attr]})
+	}
+
 	// --set property form has priority to splunk.discovery mappings (processed afterward and overwrites)
 	entriesConf := confmap.NewFromStringMap(file.Entries)
 	for _, k := range entriesConf.AllKeys() {
@@ -70,6 +70,20 @@ func TestConf(t *testing.T) {
 	}
 }
 
+func TestConfResourceAttributes(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join(".", "testdata", "valid-resource-attributes.yaml"))
+	require.NoError(t, err)
+	loaded, warning, fatal := LoadConf(conf.ToStringMap())
+	require.NoError(t, warning)
+	require.NoError(t, fatal)
+	require.Equal(t, map[string]any{
+		"resource_attributes": map[string]any{
+			"deployment.environment": "production",
+			"team":                   "observability",
+		},
+	}, loaded.ToStringMap())
+}
+
 func TestInvalidPropertiesIgnoredWhenLoadingConf(t *testing.T) {
 	for _, fixture := range []struct {
 		expectedError string
@@ -38,6 +38,11 @@ import (
 // with corresponding env var:
 // SPLUNK_DISCOVERY_RECEIVERS_receiver_x2d_type_x2f_receiver_x2d_name_CONFIG_field_x3a__x3a_subfield=value
 // SPLUNK_DISCOVERY_EXTENSIONS_observer_x2d_type_x2f_observer_x2d_name_CONFIG_field_x3a__x3a_subfield=value
+//
+// Default resource attributes for all discovered receivers are of the format:
+// splunk.discovery.resource_attributes.<attribute>=value
+// with corresponding env var:
+// SPLUNK_DISCOVERY_RESOURCE_ATTRIBUTES_attribute_x2e_key=value
 
 // Parsing properties requires lookaheads (backtracking), which isn't possible in re2. Using participle we
 // can define a simple lexer and grammar to establish the Property type as an ast.
@@ -54,8 +59,9 @@ var parser = participle.MustBuild[Property](
 	participle.UseLookahead(participle.MaxLookahead),
 )
 
-// Property is the ast for a parsed property.
-// TODO: support rules and resource_attributes instead of just embedded config
+// Property is the ast for a parsed property. Only the config and enabled state of
+// components can be set: receiver rules, status and resource_attributes are set in
+// their discovery config files.
 type Property struct {
 	stringMap     map[string]any
 	ComponentType string      `parser:"'splunk' Dot 'discovery' Dot @('receivers' | 'extensions') Dot"`
@@ -72,6 +78,9 @@ type ComponentID struct {
 }
 
 func NewProperty(property, val string) (*Property, error) {
+	if strings.HasPrefix(property, resourceAttributesPrefixS) {
+		return newResourceAttributeProperty(property, val)
+	}
 	p, err := parser.ParseString("splunk.discovery", property)
 	if err != nil {
 		return nil, fmt.Errorf("invalid property %q (parsing error): %w", property, err)
@@ -108,8 +117,29 @@ func NewProperty(property, val string) (*Property, error) {
 	return p, nil
 }
 
+// newResourceAttributeProperty parses a splunk.discovery.resource_attributes.<attribute> property,
+// whose value is added to the resource_attributes of every discovered receiver not already setting it.
+func newResourceAttributeProperty(property, val string) (*Property, error) {
+	key := strings.TrimPrefix(property, resourceAttributesPrefixS)
+	if key == "" {
+		return nil, fmt.Errorf("invalid property %q: missing resource attribute name", property)
+	}
+	return &Property{
+		stringMap: map[string]any{
+			ResourceAttributesType: map[string]any{key: val},
+		},
+		ComponentType: ResourceAttributesType,
+		Key:           key,
+		Val:           val,
+		Input:         property,
+	}, nil
+}
+
 // ToEnvVar will output the equivalent env var property for informational purposes.
 func (p *Property) ToEnvVar() string {
+	if p.ComponentType == ResourceAttributesType {
+		return fmt.Sprintf("%s%s", resourceAttributesEnvVarPrefixS, wordify(p.Key))
+	}
 	envVar := envVarPrefixS
 	envVar = fmt.Sprintf("%s%s_", envVar, strings.ToUpper(p.ComponentType))
 	envVar = fmt.Sprintf("%s%s", envVar, wordify(p.Component.Type))
@@ -133,7 +163,12 @@ func (p *Property) ToStringMap() map[string]any {
 }
 
 const (
-	envVarPrefixS = "SPLUNK_DISCOVERY_"
+	// ResourceAttributesType is the root key of default resource attribute properties.
+	ResourceAttributesType = "resource_attributes"
+
+	envVarPrefixS                   = "SPLUNK_DISCOVERY_"
+	resourceAttributesPrefixS       = "splunk.discovery.resource_attributes."
+	resourceAttributesEnvVarPrefixS = "SPLUNK_DISCOVERY_RESOURCE_ATTRIBUTES_"
 )
 
 var (
@@ -145,6 +180,14 @@ func NewPropertyFromEnvVar(envVar, val string) (*Property, bool, error) {
 	if !envVarPrefixRE.MatchString(envVar) {
 		return nil, false, nil
 	}
+	if strings.HasPrefix(envVar, resourceAttributesEnvVarPrefixS) {
+		key, err := unwordify(strings.TrimPrefix(envVar, resourceAttributesEnvVarPrefixS))
+		if err != nil {
+			return nil, true, fmt.Errorf("failed parsing env var property resource attribute: %w", err)
+		}
+		prop, err := NewProperty(fmt.Sprintf("%s%s", resourceAttributesPrefixS, key), val)
+		return prop, true, err
+	}
 	evp, err := NewEnvVarProperty(envVar, val)
 	if err != nil {
 		return nil, true, fmt.Errorf("invalid env var property (parsing error): %w", err)
@@ -194,6 +194,19 @@ func TestValidProperties(t *testing.T) {
 				Input:         "splunk.discovery.receivers.receiver_type////.enabled",
 			},
 		},
+		{key: "splunk.discovery.resource_attributes.deployment.environment", val: "production",
+			expected: &Property{
+				stringMap: map[string]any{
+					"resource_attributes": map[string]any{
+						"deployment.environment": "production",
+					},
+				},
+				ComponentType: "resource_attributes",
+				Key:           "deployment.environment",
+				Val:           "production",
+				Input:         "splunk.discovery.resource_attributes.deployment.environment",
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s=%s", tt.key, tt.val), func(t *testing.T) {
 			p, err := NewProperty(tt.key, tt.val)
@@ -219,6 +232,7 @@ func TestInvalidProperties(t *testing.T) {
 		property, expectedError string
 	}{
 		{property: "splunk.discovery.invalid", expectedError: "invalid property \"splunk.discovery.invalid\" (parsing error): splunk.discovery:1:18: unexpected token \"invalid\" (expected (\"receivers\" | \"extensions\") <dot> ComponentID <dot> ((\"config\" <dot>) | \"enabled\") (<string> | <dot> | <forwardslash>)*)"},
+		{property: "splunk.discovery.resource_attributes.", expectedError: "invalid property \"splunk.discovery.resource_attributes.\": missing resource attribute name"},
 		{property: "splunk.discovery.extensions.config.one.two", expectedError: "invalid property \"splunk.discovery.extensions.config.one.two\" (parsing error): splunk.discovery:1:43: unexpected token \"<EOF>\" (expected <dot> ((\"config\" <dot>) | \"enabled\") (<string> | <dot> | <forwardslash>)*)"},
 		{property: "splunk.discovery.receivers.type/name.config", expectedError: "invalid property \"splunk.discovery.receivers.type/name.config\" (parsing error): splunk.discovery:1:44: unexpected token \"<EOF>\" (expected <dot>)"},
 		{property: "splunk.discovery.extensions.extension--0-1-with-config-in-type-_x64__x86_🙈🙉🙊4:000x0;;0;;0;;-___-----type/e/x/t/e%ns<i>o<=n=>nam/e-with-config.config.o::n::e.config", expectedError: "invalid receiver type \"extension--0-1-with-config-in-type-_x64__x86_🙈🙉🙊4:000x0;;0;;0;;-___-----type\": invalid character(s) in type \"extension--0-1-with-config-in-type-_x64__x86_🙈🙉🙊4:000x0;;0;;0;;-___-----type\""},
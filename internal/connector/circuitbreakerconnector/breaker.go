@@ -0,0 +1,134 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreakerconnector
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type state int
+
+const (
+	closed state = iota
+	open
+	halfOpen
+)
+
+func (s state) String() string {
+	switch s {
+	case open:
+		return "open"
+	case halfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker tracks the outcome of the requests sent to the primary pipelines. While closed all requests
+// are allowed and the circuit opens once the failure ratio of the current window reaches the threshold.
+// Once open, no requests are allowed until the open duration elapses, after which a single probe request
+// at a time is allowed. The circuit closes after enough consecutive probes succeed, and opens again on
+// any probe failure.
+type breaker struct {
+	now         func() time.Time
+	logger      *zap.Logger
+	cfg         *Config
+	windowStart time.Time
+	openedAt    time.Time
+	state       state
+	requests    int
+	failures    int
+	successes   int
+	probing     bool
+	mu          sync.Mutex
+}
+
+func newBreaker(cfg *Config, logger *zap.Logger) *breaker {
+	b := &breaker{now: time.Now, logger: logger, cfg: cfg}
+	b.windowStart = b.now()
+	return b
+}
+
+// allow returns whether the request should be sent to the primary pipelines and whether it's a
+// probe. Each allowed request must be followed by a call to record with its outcome.
+func (b *breaker) allow() (allowed, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case closed:
+		return true, false
+	case open:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenDuration {
+			return false, false
+		}
+		b.transition(halfOpen)
+	}
+	if b.probing {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+// record accounts for the outcome of an allowed request. Outcomes of requests allowed before
+// the circuit last changed state are ignored.
+func (b *breaker) record(probe, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.state == closed && !probe:
+		now := b.now()
+		if now.Sub(b.windowStart) >= b.cfg.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+		b.requests++
+		if !success {
+			b.failures++
+		}
+		if b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.FailureThreshold {
+			b.transition(open)
+		}
+	case b.state == halfOpen && probe:
+		b.probing = false
+		if !success {
+			b.transition(open)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.ProbeSuccesses {
+			b.transition(closed)
+		}
+	}
+}
+
+// transition must be called with the lock held.
+func (b *breaker) transition(to state) {
+	b.logger.Info("Circuit state changed",
+		zap.Stringer("from", b.state), zap.Stringer("to", to),
+		zap.Int("requests", b.requests), zap.Int("failures", b.failures))
+	b.state = to
+	b.successes = 0
+	b.probing = false
+	switch to {
+	case open:
+		b.openedAt = b.now()
+	case closed:
+		b.windowStart, b.requests, b.failures = b.now(), 0, 0
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreakerconnector
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines when the circuit of the primary pipelines opens and where data is routed meanwhile.
+type Config struct {
+	// Pipelines receive the data while the circuit is closed.
+	Pipelines []pipeline.ID `mapstructure:"pipelines"`
+	// FallbackPipelines receive the data while the circuit is open, and the data the
+	// primary pipelines failed to export.
+	FallbackPipelines []pipeline.ID `mapstructure:"fallback_pipelines"`
+	// FailureThreshold is the ratio of failed requests within Window that opens the circuit.
+	FailureThreshold float64 `mapstructure:"failure_threshold"`
+	// MinRequests is the number of requests within Window required before the circuit can open.
+	MinRequests int `mapstructure:"min_requests"`
+	// Window is the period over which the failure ratio is computed.
+	Window time.Duration `mapstructure:"window"`
+	// OpenDuration is how long the circuit stays open before probe requests are sent
+	// to the primary pipelines.
+	OpenDuration time.Duration `mapstructure:"open_duration"`
+	// ProbeSuccesses is the number of consecutive successful probe requests that close the circuit.
+	ProbeSuccesses int `mapstructure:"probe_successes"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Pipelines) == 0 {
+		errs = errors.Join(errs, errors.New("at least one pipeline must be specified"))
+	}
+	if len(cfg.FallbackPipelines) == 0 {
+		errs = errors.Join(errs, errors.New("at least one fallback pipeline must be specified"))
+	}
+	primary := map[pipeline.ID]struct{}{}
+	for _, id := range cfg.Pipelines {
+		primary[id] = struct{}{}
+	}
+	for _, id := range cfg.FallbackPipelines {
+		if _, ok := primary[id]; ok {
+			errs = errors.Join(errs, fmt.Errorf("pipeline %q can't be both a pipeline and a fallback pipeline", id))
+		}
+	}
+	if cfg.FailureThreshold <= 0 || cfg.FailureThreshold > 1 {
+		errs = errors.Join(errs, errors.New("failure_threshold must be greater than 0 and at most 1"))
+	}
+	if cfg.MinRequests < 1 {
+		errs = errors.Join(errs, errors.New("min_requests must be positive"))
+	}
+	if cfg.Window <= 0 {
+		errs = errors.Join(errs, errors.New("window must be positive"))
+	}
+	if cfg.OpenDuration <= 0 {
+		errs = errors.Join(errs, errors.New("open_duration must be positive"))
+	}
+	if cfg.ProbeSuccesses < 1 {
+		errs = errors.Join(errs, errors.New("probe_successes must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreakerconnector
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var (
+	_ connector.Traces  = (*tracesConnector)(nil)
+	_ connector.Metrics = (*metricsConnector)(nil)
+	_ connector.Logs    = (*logsConnector)(nil)
+)
+
+// route sends the data to the primary pipelines if the breaker allows it, and to the fallback
+// pipelines otherwise or if the primary pipelines fail to export it. Permanent errors are caused
+// by the data rather than the primary pipelines' health, so they aren't recorded as failures
+// and the data isn't sent to the fallback pipelines.
+func route[T any](ctx context.Context, b *breaker, data T, primary, fallback func(context.Context, T) error) error {
+	allowed, probe := b.allow()
+	if !allowed {
+		return fallback(ctx, data)
+	}
+	err := primary(ctx, data)
+	if err != nil && consumererror.IsPermanent(err) {
+		b.record(probe, true)
+		return err
+	}
+	b.record(probe, err == nil)
+	if err == nil {
+		return nil
+	}
+	return fallback(ctx, data)
+}
+
+type tracesConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	breaker  *breaker
+	primary  consumer.Traces
+	fallback consumer.Traces
+}
+
+func (c *tracesConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *tracesConnector) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return route(ctx, c.breaker, td, c.primary.ConsumeTraces, c.fallback.ConsumeTraces)
+}
+
+type metricsConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	breaker  *breaker
+	primary  consumer.Metrics
+	fallback consumer.Metrics
+}
+
+func (c *metricsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *metricsConnector) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return route(ctx, c.breaker, md, c.primary.ConsumeMetrics, c.fallback.ConsumeMetrics)
+}
+
+type logsConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	breaker  *breaker
+	primary  consumer.Logs
+	fallback consumer.Logs
+}
+
+func (c *logsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *logsConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return route(ctx, c.breaker, ld, c.primary.ConsumeLogs, c.fallback.ConsumeLogs)
+}
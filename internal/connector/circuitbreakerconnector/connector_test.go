@@ -0,0 +1,134 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreakerconnector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+func newTestBreaker() (*breaker, *time.Time) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MinRequests = 4
+	cfg.ProbeSuccesses = 2
+	b := newBreaker(cfg, zap.NewNop())
+	var now *time.Time
+	b.now, now = testclock.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	b.windowStart = *now
+	return b, now
+}
+
+// send routes a request through the breaker, returning which pipeline received it.
+func send(b *breaker, primaryErr error) (string, error) {
+	var received string
+	err := route(context.Background(), b, "data",
+		func(context.Context, string) error {
+			received = "primary"
+			return primaryErr
+		},
+		func(context.Context, string) error {
+			received = "fallback"
+			return nil
+		})
+	return received, err
+}
+
+func TestBreakerOpensAndCloses(t *testing.T) {
+	b, now := newTestBreaker()
+	failure := errors.New("export failed")
+
+	// failures below min_requests don't open the circuit but are sent to the fallback
+	for i := 0; i < 2; i++ {
+		received, err := send(b, failure)
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", received)
+	}
+	received, _ := send(b, nil)
+	assert.Equal(t, "primary", received)
+	assert.Equal(t, closed, b.state)
+	received, _ = send(b, nil)
+	assert.Equal(t, "primary", received)
+	// 2 of 4 requests failed
+	assert.Equal(t, open, b.state)
+
+	received, _ = send(b, nil)
+	assert.Equal(t, "fallback", received)
+
+	// a failed probe opens the circuit again
+	*now = now.Add(30 * time.Second)
+	received, _ = send(b, failure)
+	assert.Equal(t, "fallback", received)
+	assert.Equal(t, open, b.state)
+	received, _ = send(b, nil)
+	assert.Equal(t, "fallback", received)
+
+	*now = now.Add(30 * time.Second)
+	received, _ = send(b, nil)
+	assert.Equal(t, "primary", received)
+	assert.Equal(t, halfOpen, b.state)
+	received, _ = send(b, nil)
+	assert.Equal(t, "primary", received)
+	assert.Equal(t, closed, b.state)
+}
+
+func TestBreakerWindow(t *testing.T) {
+	b, now := newTestBreaker()
+	for i := 0; i < 3; i++ {
+		_, _ = send(b, errors.New("export failed"))
+	}
+	*now = now.Add(time.Minute)
+	// the failures of the previous window are discarded
+	_, _ = send(b, errors.New("export failed"))
+	assert.Equal(t, closed, b.state)
+	assert.Equal(t, 1, b.requests)
+}
+
+func TestBreakerSingleProbe(t *testing.T) {
+	b, now := newTestBreaker()
+	b.transition(open)
+	*now = now.Add(time.Minute)
+
+	allowed, probe := b.allow()
+	require.True(t, allowed)
+	require.True(t, probe)
+	// concurrent requests are sent to the fallback while probing
+	allowed, _ = b.allow()
+	assert.False(t, allowed)
+
+	b.record(true, true)
+	allowed, probe = b.allow()
+	assert.True(t, allowed)
+	assert.True(t, probe)
+}
+
+func TestRoutePermanentError(t *testing.T) {
+	b, _ := newTestBreaker()
+	for i := 0; i < 4; i++ {
+		received, err := send(b, consumererror.NewPermanent(errors.New("bad data")))
+		require.Error(t, err)
+		assert.Equal(t, "primary", received)
+	}
+	assert.Equal(t, closed, b.state)
+	assert.Zero(t, b.failures)
+}
@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreakerconnector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+const (
+	typeStr   = "circuit_breaker"
+	stability = component.StabilityLevelDevelopment
+)
+
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		connector.WithTracesToTraces(createTracesToTraces, stability),
+		connector.WithMetricsToMetrics(createMetricsToMetrics, stability),
+		connector.WithLogsToLogs(createLogsToLogs, stability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		FailureThreshold: 0.5,
+		MinRequests:      10,
+		Window:           time.Minute,
+		OpenDuration:     30 * time.Second,
+		ProbeSuccesses:   3,
+	}
+}
+
+func createTracesToTraces(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (connector.Traces, error) {
+	router, ok := nextConsumer.(connector.TracesRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	fallback, err := router.Consumer(oCfg.FallbackPipelines...)
+	if err != nil {
+		return nil, err
+	}
+	return &tracesConnector{breaker: newBreaker(oCfg, set.Logger), primary: primary, fallback: fallback}, nil
+}
+
+func createMetricsToMetrics(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Metrics, error) {
+	router, ok := nextConsumer.(connector.MetricsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	fallback, err := router.Consumer(oCfg.FallbackPipelines...)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsConnector{breaker: newBreaker(oCfg, set.Logger), primary: primary, fallback: fallback}, nil
+}
+
+func createLogsToLogs(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (connector.Logs, error) {
+	router, ok := nextConsumer.(connector.LogsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	fallback, err := router.Consumer(oCfg.FallbackPipelines...)
+	if err != nil {
+		return nil, err
+	}
+	return &logsConnector{breaker: newBreaker(oCfg, set.Logger), primary: primary, fallback: fallback}, nil
+}
@@ -0,0 +1,490 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contentroutingconnector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// condition is a compiled routing condition, e.g. `team == "payments" && env != "dev"`.
+type condition interface {
+	// eval returns whether the condition matches the attributes of a record and its resource.
+	eval(record, resource pcommon.Map) bool
+}
+
+// operand is a value of a comparison: a literal or the attribute of a record or its resource.
+type operand interface {
+	value(record, resource pcommon.Map) (any, bool)
+}
+
+type literal struct{ v any }
+
+func (l literal) value(pcommon.Map, pcommon.Map) (any, bool) { return l.v, true }
+
+// attribute is a reference to an attribute. Unqualified references resolve to the attribute of the record,
+// or of its resource if the record doesn't have it.
+type attribute struct {
+	name     string
+	record   bool
+	resource bool
+}
+
+func (a attribute) value(record, resource pcommon.Map) (any, bool) {
+	if a.record {
+		if v, ok := record.Get(a.name); ok {
+			return attributeValue(v)
+		}
+	}
+	if a.resource {
+		if v, ok := resource.Get(a.name); ok {
+			return attributeValue(v)
+		}
+	}
+	return nil, false
+}
+
+// attributeValue converts an attribute to a string, float64 or bool. Other attributes are compared as strings.
+func attributeValue(v pcommon.Value) (any, bool) {
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		return v.Str(), true
+	case pcommon.ValueTypeInt:
+		return float64(v.Int()), true
+	case pcommon.ValueTypeDouble:
+		return v.Double(), true
+	case pcommon.ValueTypeBool:
+		return v.Bool(), true
+	case pcommon.ValueTypeEmpty:
+		return nil, false
+	default:
+		return v.AsString(), true
+	}
+}
+
+type and struct{ left, right condition }
+
+func (c and) eval(record, resource pcommon.Map) bool {
+	return c.left.eval(record, resource) && c.right.eval(record, resource)
+}
+
+type or struct{ left, right condition }
+
+func (c or) eval(record, resource pcommon.Map) bool {
+	return c.left.eval(record, resource) || c.right.eval(record, resource)
+}
+
+type not struct{ c condition }
+
+func (c not) eval(record, resource pcommon.Map) bool { return !c.c.eval(record, resource) }
+
+// truthy matches bool operands that are true, and other operands that are set, e.g. `team`.
+type truthy struct{ o operand }
+
+func (c truthy) eval(record, resource pcommon.Map) bool {
+	v, ok := c.o.value(record, resource)
+	if b, isBool := v.(bool); isBool {
+		return b
+	}
+	return ok
+}
+
+type comparison struct {
+	left, right operand
+	op          string
+}
+
+func (c comparison) eval(record, resource pcommon.Map) bool {
+	l, lok := c.left.value(record, resource)
+	r, rok := c.right.value(record, resource)
+	if !lok || !rok {
+		// unset attributes are only different from everything
+		return c.op == "!="
+	}
+	switch c.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+	switch l := l.(type) {
+	case float64:
+		if r, ok := r.(float64); ok {
+			return compare(c.op, l, r)
+		}
+	case string:
+		if r, ok := r.(string); ok {
+			return compare(c.op, l, r)
+		}
+	}
+	return false
+}
+
+func compare[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+// match matches operands against a regular expression with `=~`, or not with `!~`. Operands that aren't
+// strings are matched by their string representation.
+type match struct {
+	o      operand
+	re     *regexp.Regexp
+	negate bool
+}
+
+func (c match) eval(record, resource pcommon.Map) bool {
+	v, ok := c.o.value(record, resource)
+	if !ok {
+		return c.negate
+	}
+	s, isStr := v.(string)
+	if !isStr {
+		s = fmt.Sprint(v)
+	}
+	return c.re.MatchString(s) != c.negate
+}
+
+// in matches operands equal to one of a list of literals, e.g. `env in ["prod", "staging"]`.
+type in struct {
+	o      operand
+	values []any
+}
+
+func (c in) eval(record, resource pcommon.Map) bool {
+	v, ok := c.o.value(record, resource)
+	if !ok {
+		return false
+	}
+	for _, value := range c.values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCondition compiles a condition of the routing language:
+//
+//	condition  = or
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" condition ")" | comparison
+//	comparison = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand
+//	                     | ( "=~" | "!~" ) string | "in" "[" literal { "," literal } "]" ]
+//	operand    = literal | name | "attributes" "[" string "]" | "resource" "[" string "]"
+//	literal    = string | number | "true" | "false"
+func parseCondition(s string) (condition, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	c, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at offset %d", t, t.pos)
+	}
+	return c, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if t := p.next(); t.kind != kind || (text != "" && t.text != text) {
+		expected := text
+		if expected == "" {
+			expected = kind.String()
+		}
+		return fmt.Errorf("expected %q, got %s at offset %d", expected, t, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is(tokenOperator, "||") {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (condition, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is(tokenOperator, "&&") {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = and{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (condition, error) {
+	switch t := p.peek(); {
+	case t.is(tokenOperator, "!"):
+		p.next()
+		c, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return not{c}, nil
+	case t.is(tokenPunct, "("):
+		p.next()
+		c, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(tokenPunct, ")"); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (condition, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch {
+	case t.is(tokenOperator, "==", "!=", "<", "<=", ">", ">="):
+		p.next()
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return comparison{left: left, right: right, op: t.text}, nil
+	case t.is(tokenOperator, "=~", "!~"):
+		p.next()
+		pattern := p.next()
+		if pattern.kind != tokenString {
+			return nil, fmt.Errorf("expected a regular expression string, got %s at offset %d", pattern, pattern.pos)
+		}
+		re, err := regexp.Compile(pattern.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at offset %d: %w", pattern.pos, err)
+		}
+		return match{o: left, re: re, negate: t.text == "!~"}, nil
+	case t.is(tokenIdent, "in"):
+		p.next()
+		if err = p.expect(tokenPunct, "["); err != nil {
+			return nil, err
+		}
+		var values []any
+		for {
+			v := p.next()
+			l, ok := v.literal()
+			if !ok {
+				return nil, fmt.Errorf("expected a literal, got %s at offset %d", v, v.pos)
+			}
+			values = append(values, l)
+			if !p.peek().is(tokenPunct, ",") {
+				break
+			}
+			p.next()
+		}
+		if err = p.expect(tokenPunct, "]"); err != nil {
+			return nil, err
+		}
+		return in{o: left, values: values}, nil
+	}
+	return truthy{left}, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	t := p.next()
+	if l, ok := t.literal(); ok {
+		return literal{l}, nil
+	}
+	if t.kind != tokenIdent || t.text == "in" {
+		return nil, fmt.Errorf("expected an attribute or literal, got %s at offset %d", t, t.pos)
+	}
+	if (t.text == "attributes" || t.text == "resource") && p.peek().is(tokenPunct, "[") {
+		p.next()
+		name := p.next()
+		if name.kind != tokenString {
+			return nil, fmt.Errorf("expected an attribute name string, got %s at offset %d", name, name.pos)
+		}
+		if err := p.expect(tokenPunct, "]"); err != nil {
+			return nil, err
+		}
+		return attribute{name: name.text, record: t.text == "attributes", resource: t.text == "resource"}, nil
+	}
+	return attribute{name: t.text, record: true, resource: true}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenPunct
+)
+
+func (k tokenKind) String() string {
+	return [...]string{"end of condition", "name", "string", "number", "operator", "punctuation"}[k]
+}
+
+type token struct {
+	text string
+	kind tokenKind
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return t.kind.String()
+	}
+	return strconv.Quote(t.text)
+}
+
+func (t token) is(kind tokenKind, texts ...string) bool {
+	if t.kind != kind {
+		return false
+	}
+	for _, text := range texts {
+		if t.text == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (t token) literal() (any, bool) {
+	switch {
+	case t.kind == tokenString:
+		return t.text, true
+	case t.kind == tokenNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		return f, err == nil
+	case t.is(tokenIdent, "true", "false"):
+		return t.text == "true", true
+	}
+	return nil, false
+}
+
+var operators = []string{"&&", "||", "==", "!=", "=~", "!~", "<=", ">=", "<", ">", "!"}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			text, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, token{text: text, kind: tokenString, pos: i})
+			i = end + 1
+		case c == '-' || unicode.IsDigit(c):
+			end := i + 1
+			for end < len(s) && (unicode.IsDigit(rune(s[end])) || s[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{text: s[i:end], kind: tokenNumber, pos: i})
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i + 1
+			// attribute names are dotted, e.g. k8s.namespace.name
+			for end < len(s) && (s[end] == '_' || s[end] == '.' || s[end] == '-' ||
+				unicode.IsLetter(rune(s[end])) || unicode.IsDigit(rune(s[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{text: s[i:end], kind: tokenIdent, pos: i})
+			i = end
+		case strings.ContainsRune("()[],", c):
+			tokens = append(tokens, token{text: string(c), kind: tokenPunct, pos: i})
+			i++
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{text: op, kind: tokenOperator, pos: i})
+			i += len(op)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty condition")
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
@@ -0,0 +1,111 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contentroutingconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestCondition(t *testing.T) {
+	record := pcommon.NewMap()
+	require.NoError(t, record.FromRaw(map[string]any{
+		"team":        "payments",
+		"http.status": 503,
+		"latency":     1.5,
+		"sampled":     false,
+		"tags":        []any{"a", "b"},
+	}))
+	resource := pcommon.NewMap()
+	require.NoError(t, resource.FromRaw(map[string]any{
+		"env":                "prod",
+		"team":               "platform",
+		"k8s.namespace.name": "prod-payments",
+	}))
+
+	for _, tt := range []struct {
+		condition string
+		expected  bool
+	}{
+		{`team == "payments" && env != "dev"`, true},
+		{`team == "payments" && env == "dev"`, false},
+		{`team == "platform" || env == "prod"`, true},
+		// record attributes take precedence over resource ones
+		{`team == "platform"`, false},
+		{`resource["team"] == "platform"`, true},
+		{`attributes["team"] == "payments"`, true},
+		{`attributes["env"] == "prod"`, false},
+		{`k8s.namespace.name =~ "^prod-"`, true},
+		{`k8s.namespace.name !~ "^prod-"`, false},
+		{`http.status >= 500 && http.status < 600`, true},
+		{`http.status == 503`, true},
+		{`latency > 1`, true},
+		{`latency <= 1.5 && latency > -1`, true},
+		{`env > "dev"`, true},
+		{`http.status > "500"`, false},
+		{`env in ["prod", "staging"]`, true},
+		{`env in ["dev"]`, false},
+		{`http.status in [500, 503]`, true},
+		{`team`, true},
+		{`missing`, false},
+		{`!missing`, true},
+		{`sampled`, false},
+		{`!sampled && team`, true},
+		{`missing == "x"`, false},
+		{`missing != "x"`, true},
+		{`missing =~ "x"`, false},
+		{`missing !~ "x"`, true},
+		{`missing in ["x"]`, false},
+		{`tags =~ "a"`, true},
+		{`!(team == "payments" || env == "prod")`, false},
+		{`(team == "x" || env == "prod") && true`, true},
+		{`team == "x" || env == "prod" && false`, false},
+		{`"payments" == team`, true},
+	} {
+		t.Run(tt.condition, func(t *testing.T) {
+			c, err := parseCondition(tt.condition)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, c.eval(record, resource))
+		})
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	for _, tt := range []struct {
+		condition   string
+		expectedErr string
+	}{
+		{``, "empty condition"},
+		{`team ==`, "expected an attribute or literal, got end of condition at offset 7"},
+		{`team == "payments`, "unterminated string at offset 8"},
+		{`team = "payments"`, `unexpected character '=' at offset 5`},
+		{`(team == "x"`, `expected ")", got end of condition at offset 12`},
+		{`team == "x" env == "y"`, `unexpected "env" at offset 12`},
+		{`team =~ payments`, `expected a regular expression string, got "payments" at offset 8`},
+		{`team =~ "("`, "invalid regular expression at offset 8: error parsing regexp: missing closing ): `(`"},
+		{`env in "prod"`, `expected "[", got "prod" at offset 7`},
+		{`env in [prod]`, `expected a literal, got "prod" at offset 8`},
+		{`attributes[team] == "x"`, `expected an attribute name string, got "team" at offset 11`},
+		{`team == "x" &&`, "expected an attribute or literal, got end of condition at offset 14"},
+	} {
+		t.Run(tt.condition, func(t *testing.T) {
+			_, err := parseCondition(tt.condition)
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
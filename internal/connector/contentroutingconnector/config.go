@@ -0,0 +1,60 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contentroutingconnector
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the routes data is sent to depending on its attributes.
+type Config struct {
+	// Routes are matched against each record, a log record, span or metric data point, in order.
+	Routes []Route `mapstructure:"routes"`
+	// DefaultPipelines receive the records no route matched, which are dropped if empty.
+	DefaultPipelines []pipeline.ID `mapstructure:"default_pipelines"`
+	// MatchOnce routes records to the first matching route only, instead of all the matching routes.
+	MatchOnce bool `mapstructure:"match_once"`
+}
+
+// Route sends the records matching its condition to its pipelines.
+type Route struct {
+	// Condition is an expression over the attributes of the record and its resource, e.g.
+	// `team == "payments" && env != "dev"`.
+	Condition string `mapstructure:"condition"`
+	// Pipelines receive the matching records.
+	Pipelines []pipeline.ID `mapstructure:"pipelines"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Routes) == 0 {
+		errs = errors.Join(errs, errors.New("at least one route must be specified"))
+	}
+	for i, route := range cfg.Routes {
+		if _, err := parseCondition(route.Condition); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("routes[%d]: invalid condition: %w", i, err))
+		}
+		if len(route.Pipelines) == 0 {
+			errs = errors.Join(errs, fmt.Errorf("routes[%d]: at least one pipeline must be specified", i))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,335 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contentroutingconnector
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var (
+	_ connector.Traces  = (*tracesConnector)(nil)
+	_ connector.Metrics = (*metricsConnector)(nil)
+	_ connector.Logs    = (*logsConnector)(nil)
+)
+
+// router matches records against the conditions of the routes, in order. The last consumer is the one of
+// the default pipelines if its condition is nil.
+type router[T any] struct {
+	conditions []condition
+	consumers  []T
+	matchOnce  bool
+}
+
+// match appends to matched the indexes of the consumers of a record.
+func (r *router[T]) match(record, resource pcommon.Map, matched []int) []int {
+	for i, c := range r.conditions {
+		if c == nil {
+			if len(matched) == 0 {
+				matched = append(matched, i)
+			}
+			break
+		}
+		if c.eval(record, resource) {
+			matched = append(matched, i)
+			if r.matchOnce {
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// route copies each record to the data of the consumers it's matched to, creating their data the first time,
+// and sends it to them.
+func route[T, D any](
+	ctx context.Context,
+	r *router[T],
+	visit func(func(record, resource pcommon.Map, copyTo func(D))),
+	newData func() D,
+	consume func(T, context.Context, D) error,
+) error {
+	data := make([]D, len(r.consumers))
+	created := make([]bool, len(r.consumers))
+	var matched []int
+	visit(func(record, resource pcommon.Map, copyTo func(D)) {
+		matched = r.match(record, resource, matched[:0])
+		for _, i := range matched {
+			if !created[i] {
+				data[i], created[i] = newData(), true
+			}
+			copyTo(data[i])
+		}
+	})
+	var errs error
+	for i, d := range data {
+		if created[i] {
+			errs = errors.Join(errs, consume(r.consumers[i], ctx, d))
+		}
+	}
+	return errs
+}
+
+// dest is the data of a consumer. It tracks the resource and scope of the last copied record, so the records of
+// the same resource and scope are copied to the same resource and scope.
+type dest[R, S any] struct {
+	resource    R
+	scope       S
+	resourceIdx int
+	scopeIdx    int
+	started     bool
+}
+
+func (d *dest[R, S]) scopeOf(resourceIdx, scopeIdx int, newResource func() R, newScope func(R) S) S {
+	if !d.started || d.resourceIdx != resourceIdx {
+		d.resource, d.resourceIdx, d.scopeIdx, d.started = newResource(), resourceIdx, -1, true
+	}
+	if d.scopeIdx != scopeIdx {
+		d.scope, d.scopeIdx = newScope(d.resource), scopeIdx
+	}
+	return d.scope
+}
+
+type tracesConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	router *router[consumer.Traces]
+}
+
+func (c *tracesConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+type tracesDest struct {
+	traces ptrace.Traces
+	dest[ptrace.ResourceSpans, ptrace.ScopeSpans]
+}
+
+func (c *tracesConnector) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return route(ctx, c.router, func(yield func(record, resource pcommon.Map, copyTo func(*tracesDest))) {
+		for i := 0; i < td.ResourceSpans().Len(); i++ {
+			rs := td.ResourceSpans().At(i)
+			for j := 0; j < rs.ScopeSpans().Len(); j++ {
+				ss := rs.ScopeSpans().At(j)
+				for k := 0; k < ss.Spans().Len(); k++ {
+					span := ss.Spans().At(k)
+					yield(span.Attributes(), rs.Resource().Attributes(), func(d *tracesDest) {
+						scope := d.scopeOf(i, j, func() ptrace.ResourceSpans {
+							out := d.traces.ResourceSpans().AppendEmpty()
+							rs.Resource().CopyTo(out.Resource())
+							out.SetSchemaUrl(rs.SchemaUrl())
+							return out
+						}, func(out ptrace.ResourceSpans) ptrace.ScopeSpans {
+							scope := out.ScopeSpans().AppendEmpty()
+							ss.Scope().CopyTo(scope.Scope())
+							scope.SetSchemaUrl(ss.SchemaUrl())
+							return scope
+						})
+						span.CopyTo(scope.Spans().AppendEmpty())
+					})
+				}
+			}
+		}
+	}, func() *tracesDest {
+		return &tracesDest{traces: ptrace.NewTraces()}
+	}, func(next consumer.Traces, ctx context.Context, d *tracesDest) error {
+		return next.ConsumeTraces(ctx, d.traces)
+	})
+}
+
+type metricsConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	router *router[consumer.Metrics]
+}
+
+func (c *metricsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+type metricsDest struct {
+	metrics   pmetric.Metrics
+	metric    pmetric.Metric
+	metricKey [3]int
+	hasMetric bool
+	dest[pmetric.ResourceMetrics, pmetric.ScopeMetrics]
+}
+
+// metricOf returns the metric the data points of the metric m, at the key indexes of its resource, scope and
+// metric, are copied to.
+func (d *metricsDest) metricOf(scope pmetric.ScopeMetrics, key [3]int, m pmetric.Metric) pmetric.Metric {
+	if d.hasMetric && d.metricKey == key {
+		return d.metric
+	}
+	out := scope.Metrics().AppendEmpty()
+	out.SetName(m.Name())
+	out.SetDescription(m.Description())
+	out.SetUnit(m.Unit())
+	m.Metadata().CopyTo(out.Metadata())
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		out.SetEmptyGauge()
+	case pmetric.MetricTypeSum:
+		out.SetEmptySum().SetAggregationTemporality(m.Sum().AggregationTemporality())
+		out.Sum().SetIsMonotonic(m.Sum().IsMonotonic())
+	case pmetric.MetricTypeHistogram:
+		out.SetEmptyHistogram().SetAggregationTemporality(m.Histogram().AggregationTemporality())
+	case pmetric.MetricTypeExponentialHistogram:
+		out.SetEmptyExponentialHistogram().SetAggregationTemporality(m.ExponentialHistogram().AggregationTemporality())
+	case pmetric.MetricTypeSummary:
+		out.SetEmptySummary()
+	}
+	d.metric, d.metricKey, d.hasMetric = out, key, true
+	return out
+}
+
+func (c *metricsConnector) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return route(ctx, c.router, func(yield func(record, resource pcommon.Map, copyTo func(*metricsDest))) {
+		for i := 0; i < md.ResourceMetrics().Len(); i++ {
+			rm := md.ResourceMetrics().At(i)
+			for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+				sm := rm.ScopeMetrics().At(j)
+				for k := 0; k < sm.Metrics().Len(); k++ {
+					m := sm.Metrics().At(k)
+					metricOf := func(d *metricsDest) pmetric.Metric {
+						scope := d.scopeOf(i, j, func() pmetric.ResourceMetrics {
+							out := d.metrics.ResourceMetrics().AppendEmpty()
+							rm.Resource().CopyTo(out.Resource())
+							out.SetSchemaUrl(rm.SchemaUrl())
+							return out
+						}, func(out pmetric.ResourceMetrics) pmetric.ScopeMetrics {
+							scope := out.ScopeMetrics().AppendEmpty()
+							sm.Scope().CopyTo(scope.Scope())
+							scope.SetSchemaUrl(sm.SchemaUrl())
+							return scope
+						})
+						return d.metricOf(scope, [3]int{i, j, k}, m)
+					}
+					visitDataPoints(m, rm.Resource().Attributes(), yield, metricOf)
+				}
+			}
+		}
+	}, func() *metricsDest {
+		return &metricsDest{metrics: pmetric.NewMetrics()}
+	}, func(next consumer.Metrics, ctx context.Context, d *metricsDest) error {
+		return next.ConsumeMetrics(ctx, d.metrics)
+	})
+}
+
+// visitDataPoints yields the data points of a metric, copied to the metric returned by metricOf.
+func visitDataPoints(
+	m pmetric.Metric,
+	resource pcommon.Map,
+	yield func(record, resource pcommon.Map, copyTo func(*metricsDest)),
+	metricOf func(*metricsDest) pmetric.Metric,
+) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := m.Gauge().DataPoints()
+		for l := 0; l < dps.Len(); l++ {
+			dp := dps.At(l)
+			yield(dp.Attributes(), resource, func(d *metricsDest) {
+				dp.CopyTo(metricOf(d).Gauge().DataPoints().AppendEmpty())
+			})
+		}
+	case pmetric.MetricTypeSum:
+		dps := m.Sum().DataPoints()
+		for l := 0; l < dps.Len(); l++ {
+			dp := dps.At(l)
+			yield(dp.Attributes(), resource, func(d *metricsDest) {
+				dp.CopyTo(metricOf(d).Sum().DataPoints().AppendEmpty())
+			})
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for l := 0; l < dps.Len(); l++ {
+			dp := dps.At(l)
+			yield(dp.Attributes(), resource, func(d *metricsDest) {
+				dp.CopyTo(metricOf(d).Histogram().DataPoints().AppendEmpty())
+			})
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for l := 0; l < dps.Len(); l++ {
+			dp := dps.At(l)
+			yield(dp.Attributes(), resource, func(d *metricsDest) {
+				dp.CopyTo(metricOf(d).ExponentialHistogram().DataPoints().AppendEmpty())
+			})
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for l := 0; l < dps.Len(); l++ {
+			dp := dps.At(l)
+			yield(dp.Attributes(), resource, func(d *metricsDest) {
+				dp.CopyTo(metricOf(d).Summary().DataPoints().AppendEmpty())
+			})
+		}
+	}
+}
+
+type logsConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	router *router[consumer.Logs]
+}
+
+func (c *logsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+type logsDest struct {
+	logs plog.Logs
+	dest[plog.ResourceLogs, plog.ScopeLogs]
+}
+
+func (c *logsConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return route(ctx, c.router, func(yield func(record, resource pcommon.Map, copyTo func(*logsDest))) {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			rl := ld.ResourceLogs().At(i)
+			for j := 0; j < rl.ScopeLogs().Len(); j++ {
+				sl := rl.ScopeLogs().At(j)
+				for k := 0; k < sl.LogRecords().Len(); k++ {
+					lr := sl.LogRecords().At(k)
+					yield(lr.Attributes(), rl.Resource().Attributes(), func(d *logsDest) {
+						scope := d.scopeOf(i, j, func() plog.ResourceLogs {
+							out := d.logs.ResourceLogs().AppendEmpty()
+							rl.Resource().CopyTo(out.Resource())
+							out.SetSchemaUrl(rl.SchemaUrl())
+							return out
+						}, func(out plog.ResourceLogs) plog.ScopeLogs {
+							scope := out.ScopeLogs().AppendEmpty()
+							sl.Scope().CopyTo(scope.Scope())
+							scope.SetSchemaUrl(sl.SchemaUrl())
+							return scope
+						})
+						lr.CopyTo(scope.LogRecords().AppendEmpty())
+					})
+				}
+			}
+		}
+	}, func() *logsDest {
+		return &logsDest{logs: plog.NewLogs()}
+	}, func(next consumer.Logs, ctx context.Context, d *logsDest) error {
+		return next.ConsumeLogs(ctx, d.logs)
+	})
+}
@@ -0,0 +1,163 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contentroutingconnector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func newTestRouter[T any](t *testing.T, matchOnce bool, conditions []string, consumers ...T) *router[T] {
+	r := &router[T]{consumers: consumers, matchOnce: matchOnce}
+	for _, s := range conditions {
+		c, err := parseCondition(s)
+		require.NoError(t, err)
+		r.conditions = append(r.conditions, c)
+	}
+	// the consumer without condition is the default one
+	for len(r.conditions) < len(consumers) {
+		r.conditions = append(r.conditions, nil)
+	}
+	return r
+}
+
+func testLogs() plog.Logs {
+	ld := plog.NewLogs()
+	for _, resource := range []struct {
+		team    string
+		records []string
+	}{
+		{team: "payments", records: []string{"prod", "dev", "prod"}},
+		{team: "search", records: []string{"prod"}},
+	} {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("team", resource.team)
+		sl := rl.ScopeLogs().AppendEmpty()
+		sl.Scope().SetName("scope")
+		for _, env := range resource.records {
+			lr := sl.LogRecords().AppendEmpty()
+			lr.Attributes().PutStr("env", env)
+			lr.Body().SetStr(resource.team + " " + env)
+		}
+	}
+	return ld
+}
+
+// bodies returns the bodies of the log records of each resource.
+func bodies(ld plog.Logs) [][]string {
+	var out [][]string
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		var resource []string
+		rl := ld.ResourceLogs().At(i)
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				resource = append(resource, sl.LogRecords().At(k).Body().Str())
+			}
+		}
+		out = append(out, resource)
+	}
+	return out
+}
+
+func TestRouteLogs(t *testing.T) {
+	payments := new(consumertest.LogsSink)
+	prod := new(consumertest.LogsSink)
+	defaults := new(consumertest.LogsSink)
+	c := &logsConnector{router: newTestRouter[consumer.Logs](t, true,
+		[]string{`team == "payments" && env != "dev"`, `env == "prod"`}, payments, prod, defaults)}
+
+	require.NoError(t, c.ConsumeLogs(context.Background(), testLogs()))
+	require.Len(t, payments.AllLogs(), 1)
+	assert.Equal(t, [][]string{{"payments prod", "payments prod"}}, bodies(payments.AllLogs()[0]))
+	// records matching an earlier route aren't routed again
+	require.Len(t, prod.AllLogs(), 1)
+	assert.Equal(t, [][]string{{"search prod"}}, bodies(prod.AllLogs()[0]))
+	require.Len(t, defaults.AllLogs(), 1)
+	assert.Equal(t, [][]string{{"payments dev"}}, bodies(defaults.AllLogs()[0]))
+	assert.Equal(t, map[string]any{"team": "payments"}, defaults.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().AsRaw())
+}
+
+func TestRouteLogsToAllMatches(t *testing.T) {
+	payments := new(consumertest.LogsSink)
+	prod := new(consumertest.LogsSink)
+	c := &logsConnector{router: newTestRouter[consumer.Logs](t, false,
+		[]string{`team == "payments"`, `env == "prod"`}, payments, prod)}
+
+	require.NoError(t, c.ConsumeLogs(context.Background(), testLogs()))
+	require.Len(t, payments.AllLogs(), 1)
+	assert.Equal(t, [][]string{{"payments prod", "payments dev", "payments prod"}}, bodies(payments.AllLogs()[0]))
+	require.Len(t, prod.AllLogs(), 1)
+	assert.Equal(t, [][]string{{"payments prod", "payments prod"}, {"search prod"}}, bodies(prod.AllLogs()[0]))
+}
+
+func TestRouteTraces(t *testing.T) {
+	failed := new(consumertest.TracesSink)
+	others := new(consumertest.TracesSink)
+	c := &tracesConnector{router: newTestRouter[consumer.Traces](t, true, []string{`http.status >= 500`}, failed, others)}
+
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	for _, status := range []int64{200, 503} {
+		span := spans.AppendEmpty()
+		span.Attributes().PutInt("http.status", status)
+	}
+	require.NoError(t, c.ConsumeTraces(context.Background(), td))
+	require.Len(t, failed.AllTraces(), 1)
+	assert.Equal(t, 1, failed.SpanCount())
+	status, _ := failed.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().Get("http.status")
+	assert.Equal(t, int64(503), status.Int())
+	assert.Equal(t, 1, others.SpanCount())
+}
+
+func TestRouteMetricDataPoints(t *testing.T) {
+	prod := new(consumertest.MetricsSink)
+	c := &metricsConnector{router: newTestRouter[consumer.Metrics](t, true, []string{`env == "prod"`}, prod)}
+
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	sum := metrics.AppendEmpty()
+	sum.SetName("requests")
+	sum.SetUnit("1")
+	sum.SetEmptySum().SetIsMonotonic(true)
+	sum.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	for _, env := range []string{"prod", "dev", "prod"} {
+		dp := sum.Sum().DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("env", env)
+		dp.SetIntValue(1)
+	}
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("latency")
+	histogram.SetEmptyHistogram().DataPoints().AppendEmpty().Attributes().PutStr("env", "dev")
+
+	require.NoError(t, c.ConsumeMetrics(context.Background(), md))
+	// records matching no route are dropped without default pipelines
+	require.Len(t, prod.AllMetrics(), 1)
+	routed := prod.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, routed.Len())
+	assert.Equal(t, "requests", routed.At(0).Name())
+	assert.Equal(t, "1", routed.At(0).Unit())
+	assert.True(t, routed.At(0).Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, routed.At(0).Sum().AggregationTemporality())
+	assert.Equal(t, 2, routed.At(0).Sum().DataPoints().Len())
+}
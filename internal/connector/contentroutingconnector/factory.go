@@ -0,0 +1,120 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contentroutingconnector
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+const (
+	typeStr   = "content_routing"
+	stability = component.StabilityLevelDevelopment
+)
+
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		connector.WithTracesToTraces(createTracesToTraces, stability),
+		connector.WithMetricsToMetrics(createMetricsToMetrics, stability),
+		connector.WithLogsToLogs(createLogsToLogs, stability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{MatchOnce: true}
+}
+
+// newRouter resolves the consumers of the routes and default pipelines of the config.
+func newRouter[T any](cfg *Config, consumerOf func(...pipeline.ID) (T, error)) (*router[T], error) {
+	r := &router[T]{matchOnce: cfg.MatchOnce}
+	for _, route := range cfg.Routes {
+		c, err := parseCondition(route.Condition)
+		if err != nil {
+			return nil, err
+		}
+		next, err := consumerOf(route.Pipelines...)
+		if err != nil {
+			return nil, err
+		}
+		r.conditions = append(r.conditions, c)
+		r.consumers = append(r.consumers, next)
+	}
+	if len(cfg.DefaultPipelines) > 0 {
+		next, err := consumerOf(cfg.DefaultPipelines...)
+		if err != nil {
+			return nil, err
+		}
+		r.conditions = append(r.conditions, nil)
+		r.consumers = append(r.consumers, next)
+	}
+	return r, nil
+}
+
+func createTracesToTraces(
+	_ context.Context,
+	_ connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (connector.Traces, error) {
+	tr, ok := nextConsumer.(connector.TracesRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	r, err := newRouter(cfg.(*Config), tr.Consumer)
+	if err != nil {
+		return nil, err
+	}
+	return &tracesConnector{router: r}, nil
+}
+
+func createMetricsToMetrics(
+	_ context.Context,
+	_ connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Metrics, error) {
+	mr, ok := nextConsumer.(connector.MetricsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	r, err := newRouter(cfg.(*Config), mr.Consumer)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsConnector{router: r}, nil
+}
+
+func createLogsToLogs(
+	_ context.Context,
+	_ connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (connector.Logs, error) {
+	lr, ok := nextConsumer.(connector.LogsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	r, err := newRouter(cfg.(*Config), lr.Consumer)
+	if err != nil {
+		return nil, err
+	}
+	return &logsConnector{router: r}, nil
+}
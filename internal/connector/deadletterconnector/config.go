@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletterconnector
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the pipelines data is sent to, and where the data they fail to consume is kept.
+type Config struct {
+	// Pipelines receive the data, e.g. with the exporters whose failures are dead-lettered.
+	Pipelines []pipeline.ID `mapstructure:"pipelines"`
+	// DeadLetterPipelines receive the data the pipelines failed to consume.
+	DeadLetterPipelines []pipeline.ID `mapstructure:"dead_letter_pipelines"`
+	// Directory is where the data the pipelines failed to consume is written, as OTLP JSON files.
+	Directory string `mapstructure:"directory"`
+	// MaxFileSizeMiB is the size files are rotated at.
+	MaxFileSizeMiB int64 `mapstructure:"max_file_size_mib"`
+	// MaxTotalSizeMiB is the size of the files of a signal the oldest files are removed at, or 0 to keep them all.
+	MaxTotalSizeMiB int64 `mapstructure:"max_total_size_mib"`
+	// MaxAge is the age files are removed at, or 0 to keep them all.
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Pipelines) == 0 {
+		errs = errors.Join(errs, errors.New("at least one pipeline must be specified"))
+	}
+	if len(cfg.DeadLetterPipelines) == 0 && cfg.Directory == "" {
+		errs = errors.Join(errs, errors.New("either dead_letter_pipelines or directory must be specified"))
+	}
+	primary := map[pipeline.ID]struct{}{}
+	for _, id := range cfg.Pipelines {
+		primary[id] = struct{}{}
+	}
+	for _, id := range cfg.DeadLetterPipelines {
+		if _, ok := primary[id]; ok {
+			errs = errors.Join(errs, fmt.Errorf("pipeline %q can't be both a pipeline and a dead letter pipeline", id))
+		}
+	}
+	if cfg.MaxFileSizeMiB <= 0 {
+		errs = errors.Join(errs, errors.New("max_file_size_mib must be positive"))
+	}
+	if cfg.MaxTotalSizeMiB < 0 {
+		errs = errors.Join(errs, errors.New("max_total_size_mib must not be negative"))
+	} else if cfg.MaxTotalSizeMiB > 0 && cfg.MaxTotalSizeMiB < cfg.MaxFileSizeMiB {
+		errs = errors.Join(errs, errors.New("max_total_size_mib must be at least max_file_size_mib"))
+	}
+	if cfg.MaxAge < 0 {
+		errs = errors.Join(errs, errors.New("max_age must not be negative"))
+	}
+	return errs
+}
@@ -0,0 +1,170 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletterconnector
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+var (
+	_ connector.Traces  = (*tracesConnector)(nil)
+	_ connector.Metrics = (*metricsConnector)(nil)
+	_ connector.Logs    = (*logsConnector)(nil)
+)
+
+// deadLetterer keeps the data the pipelines failed to consume in the dead letter files and pipelines.
+type deadLetterer[T any] struct {
+	logger  *zap.Logger
+	writer  *fileWriter
+	marshal func(T) ([]byte, error)
+}
+
+func newDeadLetterer[T any](logger *zap.Logger, cfg *Config, signal string, marshal func(T) ([]byte, error)) *deadLetterer[T] {
+	d := &deadLetterer[T]{logger: logger, marshal: marshal}
+	if cfg.Directory != "" {
+		d.writer = newFileWriter(cfg, signal)
+	}
+	return d
+}
+
+func (d *deadLetterer[T]) Start(context.Context, component.Host) error {
+	if d.writer == nil {
+		return nil
+	}
+	return d.writer.start()
+}
+
+func (d *deadLetterer[T]) Shutdown(context.Context) error {
+	if d.writer == nil {
+		return nil
+	}
+	return d.writer.close()
+}
+
+// consume sends the data to the pipelines, and the data they fail to consume, e.g. once the retries of their
+// exporters are exhausted, to the dead letter files and pipelines. The pipelines' error is only returned if the
+// data couldn't be dead-lettered either, so the receiver can fail the request of the sender instead of losing it.
+// The pipelines get a copy if they mutate the data, so the data is dead-lettered unchanged.
+func (d *deadLetterer[T]) consume(
+	ctx context.Context,
+	data T,
+	clone func(T) T,
+	primary consumer.Capabilities,
+	consumePrimary func(context.Context, T) error,
+	consumeDeadLetter func(context.Context, T) error,
+) error {
+	primaryData := data
+	if primary.MutatesData {
+		primaryData = clone(data)
+	}
+	err := consumePrimary(ctx, primaryData)
+	if err == nil {
+		return nil
+	}
+
+	var errs error
+	if d.writer != nil {
+		b, marshalErr := d.marshal(data)
+		if marshalErr == nil {
+			marshalErr = d.writer.write(b)
+		}
+		errs = errors.Join(errs, marshalErr)
+	}
+	if consumeDeadLetter != nil {
+		// the pipelines may have failed because the request of the sender was canceled
+		errs = errors.Join(errs, consumeDeadLetter(context.WithoutCancel(ctx), data))
+	}
+	if errs != nil {
+		d.logger.Error("Failed to dead-letter data the pipelines failed to consume", zap.NamedError("pipelines_error", err), zap.Error(errs))
+		return err
+	}
+	d.logger.Warn("Dead-lettered data the pipelines failed to consume", zap.Error(err))
+	return nil
+}
+
+type tracesConnector struct {
+	*deadLetterer[ptrace.Traces]
+	primary    consumer.Traces
+	deadLetter consumer.Traces
+}
+
+func (c *tracesConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *tracesConnector) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	var consumeDeadLetter func(context.Context, ptrace.Traces) error
+	if c.deadLetter != nil {
+		consumeDeadLetter = c.deadLetter.ConsumeTraces
+	}
+	return c.consume(ctx, td, func(td ptrace.Traces) ptrace.Traces {
+		clone := ptrace.NewTraces()
+		td.CopyTo(clone)
+		return clone
+	}, c.primary.Capabilities(), c.primary.ConsumeTraces, consumeDeadLetter)
+}
+
+type metricsConnector struct {
+	*deadLetterer[pmetric.Metrics]
+	primary    consumer.Metrics
+	deadLetter consumer.Metrics
+}
+
+func (c *metricsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *metricsConnector) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	var consumeDeadLetter func(context.Context, pmetric.Metrics) error
+	if c.deadLetter != nil {
+		consumeDeadLetter = c.deadLetter.ConsumeMetrics
+	}
+	return c.consume(ctx, md, func(md pmetric.Metrics) pmetric.Metrics {
+		clone := pmetric.NewMetrics()
+		md.CopyTo(clone)
+		return clone
+	}, c.primary.Capabilities(), c.primary.ConsumeMetrics, consumeDeadLetter)
+}
+
+type logsConnector struct {
+	*deadLetterer[plog.Logs]
+	primary    consumer.Logs
+	deadLetter consumer.Logs
+}
+
+func (c *logsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *logsConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	var consumeDeadLetter func(context.Context, plog.Logs) error
+	if c.deadLetter != nil {
+		consumeDeadLetter = c.deadLetter.ConsumeLogs
+	}
+	return c.consume(ctx, ld, func(ld plog.Logs) plog.Logs {
+		clone := plog.NewLogs()
+		ld.CopyTo(clone)
+		return clone
+	}, c.primary.Capabilities(), c.primary.ConsumeLogs, consumeDeadLetter)
+}
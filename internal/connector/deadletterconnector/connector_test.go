@@ -0,0 +1,122 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletterconnector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.uber.org/zap"
+)
+
+type mutatingErrSink struct {
+	err error
+}
+
+func (s *mutatingErrSink) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (s *mutatingErrSink) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	ld.ResourceLogs().At(0).Resource().Attributes().PutStr("mutated", "true")
+	return s.err
+}
+
+func testLogs() plog.Logs {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("log")
+	return ld
+}
+
+func newTestConnector(t *testing.T, primary, deadLetter consumer.Logs, dir string) *logsConnector {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Pipelines = []pipeline.ID{pipeline.NewID(pipeline.SignalLogs)}
+	cfg.Directory = dir
+	c := &logsConnector{
+		primary:    primary,
+		deadLetter: deadLetter,
+		deadLetterer: newDeadLetterer(zap.NewNop(), cfg, signalLogs, func(ld plog.Logs) ([]byte, error) {
+			return (&plog.JSONMarshaler{}).MarshalLogs(ld)
+		}),
+	}
+	require.NoError(t, c.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, c.Shutdown(context.Background())) })
+	return c
+}
+
+func TestConsumedDataIsNotDeadLettered(t *testing.T) {
+	primary := new(consumertest.LogsSink)
+	deadLetter := new(consumertest.LogsSink)
+	dir := t.TempDir()
+	c := newTestConnector(t, primary, deadLetter, dir)
+
+	require.NoError(t, c.ConsumeLogs(context.Background(), testLogs()))
+	assert.Len(t, primary.AllLogs(), 1)
+	assert.Empty(t, deadLetter.AllLogs())
+	files, err := ListFiles(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFailedDataIsDeadLettered(t *testing.T) {
+	deadLetter := new(consumertest.LogsSink)
+	dir := t.TempDir()
+	c := newTestConnector(t, &mutatingErrSink{err: errors.New("retries exhausted")}, deadLetter, dir)
+
+	require.NoError(t, c.ConsumeLogs(context.Background(), testLogs()))
+	require.NoError(t, c.ConsumeLogs(context.Background(), testLogs()))
+
+	// the pipelines got a copy, so the data is dead-lettered unchanged
+	require.Len(t, deadLetter.AllLogs(), 2)
+	assert.Equal(t, testLogs(), deadLetter.AllLogs()[0])
+
+	files, err := ListFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, signalLogs, files[0].Signal)
+	content, err := os.ReadFile(files[0].Path)
+	require.NoError(t, err)
+	batch, err := (&plog.JSONMarshaler{}).MarshalLogs(testLogs())
+	require.NoError(t, err)
+	assert.Equal(t, string(batch)+"\n"+string(batch)+"\n", string(content))
+}
+
+func TestPipelinesErrorReturnedWhenDeadLetteringFails(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestConnector(t, consumertest.NewErr(errors.New("retries exhausted")), consumertest.NewErr(errors.New("archive unavailable")), dir)
+
+	require.EqualError(t, c.ConsumeLogs(context.Background(), testLogs()), "retries exhausted")
+	// the data is still written to the files
+	files, err := ListFiles(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// the directory is replaced by a file the files can't be written to
+	sub := filepath.Join(dir, "sub")
+	c = newTestConnector(t, consumertest.NewErr(errors.New("retries exhausted")), nil, sub)
+	require.NoError(t, os.RemoveAll(sub))
+	require.NoError(t, os.WriteFile(sub, nil, 0o600))
+	require.EqualError(t, c.ConsumeLogs(context.Background(), testLogs()), "retries exhausted")
+}
@@ -0,0 +1,131 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletterconnector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	typeStr   = "deadletter"
+	stability = component.StabilityLevelDevelopment
+)
+
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		connector.WithTracesToTraces(createTracesToTraces, stability),
+		connector.WithMetricsToMetrics(createMetricsToMetrics, stability),
+		connector.WithLogsToLogs(createLogsToLogs, stability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		MaxFileSizeMiB:  64,
+		MaxTotalSizeMiB: 1024,
+		MaxAge:          7 * 24 * time.Hour,
+	}
+}
+
+func createTracesToTraces(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (connector.Traces, error) {
+	router, ok := nextConsumer.(connector.TracesRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	c := &tracesConnector{primary: primary}
+	if len(oCfg.DeadLetterPipelines) > 0 {
+		if c.deadLetter, err = router.Consumer(oCfg.DeadLetterPipelines...); err != nil {
+			return nil, err
+		}
+	}
+	c.deadLetterer = newDeadLetterer(set.Logger, oCfg, signalTraces, func(td ptrace.Traces) ([]byte, error) {
+		return (&ptrace.JSONMarshaler{}).MarshalTraces(td)
+	})
+	return c, nil
+}
+
+func createMetricsToMetrics(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Metrics, error) {
+	router, ok := nextConsumer.(connector.MetricsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	c := &metricsConnector{primary: primary}
+	if len(oCfg.DeadLetterPipelines) > 0 {
+		if c.deadLetter, err = router.Consumer(oCfg.DeadLetterPipelines...); err != nil {
+			return nil, err
+		}
+	}
+	c.deadLetterer = newDeadLetterer(set.Logger, oCfg, signalMetrics, func(md pmetric.Metrics) ([]byte, error) {
+		return (&pmetric.JSONMarshaler{}).MarshalMetrics(md)
+	})
+	return c, nil
+}
+
+func createLogsToLogs(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (connector.Logs, error) {
+	router, ok := nextConsumer.(connector.LogsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	c := &logsConnector{primary: primary}
+	if len(oCfg.DeadLetterPipelines) > 0 {
+		if c.deadLetter, err = router.Consumer(oCfg.DeadLetterPipelines...); err != nil {
+			return nil, err
+		}
+	}
+	c.deadLetterer = newDeadLetterer(set.Logger, oCfg, signalLogs, func(ld plog.Logs) ([]byte, error) {
+		return (&plog.JSONMarshaler{}).MarshalLogs(ld)
+	})
+	return c, nil
+}
@@ -0,0 +1,193 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletterconnector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	signalTraces  = "traces"
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+
+	fileExt = ".json"
+	mib     = 1024 * 1024
+)
+
+// File is a dead letter file, with a line per batch of data in the OTLP JSON encoding of the export
+// request of its signal.
+type File struct {
+	// Path is the path of the file.
+	Path string
+	// Signal is the signal of the data, traces, metrics or logs, and the OTLP/HTTP path of its export requests.
+	Signal string
+	// Created is when the file was created.
+	Created time.Time
+}
+
+// ListFiles returns the dead letter files of the directory, oldest first.
+func ListFiles(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, entry := range entries {
+		if f, ok := parseFileName(entry.Name()); ok && entry.Type().IsRegular() {
+			f.Path = filepath.Join(dir, entry.Name())
+			files = append(files, f)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Created.Before(files[j].Created) })
+	return files, nil
+}
+
+// fileName returns the name of the file of the signal created at t, e.g. "logs-1705752000000000000.json".
+func fileName(signal string, t time.Time) string {
+	return fmt.Sprintf("%s-%d%s", signal, t.UnixNano(), fileExt)
+}
+
+func parseFileName(name string) (File, bool) {
+	signal, created, ok := strings.Cut(strings.TrimSuffix(name, fileExt), "-")
+	if !ok || !strings.HasSuffix(name, fileExt) || (signal != signalTraces && signal != signalMetrics && signal != signalLogs) {
+		return File{}, false
+	}
+	nanos, err := strconv.ParseInt(created, 10, 64)
+	if err != nil {
+		return File{}, false
+	}
+	return File{Signal: signal, Created: time.Unix(0, nanos)}, true
+}
+
+// fileWriter appends batches to the dead letter files of a signal, rotating them at their max size and
+// removing the files past their max age or total size when rotating.
+type fileWriter struct {
+	now          func() time.Time
+	file         *os.File
+	dir          string
+	signal       string
+	maxFileSize  int64
+	maxTotalSize int64
+	maxAge       time.Duration
+	size         int64
+	mu           sync.Mutex
+}
+
+func newFileWriter(cfg *Config, signal string) *fileWriter {
+	return &fileWriter{
+		now:          time.Now,
+		dir:          cfg.Directory,
+		signal:       signal,
+		maxFileSize:  cfg.MaxFileSizeMiB * mib,
+		maxTotalSize: cfg.MaxTotalSizeMiB * mib,
+		maxAge:       cfg.MaxAge,
+	}
+}
+
+func (w *fileWriter) start() error {
+	if err := os.MkdirAll(w.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create the dead letter directory: %w", err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.removeExpired()
+}
+
+// write appends a batch to the current file, and syncs it so the batch isn't lost if the collector crashes.
+func (w *fileWriter) write(b []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file != nil && w.size > 0 && w.size+int64(len(b))+1 > w.maxFileSize {
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.removeExpired(); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(filepath.Join(w.dir, fileName(w.signal, w.now())), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		w.file, w.size = f, 0
+	}
+	n, err := w.file.Write(append(b, '\n'))
+	w.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+func (w *fileWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeFile()
+}
+
+func (w *fileWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// removeExpired removes the closed files of the signal older than the max age, and the oldest ones until the
+// files fit in the max total size with a new file.
+func (w *fileWriter) removeExpired() error {
+	files, err := ListFiles(w.dir)
+	if err != nil {
+		return err
+	}
+	var signalFiles []File
+	var sizes []int64
+	var total int64
+	for _, f := range files {
+		if f.Signal != w.signal || (w.file != nil && f.Path == w.file.Name()) {
+			continue
+		}
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			continue
+		}
+		signalFiles = append(signalFiles, f)
+		sizes = append(sizes, info.Size())
+		total += info.Size()
+	}
+	now := w.now()
+	for i, f := range signalFiles {
+		expired := w.maxAge > 0 && now.Sub(f.Created) > w.maxAge
+		oversized := w.maxTotalSize > 0 && total+w.maxFileSize > w.maxTotalSize
+		if !expired && !oversized {
+			break
+		}
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= sizes[i]
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletterconnector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+func TestFileWriterRotation(t *testing.T) {
+	dir := t.TempDir()
+	w := newFileWriter(&Config{Directory: dir, MaxFileSizeMiB: 1}, signalMetrics)
+	now, clock := testclock.New(time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC))
+	w.now = now
+	require.NoError(t, w.start())
+
+	batch := make([]byte, mib/2-1)
+	for i := range batch {
+		batch[i] = 'a'
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.write(batch))
+		*clock = clock.Add(time.Second)
+	}
+	require.NoError(t, w.close())
+
+	files, err := ListFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for i, expectedBatches := range []int{1, 2} {
+		info, err := os.Stat(files[len(files)-1-i].Path)
+		require.NoError(t, err)
+		assert.Equal(t, int64(expectedBatches*(len(batch)+1)), info.Size())
+	}
+	assert.Equal(t, filepath.Join(dir, "metrics-1705752000000000000.json"), files[0].Path)
+	assert.Equal(t, time.Date(2024, 1, 20, 12, 0, 2, 0, time.UTC), files[1].Created.UTC())
+}
+
+func TestFileWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+	for _, name := range []string{
+		fileName(signalLogs, start.Add(-48*time.Hour)),
+		fileName(signalLogs, start.Add(-2*time.Hour)),
+		fileName(signalLogs, start.Add(-time.Hour)),
+		// files of other signals are left to their own writer
+		fileName(signalTraces, start.Add(-48*time.Hour)),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), make([]byte, mib), 0o600))
+	}
+
+	w := newFileWriter(&Config{Directory: dir, MaxFileSizeMiB: 1, MaxTotalSizeMiB: 2, MaxAge: 24 * time.Hour}, signalLogs)
+	now, _ := testclock.New(start)
+	w.now = now
+	require.NoError(t, w.start())
+	defer func() { require.NoError(t, w.close()) }()
+
+	files, err := ListFiles(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.Path))
+	}
+	// the expired file is removed, and the oldest remaining one to make room for a new file
+	assert.Equal(t, []string{
+		fileName(signalTraces, start.Add(-48*time.Hour)),
+		fileName(signalLogs, start.Add(-time.Hour)),
+	}, names)
+}
+
+func TestParseFileName(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"logs-1705752000000000000.json":     true,
+		"traces-1705752000000000000.json":   true,
+		"metrics-1705752000000000000.json":  true,
+		"events-1705752000000000000.json":   false,
+		"logs-1705752000000000000.json.tmp": false,
+		"logs-latest.json":                  false,
+		"logs.json":                         false,
+	} {
+		_, ok := parseFileName(name)
+		assert.Equal(t, expected, ok, name)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanoutconnector
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the pipelines data is fanned out to and whose failures are reported upstream.
+type Config struct {
+	// Pipelines receive the data, and their errors are returned to the receiver, e.g. failing
+	// the request of the sender.
+	Pipelines []pipeline.ID `mapstructure:"pipelines"`
+	// SecondaryPipelines receive the data too, but their errors are only logged.
+	SecondaryPipelines []pipeline.ID `mapstructure:"secondary_pipelines"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Pipelines) == 0 {
+		errs = errors.Join(errs, errors.New("at least one pipeline must be specified"))
+	}
+	if len(cfg.SecondaryPipelines) == 0 {
+		errs = errors.Join(errs, errors.New("at least one secondary pipeline must be specified"))
+	}
+	primary := map[pipeline.ID]struct{}{}
+	for _, id := range cfg.Pipelines {
+		primary[id] = struct{}{}
+	}
+	for _, id := range cfg.SecondaryPipelines {
+		if _, ok := primary[id]; ok {
+			errs = errors.Join(errs, fmt.Errorf("pipeline %q can't be both a pipeline and a secondary pipeline", id))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanoutconnector
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Pipelines:          []pipeline.ID{pipeline.NewIDWithName(pipeline.SignalMetrics, "primary")},
+				SecondaryPipelines: []pipeline.ID{pipeline.NewIDWithName(pipeline.SignalMetrics, "archive")},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "multiple"),
+			expected: &Config{
+				Pipelines: []pipeline.ID{
+					pipeline.NewIDWithName(pipeline.SignalLogs, "us0"),
+					pipeline.NewIDWithName(pipeline.SignalLogs, "us1"),
+				},
+				SecondaryPipelines: []pipeline.ID{
+					pipeline.NewIDWithName(pipeline.SignalLogs, "file"),
+					pipeline.NewIDWithName(pipeline.SignalLogs, "sampling"),
+				},
+			},
+		},
+		{
+			id:          component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "pipeline \"traces/primary\" can't be both a pipeline and a secondary pipeline",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateRequiresPipelines(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.EqualError(t, cfg.Validate(), "at least one pipeline must be specified\n"+
+		"at least one secondary pipeline must be specified")
+}
@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanoutconnector
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+var (
+	_ connector.Traces  = (*tracesConnector)(nil)
+	_ connector.Metrics = (*metricsConnector)(nil)
+	_ connector.Logs    = (*logsConnector)(nil)
+)
+
+// fanout sends the data to the secondary pipelines, then to the primary ones, and only returns
+// the primary pipelines' error. The secondary pipelines get a copy if they mutate the data, so
+// the primary pipelines receive it unchanged.
+func fanout[T any](
+	ctx context.Context,
+	logger *zap.Logger,
+	data T,
+	clone func(T) T,
+	primary func(context.Context, T) error,
+	secondary consumer.Capabilities,
+	consumeSecondary func(context.Context, T) error,
+) error {
+	secondaryData := data
+	if secondary.MutatesData {
+		secondaryData = clone(data)
+	}
+	if err := consumeSecondary(ctx, secondaryData); err != nil {
+		logger.Warn("Secondary pipelines failed to consume data", zap.Error(err))
+	}
+	return primary(ctx, data)
+}
+
+type tracesConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	logger    *zap.Logger
+	primary   consumer.Traces
+	secondary consumer.Traces
+}
+
+func (c *tracesConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *tracesConnector) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return fanout(ctx, c.logger, td, func(td ptrace.Traces) ptrace.Traces {
+		clone := ptrace.NewTraces()
+		td.CopyTo(clone)
+		return clone
+	}, c.primary.ConsumeTraces, c.secondary.Capabilities(), c.secondary.ConsumeTraces)
+}
+
+type metricsConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	logger    *zap.Logger
+	primary   consumer.Metrics
+	secondary consumer.Metrics
+}
+
+func (c *metricsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *metricsConnector) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return fanout(ctx, c.logger, md, func(md pmetric.Metrics) pmetric.Metrics {
+		clone := pmetric.NewMetrics()
+		md.CopyTo(clone)
+		return clone
+	}, c.primary.ConsumeMetrics, c.secondary.Capabilities(), c.secondary.ConsumeMetrics)
+}
+
+type logsConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+	logger    *zap.Logger
+	primary   consumer.Logs
+	secondary consumer.Logs
+}
+
+func (c *logsConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (c *logsConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return fanout(ctx, c.logger, ld, func(ld plog.Logs) plog.Logs {
+		clone := plog.NewLogs()
+		ld.CopyTo(clone)
+		return clone
+	}, c.primary.ConsumeLogs, c.secondary.Capabilities(), c.secondary.ConsumeLogs)
+}
@@ -0,0 +1,89 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanoutconnector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type mutatingSink struct {
+	consumertest.MetricsSink
+}
+
+func (s *mutatingSink) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (s *mutatingSink) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	md.ResourceMetrics().At(0).Resource().Attributes().PutStr("mutated", "true")
+	return s.MetricsSink.ConsumeMetrics(ctx, md)
+}
+
+func testMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("metric")
+	return md
+}
+
+func TestSecondaryErrorsAreLogged(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	primary := new(consumertest.MetricsSink)
+	c := &metricsConnector{
+		logger:    zap.New(core),
+		primary:   primary,
+		secondary: consumertest.NewErr(errors.New("archive unavailable")),
+	}
+
+	require.NoError(t, c.ConsumeMetrics(context.Background(), testMetrics()))
+	assert.Len(t, primary.AllMetrics(), 1)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "Secondary pipelines failed to consume data", logs.All()[0].Message)
+}
+
+func TestPrimaryErrorsAreReturned(t *testing.T) {
+	secondary := new(consumertest.MetricsSink)
+	c := &metricsConnector{
+		logger:    zap.NewNop(),
+		primary:   consumertest.NewErr(errors.New("export failed")),
+		secondary: secondary,
+	}
+
+	require.EqualError(t, c.ConsumeMetrics(context.Background(), testMetrics()), "export failed")
+	assert.Len(t, secondary.AllMetrics(), 1)
+}
+
+func TestMutatingSecondaryGetsCopy(t *testing.T) {
+	primary := new(consumertest.MetricsSink)
+	secondary := new(mutatingSink)
+	c := &metricsConnector{logger: zap.NewNop(), primary: primary, secondary: secondary}
+
+	require.NoError(t, c.ConsumeMetrics(context.Background(), testMetrics()))
+	require.Len(t, secondary.AllMetrics(), 1)
+	_, ok := secondary.AllMetrics()[0].ResourceMetrics().At(0).Resource().Attributes().Get("mutated")
+	assert.True(t, ok)
+	require.Len(t, primary.AllMetrics(), 1)
+	_, ok = primary.AllMetrics()[0].ResourceMetrics().At(0).Resource().Attributes().Get("mutated")
+	assert.False(t, ok)
+}
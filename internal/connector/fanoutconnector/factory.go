@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanoutconnector
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+const (
+	typeStr   = "fanout"
+	stability = component.StabilityLevelDevelopment
+)
+
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		connector.WithTracesToTraces(createTracesToTraces, stability),
+		connector.WithMetricsToMetrics(createMetricsToMetrics, stability),
+		connector.WithLogsToLogs(createLogsToLogs, stability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{}
+}
+
+func createTracesToTraces(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (connector.Traces, error) {
+	router, ok := nextConsumer.(connector.TracesRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := router.Consumer(oCfg.SecondaryPipelines...)
+	if err != nil {
+		return nil, err
+	}
+	return &tracesConnector{logger: set.Logger, primary: primary, secondary: secondary}, nil
+}
+
+func createMetricsToMetrics(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (connector.Metrics, error) {
+	router, ok := nextConsumer.(connector.MetricsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := router.Consumer(oCfg.SecondaryPipelines...)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsConnector{logger: set.Logger, primary: primary, secondary: secondary}, nil
+}
+
+func createLogsToLogs(
+	_ context.Context,
+	set connector.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (connector.Logs, error) {
+	router, ok := nextConsumer.(connector.LogsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("expected consumer to be a connector router")
+	}
+	oCfg := cfg.(*Config)
+	primary, err := router.Consumer(oCfg.Pipelines...)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := router.Consumer(oCfg.SecondaryPipelines...)
+	if err != nil {
+		return nil, err
+	}
+	return &logsConnector{logger: set.Logger, primary: primary, secondary: secondary}, nil
+}
@@ -0,0 +1,181 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package egress restricts the hosts the HTTP and gRPC clients of the collector connect to,
+// for networks where it must be provable that no other destination is ever contacted.
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// Allowlist is a set of allowed hosts. Entries are host names, `*.` prefixed domains allowing
+// all their subdomains, IP addresses and CIDR ranges.
+type Allowlist struct {
+	hosts   map[string]struct{}
+	domains []string
+	nets    []*net.IPNet
+}
+
+// NewAllowlist returns the allowlist of the entries.
+func NewAllowlist(entries []string) (*Allowlist, error) {
+	a := &Allowlist{hosts: map[string]struct{}{}}
+	for _, entry := range entries {
+		entry = normalize(entry)
+		switch {
+		case strings.HasPrefix(entry, "*.") && len(entry) > 2 && !strings.ContainsAny(entry[2:], "*/:"):
+			a.domains = append(a.domains, entry[1:])
+		case strings.Contains(entry, "/"):
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, invalidEntryError(entry)
+			}
+			a.nets = append(a.nets, ipNet)
+		case entry == "" || strings.Contains(entry, "*") || strings.Contains(entry, ":") && net.ParseIP(entry) == nil:
+			return nil, invalidEntryError(entry)
+		default:
+			if ip := net.ParseIP(entry); ip != nil {
+				entry = ip.String()
+			}
+			a.hosts[entry] = struct{}{}
+		}
+	}
+	return a, nil
+}
+
+func invalidEntryError(entry string) error {
+	return fmt.Errorf("invalid allowed host %q: must be a host name, a *. prefixed domain, an IP address or a CIDR range", entry)
+}
+
+// Allows returns whether the host, without a port, is allowed.
+func (a *Allowlist) Allows(host string) bool {
+	host = normalize(host)
+	if _, ok := a.hosts[host]; ok {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if _, ok := a.hosts[ip.String()]; ok {
+			return true
+		}
+		for _, ipNet := range a.nets {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, domain := range a.domains {
+		if strings.HasSuffix(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(host string) string {
+	return strings.TrimSuffix(strings.Trim(strings.ToLower(strings.TrimSpace(host)), "[]"), ".")
+}
+
+// NotAllowedError is returned when connecting to a host that isn't allowed.
+type NotAllowedError struct {
+	Host string
+}
+
+func (e *NotAllowedError) Error() string {
+	return fmt.Sprintf("connection to %q refused: the host isn't in the egress allowlist", e.Host)
+}
+
+var (
+	current     atomic.Pointer[Allowlist]
+	installOnce sync.Once
+)
+
+// Enforce makes the clients created from then on refuse connections to the hosts the
+// allowlist doesn't allow. confighttp clients are restricted by the http.DefaultTransport
+// they're cloned from and configgrpc clients by the dns and passthrough gRPC resolvers their
+// targets are resolved with, so Enforce must be called before components start, as the egress
+// extension does. A nil allowlist lifts the restriction.
+func Enforce(a *Allowlist) {
+	installOnce.Do(install)
+	current.Store(a)
+}
+
+// Check returns a NotAllowedError if the host, with or without a port, isn't allowed.
+func Check(hostport string) error {
+	a := current.Load()
+	if a == nil {
+		return nil
+	}
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	if !a.Allows(host) {
+		return &NotAllowedError{Host: host}
+	}
+	return nil
+}
+
+func install() {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if err := Check(addr); err != nil {
+				return nil, err
+			}
+			return dial(ctx, network, addr)
+		}
+		proxy := t.Proxy
+		// Requests sent through a proxy are checked against their destination, in addition
+		// to the proxy host being checked when dialed.
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			if err := Check(req.URL.Host); err != nil {
+				return nil, err
+			}
+			if proxy == nil {
+				return nil, nil
+			}
+			return proxy(req)
+		}
+	}
+	for _, scheme := range []string{"dns", "passthrough"} {
+		if b := resolver.Get(scheme); b != nil {
+			resolver.Register(&resolverBuilder{Builder: b})
+		}
+	}
+}
+
+// resolverBuilder refuses to resolve the targets of gRPC clients that aren't allowed.
+type resolverBuilder struct {
+	resolver.Builder
+}
+
+func (b *resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	if err := Check(target.Endpoint()); err != nil {
+		return nil, err
+	}
+	return b.Builder.Build(target, cc, opts)
+}
@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestAllowlist(t *testing.T) {
+	a, err := NewAllowlist([]string{"ingest.us0.signalfx.com", "*.corp.example.com", "10.0.0.0/8", "LOCALHOST", "::1"})
+	require.NoError(t, err)
+
+	for host, allowed := range map[string]bool{
+		"ingest.us0.signalfx.com":  true,
+		"INGEST.us0.signalfx.com.": true,
+		"api.us0.signalfx.com":     false,
+		"otlp.corp.example.com":    true,
+		"a.b.corp.example.com":     true,
+		"corp.example.com":         false,
+		"evilcorp.example.com":     false,
+		"10.1.2.3":                 true,
+		"11.1.2.3":                 false,
+		"localhost":                true,
+		"[::1]":                    true,
+		"0:0:0:0:0:0:0:1":          true,
+		"::2":                      false,
+	} {
+		assert.Equal(t, allowed, a.Allows(host), host)
+	}
+}
+
+func TestNewAllowlistInvalid(t *testing.T) {
+	for _, entry := range []string{"", "https://ingest.us0.signalfx.com", "ingest.us0.signalfx.com:443", "*", "*.", "ingest.*.signalfx.com", "10.0.0.0/33"} {
+		_, err := NewAllowlist([]string{entry})
+		assert.ErrorContains(t, err, "must be a host name, a *. prefixed domain, an IP address or a CIDR range", entry)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	allowed, err := NewAllowlist([]string{"127.0.0.1"})
+	require.NoError(t, err)
+	Enforce(allowed)
+	t.Cleanup(func() { Enforce(nil) })
+
+	client := &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, err = client.Get("http://ingest.us0.signalfx.com")
+	var notAllowed *NotAllowedError
+	require.True(t, errors.As(err, &notAllowed))
+	assert.Equal(t, "ingest.us0.signalfx.com", notAllowed.Host)
+	assert.EqualError(t, notAllowed, `connection to "ingest.us0.signalfx.com" refused: the host isn't in the egress allowlist`)
+
+	conn, err := grpc.NewClient("ingest.us0.signalfx.com:443", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.ErrorContains(t, err, `connection to "ingest.us0.signalfx.com" refused`)
+
+	Enforce(nil)
+	require.NoError(t, Check("ingest.us0.signalfx.com:443"))
+}
+
+func TestCheck(t *testing.T) {
+	allowed, err := NewAllowlist([]string{"localhost"})
+	require.NoError(t, err)
+	Enforce(allowed)
+	t.Cleanup(func() { Enforce(nil) })
+
+	require.NoError(t, Check("localhost"))
+	require.NoError(t, Check("localhost:4317"))
+	require.EqualError(t, Check("127.0.0.1:4317"), `connection to "127.0.0.1" refused: the host isn't in the egress allowlist`)
+}
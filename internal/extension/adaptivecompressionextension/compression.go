@@ -0,0 +1,317 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivecompressionextension
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	scopeName = "github.com/signalfx/splunk-otel-collector/internal/extension/adaptivecompressionextension"
+
+	encodingNone = "none"
+
+	// ewmaWeight is the weight of each new measurement in the moving averages.
+	ewmaWeight = 0.2
+	// defaultThroughput is the throughput in bytes per second assumed until it's measured.
+	defaultThroughput = 10 << 20
+	// minThroughputSampleSize is the size in bytes of the smallest requests the throughput is
+	// measured with, so it isn't dominated by the latency of the destination.
+	minThroughputSampleSize = 64 << 10
+	// cpuSampleInterval is the minimum interval the CPU headroom is sampled at.
+	cpuSampleInterval = time.Second
+	// entropySampleSize is the number of bytes the entropy of payloads is estimated from.
+	entropySampleSize  = 4096
+	entropySampleChunk = 256
+)
+
+// encodingStats are the moving averages of the compressions of an encoding.
+type encodingStats struct {
+	// ratio is the compressed size over the uncompressed size.
+	ratio float64
+	// secondsPerByte is the CPU time of the compression per uncompressed byte.
+	secondsPerByte float64
+}
+
+// defaultStats are the stats assumed for the encodings until their compressions are measured,
+// of telemetry payloads compressed with the default compression level.
+var defaultStats = map[string]encodingStats{
+	encodingGzip: {ratio: 0.3, secondsPerByte: 1.0 / (50 << 20)},
+	encodingZstd: {ratio: 0.25, secondsPerByte: 1.0 / (200 << 20)},
+}
+
+// tuner chooses the encoding of each request sending it fastest: the one minimizing the time
+// to compress it, scaled by the CPU headroom, plus the time to transfer it at the measured
+// throughput.
+type tuner struct {
+	cfg         *Config
+	zstd        *zstd.Encoder
+	gzipWriters sync.Pool
+	now         func() time.Time
+	// cpuSamples are the runtime/metrics samples of the total and idle CPU time.
+	cpuSamples []metrics.Sample
+	requests   metric.Int64Counter
+	reqBytes   metric.Int64Counter
+	sentBytes  metric.Int64Counter
+	extension  attribute.KeyValue
+
+	mu         sync.Mutex
+	stats      map[string]encodingStats
+	throughput float64
+	headroom   float64
+	lastSample time.Time
+	lastTotal  float64
+	lastIdle   float64
+}
+
+func newTuner(set component.TelemetrySettings, id component.ID, cfg *Config) (*tuner, error) {
+	meterProvider := set.MeterProvider
+	if set.LeveledMeterProvider != nil {
+		meterProvider = set.LeveledMeterProvider(configtelemetry.LevelBasic)
+	}
+	meter := meterProvider.Meter(scopeName)
+	t := &tuner{
+		cfg: cfg,
+		now: time.Now,
+		cpuSamples: []metrics.Sample{
+			{Name: "/cpu/classes/total:cpu-seconds"},
+			{Name: "/cpu/classes/idle:cpu-seconds"},
+		},
+		extension:  attribute.String("extension", id.String()),
+		stats:      map[string]encodingStats{},
+		throughput: defaultThroughput,
+		headroom:   1,
+	}
+	for _, encoding := range cfg.Encodings {
+		t.stats[encoding] = defaultStats[encoding]
+		if encoding == encodingZstd {
+			var err error
+			if t.zstd, err = zstd.NewWriter(nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var err error
+	if t.requests, err = meter.Int64Counter(
+		"adaptive_compression_requests",
+		metric.WithDescription("Number of requests sent per encoding."),
+		metric.WithUnit("{requests}"),
+	); err != nil {
+		return nil, err
+	}
+	if t.reqBytes, err = meter.Int64Counter(
+		"adaptive_compression_uncompressed_bytes",
+		metric.WithDescription("Uncompressed size of the requests sent per encoding."),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, err
+	}
+	if t.sentBytes, err = meter.Int64Counter(
+		"adaptive_compression_sent_bytes",
+		metric.WithDescription("Size of the requests sent per encoding, after compression."),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// choose returns the encoding the payload is sent fastest with, or encodingNone.
+func (t *tuner) choose(body []byte) string {
+	if len(body) < t.cfg.MinSize || entropy(body) > t.cfg.MaxEntropy {
+		return encodingNone
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sampleCPU()
+	if t.headroom < t.cfg.MinCPUHeadroom {
+		return encodingNone
+	}
+	size := float64(len(body))
+	best, bestCost := encodingNone, size/t.throughput
+	for _, encoding := range t.cfg.Encodings {
+		stats := t.stats[encoding]
+		cost := size*stats.secondsPerByte/math.Max(t.headroom, 0.01) + size*stats.ratio/t.throughput
+		if cost < bestCost {
+			best, bestCost = encoding, cost
+		}
+	}
+	return best
+}
+
+// sampleCPU updates the CPU headroom, the fraction of the CPU time available to the collector's
+// GOMAXPROCS that was idle since the last sample. It must be called with the lock held.
+func (t *tuner) sampleCPU() {
+	now := t.now()
+	if now.Sub(t.lastSample) < cpuSampleInterval {
+		return
+	}
+	t.lastSample = now
+	metrics.Read(t.cpuSamples)
+	if t.cpuSamples[0].Value.Kind() != metrics.KindFloat64 || t.cpuSamples[1].Value.Kind() != metrics.KindFloat64 {
+		return
+	}
+	total, idle := t.cpuSamples[0].Value.Float64(), t.cpuSamples[1].Value.Float64()
+	// the runtime only updates the CPU time at the end of garbage collections, so the headroom is
+	// kept until they're updated
+	if total > t.lastTotal {
+		t.headroom = min(max((idle-t.lastIdle)/(total-t.lastTotal), 0), 1)
+	}
+	t.lastTotal, t.lastIdle = total, idle
+}
+
+// compress compresses the payload with the encoding, recording the ratio and CPU time of the
+// compression.
+func (t *tuner) compress(encoding string, body []byte) ([]byte, error) {
+	start := t.now()
+	var compressed []byte
+	switch encoding {
+	case encodingGzip:
+		var buf bytes.Buffer
+		buf.Grow(len(body) / 2)
+		w, ok := t.gzipWriters.Get().(*gzip.Writer)
+		if ok {
+			w.Reset(&buf)
+		} else {
+			w = gzip.NewWriter(&buf)
+		}
+		defer t.gzipWriters.Put(w)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		compressed = buf.Bytes()
+	case encodingZstd:
+		compressed = t.zstd.EncodeAll(body, make([]byte, 0, len(body)/2))
+	}
+	elapsed := t.now().Sub(start)
+
+	t.mu.Lock()
+	stats := t.stats[encoding]
+	stats.ratio = ewma(stats.ratio, float64(len(compressed))/float64(len(body)))
+	stats.secondsPerByte = ewma(stats.secondsPerByte, elapsed.Seconds()/float64(len(body)))
+	t.stats[encoding] = stats
+	t.mu.Unlock()
+	return compressed, nil
+}
+
+// recordSent records a request sent with the encoding, measuring the throughput with the time
+// from sending it to receiving the response.
+func (t *tuner) recordSent(ctx context.Context, encoding string, uncompressed, sent int, elapsed time.Duration) {
+	attrs := metric.WithAttributes(t.extension, attribute.String("encoding", encoding))
+	t.requests.Add(ctx, 1, attrs)
+	t.reqBytes.Add(ctx, int64(uncompressed), attrs)
+	t.sentBytes.Add(ctx, int64(sent), attrs)
+	if sent < minThroughputSampleSize || elapsed <= 0 {
+		return
+	}
+	t.mu.Lock()
+	t.throughput = ewma(t.throughput, float64(sent)/elapsed.Seconds())
+	t.mu.Unlock()
+}
+
+func ewma(average, value float64) float64 {
+	return average + ewmaWeight*(value-average)
+}
+
+// entropy estimates the Shannon entropy in bits per byte of the payload from evenly spaced
+// chunks of it.
+func entropy(body []byte) float64 {
+	var counts [256]int
+	var n int
+	if len(body) <= entropySampleSize {
+		for _, b := range body {
+			counts[b]++
+		}
+		n = len(body)
+	} else {
+		chunks := entropySampleSize / entropySampleChunk
+		stride := (len(body) - entropySampleChunk) / (chunks - 1)
+		for i := 0; i < chunks; i++ {
+			for _, b := range body[i*stride : i*stride+entropySampleChunk] {
+				counts[b]++
+			}
+		}
+		n = entropySampleSize
+	}
+	var h float64
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / float64(n)
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// compressingRoundTripper compresses the uncompressed requests with the encoding the tuner
+// chooses.
+type compressingRoundTripper struct {
+	base  http.RoundTripper
+	tuner *tuner
+}
+
+func (rt *compressingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return rt.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	encoding := rt.tuner.choose(body)
+	payload := body
+	if encoding != encodingNone {
+		compressed, err := rt.tuner.compress(encoding, body)
+		if err != nil || len(compressed) >= len(body) {
+			encoding = encodingNone
+		} else {
+			payload = compressed
+		}
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+	req.ContentLength = int64(len(payload))
+	if encoding != encodingNone {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	start := rt.tuner.now()
+	resp, err := rt.base.RoundTrip(req)
+	if err == nil {
+		rt.tuner.recordSent(req.Context(), encoding, len(body), len(payload), rt.tuner.now().Sub(start))
+	}
+	return resp, err
+}
@@ -0,0 +1,197 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivecompressionextension
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func newTestTuner(t *testing.T, cfg *Config) *tuner {
+	tu, err := newTuner(componenttest.NewNopTelemetrySettings(), component.MustNewID(typeStr), cfg)
+	require.NoError(t, err)
+	// the CPU headroom is set by the tests
+	tu.lastSample = time.Now().Add(time.Hour)
+	return tu
+}
+
+// telemetryPayload returns a compressible payload of the size.
+func telemetryPayload(size int) []byte {
+	var b strings.Builder
+	for i := 0; b.Len() < size; i++ {
+		fmt.Fprintf(&b, `{"name":"http.server.duration","host":"host-%d","value":%d}`, i%50, i*7%1000)
+	}
+	return []byte(b.String()[:size])
+}
+
+func randomPayload(t *testing.T, size int) []byte {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
+
+func TestEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, entropy(bytes.Repeat([]byte{'a'}, 10000)))
+	assert.InDelta(t, 1.0, entropy(bytes.Repeat([]byte("ab"), 10000)), 0.01)
+	assert.Greater(t, entropy(randomPayload(t, 100000)), 7.9)
+	assert.Less(t, entropy(telemetryPayload(100000)), 5.0)
+}
+
+func TestChoose(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Encodings = []string{encodingGzip, encodingZstd}
+	tu := newTestTuner(t, cfg)
+	payload := telemetryPayload(100000)
+
+	// zstd is faster and compresses better by default
+	assert.Equal(t, encodingZstd, tu.choose(payload))
+
+	// payloads that are small or incompressible aren't compressed
+	assert.Equal(t, encodingNone, tu.choose(payload[:100]))
+	assert.Equal(t, encodingNone, tu.choose(randomPayload(t, 100000)))
+
+	// on fast links the time to compress exceeds the time saved transferring
+	tu.throughput = 10 << 30
+	assert.Equal(t, encodingNone, tu.choose(payload))
+	tu.throughput = 100 << 20
+	assert.Equal(t, encodingZstd, tu.choose(payload))
+
+	// CPU bound collectors compress less
+	tu.headroom = 0.05
+	assert.Equal(t, encodingNone, tu.choose(payload))
+	tu.headroom = 0.3
+	tu.throughput = 1 << 20
+	assert.Equal(t, encodingZstd, tu.choose(payload))
+
+	// the measured compressions replace the defaults
+	tu.stats[encodingZstd] = encodingStats{ratio: 0.9, secondsPerByte: 1.0 / (200 << 20)}
+	assert.Equal(t, encodingGzip, tu.choose(payload))
+}
+
+func TestSampleCPU(t *testing.T) {
+	tu := newTestTuner(t, createDefaultConfig().(*Config))
+	now := time.Now()
+	tu.now = func() time.Time { return now }
+	tu.lastSample = time.Time{}
+	// the CPU time is updated by garbage collections
+	runtime.GC()
+	tu.sampleCPU()
+	assert.Equal(t, now, tu.lastSample)
+	assert.Positive(t, tu.lastTotal)
+	assert.GreaterOrEqual(t, tu.headroom, 0.0)
+	assert.LessOrEqual(t, tu.headroom, 1.0)
+
+	// the headroom isn't sampled again within the interval
+	tu.headroom = 0.5
+	tu.lastTotal = 0
+	tu.sampleCPU()
+	assert.Equal(t, 0.5, tu.headroom)
+}
+
+func TestCompress(t *testing.T) {
+	tu := newTestTuner(t, &Config{Encodings: []string{encodingGzip, encodingZstd}})
+	payload := telemetryPayload(100000)
+
+	compressed, err := tu.compress(encodingGzip, payload)
+	require.NoError(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decompressed)
+	assert.Less(t, tu.stats[encodingGzip].ratio, defaultStats[encodingGzip].ratio)
+
+	// pooled writers are reset
+	compressed, err = tu.compress(encodingGzip, payload[:50000])
+	require.NoError(t, err)
+	r, err = gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	decompressed, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, payload[:50000], decompressed)
+
+	compressed, err = tu.compress(encodingZstd, payload)
+	require.NoError(t, err)
+	d, err := zstd.NewReader(nil)
+	require.NoError(t, err)
+	defer d.Close()
+	decompressed, err = d.DecodeAll(compressed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decompressed)
+}
+
+func TestCompressingRoundTripper(t *testing.T) {
+	type received struct {
+		encoding string
+		body     []byte
+	}
+	var requests []received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == encodingGzip {
+			var err error
+			body, err = gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+		}
+		b, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		requests = append(requests, received{encoding: r.Header.Get("Content-Encoding"), body: b})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tu := newTestTuner(t, createDefaultConfig().(*Config))
+	client := &http.Client{Transport: &compressingRoundTripper{base: http.DefaultTransport, tuner: tu}}
+	payload := telemetryPayload(200000)
+	random := randomPayload(t, 100000)
+	for _, body := range [][]byte{payload, payload[:100], random} {
+		resp, err := client.Post(server.URL, "application/x-protobuf", bytes.NewReader(body))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	// requests compressed by the exporter are sent as is
+	req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte("compressed")))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "identity")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, []received{
+		{encoding: encodingGzip, body: payload},
+		{body: payload[:100]},
+		{body: random},
+		{encoding: "identity", body: []byte("compressed")},
+	}, requests)
+	assert.NotEqual(t, float64(defaultThroughput), tu.throughput)
+}
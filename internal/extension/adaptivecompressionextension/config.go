@@ -0,0 +1,72 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivecompressionextension
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+const (
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+)
+
+// Config defines the encodings requests may be compressed with and the thresholds the encoding
+// is chosen by.
+type Config struct {
+	// Encodings are the encodings the destination accepts, besides sending requests
+	// uncompressed: gzip and zstd.
+	Encodings []string `mapstructure:"encodings"`
+	// MinSize is the size in bytes under which requests are sent uncompressed.
+	MinSize int `mapstructure:"min_size"`
+	// MaxEntropy is the entropy in bits per byte of the payload above which requests are sent
+	// uncompressed, like already compressed or encrypted payloads.
+	MaxEntropy float64 `mapstructure:"max_entropy"`
+	// MinCPUHeadroom is the fraction of the CPU available to the collector that must be idle for
+	// requests to be compressed.
+	MinCPUHeadroom float64 `mapstructure:"min_cpu_headroom"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Encodings) == 0 {
+		errs = errors.New("encodings must not be empty")
+	}
+	seen := map[string]bool{}
+	for _, encoding := range cfg.Encodings {
+		switch {
+		case encoding != encodingGzip && encoding != encodingZstd:
+			errs = errors.Join(errs, fmt.Errorf("unsupported encoding %q, must be gzip or zstd", encoding))
+		case seen[encoding]:
+			errs = errors.Join(errs, fmt.Errorf("duplicate encoding %q", encoding))
+		}
+		seen[encoding] = true
+	}
+	if cfg.MinSize < 0 {
+		errs = errors.Join(errs, errors.New("min_size must not be negative"))
+	}
+	if cfg.MaxEntropy <= 0 || cfg.MaxEntropy > 8 {
+		errs = errors.Join(errs, errors.New("max_entropy must be greater than 0 and at most 8"))
+	}
+	if cfg.MinCPUHeadroom < 0 || cfg.MinCPUHeadroom >= 1 {
+		errs = errors.Join(errs, errors.New("min_cpu_headroom must be at least 0 and less than 1"))
+	}
+	return errs
+}
@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivecompressionextension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Encodings:      []string{"gzip"},
+				MinSize:        1024,
+				MaxEntropy:     7.5,
+				MinCPUHeadroom: 0.2,
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				Encodings:      []string{"zstd", "gzip"},
+				MinSize:        4096,
+				MaxEntropy:     7,
+				MinCPUHeadroom: 0.5,
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "unsupported encoding \"br\", must be gzip or zstd\nduplicate encoding \"gzip\"\n" +
+				"min_size must not be negative\nmax_entropy must be greater than 0 and at most 8\n" +
+				"min_cpu_headroom must be at least 0 and less than 1",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivecompressionextension
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/auth"
+)
+
+const typeStr = "adaptive_compression"
+
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		createExtension,
+		component.StabilityLevelDevelopment,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Encodings:      []string{encodingGzip},
+		MinSize:        1024,
+		MaxEntropy:     7.5,
+		MinCPUHeadroom: 0.2,
+	}
+}
+
+// createExtension creates a client authenticator that doesn't authenticate requests but
+// compresses them with the encoding sending them fastest.
+func createExtension(_ context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
+	tuner, err := newTuner(set.TelemetrySettings, set.ID, cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewClient(
+		auth.WithClientRoundTripper(func(base http.RoundTripper) (http.RoundTripper, error) {
+			return &compressingRoundTripper{base: base, tuner: tuner}, nil
+		}),
+	), nil
+}
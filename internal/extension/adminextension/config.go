@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminextension
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
+)
+
+const (
+	readAccess  = "read"
+	writeAccess = "write"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the admin endpoint and the tokens authorized to perform its operations.
+type Config struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+	// Tokens are the bearer tokens of the admin requests.
+	Tokens []Token `mapstructure:"tokens"`
+}
+
+// Token is a bearer token and the operations it's authorized to perform.
+type Token struct {
+	// Name identifies the token in the logs of the operations performed with it.
+	Name  string              `mapstructure:"name"`
+	Token configopaque.String `mapstructure:"token"`
+	// Permissions are "<operation>:read" or "<operation>:write" permissions, the write
+	// permission of an operation including its read permission.
+	Permissions []string `mapstructure:"permissions"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("endpoint must be specified"))
+	} else if !isLoopback(cfg.Endpoint) && (cfg.TLSSetting == nil || cfg.TLSSetting.ClientCAFile == "") {
+		errs = errors.Join(errs, errors.New("tls::client_ca_file must be specified to authenticate clients unless the endpoint is a loopback address"))
+	}
+	if len(cfg.Tokens) == 0 {
+		errs = errors.Join(errs, errors.New("at least one token must be specified"))
+	}
+	names := map[string]struct{}{}
+	tokens := map[configopaque.String]struct{}{}
+	for i, token := range cfg.Tokens {
+		if token.Name == "" {
+			errs = errors.Join(errs, fmt.Errorf("tokens[%d]: name must be specified", i))
+		} else if _, ok := names[token.Name]; ok {
+			errs = errors.Join(errs, fmt.Errorf("tokens[%d]: name %q is used more than once", i, token.Name))
+		}
+		names[token.Name] = struct{}{}
+		if token.Token == "" {
+			errs = errors.Join(errs, fmt.Errorf("tokens[%d]: token must be specified", i))
+		} else if _, ok := tokens[token.Token]; ok {
+			errs = errors.Join(errs, fmt.Errorf("tokens[%d]: token is used more than once", i))
+		}
+		tokens[token.Token] = struct{}{}
+		for _, permission := range token.Permissions {
+			if _, _, err := parsePermission(permission); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("tokens[%d]: %w", i, err))
+			}
+		}
+	}
+	return errs
+}
+
+func parsePermission(permission string) (operation, access string, err error) {
+	operation, access, _ = strings.Cut(permission, ":")
+	if !slices.Contains(admin.Operations, operation) || access != readAccess && access != writeAccess {
+		return "", "", fmt.Errorf("invalid permission %q: must be <operation>:read or <operation>:write, with an operation of %s",
+			permission, strings.Join(admin.Operations, ", "))
+	}
+	return operation, access, nil
+}
+
+func isLoopback(endpoint string) bool {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
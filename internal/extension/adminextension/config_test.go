@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminextension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id:          component.MustNewID(typeStr),
+			expectedErr: "at least one token must be specified",
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				ServerConfig: confighttp.ServerConfig{
+					Endpoint: "0.0.0.0:13136",
+					TLSSetting: &configtls.ServerConfig{
+						Config: configtls.Config{
+							CertFile: "/etc/otel/certs/admin.crt",
+							KeyFile:  "/etc/otel/certs/admin.key",
+						},
+						ClientCAFile: "/etc/otel/certs/ca.crt",
+					},
+				},
+				Tokens: []Token{
+					{Name: "ops", Token: "ops-token", Permissions: []string{"log_level:write", "feature_gates:read"}},
+					{Name: "security", Token: "security-token", Permissions: []string{"diagnostics:read"}},
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "tls::client_ca_file must be specified to authenticate clients unless the endpoint is a loopback address\n" +
+				"tokens[0]: name must be specified\n" +
+				`tokens[0]: invalid permission "log_level:admin": must be <operation>:read or <operation>:write, ` +
+				"with an operation of brownout, diagnostics, feature_gates, log_level, pipelines\n" +
+				"tokens[1]: token is used more than once\n" +
+				`tokens[2]: name "ops" is used more than once` + "\n" +
+				"tokens[2]: token must be specified",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminextension
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
+)
+
+var _ extension.Extension = (*adminExtension)(nil)
+
+// token is a configured token, hashed so that tokens of any length are compared in constant time.
+type token struct {
+	// permissions are the access to each operation, read or write.
+	permissions map[string]string
+	name        string
+	hash        [sha256.Size]byte
+}
+
+func (t *token) allows(operation, access string) bool {
+	granted, ok := t.permissions[operation]
+	return ok && (granted == writeAccess || access == readAccess)
+}
+
+// adminExtension serves the admin endpoints registered by the collector and provided by its
+// extensions, authorizing each request by the permissions of its bearer token.
+type adminExtension struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	server    *http.Server
+	tokens    []token
+	wg        sync.WaitGroup
+}
+
+func newAdminExtension(config *Config, telemetry component.TelemetrySettings) *adminExtension {
+	tokens := make([]token, len(config.Tokens))
+	for i, t := range config.Tokens {
+		tokens[i] = token{name: t.Name, hash: sha256.Sum256([]byte(t.Token)), permissions: map[string]string{}}
+		for _, permission := range t.Permissions {
+			operation, access, _ := parsePermission(permission)
+			if tokens[i].permissions[operation] != writeAccess {
+				tokens[i].permissions[operation] = access
+			}
+		}
+	}
+	return &adminExtension{config: config, telemetry: telemetry, tokens: tokens}
+}
+
+func (e *adminExtension) Start(ctx context.Context, host component.Host) error {
+	routes := admin.Registered()
+	extensions := host.GetExtensions()
+	ids := make([]component.ID, 0, len(extensions))
+	for id := range extensions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	for _, id := range ids {
+		if provider, ok := extensions[id].(admin.Provider); ok {
+			routes = append(routes, provider.AdminRoutes()...)
+		}
+	}
+	handler, err := e.handler(routes)
+	if err != nil {
+		return err
+	}
+
+	var listener net.Listener
+	if listener, err = e.config.ServerConfig.ToListener(ctx); err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", e.config.Endpoint, err)
+	}
+	if e.server, err = e.config.ServerConfig.ToServer(ctx, host, e.telemetry, handler); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if serveErr := e.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (e *adminExtension) Shutdown(context.Context) error {
+	var err error
+	if e.server != nil {
+		err = e.server.Close()
+	}
+	e.wg.Wait()
+	return err
+}
+
+// handler returns the handler serving the routes, each authorized by its operation.
+func (e *adminExtension) handler(routes []admin.Route) (http.Handler, error) {
+	mux := http.NewServeMux()
+	seen := map[string]struct{}{}
+	for _, route := range routes {
+		if _, ok := seen[route.Pattern]; ok {
+			return nil, fmt.Errorf("admin endpoint %q is provided more than once", route.Pattern)
+		}
+		seen[route.Pattern] = struct{}{}
+		mux.Handle(route.Pattern, e.authorize(route))
+	}
+	return mux, nil
+}
+
+// authorize serves the requests whose bearer token has the permission of the route's operation,
+// the write permission for requests other than GET and HEAD, and logs the write operations.
+func (e *adminExtension) authorize(route admin.Route) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := e.authenticate(r)
+		if t == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+			return
+		}
+		access := writeAccess
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			access = readAccess
+		}
+		if !t.allows(route.Operation, access) {
+			http.Error(w, fmt.Sprintf("the token doesn't have the %s:%s permission", route.Operation, access), http.StatusForbidden)
+			return
+		}
+		if access == writeAccess {
+			fields := []zap.Field{
+				zap.String("token", t.name),
+				zap.String("operation", route.Operation),
+				zap.String("method", r.Method),
+				zap.String("url", r.URL.String()),
+			}
+			if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+				fields = append(fields, zap.String("client", r.TLS.PeerCertificates[0].Subject.String()))
+			}
+			e.telemetry.Logger.Info("Admin operation requested", fields...)
+		}
+		route.Handler.ServeHTTP(w, r)
+	})
+}
+
+// authenticate returns the token of the request's bearer token, or nil if it's not configured.
+func (e *adminExtension) authenticate(r *http.Request) *token {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil
+	}
+	hash := sha256.Sum256([]byte(value))
+	var found *token
+	for i := range e.tokens {
+		// every token is compared, so the time taken doesn't reveal which one matched
+		if subtle.ConstantTimeCompare(hash[:], e.tokens[i].hash[:]) == 1 {
+			found = &e.tokens[i]
+		}
+	}
+	return found
+}
@@ -0,0 +1,88 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminextension
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
+)
+
+func TestAuthorization(t *testing.T) {
+	cfg := &Config{Tokens: []Token{
+		{Name: "ops", Token: "ops-token", Permissions: []string{"log_level:read", "log_level:write", "brownout:read"}},
+		{Name: "security", Token: "security-token", Permissions: []string{"diagnostics:read"}},
+	}}
+	e := newAdminExtension(cfg, componenttest.NewNopTelemetrySettings())
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler, err := e.handler([]admin.Route{
+		{Pattern: "/debug/loglevel", Operation: admin.OperationLogLevel, Handler: ok},
+		{Pattern: "/brownout", Operation: admin.OperationBrownout, Handler: ok},
+		{Pattern: "/debug/configz/effective", Operation: admin.OperationDiagnostics, Handler: ok},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		expectedCode  int
+	}{
+		{name: "without token", method: http.MethodGet, path: "/debug/loglevel", expectedCode: http.StatusUnauthorized},
+		{name: "unknown token", method: http.MethodGet, path: "/debug/loglevel", authorization: "Bearer other", expectedCode: http.StatusUnauthorized},
+		{name: "basic auth", method: http.MethodGet, path: "/debug/loglevel", authorization: "Basic ops-token", expectedCode: http.StatusUnauthorized},
+		{name: "read", method: http.MethodGet, path: "/debug/loglevel", authorization: "Bearer ops-token", expectedCode: http.StatusOK},
+		{name: "write", method: http.MethodPut, path: "/debug/loglevel?level=debug", authorization: "Bearer ops-token", expectedCode: http.StatusOK},
+		{name: "read only", method: http.MethodPost, path: "/brownout", authorization: "bearer ops-token", expectedCode: http.StatusForbidden},
+		{name: "other operation", method: http.MethodGet, path: "/debug/configz/effective", authorization: "Bearer ops-token", expectedCode: http.StatusForbidden},
+		{name: "other token", method: http.MethodGet, path: "/debug/configz/effective", authorization: "Bearer security-token", expectedCode: http.StatusOK},
+		{name: "write without permission", method: http.MethodPut, path: "/debug/loglevel", authorization: "Bearer security-token", expectedCode: http.StatusForbidden},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestWriteIncludesRead(t *testing.T) {
+	e := newAdminExtension(&Config{Tokens: []Token{
+		{Name: "ops", Token: "ops-token", Permissions: []string{"feature_gates:write", "feature_gates:read"}},
+	}}, componenttest.NewNopTelemetrySettings())
+	assert.True(t, e.tokens[0].allows(admin.OperationFeatureGates, readAccess))
+	assert.True(t, e.tokens[0].allows(admin.OperationFeatureGates, writeAccess))
+	assert.False(t, e.tokens[0].allows(admin.OperationLogLevel, readAccess))
+}
+
+func TestDuplicateRoutes(t *testing.T) {
+	e := newAdminExtension(&Config{}, componenttest.NewNopTelemetrySettings())
+	route := admin.Route{Pattern: "/brownout", Operation: admin.OperationBrownout, Handler: http.NotFoundHandler()}
+	_, err := e.handler([]admin.Route{route, route})
+	require.EqualError(t, err, `admin endpoint "/brownout" is provided more than once`)
+}
@@ -0,0 +1,46 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brownoutextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the admin endpoint and how the brownout level is raised while draining.
+type Config struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+	// MaxLevel is the highest brownout level, at which the brownout processors of all
+	// pipelines should reject data.
+	MaxLevel int `mapstructure:"max_level"`
+	// StepInterval is how often the brownout level is raised by one while draining.
+	StepInterval time.Duration `mapstructure:"step_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.MaxLevel <= 0 {
+		errs = errors.Join(errs, errors.New("max_level must be positive"))
+	}
+	if cfg.StepInterval <= 0 {
+		errs = errors.Join(errs, errors.New("step_interval must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,246 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brownoutextension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
+)
+
+const (
+	levelPath = "/brownout"
+	drainPath = "/brownout/drain"
+)
+
+var (
+	_ Brownout       = (*brownoutExtension)(nil)
+	_ admin.Provider = (*brownoutExtension)(nil)
+)
+
+// Brownout provides the current brownout level to the brownout processors.
+type Brownout interface {
+	extension.Extension
+	// Level returns the current brownout level, where 0 is normal operation.
+	Level() int
+}
+
+// State is the document served by the admin endpoint.
+type State struct {
+	Level    int  `json:"level"`
+	MaxLevel int  `json:"max_level"`
+	Draining bool `json:"draining"`
+}
+
+// brownoutExtension holds the brownout level of the collector, which is set through its
+// admin endpoint. Draining raises the level by one every step_interval until max_level
+// so senders are shed progressively rather than all at once.
+type brownoutExtension struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	server    *http.Server
+	stopDrain chan struct{}
+	level     atomic.Int64
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	// shutdown is set once Shutdown is called, after which draining can't start.
+	shutdown bool
+}
+
+func newBrownoutExtension(config *Config, telemetry component.TelemetrySettings) *brownoutExtension {
+	return &brownoutExtension{config: config, telemetry: telemetry}
+}
+
+func (b *brownoutExtension) Start(ctx context.Context, host component.Host) error {
+	if b.config.Endpoint == "" {
+		// only served by the admin extension
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(levelPath, b.handleLevel)
+	mux.HandleFunc(drainPath, b.handleDrain)
+
+	var listener net.Listener
+	var err error
+	if listener, err = b.config.ServerConfig.ToListener(ctx); err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", b.config.Endpoint, err)
+	}
+	if b.server, err = b.config.ServerConfig.ToServer(ctx, host, b.telemetry, mux); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		if serveErr := b.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (b *brownoutExtension) Shutdown(context.Context) error {
+	var err error
+	if b.server != nil {
+		err = b.server.Close()
+	}
+	b.mu.Lock()
+	b.shutdown = true
+	b.stopDraining()
+	b.mu.Unlock()
+	b.wg.Wait()
+	return err
+}
+
+// AdminRoutes returns the endpoints served by the admin extension.
+func (b *brownoutExtension) AdminRoutes() []admin.Route {
+	return []admin.Route{
+		{Pattern: levelPath, Operation: admin.OperationBrownout, Handler: http.HandlerFunc(b.handleLevel)},
+		{Pattern: drainPath, Operation: admin.OperationBrownout, Handler: http.HandlerFunc(b.handleDrain)},
+	}
+}
+
+func (b *brownoutExtension) Level() int {
+	return int(b.level.Load())
+}
+
+// SetLevel sets the brownout level and stops draining.
+func (b *brownoutExtension) SetLevel(level int) error {
+	if level < 0 || level > b.config.MaxLevel {
+		return fmt.Errorf("level must be between 0 and %d", b.config.MaxLevel)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopDraining()
+	b.setLevel(level)
+	return nil
+}
+
+// Drain raises the brownout level by one every step_interval until max_level. It does
+// nothing once the extension is shut down.
+func (b *brownoutExtension) Drain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.shutdown || b.stopDrain != nil || b.Level() >= b.config.MaxLevel {
+		return
+	}
+	stop := make(chan struct{})
+	b.stopDrain = stop
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(b.config.StepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			b.mu.Lock()
+			// draining may have been stopped while waiting for the lock
+			if b.stopDrain != stop {
+				b.mu.Unlock()
+				return
+			}
+			b.setLevel(b.Level() + 1)
+			done := b.Level() >= b.config.MaxLevel
+			if done {
+				b.stopDrain = nil
+			}
+			b.mu.Unlock()
+			if done {
+				return
+			}
+		}
+	}()
+}
+
+func (b *brownoutExtension) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return State{
+		Level:    b.Level(),
+		MaxLevel: b.config.MaxLevel,
+		Draining: b.stopDrain != nil,
+	}
+}
+
+// setLevel must be called with the lock held.
+func (b *brownoutExtension) setLevel(level int) {
+	if previous := int(b.level.Swap(int64(level))); previous != level {
+		b.telemetry.Logger.Info("Brownout level changed", zap.Int("previous", previous), zap.Int("level", level))
+	}
+}
+
+// stopDraining must be called with the lock held.
+func (b *brownoutExtension) stopDraining() {
+	if b.stopDrain != nil {
+		close(b.stopDrain)
+		b.stopDrain = nil
+	}
+}
+
+// handleLevel serves the brownout state on GET, sets the level from the "level" query
+// parameter on PUT and ends the brownout on DELETE.
+func (b *brownoutExtension) handleLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		level, err := strconv.Atoi(r.URL.Query().Get("level"))
+		if err == nil {
+			err = b.SetLevel(level)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid level: %v", err), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		_ = b.SetLevel(0)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	b.writeState(w)
+}
+
+// handleDrain starts draining on POST.
+func (b *brownoutExtension) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	b.Drain()
+	b.writeState(w)
+}
+
+func (b *brownoutExtension) writeState(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(b.State())
+}
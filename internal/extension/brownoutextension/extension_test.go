@@ -0,0 +1,136 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brownoutextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func newTestExtension(t *testing.T, stepInterval time.Duration) *brownoutExtension {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StepInterval = stepInterval
+	b := newBrownoutExtension(cfg, componenttest.NewNopTelemetrySettings())
+	t.Cleanup(func() { require.NoError(t, b.Shutdown(context.Background())) })
+	return b
+}
+
+func TestAdminEndpoint(t *testing.T) {
+	b := newTestExtension(t, time.Hour)
+
+	for _, tt := range []struct {
+		name          string
+		method        string
+		path          string
+		expectedState State
+		expectedCode  int
+	}{
+		{
+			name:          "initial state",
+			method:        http.MethodGet,
+			path:          "/brownout",
+			expectedCode:  http.StatusOK,
+			expectedState: State{MaxLevel: 3},
+		},
+		{
+			name:          "set level",
+			method:        http.MethodPut,
+			path:          "/brownout?level=2",
+			expectedCode:  http.StatusOK,
+			expectedState: State{Level: 2, MaxLevel: 3},
+		},
+		{name: "level above max", method: http.MethodPut, path: "/brownout?level=4", expectedCode: http.StatusBadRequest},
+		{name: "invalid level", method: http.MethodPut, path: "/brownout?level=high", expectedCode: http.StatusBadRequest},
+		{
+			name:          "drain",
+			method:        http.MethodPost,
+			path:          "/brownout/drain",
+			expectedCode:  http.StatusOK,
+			expectedState: State{Level: 2, MaxLevel: 3, Draining: true},
+		},
+		{
+			name:          "end brownout",
+			method:        http.MethodDelete,
+			path:          "/brownout",
+			expectedCode:  http.StatusOK,
+			expectedState: State{MaxLevel: 3},
+		},
+		{name: "invalid method", method: http.MethodPost, path: "/brownout", expectedCode: http.StatusMethodNotAllowed},
+		{name: "invalid drain method", method: http.MethodGet, path: "/brownout/drain", expectedCode: http.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if req.URL.Path == drainPath {
+				b.handleDrain(rec, req)
+			} else {
+				b.handleLevel(rec, req)
+			}
+			require.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var state State
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+			assert.Equal(t, tt.expectedState, state)
+			assert.Equal(t, tt.expectedState.Level, b.Level())
+		})
+	}
+}
+
+func TestDrain(t *testing.T) {
+	b := newTestExtension(t, 10*time.Millisecond)
+
+	b.Drain()
+	require.Eventually(t, func() bool {
+		return b.State() == State{Level: 3, MaxLevel: 3}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// setting the level stops draining
+	require.NoError(t, b.SetLevel(1))
+	b.Drain()
+	require.NoError(t, b.SetLevel(0))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, State{MaxLevel: 3}, b.State())
+}
+
+func TestDrainAfterShutdown(t *testing.T) {
+	b := newTestExtension(t, 10*time.Millisecond)
+	require.NoError(t, b.Shutdown(context.Background()))
+
+	b.Drain()
+	assert.Equal(t, State{MaxLevel: 3}, b.State())
+}
+
+func TestAdminRoutes(t *testing.T) {
+	b := newTestExtension(t, time.Hour)
+
+	routes := b.AdminRoutes()
+	require.Len(t, routes, 2)
+	assert.Equal(t, levelPath, routes[0].Pattern)
+	assert.Equal(t, drainPath, routes[1].Pattern)
+	rec := httptest.NewRecorder()
+	routes[0].Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/brownout?level=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, b.Level())
+}
@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerdobserver
+
+import (
+	"context"
+	"fmt"
+
+	containersapi "github.com/containerd/containerd/api/services/containers/v1"
+	tasksapi "github.com/containerd/containerd/api/services/tasks/v1"
+	"github.com/containerd/containerd/api/types/task"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// namespaceHeader is the grpc metadata key selecting the containerd namespace of a request.
+const namespaceHeader = "containerd-namespace"
+
+// container is a containerd container and whether its task is running.
+type container struct {
+	labels  map[string]string
+	id      string
+	image   string
+	running bool
+}
+
+// containerdClient lists the containers of a containerd namespace.
+type containerdClient interface {
+	containers(ctx context.Context, namespace string) ([]container, error)
+	close() error
+}
+
+var _ containerdClient = (*grpcClient)(nil)
+
+// grpcClient is a containerdClient using the containerd grpc API.
+type grpcClient struct {
+	conn       *grpc.ClientConn
+	containerd containersapi.ContainersClient
+	tasks      tasksapi.TasksClient
+}
+
+func newGRPCClient(endpoint string) (containerdClient, error) {
+	conn, err := grpc.NewClient("unix://"+endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed creating containerd client: %w", err)
+	}
+	return &grpcClient{
+		conn:       conn,
+		containerd: containersapi.NewContainersClient(conn),
+		tasks:      tasksapi.NewTasksClient(conn),
+	}, nil
+}
+
+func (c *grpcClient) containers(ctx context.Context, namespace string) ([]container, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, namespaceHeader, namespace)
+	containersResp, err := c.containerd.List(ctx, &containersapi.ListContainersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing containers: %w", err)
+	}
+	tasksResp, err := c.tasks.List(ctx, &tasksapi.ListTasksRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed listing tasks: %w", err)
+	}
+	// a container's task has the container's id
+	running := map[string]bool{}
+	for _, t := range tasksResp.Tasks {
+		running[t.ID] = t.Status == task.Status_RUNNING
+	}
+	containers := make([]container, 0, len(containersResp.Containers))
+	for _, ctr := range containersResp.Containers {
+		containers = append(containers, container{
+			id:      ctr.ID,
+			image:   ctr.Image,
+			labels:  ctr.Labels,
+			running: running[ctr.ID],
+		})
+	}
+	return containers, nil
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
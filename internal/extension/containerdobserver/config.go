@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerdobserver
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the containerd socket and namespaces to observe.
+type Config struct {
+	// Endpoint is the path of the containerd socket.
+	Endpoint string `mapstructure:"endpoint"`
+	// Namespaces are the containerd namespaces whose containers are observed.
+	Namespaces []string `mapstructure:"namespaces"`
+	// RefreshInterval is how often the containers are listed.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	// Timeout is the timeout of each containerd API request.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("endpoint must be specified"))
+	}
+	if len(cfg.Namespaces) == 0 {
+		errs = errors.Join(errs, errors.New("namespaces must not be empty"))
+	}
+	if cfg.RefreshInterval <= 0 {
+		errs = errors.Join(errs, errors.New("refresh_interval must be positive"))
+	}
+	if cfg.Timeout <= 0 {
+		errs = errors.Join(errs, errors.New("timeout must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,177 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerdobserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+)
+
+// Labels nerdctl sets on the containers it runs.
+const (
+	nerdctlNameLabel  = "nerdctl/name"
+	nerdctlPortsLabel = "nerdctl/ports"
+)
+
+var (
+	_ extension.Extension = (*containerdObserver)(nil)
+	_ observer.Observable = (*containerdObserver)(nil)
+)
+
+// portMapping is a published port of a nerdctl/ports container label.
+type portMapping struct {
+	Protocol      string `json:"Protocol"`
+	HostIP        string `json:"HostIP"`
+	HostPort      int32  `json:"HostPort"`
+	ContainerPort int32  `json:"ContainerPort"`
+}
+
+// containerdObserver reports an endpoint for each published port of the running containers of the
+// configured containerd namespaces. Containers without published ports aren't reachable from the host
+// and aren't reported. The endpoints' details have the same form as the docker_observer's with
+// use_host_bindings enabled, so discovery rules for docker containers apply to them as well.
+type containerdObserver struct {
+	*observer.EndpointsWatcher
+	client    containerdClient
+	newClient func(endpoint string) (containerdClient, error)
+	config    *Config
+	logger    *zap.Logger
+	mu        sync.Mutex
+}
+
+func newObserver(config *Config, set extension.Settings) *containerdObserver {
+	o := &containerdObserver{
+		config:    config,
+		logger:    set.Logger,
+		newClient: newGRPCClient,
+	}
+	o.EndpointsWatcher = observer.NewEndpointsWatcher(o, config.RefreshInterval, set.Logger)
+	return o
+}
+
+func (o *containerdObserver) Start(context.Context, component.Host) error {
+	client, err := o.newClient(o.config.Endpoint)
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	o.client = client
+	o.mu.Unlock()
+	return nil
+}
+
+func (o *containerdObserver) Shutdown(context.Context) error {
+	o.StopListAndWatch()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.client == nil {
+		return nil
+	}
+	err := o.client.close()
+	o.client = nil
+	return err
+}
+
+// ListEndpoints lists the endpoints of all running containers of the configured namespaces.
+func (o *containerdObserver) ListEndpoints() []observer.Endpoint {
+	o.mu.Lock()
+	client := o.client
+	o.mu.Unlock()
+	if client == nil {
+		return nil
+	}
+	var endpoints []observer.Endpoint
+	for _, namespace := range o.config.Namespaces {
+		ctx, cancel := context.WithTimeout(context.Background(), o.config.Timeout)
+		containers, err := client.containers(ctx, namespace)
+		cancel()
+		if err != nil {
+			o.logger.Warn("failed listing containerd containers", zap.String("namespace", namespace), zap.Error(err))
+			continue
+		}
+		for _, ctr := range containers {
+			if ctr.running {
+				endpoints = append(endpoints, o.containerEndpoints(namespace, ctr)...)
+			}
+		}
+	}
+	return endpoints
+}
+
+func (o *containerdObserver) containerEndpoints(namespace string, ctr container) []observer.Endpoint {
+	portsLabel, ok := ctr.labels[nerdctlPortsLabel]
+	if !ok {
+		return nil
+	}
+	var ports []portMapping
+	if err := json.Unmarshal([]byte(portsLabel), &ports); err != nil {
+		o.logger.Debug("invalid container ports label", zap.String("container", ctr.id), zap.Error(err))
+		return nil
+	}
+	name := ctr.labels[nerdctlNameLabel]
+	if name == "" {
+		name = ctr.id
+	}
+	image, tag := splitImage(ctr.image)
+	endpoints := make([]observer.Endpoint, 0, len(ports))
+	for _, port := range ports {
+		host := port.HostIP
+		switch host {
+		case "", "0.0.0.0":
+			host = "127.0.0.1"
+		case "::":
+			host = "::1"
+		}
+		transport := observer.ProtocolTCP
+		if strings.EqualFold(port.Protocol, "udp") {
+			transport = observer.ProtocolUDP
+		}
+		endpoints = append(endpoints, observer.Endpoint{
+			ID:     observer.EndpointID(fmt.Sprintf("%s/%s:%d", namespace, ctr.id, port.HostPort)),
+			Target: net.JoinHostPort(host, strconv.Itoa(int(port.HostPort))),
+			Details: &observer.Container{
+				Name:          name,
+				Image:         image,
+				Tag:           tag,
+				Port:          uint16(port.HostPort),      //nolint:gosec
+				AlternatePort: uint16(port.ContainerPort), //nolint:gosec
+				ContainerID:   ctr.id,
+				Host:          host,
+				Transport:     transport,
+				Labels:        ctr.labels,
+			},
+		})
+	}
+	return endpoints
+}
+
+// splitImage splits an image reference into its repository and tag, ignoring any digest.
+func splitImage(ref string) (string, string) {
+	ref, _, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
@@ -0,0 +1,140 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerdobserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+)
+
+type fakeClient struct {
+	namespaces map[string][]container
+	closed     bool
+}
+
+func (c *fakeClient) containers(_ context.Context, namespace string) ([]container, error) {
+	containers, ok := c.namespaces[namespace]
+	if !ok {
+		return nil, errors.New("namespace not found")
+	}
+	return containers, nil
+}
+
+func (c *fakeClient) close() error {
+	c.closed = true
+	return nil
+}
+
+func TestListEndpoints(t *testing.T) {
+	redisLabels := map[string]string{
+		nerdctlNameLabel:  "redis",
+		nerdctlPortsLabel: `[{"HostPort":6380,"ContainerPort":6379,"Protocol":"tcp","HostIP":"0.0.0.0"},{"HostPort":5353,"ContainerPort":53,"Protocol":"udp","HostIP":"10.0.0.1"}]`,
+	}
+	client := &fakeClient{namespaces: map[string][]container{
+		"default": {
+			{id: "abc", image: "docker.io/library/redis:7.2", labels: redisLabels, running: true},
+			{id: "stopped", image: "docker.io/library/redis:7.2", labels: redisLabels},
+			{id: "unpublished", image: "docker.io/library/nginx", labels: map[string]string{nerdctlNameLabel: "nginx"}, running: true},
+			{id: "invalid", image: "docker.io/library/nginx", labels: map[string]string{nerdctlPortsLabel: "{"}, running: true},
+		},
+		"buildkit": {
+			{id: "def", image: "localhost:5000/app@sha256:0123", labels: map[string]string{nerdctlPortsLabel: `[{"HostPort":8080,"ContainerPort":80,"Protocol":"tcp"}]`}, running: true},
+		},
+	}}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Namespaces = []string{"default", "missing", "buildkit"}
+	o := newObserver(cfg, extensiontest.NewNopSettings())
+	o.newClient = func(endpoint string) (containerdClient, error) {
+		assert.Equal(t, "/run/containerd/containerd.sock", endpoint)
+		return client, nil
+	}
+	assert.Empty(t, o.ListEndpoints())
+	require.NoError(t, o.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.Equal(t, []observer.Endpoint{
+		{
+			ID:     "default/abc:6380",
+			Target: "127.0.0.1:6380",
+			Details: &observer.Container{
+				Name:          "redis",
+				Image:         "docker.io/library/redis",
+				Tag:           "7.2",
+				Port:          6380,
+				AlternatePort: 6379,
+				ContainerID:   "abc",
+				Host:          "127.0.0.1",
+				Transport:     observer.ProtocolTCP,
+				Labels:        redisLabels,
+			},
+		},
+		{
+			ID:     "default/abc:5353",
+			Target: "10.0.0.1:5353",
+			Details: &observer.Container{
+				Name:          "redis",
+				Image:         "docker.io/library/redis",
+				Tag:           "7.2",
+				Port:          5353,
+				AlternatePort: 53,
+				ContainerID:   "abc",
+				Host:          "10.0.0.1",
+				Transport:     observer.ProtocolUDP,
+				Labels:        redisLabels,
+			},
+		},
+		{
+			ID:     "buildkit/def:8080",
+			Target: "127.0.0.1:8080",
+			Details: &observer.Container{
+				Name:          "def",
+				Image:         "localhost:5000/app",
+				Tag:           "latest",
+				Port:          8080,
+				AlternatePort: 80,
+				ContainerID:   "def",
+				Host:          "127.0.0.1",
+				Transport:     observer.ProtocolTCP,
+				Labels:        map[string]string{nerdctlPortsLabel: `[{"HostPort":8080,"ContainerPort":80,"Protocol":"tcp"}]`},
+			},
+		},
+	}, o.ListEndpoints())
+
+	require.NoError(t, o.Shutdown(context.Background()))
+	assert.True(t, client.closed)
+	assert.Empty(t, o.ListEndpoints())
+}
+
+func TestSplitImage(t *testing.T) {
+	for _, tt := range []struct {
+		ref, image, tag string
+	}{
+		{ref: "redis", image: "redis", tag: "latest"},
+		{ref: "redis:7", image: "redis", tag: "7"},
+		{ref: "localhost:5000/redis", image: "localhost:5000/redis", tag: "latest"},
+		{ref: "localhost:5000/redis:7@sha256:0123", image: "localhost:5000/redis", tag: "7"},
+	} {
+		image, tag := splitImage(tt.ref)
+		assert.Equal(t, tt.image, image, tt.ref)
+		assert.Equal(t, tt.tag, tag, tt.ref)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egressextension
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/egress"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the hosts the collector's HTTP and gRPC clients may connect to.
+type Config struct {
+	// AllowedHosts are host names, `*.` prefixed domains allowing all their subdomains,
+	// IP addresses and CIDR ranges.
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.AllowedHosts) == 0 {
+		return errors.New("allowed_hosts must not be empty")
+	}
+	_, err := egress.NewAllowlist(cfg.AllowedHosts)
+	return err
+}
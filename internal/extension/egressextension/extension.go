@@ -0,0 +1,56 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egressextension
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+
+	"github.com/signalfx/splunk-otel-collector/internal/egress"
+)
+
+var _ extension.Extension = (*egressExtension)(nil)
+
+// enforce is overridden in tests.
+var enforce = egress.Enforce
+
+// egressExtension restricts the HTTP and gRPC clients of the components to the allowed hosts
+// while it runs. Extensions are started before any other component, so the clients the
+// components create when started are restricted.
+type egressExtension struct {
+	config *Config
+}
+
+func newEgressExtension(config *Config) *egressExtension {
+	return &egressExtension{config: config}
+}
+
+func (e *egressExtension) Start(context.Context, component.Host) error {
+	allowlist, err := egress.NewAllowlist(e.config.AllowedHosts)
+	if err != nil {
+		return err
+	}
+	enforce(allowlist)
+	return nil
+}
+
+// Shutdown lifts the restriction, so the allowlist of a reloaded config replaces it rather
+// than the previous one outliving its config.
+func (e *egressExtension) Shutdown(context.Context) error {
+	enforce(nil)
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egressextension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/egress"
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+	assert.Equal(t, typeStr, f.Type().String())
+	require.NoError(t, componenttest.CheckConfigStruct(f.CreateDefaultConfig()))
+}
+
+func TestEnforcedWhileRunning(t *testing.T) {
+	var enforced []*egress.Allowlist
+	enforce = func(a *egress.Allowlist) { enforced = append(enforced, a) }
+	t.Cleanup(func() { enforce = egress.Enforce })
+
+	cfg := &Config{AllowedHosts: []string{"*.us0.signalfx.com"}}
+	ext, err := NewFactory().Create(context.Background(), extensiontest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	require.Len(t, enforced, 1)
+	assert.True(t, enforced[0].Allows("ingest.us0.signalfx.com"))
+	assert.False(t, enforced[0].Allows("ingest.us1.signalfx.com"))
+
+	// the restriction is lifted, e.g. for the allowlist of a reloaded config
+	require.NoError(t, ext.Shutdown(context.Background()))
+	require.Len(t, enforced, 2)
+	assert.Nil(t, enforced[1])
+}
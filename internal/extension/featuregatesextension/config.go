@@ -0,0 +1,50 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuregatesextension
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the admin endpoint and the feature gates it may toggle.
+type Config struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+	// RuntimeSafe are the ids of the feature gates that are safe to toggle while the
+	// collector runs, i.e. whose state is checked on use rather than at startup. Other
+	// feature gates are listed but can't be toggled.
+	RuntimeSafe []string `mapstructure:"runtime_safe"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	seen := map[string]struct{}{}
+	for _, id := range cfg.RuntimeSafe {
+		if id == "" {
+			errs = errors.Join(errs, errors.New("runtime_safe must not contain empty feature gate ids"))
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			errs = errors.Join(errs, fmt.Errorf("runtime_safe contains %q more than once", id))
+		}
+		seen[id] = struct{}{}
+	}
+	return errs
+}
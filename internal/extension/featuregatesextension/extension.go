@@ -0,0 +1,328 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuregatesextension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
+)
+
+const gatesPath = "/featuregates"
+
+var _ admin.Provider = (*featureGatesExtension)(nil)
+
+var (
+	errUnknownGate    = errors.New("unknown feature gate")
+	errNotRuntimeSafe = errors.New("feature gate is not runtime safe")
+)
+
+// Gate is a feature gate as served by the admin endpoint.
+type Gate struct {
+	// ExpiresAt is when a temporary override of the gate is reverted.
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ID           string     `json:"id"`
+	Description  string     `json:"description,omitempty"`
+	Stage        string     `json:"stage"`
+	ReferenceURL string     `json:"reference_url,omitempty"`
+	Enabled      bool       `json:"enabled"`
+	RuntimeSafe  bool       `json:"runtime_safe"`
+	Overridden   bool       `json:"overridden"`
+}
+
+// override is a feature gate toggled through the admin endpoint.
+type override struct {
+	timer     *time.Timer
+	expiresAt time.Time
+	original  bool
+}
+
+// featureGatesExtension serves the collector's feature gates and toggles the runtime safe
+// ones, optionally for a limited duration. Overrides are reverted on shutdown.
+type featureGatesExtension struct {
+	config      *Config
+	telemetry   component.TelemetrySettings
+	registry    *featuregate.Registry
+	server      *http.Server
+	runtimeSafe map[string]struct{}
+	overrides   map[string]*override
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+}
+
+func newFeatureGatesExtension(config *Config, telemetry component.TelemetrySettings, registry *featuregate.Registry) *featureGatesExtension {
+	runtimeSafe := map[string]struct{}{}
+	for _, id := range config.RuntimeSafe {
+		runtimeSafe[id] = struct{}{}
+	}
+	return &featureGatesExtension{
+		config:      config,
+		telemetry:   telemetry,
+		registry:    registry,
+		runtimeSafe: runtimeSafe,
+		overrides:   map[string]*override{},
+	}
+}
+
+func (f *featureGatesExtension) Start(ctx context.Context, host component.Host) error {
+	for _, id := range f.config.RuntimeSafe {
+		if _, ok := f.gate(id); !ok {
+			return fmt.Errorf("runtime_safe feature gate %q is not registered", id)
+		}
+	}
+
+	if f.config.Endpoint == "" {
+		// only served by the admin extension
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(gatesPath, f.handleGates)
+	mux.HandleFunc(gatesPath+"/", f.handleGate)
+
+	var listener net.Listener
+	var err error
+	if listener, err = f.config.ServerConfig.ToListener(ctx); err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", f.config.Endpoint, err)
+	}
+	if f.server, err = f.config.ServerConfig.ToServer(ctx, host, f.telemetry, mux); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		if serveErr := f.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (f *featureGatesExtension) Shutdown(context.Context) error {
+	var err error
+	if f.server != nil {
+		err = f.server.Close()
+	}
+	f.wg.Wait()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id := range f.overrides {
+		f.revert(id)
+	}
+	return err
+}
+
+// AdminRoutes returns the endpoints served by the admin extension.
+func (f *featureGatesExtension) AdminRoutes() []admin.Route {
+	return []admin.Route{
+		{Pattern: gatesPath, Operation: admin.OperationFeatureGates, Handler: http.HandlerFunc(f.handleGates)},
+		{Pattern: gatesPath + "/", Operation: admin.OperationFeatureGates, Handler: http.HandlerFunc(f.handleGate)},
+	}
+}
+
+// Gates returns the registered feature gates ordered by id.
+func (f *featureGatesExtension) Gates() []Gate {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var gates []Gate
+	f.registry.VisitAll(func(g *featuregate.Gate) {
+		gates = append(gates, f.toGate(g))
+	})
+	sort.Slice(gates, func(i, j int) bool { return gates[i].ID < gates[j].ID })
+	return gates
+}
+
+// Set toggles a runtime safe feature gate. A positive duration reverts the gate to its
+// state before it was first overridden once elapsed.
+func (f *featureGatesExtension) Set(id string, enabled bool, duration time.Duration) (Gate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g, ok := f.gate(id)
+	if !ok {
+		return Gate{}, errUnknownGate
+	}
+	if _, safe := f.runtimeSafe[id]; !safe {
+		return Gate{}, errNotRuntimeSafe
+	}
+
+	o, overridden := f.overrides[id]
+	if !overridden {
+		o = &override{original: g.IsEnabled()}
+	}
+	if err := f.registry.Set(id, enabled); err != nil {
+		return Gate{}, err
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	o.expiresAt = time.Time{}
+	if duration > 0 {
+		o.expiresAt = time.Now().Add(duration)
+		var timer *time.Timer
+		timer = time.AfterFunc(duration, func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			// the override may have been replaced or reverted while this timer was firing
+			if current, ok := f.overrides[id]; ok && current.timer == timer {
+				f.revert(id)
+			}
+		})
+		o.timer = timer
+	}
+	f.overrides[id] = o
+	f.telemetry.Logger.Info("Feature gate overridden",
+		zap.String("id", id), zap.Bool("enabled", enabled), zap.Duration("duration", duration))
+	return f.toGate(g), nil
+}
+
+// Reset reverts an overridden feature gate to its state before it was first overridden.
+func (f *featureGatesExtension) Reset(id string) (Gate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g, ok := f.gate(id)
+	if !ok {
+		return Gate{}, errUnknownGate
+	}
+	f.revert(id)
+	return f.toGate(g), nil
+}
+
+// revert restores the state of an overridden feature gate. Must be called with the lock held.
+func (f *featureGatesExtension) revert(id string) {
+	o, ok := f.overrides[id]
+	if !ok {
+		return
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+	delete(f.overrides, id)
+	if err := f.registry.Set(id, o.original); err != nil {
+		f.telemetry.Logger.Warn("Failed to revert feature gate", zap.String("id", id), zap.Error(err))
+		return
+	}
+	f.telemetry.Logger.Info("Feature gate reverted", zap.String("id", id), zap.Bool("enabled", o.original))
+}
+
+func (f *featureGatesExtension) gate(id string) (*featuregate.Gate, bool) {
+	var found *featuregate.Gate
+	f.registry.VisitAll(func(g *featuregate.Gate) {
+		if g.ID() == id {
+			found = g
+		}
+	})
+	return found, found != nil
+}
+
+// toGate returns the served representation of the feature gate. Must be called with the lock held.
+func (f *featureGatesExtension) toGate(g *featuregate.Gate) Gate {
+	_, runtimeSafe := f.runtimeSafe[g.ID()]
+	gate := Gate{
+		ID:           g.ID(),
+		Description:  g.Description(),
+		Stage:        g.Stage().String(),
+		ReferenceURL: g.ReferenceURL(),
+		Enabled:      g.IsEnabled(),
+		RuntimeSafe:  runtimeSafe,
+	}
+	if o, ok := f.overrides[g.ID()]; ok {
+		gate.Overridden = true
+		if !o.expiresAt.IsZero() {
+			expiresAt := o.expiresAt
+			gate.ExpiresAt = &expiresAt
+		}
+	}
+	return gate
+}
+
+// handleGates lists the feature gates on GET.
+func (f *featureGatesExtension) handleGates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, f.Gates())
+}
+
+// handleGate serves a feature gate on GET, sets it from the "enabled" and optional "duration"
+// query parameters on PUT and reverts its override on DELETE.
+func (f *featureGatesExtension) handleGate(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, gatesPath+"/")
+	var gate Gate
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		f.mu.Lock()
+		g, ok := f.gate(id)
+		if ok {
+			gate = f.toGate(g)
+		} else {
+			err = errUnknownGate
+		}
+		f.mu.Unlock()
+	case http.MethodPut:
+		query := r.URL.Query()
+		enabled, parseErr := strconv.ParseBool(query.Get("enabled"))
+		if parseErr != nil {
+			http.Error(w, "enabled must be true or false", http.StatusBadRequest)
+			return
+		}
+		var duration time.Duration
+		if d := query.Get("duration"); d != "" {
+			if duration, parseErr = time.ParseDuration(d); parseErr != nil || duration <= 0 {
+				http.Error(w, "duration must be a positive duration, e.g. 1h", http.StatusBadRequest)
+				return
+			}
+		}
+		gate, err = f.Set(id, enabled, duration)
+	case http.MethodDelete:
+		gate, err = f.Reset(id)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch {
+	case errors.Is(err, errUnknownGate):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errNotRuntimeSafe):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSON(w, gate)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,172 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuregatesextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+func newTestExtension() (*featureGatesExtension, *featuregate.Registry) {
+	registry := featuregate.NewRegistry()
+	registry.MustRegister("splunk.safe", featuregate.StageAlpha, featuregate.WithRegisterDescription("A runtime safe gate."))
+	registry.MustRegister("splunk.unsafe", featuregate.StageBeta)
+	cfg := &Config{RuntimeSafe: []string{"splunk.safe"}}
+	return newFeatureGatesExtension(cfg, componenttest.NewNopTelemetrySettings(), registry), registry
+}
+
+func isEnabled(registry *featuregate.Registry, id string) bool {
+	enabled := false
+	registry.VisitAll(func(g *featuregate.Gate) {
+		if g.ID() == id {
+			enabled = g.IsEnabled()
+		}
+	})
+	return enabled
+}
+
+func TestSetAndReset(t *testing.T) {
+	f, registry := newTestExtension()
+
+	gate, err := f.Set("splunk.safe", true, 0)
+	require.NoError(t, err)
+	assert.True(t, gate.Enabled)
+	assert.True(t, gate.Overridden)
+	assert.Nil(t, gate.ExpiresAt)
+	assert.True(t, isEnabled(registry, "splunk.safe"))
+
+	// overriding again keeps the state from before the first override
+	_, err = f.Set("splunk.safe", false, 0)
+	require.NoError(t, err)
+	_, err = f.Set("splunk.safe", true, 0)
+	require.NoError(t, err)
+	gate, err = f.Reset("splunk.safe")
+	require.NoError(t, err)
+	assert.False(t, gate.Enabled)
+	assert.False(t, gate.Overridden)
+	assert.False(t, isEnabled(registry, "splunk.safe"))
+
+	_, err = f.Set("splunk.unsafe", false, 0)
+	require.ErrorIs(t, err, errNotRuntimeSafe)
+	assert.True(t, isEnabled(registry, "splunk.unsafe"))
+	_, err = f.Set("splunk.unknown", true, 0)
+	require.ErrorIs(t, err, errUnknownGate)
+}
+
+func TestSetWithDuration(t *testing.T) {
+	f, registry := newTestExtension()
+
+	gate, err := f.Set("splunk.safe", true, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, gate.ExpiresAt)
+	assert.True(t, isEnabled(registry, "splunk.safe"))
+	require.Eventually(t, func() bool {
+		return !isEnabled(registry, "splunk.safe")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// a later override without duration cancels the expiry
+	_, err = f.Set("splunk.safe", true, 20*time.Millisecond)
+	require.NoError(t, err)
+	gate, err = f.Set("splunk.safe", true, 0)
+	require.NoError(t, err)
+	assert.Nil(t, gate.ExpiresAt)
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, isEnabled(registry, "splunk.safe"))
+}
+
+func TestShutdownRevertsOverrides(t *testing.T) {
+	f, registry := newTestExtension()
+	f.config.Endpoint = "localhost:0"
+	require.NoError(t, f.Start(context.Background(), componenttest.NewNopHost()))
+	_, err := f.Set("splunk.safe", true, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, f.Shutdown(context.Background()))
+	assert.False(t, isEnabled(registry, "splunk.safe"))
+}
+
+func TestStartWithUnknownRuntimeSafeGate(t *testing.T) {
+	f, _ := newTestExtension()
+	f.config.Endpoint = "localhost:0"
+	f.config.RuntimeSafe = []string{"splunk.unknown"}
+	require.EqualError(t, f.Start(context.Background(), componenttest.NewNopHost()), `runtime_safe feature gate "splunk.unknown" is not registered`)
+	require.NoError(t, f.Shutdown(context.Background()))
+}
+
+func TestHandlers(t *testing.T) {
+	f, _ := newTestExtension()
+	defer func() { require.NoError(t, f.Shutdown(context.Background())) }()
+
+	rec := httptest.NewRecorder()
+	f.handleGates(rec, httptest.NewRequest(http.MethodGet, gatesPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var gates []Gate
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &gates))
+	assert.Equal(t, []Gate{
+		{ID: "splunk.safe", Description: "A runtime safe gate.", Stage: "Alpha", RuntimeSafe: true},
+		{ID: "splunk.unsafe", Stage: "Beta", Enabled: true},
+	}, gates)
+
+	for _, tt := range []struct {
+		name            string
+		method          string
+		target          string
+		expectedCode    int
+		expectedEnabled bool
+	}{
+		{name: "get", method: http.MethodGet, target: "/splunk.safe", expectedCode: http.StatusOK},
+		{name: "enable", method: http.MethodPut, target: "/splunk.safe?enabled=true&duration=1h", expectedCode: http.StatusOK, expectedEnabled: true},
+		{name: "reset", method: http.MethodDelete, target: "/splunk.safe", expectedCode: http.StatusOK},
+		{name: "invalid enabled", method: http.MethodPut, target: "/splunk.safe?enabled=maybe", expectedCode: http.StatusBadRequest},
+		{name: "invalid duration", method: http.MethodPut, target: "/splunk.safe?enabled=true&duration=-1h", expectedCode: http.StatusBadRequest},
+		{name: "not runtime safe", method: http.MethodPut, target: "/splunk.unsafe?enabled=false", expectedCode: http.StatusForbidden},
+		{name: "unknown", method: http.MethodGet, target: "/splunk.unknown", expectedCode: http.StatusNotFound},
+		{name: "invalid method", method: http.MethodPost, target: "/splunk.safe", expectedCode: http.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			f.handleGate(rec, httptest.NewRequest(tt.method, gatesPath+tt.target, nil))
+			require.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var gate Gate
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &gate))
+			assert.Equal(t, tt.expectedEnabled, gate.Enabled)
+		})
+	}
+}
+
+func TestAdminRoutes(t *testing.T) {
+	f, registry := newTestExtension()
+
+	routes := f.AdminRoutes()
+	require.Len(t, routes, 2)
+	assert.Equal(t, gatesPath, routes[0].Pattern)
+	assert.Equal(t, gatesPath+"/", routes[1].Pattern)
+	rec := httptest.NewRecorder()
+	routes[1].Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/featuregates/splunk.safe?enabled=true", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, isEnabled(registry, "splunk.safe"))
+	require.NoError(t, f.Shutdown(context.Background()))
+}
@@ -0,0 +1,37 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpmonitoringencodingextension
+
+import (
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+var (
+	_ extension.Extension = (*encodingExtension)(nil)
+	_ pmetric.Unmarshaler = (*encodingExtension)(nil)
+)
+
+// encodingExtension is a metrics unmarshaler extension, like the ones of the contrib encoding
+// extensions, so receivers like the googlecloudpubsub receiver can use it as their encoding.
+type encodingExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (*encodingExtension) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	return unmarshalTimeSeries(buf)
+}
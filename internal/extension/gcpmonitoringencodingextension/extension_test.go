@@ -0,0 +1,134 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpmonitoringencodingextension
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func timestamp(t *testing.T, value string) pcommon.Timestamp {
+	ts, err := time.Parse(time.RFC3339, value)
+	require.NoError(t, err)
+	return pcommon.NewTimestampFromTime(ts)
+}
+
+func TestUnmarshalMetrics(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "time_series.json"))
+	require.NoError(t, err)
+
+	md, err := (&encodingExtension{}).UnmarshalMetrics(buf)
+	require.NoError(t, err)
+	require.Equal(t, 2, md.ResourceMetrics().Len())
+
+	instance := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{
+		"cloud.provider":    "gcp",
+		"cloud.account.id":  "acme-prod",
+		"gcp.resource_type": "gce_instance",
+		"instance_id":       "1234",
+		"zone":              "us-central1-a",
+	}, instance.Resource().Attributes().AsRaw())
+	metrics := instance.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+
+	cpu := metrics.At(0)
+	assert.Equal(t, "compute.googleapis.com/instance/cpu/utilization", cpu.Name())
+	assert.Equal(t, "10^2.%", cpu.Unit())
+	require.Equal(t, pmetric.MetricTypeGauge, cpu.Type())
+	dp := cpu.Gauge().DataPoints().At(0)
+	assert.Equal(t, 0.25, dp.DoubleValue())
+	assert.Equal(t, timestamp(t, "2024-05-01T10:00:00Z"), dp.Timestamp())
+	assert.Zero(t, dp.StartTimestamp())
+	assert.Equal(t, map[string]any{"instance_name": "web-1"}, dp.Attributes().AsRaw())
+
+	received := metrics.At(1)
+	require.Equal(t, pmetric.MetricTypeSum, received.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, received.Sum().AggregationTemporality())
+	assert.False(t, received.Sum().IsMonotonic())
+	dp = received.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(4096), dp.IntValue())
+	assert.Equal(t, timestamp(t, "2024-05-01T09:59:00Z"), dp.StartTimestamp())
+
+	lb := md.ResourceMetrics().At(1)
+	assert.Equal(t, "https_lb_rule", lb.Resource().Attributes().AsRaw()["gcp.resource_type"])
+	latencies := lb.ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, pmetric.MetricTypeHistogram, latencies.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, latencies.Histogram().AggregationTemporality())
+	hdp := latencies.Histogram().DataPoints().At(0)
+	assert.Equal(t, uint64(4), hdp.Count())
+	assert.Equal(t, 120.0, hdp.Sum())
+	assert.Equal(t, []float64{10, 20, 40}, hdp.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{0, 1, 3, 0}, hdp.BucketCounts().AsRaw())
+}
+
+func TestUnmarshalSingleTimeSeries(t *testing.T) {
+	md, err := (&encodingExtension{}).UnmarshalMetrics([]byte(`{
+		"metric": {"type": "pubsub.googleapis.com/subscription/num_undelivered_messages"},
+		"resource": {"type": "pubsub_subscription", "labels": {"project_id": "acme-prod"}},
+		"metricKind": "CUMULATIVE",
+		"valueType": "BOOL",
+		"points": [{"interval": {"startTime": "2024-05-01T09:00:00Z", "endTime": "2024-05-01T10:00:00Z"}, "value": {"boolValue": true}}]
+	}`))
+	require.NoError(t, err)
+	m := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, pmetric.MetricTypeSum, m.Type())
+	assert.True(t, m.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, m.Sum().AggregationTemporality())
+	assert.Equal(t, int64(1), m.Sum().DataPoints().At(0).IntValue())
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		buf         string
+		expectedErr string
+	}{
+		{name: "not json", buf: `metrics`, expectedErr: "invalid time series: invalid character 'm' looking for beginning of value"},
+		{name: "no metric type", buf: `{"points": []}`, expectedErr: "invalid time series 0: metric type is missing"},
+		{
+			name:        "unsupported kind",
+			buf:         `{"metric": {"type": "m"}, "metricKind": "METRIC_KIND_UNSPECIFIED"}`,
+			expectedErr: `invalid time series 0: unsupported metric kind "METRIC_KIND_UNSPECIFIED"`,
+		},
+		{
+			name:        "string value",
+			buf:         `{"metric": {"type": "m"}, "valueType": "STRING", "points": [{"interval": {"endTime": "2024-05-01T10:00:00Z"}, "value": {"stringValue": "up"}}]}`,
+			expectedErr: `invalid time series 0: unsupported value type "STRING"`,
+		},
+		{
+			name:        "invalid time",
+			buf:         `{"timeSeries": [{"metric": {"type": "m"}, "points": [{"interval": {"endTime": "now"}}]}]}`,
+			expectedErr: `invalid time series 0: invalid end time: parsing time "now" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "now" as "2006"`,
+		},
+		{
+			name:        "too many buckets",
+			buf:         `{"metric": {"type": "m"}, "points": [{"interval": {"endTime": "2024-05-01T10:00:00Z"}, "value": {"distributionValue": {"count": "1", "bucketOptions": {"explicitBuckets": {"bounds": [1]}}, "bucketCounts": ["0", "0", "1"]}}}]}`,
+			expectedErr: "invalid time series 0: distribution has 3 bucket counts for 1 bounds",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&encodingExtension{}).UnmarshalMetrics([]byte(tt.buf))
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
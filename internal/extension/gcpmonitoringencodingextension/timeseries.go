@@ -0,0 +1,335 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpmonitoringencodingextension
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// The Cloud Monitoring API types, as serialized to JSON by protojson.
+// See https://cloud.google.com/monitoring/api/ref_v3/rest/v3/TimeSeries.
+
+type listTimeSeriesResponse struct {
+	TimeSeries []timeSeries `json:"timeSeries"`
+}
+
+type timeSeries struct {
+	Metric struct {
+		Labels map[string]string `json:"labels"`
+		Type   string            `json:"type"`
+	} `json:"metric"`
+	Resource   monitoredResource `json:"resource"`
+	MetricKind string            `json:"metricKind"`
+	ValueType  string            `json:"valueType"`
+	Unit       string            `json:"unit"`
+	Points     []point           `json:"points"`
+}
+
+type monitoredResource struct {
+	Labels map[string]string `json:"labels"`
+	Type   string            `json:"type"`
+}
+
+type point struct {
+	Interval struct {
+		StartTime string `json:"startTime"`
+		EndTime   string `json:"endTime"`
+	} `json:"interval"`
+	Value typedValue `json:"value"`
+}
+
+type typedValue struct {
+	BoolValue         *bool         `json:"boolValue"`
+	Int64Value        *int64String  `json:"int64Value"`
+	DoubleValue       *float64      `json:"doubleValue"`
+	DistributionValue *distribution `json:"distributionValue"`
+}
+
+type distribution struct {
+	BucketOptions struct {
+		LinearBuckets *struct {
+			NumFiniteBuckets int     `json:"numFiniteBuckets"`
+			Width            float64 `json:"width"`
+			Offset           float64 `json:"offset"`
+		} `json:"linearBuckets"`
+		ExponentialBuckets *struct {
+			NumFiniteBuckets int     `json:"numFiniteBuckets"`
+			GrowthFactor     float64 `json:"growthFactor"`
+			Scale            float64 `json:"scale"`
+		} `json:"exponentialBuckets"`
+		ExplicitBuckets *struct {
+			Bounds []float64 `json:"bounds"`
+		} `json:"explicitBuckets"`
+	} `json:"bucketOptions"`
+	Count        int64String   `json:"count"`
+	Mean         float64       `json:"mean"`
+	BucketCounts []int64String `json:"bucketCounts"`
+}
+
+// int64String is an int64 serialized as a JSON string by protojson, or as a number.
+type int64String int64
+
+func (i *int64String) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 %s", data)
+	}
+	*i = int64String(v)
+	return nil
+}
+
+const (
+	metricKindGauge      = "GAUGE"
+	metricKindDelta      = "DELTA"
+	metricKindCumulative = "CUMULATIVE"
+
+	cloudProviderAttr  = "cloud.provider"
+	cloudAccountIDAttr = "cloud.account.id"
+	resourceTypeAttr   = "gcp.resource_type"
+	projectIDLabel     = "project_id"
+)
+
+// unmarshalTimeSeries converts a Cloud Monitoring time series, or a list of time series response,
+// to metrics named after the metric type of the time series. The metrics of the time series
+// sharing their monitored resource are grouped in the same resource.
+func unmarshalTimeSeries(buf []byte) (pmetric.Metrics, error) {
+	md := pmetric.NewMetrics()
+	buf = bytes.TrimSpace(buf)
+	var series []timeSeries
+	var list listTimeSeriesResponse
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return md, fmt.Errorf("invalid time series: %w", err)
+	}
+	if list.TimeSeries != nil {
+		series = list.TimeSeries
+	} else {
+		var ts timeSeries
+		if err := json.Unmarshal(buf, &ts); err != nil {
+			return md, fmt.Errorf("invalid time series: %w", err)
+		}
+		series = []timeSeries{ts}
+	}
+
+	resources := map[string]pmetric.ScopeMetrics{}
+	for i := range series {
+		ts := &series[i]
+		if ts.Metric.Type == "" {
+			return md, fmt.Errorf("invalid time series %d: metric type is missing", i)
+		}
+		key := resourceKey(ts.Resource)
+		sm, ok := resources[key]
+		if !ok {
+			rm := md.ResourceMetrics().AppendEmpty()
+			putResource(rm.Resource(), ts.Resource)
+			sm = rm.ScopeMetrics().AppendEmpty()
+			resources[key] = sm
+		}
+		if err := appendMetric(sm.Metrics(), ts); err != nil {
+			return md, fmt.Errorf("invalid time series %d: %w", i, err)
+		}
+	}
+	return md, nil
+}
+
+func resourceKey(resource monitoredResource) string {
+	keys := make([]string, 0, len(resource.Labels))
+	for k := range resource.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(strconv.Quote(resource.Type))
+	for _, k := range keys {
+		sb.WriteString(strconv.Quote(k))
+		sb.WriteString(strconv.Quote(resource.Labels[k]))
+	}
+	return sb.String()
+}
+
+func putResource(resource pcommon.Resource, mr monitoredResource) {
+	attrs := resource.Attributes()
+	attrs.PutStr(cloudProviderAttr, "gcp")
+	if mr.Type != "" {
+		attrs.PutStr(resourceTypeAttr, mr.Type)
+	}
+	for k, v := range mr.Labels {
+		if k == projectIDLabel {
+			attrs.PutStr(cloudAccountIDAttr, v)
+			continue
+		}
+		attrs.PutStr(k, v)
+	}
+}
+
+func appendMetric(metrics pmetric.MetricSlice, ts *timeSeries) error {
+	m := metrics.AppendEmpty()
+	m.SetName(ts.Metric.Type)
+	m.SetUnit(ts.Unit)
+
+	var temporality pmetric.AggregationTemporality
+	switch ts.MetricKind {
+	case metricKindGauge, "":
+		temporality = pmetric.AggregationTemporalityUnspecified
+	case metricKindDelta:
+		temporality = pmetric.AggregationTemporalityDelta
+	case metricKindCumulative:
+		temporality = pmetric.AggregationTemporalityCumulative
+	default:
+		return fmt.Errorf("unsupported metric kind %q", ts.MetricKind)
+	}
+
+	for i := range ts.Points {
+		p := &ts.Points[i]
+		start, end, err := parseInterval(p)
+		if err != nil {
+			return err
+		}
+		if temporality == pmetric.AggregationTemporalityUnspecified {
+			// gauges measure an instant, and have no start time
+			start = 0
+		}
+		v := p.Value
+		if v.DistributionValue != nil {
+			hist := m.Histogram()
+			if m.Type() != pmetric.MetricTypeHistogram {
+				hist = m.SetEmptyHistogram()
+				hist.SetAggregationTemporality(temporality)
+				if temporality == pmetric.AggregationTemporalityUnspecified {
+					hist.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+				}
+			}
+			dp := hist.DataPoints().AppendEmpty()
+			dp.SetStartTimestamp(start)
+			dp.SetTimestamp(end)
+			putLabels(dp.Attributes(), ts.Metric.Labels)
+			if err = setDistribution(dp, v.DistributionValue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var dps pmetric.NumberDataPointSlice
+		if temporality == pmetric.AggregationTemporalityUnspecified {
+			if m.Type() != pmetric.MetricTypeGauge {
+				m.SetEmptyGauge()
+			}
+			dps = m.Gauge().DataPoints()
+		} else {
+			if m.Type() != pmetric.MetricTypeSum {
+				sum := m.SetEmptySum()
+				sum.SetAggregationTemporality(temporality)
+				sum.SetIsMonotonic(temporality == pmetric.AggregationTemporalityCumulative)
+			}
+			dps = m.Sum().DataPoints()
+		}
+		dp := dps.AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(end)
+		putLabels(dp.Attributes(), ts.Metric.Labels)
+		switch {
+		case v.DoubleValue != nil:
+			dp.SetDoubleValue(*v.DoubleValue)
+		case v.Int64Value != nil:
+			dp.SetIntValue(int64(*v.Int64Value))
+		case v.BoolValue != nil:
+			if *v.BoolValue {
+				dp.SetIntValue(1)
+			} else {
+				dp.SetIntValue(0)
+			}
+		default:
+			return fmt.Errorf("unsupported value type %q", ts.ValueType)
+		}
+	}
+	return nil
+}
+
+func parseInterval(p *point) (start, end pcommon.Timestamp, err error) {
+	endTime, err := time.Parse(time.RFC3339Nano, p.Interval.EndTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end time: %w", err)
+	}
+	end = pcommon.NewTimestampFromTime(endTime)
+	if p.Interval.StartTime == "" {
+		return end, end, nil
+	}
+	startTime, err := time.Parse(time.RFC3339Nano, p.Interval.StartTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start time: %w", err)
+	}
+	return pcommon.NewTimestampFromTime(startTime), end, nil
+}
+
+func putLabels(attrs pcommon.Map, labels map[string]string) {
+	for k, v := range labels {
+		attrs.PutStr(k, v)
+	}
+}
+
+// setDistribution sets the histogram data point of a distribution, whose first and last buckets
+// are its underflow and overflow buckets.
+func setDistribution(dp pmetric.HistogramDataPoint, d *distribution) error {
+	count := uint64(d.Count)
+	dp.SetCount(count)
+	dp.SetSum(d.Mean * float64(count))
+	if len(d.BucketCounts) == 0 {
+		return nil
+	}
+
+	var bounds []float64
+	opts := d.BucketOptions
+	switch {
+	case opts.ExplicitBuckets != nil:
+		bounds = opts.ExplicitBuckets.Bounds
+	case opts.LinearBuckets != nil:
+		lb := opts.LinearBuckets
+		for i := 0; i <= lb.NumFiniteBuckets; i++ {
+			bounds = append(bounds, lb.Offset+lb.Width*float64(i))
+		}
+	case opts.ExponentialBuckets != nil:
+		eb := opts.ExponentialBuckets
+		for i := 0; i <= eb.NumFiniteBuckets; i++ {
+			bounds = append(bounds, eb.Scale*math.Pow(eb.GrowthFactor, float64(i)))
+		}
+	default:
+		return errors.New("distribution bucket options are missing")
+	}
+	if len(d.BucketCounts) > len(bounds)+1 {
+		return fmt.Errorf("distribution has %d bucket counts for %d bounds", len(d.BucketCounts), len(bounds))
+	}
+
+	dp.ExplicitBounds().FromRaw(bounds)
+	// trailing empty buckets may be omitted
+	counts := dp.BucketCounts()
+	counts.EnsureCapacity(len(bounds) + 1)
+	for _, c := range d.BucketCounts {
+		counts.Append(uint64(c))
+	}
+	for counts.Len() < len(bounds)+1 {
+		counts.Append(0)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventoryextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines where and how often the collector inventory is reported.
+type Config struct {
+	// ClientConfig configures the client sending the inventory. Its endpoint is the ingest
+	// url, which defaults to the ingest endpoint of the realm.
+	confighttp.ClientConfig `mapstructure:",squash"`
+	// AccessToken is the access token the inventory is sent with.
+	AccessToken configopaque.String `mapstructure:"access_token"`
+	// Realm is the realm the inventory is sent to, unless an endpoint is specified.
+	Realm string `mapstructure:"realm"`
+	// Interval is how often the inventory is reported.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.AccessToken == "" {
+		errs = errors.Join(errs, errors.New("access_token must be specified"))
+	}
+	if cfg.Realm == "" && cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("one of realm or endpoint must be specified"))
+	}
+	if cfg.Interval <= 0 {
+		errs = errors.Join(errs, errors.New("interval must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,176 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventoryextension
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensioncapabilities"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
+)
+
+const (
+	datapointPath = "/v2/datapoint"
+	eventPath     = "/v2/event"
+)
+
+var _ extensioncapabilities.ConfigWatcher = (*inventoryExtension)(nil)
+
+// inventoryExtension periodically reports the collector version, platform, enabled components
+// and config hash to Splunk Observability Cloud, as a gauge and an event, so outdated or
+// misconfigured collectors can be found without access to their hosts.
+type inventoryExtension struct {
+	config   *Config
+	settings extension.Settings
+	client   *http.Client
+	conf     *confmap.Conf
+	notified chan struct{}
+	cancel   context.CancelFunc
+	endpoint string
+	host     string
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+func newInventoryExtension(config *Config, settings extension.Settings) *inventoryExtension {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = realm.ForRealm(config.Realm).Ingest
+	}
+	host, _ := os.Hostname()
+	return &inventoryExtension{
+		config:   config,
+		settings: settings,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		host:     host,
+		notified: make(chan struct{}, 1),
+	}
+}
+
+func (e *inventoryExtension) Start(ctx context.Context, host component.Host) error {
+	client, err := e.config.ClientConfig.ToClient(ctx, host, e.settings.TelemetrySettings)
+	if err != nil {
+		return err
+	}
+	e.client = client
+	var runCtx context.Context
+	runCtx, e.cancel = context.WithCancel(context.Background())
+	e.wg.Add(1)
+	go e.run(runCtx)
+	return nil
+}
+
+func (e *inventoryExtension) Shutdown(context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+	return nil
+}
+
+// NotifyConfig records the effective config, reporting the inventory right away.
+func (e *inventoryExtension) NotifyConfig(_ context.Context, conf *confmap.Conf) error {
+	e.mu.Lock()
+	e.conf = conf
+	e.mu.Unlock()
+	select {
+	case e.notified <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (e *inventoryExtension) run(ctx context.Context) {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.notified:
+		case <-ticker.C:
+		}
+		if err := e.report(ctx); err != nil && ctx.Err() == nil {
+			e.settings.Logger.Warn("Failed to report the collector inventory", zap.Error(err))
+		}
+	}
+}
+
+// report sends the inventory as a gauge and an event.
+func (e *inventoryExtension) report(ctx context.Context) error {
+	e.mu.Lock()
+	inv := buildInventory(e.settings.BuildInfo, e.host, e.conf)
+	e.mu.Unlock()
+
+	dimensions := inv.dimensions()
+	timestamp := time.Now().UnixMilli()
+	datapoints := map[string]any{
+		"gauge": []any{map[string]any{
+			"metric":     inventoryMetric,
+			"value":      1,
+			"dimensions": dimensions,
+			"timestamp":  timestamp,
+		}},
+	}
+	if err := e.send(ctx, datapointPath, datapoints); err != nil {
+		return err
+	}
+	events := []any{map[string]any{
+		"category":   "USER_DEFINED",
+		"eventType":  inventoryEventType,
+		"dimensions": dimensions,
+		"properties": inv.properties(),
+		"timestamp":  timestamp,
+	}}
+	return e.send(ctx, eventPath, events)
+}
+
+func (e *inventoryExtension) send(ctx context.Context, path string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-SF-Token", string(e.config.AccessToken))
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with %s", path, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventoryextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+)
+
+type receivedRequest struct {
+	token string
+	body  []map[string]any
+}
+
+func TestEnabledComponents(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "collector.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"receivers":  {"hostmetrics", "otlp", "prometheus"},
+		"processors": {"batch", "memory_limiter"},
+		"exporters":  {"sapm", "signalfx"},
+		"connectors": {"spanmetrics"},
+		"extensions": {"health_check", "inventory"},
+	}, enabledComponents(conf))
+}
+
+func TestConfigHash(t *testing.T) {
+	a := confmap.NewFromStringMap(map[string]any{"receivers": map[string]any{"otlp": nil, "zipkin": nil}})
+	b := confmap.NewFromStringMap(map[string]any{"receivers": map[string]any{"zipkin": nil, "otlp": nil}})
+	c := confmap.NewFromStringMap(map[string]any{"receivers": map[string]any{"otlp": nil}})
+	assert.Equal(t, configHash(a), configHash(b))
+	assert.NotEqual(t, configHash(a), configHash(c))
+	assert.Len(t, configHash(a), 16)
+}
+
+func TestReportInventory(t *testing.T) {
+	requests := map[string]receivedRequest{}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		// datapoints are keyed by metric type, events are a list
+		var items []map[string]any
+		switch b := body.(type) {
+		case map[string]any:
+			for _, dp := range b["gauge"].([]any) {
+				items = append(items, dp.(map[string]any))
+			}
+		case []any:
+			for _, ev := range b {
+				items = append(items, ev.(map[string]any))
+			}
+		}
+		mu.Lock()
+		requests[r.URL.Path] = receivedRequest{token: r.Header.Get("X-SF-Token"), body: items}
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.AccessToken = "token"
+	cfg.Endpoint = server.URL
+	set := extensiontest.NewNopSettings()
+	set.BuildInfo.Version = "v1.2.3"
+	set.BuildInfo.Command = "otelcol"
+	ext := newInventoryExtension(cfg, set)
+	ext.host = "my-host"
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "collector.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, ext.NotifyConfig(context.Background(), conf))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(requests) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	expectedDimensions := map[string]any{
+		"host":              "my-host",
+		"collector_version": "v1.2.3",
+		"collector_command": "otelcol",
+		"os":                runtime.GOOS,
+		"arch":              runtime.GOARCH,
+		"config_hash":       configHash(conf),
+	}
+
+	datapoints := requests[datapointPath]
+	assert.Equal(t, "token", datapoints.token)
+	require.Len(t, datapoints.body, 1)
+	assert.Equal(t, inventoryMetric, datapoints.body[0]["metric"])
+	assert.Equal(t, 1.0, datapoints.body[0]["value"])
+	assert.Equal(t, expectedDimensions, datapoints.body[0]["dimensions"])
+
+	events := requests[eventPath]
+	assert.Equal(t, "token", events.token)
+	require.Len(t, events.body, 1)
+	assert.Equal(t, inventoryEventType, events.body[0]["eventType"])
+	assert.Equal(t, "USER_DEFINED", events.body[0]["category"])
+	assert.Equal(t, expectedDimensions, events.body[0]["dimensions"])
+	assert.Equal(t, map[string]any{
+		"receivers":  "hostmetrics,otlp,prometheus",
+		"processors": "batch,memory_limiter",
+		"exporters":  "sapm,signalfx",
+		"connectors": "spanmetrics",
+		"extensions": "health_check,inventory",
+	}, events.body[0]["properties"])
+}
+
+func TestReportInventoryFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.AccessToken = "token"
+	cfg.Endpoint = server.URL
+	ext := newInventoryExtension(cfg, extensiontest.NewNopSettings())
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+	require.EqualError(t, ext.report(context.Background()), "/v2/datapoint responded with 401 Unauthorized")
+}
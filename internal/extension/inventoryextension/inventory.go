@@ -0,0 +1,150 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventoryextension
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	// inventoryMetric is the gauge, always 1, reported with the inventory dimensions so
+	// collectors can be charted and alerted on by version, platform and config.
+	inventoryMetric = "splunk.collector.inventory"
+	// inventoryEventType is the event type of the inventory, whose properties list the
+	// enabled components.
+	inventoryEventType = "splunk.collector.inventory"
+)
+
+// componentKinds are the service sections components are enabled in.
+var componentKinds = []string{"receivers", "processors", "exporters", "connectors", "extensions"}
+
+// inventory is the reported state of the collector.
+type inventory struct {
+	// components are the types of the enabled components by kind.
+	components map[string][]string
+	host       string
+	version    string
+	command    string
+	configHash string
+}
+
+// dimensions identify the collector and the version, platform and config it runs.
+func (i inventory) dimensions() map[string]string {
+	dimensions := map[string]string{
+		"collector_version": i.version,
+		"collector_command": i.command,
+		"os":                runtime.GOOS,
+		"arch":              runtime.GOARCH,
+	}
+	if i.host != "" {
+		dimensions["host"] = i.host
+	}
+	if i.configHash != "" {
+		dimensions["config_hash"] = i.configHash
+	}
+	return dimensions
+}
+
+// properties are the enabled component types by kind, comma separated.
+func (i inventory) properties() map[string]string {
+	properties := map[string]string{}
+	for kind, types := range i.components {
+		properties[kind] = strings.Join(types, ",")
+	}
+	return properties
+}
+
+// enabledComponents returns the types of the components the service section of the config
+// enables, by kind. Components that are configured but unused aren't reported.
+func enabledComponents(conf *confmap.Conf) map[string][]string {
+	enabled := map[string]map[string]struct{}{}
+	add := func(kind string, ids any) {
+		list, _ := ids.([]any)
+		for _, id := range list {
+			s, ok := id.(string)
+			if !ok {
+				continue
+			}
+			typ, _, _ := strings.Cut(s, "/")
+			if enabled[kind] == nil {
+				enabled[kind] = map[string]struct{}{}
+			}
+			enabled[kind][typ] = struct{}{}
+		}
+	}
+
+	service, _ := conf.Get("service").(map[string]any)
+	add("extensions", service["extensions"])
+	pipelines, _ := service["pipelines"].(map[string]any)
+	connectors, _ := conf.Get("connectors").(map[string]any)
+	for _, p := range pipelines {
+		pipeline, _ := p.(map[string]any)
+		for _, kind := range []string{"receivers", "exporters"} {
+			ids, _ := pipeline[kind].([]any)
+			// connectors are used as both exporters and receivers
+			var own, conns []any
+			for _, id := range ids {
+				if s, ok := id.(string); ok {
+					if _, isConnector := connectors[s]; isConnector {
+						conns = append(conns, id)
+						continue
+					}
+				}
+				own = append(own, id)
+			}
+			add(kind, own)
+			add("connectors", conns)
+		}
+		add("processors", pipeline["processors"])
+	}
+
+	components := map[string][]string{}
+	for _, kind := range componentKinds {
+		for typ := range enabled[kind] {
+			components[kind] = append(components[kind], typ)
+		}
+		sort.Strings(components[kind])
+	}
+	return components
+}
+
+// configHash returns a short hash identifying the effective config. Identical configs have the
+// same hash regardless of the order of their keys.
+func configHash(conf *confmap.Conf) string {
+	data, err := json.Marshal(conf.ToStringMap())
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// buildInventory returns the inventory of the collector running the config, if notified.
+func buildInventory(info component.BuildInfo, host string, conf *confmap.Conf) inventory {
+	inv := inventory{host: host, version: info.Version, command: info.Command, components: map[string][]string{}}
+	if conf != nil {
+		inv.components = enabledComponents(conf)
+		inv.configHash = configHash(conf)
+	}
+	return inv
+}
@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kerberosextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the Kerberos credentials the extension authenticates outgoing requests with,
+// and the service keytab it validates incoming requests with.
+type Config struct {
+	// Client configures the authentication of the requests of exporters and other clients.
+	Client *ClientConfig `mapstructure:"client"`
+	// Server configures the validation of the requests received by receivers.
+	Server *ServerConfig `mapstructure:"server"`
+}
+
+// ClientConfig defines the Kerberos principal the SPNEGO tokens of outgoing requests are
+// obtained for, from either a keytab or a credential cache.
+type ClientConfig struct {
+	// Username is the principal name authenticating with the keytab, without realm.
+	Username string `mapstructure:"username"`
+	// Realm is the realm of the principal authenticating with the keytab.
+	Realm string `mapstructure:"realm"`
+	// KeytabFile is the keytab the principal logs in with.
+	KeytabFile string `mapstructure:"keytab_file"`
+	// CCacheFile is the credential cache of the principal, kept up to date by e.g. kinit or
+	// k5start, used instead of a keytab.
+	CCacheFile string `mapstructure:"ccache_file"`
+	// ConfigFile is the krb5.conf file locating the KDCs of the realms, /etc/krb5.conf by default.
+	ConfigFile string `mapstructure:"config_file"`
+	// ServicePrincipal is the principal of the destination service, by default HTTP/<host> with
+	// the host of each request.
+	ServicePrincipal string `mapstructure:"service_principal"`
+	// DisablePAFXFAST disables the FAST pre-authentication, unsupported by e.g. Active Directory.
+	DisablePAFXFAST bool `mapstructure:"disable_pafx_fast"`
+}
+
+// ServerConfig defines the service keytab the SPNEGO tokens of incoming requests are validated with.
+type ServerConfig struct {
+	// KeytabFile is the keytab of the service principal of the collector.
+	KeytabFile string `mapstructure:"keytab_file"`
+	// ServicePrincipal is the principal of the keytab tokens are validated with, by default the
+	// one the tokens are issued for.
+	ServicePrincipal string `mapstructure:"service_principal"`
+	// MaxClockSkew is the maximum difference between the clocks of the clients and the collector,
+	// 5 minutes by default.
+	MaxClockSkew time.Duration `mapstructure:"max_clock_skew"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Client == nil && cfg.Server == nil {
+		return errors.New("at least one of client or server must be specified")
+	}
+	var errs []error
+	if cfg.Client != nil {
+		switch {
+		case cfg.Client.KeytabFile == "" && cfg.Client.CCacheFile == "":
+			errs = append(errs, errors.New("client: one of keytab_file or ccache_file must be specified"))
+		case cfg.Client.KeytabFile != "" && cfg.Client.CCacheFile != "":
+			errs = append(errs, errors.New("client: only one of keytab_file or ccache_file can be specified"))
+		case cfg.Client.KeytabFile != "" && (cfg.Client.Username == "" || cfg.Client.Realm == ""):
+			errs = append(errs, errors.New("client: username and realm must be specified with keytab_file"))
+		}
+	}
+	if cfg.Server != nil {
+		if cfg.Server.KeytabFile == "" {
+			errs = append(errs, errors.New("server: keytab_file must be specified"))
+		}
+		if cfg.Server.MaxClockSkew < 0 {
+			errs = append(errs, errors.New("server: max_clock_skew must not be negative"))
+		}
+	}
+	return errors.Join(errs...)
+}
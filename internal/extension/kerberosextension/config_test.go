@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kerberosextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewIDWithName(typeStr, "keytab_client"),
+			expected: &Config{
+				Client: &ClientConfig{
+					Username:         "otel-collector",
+					Realm:            "EXAMPLE.COM",
+					KeytabFile:       "/etc/otel/collector.keytab",
+					ServicePrincipal: "HTTP/gateway.example.com",
+					DisablePAFXFAST:  true,
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "ccache_client"),
+			expected: &Config{
+				Client: &ClientConfig{
+					CCacheFile: "/tmp/krb5cc_1000",
+					ConfigFile: "/etc/otel/krb5.conf",
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "server"),
+			expected: &Config{
+				Server: &ServerConfig{
+					KeytabFile:       "/etc/otel/http.keytab",
+					ServicePrincipal: "HTTP/collector.example.com",
+					MaxClockSkew:     time.Minute,
+				},
+			},
+		},
+		{
+			id:          component.MustNewIDWithName(typeStr, "empty"),
+			expectedErr: "at least one of client or server must be specified",
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "client: username and realm must be specified with keytab_file" +
+				"\nserver: keytab_file must be specified" +
+				"\nserver: max_clock_skew must not be negative",
+		},
+		{
+			id:          component.MustNewIDWithName(typeStr, "both_client_credentials"),
+			expectedErr: "client: only one of keytab_file or ccache_file can be specified",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kerberosextension
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	krbcredentials "github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/auth"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	defaultConfigFile   = "/etc/krb5.conf"
+	defaultMaxClockSkew = 5 * time.Minute
+	negotiatePrefix     = "Negotiate "
+	// credentialsKey is the context key gokrb5 sets the credentials of the validated tokens with.
+	credentialsKey = "github.com/jcmturner/gokrb5/v8/ctxCredentials"
+)
+
+var (
+	_ auth.Client = (*kerberosAuth)(nil)
+	_ auth.Server = (*kerberosAuth)(nil)
+
+	errNoClient          = errors.New("the kerberos extension has no client configuration")
+	errNoServer          = errors.New("the kerberos extension has no server configuration")
+	errNoNegotiateHeader = errors.New("missing Negotiate authorization header")
+)
+
+// kerberosAuth authenticates the requests of clients with SPNEGO tokens obtained for the
+// configured principal, and validates the SPNEGO tokens of the requests received by servers
+// with the service keytab.
+type kerberosAuth struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	// serviceSettings are the settings tokens are validated with, nil without server config.
+	serviceSettings []func(*service.Settings)
+	serviceKeytab   *keytab.Keytab
+
+	mu sync.Mutex
+	// krbClient obtains the service tickets, nil without client config.
+	krbClient *krbclient.Client
+	krbConfig *krbconfig.Config
+	// ccacheModTime is the modification time of the credential cache krbClient was loaded from.
+	ccacheModTime time.Time
+}
+
+func newKerberosAuth(config *Config, telemetry component.TelemetrySettings) *kerberosAuth {
+	return &kerberosAuth{config: config, telemetry: telemetry}
+}
+
+func (k *kerberosAuth) Start(context.Context, component.Host) error {
+	if cfg := k.config.Server; cfg != nil {
+		kt, err := keytab.Load(cfg.KeytabFile)
+		if err != nil {
+			return fmt.Errorf("failed to load server keytab %s: %w", cfg.KeytabFile, err)
+		}
+		k.serviceKeytab = kt
+		maxClockSkew := cfg.MaxClockSkew
+		if maxClockSkew == 0 {
+			maxClockSkew = defaultMaxClockSkew
+		}
+		k.serviceSettings = []func(*service.Settings){service.MaxClockSkew(maxClockSkew)}
+		if cfg.ServicePrincipal != "" {
+			k.serviceSettings = append(k.serviceSettings, service.KeytabPrincipal(cfg.ServicePrincipal))
+		}
+	}
+
+	if cfg := k.config.Client; cfg != nil {
+		configFile := cfg.ConfigFile
+		if configFile == "" {
+			configFile = defaultConfigFile
+		}
+		var err error
+		if k.krbConfig, err = krbconfig.Load(configFile); err != nil {
+			return fmt.Errorf("failed to load Kerberos config %s: %w", configFile, err)
+		}
+		if cfg.CCacheFile != "" {
+			k.mu.Lock()
+			defer k.mu.Unlock()
+			return k.loadCCache()
+		}
+		kt, err := keytab.Load(cfg.KeytabFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client keytab %s: %w", cfg.KeytabFile, err)
+		}
+		k.krbClient = krbclient.NewWithKeytab(cfg.Username, cfg.Realm, kt, k.krbConfig, krbclient.DisablePAFXFAST(cfg.DisablePAFXFAST))
+		if err = k.krbClient.Login(); err != nil {
+			return fmt.Errorf("failed to log in as %s@%s: %w", cfg.Username, cfg.Realm, err)
+		}
+	}
+	return nil
+}
+
+func (k *kerberosAuth) Shutdown(context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.krbClient != nil {
+		k.krbClient.Destroy()
+		k.krbClient = nil
+	}
+	return nil
+}
+
+// loadCCache (re)creates the client from the credential cache if it was modified since it was
+// last loaded, so the tickets renewed by e.g. kinit are used. It must be called with mu held.
+func (k *kerberosAuth) loadCCache() error {
+	path := strings.TrimPrefix(k.config.Client.CCacheFile, "FILE:")
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to load credential cache: %w", err)
+	}
+	if k.krbClient != nil && info.ModTime().Equal(k.ccacheModTime) {
+		return nil
+	}
+	ccache, err := krbcredentials.LoadCCache(path)
+	if err != nil {
+		return fmt.Errorf("failed to load credential cache %s: %w", path, err)
+	}
+	cl, err := krbclient.NewFromCCache(ccache, k.krbConfig, krbclient.DisablePAFXFAST(k.config.Client.DisablePAFXFAST))
+	if err != nil {
+		return fmt.Errorf("failed to load credential cache %s: %w", path, err)
+	}
+	if k.krbClient != nil {
+		k.krbClient.Destroy()
+	}
+	k.krbClient = cl
+	k.ccacheModTime = info.ModTime()
+	return nil
+}
+
+// negotiateHeader returns the Negotiate authorization header of a new SPNEGO token for the
+// service principal of the host.
+func (k *kerberosAuth) negotiateHeader(host string) (string, error) {
+	spn := k.config.Client.ServicePrincipal
+	if spn == "" {
+		spn = "HTTP/" + host
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.krbClient == nil {
+		return "", errors.New("the kerberos extension isn't started")
+	}
+	if k.config.Client.CCacheFile != "" {
+		if err := k.loadCCache(); err != nil {
+			return "", err
+		}
+	}
+	s := spnego.SPNEGOClient(k.krbClient, spn)
+	if err := s.AcquireCred(); err != nil {
+		return "", fmt.Errorf("failed to obtain a ticket granting ticket: %w", err)
+	}
+	token, err := s.InitSecContext()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain a service ticket for %s: %w", spn, err)
+	}
+	b, err := token.Marshal()
+	if err != nil {
+		return "", err
+	}
+	return negotiatePrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+func (k *kerberosAuth) RoundTripper(base http.RoundTripper) (http.RoundTripper, error) {
+	if k.config.Client == nil {
+		return nil, errNoClient
+	}
+	return &negotiateRoundTripper{base: base, auth: k}, nil
+}
+
+func (k *kerberosAuth) PerRPCCredentials() (credentials.PerRPCCredentials, error) {
+	if k.config.Client == nil {
+		return nil, errNoClient
+	}
+	return &negotiateCredentials{auth: k}, nil
+}
+
+// Authenticate validates the SPNEGO token of the Negotiate authorization header, and sets the
+// authenticated principal as the auth data of the request's client info.
+func (k *kerberosAuth) Authenticate(ctx context.Context, headers map[string][]string) (context.Context, error) {
+	if k.serviceKeytab == nil {
+		return ctx, errNoServer
+	}
+	var header string
+	for name, values := range headers {
+		if strings.EqualFold(name, "authorization") && len(values) > 0 {
+			header = values[0]
+			break
+		}
+	}
+	encoded, ok := strings.CutPrefix(header, negotiatePrefix)
+	if !ok {
+		return ctx, errNoNegotiateHeader
+	}
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid SPNEGO token: %w", err)
+	}
+	var token spnego.SPNEGOToken
+	if err = token.Unmarshal(b); err != nil {
+		return ctx, fmt.Errorf("invalid SPNEGO token: %w", err)
+	}
+	authenticated, tokenCtx, status := spnego.SPNEGOService(k.serviceKeytab, k.serviceSettings...).AcceptSecContext(&token)
+	if !authenticated {
+		return ctx, fmt.Errorf("SPNEGO token rejected: %s", status.Message)
+	}
+	creds, ok := tokenCtx.Value(credentialsKey).(*krbcredentials.Credentials)
+	if !ok {
+		return ctx, errors.New("SPNEGO token without credentials")
+	}
+	info := client.FromContext(ctx)
+	info.Auth = newAuthData(creds)
+	return client.NewContext(ctx, info), nil
+}
+
+// negotiateRoundTripper sets the Negotiate authorization header of the requests.
+type negotiateRoundTripper struct {
+	base http.RoundTripper
+	auth *kerberosAuth
+}
+
+func (rt *negotiateRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	header, err := rt.auth.negotiateHeader(req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", header)
+	return rt.base.RoundTrip(req)
+}
+
+// negotiateCredentials sets the Negotiate authorization header of gRPC calls.
+type negotiateCredentials struct {
+	auth *kerberosAuth
+}
+
+func (c *negotiateCredentials) GetRequestMetadata(_ context.Context, uri ...string) (map[string]string, error) {
+	var host string
+	if len(uri) > 0 {
+		u, err := url.Parse(uri[0])
+		if err != nil {
+			return nil, err
+		}
+		host = u.Hostname()
+	}
+	header, err := c.auth.negotiateHeader(host)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": header}, nil
+}
+
+// RequireTransportSecurity requires TLS, since SPNEGO tokens can be replayed by eavesdroppers
+// to other collectors until they expire.
+func (c *negotiateCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// authData exposes the authenticated principal as the `subject`, e.g. alice@EXAMPLE.COM, and
+// `realm` attributes, and its Active Directory group SIDs as the `membership` attribute.
+type authData struct {
+	subject    string
+	realm      string
+	membership []string
+}
+
+var _ client.AuthData = (*authData)(nil)
+
+func newAuthData(creds *krbcredentials.Credentials) *authData {
+	return &authData{
+		subject:    creds.CName().PrincipalNameString() + "@" + creds.Realm(),
+		realm:      creds.Realm(),
+		membership: creds.AuthzAttributes(),
+	}
+}
+
+func (a *authData) GetAttribute(name string) any {
+	switch name {
+	case "subject":
+		return a.subject
+	case "realm":
+		return a.realm
+	case "membership":
+		return a.membership
+	}
+	return nil
+}
+
+func (a *authData) GetAttributeNames() []string {
+	return []string{"subject", "realm", "membership"}
+}
@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines how the targets of gRPC clients with the lb scheme are balanced.
+type Config struct {
+	// OutlierEjection ejects the backends failing the most requests.
+	OutlierEjection OutlierEjectionConfig `mapstructure:"outlier_ejection"`
+	// ResolutionInterval is the interval at which targets are re-resolved.
+	ResolutionInterval time.Duration `mapstructure:"resolution_interval"`
+}
+
+// OutlierEjectionConfig defines when backends are ejected from the balancing.
+type OutlierEjectionConfig struct {
+	// Interval is the interval at which the requests of the backends are checked.
+	Interval time.Duration `mapstructure:"interval"`
+	// BaseEjectionTime is the time a backend is ejected for, multiplied by the number
+	// of times it has been ejected in a row.
+	BaseEjectionTime time.Duration `mapstructure:"base_ejection_time"`
+	// MaxEjectionTime caps the time a backend is ejected for.
+	MaxEjectionTime time.Duration `mapstructure:"max_ejection_time"`
+	// MaxEjectionPercent is the maximum percentage of the backends ejected at once.
+	MaxEjectionPercent uint32 `mapstructure:"max_ejection_percent"`
+	// FailurePercentageThreshold is the percentage of failed requests over which a backend
+	// is ejected.
+	FailurePercentageThreshold uint32 `mapstructure:"failure_percentage_threshold"`
+	// MinimumHosts is the minimum number of backends with RequestVolume requests in an
+	// interval for any of them to be ejected.
+	MinimumHosts uint32 `mapstructure:"minimum_hosts"`
+	// RequestVolume is the minimum number of requests of a backend in an interval for it
+	// to be ejected.
+	RequestVolume uint32 `mapstructure:"request_volume"`
+	Enabled       bool   `mapstructure:"enabled"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.ResolutionInterval <= 0 {
+		errs = errors.Join(errs, errors.New("resolution_interval must be positive"))
+	}
+	if !cfg.OutlierEjection.Enabled {
+		return errs
+	}
+	oe := cfg.OutlierEjection
+	if oe.Interval <= 0 {
+		errs = errors.Join(errs, errors.New("outlier_ejection::interval must be positive"))
+	}
+	if oe.BaseEjectionTime <= 0 {
+		errs = errors.Join(errs, errors.New("outlier_ejection::base_ejection_time must be positive"))
+	}
+	if oe.MaxEjectionTime < oe.BaseEjectionTime {
+		errs = errors.Join(errs, errors.New("outlier_ejection::max_ejection_time must be at least base_ejection_time"))
+	}
+	if oe.MaxEjectionPercent > 100 {
+		errs = errors.Join(errs, errors.New("outlier_ejection::max_ejection_percent must be at most 100"))
+	}
+	if oe.FailurePercentageThreshold > 100 {
+		errs = errors.Join(errs, errors.New("outlier_ejection::failure_percentage_threshold must be at most 100"))
+	}
+	return errs
+}
@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id:       component.MustNewID(typeStr),
+			expected: createDefaultConfig().(*Config),
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				ResolutionInterval: 10 * time.Second,
+				OutlierEjection: OutlierEjectionConfig{
+					Enabled:                    true,
+					Interval:                   5 * time.Second,
+					BaseEjectionTime:           time.Minute,
+					MaxEjectionTime:            10 * time.Minute,
+					MaxEjectionPercent:         30,
+					FailurePercentageThreshold: 20,
+					MinimumHosts:               3,
+					RequestVolume:              100,
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "resolution_interval must be positive\n" +
+				"outlier_ejection::interval must be positive\n" +
+				"outlier_ejection::max_ejection_time must be at least base_ejection_time\n" +
+				"outlier_ejection::max_ejection_percent must be at most 100\n" +
+				"outlier_ejection::failure_percentage_threshold must be at most 100",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingextension
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+)
+
+var _ extension.Extension = (*loadBalancingExtension)(nil)
+
+// loadBalancingExtension configures the balancing of the gRPC clients with lb targets while
+// it runs. Extensions are started before any other component, so the clients the components
+// create when started are balanced by its config.
+type loadBalancingExtension struct {
+	config *Config
+}
+
+func newLoadBalancingExtension(config *Config) *loadBalancingExtension {
+	return &loadBalancingExtension{config: config}
+}
+
+func (e *loadBalancingExtension) Start(context.Context, component.Host) error {
+	current.Store(e.config)
+	return nil
+}
+
+// Shutdown restores the default balancing, so the config of a reloaded config replaces it
+// rather than the previous one outliving its config.
+func (e *loadBalancingExtension) Shutdown(context.Context) error {
+	current.Store(nil)
+	return nil
+}
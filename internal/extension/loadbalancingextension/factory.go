@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingextension
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+)
+
+const typeStr = "grpc_load_balancing"
+
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		createExtension,
+		component.StabilityLevelDevelopment,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		ResolutionInterval: 30 * time.Second,
+		OutlierEjection: OutlierEjectionConfig{
+			Interval:                   10 * time.Second,
+			BaseEjectionTime:           30 * time.Second,
+			MaxEjectionTime:            5 * time.Minute,
+			MaxEjectionPercent:         50,
+			FailurePercentageThreshold: 50,
+			MinimumHosts:               2,
+			RequestVolume:              20,
+		},
+	}
+}
+
+func createExtension(_ context.Context, _ extension.Settings, cfg component.Config) (extension.Extension, error) {
+	return newLoadBalancingExtension(cfg.(*Config)), nil
+}
@@ -0,0 +1,189 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingextension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+
+	"github.com/signalfx/splunk-otel-collector/internal/egress"
+)
+
+const (
+	scheme      = "lb"
+	defaultPort = "443"
+	// minResolveNowInterval rate limits the re-resolutions gRPC requests when connections
+	// to backends fail, e.g. while a backend is replaced.
+	minResolveNowInterval = 5 * time.Second
+)
+
+var (
+	// current is the config of the running extension, or nil for the default config.
+	current atomic.Pointer[Config]
+	// lookupHost is overridden in tests.
+	lookupHost = net.DefaultResolver.LookupHost
+)
+
+func init() {
+	resolver.Register(&resolverBuilder{})
+}
+
+// resolverBuilder resolves lb targets to all the addresses of their host, re-resolved at the
+// resolution interval, and balances them round robin, ejecting outliers if enabled.
+type resolverBuilder struct{}
+
+func (*resolverBuilder) Scheme() string {
+	return scheme
+}
+
+func (*resolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	endpoint := target.Endpoint()
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host, port = endpoint, defaultPort
+	}
+	if host == "" {
+		return nil, fmt.Errorf("invalid lb target %q: the host must be specified", target.URL.String())
+	}
+	// lb targets are resolved with the default resolver rather than the dns gRPC resolver the
+	// egress restriction wraps, so they're checked here.
+	if err = egress.Check(host); err != nil {
+		return nil, err
+	}
+	cfg := current.Load()
+	if cfg == nil {
+		cfg = createDefaultConfig().(*Config)
+	}
+	sc := cc.ParseServiceConfig(serviceConfig(cfg))
+	if sc.Err != nil {
+		return nil, fmt.Errorf("invalid load balancing config: %w", sc.Err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &lbResolver{
+		cc:         cc,
+		host:       host,
+		port:       port,
+		state:      resolver.State{ServiceConfig: sc},
+		interval:   cfg.ResolutionInterval,
+		resolveNow: make(chan struct{}, 1),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	go r.run(ctx)
+	return r, nil
+}
+
+type lbResolver struct {
+	cc         resolver.ClientConn
+	resolveNow chan struct{}
+	cancel     context.CancelFunc
+	done       chan struct{}
+	host       string
+	port       string
+	state      resolver.State
+	interval   time.Duration
+}
+
+func (r *lbResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *lbResolver) Close() {
+	r.cancel()
+	<-r.done
+}
+
+func (r *lbResolver) run(ctx context.Context) {
+	defer close(r.done)
+	for {
+		r.resolve(ctx)
+		resolved := time.Now()
+		timer := time.NewTimer(r.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			continue
+		case <-r.resolveNow:
+			timer.Stop()
+		}
+		timer = time.NewTimer(time.Until(resolved.Add(minResolveNowInterval)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *lbResolver) resolve(ctx context.Context) {
+	addrs, err := lookupHost(ctx, r.host)
+	if err != nil {
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			r.cc.ReportError(fmt.Errorf("failed to resolve %q: %w", r.host, err))
+		}
+		return
+	}
+	// sorted so that an unchanged set of addresses doesn't rebalance the connections
+	sort.Strings(addrs)
+	state := r.state
+	state.Addresses = make([]resolver.Address, len(addrs))
+	for i, addr := range addrs {
+		state.Addresses[i] = resolver.Address{Addr: net.JoinHostPort(addr, r.port)}
+	}
+	// an error is returned if the balancer rejects the addresses, which are re-resolved at the
+	// next interval anyway.
+	_ = r.cc.UpdateState(state)
+}
+
+// serviceConfig returns the gRPC service config balancing the backends of the config.
+func serviceConfig(cfg *Config) string {
+	policy := map[string]any{"round_robin": struct{}{}}
+	if oe := cfg.OutlierEjection; oe.Enabled {
+		policy = map[string]any{"outlier_detection_experimental": map[string]any{
+			"interval":           duration(oe.Interval),
+			"baseEjectionTime":   duration(oe.BaseEjectionTime),
+			"maxEjectionTime":    duration(oe.MaxEjectionTime),
+			"maxEjectionPercent": oe.MaxEjectionPercent,
+			"failurePercentageEjection": map[string]any{
+				"threshold":             oe.FailurePercentageThreshold,
+				"enforcementPercentage": 100,
+				"minimumHosts":          oe.MinimumHosts,
+				"requestVolume":         oe.RequestVolume,
+			},
+			"childPolicy": []any{policy},
+		}}
+	}
+	b, _ := json.Marshal(map[string]any{"loadBalancingConfig": []any{policy}})
+	return string(b)
+}
+
+// duration formats a duration as the seconds of a protobuf JSON duration.
+func duration(d time.Duration) string {
+	return fmt.Sprintf("%d.%09ds", d/time.Second, d%time.Second)
+}
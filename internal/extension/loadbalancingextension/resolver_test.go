@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingextension
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+
+	"github.com/signalfx/splunk-otel-collector/internal/egress"
+)
+
+type fakeClientConn struct {
+	resolver.ClientConn
+	states        chan resolver.State
+	serviceConfig string
+}
+
+func (cc *fakeClientConn) UpdateState(state resolver.State) error {
+	select {
+	case cc.states <- state:
+	default:
+	}
+	return nil
+}
+
+func (cc *fakeClientConn) ReportError(error) {}
+
+func (cc *fakeClientConn) ParseServiceConfig(sc string) *serviceconfig.ParseResult {
+	cc.serviceConfig = sc
+	return &serviceconfig.ParseResult{}
+}
+
+func addrs(state resolver.State) []string {
+	var addrs []string
+	for _, a := range state.Addresses {
+		addrs = append(addrs, a.Addr)
+	}
+	return addrs
+}
+
+func build(t *testing.T, target string) (*fakeClientConn, error) {
+	u, err := url.Parse(target)
+	require.NoError(t, err)
+	cc := &fakeClientConn{states: make(chan resolver.State, 10)}
+	r, err := resolver.Get(scheme).Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{})
+	if err == nil {
+		t.Cleanup(r.Close)
+	}
+	return cc, err
+}
+
+func TestResolver(t *testing.T) {
+	var mu sync.Mutex
+	hosts := map[string][]string{"gateway.example.com": {"10.0.0.2", "10.0.0.1"}}
+	lookupHost = func(_ context.Context, host string) ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return hosts[host], nil
+	}
+	t.Cleanup(func() { lookupHost = net.DefaultResolver.LookupHost })
+	current.Store(&Config{ResolutionInterval: 10 * time.Millisecond})
+	t.Cleanup(func() { current.Store(nil) })
+
+	cc, err := build(t, "lb:///gateway.example.com:4317")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"loadBalancingConfig":[{"round_robin":{}}]}`, cc.serviceConfig)
+	assert.Equal(t, []string{"10.0.0.1:4317", "10.0.0.2:4317"}, addrs(<-cc.states))
+
+	// the backends added by scaling the gateways are resolved at the next interval
+	mu.Lock()
+	hosts["gateway.example.com"] = append(hosts["gateway.example.com"], "10.0.0.3")
+	mu.Unlock()
+	require.Eventually(t, func() bool {
+		return len(addrs(<-cc.states)) == 3
+	}, 5*time.Second, time.Millisecond)
+
+	mu.Lock()
+	hosts["10.0.0.1"] = []string{"10.0.0.1"}
+	mu.Unlock()
+	cc, err = build(t, "lb:///10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:443"}, addrs(<-cc.states))
+
+	_, err = build(t, "lb:///:4317")
+	require.EqualError(t, err, `invalid lb target "lb:///:4317": the host must be specified`)
+}
+
+func TestResolverEgress(t *testing.T) {
+	allowlist, err := egress.NewAllowlist([]string{"*.example.com"})
+	require.NoError(t, err)
+	egress.Enforce(allowlist)
+	t.Cleanup(func() { egress.Enforce(nil) })
+
+	_, err = build(t, "lb:///gateway.example.org:4317")
+	require.EqualError(t, err, `connection to "gateway.example.org" refused: the host isn't in the egress allowlist`)
+}
+
+func TestServiceConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.JSONEq(t, `{"loadBalancingConfig":[{"round_robin":{}}]}`, serviceConfig(cfg))
+
+	cfg.OutlierEjection.Enabled = true
+	cfg.OutlierEjection.Interval = 1500 * time.Millisecond
+	assert.JSONEq(t, `{"loadBalancingConfig":[{"outlier_detection_experimental":{
+		"interval":"1.500000000s",
+		"baseEjectionTime":"30.000000000s",
+		"maxEjectionTime":"300.000000000s",
+		"maxEjectionPercent":50,
+		"failurePercentageEjection":{"threshold":50,"enforcementPercentage":100,"minimumHosts":2,"requestVolume":20},
+		"childPolicy":[{"round_robin":{}}]
+	}}]}`, serviceConfig(cfg))
+}
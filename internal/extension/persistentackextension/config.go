@@ -0,0 +1,56 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistentackextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines where the acknowledgement state is persisted and how much of it is retained.
+type Config struct {
+	// StorageID is the storage extension the acknowledgement state is persisted in.
+	StorageID *component.ID `mapstructure:"storage"`
+	// MaxNumPartition is the maximum number of partitions, e.g. HEC channels, whose state is
+	// retained. The least recently used partition is dropped when exceeded.
+	MaxNumPartition uint64 `mapstructure:"max_number_of_partition"`
+	// MaxNumPendingAcksPerPartition is the maximum number of unqueried ack ids retained per
+	// partition. The oldest ack id is dropped when exceeded.
+	MaxNumPendingAcksPerPartition uint64 `mapstructure:"max_number_of_outstanding_acks_per_partition"`
+	// FlushInterval is how often changes are written to storage. Changes made since the last
+	// flush are lost if the collector stops without shutting down.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.StorageID == nil {
+		errs = errors.Join(errs, errors.New("storage must be specified"))
+	}
+	if cfg.MaxNumPartition == 0 {
+		errs = errors.Join(errs, errors.New("max_number_of_partition must be positive"))
+	}
+	if cfg.MaxNumPendingAcksPerPartition == 0 {
+		errs = errors.Join(errs, errors.New("max_number_of_outstanding_acks_per_partition must be positive"))
+	}
+	if cfg.FlushInterval <= 0 {
+		errs = errors.Join(errs, errors.New("flush_interval must be positive"))
+	}
+	return errs
+}
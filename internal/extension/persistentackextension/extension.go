@@ -0,0 +1,501 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistentackextension
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/ackextension"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	partitionsKey      = "partitions"
+	partitionKeyPrefix = "partition/"
+	bucketKeyPrefix    = "bucket/"
+
+	// bucketSize is the number of consecutive ack ids persisted under one key, so a change
+	// only rewrites the bucket of the changed ack id instead of the whole partition.
+	bucketSize = 1024
+)
+
+var _ ackextension.AckExtension = (*persistentAck)(nil)
+
+// partition is the acknowledgement state of a partition, e.g. a HEC channel.
+type partition struct {
+	// acks are the issued and not yet queried ack ids and whether they've been acked.
+	acks map[uint64]bool
+	// buckets are the number of acks per bucket, for the buckets holding any.
+	buckets map[uint64]int
+	// dirty are the buckets changed since the partition was last flushed.
+	dirty map[uint64]struct{}
+	// order are the ack ids in the order they were issued, possibly including already
+	// queried ones that are skipped.
+	order  []uint64
+	nextID uint64
+}
+
+func newPartition() *partition {
+	return &partition{acks: map[uint64]bool{}, buckets: map[uint64]int{}, dirty: map[uint64]struct{}{}}
+}
+
+func (part *partition) set(ackID uint64, acked bool) {
+	if _, ok := part.acks[ackID]; !ok {
+		part.buckets[ackID/bucketSize]++
+	}
+	part.acks[ackID] = acked
+	part.dirty[ackID/bucketSize] = struct{}{}
+}
+
+func (part *partition) remove(ackID uint64) {
+	if _, ok := part.acks[ackID]; !ok {
+		return
+	}
+	delete(part.acks, ackID)
+	bucket := ackID / bucketSize
+	if part.buckets[bucket]--; part.buckets[bucket] == 0 {
+		delete(part.buckets, bucket)
+	}
+	part.dirty[bucket] = struct{}{}
+}
+
+// persistedPartition is the serialized form of a partition, whose acks are persisted in buckets.
+type persistedPartition struct {
+	Buckets []uint64 `json:"buckets"`
+	NextID  uint64   `json:"next_id"`
+}
+
+// persistedBucket is the serialized form of the acks of a bucket.
+type persistedBucket struct {
+	Pending []uint64 `json:"pending"`
+	Acked   []uint64 `json:"acked"`
+}
+
+// persistentAck is an ackextension.AckExtension whose state is persisted in a storage
+// extension, so acknowledgements issued before a restart can still be queried. Changes are
+// flushed every flush_interval and on shutdown, and partitions are loaded from storage when
+// they're first used after a restart. Storage is never accessed with the lock held.
+type persistentAck struct {
+	client storage.Client
+	logger *zap.Logger
+	config *Config
+	cancel context.CancelFunc
+	// partitions are the loaded partitions, while lru orders all known partitions by use.
+	partitions map[string]*partition
+	lru        *list.List
+	elements   map[string]*list.Element
+	// dirty are the loaded partitions changed since the last flush, and evicted the partitions
+	// whose persisted state is still to be deleted.
+	dirty           map[string]struct{}
+	evicted         map[string]struct{}
+	id              component.ID
+	wg              sync.WaitGroup
+	mu              sync.Mutex
+	flushMu         sync.Mutex
+	partitionsDirty bool
+}
+
+func newPersistentAck(config *Config, id component.ID, logger *zap.Logger) *persistentAck {
+	return &persistentAck{
+		config:     config,
+		id:         id,
+		logger:     logger,
+		partitions: map[string]*partition{},
+		lru:        list.New(),
+		elements:   map[string]*list.Element{},
+		dirty:      map[string]struct{}{},
+		evicted:    map[string]struct{}{},
+	}
+}
+
+func (p *persistentAck) Start(ctx context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[*p.config.StorageID]
+	if !ok {
+		return fmt.Errorf("storage extension %q not found", p.config.StorageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("extension %q is not a storage extension", p.config.StorageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindExtension, p.id, "")
+	if err != nil {
+		return fmt.Errorf("failed creating storage client: %w", err)
+	}
+	data, err := client.Get(ctx, partitionsKey)
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed reading partitions: %w", err), client.Close(ctx))
+	}
+	var ids []string
+	if data != nil {
+		if err = json.Unmarshal(data, &ids); err != nil {
+			return errors.Join(fmt.Errorf("failed decoding partitions: %w", err), client.Close(ctx))
+		}
+	}
+
+	p.mu.Lock()
+	p.client = client
+	// partitions are persisted from least to most recently used
+	for _, partitionID := range ids {
+		p.elements[partitionID] = p.lru.PushFront(partitionID)
+	}
+	p.mu.Unlock()
+
+	flushCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.wg.Add(1)
+	go p.flushLoop(flushCtx)
+	return nil
+}
+
+func (p *persistentAck) Shutdown(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	if p.client == nil {
+		return nil
+	}
+	// the partitions' use order is only persisted on shutdown
+	p.mu.Lock()
+	p.partitionsDirty = true
+	p.mu.Unlock()
+	err := p.flush(ctx)
+	return errors.Join(err, p.client.Close(ctx))
+}
+
+func (p *persistentAck) flushLoop(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.flush(ctx); err != nil {
+				p.logger.Warn("Failed persisting acknowledgements", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ProcessEvent issues a new ack id for the partition.
+func (p *persistentAck) ProcessEvent(partitionID string) uint64 {
+	part := p.lockPartition(partitionID, true)
+	defer p.mu.Unlock()
+	part.nextID++
+	ackID := part.nextID
+	part.set(ackID, false)
+	part.order = append(part.order, ackID)
+	for uint64(len(part.acks)) > p.config.MaxNumPendingAcksPerPartition && len(part.order) > 0 {
+		part.remove(part.order[0])
+		part.order = part.order[1:]
+	}
+	p.dirty[partitionID] = struct{}{}
+	return ackID
+}
+
+// Ack marks the ack id of the partition as acked.
+func (p *persistentAck) Ack(partitionID string, ackID uint64) {
+	part := p.lockPartition(partitionID, false)
+	defer p.mu.Unlock()
+	if part == nil {
+		return
+	}
+	if acked, ok := part.acks[ackID]; ok && !acked {
+		part.set(ackID, true)
+		p.dirty[partitionID] = struct{}{}
+	}
+}
+
+// QueryAcks returns whether the ack ids of the partition have been acked. Acked ids are
+// removed once queried.
+func (p *persistentAck) QueryAcks(partitionID string, ackIDs []uint64) map[uint64]bool {
+	part := p.lockPartition(partitionID, false)
+	defer p.mu.Unlock()
+	result := make(map[uint64]bool, len(ackIDs))
+	if part == nil {
+		for _, ackID := range ackIDs {
+			result[ackID] = false
+		}
+		return result
+	}
+	for _, ackID := range ackIDs {
+		acked := part.acks[ackID]
+		result[ackID] = acked
+		if acked {
+			part.remove(ackID)
+			p.dirty[partitionID] = struct{}{}
+		}
+	}
+	// skipped ack ids are only compacted once they make up half of the order
+	if len(part.order) > 2*len(part.acks) {
+		order := part.order[:0]
+		for _, ackID := range part.order {
+			if _, ok := part.acks[ackID]; ok {
+				order = append(order, ackID)
+			}
+		}
+		part.order = order
+	}
+	return result
+}
+
+// lockPartition acquires the lock and returns the partition, loading it from storage if
+// needed, and marks it as most recently used. Unknown partitions are only created if create
+// is true, evicting the least recently used partition if max_number_of_partition is exceeded.
+// The lock is released while a partition is loaded, and held when lockPartition returns.
+func (p *persistentAck) lockPartition(partitionID string, create bool) *partition {
+	p.mu.Lock()
+	for {
+		if part, ok := p.partitions[partitionID]; ok {
+			p.lru.MoveToFront(p.elements[partitionID])
+			return part
+		}
+		if _, ok := p.elements[partitionID]; !ok {
+			break
+		}
+		p.mu.Unlock()
+		loaded := p.load(partitionID)
+		p.mu.Lock()
+		// the partition may have been loaded or evicted concurrently
+		if _, ok := p.partitions[partitionID]; !ok {
+			if _, known := p.elements[partitionID]; known {
+				p.partitions[partitionID] = loaded
+			}
+		}
+	}
+	if !create {
+		return nil
+	}
+	part := newPartition()
+	p.partitions[partitionID] = part
+	p.elements[partitionID] = p.lru.PushFront(partitionID)
+	for uint64(p.lru.Len()) > p.config.MaxNumPartition {
+		p.evict(p.lru.Back().Value.(string))
+	}
+	p.partitionsDirty = true
+	return part
+}
+
+// evict drops the partition, whose persisted state is deleted on the next flush. Must be
+// called with the lock held.
+func (p *persistentAck) evict(partitionID string) {
+	p.lru.Remove(p.elements[partitionID])
+	delete(p.elements, partitionID)
+	delete(p.partitions, partitionID)
+	delete(p.dirty, partitionID)
+	p.evicted[partitionID] = struct{}{}
+	p.partitionsDirty = true
+}
+
+// load reads a partition from storage, starting an empty one if it can't be read.
+func (p *persistentAck) load(partitionID string) *partition {
+	part := newPartition()
+	persisted, err := p.readPartition(context.Background(), partitionID)
+	if err != nil {
+		p.logger.Warn("Failed reading partition", zap.String("partition", partitionID), zap.Error(err))
+		return part
+	}
+	if persisted == nil {
+		return part
+	}
+	ops := make([]storage.Operation, len(persisted.Buckets))
+	for i, bucket := range persisted.Buckets {
+		ops[i] = storage.GetOperation(bucketKey(partitionID, bucket))
+	}
+	if err = p.client.Batch(context.Background(), ops...); err != nil {
+		p.logger.Warn("Failed reading partition", zap.String("partition", partitionID), zap.Error(err))
+		return part
+	}
+	part.nextID = persisted.NextID
+	for _, op := range ops {
+		if op.Value == nil {
+			continue
+		}
+		var bucket persistedBucket
+		if err = json.Unmarshal(op.Value, &bucket); err != nil {
+			p.logger.Warn("Failed decoding partition", zap.String("partition", partitionID), zap.Error(err))
+			continue
+		}
+		for _, ackID := range bucket.Pending {
+			part.set(ackID, false)
+		}
+		for _, ackID := range bucket.Acked {
+			part.set(ackID, true)
+		}
+		part.order = append(append(part.order, bucket.Pending...), bucket.Acked...)
+	}
+	// ack ids are issued in increasing order
+	slices.Sort(part.order)
+	clear(part.dirty)
+	return part
+}
+
+func (p *persistentAck) readPartition(ctx context.Context, partitionID string) (*persistedPartition, error) {
+	data, err := p.client.Get(ctx, partitionKeyPrefix+partitionID)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var persisted persistedPartition
+	if err = json.Unmarshal(data, &persisted); err != nil {
+		return nil, err
+	}
+	return &persisted, nil
+}
+
+// flush persists the changes since the last flush in a single batch. The changed buckets are
+// copied with the lock held, and encoded and written after releasing it.
+func (p *persistentAck) flush(ctx context.Context) error {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+
+	p.mu.Lock()
+	evicted := make([]string, 0, len(p.evicted))
+	for partitionID := range p.evicted {
+		evicted = append(evicted, partitionID)
+	}
+	clear(p.evicted)
+	snapshots := make([]partitionSnapshot, 0, len(p.dirty))
+	for partitionID := range p.dirty {
+		part, ok := p.partitions[partitionID]
+		if !ok {
+			continue
+		}
+		snapshot := partitionSnapshot{
+			id:        partitionID,
+			partition: persistedPartition{NextID: part.nextID, Buckets: sortedKeys(part.buckets)},
+		}
+		for bucket := range part.dirty {
+			snapshot.buckets = append(snapshot.buckets, snapshotBucket(part, partitionID, bucket))
+		}
+		clear(part.dirty)
+		snapshots = append(snapshots, snapshot)
+	}
+	clear(p.dirty)
+	var ids []string
+	if p.partitionsDirty {
+		ids = make([]string, 0, p.lru.Len())
+		for e := p.lru.Back(); e != nil; e = e.Prev() {
+			ids = append(ids, e.Value.(string))
+		}
+		p.partitionsDirty = false
+	}
+	p.mu.Unlock()
+
+	var errs error
+	var ops []storage.Operation
+	// evicted partitions are deleted first, as they may have been recreated since
+	for _, partitionID := range evicted {
+		persisted, err := p.readPartition(ctx, partitionID)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed reading evicted partition %q: %w", partitionID, err))
+		}
+		ops = append(ops, storage.DeleteOperation(partitionKeyPrefix+partitionID))
+		if persisted != nil {
+			for _, bucket := range persisted.Buckets {
+				ops = append(ops, storage.DeleteOperation(bucketKey(partitionID, bucket)))
+			}
+		}
+	}
+	for _, snapshot := range snapshots {
+		for _, bucket := range snapshot.buckets {
+			if bucket.empty {
+				ops = append(ops, storage.DeleteOperation(bucket.key))
+				continue
+			}
+			data, err := json.Marshal(bucket.bucket)
+			if err != nil {
+				errs = errors.Join(errs, err)
+				continue
+			}
+			ops = append(ops, storage.SetOperation(bucket.key, data))
+		}
+		data, err := json.Marshal(snapshot.partition)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		ops = append(ops, storage.SetOperation(partitionKeyPrefix+snapshot.id, data))
+	}
+	if ids != nil {
+		data, err := json.Marshal(ids)
+		if err != nil {
+			return errors.Join(errs, err)
+		}
+		ops = append(ops, storage.SetOperation(partitionsKey, data))
+	}
+	if len(ops) == 0 {
+		return errs
+	}
+	return errors.Join(errs, p.client.Batch(ctx, ops...))
+}
+
+// bucketSnapshot is a copy of the acks of a bucket to be written by a flush.
+type bucketSnapshot struct {
+	key    string
+	bucket persistedBucket
+	empty  bool
+}
+
+// partitionSnapshot is a copy of the changed state of a partition to be written by a flush.
+type partitionSnapshot struct {
+	id        string
+	buckets   []bucketSnapshot
+	partition persistedPartition
+}
+
+// snapshotBucket copies the acks of the bucket. Must be called with the lock held.
+func snapshotBucket(part *partition, partitionID string, bucket uint64) bucketSnapshot {
+	snapshot := bucketSnapshot{key: bucketKey(partitionID, bucket), empty: part.buckets[bucket] == 0}
+	if snapshot.empty {
+		return snapshot
+	}
+	snapshot.bucket = persistedBucket{Pending: []uint64{}, Acked: []uint64{}}
+	for ackID := bucket * bucketSize; ackID < (bucket+1)*bucketSize; ackID++ {
+		acked, ok := part.acks[ackID]
+		switch {
+		case !ok:
+		case acked:
+			snapshot.bucket.Acked = append(snapshot.bucket.Acked, ackID)
+		default:
+			snapshot.bucket.Pending = append(snapshot.bucket.Pending, ackID)
+		}
+	}
+	return snapshot
+}
+
+func bucketKey(partitionID string, bucket uint64) string {
+	return bucketKeyPrefix + strconv.FormatUint(bucket, 10) + "/" + partitionID
+}
+
+func sortedKeys(buckets map[uint64]int) []uint64 {
+	keys := make([]uint64, 0, len(buckets))
+	for bucket := range buckets {
+		keys = append(keys, bucket)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,253 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistentackextension
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+var storageID = component.MustNewID("file_storage")
+
+// memoryStorage is a storage extension whose data outlives the clients it provides.
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	data map[string][]byte
+	// access is called whenever the storage is accessed, if set.
+	access func()
+	mu     sync.Mutex
+}
+
+func (s *memoryStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return &memoryClient{storage: s}, nil
+}
+
+func (s *memoryStorage) accessed() {
+	if s.access != nil {
+		s.access()
+	}
+	s.mu.Lock()
+}
+
+type memoryClient struct {
+	storage *memoryStorage
+}
+
+func (c *memoryClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.storage.accessed()
+	defer c.storage.mu.Unlock()
+	return c.storage.data[key], nil
+}
+
+func (c *memoryClient) Set(_ context.Context, key string, value []byte) error {
+	c.storage.accessed()
+	defer c.storage.mu.Unlock()
+	c.storage.data[key] = value
+	return nil
+}
+
+func (c *memoryClient) Delete(_ context.Context, key string) error {
+	c.storage.accessed()
+	defer c.storage.mu.Unlock()
+	delete(c.storage.data, key)
+	return nil
+}
+
+func (c *memoryClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	for _, op := range ops {
+		var err error
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error {
+	return nil
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func (h storageHost) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+func newStorageHost(s *memoryStorage) component.Host {
+	return storageHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]extension.Extension{storageID: s},
+	}
+}
+
+func startPersistentAck(t *testing.T, host component.Host, maxPartitions, maxAcks uint64) *persistentAck {
+	// changes are only flushed on shutdown unless a test flushes them
+	cfg := &Config{StorageID: &storageID, MaxNumPartition: maxPartitions, MaxNumPendingAcksPerPartition: maxAcks, FlushInterval: time.Hour}
+	p := newPersistentAck(cfg, component.MustNewID(typeStr), zap.NewNop())
+	require.NoError(t, p.Start(context.Background(), host))
+	return p
+}
+
+func TestAcksSurviveRestart(t *testing.T) {
+	host := newStorageHost(&memoryStorage{data: map[string][]byte{}})
+	p := startPersistentAck(t, host, 10, 10)
+	assert.Equal(t, uint64(1), p.ProcessEvent("channel-1"))
+	assert.Equal(t, uint64(2), p.ProcessEvent("channel-1"))
+	assert.Equal(t, uint64(3), p.ProcessEvent("channel-1"))
+	assert.Equal(t, uint64(1), p.ProcessEvent("channel-2"))
+	p.Ack("channel-1", 1)
+	p.Ack("channel-1", 3)
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	p = startPersistentAck(t, host, 10, 10)
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+	assert.Equal(t, map[uint64]bool{1: true, 2: false, 3: true}, p.QueryAcks("channel-1", []uint64{1, 2, 3}))
+	// queried acks are removed
+	assert.Equal(t, map[uint64]bool{1: false, 2: false, 3: false}, p.QueryAcks("channel-1", []uint64{1, 2, 3}))
+	p.Ack("channel-1", 2)
+	assert.Equal(t, map[uint64]bool{2: true}, p.QueryAcks("channel-1", []uint64{2}))
+	// ack ids keep increasing across restarts
+	assert.Equal(t, uint64(4), p.ProcessEvent("channel-1"))
+	assert.Equal(t, uint64(2), p.ProcessEvent("channel-2"))
+	assert.Equal(t, map[uint64]bool{1: false}, p.QueryAcks("unknown", []uint64{1}))
+}
+
+func TestMaxNumPendingAcksPerPartition(t *testing.T) {
+	host := newStorageHost(&memoryStorage{data: map[string][]byte{}})
+	p := startPersistentAck(t, host, 10, 2)
+	for i := 0; i < 3; i++ {
+		p.ProcessEvent("channel")
+	}
+	p.Ack("channel", 1)
+	p.Ack("channel", 2)
+	p.Ack("channel", 3)
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	p = startPersistentAck(t, host, 10, 2)
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+	assert.Equal(t, map[uint64]bool{1: false, 2: true, 3: true}, p.QueryAcks("channel", []uint64{1, 2, 3}))
+}
+
+func TestMaxNumPartition(t *testing.T) {
+	s := &memoryStorage{data: map[string][]byte{}}
+	host := newStorageHost(s)
+	p := startPersistentAck(t, host, 2, 10)
+	p.ProcessEvent("channel-1")
+	p.ProcessEvent("channel-2")
+	// channel-2 is the least recently used once channel-1 is used again
+	p.Ack("channel-1", 1)
+	p.ProcessEvent("channel-3")
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.NotContains(t, s.data, partitionKeyPrefix+"channel-2")
+	assert.NotContains(t, s.data, bucketKey("channel-2", 0))
+
+	p = startPersistentAck(t, host, 2, 10)
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+	assert.Equal(t, map[uint64]bool{1: true}, p.QueryAcks("channel-1", []uint64{1}))
+	assert.Equal(t, map[uint64]bool{1: false}, p.QueryAcks("channel-2", []uint64{1}))
+	assert.Equal(t, uint64(2), p.ProcessEvent("channel-3"))
+	// channel-1 is evicted in turn
+	assert.Equal(t, uint64(1), p.ProcessEvent("channel-2"))
+	assert.Equal(t, map[uint64]bool{2: false}, p.QueryAcks("channel-1", []uint64{2}))
+}
+
+func TestChangesAreFlushedPerBucket(t *testing.T) {
+	s := &memoryStorage{data: map[string][]byte{}}
+	host := newStorageHost(s)
+	p := startPersistentAck(t, host, 10, 10*bucketSize)
+	for i := 0; i < 3*bucketSize; i++ {
+		p.ProcessEvent("channel")
+	}
+	// changes aren't written through
+	assert.NotContains(t, s.data, partitionKeyPrefix+"channel")
+	require.NoError(t, p.flush(context.Background()))
+	assert.Contains(t, s.data, partitionKeyPrefix+"channel")
+	for bucket := uint64(0); bucket <= 3; bucket++ {
+		assert.Contains(t, s.data, bucketKey("channel", bucket))
+	}
+
+	// only the changed bucket is rewritten
+	s.data[bucketKey("channel", 0)] = []byte("unchanged")
+	p.Ack("channel", 2*bucketSize)
+	require.NoError(t, p.flush(context.Background()))
+	assert.Equal(t, []byte("unchanged"), s.data[bucketKey("channel", 0)])
+	assert.Contains(t, string(s.data[bucketKey("channel", 2)]), `"acked":[2048]`)
+
+	// buckets without acks are deleted
+	ackIDs := make([]uint64, 0, bucketSize)
+	for ackID := uint64(1); ackID < bucketSize; ackID++ {
+		p.Ack("channel", ackID)
+		ackIDs = append(ackIDs, ackID)
+	}
+	p.QueryAcks("channel", ackIDs)
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.NotContains(t, s.data, bucketKey("channel", 0))
+
+	p = startPersistentAck(t, host, 10, 10*bucketSize)
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+	assert.Equal(t, map[uint64]bool{1: false, bucketSize: false, 2 * bucketSize: true, 3 * bucketSize: false}, p.QueryAcks("channel", []uint64{1, bucketSize, 2 * bucketSize, 3 * bucketSize}))
+	assert.Equal(t, uint64(3*bucketSize+1), p.ProcessEvent("channel"))
+}
+
+func TestStorageIsAccessedWithoutLock(t *testing.T) {
+	s := &memoryStorage{data: map[string][]byte{}}
+	host := newStorageHost(s)
+	p := startPersistentAck(t, host, 1, 10)
+	p.ProcessEvent("channel-1")
+	p.Ack("channel-1", 1)
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	p = startPersistentAck(t, host, 1, 10)
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+	s.access = func() {
+		if assert.True(t, p.mu.TryLock(), "storage accessed with the lock held") {
+			p.mu.Unlock()
+		}
+	}
+	// loads channel-1, then evicts it
+	assert.Equal(t, map[uint64]bool{1: true}, p.QueryAcks("channel-1", []uint64{1}))
+	p.ProcessEvent("channel-2")
+	require.NoError(t, p.flush(context.Background()))
+	assert.NotContains(t, s.data, partitionKeyPrefix+"channel-1")
+}
+
+func TestStartWithoutStorage(t *testing.T) {
+	cfg := &Config{StorageID: &storageID, MaxNumPartition: 1, MaxNumPendingAcksPerPartition: 1}
+	p := newPersistentAck(cfg, component.MustNewID(typeStr), zap.NewNop())
+	require.EqualError(t, p.Start(context.Background(), componenttest.NewNopHost()), `storage extension "file_storage" not found`)
+	require.NoError(t, p.Shutdown(context.Background()))
+}
@@ -0,0 +1,202 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinepauseextension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/admin"
+)
+
+const pausedPath = "/pipelines/paused"
+
+var (
+	_ PipelinePause  = (*pipelinePauseExtension)(nil)
+	_ admin.Provider = (*pipelinePauseExtension)(nil)
+)
+
+// PipelinePause provides the paused state of the pipelines to the pipeline_pause processors.
+type PipelinePause interface {
+	extension.Extension
+	// Register registers a pipeline that can be paused, by its name.
+	Register(pipeline string)
+	// Paused returns whether the pipeline is paused.
+	Paused(pipeline string) bool
+}
+
+// State is the document served by the admin endpoint, the state of the registered pipelines
+// by name.
+type State struct {
+	Pipelines map[string]PipelineState `json:"pipelines"`
+}
+
+type PipelineState struct {
+	// PausedSince is when the pipeline was paused, nil if it isn't.
+	PausedSince *time.Time `json:"paused_since,omitempty"`
+	Paused      bool       `json:"paused"`
+}
+
+// pipelinePauseExtension holds the paused state of the pipelines whose pipeline_pause processor
+// registered them, which is set through its admin endpoint.
+type pipelinePauseExtension struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	server    *http.Server
+	// pipelines are the registered pipelines by name, with the time they were paused at, zero
+	// if they aren't paused.
+	pipelines map[string]time.Time
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+}
+
+func newPipelinePauseExtension(config *Config, telemetry component.TelemetrySettings) *pipelinePauseExtension {
+	return &pipelinePauseExtension{config: config, telemetry: telemetry, pipelines: map[string]time.Time{}}
+}
+
+func (p *pipelinePauseExtension) Start(ctx context.Context, host component.Host) error {
+	if p.config.Endpoint == "" {
+		// only served by the admin extension
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(pausedPath, p.handlePaused)
+
+	var listener net.Listener
+	var err error
+	if listener, err = p.config.ServerConfig.ToListener(ctx); err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", p.config.Endpoint, err)
+	}
+	if p.server, err = p.config.ServerConfig.ToServer(ctx, host, p.telemetry, mux); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if serveErr := p.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (p *pipelinePauseExtension) Shutdown(context.Context) error {
+	var err error
+	if p.server != nil {
+		err = p.server.Close()
+	}
+	p.wg.Wait()
+	return err
+}
+
+// AdminRoutes returns the endpoints served by the admin extension.
+func (p *pipelinePauseExtension) AdminRoutes() []admin.Route {
+	return []admin.Route{
+		{Pattern: pausedPath, Operation: admin.OperationPipelines, Handler: http.HandlerFunc(p.handlePaused)},
+	}
+}
+
+func (p *pipelinePauseExtension) Register(pipeline string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.pipelines[pipeline]; !ok {
+		p.pipelines[pipeline] = time.Time{}
+	}
+}
+
+func (p *pipelinePauseExtension) Paused(pipeline string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return !p.pipelines[pipeline].IsZero()
+}
+
+// Pause pauses the registered pipeline.
+func (p *pipelinePauseExtension) Pause(pipeline string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pausedSince, ok := p.pipelines[pipeline]
+	if !ok {
+		return fmt.Errorf("unknown pipeline %q", pipeline)
+	}
+	if pausedSince.IsZero() {
+		p.pipelines[pipeline] = time.Now()
+		p.telemetry.Logger.Info("Pipeline paused", zap.String("pipeline", pipeline))
+	}
+	return nil
+}
+
+// Resume resumes the registered pipeline.
+func (p *pipelinePauseExtension) Resume(pipeline string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pausedSince, ok := p.pipelines[pipeline]
+	if !ok {
+		return fmt.Errorf("unknown pipeline %q", pipeline)
+	}
+	if !pausedSince.IsZero() {
+		p.pipelines[pipeline] = time.Time{}
+		p.telemetry.Logger.Info("Pipeline resumed", zap.String("pipeline", pipeline),
+			zap.Duration("paused_for", time.Since(pausedSince)))
+	}
+	return nil
+}
+
+func (p *pipelinePauseExtension) State() State {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	state := State{Pipelines: make(map[string]PipelineState, len(p.pipelines))}
+	for name, pausedSince := range p.pipelines {
+		ps := PipelineState{Paused: !pausedSince.IsZero()}
+		if ps.Paused {
+			ps.PausedSince = &pausedSince
+		}
+		state.Pipelines[name] = ps
+	}
+	return state
+}
+
+// handlePaused serves the state of the pipelines on GET, pauses the pipeline of the "pipeline"
+// query parameter on PUT and resumes it on DELETE.
+func (p *pipelinePauseExtension) handlePaused(w http.ResponseWriter, r *http.Request) {
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		err = p.Pause(r.URL.Query().Get("pipeline"))
+	case http.MethodDelete:
+		err = p.Resume(r.URL.Query().Get("pipeline"))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p.State())
+}
@@ -0,0 +1,125 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinepauseextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func newTestExtension(t *testing.T) *pipelinePauseExtension {
+	p := newPipelinePauseExtension(createDefaultConfig().(*Config), componenttest.NewNopTelemetrySettings())
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.Background())) })
+	p.Register("logs/debug")
+	p.Register("metrics")
+	return p
+}
+
+func TestAdminEndpoint(t *testing.T) {
+	p := newTestExtension(t)
+
+	for _, tt := range []struct {
+		expectedPaused map[string]bool
+		name           string
+		method         string
+		path           string
+		expectedCode   int
+	}{
+		{
+			name:           "initial state",
+			method:         http.MethodGet,
+			path:           "/pipelines/paused",
+			expectedCode:   http.StatusOK,
+			expectedPaused: map[string]bool{"logs/debug": false, "metrics": false},
+		},
+		{
+			name:           "pause",
+			method:         http.MethodPut,
+			path:           "/pipelines/paused?pipeline=logs/debug",
+			expectedCode:   http.StatusOK,
+			expectedPaused: map[string]bool{"logs/debug": true, "metrics": false},
+		},
+		{
+			name:           "pause again",
+			method:         http.MethodPut,
+			path:           "/pipelines/paused?pipeline=logs/debug",
+			expectedCode:   http.StatusOK,
+			expectedPaused: map[string]bool{"logs/debug": true, "metrics": false},
+		},
+		{name: "pause unknown pipeline", method: http.MethodPut, path: "/pipelines/paused?pipeline=traces", expectedCode: http.StatusBadRequest},
+		{name: "resume unknown pipeline", method: http.MethodDelete, path: "/pipelines/paused", expectedCode: http.StatusBadRequest},
+		{
+			name:           "resume",
+			method:         http.MethodDelete,
+			path:           "/pipelines/paused?pipeline=logs/debug",
+			expectedCode:   http.StatusOK,
+			expectedPaused: map[string]bool{"logs/debug": false, "metrics": false},
+		},
+		{name: "invalid method", method: http.MethodPost, path: "/pipelines/paused", expectedCode: http.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			p.handlePaused(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			require.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var state State
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+			paused := map[string]bool{}
+			for name, ps := range state.Pipelines {
+				paused[name] = ps.Paused
+				assert.Equal(t, ps.Paused, ps.PausedSince != nil)
+				assert.Equal(t, ps.Paused, p.Paused(name))
+			}
+			assert.Equal(t, tt.expectedPaused, paused)
+		})
+	}
+}
+
+func TestPausedSince(t *testing.T) {
+	p := newTestExtension(t)
+
+	require.NoError(t, p.Pause("metrics"))
+	pausedSince := p.State().Pipelines["metrics"].PausedSince
+	require.NotNil(t, pausedSince)
+	// pausing a paused pipeline keeps the time it was paused at
+	require.NoError(t, p.Pause("metrics"))
+	assert.Equal(t, pausedSince, p.State().Pipelines["metrics"].PausedSince)
+
+	// registering a paused pipeline again, e.g. from another processor, doesn't resume it
+	p.Register("metrics")
+	assert.True(t, p.Paused("metrics"))
+	assert.False(t, p.Paused("unregistered"))
+}
+
+func TestAdminRoutes(t *testing.T) {
+	p := newTestExtension(t)
+
+	routes := p.AdminRoutes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, pausedPath, routes[0].Pattern)
+	rec := httptest.NewRecorder()
+	routes[0].Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/pipelines/paused?pipeline=metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, p.Paused("metrics"))
+}
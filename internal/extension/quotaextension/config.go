@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaextension
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the quotas of the namespaces and the interval they apply to.
+type Config struct {
+	// Quotas are the limits of specific namespaces, replacing the default limits.
+	Quotas map[string]Limits `mapstructure:"quotas"`
+	// Default are the limits of each namespace without specific quota.
+	Default Limits `mapstructure:"default"`
+	// Interval is the period of the quotas. The usage of all namespaces is reset at each
+	// multiple of the interval.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Limits are the maximum usage of a namespace per interval. Zero limits are unlimited.
+type Limits struct {
+	// Bytes is the maximum uncompressed OTLP size of the telemetry of all signals.
+	Bytes int64 `mapstructure:"bytes"`
+	// DataPoints is the maximum number of metric data points.
+	DataPoints int64 `mapstructure:"data_points"`
+	// LogRecords is the maximum number of log records.
+	LogRecords int64 `mapstructure:"log_records"`
+	// Spans is the maximum number of spans.
+	Spans int64 `mapstructure:"spans"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Interval <= 0 {
+		errs = errors.Join(errs, errors.New("interval must be positive"))
+	}
+	errs = errors.Join(errs, cfg.Default.validate("default"))
+	namespaces := make([]string, 0, len(cfg.Quotas))
+	for namespace := range cfg.Quotas {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		limits := cfg.Quotas[namespace]
+		errs = errors.Join(errs, limits.validate("quotas::"+namespace))
+	}
+	return errs
+}
+
+func (l *Limits) validate(path string) error {
+	var errs error
+	for _, limit := range []struct {
+		name  string
+		value int64
+	}{
+		{name: "bytes", value: l.Bytes},
+		{name: "data_points", value: l.DataPoints},
+		{name: "log_records", value: l.LogRecords},
+		{name: "spans", value: l.Spans},
+	} {
+		if limit.value < 0 {
+			errs = errors.Join(errs, fmt.Errorf("%s::%s must not be negative", path, limit.name))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,188 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaextension
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const scopeName = "github.com/signalfx/splunk-otel-collector/internal/extension/quotaextension"
+
+var _ Quota = (*quotaExtension)(nil)
+
+// Quota accounts the telemetry of namespaces to their quotas, for the quota processors.
+type Quota interface {
+	extension.Extension
+	// Admit accounts the items of the signal and their bytes to the quota of the namespace, and
+	// returns whether they're within it. Items exceeding the quota aren't accounted.
+	Admit(ctx context.Context, namespace string, signal pipeline.Signal, items, bytes int64) bool
+}
+
+// quotaExtension holds the usage of the namespaces in the current interval, shared by the quota
+// processors of all pipelines.
+type quotaExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	window        time.Time
+	config        *Config
+	now           func() time.Time
+	usage         map[string]*Limits
+	itemsAccepted metric.Int64Counter
+	itemsExceeded metric.Int64Counter
+	bytesAccepted metric.Int64Counter
+	bytesExceeded metric.Int64Counter
+	mu            sync.Mutex
+}
+
+func newQuotaExtension(config *Config, set component.TelemetrySettings) (*quotaExtension, error) {
+	q := &quotaExtension{config: config, now: time.Now, usage: map[string]*Limits{}}
+
+	meterProvider := set.MeterProvider
+	if set.LeveledMeterProvider != nil {
+		meterProvider = set.LeveledMeterProvider(configtelemetry.LevelBasic)
+	}
+	meter := meterProvider.Meter(scopeName)
+	var err error
+	if q.itemsAccepted, err = meter.Int64Counter(
+		"namespace_quota_items_accepted",
+		metric.WithDescription("Number of data points, log records and spans within the quota of their namespace."),
+		metric.WithUnit("{items}"),
+	); err != nil {
+		return nil, err
+	}
+	if q.itemsExceeded, err = meter.Int64Counter(
+		"namespace_quota_items_exceeded",
+		metric.WithDescription("Number of data points, log records and spans exceeding the quota of their namespace."),
+		metric.WithUnit("{items}"),
+	); err != nil {
+		return nil, err
+	}
+	if q.bytesAccepted, err = meter.Int64Counter(
+		"namespace_quota_bytes_accepted",
+		metric.WithDescription("Uncompressed OTLP size of the telemetry within the quota of its namespace."),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, err
+	}
+	if q.bytesExceeded, err = meter.Int64Counter(
+		"namespace_quota_bytes_exceeded",
+		metric.WithDescription("Uncompressed OTLP size of the telemetry exceeding the quota of its namespace."),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, err
+	}
+	if _, err = meter.Float64ObservableGauge(
+		"namespace_quota_utilization",
+		metric.WithDescription("Highest ratio of the usage of a namespace in the current interval to its limits."),
+		metric.WithUnit("1"),
+		metric.WithFloat64Callback(q.observeUtilization),
+	); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// limits returns the limits of the namespace.
+func (q *quotaExtension) limits(namespace string) Limits {
+	if limits, ok := q.config.Quotas[namespace]; ok {
+		return limits
+	}
+	return q.config.Default
+}
+
+func (q *quotaExtension) Admit(ctx context.Context, namespace string, signal pipeline.Signal, items, bytes int64) bool {
+	limits := q.limits(namespace)
+
+	q.mu.Lock()
+	q.rotate()
+	u, ok := q.usage[namespace]
+	if !ok {
+		u = &Limits{}
+		q.usage[namespace] = u
+	}
+	admitted := within(limits.Bytes, u.Bytes, bytes) && within(*limits.items(signal), *u.items(signal), items)
+	if admitted {
+		u.Bytes += bytes
+		*u.items(signal) += items
+	}
+	q.mu.Unlock()
+
+	attrs := metric.WithAttributes(attribute.String("namespace", namespace), attribute.String("signal", signal.String()))
+	if admitted {
+		q.itemsAccepted.Add(ctx, items, attrs)
+		q.bytesAccepted.Add(ctx, bytes, attrs)
+	} else {
+		q.itemsExceeded.Add(ctx, items, attrs)
+		q.bytesExceeded.Add(ctx, bytes, attrs)
+	}
+	return admitted
+}
+
+// rotate resets the usage of all namespaces when a new interval starts, which also stops
+// tracking the namespaces that stopped sending. Must be called with the lock held.
+func (q *quotaExtension) rotate() {
+	if window := q.now().Truncate(q.config.Interval); !window.Equal(q.window) {
+		q.window = window
+		clear(q.usage)
+	}
+}
+
+func (q *quotaExtension) observeUtilization(_ context.Context, observer metric.Float64Observer) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rotate()
+	for namespace, u := range q.usage {
+		limits := q.limits(namespace)
+		var utilization float64
+		for _, l := range []struct{ used, limit int64 }{
+			{used: u.Bytes, limit: limits.Bytes},
+			{used: u.DataPoints, limit: limits.DataPoints},
+			{used: u.LogRecords, limit: limits.LogRecords},
+			{used: u.Spans, limit: limits.Spans},
+		} {
+			if l.limit > 0 {
+				utilization = max(utilization, float64(l.used)/float64(l.limit))
+			}
+		}
+		observer.Observe(utilization, metric.WithAttributes(attribute.String("namespace", namespace)))
+	}
+	return nil
+}
+
+// items returns the limit, or usage, of the items of the signal.
+func (l *Limits) items(signal pipeline.Signal) *int64 {
+	switch signal {
+	case pipeline.SignalMetrics:
+		return &l.DataPoints
+	case pipeline.SignalLogs:
+		return &l.LogRecords
+	default:
+		return &l.Spans
+	}
+}
+
+// within returns whether n more is within the limit, zero being unlimited.
+func within(limit, used, n int64) bool {
+	return limit == 0 || used+n <= limit
+}
@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaextension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+func newTestExtension(t *testing.T, cfg *Config) (*quotaExtension, *time.Time) {
+	q, err := newQuotaExtension(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	var now *time.Time
+	q.now, now = testclock.New(time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC))
+	return q, now
+}
+
+func TestAdmit(t *testing.T) {
+	q, now := newTestExtension(t, &Config{
+		Interval: time.Minute,
+		Default:  Limits{Bytes: 1000, LogRecords: 10},
+		Quotas:   map[string]Limits{"payments": {LogRecords: 20}},
+	})
+	ctx := context.Background()
+
+	assert.True(t, q.Admit(ctx, "checkout", pipeline.SignalLogs, 8, 100))
+	// exceeding items aren't accounted, so smaller batches are still admitted
+	assert.False(t, q.Admit(ctx, "checkout", pipeline.SignalLogs, 3, 100))
+	assert.True(t, q.Admit(ctx, "checkout", pipeline.SignalLogs, 2, 100))
+	assert.False(t, q.Admit(ctx, "checkout", pipeline.SignalLogs, 1, 1))
+	// the item limits are per signal, and the bytes limit for all signals
+	assert.True(t, q.Admit(ctx, "checkout", pipeline.SignalMetrics, 1000, 700))
+	assert.False(t, q.Admit(ctx, "checkout", pipeline.SignalTraces, 1, 200))
+
+	// each namespace has its own quota
+	assert.True(t, q.Admit(ctx, "search", pipeline.SignalLogs, 10, 100))
+	assert.True(t, q.Admit(ctx, "payments", pipeline.SignalLogs, 20, 100_000))
+	assert.False(t, q.Admit(ctx, "payments", pipeline.SignalLogs, 1, 1))
+
+	// the usage is reset at the next interval
+	*now = now.Add(30 * time.Second)
+	assert.True(t, q.Admit(ctx, "checkout", pipeline.SignalLogs, 10, 1000))
+}
+
+type fakeObserver struct {
+	metric.Float64Observer
+	values map[string]float64
+}
+
+func (f *fakeObserver) Observe(value float64, opts ...metric.ObserveOption) {
+	attrs := metric.NewObserveConfig(opts).Attributes()
+	namespace, _ := attrs.Value("namespace")
+	f.values[namespace.AsString()] = value
+}
+
+func TestObserveUtilization(t *testing.T) {
+	q, now := newTestExtension(t, &Config{
+		Interval: time.Minute,
+		Default:  Limits{Bytes: 1000, Spans: 10},
+		Quotas:   map[string]Limits{"unlimited": {}},
+	})
+	ctx := context.Background()
+	require.True(t, q.Admit(ctx, "checkout", pipeline.SignalTraces, 5, 100))
+	require.True(t, q.Admit(ctx, "search", pipeline.SignalTraces, 1, 800))
+	require.True(t, q.Admit(ctx, "unlimited", pipeline.SignalTraces, 100, 100_000))
+
+	observer := &fakeObserver{values: map[string]float64{}}
+	require.NoError(t, q.observeUtilization(ctx, observer))
+	assert.Equal(t, map[string]float64{"checkout": 0.5, "search": 0.8, "unlimited": 0}, observer.values)
+
+	*now = now.Add(time.Minute)
+	observer = &fakeObserver{values: map[string]float64{}}
+	require.NoError(t, q.observeUtilization(ctx, observer))
+	assert.Empty(t, observer.values)
+}
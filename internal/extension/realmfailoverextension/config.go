@@ -0,0 +1,77 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realmfailoverextension
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines where and when the requests of the exporters using this extension
+// as their authenticator fail over.
+type Config struct {
+	// FallbackRealms are the realms whose endpoints are used, in order, when the primary
+	// realm endpoint returns sustained 5xx responses or errors.
+	FallbackRealms []string `mapstructure:"fallback_realms"`
+	// FallbackEndpoints are the scheme and host of endpoints, e.g. https://gateway:4318,
+	// used in order after the fallback realms.
+	FallbackEndpoints []string `mapstructure:"fallback_endpoints"`
+	// FailureThreshold is the number of consecutive 5xx responses or request errors after
+	// which requests fail over to the next fallback.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// RecoveryInterval is how long requests are sent to a fallback before the primary
+	// endpoint is tried again.
+	RecoveryInterval time.Duration `mapstructure:"recovery_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.FallbackRealms) == 0 && len(cfg.FallbackEndpoints) == 0 {
+		errs = errors.Join(errs, errors.New("at least one of fallback_realms or fallback_endpoints must be specified"))
+	}
+	for _, realm := range cfg.FallbackRealms {
+		if realm == "" {
+			errs = errors.Join(errs, errors.New("fallback_realms must not contain empty realms"))
+		}
+	}
+	if _, err := cfg.fallbackEndpoints(); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	if cfg.FailureThreshold <= 0 {
+		errs = errors.Join(errs, errors.New("failure_threshold must be positive"))
+	}
+	if cfg.RecoveryInterval <= 0 {
+		errs = errors.Join(errs, errors.New("recovery_interval must be positive"))
+	}
+	return errs
+}
+
+func (cfg *Config) fallbackEndpoints() ([]*url.URL, error) {
+	var endpoints []*url.URL
+	for _, endpoint := range cfg.FallbackEndpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid fallback endpoint %q: must be of the form <scheme>://<host>[:<port>]", endpoint)
+		}
+		endpoints = append(endpoints, u)
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realmfailoverextension
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/auth"
+
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
+)
+
+const typeStr = "realm_failover"
+
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		createExtension,
+		component.StabilityLevelDevelopment,
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		FailureThreshold: 5,
+		RecoveryInterval: 5 * time.Minute,
+	}
+}
+
+// createExtension creates a client authenticator that doesn't authenticate requests
+// but sends them to the configured fallbacks when their primary endpoint fails.
+func createExtension(_ context.Context, _ extension.Settings, cfg component.Config) (extension.Extension, error) {
+	oCfg := cfg.(*Config)
+	endpoints, err := oCfg.fallbackEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	failoverCfg := realm.FailoverConfig{
+		FallbackRealms:    oCfg.FallbackRealms,
+		FallbackEndpoints: endpoints,
+		FailureThreshold:  oCfg.FailureThreshold,
+		RecoveryInterval:  oCfg.RecoveryInterval,
+	}
+	return auth.NewClient(
+		auth.WithClientRoundTripper(func(base http.RoundTripper) (http.RoundTripper, error) {
+			return realm.NewFailoverRoundTripper(base, failoverCfg), nil
+		}),
+	), nil
+}
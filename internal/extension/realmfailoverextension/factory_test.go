@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realmfailoverextension
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/auth"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+	assert.Equal(t, typeStr, f.Type().String())
+	require.NoError(t, componenttest.CheckConfigStruct(f.CreateDefaultConfig()))
+}
+
+func TestCreateExtension(t *testing.T) {
+	var requests int
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fallback.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.FallbackEndpoints = []string{fallback.URL}
+	cfg.FailureThreshold = 1
+
+	ext, err := NewFactory().Create(context.Background(), extensiontest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	client, ok := ext.(auth.Client)
+	require.True(t, ok)
+	rt, err := client.RoundTripper(http.DefaultTransport)
+	require.NoError(t, err)
+	require.IsType(t, &realm.FailoverRoundTripper{}, rt)
+
+	// the unreachable primary fails over to the fallback endpoint
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:1/v2/datapoint", http.NoBody)
+		require.NoError(t, err)
+		if resp, err := rt.RoundTrip(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+	assert.Equal(t, 1, requests)
+}
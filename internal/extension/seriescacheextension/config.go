@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seriescacheextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines how much per-series state is retained and where it's persisted.
+type Config struct {
+	// StorageID is the storage extension the entries are persisted in. Without it, the entries
+	// are only kept in memory.
+	StorageID *component.ID `mapstructure:"storage"`
+	// MaxEntries is the maximum number of entries per namespace. The least recently used entry
+	// of a namespace is dropped when exceeded.
+	MaxEntries int `mapstructure:"max_entries"`
+	// TTL is the duration after which entries that weren't read or written are dropped.
+	TTL time.Duration `mapstructure:"ttl"`
+	// FlushInterval is how often changes are written to storage and expired entries dropped.
+	// Changes made since the last flush are lost if the collector stops without shutting down.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.MaxEntries <= 0 {
+		errs = errors.Join(errs, errors.New("max_entries must be positive"))
+	}
+	if cfg.TTL <= 0 {
+		errs = errors.Join(errs, errors.New("ttl must be positive"))
+	}
+	if cfg.FlushInterval <= 0 {
+		errs = errors.Join(errs, errors.New("flush_interval must be positive"))
+	}
+	return errs
+}
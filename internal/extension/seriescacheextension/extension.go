@@ -0,0 +1,443 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seriescacheextension
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	namespacesKey  = "namespaces"
+	indexKeyPrefix = "index/"
+	entryKeyPrefix = "entry/"
+	// loadBatchSize is the number of entries read from storage in a single batch on start.
+	loadBatchSize = 1000
+)
+
+var _ SeriesCache = (*seriesCache)(nil)
+
+// SeriesCache holds the per-series state of components, like the metadata or start timestamps of
+// series, in namespaces bounded in size and optionally persisted in a storage extension, so
+// components don't each keep an unbounded map of their own that is lost on restarts.
+type SeriesCache interface {
+	extension.Extension
+	// Namespace returns the cache of the namespace. Components use namespaces of their own, like
+	// their component id, so their keys don't collide.
+	Namespace(name string) Cache
+}
+
+// Cache is the cache of a namespace. It's safe for concurrent use.
+type Cache interface {
+	// Get returns the value of the key and whether it's cached.
+	Get(key string) ([]byte, bool)
+	// Set sets the value of the key. The value must not be modified afterward.
+	Set(key string, value []byte)
+	// Delete removes the key.
+	Delete(key string)
+}
+
+type entry struct {
+	lastUsed time.Time
+	key      string
+	value    []byte
+}
+
+// namespace is the cache of a namespace, whose entries are ordered by use.
+type namespace struct {
+	cache   *seriesCache
+	entries map[string]*list.Element
+	lru     *list.List
+	// dirty are the keys set since the last flush, and removed the keys deleted, evicted or
+	// expired since. They're only tracked if the entries are persisted.
+	dirty        map[string]struct{}
+	removed      map[string]struct{}
+	name         string
+	mu           sync.Mutex
+	persisted    bool
+	indexChanged bool
+}
+
+// seriesCache is the SeriesCache of the extension. The entries of all namespaces are loaded from
+// storage on start, and changes are written to storage in a single batch every flush_interval
+// and on shutdown. Storage is never accessed with a lock of the namespaces held.
+type seriesCache struct {
+	client     storage.Client
+	logger     *zap.Logger
+	config     *Config
+	now        func() time.Time
+	cancel     context.CancelFunc
+	namespaces map[string]*namespace
+	id         component.ID
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	flushMu    sync.Mutex
+	// namespacesChanged is whether namespaces were added since the last flush.
+	namespacesChanged bool
+}
+
+func newSeriesCache(config *Config, id component.ID, logger *zap.Logger) *seriesCache {
+	return &seriesCache{
+		config:     config,
+		id:         id,
+		logger:     logger,
+		now:        time.Now,
+		namespaces: map[string]*namespace{},
+	}
+}
+
+func (c *seriesCache) Start(ctx context.Context, host component.Host) error {
+	if c.config.StorageID != nil {
+		client, err := c.storageClient(ctx, host)
+		if err != nil {
+			return err
+		}
+		if err = c.load(ctx, client); err != nil {
+			return errors.Join(err, client.Close(ctx))
+		}
+		c.client = client
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.wg.Add(1)
+	go c.flushLoop(loopCtx)
+	return nil
+}
+
+func (c *seriesCache) Shutdown(ctx context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	if c.client == nil {
+		return nil
+	}
+	err := c.flush(ctx)
+	return errors.Join(err, c.client.Close(ctx))
+}
+
+func (c *seriesCache) storageClient(ctx context.Context, host component.Host) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*c.config.StorageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", c.config.StorageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", c.config.StorageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindExtension, c.id, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed creating storage client: %w", err)
+	}
+	return client, nil
+}
+
+func (c *seriesCache) Namespace(name string) Cache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.namespaceLocked(name)
+}
+
+func (c *seriesCache) namespaceLocked(name string) *namespace {
+	if ns, ok := c.namespaces[name]; ok {
+		return ns
+	}
+	ns := &namespace{
+		cache:     c,
+		name:      name,
+		entries:   map[string]*list.Element{},
+		lru:       list.New(),
+		dirty:     map[string]struct{}{},
+		removed:   map[string]struct{}{},
+		persisted: c.config.StorageID != nil,
+	}
+	c.namespaces[name] = ns
+	c.namespacesChanged = true
+	return ns
+}
+
+func (ns *namespace) Get(key string) ([]byte, bool) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	elem, ok := ns.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	now := ns.cache.now()
+	if now.Sub(e.lastUsed) > ns.cache.config.TTL {
+		ns.remove(elem)
+		return nil, false
+	}
+	e.lastUsed = now
+	ns.lru.MoveToFront(elem)
+	return e.value, true
+}
+
+func (ns *namespace) Set(key string, value []byte) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	now := ns.cache.now()
+	if elem, ok := ns.entries[key]; ok {
+		e := elem.Value.(*entry)
+		e.value, e.lastUsed = value, now
+		ns.lru.MoveToFront(elem)
+	} else {
+		ns.entries[key] = ns.lru.PushFront(&entry{key: key, value: value, lastUsed: now})
+		ns.indexChanged = true
+	}
+	if ns.persisted {
+		ns.dirty[key] = struct{}{}
+		delete(ns.removed, key)
+	}
+	for ns.lru.Len() > ns.cache.config.MaxEntries {
+		ns.remove(ns.lru.Back())
+	}
+}
+
+func (ns *namespace) Delete(key string) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	if elem, ok := ns.entries[key]; ok {
+		ns.remove(elem)
+	}
+}
+
+// remove removes the entry of the element. It must be called with the lock held.
+func (ns *namespace) remove(elem *list.Element) {
+	key := elem.Value.(*entry).key
+	ns.lru.Remove(elem)
+	delete(ns.entries, key)
+	ns.indexChanged = true
+	if ns.persisted {
+		delete(ns.dirty, key)
+		ns.removed[key] = struct{}{}
+	}
+}
+
+// expire removes the entries that weren't used within the ttl.
+func (ns *namespace) expire(now time.Time) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	for elem := ns.lru.Back(); elem != nil && now.Sub(elem.Value.(*entry).lastUsed) > ns.cache.config.TTL; elem = ns.lru.Back() {
+		ns.remove(elem)
+	}
+}
+
+// entryKey returns the storage key of the entry of the namespace, prefixed with the length of the
+// namespace so namespaces and keys containing separators don't collide.
+func entryKey(namespace, key string) string {
+	return entryKeyPrefix + strconv.Itoa(len(namespace)) + "/" + namespace + "/" + key
+}
+
+// load reads the persisted entries, from the least to the most recently used of each namespace.
+// Entries are used as of the start, so those the components don't use again expire a ttl later.
+func (c *seriesCache) load(ctx context.Context, client storage.Client) error {
+	data, err := client.Get(ctx, namespacesKey)
+	if err != nil {
+		return fmt.Errorf("failed reading namespaces: %w", err)
+	}
+	var names []string
+	if data != nil {
+		if err = json.Unmarshal(data, &names); err != nil {
+			return fmt.Errorf("failed decoding namespaces: %w", err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for _, name := range names {
+		ns := c.namespaceLocked(name)
+		if data, err = client.Get(ctx, indexKeyPrefix+name); err != nil {
+			return fmt.Errorf("failed reading the index of namespace %q: %w", name, err)
+		}
+		var keys []string
+		if data != nil {
+			if err = json.Unmarshal(data, &keys); err != nil {
+				return fmt.Errorf("failed decoding the index of namespace %q: %w", name, err)
+			}
+		}
+		for len(keys) > 0 {
+			batch := keys[:min(loadBatchSize, len(keys))]
+			keys = keys[len(batch):]
+			ops := make([]storage.Operation, len(batch))
+			for i, key := range batch {
+				ops[i] = storage.GetOperation(entryKey(name, key))
+			}
+			if err = client.Batch(ctx, ops...); err != nil {
+				return fmt.Errorf("failed reading the entries of namespace %q: %w", name, err)
+			}
+			for i, op := range ops {
+				if op.Value == nil {
+					ns.indexChanged = true
+					continue
+				}
+				ns.entries[batch[i]] = ns.lru.PushFront(&entry{key: batch[i], value: op.Value, lastUsed: now})
+			}
+		}
+		for ns.lru.Len() > c.config.MaxEntries {
+			ns.remove(ns.lru.Back())
+		}
+	}
+	c.namespacesChanged = false
+	return nil
+}
+
+func (c *seriesCache) flushLoop(ctx context.Context) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := c.now()
+			for _, ns := range c.snapshot() {
+				ns.expire(now)
+			}
+			if c.client == nil {
+				continue
+			}
+			if err := c.flush(ctx); err != nil {
+				c.logger.Warn("Failed persisting series cache", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (c *seriesCache) snapshot() []*namespace {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	namespaces := make([]*namespace, 0, len(c.namespaces))
+	for _, ns := range c.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces
+}
+
+// changes are the changes of a namespace taken by a flush, restored if writing them failed.
+type changes struct {
+	ns           *namespace
+	dirty        map[string]struct{}
+	removed      map[string]struct{}
+	indexChanged bool
+}
+
+// flush persists the changes since the last flush in a single batch. The changes of each
+// namespace are encoded with its lock held, and written after releasing it.
+func (c *seriesCache) flush(ctx context.Context) error {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	var ops []storage.Operation
+	c.mu.Lock()
+	namespacesChanged := c.namespacesChanged
+	if namespacesChanged {
+		names := make([]string, 0, len(c.namespaces))
+		for name := range c.namespaces {
+			names = append(names, name)
+		}
+		data, err := json.Marshal(names)
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		ops = append(ops, storage.SetOperation(namespacesKey, data))
+		c.namespacesChanged = false
+	}
+	c.mu.Unlock()
+
+	var taken []changes
+	for _, ns := range c.snapshot() {
+		nsOps, ch, err := ns.takeChanges()
+		if err != nil {
+			return err
+		}
+		ops = append(ops, nsOps...)
+		taken = append(taken, ch)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := c.client.Batch(ctx, ops...); err != nil {
+		// the changes are retried on the next flush, unless they're superseded by then
+		c.mu.Lock()
+		c.namespacesChanged = c.namespacesChanged || namespacesChanged
+		c.mu.Unlock()
+		for _, ch := range taken {
+			ch.restore()
+		}
+		return err
+	}
+	return nil
+}
+
+// takeChanges returns the operations persisting the changes of the namespace since the last
+// flush, and the changes taken.
+func (ns *namespace) takeChanges() ([]storage.Operation, changes, error) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	ops := make([]storage.Operation, 0, len(ns.dirty)+len(ns.removed)+1)
+	for key := range ns.dirty {
+		ops = append(ops, storage.SetOperation(entryKey(ns.name, key), ns.entries[key].Value.(*entry).value))
+	}
+	for key := range ns.removed {
+		ops = append(ops, storage.DeleteOperation(entryKey(ns.name, key)))
+	}
+	if ns.indexChanged {
+		// the index lists the keys from the least to the most recently used
+		keys := make([]string, 0, ns.lru.Len())
+		for elem := ns.lru.Back(); elem != nil; elem = elem.Prev() {
+			keys = append(keys, elem.Value.(*entry).key)
+		}
+		data, err := json.Marshal(keys)
+		if err != nil {
+			return nil, changes{}, err
+		}
+		ops = append(ops, storage.SetOperation(indexKeyPrefix+ns.name, data))
+	}
+	ch := changes{ns: ns, dirty: ns.dirty, removed: ns.removed, indexChanged: ns.indexChanged}
+	ns.dirty, ns.removed, ns.indexChanged = map[string]struct{}{}, map[string]struct{}{}, false
+	return ops, ch, nil
+}
+
+func (ch changes) restore() {
+	ns := ch.ns
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	for key := range ch.dirty {
+		if _, ok := ns.entries[key]; ok {
+			ns.dirty[key] = struct{}{}
+		}
+	}
+	for key := range ch.removed {
+		if _, ok := ns.entries[key]; !ok {
+			ns.removed[key] = struct{}{}
+		}
+	}
+	ns.indexChanged = ns.indexChanged || ch.indexChanged
+}
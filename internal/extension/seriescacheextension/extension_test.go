@@ -0,0 +1,243 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seriescacheextension
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+var (
+	storageID = component.MustNewID("file_storage")
+	epoch     = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// memoryStorage is a storage extension whose data outlives the clients it provides.
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	data map[string][]byte
+	err  error
+	mu   sync.Mutex
+}
+
+func (s *memoryStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return &memoryClient{storage: s}, nil
+}
+
+func (s *memoryStorage) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+type memoryClient struct {
+	storage *memoryStorage
+}
+
+func (c *memoryClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	return c.storage.data[key], c.storage.err
+}
+
+func (c *memoryClient) Set(_ context.Context, key string, value []byte) error {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	c.storage.data[key] = value
+	return c.storage.err
+}
+
+func (c *memoryClient) Delete(_ context.Context, key string) error {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	delete(c.storage.data, key)
+	return c.storage.err
+}
+
+func (c *memoryClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	c.storage.mu.Lock()
+	err := c.storage.err
+	c.storage.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error {
+	return nil
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func (h storageHost) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+// startSeriesCache starts a series cache persisted in the storage, if any, whose clock is set
+// to the returned time.
+func startSeriesCache(t *testing.T, s *memoryStorage, maxEntries int) (*seriesCache, *time.Time) {
+	// changes are only flushed on shutdown unless a test flushes them
+	cfg := &Config{MaxEntries: maxEntries, TTL: time.Hour, FlushInterval: time.Hour}
+	host := componenttest.NewNopHost()
+	if s != nil {
+		cfg.StorageID = &storageID
+		host = storageHost{Host: host, extensions: map[component.ID]extension.Extension{storageID: s}}
+	}
+	c := newSeriesCache(cfg, component.MustNewID(typeStr), zap.NewNop())
+	now := epoch
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Start(context.Background(), host))
+	return c, &now
+}
+
+func get(ns Cache, key string) string {
+	value, ok := ns.Get(key)
+	if !ok {
+		return "<missing>"
+	}
+	return string(value)
+}
+
+func TestNamespaces(t *testing.T) {
+	c, _ := startSeriesCache(t, nil, 10)
+	defer func() { require.NoError(t, c.Shutdown(context.Background())) }()
+
+	metadata := c.Namespace("prometheus/metadata")
+	starts := c.Namespace("prometheus/start_timestamps")
+	assert.Same(t, metadata, c.Namespace("prometheus/metadata"))
+	metadata.Set("http_requests", []byte("counter"))
+	starts.Set("http_requests", []byte("1700000000"))
+	assert.Equal(t, "counter", get(metadata, "http_requests"))
+	assert.Equal(t, "1700000000", get(starts, "http_requests"))
+
+	metadata.Set("http_requests", []byte("gauge"))
+	assert.Equal(t, "gauge", get(metadata, "http_requests"))
+	metadata.Delete("http_requests")
+	assert.Equal(t, "<missing>", get(metadata, "http_requests"))
+	assert.Equal(t, "1700000000", get(starts, "http_requests"))
+}
+
+func TestEviction(t *testing.T) {
+	c, now := startSeriesCache(t, nil, 2)
+	defer func() { require.NoError(t, c.Shutdown(context.Background())) }()
+
+	ns := c.Namespace("ns")
+	ns.Set("a", []byte("1"))
+	ns.Set("b", []byte("2"))
+	// reading a makes b the least recently used entry
+	assert.Equal(t, "1", get(ns, "a"))
+	ns.Set("c", []byte("3"))
+	assert.Equal(t, "<missing>", get(ns, "b"))
+	assert.Equal(t, "1", get(ns, "a"))
+	assert.Equal(t, "3", get(ns, "c"))
+
+	*now = now.Add(30 * time.Minute)
+	assert.Equal(t, "1", get(ns, "a"))
+	*now = now.Add(45 * time.Minute)
+	// c wasn't used for longer than the ttl
+	assert.Equal(t, "<missing>", get(ns, "c"))
+	c.namespaces["ns"].expire(now.Add(time.Hour))
+	assert.Equal(t, 0, c.namespaces["ns"].lru.Len())
+}
+
+func TestEntriesSurviveRestart(t *testing.T) {
+	s := &memoryStorage{data: map[string][]byte{}}
+	c, _ := startSeriesCache(t, s, 2)
+	c.Namespace("a/b").Set("c", []byte("1"))
+	c.Namespace("a").Set("b/c", []byte("2"))
+	ns := c.Namespace("ns")
+	ns.Set("x", []byte("3"))
+	ns.Set("y", []byte("4"))
+	ns.Delete("x")
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	c, _ = startSeriesCache(t, s, 2)
+	assert.Equal(t, "1", get(c.Namespace("a/b"), "c"))
+	assert.Equal(t, "<missing>", get(c.Namespace("a/b"), "b/c"))
+	assert.Equal(t, "2", get(c.Namespace("a"), "b/c"))
+	ns = c.Namespace("ns")
+	assert.Equal(t, "<missing>", get(ns, "x"))
+	assert.Equal(t, "4", get(ns, "y"))
+	// the entries are loaded in the order they were used
+	ns.Set("z", []byte("5"))
+	ns.Set("w", []byte("6"))
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	c, _ = startSeriesCache(t, s, 1)
+	ns = c.Namespace("ns")
+	assert.Equal(t, "<missing>", get(ns, "z"))
+	assert.Equal(t, "6", get(ns, "w"))
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Equal(t, []byte(`["w"]`), s.data[indexKeyPrefix+"ns"])
+	assert.NotContains(t, s.data, entryKey("ns", "y"))
+	assert.NotContains(t, s.data, entryKey("ns", "z"))
+}
+
+func TestFailedFlushIsRetried(t *testing.T) {
+	s := &memoryStorage{data: map[string][]byte{}}
+	c, _ := startSeriesCache(t, s, 10)
+	ns := c.Namespace("ns")
+	ns.Set("a", []byte("1"))
+	ns.Set("b", []byte("2"))
+
+	s.setErr(errors.New("disk full"))
+	require.EqualError(t, c.flush(context.Background()), "disk full")
+	assert.Empty(t, s.data)
+
+	ns.Delete("b")
+	s.setErr(nil)
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Equal(t, map[string][]byte{
+		namespacesKey:         []byte(`["ns"]`),
+		indexKeyPrefix + "ns": []byte(`["a"]`),
+		entryKey("ns", "a"):   []byte("1"),
+	}, s.data)
+}
+
+func TestStartWithoutStorage(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StorageID = &storageID
+	c := newSeriesCache(cfg, component.MustNewID(typeStr), zap.NewNop())
+	require.EqualError(t, c.Start(context.Background(), componenttest.NewNopHost()), `storage extension "file_storage" not found`)
+}
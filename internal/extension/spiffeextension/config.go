@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffeextension
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the Workload API the X.509 SVID is fetched from and the files it's written to.
+type Config struct {
+	// Endpoint is the `unix://` or `tcp://` address of the SPIFFE Workload API, by default the
+	// one of the SPIFFE_ENDPOINT_SOCKET environment variable.
+	Endpoint string `mapstructure:"endpoint"`
+	// SPIFFEID selects the SVID with this SPIFFE ID when the workload is entitled to several,
+	// instead of the first one.
+	SPIFFEID string `mapstructure:"spiffe_id"`
+	// CertFile is the file the certificate chain of the SVID is written to.
+	CertFile string `mapstructure:"cert_file"`
+	// KeyFile is the file the private key of the SVID is written to.
+	KeyFile string `mapstructure:"key_file"`
+	// CAFile is the file the trust bundle of the SVID's trust domain is written to.
+	CAFile string `mapstructure:"ca_file"`
+	// Timeout is the maximum duration the extension waits for the first SVID when starting.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Endpoint != "" && !strings.HasPrefix(cfg.Endpoint, "unix://") && !strings.HasPrefix(cfg.Endpoint, "tcp://") {
+		errs = append(errs, fmt.Errorf("invalid endpoint %q: must start with unix:// or tcp://", cfg.Endpoint))
+	}
+	if cfg.SPIFFEID != "" && !strings.HasPrefix(cfg.SPIFFEID, "spiffe://") {
+		errs = append(errs, fmt.Errorf("invalid spiffe_id %q: must start with spiffe://", cfg.SPIFFEID))
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		errs = append(errs, errors.New("cert_file and key_file must be specified"))
+	}
+	if cfg.Timeout <= 0 {
+		errs = append(errs, errors.New("timeout must be positive"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,239 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffeextension
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	endpointEnvVar = "SPIFFE_ENDPOINT_SOCKET"
+	minRetryDelay  = time.Second
+	maxRetryDelay  = 30 * time.Second
+)
+
+// spiffeExtension fetches the X.509 SVID of the collector from the SPIFFE Workload API and
+// writes it to files, which the TLS configs of the collector's components load. The Workload
+// API pushes the rotated SVIDs, so the files are rewritten before the previous SVID expires.
+type spiffeExtension struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	conn      *grpc.ClientConn
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	// lastWritten is the PEM encoding of the files last written, to only rewrite them on rotations.
+	lastWritten []byte
+}
+
+func newSPIFFEExtension(config *Config, telemetry component.TelemetrySettings) *spiffeExtension {
+	return &spiffeExtension{config: config, telemetry: telemetry}
+}
+
+func (s *spiffeExtension) Start(ctx context.Context, _ component.Host) error {
+	endpoint := s.config.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv(endpointEnvVar)
+	}
+	target, err := grpcTarget(endpoint)
+	if err != nil {
+		return err
+	}
+	if s.conn, err = grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+		return fmt.Errorf("failed to create Workload API client: %w", err)
+	}
+
+	var runCtx context.Context
+	runCtx, s.cancel = context.WithCancel(context.Background())
+	written := make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(runCtx, endpoint, written)
+	}()
+
+	timer := time.NewTimer(s.config.Timeout)
+	defer timer.Stop()
+	select {
+	case <-written:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("no X.509 SVID received from the Workload API at %s within %s", endpoint, s.config.Timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *spiffeExtension) Shutdown(context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// run streams the SVIDs of the workload and writes them, restarting the stream with an
+// exponential backoff when it fails, e.g. while the SPIRE agent restarts. written is closed once
+// the first SVID is written.
+func (s *spiffeExtension) run(ctx context.Context, endpoint string, written chan struct{}) {
+	delay := minRetryDelay
+	for {
+		err := fetchX509SVIDs(ctx, s.conn, func(resp *x509SVIDResponse) error {
+			if err := s.write(resp); err != nil {
+				return err
+			}
+			if written != nil {
+				close(written)
+				written = nil
+			}
+			delay = minRetryDelay
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		s.telemetry.Logger.Warn("Failed to fetch X.509 SVID from the Workload API, retrying",
+			zap.String("endpoint", endpoint), zap.Duration("retry_delay", delay), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// write writes the certificate chain, private key and trust bundle of the selected SVID of the
+// response to the configured files in PEM format.
+func (s *spiffeExtension) write(resp *x509SVIDResponse) error {
+	svid, err := s.selectSVID(resp)
+	if err != nil {
+		return err
+	}
+	certs, err := x509.ParseCertificates(svid.certs)
+	if err == nil && len(certs) == 0 {
+		err = errors.New("no certificate")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid certificates of X.509 SVID %q: %w", svid.spiffeID, err)
+	}
+	bundle, err := x509.ParseCertificates(svid.bundle)
+	if err != nil {
+		return fmt.Errorf("invalid trust bundle of X.509 SVID %q: %w", svid.spiffeID, err)
+	}
+	certPEM := encodeCertificates(certs)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: svid.key})
+	if _, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return fmt.Errorf("invalid private key of X.509 SVID %q: %w", svid.spiffeID, err)
+	}
+	bundlePEM := encodeCertificates(bundle)
+
+	all := bytes.Join([][]byte{certPEM, keyPEM, bundlePEM}, nil)
+	if bytes.Equal(all, s.lastWritten) {
+		return nil
+	}
+	// the key is written first, so a component reloading the files in between loads the new
+	// certificate with the new key, or the previous certificate which is still valid
+	if err = writeFile(s.config.KeyFile, keyPEM, 0o600); err != nil {
+		return err
+	}
+	if err = writeFile(s.config.CertFile, certPEM, 0o644); err != nil {
+		return err
+	}
+	if s.config.CAFile != "" {
+		if err = writeFile(s.config.CAFile, bundlePEM, 0o644); err != nil {
+			return err
+		}
+	}
+	s.lastWritten = all
+	s.telemetry.Logger.Info("Wrote X.509 SVID",
+		zap.String("spiffe_id", svid.spiffeID), zap.Time("expires_at", certs[0].NotAfter))
+	return nil
+}
+
+func (s *spiffeExtension) selectSVID(resp *x509SVIDResponse) (x509SVID, error) {
+	if s.config.SPIFFEID == "" {
+		return resp.svids[0], nil
+	}
+	for _, svid := range resp.svids {
+		if svid.spiffeID == s.config.SPIFFEID {
+			return svid, nil
+		}
+	}
+	return x509SVID{}, fmt.Errorf("the workload isn't entitled to an X.509 SVID with SPIFFE ID %q", s.config.SPIFFEID)
+}
+
+func encodeCertificates(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+// writeFile replaces the file by renaming a temporary file, so the file is never read partially
+// written.
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// grpcTarget returns the gRPC target of the Workload API address.
+func grpcTarget(endpoint string) (string, error) {
+	switch {
+	case endpoint == "":
+		return "", fmt.Errorf("endpoint must be specified when the %s environment variable isn't set", endpointEnvVar)
+	case strings.HasPrefix(endpoint, "unix://"):
+		return endpoint, nil
+	case strings.HasPrefix(endpoint, "tcp://"):
+		return "passthrough:///" + strings.TrimPrefix(endpoint, "tcp://"), nil
+	}
+	return "", fmt.Errorf("invalid endpoint %q: must start with unix:// or tcp://", endpoint)
+}
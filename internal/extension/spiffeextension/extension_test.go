@@ -0,0 +1,281 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffeextension
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// rawCodec passes the encoded messages of the fake Workload API through.
+type rawCodec struct{}
+
+func (rawCodec) Name() string { return "proto" }
+
+func (rawCodec) Marshal(v any) ([]byte, error) { return v.([]byte), nil }
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	*v.(*[]byte) = data
+	return nil
+}
+
+type testSVID struct {
+	spiffeID string
+	cert     *x509.Certificate
+	certDER  []byte
+	keyDER   []byte
+}
+
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"SPIFFE"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return ca, key
+}
+
+func newTestSVID(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, spiffeID string, serial int64) testSVID {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id, err := url.Parse(spiffeID)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{id},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return testSVID{spiffeID: spiffeID, cert: cert, certDER: certDER, keyDER: keyDER}
+}
+
+func encodeResponse(ca *x509.Certificate, svids ...testSVID) []byte {
+	var resp []byte
+	for _, svid := range svids {
+		var msg []byte
+		msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+		msg = protowire.AppendString(msg, svid.spiffeID)
+		msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, svid.certDER)
+		msg = protowire.AppendTag(msg, 3, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, svid.keyDER)
+		msg = protowire.AppendTag(msg, 4, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, ca.Raw)
+		// unknown fields are skipped
+		msg = protowire.AppendTag(msg, 6, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, 1)
+		resp = protowire.AppendTag(resp, 1, protowire.BytesType)
+		resp = protowire.AppendBytes(resp, msg)
+	}
+	return resp
+}
+
+// startWorkloadAPI serves a fake Workload API on a unix socket, streaming the responses of the
+// channel to each FetchX509SVID call, or failing the call with the error of the channel.
+func startWorkloadAPI(t *testing.T, responses <-chan []byte, errs <-chan error) string {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}), grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		if method, _ := grpc.MethodFromServerStream(stream); method != fetchX509SVIDMethod {
+			return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+		}
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if len(md.Get(workloadAPIHeader)) == 0 {
+			return status.Error(codes.InvalidArgument, "security header missing from request")
+		}
+		var req []byte
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		for {
+			select {
+			case resp := <-responses:
+				if err := stream.SendMsg(resp); err != nil {
+					return err
+				}
+			case err := <-errs:
+				return err
+			case <-stream.Context().Done():
+				return nil
+			}
+		}
+	}))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return "unix://" + socket
+}
+
+func newTestExtension(t *testing.T, endpoint string) *spiffeExtension {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.CertFile = filepath.Join(dir, "svid.pem")
+	cfg.KeyFile = filepath.Join(dir, "svid_key.pem")
+	cfg.CAFile = filepath.Join(dir, "bundle.pem")
+	cfg.Timeout = 5 * time.Second
+	s := newSPIFFEExtension(cfg, componenttest.NewNopTelemetrySettings())
+	t.Cleanup(func() { require.NoError(t, s.Shutdown(context.Background())) })
+	return s
+}
+
+func readCertificate(t *testing.T, path string) *x509.Certificate {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestWritesRotatedSVIDs(t *testing.T) {
+	ca, caKey := newTestCA(t)
+	responses := make(chan []byte, 1)
+	s := newTestExtension(t, startWorkloadAPI(t, responses, nil))
+
+	first := newTestSVID(t, ca, caKey, "spiffe://example.org/otel-collector", 2)
+	responses <- encodeResponse(ca, first)
+	require.NoError(t, s.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.Equal(t, first.cert, readCertificate(t, s.config.CertFile))
+	assert.Equal(t, ca, readCertificate(t, s.config.CAFile))
+	_, err := tls.LoadX509KeyPair(s.config.CertFile, s.config.KeyFile)
+	require.NoError(t, err)
+	info, err := os.Stat(s.config.KeyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	rotated := newTestSVID(t, ca, caKey, "spiffe://example.org/otel-collector", 3)
+	responses <- encodeResponse(ca, rotated)
+	require.Eventually(t, func() bool {
+		return readCertificate(t, s.config.CertFile).Equal(rotated.cert)
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = tls.LoadX509KeyPair(s.config.CertFile, s.config.KeyFile)
+	require.NoError(t, err)
+}
+
+func TestSelectsSPIFFEID(t *testing.T) {
+	ca, caKey := newTestCA(t)
+	responses := make(chan []byte, 2)
+	s := newTestExtension(t, startWorkloadAPI(t, responses, nil))
+	s.config.SPIFFEID = "spiffe://example.org/gateway"
+
+	collector := newTestSVID(t, ca, caKey, "spiffe://example.org/otel-collector", 2)
+	gateway := newTestSVID(t, ca, caKey, "spiffe://example.org/gateway", 3)
+	responses <- encodeResponse(ca, collector, gateway)
+	require.NoError(t, s.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, gateway.cert, readCertificate(t, s.config.CertFile))
+}
+
+func TestRetriesFailedStreams(t *testing.T) {
+	ca, caKey := newTestCA(t)
+	responses := make(chan []byte, 1)
+	errs := make(chan error, 1)
+	errs <- status.Error(codes.PermissionDenied, "no identity issued")
+	s := newTestExtension(t, startWorkloadAPI(t, responses, errs))
+
+	svid := newTestSVID(t, ca, caKey, "spiffe://example.org/otel-collector", 2)
+	go func() {
+		// only sent once the first stream failed
+		for len(errs) > 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		responses <- encodeResponse(ca, svid)
+	}()
+	require.NoError(t, s.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, svid.cert, readCertificate(t, s.config.CertFile))
+}
+
+func TestStartTimeout(t *testing.T) {
+	s := newTestExtension(t, startWorkloadAPI(t, nil, nil))
+	s.config.Timeout = 100 * time.Millisecond
+	err := s.Start(context.Background(), componenttest.NewNopHost())
+	require.ErrorContains(t, err, "no X.509 SVID received from the Workload API at unix://")
+	_, err = os.Stat(s.config.CertFile)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStartEndpointFromEnv(t *testing.T) {
+	ca, caKey := newTestCA(t)
+	responses := make(chan []byte, 1)
+	t.Setenv(endpointEnvVar, startWorkloadAPI(t, responses, nil))
+	s := newTestExtension(t, "")
+
+	responses <- encodeResponse(ca, newTestSVID(t, ca, caKey, "spiffe://example.org/otel-collector", 2))
+	require.NoError(t, s.Start(context.Background(), componenttest.NewNopHost()))
+}
+
+func TestGRPCTarget(t *testing.T) {
+	for _, tt := range []struct {
+		endpoint    string
+		expected    string
+		expectedErr string
+	}{
+		{endpoint: "unix:///run/spire/sockets/agent.sock", expected: "unix:///run/spire/sockets/agent.sock"},
+		{endpoint: "tcp://127.0.0.1:8081", expected: "passthrough:///127.0.0.1:8081"},
+		{endpoint: "", expectedErr: "endpoint must be specified when the SPIFFE_ENDPOINT_SOCKET environment variable isn't set"},
+		{endpoint: "/run/spire/sockets/agent.sock", expectedErr: `invalid endpoint "/run/spire/sockets/agent.sock": must start with unix:// or tcp://`},
+	} {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			target, err := grpcTarget(tt.endpoint)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, target)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffeextension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	fetchX509SVIDMethod = "/SpiffeWorkloadAPI/FetchX509SVID"
+	// workloadAPIHeader must be sent with all Workload API calls, so the Workload API can
+	// tell them apart from requests forwarded by proxies, e.g. a server side request forgery.
+	workloadAPIHeader = "workload.spiffe.io"
+)
+
+// x509SVIDRequest is the empty request of the FetchX509SVID call.
+type x509SVIDRequest struct{}
+
+// x509SVIDResponse is the subset of the X509SVIDResponse message of the Workload API the
+// extension uses.
+type x509SVIDResponse struct {
+	svids []x509SVID
+}
+
+// x509SVID is an X509SVID message of the Workload API, with the ASN.1 DER encoded certificate
+// chain, PKCS#8 private key and trust bundle of the SVID.
+type x509SVID struct {
+	spiffeID string
+	certs    []byte
+	key      []byte
+	bundle   []byte
+}
+
+// codec encodes the messages of the FetchX509SVID call in the protobuf wire format, which saves
+// depending on the generated Workload API code for the two messages the extension uses.
+type codec struct{}
+
+func (codec) Name() string {
+	return "proto"
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	if _, ok := v.(*x509SVIDRequest); !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return nil, nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	resp, ok := v.(*x509SVIDResponse)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	resp.svids = nil
+	return consumeFields(data, func(num protowire.Number, value []byte) error {
+		if num != 1 {
+			return nil
+		}
+		var svid x509SVID
+		err := consumeFields(value, func(num protowire.Number, value []byte) error {
+			switch num {
+			case 1:
+				svid.spiffeID = string(value)
+			case 2:
+				svid.certs = value
+			case 3:
+				svid.key = value
+			case 4:
+				svid.bundle = value
+			}
+			return nil
+		})
+		resp.svids = append(resp.svids, svid)
+		return err
+	})
+}
+
+// consumeFields calls fn with the number and value of the length delimited fields of the
+// message, skipping the fields of other wire types.
+func consumeFields(data []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, data); n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := fn(num, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchX509SVIDs streams the X.509 SVIDs of the workload, calling update with each response,
+// until the stream fails or ctx is done.
+func fetchX509SVIDs(ctx context.Context, conn *grpc.ClientConn, update func(*x509SVIDResponse) error) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, workloadAPIHeader, "true")
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, fetchX509SVIDMethod, grpc.ForceCodec(codec{}))
+	if err != nil {
+		return err
+	}
+	if err = stream.SendMsg(&x509SVIDRequest{}); err != nil {
+		return err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		resp := &x509SVIDResponse{}
+		if err = stream.RecvMsg(resp); err != nil {
+			return err
+		}
+		if len(resp.svids) == 0 {
+			return errors.New("the workload isn't entitled to any X.509 SVID")
+		}
+		if err = update(resp); err != nil {
+			return err
+		}
+	}
+}
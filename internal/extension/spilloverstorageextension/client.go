@@ -0,0 +1,272 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spilloverstorageextension
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// diskSizeKey is the key the total size of the spilled over items is persisted under.
+	diskSizeKey = "spillover_disk_size"
+	// spilled over items are prefixed with the marker and the unix nano time they were stored at.
+	// Protobuf encoded data never starts with the marker, so items stored without the
+	// extension are still returned as is.
+	itemMarker     = 0x01
+	itemHeaderSize = 9
+	mib            = 1 << 20
+)
+
+var errDiskLimit = errors.New("spillover storage disk limit reached")
+
+var _ storage.Client = (*spilloverClient)(nil)
+
+type memoryItem struct {
+	storedAt time.Time
+	value    []byte
+}
+
+// spilloverClient keeps the items of a persistent queue in memory until their total size reaches
+// the memory limit, and spills further items over to the wrapped storage client. The queue's own
+// metadata, like its read and write indexes, is always written to the wrapped client. Items kept
+// in memory are written to the wrapped client when the client is closed.
+type spilloverClient struct {
+	disk        storage.Client
+	now         func() time.Time
+	logger      *zap.Logger
+	memory      map[string]memoryItem
+	diskSizes   map[string]int64
+	memoryLimit int64
+	diskLimit   int64
+	maxAge      time.Duration
+	memoryBytes int64
+	diskBytes   int64
+	mu          sync.Mutex
+}
+
+func newSpilloverClient(ctx context.Context, disk storage.Client, cfg *Config, logger *zap.Logger) (*spilloverClient, error) {
+	c := &spilloverClient{
+		disk:        disk,
+		now:         time.Now,
+		logger:      logger,
+		memory:      map[string]memoryItem{},
+		diskSizes:   map[string]int64{},
+		memoryLimit: cfg.MemoryLimitMiB * mib,
+		diskLimit:   cfg.DiskLimitMiB * mib,
+		maxAge:      cfg.MaxAge,
+	}
+	size, err := disk.Get(ctx, diskSizeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading spilled over size: %w", err)
+	}
+	if size != nil {
+		if c.diskBytes, err = strconv.ParseInt(string(size), 10, 64); err != nil {
+			return nil, fmt.Errorf("failed decoding spilled over size: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func (c *spilloverClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *spilloverClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *spilloverClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+// Batch applies the operations on items kept in memory directly and the remaining ones in a
+// single batch of the wrapped client.
+func (c *spilloverClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	diskBytes := c.diskBytes
+	diskSizes := map[string]int64{}
+	var diskOps, diskGets []storage.Operation
+	for _, op := range ops {
+		if !isItemKey(op.Key) {
+			diskOps = append(diskOps, op)
+			continue
+		}
+		switch op.Type {
+		case storage.Get:
+			if item, ok := c.memory[op.Key]; ok {
+				op.Value = nil
+				if !c.expired(item.storedAt, now) {
+					op.Value = item.value
+				}
+				continue
+			}
+			diskOps = append(diskOps, op)
+			diskGets = append(diskGets, op)
+		case storage.Set:
+			size := int64(len(op.Value))
+			if item, ok := c.memory[op.Key]; ok {
+				c.memoryBytes -= int64(len(item.value))
+				delete(c.memory, op.Key)
+			}
+			if c.memoryBytes+size <= c.memoryLimit {
+				c.memory[op.Key] = memoryItem{storedAt: now, value: op.Value}
+				c.memoryBytes += size
+				continue
+			}
+			if diskBytes+size > c.diskLimit {
+				return errDiskLimit
+			}
+			diskBytes += size
+			diskSizes[op.Key] = size
+			diskOps = append(diskOps, storage.SetOperation(op.Key, encodeItem(op.Value, now)))
+		case storage.Delete:
+			if item, ok := c.memory[op.Key]; ok {
+				c.memoryBytes -= int64(len(item.value))
+				delete(c.memory, op.Key)
+				continue
+			}
+			size, err := c.diskSize(ctx, op.Key)
+			if err != nil {
+				return err
+			}
+			diskBytes -= size
+			diskSizes[op.Key] = 0
+			diskOps = append(diskOps, op)
+		}
+	}
+	if len(diskOps) == 0 {
+		return nil
+	}
+
+	if diskBytes != c.diskBytes {
+		diskOps = append(diskOps, storage.SetOperation(diskSizeKey, []byte(strconv.FormatInt(diskBytes, 10))))
+	}
+	if err := c.disk.Batch(ctx, diskOps...); err != nil {
+		return err
+	}
+	c.diskBytes = diskBytes
+	for key, size := range diskSizes {
+		if size == 0 {
+			delete(c.diskSizes, key)
+		} else {
+			c.diskSizes[key] = size
+		}
+	}
+	for _, op := range diskGets {
+		if op.Value == nil {
+			continue
+		}
+		value, storedAt := decodeItem(op.Value)
+		c.diskSizes[op.Key] = int64(len(value))
+		op.Value = nil
+		if !c.expired(storedAt, now) {
+			op.Value = value
+		}
+	}
+	return nil
+}
+
+// Close writes the items kept in memory to the wrapped client and closes it. Items exceeding
+// the disk limit are dropped.
+func (c *spilloverClient) Close(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.memory))
+	for key := range c.memory {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	diskBytes := c.diskBytes
+	var ops []storage.Operation
+	dropped := 0
+	for _, key := range keys {
+		item := c.memory[key]
+		size := int64(len(item.value))
+		if diskBytes+size > c.diskLimit {
+			dropped++
+			continue
+		}
+		diskBytes += size
+		ops = append(ops, storage.SetOperation(key, encodeItem(item.value, item.storedAt)))
+	}
+	if dropped > 0 {
+		c.logger.Warn("Dropped queue items kept in memory exceeding the disk limit", zap.Int("dropped", dropped))
+	}
+	var err error
+	if len(ops) > 0 {
+		ops = append(ops, storage.SetOperation(diskSizeKey, []byte(strconv.FormatInt(diskBytes, 10))))
+		if err = c.disk.Batch(ctx, ops...); err != nil {
+			err = fmt.Errorf("failed writing queue items kept in memory: %w", err)
+		}
+	}
+	c.memory, c.memoryBytes = map[string]memoryItem{}, 0
+	return errors.Join(err, c.disk.Close(ctx))
+}
+
+// diskSize returns the size of the spilled over item, reading it if it was spilled over
+// before the client was created. Must be called with the lock held.
+func (c *spilloverClient) diskSize(ctx context.Context, key string) (int64, error) {
+	if size, ok := c.diskSizes[key]; ok {
+		return size, nil
+	}
+	value, err := c.disk.Get(ctx, key)
+	if err != nil || value == nil {
+		return 0, err
+	}
+	value, _ = decodeItem(value)
+	return int64(len(value)), nil
+}
+
+func (c *spilloverClient) expired(storedAt, now time.Time) bool {
+	return c.maxAge > 0 && !storedAt.IsZero() && now.Sub(storedAt) > c.maxAge
+}
+
+// isItemKey returns whether the key is one of a persistent queue's items, which are keyed by
+// their index.
+func isItemKey(key string) bool {
+	_, err := strconv.ParseUint(key, 10, 64)
+	return err == nil
+}
+
+func encodeItem(value []byte, storedAt time.Time) []byte {
+	encoded := make([]byte, itemHeaderSize+len(value))
+	encoded[0] = itemMarker
+	binary.BigEndian.PutUint64(encoded[1:itemHeaderSize], uint64(storedAt.UnixNano()))
+	copy(encoded[itemHeaderSize:], value)
+	return encoded
+}
+
+func decodeItem(encoded []byte) ([]byte, time.Time) {
+	if len(encoded) < itemHeaderSize || encoded[0] != itemMarker {
+		return encoded, time.Time{}
+	}
+	return encoded[itemHeaderSize:], time.Unix(0, int64(binary.BigEndian.Uint64(encoded[1:itemHeaderSize])))
+}
@@ -0,0 +1,167 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spilloverstorageextension
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+var storageID = component.MustNewID("file_storage")
+
+// memoryStorage is a storage extension whose data outlives the clients it provides.
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	data map[string][]byte
+	mu   sync.Mutex
+}
+
+func (s *memoryStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return &memoryClient{storage: s}, nil
+}
+
+type memoryClient struct {
+	storage *memoryStorage
+}
+
+func (c *memoryClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	return c.storage.data[key], nil
+}
+
+func (c *memoryClient) Set(_ context.Context, key string, value []byte) error {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	c.storage.data[key] = value
+	return nil
+}
+
+func (c *memoryClient) Delete(_ context.Context, key string) error {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	delete(c.storage.data, key)
+	return nil
+}
+
+func (c *memoryClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	for _, op := range ops {
+		var err error
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error {
+	return nil
+}
+
+func newTestClient(t *testing.T, disk *memoryStorage) (*spilloverClient, *time.Time) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StorageID = &storageID
+	cfg.MaxAge = time.Minute
+	diskClient, err := disk.GetClient(context.Background(), component.KindExporter, component.MustNewID("signalfx"), "")
+	require.NoError(t, err)
+	client, err := newSpilloverClient(context.Background(), diskClient, cfg, zap.NewNop())
+	require.NoError(t, err)
+	client.memoryLimit = 10
+	client.diskLimit = 20
+	var now *time.Time
+	client.now, now = testclock.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return client, now
+}
+
+func TestSpillOver(t *testing.T) {
+	ctx := context.Background()
+	disk := &memoryStorage{data: map[string][]byte{}}
+	client, _ := newTestClient(t, disk)
+
+	require.NoError(t, client.Batch(ctx, storage.SetOperation("wi", []byte("1")), storage.SetOperation("0", []byte("first!"))))
+	// queue metadata is always written to disk, items only once the memory limit is reached
+	assert.Equal(t, map[string][]byte{"wi": []byte("1")}, disk.data)
+
+	require.NoError(t, client.Set(ctx, "1", []byte("second")))
+	assert.Equal(t, "6", string(disk.data[diskSizeKey]))
+	assert.Equal(t, encodeItem([]byte("second"), client.now()), disk.data["1"])
+
+	require.ErrorIs(t, client.Set(ctx, "2", []byte("too large for disk")), errDiskLimit)
+
+	// spilled over items are returned without their header
+	getFirst, getSecond := storage.GetOperation("0"), storage.GetOperation("1")
+	require.NoError(t, client.Batch(ctx, getFirst, getSecond))
+	assert.Equal(t, "first!", string(getFirst.Value))
+	assert.Equal(t, "second", string(getSecond.Value))
+
+	require.NoError(t, client.Delete(ctx, "0"))
+	require.NoError(t, client.Delete(ctx, "1"))
+	assert.Zero(t, client.memoryBytes)
+	assert.Equal(t, "0", string(disk.data[diskSizeKey]))
+	assert.NotContains(t, disk.data, "1")
+}
+
+func TestMaxAge(t *testing.T) {
+	ctx := context.Background()
+	disk := &memoryStorage{data: map[string][]byte{"0": []byte("stored without the extension")}}
+	client, now := newTestClient(t, disk)
+	require.NoError(t, client.Set(ctx, "1", []byte("memory!!")))
+	require.NoError(t, client.Set(ctx, "2", []byte("disk")))
+	require.Contains(t, disk.data, "2")
+
+	*now = now.Add(2 * time.Minute)
+	for key, expected := range map[string][]byte{"0": []byte("stored without the extension"), "1": nil, "2": nil} {
+		value, err := client.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, key)
+	}
+}
+
+func TestCloseWritesMemoryItems(t *testing.T) {
+	ctx := context.Background()
+	disk := &memoryStorage{data: map[string][]byte{}}
+	client, _ := newTestClient(t, disk)
+	require.NoError(t, client.Set(ctx, "0", []byte("memory")))
+	require.NoError(t, client.Set(ctx, "1", []byte("also")))
+	require.NoError(t, client.Close(ctx))
+	assert.Equal(t, "10", string(disk.data[diskSizeKey]))
+
+	client, _ = newTestClient(t, disk)
+	assert.Equal(t, int64(10), client.diskBytes)
+	value, err := client.Get(ctx, "0")
+	require.NoError(t, err)
+	assert.Equal(t, "memory", string(value))
+	require.NoError(t, client.Delete(ctx, "0"))
+	assert.Equal(t, int64(4), client.diskBytes)
+}
@@ -0,0 +1,54 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spilloverstorageextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+type Config struct {
+	// StorageID is the storage extension queue items spill over to.
+	StorageID *component.ID `mapstructure:"storage"`
+	// MemoryLimitMiB is the maximum size of the items each client keeps in memory before spilling
+	// over to storage. 0 spills all items over.
+	MemoryLimitMiB int64 `mapstructure:"memory_limit_mib"`
+	// DiskLimitMiB is the maximum size of the items each client spills over to storage. Items
+	// exceeding it are rejected.
+	DiskLimitMiB int64 `mapstructure:"disk_limit_mib"`
+	// MaxAge is the age after which items are discarded instead of being returned. 0 disables it.
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.StorageID == nil {
+		errs = errors.Join(errs, errors.New("storage must be specified"))
+	}
+	if cfg.MemoryLimitMiB < 0 {
+		errs = errors.Join(errs, errors.New("memory_limit_mib must not be negative"))
+	}
+	if cfg.DiskLimitMiB <= 0 {
+		errs = errors.Join(errs, errors.New("disk_limit_mib must be positive"))
+	}
+	if cfg.MaxAge < 0 {
+		errs = errors.Join(errs, errors.New("max_age must not be negative"))
+	}
+	return errs
+}
@@ -0,0 +1,72 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spilloverstorageextension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+var _ storage.Extension = (*spilloverStorage)(nil)
+
+// spilloverStorage is a storage extension whose clients keep the items of exporter persistent
+// queues in memory, spilling them over to another storage extension only once their memory
+// limit is reached.
+type spilloverStorage struct {
+	storage storage.Extension
+	logger  *zap.Logger
+	config  *Config
+}
+
+func newSpilloverStorage(config *Config, logger *zap.Logger) *spilloverStorage {
+	return &spilloverStorage{config: config, logger: logger}
+}
+
+func (s *spilloverStorage) Start(_ context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[*s.config.StorageID]
+	if !ok {
+		return fmt.Errorf("storage extension %q not found", s.config.StorageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("extension %q is not a storage extension", s.config.StorageID)
+	}
+	s.storage = storageExt
+	return nil
+}
+
+func (s *spilloverStorage) Shutdown(context.Context) error {
+	return nil
+}
+
+func (s *spilloverStorage) GetClient(ctx context.Context, kind component.Kind, id component.ID, name string) (storage.Client, error) {
+	if s.storage == nil {
+		return nil, fmt.Errorf("storage extension %q isn't started", s.config.StorageID)
+	}
+	disk, err := s.storage.GetClient(ctx, kind, id, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating storage client: %w", err)
+	}
+	client, err := newSpilloverClient(ctx, disk, s.config, s.logger.With(zap.String("client", id.String())))
+	if err != nil {
+		return nil, errors.Join(err, disk.Close(ctx))
+	}
+	return client, nil
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spilloverstorageextension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+	assert.Equal(t, typeStr, f.Type().String())
+	require.NoError(t, componenttest.CheckConfigStruct(f.CreateDefaultConfig()))
+}
+
+type host struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func (h host) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+func TestCreateExtension(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StorageID = &storageID
+	ext, err := NewFactory().Create(context.Background(), extensiontest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	storageExt, ok := ext.(storage.Extension)
+	require.True(t, ok)
+
+	disk := &memoryStorage{data: map[string][]byte{}}
+	require.NoError(t, ext.Start(context.Background(), host{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]extension.Extension{storageID: disk},
+	}))
+	client, err := storageExt.GetClient(context.Background(), component.KindExporter, component.MustNewID("signalfx"), "")
+	require.NoError(t, err)
+	require.NoError(t, client.Set(context.Background(), "0", []byte("item")))
+	require.NoError(t, client.Close(context.Background()))
+	assert.Contains(t, disk.data, "0")
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func TestStartWithoutStorage(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StorageID = &storageID
+	ext, err := NewFactory().Create(context.Background(), extensiontest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.EqualError(t, ext.Start(context.Background(), host{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]extension.Extension{storageID: nopExtension{}},
+	}), `extension "file_storage" is not a storage extension`)
+}
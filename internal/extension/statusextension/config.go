@@ -0,0 +1,47 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusextension
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the endpoint the status document is served on.
+type Config struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+	// TelemetryEndpoint is the url of the collector's own Prometheus metrics, which the depths
+	// of the exporter queues are read from. Empty doesn't report them.
+	TelemetryEndpoint string `mapstructure:"telemetry_endpoint"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("endpoint must not be empty"))
+	}
+	if cfg.TelemetryEndpoint != "" {
+		if u, err := url.Parse(cfg.TelemetryEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = errors.Join(errs, fmt.Errorf("telemetry_endpoint %q must be an http or https url", cfg.TelemetryEndpoint))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,342 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusextension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/status"
+)
+
+const (
+	statusPath  = "/status"
+	metricsPath = "/status/metrics"
+	// smartAgentType is the type of the receivers running Smart Agent monitors.
+	smartAgentType = "smartagent"
+	// telemetryTimeout bounds reading the collector's own metrics.
+	telemetryTimeout = 5 * time.Second
+)
+
+var _ componentstatus.Watcher = (*statusExtension)(nil)
+
+// ComponentStatus is the latest status reported by a component.
+type ComponentStatus struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ExporterQueue is the depth of the sending queue of an exporter for a signal.
+type ExporterQueue struct {
+	Exporter string `json:"exporter"`
+	DataType string `json:"data_type"`
+	Size     int64  `json:"size"`
+	Capacity int64  `json:"capacity"`
+}
+
+// Document is the status document.
+type Document struct {
+	Timestamp time.Time                              `json:"timestamp"`
+	Discovery map[string][]status.DiscoveredEndpoint `json:"discovery"`
+	// Components are the statuses of all components, and Monitors the ones of the receivers
+	// running Smart Agent monitors.
+	Components     []ComponentStatus `json:"components"`
+	Monitors       []ComponentStatus `json:"monitors"`
+	ExporterQueues []ExporterQueue   `json:"exporter_queues"`
+	// Errors are the errors collecting parts of the document, which are left out.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// componentState is the latest status of a component, and whether it's a receiver running a
+// Smart Agent monitor.
+type componentState struct {
+	ComponentStatus
+	monitor bool
+}
+
+// statusExtension serves a status document of the component statuses, the Smart Agent monitor
+// states, the endpoints found by discovery receivers and the depths of the exporter queues, in
+// JSON and in the Prometheus text format, for the Splunk Technical Add-on and fleet tools.
+type statusExtension struct {
+	config     *Config
+	telemetry  component.TelemetrySettings
+	server     *http.Server
+	client     *http.Client
+	components map[string]componentState
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+}
+
+func newStatusExtension(config *Config, telemetry component.TelemetrySettings) *statusExtension {
+	return &statusExtension{
+		config:     config,
+		telemetry:  telemetry,
+		client:     &http.Client{Timeout: telemetryTimeout},
+		components: map[string]componentState{},
+	}
+}
+
+func (e *statusExtension) Start(ctx context.Context, host component.Host) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(statusPath, e.handleStatus)
+	mux.HandleFunc(metricsPath, e.handleMetrics)
+
+	var listener net.Listener
+	var err error
+	if listener, err = e.config.ServerConfig.ToListener(ctx); err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", e.config.Endpoint, err)
+	}
+	if e.server, err = e.config.ServerConfig.ToServer(ctx, host, e.telemetry, mux); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if serveErr := e.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (e *statusExtension) Shutdown(context.Context) error {
+	var err error
+	if e.server != nil {
+		err = e.server.Close()
+	}
+	e.wg.Wait()
+	return err
+}
+
+// ComponentStatusChanged records the latest status of the component.
+func (e *statusExtension) ComponentStatusChanged(source *componentstatus.InstanceID, event *componentstatus.Event) {
+	s := componentState{
+		ComponentStatus: ComponentStatus{
+			Timestamp: event.Timestamp(),
+			ID:        source.ComponentID().String(),
+			Kind:      strings.ToLower(source.Kind().String()),
+			Status:    event.Status().String(),
+		},
+		monitor: source.Kind() == component.KindReceiver && source.ComponentID().Type().String() == smartAgentType,
+	}
+	if event.Err() != nil {
+		s.Error = event.Err().Error()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.components[s.Kind+"/"+s.ID] = s
+}
+
+// document returns the current status document.
+func (e *statusExtension) document(ctx context.Context) Document {
+	doc := Document{
+		Timestamp:      time.Now(),
+		Discovery:      map[string][]status.DiscoveredEndpoint{},
+		Components:     []ComponentStatus{},
+		Monitors:       []ComponentStatus{},
+		ExporterQueues: []ExporterQueue{},
+	}
+	e.mu.Lock()
+	for _, s := range e.components {
+		doc.Components = append(doc.Components, s.ComponentStatus)
+		if s.monitor {
+			doc.Monitors = append(doc.Monitors, s.ComponentStatus)
+		}
+	}
+	e.mu.Unlock()
+	sortComponents(doc.Components)
+	sortComponents(doc.Monitors)
+	for id, endpoints := range status.Discoveries() {
+		doc.Discovery[id.String()] = endpoints
+	}
+	if e.config.TelemetryEndpoint != "" {
+		queues, err := e.exporterQueues(ctx)
+		if err != nil {
+			e.telemetry.Logger.Debug("Failed reading the exporter queue depths", zap.Error(err))
+			doc.Errors = append(doc.Errors, fmt.Sprintf("failed reading the exporter queue depths: %v", err))
+		}
+		doc.ExporterQueues = append(doc.ExporterQueues, queues...)
+	}
+	return doc
+}
+
+func sortComponents(components []ComponentStatus) {
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Kind != components[j].Kind {
+			return components[i].Kind < components[j].Kind
+		}
+		return components[i].ID < components[j].ID
+	})
+}
+
+// exporterQueues reads the depths of the exporter queues from the collector's own metrics.
+func (e *statusExtension) exporterQueues(ctx context.Context) ([]ExporterQueue, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.config.TelemetryEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", e.config.TelemetryEndpoint, resp.StatusCode)
+	}
+	format := expfmt.ResponseFormat(resp.Header)
+	var decoder expfmt.Decoder
+	if format.FormatType() == expfmt.TypeProtoDelim {
+		decoder = expfmt.NewDecoder(resp.Body, format)
+	} else {
+		// the text format parser requires a trailing newline
+		decoder = expfmt.NewDecoder(io.MultiReader(resp.Body, strings.NewReader("\n")), format)
+	}
+	queues := map[[2]string]*ExporterQueue{}
+	for {
+		var mf dto.MetricFamily
+		if err = decoder.Decode(&mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if mf.GetName() != "otelcol_exporter_queue_size" && mf.GetName() != "otelcol_exporter_queue_capacity" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			var key [2]string
+			for _, label := range m.GetLabel() {
+				switch label.GetName() {
+				case "exporter":
+					key[0] = label.GetValue()
+				case "data_type":
+					key[1] = label.GetValue()
+				}
+			}
+			queue, ok := queues[key]
+			if !ok {
+				queue = &ExporterQueue{Exporter: key[0], DataType: key[1]}
+				queues[key] = queue
+			}
+			value := int64(m.GetGauge().GetValue())
+			if mf.GetName() == "otelcol_exporter_queue_size" {
+				queue.Size = value
+			} else {
+				queue.Capacity = value
+			}
+		}
+	}
+	result := make([]ExporterQueue, 0, len(queues))
+	for _, queue := range queues {
+		result = append(result, *queue)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Exporter != result[j].Exporter {
+			return result[i].Exporter < result[j].Exporter
+		}
+		return result[i].DataType < result[j].DataType
+	})
+	return result, nil
+}
+
+func (e *statusExtension) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(e.document(r.Context()))
+}
+
+func (e *statusExtension) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	format := expfmt.NewFormat(expfmt.TypeTextPlain)
+	w.Header().Set("Content-Type", string(format))
+	encoder := expfmt.NewEncoder(w, format)
+	for _, mf := range metricFamilies(e.document(r.Context())) {
+		if len(mf.Metric) == 0 {
+			// the text format has no representation of families without metrics
+			continue
+		}
+		if err := encoder.Encode(mf); err != nil {
+			return
+		}
+	}
+}
+
+// metricFamilies returns the status document as Prometheus metric families.
+func metricFamilies(doc Document) []*dto.MetricFamily {
+	components := gaugeFamily("splunk_collector_component_status", "The status of each component, with a value of 1.")
+	for _, s := range doc.Components {
+		addGauge(components, 1, "kind", s.Kind, "id", s.ID, "status", s.Status)
+	}
+	monitors := gaugeFamily("splunk_collector_monitor_status", "The status of each receiver running a Smart Agent monitor, with a value of 1.")
+	for _, s := range doc.Monitors {
+		addGauge(monitors, 1, "id", s.ID, "status", s.Status)
+	}
+	endpoints := gaugeFamily("splunk_collector_discovered_endpoint_status", "The status of each endpoint found by a discovery receiver, with a value of 1.")
+	discoveryIDs := make([]string, 0, len(doc.Discovery))
+	for id := range doc.Discovery {
+		discoveryIDs = append(discoveryIDs, id)
+	}
+	sort.Strings(discoveryIDs)
+	for _, id := range discoveryIDs {
+		for _, endpoint := range doc.Discovery[id] {
+			addGauge(endpoints, 1, "discovery", id, "endpoint", endpoint.ID, "target", endpoint.Target, "receiver", endpoint.Receiver, "status", endpoint.Status)
+		}
+	}
+	queueSizes := gaugeFamily("splunk_collector_exporter_queue_size", "The number of batches in the sending queue of each exporter.")
+	queueCapacities := gaugeFamily("splunk_collector_exporter_queue_capacity", "The capacity of the sending queue of each exporter.")
+	for _, queue := range doc.ExporterQueues {
+		addGauge(queueSizes, float64(queue.Size), "exporter", queue.Exporter, "data_type", queue.DataType)
+		addGauge(queueCapacities, float64(queue.Capacity), "exporter", queue.Exporter, "data_type", queue.DataType)
+	}
+	return []*dto.MetricFamily{components, monitors, endpoints, queueSizes, queueCapacities}
+}
+
+func gaugeFamily(name, help string) *dto.MetricFamily {
+	return &dto.MetricFamily{Name: &name, Help: &help, Type: dto.MetricType_GAUGE.Enum()}
+}
+
+// addGauge adds a gauge with the value and label name and value pairs to the family.
+func addGauge(mf *dto.MetricFamily, value float64, labels ...string) {
+	m := &dto.Metric{Gauge: &dto.Gauge{Value: &value}}
+	for i := 0; i < len(labels); i += 2 {
+		m.Label = append(m.Label, &dto.LabelPair{Name: &labels[i], Value: &labels[i+1]})
+	}
+	mf.Metric = append(mf.Metric, m)
+}
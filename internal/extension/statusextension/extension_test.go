@@ -0,0 +1,155 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusextension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/status"
+)
+
+const telemetry = `# HELP otelcol_exporter_queue_capacity Fixed capacity of the retry queue (in batches)
+# TYPE otelcol_exporter_queue_capacity gauge
+otelcol_exporter_queue_capacity{data_type="metrics",exporter="signalfx"} 5000
+otelcol_exporter_queue_capacity{data_type="logs",exporter="splunk_hec"} 1000
+# HELP otelcol_exporter_queue_size Current size of the retry queue (in batches)
+# TYPE otelcol_exporter_queue_size gauge
+otelcol_exporter_queue_size{data_type="metrics",exporter="signalfx"} 12
+otelcol_exporter_queue_size{data_type="logs",exporter="splunk_hec"} 0
+# HELP otelcol_process_uptime Uptime of the process
+# TYPE otelcol_process_uptime counter
+otelcol_process_uptime 42`
+
+func newTestExtension(t *testing.T) *statusExtension {
+	telemetryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(telemetry))
+	}))
+	t.Cleanup(telemetryServer.Close)
+	cfg := createDefaultConfig().(*Config)
+	cfg.TelemetryEndpoint = telemetryServer.URL
+	e := newStatusExtension(cfg, componenttest.NewNopTelemetrySettings())
+
+	started := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	for _, s := range []struct {
+		event *componentstatus.Event
+		id    component.ID
+		kind  component.Kind
+	}{
+		{id: component.MustNewIDWithName("smartagent", "postgresql"), kind: component.KindReceiver, event: componentstatus.NewEvent(componentstatus.StatusStarting)},
+		{id: component.MustNewIDWithName("smartagent", "postgresql"), kind: component.KindReceiver, event: componentstatus.NewRecoverableErrorEvent(errors.New("connection refused"))},
+		{id: component.MustNewID("otlp"), kind: component.KindReceiver, event: componentstatus.NewEvent(componentstatus.StatusOK)},
+		{id: component.MustNewID("signalfx"), kind: component.KindExporter, event: componentstatus.NewEvent(componentstatus.StatusOK)},
+	} {
+		e.ComponentStatusChanged(componentstatus.NewInstanceID(s.id, s.kind), s.event)
+	}
+	for key, s := range e.components {
+		s.Timestamp = started
+		e.components[key] = s
+	}
+	unregister := status.RegisterDiscovery(component.MustNewIDWithName("discovery", "host"), func() []status.DiscoveredEndpoint {
+		return []status.DiscoveredEndpoint{{ID: "(host_observer)[::]-5432-TCP-1", Target: "[::]:5432", Observer: "host_observer", Receiver: "postgresql", Status: "successful"}}
+	})
+	t.Cleanup(unregister)
+	return e
+}
+
+func TestStatusDocument(t *testing.T) {
+	e := newTestExtension(t)
+
+	rec := httptest.NewRecorder()
+	e.handleStatus(rec, httptest.NewRequest(http.MethodGet, statusPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var doc Document
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+
+	started := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	monitor := ComponentStatus{Timestamp: started, ID: "smartagent/postgresql", Kind: "receiver", Status: "StatusRecoverableError", Error: "connection refused"}
+	assert.Equal(t, []ComponentStatus{
+		{Timestamp: started, ID: "signalfx", Kind: "exporter", Status: "StatusOK"},
+		{Timestamp: started, ID: "otlp", Kind: "receiver", Status: "StatusOK"},
+		monitor,
+	}, doc.Components)
+	assert.Equal(t, []ComponentStatus{monitor}, doc.Monitors)
+	assert.Equal(t, map[string][]status.DiscoveredEndpoint{
+		"discovery/host": {{ID: "(host_observer)[::]-5432-TCP-1", Target: "[::]:5432", Observer: "host_observer", Receiver: "postgresql", Status: "successful"}},
+	}, doc.Discovery)
+	assert.Equal(t, []ExporterQueue{
+		{Exporter: "signalfx", DataType: "metrics", Size: 12, Capacity: 5000},
+		{Exporter: "splunk_hec", DataType: "logs", Size: 0, Capacity: 1000},
+	}, doc.ExporterQueues)
+	assert.Empty(t, doc.Errors)
+
+	rec = httptest.NewRecorder()
+	e.handleStatus(rec, httptest.NewRequest(http.MethodPost, statusPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestStatusDocumentWithoutTelemetry(t *testing.T) {
+	e := newTestExtension(t)
+	e.config.TelemetryEndpoint = "http://127.0.0.1:1/metrics"
+	doc := e.document(context.Background())
+	assert.Empty(t, doc.ExporterQueues)
+	require.Len(t, doc.Errors, 1)
+	assert.Contains(t, doc.Errors[0], "failed reading the exporter queue depths")
+	assert.Len(t, doc.Components, 3)
+
+	e.config.TelemetryEndpoint = ""
+	doc = e.document(context.Background())
+	assert.Empty(t, doc.ExporterQueues)
+	assert.Empty(t, doc.Errors)
+}
+
+func TestStatusMetrics(t *testing.T) {
+	e := newTestExtension(t)
+
+	rec := httptest.NewRecorder()
+	e.handleMetrics(rec, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Equal(t, `# HELP splunk_collector_component_status The status of each component, with a value of 1.
+# TYPE splunk_collector_component_status gauge
+splunk_collector_component_status{kind="exporter",id="signalfx",status="StatusOK"} 1
+splunk_collector_component_status{kind="receiver",id="otlp",status="StatusOK"} 1
+splunk_collector_component_status{kind="receiver",id="smartagent/postgresql",status="StatusRecoverableError"} 1
+# HELP splunk_collector_monitor_status The status of each receiver running a Smart Agent monitor, with a value of 1.
+# TYPE splunk_collector_monitor_status gauge
+splunk_collector_monitor_status{id="smartagent/postgresql",status="StatusRecoverableError"} 1
+# HELP splunk_collector_discovered_endpoint_status The status of each endpoint found by a discovery receiver, with a value of 1.
+# TYPE splunk_collector_discovered_endpoint_status gauge
+splunk_collector_discovered_endpoint_status{discovery="discovery/host",endpoint="(host_observer)[::]-5432-TCP-1",target="[::]:5432",receiver="postgresql",status="successful"} 1
+# HELP splunk_collector_exporter_queue_size The number of batches in the sending queue of each exporter.
+# TYPE splunk_collector_exporter_queue_size gauge
+splunk_collector_exporter_queue_size{exporter="signalfx",data_type="metrics"} 12
+splunk_collector_exporter_queue_size{exporter="splunk_hec",data_type="logs"} 0
+# HELP splunk_collector_exporter_queue_capacity The capacity of the sending queue of each exporter.
+# TYPE splunk_collector_exporter_queue_capacity gauge
+splunk_collector_exporter_queue_capacity{exporter="signalfx",data_type="metrics"} 5000
+splunk_collector_exporter_queue_capacity{exporter="splunk_hec",data_type="logs"} 1000
+`, rec.Body.String())
+}
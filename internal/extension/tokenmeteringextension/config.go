@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenmeteringextension
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the names the usage of known tokens is reported under.
+type Config struct {
+	// Tokens maps the names usage is reported under, e.g. the teams sharing the gateway,
+	// to their access or HEC tokens. The usage of other tokens is reported under a
+	// truncated hash of the token.
+	Tokens map[string]configopaque.String `mapstructure:"tokens"`
+	// QueueSize is the number of successful requests waiting to be metered, after which
+	// requests aren't metered.
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.QueueSize <= 0 {
+		errs = errors.New("queue_size must be positive")
+	}
+	names := make([]string, 0, len(cfg.Tokens))
+	for name := range cfg.Tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seen := map[configopaque.String]string{}
+	for _, name := range names {
+		token := cfg.Tokens[name]
+		if token == "" {
+			errs = errors.Join(errs, fmt.Errorf("token of %q must not be empty", name))
+			continue
+		}
+		if other, ok := seen[token]; ok {
+			errs = errors.Join(errs, fmt.Errorf("%q and %q have the same token", other, name))
+			continue
+		}
+		seen[token] = name
+	}
+	return errs
+}
@@ -0,0 +1,326 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenmeteringextension
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	splunksapm "github.com/signalfx/sapm-proto/gen"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const scopeName = "github.com/signalfx/splunk-otel-collector/internal/extension/tokenmeteringextension"
+
+// usage is the data sent by a single request.
+type usage struct {
+	datapoints int64
+	spans      int64
+	logBytes   int64
+}
+
+// measurement is a request accepted with a 2xx response, waiting to be metered.
+type measurement struct {
+	token           string
+	path            string
+	contentType     string
+	contentEncoding string
+	body            []byte
+}
+
+// usageMeter reports usage per token name as internal metrics. Requests are measured by
+// workers, off the request path, from a bounded queue.
+type usageMeter struct {
+	datapoints metric.Int64Counter
+	spans      metric.Int64Counter
+	logBytes   metric.Int64Counter
+	dropped    metric.Int64Counter
+	names      map[string]string
+	extension  attribute.KeyValue
+	queue      chan measurement
+	wg         sync.WaitGroup
+	mu         sync.RWMutex
+	closed     bool
+}
+
+func newUsageMeter(set component.TelemetrySettings, id component.ID, cfg *Config) (*usageMeter, error) {
+	meterProvider := set.MeterProvider
+	if set.LeveledMeterProvider != nil {
+		meterProvider = set.LeveledMeterProvider(configtelemetry.LevelBasic)
+	}
+	meter := meterProvider.Meter(scopeName)
+	um := &usageMeter{
+		names:     map[string]string{},
+		extension: attribute.String("extension", id.String()),
+		queue:     make(chan measurement, cfg.QueueSize),
+	}
+	for name, token := range cfg.Tokens {
+		um.names[string(token)] = name
+	}
+	var err error
+	if um.datapoints, err = meter.Int64Counter(
+		"token_usage_datapoints",
+		metric.WithDescription("Number of datapoints successfully sent per token."),
+		metric.WithUnit("{datapoints}"),
+	); err != nil {
+		return nil, err
+	}
+	if um.spans, err = meter.Int64Counter(
+		"token_usage_spans",
+		metric.WithDescription("Number of spans successfully sent per token."),
+		metric.WithUnit("{spans}"),
+	); err != nil {
+		return nil, err
+	}
+	if um.logBytes, err = meter.Int64Counter(
+		"token_usage_log_bytes",
+		metric.WithDescription("Uncompressed size of the log payloads successfully sent per token."),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, err
+	}
+	if um.dropped, err = meter.Int64Counter(
+		"token_usage_dropped_requests",
+		metric.WithDescription("Number of successful requests that weren't metered because the metering queue was full."),
+		metric.WithUnit("{requests}"),
+	); err != nil {
+		return nil, err
+	}
+	return um, nil
+}
+
+// start starts the workers measuring the queued requests.
+func (um *usageMeter) start(context.Context, component.Host) error {
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		um.wg.Add(1)
+		go func() {
+			defer um.wg.Done()
+			for m := range um.queue {
+				um.record(context.Background(), m.token, measure(m))
+			}
+		}()
+	}
+	return nil
+}
+
+// shutdown measures the queued requests and stops the workers. Requests sent after it aren't
+// metered.
+func (um *usageMeter) shutdown(context.Context) error {
+	um.mu.Lock()
+	if !um.closed {
+		um.closed = true
+		close(um.queue)
+	}
+	um.mu.Unlock()
+	um.wg.Wait()
+	return nil
+}
+
+// enqueue queues the request to be measured, or drops it if the queue is full.
+func (um *usageMeter) enqueue(m measurement) {
+	um.mu.RLock()
+	defer um.mu.RUnlock()
+	if um.closed {
+		return
+	}
+	select {
+	case um.queue <- m:
+	default:
+		um.dropped.Add(context.Background(), 1, metric.WithAttributes(um.extension, attribute.String("token", um.tokenName(m.token))))
+	}
+}
+
+func (um *usageMeter) record(ctx context.Context, token string, u usage) {
+	attrs := metric.WithAttributes(um.extension, attribute.String("token", um.tokenName(token)))
+	if u.datapoints > 0 {
+		um.datapoints.Add(ctx, u.datapoints, attrs)
+	}
+	if u.spans > 0 {
+		um.spans.Add(ctx, u.spans, attrs)
+	}
+	if u.logBytes > 0 {
+		um.logBytes.Add(ctx, u.logBytes, attrs)
+	}
+}
+
+// tokenName returns the configured name of the token, or a truncated hash of unknown
+// tokens so they are never reported as is.
+func (um *usageMeter) tokenName(token string) string {
+	if name, ok := um.names[token]; ok {
+		return name
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// meteringRoundTripper queues the requests with a token that are accepted with a 2xx
+// response to be measured.
+type meteringRoundTripper struct {
+	base    http.RoundTripper
+	enqueue func(m measurement)
+}
+
+func (rt *meteringRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := requestToken(req)
+	if token == "" || req.Body == nil || req.Body == http.NoBody {
+		return rt.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	resp, err := rt.base.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		rt.enqueue(measurement{
+			token:           token,
+			path:            req.URL.Path,
+			contentType:     req.Header.Get("Content-Type"),
+			contentEncoding: req.Header.Get("Content-Encoding"),
+			body:            body,
+		})
+	}
+	return resp, err
+}
+
+// requestToken returns the SignalFx access token or HEC token of the request.
+func requestToken(req *http.Request) string {
+	if token := req.Header.Get("X-SF-Token"); token != "" {
+		return token
+	}
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Splunk "); ok {
+		return token
+	}
+	return ""
+}
+
+type otlpRequest interface {
+	UnmarshalProto([]byte) error
+	UnmarshalJSON([]byte) error
+}
+
+// measure determines the usage of a request from its path and payload. Payloads that
+// can't be decoded aren't metered.
+func measure(m measurement) usage {
+	body, err := decompress(m.contentEncoding, m.body)
+	if err != nil {
+		return usage{}
+	}
+	isJSON := strings.HasPrefix(m.contentType, "application/json")
+	unmarshal := func(r otlpRequest) error {
+		if isJSON {
+			return r.UnmarshalJSON(body)
+		}
+		return r.UnmarshalProto(body)
+	}
+
+	path := m.path
+	switch {
+	case strings.Contains(path, "/services/collector"):
+		// HEC payloads are metered by size regardless of their event types
+		return usage{logBytes: int64(len(body))}
+	case strings.HasSuffix(path, "/v1/metrics"), strings.HasSuffix(path, "/v2/datapoint/otlp"):
+		r := pmetricotlp.NewExportRequest()
+		if unmarshal(r) == nil {
+			return usage{datapoints: int64(r.Metrics().DataPointCount())}
+		}
+	case strings.HasSuffix(path, "/v2/datapoint"):
+		var msg sfxpb.DataPointUploadMessage
+		if msg.Unmarshal(body) == nil {
+			return usage{datapoints: int64(len(msg.Datapoints))}
+		}
+	case strings.HasSuffix(path, "/v1/traces"), strings.HasSuffix(path, "/v2/trace/otlp"):
+		r := ptraceotlp.NewExportRequest()
+		if unmarshal(r) == nil {
+			return usage{spans: int64(r.Traces().SpanCount())}
+		}
+	case strings.HasSuffix(path, "/v2/trace"):
+		var sapm splunksapm.PostSpansRequest
+		if sapm.Unmarshal(body) == nil {
+			var spans int
+			for _, batch := range sapm.Batches {
+				spans += len(batch.Spans)
+			}
+			return usage{spans: int64(spans)}
+		}
+	case strings.HasSuffix(path, "/v1/logs"):
+		r := plogotlp.NewExportRequest()
+		if unmarshal(r) == nil {
+			return usage{logBytes: int64(len(body))}
+		}
+	}
+	return usage{}
+}
+
+func decompress(encoding string, body []byte) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	case "zstd":
+		return decompressZstd(body)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// zstdDecoders pools the zstd decoders, which are costly to create. They're only used with
+// DecodeAll, which doesn't start goroutines, so they don't need to be closed.
+var zstdDecoders sync.Pool
+
+func decompressZstd(body []byte) ([]byte, error) {
+	d, ok := zstdDecoders.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+		if d, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1)); err != nil {
+			return nil, err
+		}
+	}
+	defer zstdDecoders.Put(d)
+	return d.DecodeAll(body, nil)
+}
@@ -0,0 +1,263 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenmeteringextension
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/klauspost/compress/zstd"
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	splunksapm "github.com/signalfx/sapm-proto/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+)
+
+func otlpMetrics(t *testing.T, datapoints int) []byte {
+	md := pmetric.NewMetrics()
+	gauge := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge()
+	for i := 0; i < datapoints; i++ {
+		gauge.DataPoints().AppendEmpty().SetIntValue(int64(i))
+	}
+	body, err := pmetricotlp.NewExportRequestFromMetrics(md).MarshalProto()
+	require.NoError(t, err)
+	return body
+}
+
+func otlpTraces(t *testing.T, spans int) []byte {
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+	for i := 0; i < spans; i++ {
+		ss.Spans().AppendEmpty().SetName("span")
+	}
+	body, err := ptraceotlp.NewExportRequestFromTraces(td).MarshalJSON()
+	require.NoError(t, err)
+	return body
+}
+
+func otlpLogs(t *testing.T) []byte {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("a log")
+	body, err := plogotlp.NewExportRequestFromLogs(ld).MarshalProto()
+	require.NoError(t, err)
+	return body
+}
+
+func gzipped(t *testing.T, body []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestMeasure(t *testing.T) {
+	sfxBody, err := (&sfxpb.DataPointUploadMessage{Datapoints: []*sfxpb.DataPoint{{}, {}, {}}}).Marshal()
+	require.NoError(t, err)
+	sapmBody, err := (&splunksapm.PostSpansRequest{Batches: []*model.Batch{
+		{Spans: []*model.Span{{}, {}}},
+		{Spans: []*model.Span{{}}},
+	}}).Marshal()
+	require.NoError(t, err)
+	logsBody := otlpLogs(t)
+	hecBody := []byte(`{"event":"a log"}`)
+
+	for _, tt := range []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		body     []byte
+		expected usage
+	}{
+		{
+			name:     "otlp metrics",
+			path:     "/v1/metrics",
+			body:     otlpMetrics(t, 4),
+			expected: usage{datapoints: 4},
+		},
+		{
+			name:     "gzipped signalfx otlp histograms",
+			path:     "/v2/datapoint/otlp",
+			headers:  map[string]string{"Content-Encoding": "gzip"},
+			body:     gzipped(t, otlpMetrics(t, 2)),
+			expected: usage{datapoints: 2},
+		},
+		{
+			name:     "signalfx datapoints",
+			path:     "/v2/datapoint",
+			body:     sfxBody,
+			expected: usage{datapoints: 3},
+		},
+		{
+			name:     "otlp json traces",
+			path:     "/v1/traces",
+			headers:  map[string]string{"Content-Type": "application/json"},
+			body:     otlpTraces(t, 5),
+			expected: usage{spans: 5},
+		},
+		{
+			name:     "sapm",
+			path:     "/v2/trace",
+			headers:  map[string]string{"Content-Encoding": "gzip"},
+			body:     gzipped(t, sapmBody),
+			expected: usage{spans: 3},
+		},
+		{
+			name:     "otlp logs",
+			path:     "/v1/logs",
+			body:     logsBody,
+			expected: usage{logBytes: int64(len(logsBody))},
+		},
+		{
+			name:     "hec",
+			path:     "/services/collector",
+			headers:  map[string]string{"Content-Encoding": "gzip"},
+			body:     gzipped(t, hecBody),
+			expected: usage{logBytes: int64(len(hecBody))},
+		},
+		{
+			name:    "unsupported encoding",
+			path:    "/v1/logs",
+			headers: map[string]string{"Content-Encoding": "snappy"},
+			body:    logsBody,
+		},
+		{
+			name: "invalid payload",
+			path: "/v2/datapoint",
+			body: []byte("invalid"),
+		},
+		{
+			name: "unknown path",
+			path: "/v2/event",
+			body: sfxBody,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m := measurement{
+				path:            tt.path,
+				contentType:     tt.headers["Content-Type"],
+				contentEncoding: tt.headers["Content-Encoding"],
+				body:            tt.body,
+			}
+			assert.Equal(t, tt.expected, measure(m))
+		})
+	}
+}
+
+func TestMeteringRoundTripper(t *testing.T) {
+	status := http.StatusOK
+	var received [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		received = append(received, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	var queued []measurement
+	rt := &meteringRoundTripper{
+		base: http.DefaultTransport,
+		enqueue: func(m measurement) {
+			queued = append(queued, m)
+		},
+	}
+	send := func(header, value string) {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/metrics", bytes.NewReader(otlpMetrics(t, 2)))
+		require.NoError(t, err)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	send("X-SF-Token", "sfx-token")
+	send("Authorization", "Splunk hec-token")
+	send("", "")
+	status = http.StatusBadRequest
+	send("X-SF-Token", "sfx-token")
+
+	require.Len(t, queued, 2)
+	assert.Equal(t, "sfx-token", queued[0].token)
+	assert.Equal(t, "hec-token", queued[1].token)
+	for _, m := range queued {
+		assert.Equal(t, "/v1/metrics", m.path)
+		assert.Equal(t, usage{datapoints: 2}, measure(m))
+	}
+	// the request bodies are forwarded as is
+	require.Len(t, received, 4)
+	for _, body := range received {
+		assert.Equal(t, otlpMetrics(t, 2), body)
+	}
+}
+
+func TestUsageMeterQueue(t *testing.T) {
+	um, err := newUsageMeter(componenttest.NewNopTelemetrySettings(), component.MustNewID(typeStr), &Config{QueueSize: 1})
+	require.NoError(t, err)
+
+	m := measurement{token: "token", path: "/v1/metrics", body: otlpMetrics(t, 2)}
+	um.enqueue(m)
+	// dropped while the queue is full
+	um.enqueue(m)
+	require.Len(t, um.queue, 1)
+
+	require.NoError(t, um.start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, um.shutdown(context.Background()))
+	assert.Empty(t, um.queue)
+	// requests sent after shutdown aren't metered
+	um.enqueue(m)
+}
+
+func TestDecompressZstd(t *testing.T) {
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	compressed := encoder.EncodeAll([]byte("payload"), nil)
+	require.NoError(t, encoder.Close())
+
+	for i := 0; i < 3; i++ {
+		body, err := decompress("zstd", compressed)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(body))
+	}
+}
+
+func TestTokenName(t *testing.T) {
+	um, err := newUsageMeter(componenttest.NewNopTelemetrySettings(), component.MustNewID(typeStr), &Config{
+		Tokens: map[string]configopaque.String{"team-a": "token-a"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", um.tokenName("token-a"))
+	unknown := um.tokenName("token-b")
+	assert.Regexp(t, "^sha256:[0-9a-f]{12}$", unknown)
+	assert.NotContains(t, unknown, "token-b")
+}
@@ -0,0 +1,72 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sserviceaccount provides the credentials of the Kubernetes service account the
+// collector runs as, which components authenticating to the API server, kubelets and the
+// control plane with `auth_type: serviceAccount` or `use_service_account` rely on.
+package k8sserviceaccount
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// DefaultDir is where Kubernetes mounts the service account credentials in pods.
+const DefaultDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Credentials are the files of a mounted service account.
+type Credentials struct {
+	// TokenFile is the bearer token of the service account.
+	TokenFile string
+	// CAFile is the certificate authority bundle the API server certificate is signed with.
+	CAFile string
+	// Namespace is the namespace of the pod.
+	Namespace string
+}
+
+// Load returns the service account credentials mounted in dir, or an error if the token
+// or the certificate authority bundle isn't readable, e.g. outside Kubernetes or with
+// automountServiceAccountToken disabled.
+func Load(dir string) (Credentials, error) {
+	creds := Credentials{
+		TokenFile: filepath.Join(dir, "token"),
+		CAFile:    filepath.Join(dir, "ca.crt"),
+	}
+	var errs error
+	for _, file := range []string{creds.TokenFile, creds.CAFile} {
+		if _, err := os.Stat(file); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("service account file unavailable: %w", err))
+		}
+	}
+	if errs != nil {
+		return Credentials{}, errs
+	}
+	if namespace, err := os.ReadFile(filepath.Join(dir, "namespace")); err == nil {
+		creds.Namespace = string(namespace)
+	}
+	return creds, nil
+}
+
+// APIServerEndpoint returns the host:port of the API server from the KUBERNETES_SERVICE_HOST
+// and KUBERNETES_SERVICE_PORT environment variables Kubernetes sets in pods.
+func APIServerEndpoint(getenv func(string) string) (string, error) {
+	host, port := getenv("KUBERNETES_SERVICE_HOST"), getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return "", errors.New("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+	}
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sserviceaccount
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("token"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), []byte("ca"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "namespace"), []byte("monitoring"), 0600))
+
+	creds, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{
+		TokenFile: filepath.Join(dir, "token"),
+		CAFile:    filepath.Join(dir, "ca.crt"),
+		Namespace: "monitoring",
+	}, creds)
+}
+
+func TestLoadMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("token"), 0600))
+
+	_, err := Load(dir)
+	require.ErrorContains(t, err, "service account file unavailable")
+	require.ErrorContains(t, err, "ca.crt")
+	require.NotContains(t, err.Error(), "token")
+}
+
+func TestAPIServerEndpoint(t *testing.T) {
+	env := map[string]string{
+		"KUBERNETES_SERVICE_HOST": "10.96.0.1",
+		"KUBERNETES_SERVICE_PORT": "443",
+	}
+	endpoint, err := APIServerEndpoint(func(key string) string { return env[key] })
+	require.NoError(t, err)
+	assert.Equal(t, "10.96.0.1:443", endpoint)
+
+	env["KUBERNETES_SERVICE_HOST"] = "fd00::1"
+	endpoint, err = APIServerEndpoint(func(key string) string { return env[key] })
+	require.NoError(t, err)
+	assert.Equal(t, "[fd00::1]:443", endpoint)
+
+	_, err = APIServerEndpoint(func(string) string { return "" })
+	require.EqualError(t, err, "KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+}
@@ -0,0 +1,226 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loglevel changes the level of the collector logs at runtime, globally or for
+// individual components, without restarting the collector.
+package loglevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Path is the path of the log level endpoint of the debug config server.
+const Path = "/debug/loglevel"
+
+// ChangesEnabledEnvVar is the environment variable enabling the changes of the levels through
+// the endpoint when "true". Otherwise, the endpoint only reports them.
+const ChangesEnabledEnvVar = "SPLUNK_DEBUG_LOG_LEVEL_CHANGES"
+
+// componentIDKey is the logger field holding the ID of the component the logger was provided to.
+const componentIDKey = "name"
+
+// Levels holds the log level overrides. Without any override, logs are filtered by the
+// level of the collector telemetry config.
+type Levels struct {
+	global         *zapcore.Level
+	components     map[string]zapcore.Level
+	mu             sync.RWMutex
+	changesEnabled bool
+}
+
+// New returns Levels without overrides, which can only be changed through the endpoint if
+// changesEnabled is true.
+func New(changesEnabled bool) *Levels {
+	return &Levels{components: map[string]zapcore.Level{}, changesEnabled: changesEnabled}
+}
+
+// WrapCore returns the zap.Option applying the overrides to the collector logger.
+func (l *Levels) WrapCore() zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, levels: l, unfiltered: newUnfilteredSampler(core)}
+	})
+}
+
+// SetGlobal overrides the level of all the logs, or removes the override if level is nil.
+func (l *Levels) SetGlobal(level *zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.global = level
+}
+
+// SetComponent overrides the level of the logs of the component ID, or removes the
+// override if level is nil. Component overrides take precedence over the global one.
+func (l *Levels) SetComponent(id string, level *zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level == nil {
+		delete(l.components, id)
+		return
+	}
+	l.components[id] = *level
+}
+
+// level returns the override for the component ID, if any.
+func (l *Levels) level(id string) (zapcore.Level, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.components[id]; ok {
+		return level, true
+	}
+	if l.global != nil {
+		return *l.global, true
+	}
+	return zapcore.InvalidLevel, false
+}
+
+// Report is the document served by the log level endpoint.
+type Report struct {
+	Components map[string]string `json:"components"`
+	Global     string            `json:"global,omitempty"`
+}
+
+func (l *Levels) report() Report {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	report := Report{Components: map[string]string{}}
+	if l.global != nil {
+		report.Global = l.global.String()
+	}
+	for id, level := range l.components {
+		report.Components[id] = level.String()
+	}
+	return report
+}
+
+// ServeHTTP reports the overrides on GET. If changes are enabled, PUT sets the "level" query
+// parameter as the override of the "component" query parameter, or the global one if omitted,
+// and DELETE removes it.
+func (l *Levels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.serve(w, r, l.changesEnabled)
+}
+
+// AdminHandler returns the handler of the endpoint served by the admin extension, which always
+// allows changes since it authorizes them by the permissions of the request's token.
+func (l *Levels) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.serve(w, r, true)
+	})
+}
+
+func (l *Levels) serve(w http.ResponseWriter, r *http.Request, changesEnabled bool) {
+	component := r.URL.Query().Get("component")
+	var level *zapcore.Level
+	if (r.Method == http.MethodPut || r.Method == http.MethodDelete) && !changesEnabled {
+		http.Error(w, fmt.Sprintf("log level changes are disabled, set %s to true to enable them", ChangesEnabledEnvVar), http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		parsed, err := zapcore.ParseLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid level: %v", err), http.StatusBadRequest)
+			return
+		}
+		level = &parsed
+		fallthrough
+	case http.MethodDelete:
+		if component == "" {
+			l.SetGlobal(level)
+		} else {
+			l.SetComponent(component, level)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(l.report())
+}
+
+// Sampling of the entries only enabled by an override, matching the default sampling of the
+// collector logs.
+const (
+	samplingTick       = 10 * time.Second
+	samplingInitial    = 10
+	samplingThereafter = 100
+)
+
+// levelCore filters entries by the override of their component, or by the wrapped core
+// if there is none.
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+	// unfiltered samples and writes entries to the wrapped core regardless of its level.
+	unfiltered  zapcore.Core
+	componentID string
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	componentID := c.componentID
+	for _, field := range fields {
+		if field.Key == componentIDKey && field.Type == zapcore.StringType {
+			componentID = field.String
+		}
+	}
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels, unfiltered: c.unfiltered.With(fields), componentID: componentID}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	if override, ok := c.levels.level(c.componentID); ok {
+		return override.Enabled(level)
+	}
+	return c.Core.Enabled(level)
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	override, ok := c.levels.level(c.componentID)
+	if !ok || c.Core.Enabled(entry.Level) && override.Enabled(entry.Level) {
+		// the wrapped core samples the entry as configured
+		return c.Core.Check(entry, checked)
+	}
+	if !override.Enabled(entry.Level) {
+		return checked
+	}
+	// the wrapped core would filter the entry by the configured level
+	return c.unfiltered.Check(entry, checked)
+}
+
+// unfilteredCore writes entries to the wrapped core without filtering them by its level.
+type unfilteredCore struct {
+	zapcore.Core
+}
+
+func newUnfilteredSampler(core zapcore.Core) zapcore.Core {
+	return zapcore.NewSamplerWithOptions(unfilteredCore{Core: core}, samplingTick, samplingInitial, samplingThereafter)
+}
+
+func (c unfilteredCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c unfilteredCore) With(fields []zapcore.Field) zapcore.Core {
+	return unfilteredCore{Core: c.Core.With(fields)}
+}
+
+func (c unfilteredCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c.Core)
+}
@@ -0,0 +1,161 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loglevel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLevels(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	levels := New(true)
+	logger := zap.New(core, levels.WrapCore())
+	smartagent := logger.With(zap.String("kind", "receiver"), zap.String("name", "smartagent/postgresql"))
+	otlp := logger.With(zap.String("kind", "receiver"), zap.String("name", "otlp"))
+
+	smartagent.Debug("filtered")
+	otlp.Info("configured level")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "configured level", logs.TakeAll()[0].Message)
+
+	debug, warn := zapcore.DebugLevel, zapcore.WarnLevel
+	levels.SetComponent("smartagent/postgresql", &debug)
+	smartagent.Debug("component debug")
+	otlp.Debug("filtered")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "component debug", logs.TakeAll()[0].Message)
+
+	levels.SetGlobal(&warn)
+	smartagent.Debug("component override takes precedence")
+	otlp.Info("filtered")
+	logger.Warn("global warn")
+	assert.Equal(t, []string{"component override takes precedence", "global warn"}, messages(logs))
+
+	levels.SetComponent("smartagent/postgresql", nil)
+	levels.SetGlobal(nil)
+	smartagent.Debug("filtered")
+	otlp.Info("configured level")
+	assert.Equal(t, []string{"configured level"}, messages(logs))
+}
+
+func TestOverridesAreSampled(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	levels := New(true)
+	logger := zap.New(core, levels.WrapCore()).With(zap.String("name", "otlp"))
+
+	debug := zapcore.DebugLevel
+	levels.SetComponent("otlp", &debug)
+	for i := 0; i < samplingInitial+5; i++ {
+		logger.Debug("sampled")
+	}
+	assert.Equal(t, samplingInitial, logs.Len())
+}
+
+func messages(logs *observer.ObservedLogs) []string {
+	var msgs []string
+	for _, entry := range logs.TakeAll() {
+		msgs = append(msgs, entry.Message)
+	}
+	return msgs
+}
+
+func TestServeHTTP(t *testing.T) {
+	levels := New(true)
+	for _, tt := range []struct {
+		expected     Report
+		name         string
+		method       string
+		query        string
+		expectedCode int
+	}{
+		{
+			name:         "empty",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			expected:     Report{Components: map[string]string{}},
+		},
+		{
+			name:         "set component",
+			method:       http.MethodPut,
+			query:        "?component=smartagent/postgresql&level=debug",
+			expectedCode: http.StatusOK,
+			expected:     Report{Components: map[string]string{"smartagent/postgresql": "debug"}},
+		},
+		{
+			name:         "set global",
+			method:       http.MethodPut,
+			query:        "?level=warn",
+			expectedCode: http.StatusOK,
+			expected:     Report{Global: "warn", Components: map[string]string{"smartagent/postgresql": "debug"}},
+		},
+		{
+			name:         "delete component",
+			method:       http.MethodDelete,
+			query:        "?component=smartagent/postgresql",
+			expectedCode: http.StatusOK,
+			expected:     Report{Global: "warn", Components: map[string]string{}},
+		},
+		{name: "invalid level", method: http.MethodPut, query: "?level=verbose", expectedCode: http.StatusBadRequest},
+		{name: "invalid method", method: http.MethodPost, expectedCode: http.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			levels.ServeHTTP(rec, httptest.NewRequest(tt.method, Path+tt.query, nil))
+			require.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var report Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			assert.Equal(t, tt.expected, report)
+		})
+	}
+}
+
+func TestServeHTTPChangesDisabled(t *testing.T) {
+	levels := New(false)
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		rec := httptest.NewRecorder()
+		levels.ServeHTTP(rec, httptest.NewRequest(method, Path+"?level=debug", nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	levels.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, Report{Components: map[string]string{}}, report)
+}
+
+func TestAdminHandler(t *testing.T) {
+	// the admin extension authorizes the changes, so they're allowed even if disabled
+	levels := New(false)
+	rec := httptest.NewRecorder()
+	levels.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, Path+"?level=debug", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, Report{Global: "debug", Components: map[string]string{}}, report)
+}
@@ -0,0 +1,164 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logrotation provides a zap sink writing the collector logs to a file that is rotated
+// by size and age, for hosts without logrotate.
+package logrotation
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Scheme is the URL scheme of rotated log file output paths, e.g.
+// "rotating:///var/log/otelcol.log?max_size_mib=100&max_backups=5&max_age_days=30&compress=true".
+const Scheme = "rotating"
+
+// The rotation settings of output paths without the respective query parameter.
+const (
+	DefaultMaxSizeMiB = 100
+	DefaultMaxBackups = 5
+	DefaultMaxAgeDays = 30
+)
+
+const (
+	maxSizeMiBParam    = "max_size_mib"
+	maxBackupsParam    = "max_backups"
+	maxAgeDaysParam    = "max_age_days"
+	compressParam      = "compress"
+	windowsDriveLength = len("/C:")
+)
+
+var (
+	registerOnce sync.Once
+	errRegister  error
+
+	// files are the open rotated files by path, shared by the output paths writing to the same file.
+	files   = map[string]*file{}
+	filesMu sync.Mutex
+)
+
+// Register registers the rotating file sink with zap. It can be called more than once.
+func Register() error {
+	registerOnce.Do(func() {
+		errRegister = zap.RegisterSink(Scheme, newSink)
+	})
+	return errRegister
+}
+
+// URL returns the output path of a log file at path rotated with the default settings.
+func URL(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{Scheme: Scheme, Path: filepath.ToSlash(abs)}
+	if u.Path[0] != '/' {
+		// Windows paths, e.g. "C:/ProgramData/otelcol.log"
+		u.Path = "/" + u.Path
+	}
+	u.RawQuery = url.Values{
+		maxSizeMiBParam: {strconv.Itoa(DefaultMaxSizeMiB)},
+		maxBackupsParam: {strconv.Itoa(DefaultMaxBackups)},
+		maxAgeDaysParam: {strconv.Itoa(DefaultMaxAgeDays)},
+	}.Encode()
+	return u.String(), nil
+}
+
+// file is a rotated log file and the number of sinks writing to it.
+type file struct {
+	*lumberjack.Logger
+	refs int
+}
+
+// sink is a zap.Sink writing to a rotated log file.
+type sink struct {
+	*file
+	path string
+	once sync.Once
+}
+
+func (s *sink) Sync() error {
+	return nil
+}
+
+// Close closes the file once no other sink writes to it.
+func (s *sink) Close() error {
+	var err error
+	s.once.Do(func() {
+		filesMu.Lock()
+		defer filesMu.Unlock()
+		s.refs--
+		if s.refs == 0 {
+			delete(files, s.path)
+			err = s.Logger.Close()
+		}
+	})
+	return err
+}
+
+func newSink(u *url.URL) (zap.Sink, error) {
+	path := filepath.FromSlash(u.Path)
+	if runtime.GOOS == "windows" && len(u.Path) >= windowsDriveLength && u.Path[0] == '/' && u.Path[2] == ':' {
+		path = filepath.FromSlash(u.Path[1:])
+	}
+	if u.Host != "" || path == "" {
+		return nil, fmt.Errorf("%s output path %q must be of the form %s:///path/to/file.log", Scheme, u, Scheme)
+	}
+
+	logger := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    DefaultMaxSizeMiB,
+		MaxBackups: DefaultMaxBackups,
+		MaxAge:     DefaultMaxAgeDays,
+	}
+	query := u.Query()
+	for param, dst := range map[string]*int{
+		maxSizeMiBParam: &logger.MaxSize,
+		maxBackupsParam: &logger.MaxBackups,
+		maxAgeDaysParam: &logger.MaxAge,
+	} {
+		if value := query.Get(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s must be a non-negative integer: %q", param, value)
+			}
+			*dst = n
+		}
+	}
+	if value := query.Get(compressParam); value != "" {
+		compress, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a boolean: %q", compressParam, value)
+		}
+		logger.Compress = compress
+	}
+
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	f, ok := files[path]
+	if !ok {
+		f = &file{Logger: logger}
+		files[path] = f
+	}
+	f.refs++
+	return &sink{file: f, path: path}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logrotation
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestURL(t *testing.T) {
+	dir := t.TempDir()
+	u, err := URL(filepath.Join(dir, "otel col.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "rotating://"+filepath.ToSlash(dir)+"/otel%20col.log?max_age_days=30&max_backups=5&max_size_mib=100", u)
+}
+
+func TestRotatingSink(t *testing.T) {
+	require.NoError(t, Register())
+	require.NoError(t, Register())
+
+	path := filepath.Join(t.TempDir(), "otelcol.log")
+	u, err := URL(path)
+	require.NoError(t, err)
+	cfg := zap.NewProductionConfig()
+	cfg.OutputPaths = []string{u}
+	cfg.ErrorOutputPaths = []string{u}
+	logger, err := cfg.Build()
+	require.NoError(t, err)
+	logger.Info("written to the rotated file")
+	require.NoError(t, logger.Sync())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "written to the rotated file")
+	assert.Len(t, files, 1)
+}
+
+func TestSinkRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	u, err := url.Parse("rotating://" + filepath.ToSlash(dir) + "/otelcol.log?max_size_mib=1&max_backups=1&compress=false")
+	require.NoError(t, err)
+	s, err := newSink(u)
+	require.NoError(t, err)
+
+	line := append(bytes.Repeat([]byte("a"), 1023), '\n')
+	for i := 0; i < 3*1024; i++ {
+		_, err = s.Write(line)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+	assert.NotContains(t, files, filepath.Join(dir, "otelcol.log"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	// the current file and at most max_backups rotated ones, which are removed asynchronously
+	assert.GreaterOrEqual(t, len(entries), 2)
+}
+
+func TestSinkInvalidSettings(t *testing.T) {
+	for _, tt := range []struct {
+		url         string
+		expectedErr string
+	}{
+		{url: "rotating:///otelcol.log?max_size_mib=-1", expectedErr: `max_size_mib must be a non-negative integer: "-1"`},
+		{url: "rotating:///otelcol.log?max_age_days=week", expectedErr: `max_age_days must be a non-negative integer: "week"`},
+		{url: "rotating:///otelcol.log?compress=maybe", expectedErr: `compress must be a boolean: "maybe"`},
+		{url: "rotating://otelcol.log", expectedErr: `rotating output path "rotating://otelcol.log" must be of the form rotating:///path/to/file.log`},
+	} {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			_, err = newSink(u)
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureresourceidprocessor
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the resource attribute holding the Azure resource ID.
+type Config struct {
+	// Attribute is the resource attribute holding the Azure resource ID of the telemetry.
+	Attribute string `mapstructure:"attribute"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Attribute == "" {
+		return errors.New("attribute must not be empty")
+	}
+	return nil
+}
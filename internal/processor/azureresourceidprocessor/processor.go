@@ -0,0 +1,130 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureresourceidprocessor
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	cloudProviderAttr     = "cloud.provider"
+	cloudAccountIDAttr    = "cloud.account.id"
+	resourceGroupNameAttr = "azure.resource_group.name"
+	resourceTypeAttr      = "azure.resource.type"
+	resourceNameAttr      = "azure.resource.name"
+	cloudProviderAzure    = "azure"
+	subscriptionsSegment  = "subscriptions"
+	resourceGroupsSegment = "resourcegroups"
+	providersSegment      = "providers"
+)
+
+// resourceID is a parsed Azure resource ID, e.g.
+// /subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.Web/sites/<name>.
+type resourceID struct {
+	subscription  string
+	resourceGroup string
+	// resourceType is the namespace and types of the resource, e.g. Microsoft.Sql/servers/databases.
+	resourceType string
+	name         string
+}
+
+// parseResourceID parses the ID of a subscription, resource group or resource, whose keywords
+// are case-insensitive like Azure Monitor uppercases them.
+func parseResourceID(id string) (resourceID, bool) {
+	var parsed resourceID
+	segments := strings.Split(strings.Trim(id, "/"), "/")
+	if len(segments) < 2 || !strings.EqualFold(segments[0], subscriptionsSegment) || segments[1] == "" {
+		return parsed, false
+	}
+	parsed.subscription = segments[1]
+	segments = segments[2:]
+	if len(segments) >= 2 && strings.EqualFold(segments[0], resourceGroupsSegment) {
+		parsed.resourceGroup = segments[1]
+		segments = segments[2:]
+	}
+	if len(segments) == 0 {
+		return parsed, true
+	}
+	// providers/<namespace> followed by type and name pairs
+	if len(segments) < 4 || !strings.EqualFold(segments[0], providersSegment) || len(segments)%2 != 0 {
+		return parsed, false
+	}
+	types := []string{segments[1]}
+	for i := 2; i < len(segments); i += 2 {
+		types = append(types, segments[i])
+	}
+	parsed.resourceType = strings.Join(types, "/")
+	parsed.name = segments[len(segments)-1]
+	return parsed, true
+}
+
+// azureResourceIDProcessor sets the cloud and Azure resource attributes parsed from the Azure
+// resource ID of the resources, like the ones of the logs and metrics of Azure Monitor diagnostic
+// settings. Resources without a valid ID are left unchanged.
+type azureResourceIDProcessor struct {
+	config *Config
+}
+
+func newAzureResourceIDProcessor(config *Config) *azureResourceIDProcessor {
+	return &azureResourceIDProcessor{config: config}
+}
+
+func (ap *azureResourceIDProcessor) processResource(resource pcommon.Resource) {
+	attrs := resource.Attributes()
+	id, ok := attrs.Get(ap.config.Attribute)
+	if !ok {
+		return
+	}
+	parsed, ok := parseResourceID(id.AsString())
+	if !ok {
+		return
+	}
+	attrs.PutStr(cloudProviderAttr, cloudProviderAzure)
+	attrs.PutStr(cloudAccountIDAttr, parsed.subscription)
+	if parsed.resourceGroup != "" {
+		attrs.PutStr(resourceGroupNameAttr, parsed.resourceGroup)
+	}
+	if parsed.resourceType != "" {
+		attrs.PutStr(resourceTypeAttr, parsed.resourceType)
+		attrs.PutStr(resourceNameAttr, parsed.name)
+	}
+}
+
+func (ap *azureResourceIDProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		ap.processResource(td.ResourceSpans().At(i).Resource())
+	}
+	return td, nil
+}
+
+func (ap *azureResourceIDProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		ap.processResource(ld.ResourceLogs().At(i).Resource())
+	}
+	return ld, nil
+}
+
+func (ap *azureResourceIDProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		ap.processResource(md.ResourceMetrics().At(i).Resource())
+	}
+	return md, nil
+}
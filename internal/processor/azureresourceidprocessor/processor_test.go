@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azureresourceidprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestParseResourceID(t *testing.T) {
+	for _, tt := range []struct {
+		id       string
+		expected resourceID
+		valid    bool
+	}{
+		{
+			id:       "/SUBSCRIPTIONS/0000-1111/RESOURCEGROUPS/PROD-RG/PROVIDERS/MICROSOFT.WEB/SITES/CHECKOUT",
+			expected: resourceID{subscription: "0000-1111", resourceGroup: "PROD-RG", resourceType: "MICROSOFT.WEB/SITES", name: "CHECKOUT"},
+			valid:    true,
+		},
+		{
+			id:       "/subscriptions/0000-1111/resourceGroups/prod-rg/providers/Microsoft.Sql/servers/sql-1/databases/orders",
+			expected: resourceID{subscription: "0000-1111", resourceGroup: "prod-rg", resourceType: "Microsoft.Sql/servers/databases", name: "orders"},
+			valid:    true,
+		},
+		{
+			id:       "/subscriptions/0000-1111/providers/Microsoft.Insights/diagnosticSettings/audit",
+			expected: resourceID{subscription: "0000-1111", resourceType: "Microsoft.Insights/diagnosticSettings", name: "audit"},
+			valid:    true,
+		},
+		{
+			id:       "/subscriptions/0000-1111/resourceGroups/prod-rg",
+			expected: resourceID{subscription: "0000-1111", resourceGroup: "prod-rg"},
+			valid:    true,
+		},
+		{id: "/subscriptions/0000-1111/resourceGroups/prod-rg/providers/Microsoft.Web"},
+		{id: "/subscriptions/0000-1111/resourceGroups/prod-rg/providers/Microsoft.Web/sites"},
+		{id: "/tenants/0000-1111"},
+		{id: "checkout"},
+	} {
+		t.Run(tt.id, func(t *testing.T) {
+			parsed, ok := parseResourceID(tt.id)
+			require.Equal(t, tt.valid, ok)
+			if ok {
+				assert.Equal(t, tt.expected, parsed)
+			}
+		})
+	}
+}
+
+const siteID = "/SUBSCRIPTIONS/0000-1111/RESOURCEGROUPS/PROD-RG/PROVIDERS/MICROSOFT.WEB/SITES/CHECKOUT"
+
+var expectedAttributes = map[string]any{
+	"azure.resource.id":         siteID,
+	"cloud.provider":            "azure",
+	"cloud.account.id":          "0000-1111",
+	"azure.resource_group.name": "PROD-RG",
+	"azure.resource.type":       "MICROSOFT.WEB/SITES",
+	"azure.resource.name":       "CHECKOUT",
+}
+
+func TestProcessLogs(t *testing.T) {
+	ap := newAzureResourceIDProcessor(createDefaultConfig().(*Config))
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().Resource().Attributes().PutStr("azure.resource.id", siteID)
+	ld.ResourceLogs().AppendEmpty().Resource().Attributes().PutStr("azure.resource.id", "invalid")
+	ld.ResourceLogs().AppendEmpty()
+
+	ld, err := ap.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, expectedAttributes, ld.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"azure.resource.id": "invalid"}, ld.ResourceLogs().At(1).Resource().Attributes().AsRaw())
+	assert.Empty(t, ld.ResourceLogs().At(2).Resource().Attributes().AsRaw())
+}
+
+func TestProcessMetricsAndTraces(t *testing.T) {
+	ap := newAzureResourceIDProcessor(createDefaultConfig().(*Config))
+
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().Resource().Attributes().PutStr("azure.resource.id", siteID)
+	md, err := ap.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, expectedAttributes, md.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().Resource().Attributes().PutStr("azure.resource.id", siteID)
+	td, err = ap.processTraces(context.Background(), td)
+	require.NoError(t, err)
+	assert.Equal(t, expectedAttributes, td.ResourceSpans().At(0).Resource().Attributes().AsRaw())
+}
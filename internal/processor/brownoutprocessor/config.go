@@ -0,0 +1,76 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brownoutprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines how a pipeline sheds load as the brownout level rises.
+type Config struct {
+	// Brownout is the brownout extension providing the brownout level.
+	Brownout component.ID `mapstructure:"brownout"`
+	// Sampling are the percentages of traces and logs kept from a brownout level on.
+	Sampling []SamplingConfig `mapstructure:"sampling"`
+	// RejectLevel is the brownout level from which all data is rejected. Lower values
+	// should be used for the pipelines of lower priority receivers. 0 disables rejection.
+	RejectLevel int `mapstructure:"reject_level"`
+}
+
+// SamplingConfig is the percentage of traces and logs kept from a brownout level on.
+type SamplingConfig struct {
+	Level      int     `mapstructure:"level"`
+	Percentage float64 `mapstructure:"percentage"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.RejectLevel < 0 {
+		errs = errors.Join(errs, errors.New("reject_level must not be negative"))
+	}
+	levels := map[int]struct{}{}
+	for _, s := range cfg.Sampling {
+		if s.Level <= 0 {
+			errs = errors.Join(errs, fmt.Errorf("sampling level %d must be positive", s.Level))
+		}
+		if _, ok := levels[s.Level]; ok {
+			errs = errors.Join(errs, fmt.Errorf("sampling level %d is configured more than once", s.Level))
+		}
+		levels[s.Level] = struct{}{}
+		if s.Percentage < 0 || s.Percentage > 100 {
+			errs = errors.Join(errs, fmt.Errorf("sampling percentage of level %d must be between 0 and 100", s.Level))
+		}
+	}
+	if len(cfg.Sampling) == 0 && cfg.RejectLevel == 0 {
+		errs = errors.Join(errs, errors.New("at least one of reject_level or sampling must be specified"))
+	}
+	return errs
+}
+
+// samplingPercentage returns the percentage of traces and logs kept at the brownout level.
+func (cfg *Config) samplingPercentage(level int) float64 {
+	percentage, from := 100.0, 0
+	for _, s := range cfg.Sampling {
+		if s.Level <= level && s.Level > from {
+			percentage, from = s.Percentage, s.Level
+		}
+	}
+	return percentage
+}
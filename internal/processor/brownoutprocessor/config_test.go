@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brownoutprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Brownout:    component.MustNewID("brownout"),
+				RejectLevel: 2,
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				Brownout:    component.MustNewIDWithName("brownout", "gateway"),
+				RejectLevel: 3,
+				Sampling: []SamplingConfig{
+					{Level: 1, Percentage: 50},
+					{Level: 2, Percentage: 10},
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "reject_level must not be negative\n" +
+				"sampling level 0 must be positive\n" +
+				"sampling percentage of level 2 must be between 0 and 100\n" +
+				"sampling level 2 is configured more than once",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateRequiresRejectLevelOrSampling(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.EqualError(t, cfg.Validate(), "at least one of reject_level or sampling must be specified")
+}
+
+func TestSamplingPercentage(t *testing.T) {
+	cfg := &Config{Sampling: []SamplingConfig{
+		{Level: 3, Percentage: 10},
+		{Level: 1, Percentage: 50},
+	}}
+	assert.Equal(t, 100.0, cfg.samplingPercentage(0))
+	assert.Equal(t, 50.0, cfg.samplingPercentage(1))
+	assert.Equal(t, 50.0, cfg.samplingPercentage(2))
+	assert.Equal(t, 10.0, cfg.samplingPercentage(3))
+	assert.Equal(t, 10.0, cfg.samplingPercentage(4))
+}
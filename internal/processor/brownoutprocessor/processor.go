@@ -0,0 +1,128 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brownoutprocessor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+
+	"github.com/signalfx/splunk-otel-collector/internal/extension/brownoutextension"
+)
+
+// brownoutProcessor rejects or samples the data of its pipeline according to the
+// brownout level of the brownout extension. Rejections aren't permanent errors so
+// senders are told to retry, preferably against another gateway.
+type brownoutProcessor struct {
+	config   *Config
+	brownout brownoutextension.Brownout
+}
+
+func newBrownoutProcessor(config *Config) *brownoutProcessor {
+	return &brownoutProcessor{config: config}
+}
+
+func (bp *brownoutProcessor) start(_ context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[bp.config.Brownout]
+	if !ok {
+		return fmt.Errorf("brownout extension %q not found", bp.config.Brownout)
+	}
+	if bp.brownout, ok = ext.(brownoutextension.Brownout); !ok {
+		return fmt.Errorf("extension %q is not a brownout extension", bp.config.Brownout)
+	}
+	return nil
+}
+
+// level returns the current brownout level, or an error if data is rejected at it.
+func (bp *brownoutProcessor) level() (int, error) {
+	level := bp.brownout.Level()
+	if bp.config.RejectLevel > 0 && level >= bp.config.RejectLevel {
+		return level, fmt.Errorf("data rejected at brownout level %d", level)
+	}
+	return level, nil
+}
+
+func (bp *brownoutProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	_, err := bp.level()
+	return md, err
+}
+
+func (bp *brownoutProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	level, err := bp.level()
+	if err != nil {
+		return td, err
+	}
+	percentage := bp.config.samplingPercentage(level)
+	if percentage >= 100 {
+		return td, nil
+	}
+	td.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			ss.Spans().RemoveIf(func(span ptrace.Span) bool {
+				return !sampled(span.TraceID(), percentage)
+			})
+			return ss.Spans().Len() == 0
+		})
+		return rs.ScopeSpans().Len() == 0
+	})
+	if td.ResourceSpans().Len() == 0 {
+		return td, processorhelper.ErrSkipProcessingData
+	}
+	return td, nil
+}
+
+func (bp *brownoutProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	level, err := bp.level()
+	if err != nil {
+		return ld, err
+	}
+	percentage := bp.config.samplingPercentage(level)
+	if percentage >= 100 {
+		return ld, nil
+	}
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				return !sampled(lr.TraceID(), percentage)
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// sampled returns whether the item of the trace is kept. All the spans and logs of a
+// trace are kept or dropped together, while items without a trace are sampled randomly.
+func sampled(traceID pcommon.TraceID, percentage float64) bool {
+	var bucket uint64
+	if traceID.IsEmpty() {
+		bucket = rand.Uint64() // nolint:gosec // sampling doesn't require a secure random number
+	} else {
+		bucket = binary.BigEndian.Uint64(traceID[8:])
+	}
+	return float64(bucket%10000) < percentage*100
+}
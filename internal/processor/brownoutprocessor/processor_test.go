@@ -0,0 +1,153 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package brownoutprocessor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+type fakeBrownout struct {
+	component.StartFunc
+	component.ShutdownFunc
+	level int
+}
+
+func (f *fakeBrownout) Level() int {
+	return f.level
+}
+
+type hostWithExtensions struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func (h hostWithExtensions) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+func newHost(ext extension.Extension) component.Host {
+	return hostWithExtensions{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]extension.Extension{component.MustNewID("brownout"): ext},
+	}
+}
+
+func testTraces() ptrace.Traces {
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	for _, traceID := range []pcommon.TraceID{
+		{15: 0x01},           // bucket 1, kept at 50%
+		{14: 0x1f, 15: 0x40}, // bucket 8000, dropped at 50%
+	} {
+		span := spans.AppendEmpty()
+		span.SetTraceID(traceID)
+	}
+	return td
+}
+
+func TestStartRequiresBrownoutExtension(t *testing.T) {
+	cfg := &Config{Brownout: component.MustNewID("brownout"), RejectLevel: 1}
+	p, err := NewFactory().CreateMetrics(context.Background(), processortest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.EqualError(t, p.Start(context.Background(), componenttest.NewNopHost()), `brownout extension "brownout" not found`)
+
+	notBrownout := struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}
+	require.EqualError(t, p.Start(context.Background(), newHost(notBrownout)), `extension "brownout" is not a brownout extension`)
+}
+
+func TestRejectLevel(t *testing.T) {
+	brownout := &fakeBrownout{}
+	cfg := &Config{Brownout: component.MustNewID("brownout"), RejectLevel: 2}
+	sink := &consumertest.MetricsSink{}
+	p, err := NewFactory().CreateMetrics(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(context.Background(), newHost(brownout)))
+
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+	for level, rejected := range []bool{false, false, true, true} {
+		brownout.level = level
+		err = p.ConsumeMetrics(context.Background(), md)
+		if rejected {
+			require.EqualError(t, err, fmt.Sprintf("data rejected at brownout level %d", level))
+		} else {
+			require.NoError(t, err)
+		}
+	}
+	assert.Len(t, sink.AllMetrics(), 2)
+}
+
+func TestSampleTraces(t *testing.T) {
+	brownout := &fakeBrownout{}
+	cfg := &Config{
+		Brownout: component.MustNewID("brownout"),
+		Sampling: []SamplingConfig{{Level: 1, Percentage: 50}, {Level: 2, Percentage: 0}},
+	}
+	sink := &consumertest.TracesSink{}
+	p, err := NewFactory().CreateTraces(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(context.Background(), newHost(brownout)))
+
+	require.NoError(t, p.ConsumeTraces(context.Background(), testTraces()))
+	brownout.level = 1
+	require.NoError(t, p.ConsumeTraces(context.Background(), testTraces()))
+	brownout.level = 2
+	require.NoError(t, p.ConsumeTraces(context.Background(), testTraces()))
+
+	traces := sink.AllTraces()
+	require.Len(t, traces, 2)
+	assert.Equal(t, 2, traces[0].SpanCount())
+	require.Equal(t, 1, traces[1].SpanCount())
+	assert.Equal(t, testTraces().ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID(),
+		traces[1].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID())
+}
+
+func TestSampleLogs(t *testing.T) {
+	brownout := &fakeBrownout{level: 1}
+	cfg := &Config{
+		Brownout: component.MustNewID("brownout"),
+		Sampling: []SamplingConfig{{Level: 1, Percentage: 0}},
+	}
+	sink := &consumertest.LogsSink{}
+	p, err := NewFactory().CreateLogs(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(context.Background(), newHost(brownout)))
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("dropped")
+	require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+	assert.Empty(t, sink.AllLogs())
+
+	brownout.level = 0
+	require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+	assert.Len(t, sink.AllLogs(), 1)
+}
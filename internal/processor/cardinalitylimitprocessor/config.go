@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimitprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	gaugeAggregationSum  = "sum"
+	gaugeAggregationMin  = "min"
+	gaugeAggregationMax  = "max"
+	gaugeAggregationLast = "last"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the limited attributes and how the data points of their other values are aggregated.
+type Config struct {
+	// OtherValue replaces the values of limited attributes that aren't in the top K.
+	OtherValue string `mapstructure:"other_value"`
+	// GaugeAggregation is how the gauge data points whose attributes became the same are
+	// aggregated: sum, min, max, or last, the value of the latest data point.
+	GaugeAggregation string `mapstructure:"gauge_aggregation"`
+	// Attributes are the data point attributes limited to their top K values per metric.
+	Attributes []string `mapstructure:"attributes"`
+	// Metrics override Attributes and TopK for the metrics they match. Only the first matching
+	// override is applied to a metric.
+	Metrics []MetricConfig `mapstructure:"metrics"`
+	// TopK is the number of values kept per metric and attribute.
+	TopK int `mapstructure:"top_k"`
+	// Window is the interval at which the top K values are recomputed from the recent number of
+	// data points with each value.
+	Window time.Duration `mapstructure:"window"`
+}
+
+// MetricConfig overrides the limits of the metrics it matches.
+type MetricConfig struct {
+	// Match is a regular expression the metric name must match.
+	Match string `mapstructure:"match"`
+	// Attributes replace the limited attributes of the matched metrics if specified.
+	Attributes []string `mapstructure:"attributes"`
+	// TopK replaces the number of values kept per attribute of the matched metrics if specified.
+	TopK int `mapstructure:"top_k"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Attributes) == 0 && len(cfg.Metrics) == 0 {
+		errs = errors.Join(errs, errors.New("at least one of attributes or metrics must be specified"))
+	}
+	if cfg.TopK <= 0 {
+		errs = errors.Join(errs, errors.New("top_k must be positive"))
+	}
+	if cfg.Window <= 0 {
+		errs = errors.Join(errs, errors.New("window must be positive"))
+	}
+	if cfg.OtherValue == "" {
+		errs = errors.Join(errs, errors.New("other_value must not be empty"))
+	}
+	switch cfg.GaugeAggregation {
+	case gaugeAggregationSum, gaugeAggregationMin, gaugeAggregationMax, gaugeAggregationLast:
+	default:
+		errs = errors.Join(errs, fmt.Errorf("gauge_aggregation must be one of %q, %q, %q or %q",
+			gaugeAggregationSum, gaugeAggregationMin, gaugeAggregationMax, gaugeAggregationLast))
+	}
+	for i, m := range cfg.Metrics {
+		if m.Match == "" {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: match must be specified", i))
+		} else if _, err := regexp.Compile(m.Match); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: invalid match: %w", i, err))
+		}
+		if m.TopK < 0 {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: top_k must not be negative", i))
+		}
+	}
+	return errs
+}
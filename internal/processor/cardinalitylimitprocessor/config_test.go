@@ -0,0 +1,89 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimitprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Attributes:       []string{"user.id"},
+				TopK:             100,
+				Window:           10 * time.Minute,
+				OtherValue:       "other",
+				GaugeAggregation: "last",
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				Attributes:       []string{"user.id", "session.id"},
+				TopK:             50,
+				Window:           time.Minute,
+				OtherValue:       "_other_",
+				GaugeAggregation: "max",
+				Metrics: []MetricConfig{
+					{Match: `^http\.`, Attributes: []string{"http.route"}, TopK: 20},
+					{Match: `^checkout\.`, TopK: 5},
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "top_k must be positive\n" +
+				"window must be positive\n" +
+				"other_value must not be empty\n" +
+				`gauge_aggregation must be one of "sum", "min", "max" or "last"` + "\n" +
+				"metrics[0]: match must be specified\n" +
+				"metrics[0]: top_k must not be negative\n" +
+				"metrics[1]: invalid match: error parsing regexp: missing closing ): `(`",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateWithoutAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.EqualError(t, cfg.Validate(), "at least one of attributes or metrics must be specified")
+}
@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimitprocessor
+
+import (
+	"sort"
+)
+
+// maxTrackedFactor bounds the number of values whose volume is tracked per limiter, as a
+// multiple of its K, so unbounded attributes don't grow the memory of the processor.
+const maxTrackedFactor = 10
+
+// limiter keeps the top K values of an attribute of a metric, by the number of data points with
+// each value in the recent windows.
+type limiter struct {
+	volumes map[string]float64
+	kept    map[string]struct{}
+	k       int
+}
+
+func newLimiter(k int) *limiter {
+	return &limiter{k: k, volumes: map[string]float64{}, kept: map[string]struct{}{}}
+}
+
+// keep counts a data point with the value, and returns whether the value is kept. Until the top
+// K values are known at the end of the first window, or while fewer than K are, values are kept
+// in the order they're seen.
+func (l *limiter) keep(value string) bool {
+	if _, ok := l.volumes[value]; ok || len(l.volumes) < l.k*maxTrackedFactor {
+		l.volumes[value]++
+	}
+	if _, ok := l.kept[value]; ok {
+		return true
+	}
+	if len(l.kept) < l.k {
+		l.kept[value] = struct{}{}
+		return true
+	}
+	return false
+}
+
+// roll keeps the top K values by volume at the end of a window, and halves the volumes, so each
+// window weighs half as much as the next one. Values whose volume falls below one data point stop
+// being tracked, which makes room for new values. It returns whether any value is still tracked.
+func (l *limiter) roll() bool {
+	values := make([]string, 0, len(l.volumes))
+	for v := range l.volumes {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if l.volumes[values[i]] != l.volumes[values[j]] {
+			return l.volumes[values[i]] > l.volumes[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	clear(l.kept)
+	for _, v := range values[:min(l.k, len(values))] {
+		l.kept[v] = struct{}{}
+	}
+	for _, v := range values {
+		if l.volumes[v] /= 2; l.volumes[v] < 1 {
+			delete(l.volumes, v)
+		}
+	}
+	return len(l.volumes) > 0
+}
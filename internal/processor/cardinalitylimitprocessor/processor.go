@@ -0,0 +1,278 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimitprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+type limiterKey struct {
+	metric    string
+	attribute string
+}
+
+// override is a compiled MetricConfig.
+type override struct {
+	re         *regexp.Regexp
+	attributes []string
+	topK       int
+}
+
+// cardinalityLimitProcessor limits the values of attributes of metrics to their top K values, by
+// replacing the other values by an other value and aggregating the data points whose attributes
+// became the same.
+type cardinalityLimitProcessor struct {
+	rolled    time.Time
+	config    *Config
+	now       func() time.Time
+	limiters  map[limiterKey]*limiter
+	aggregate func(into, dp pmetric.NumberDataPoint)
+	overrides []override
+	mu        sync.Mutex
+}
+
+func newCardinalityLimitProcessor(cfg *Config) *cardinalityLimitProcessor {
+	cp := &cardinalityLimitProcessor{
+		config:   cfg,
+		now:      time.Now,
+		limiters: map[limiterKey]*limiter{},
+	}
+	cp.rolled = cp.now()
+	for _, m := range cfg.Metrics {
+		cp.overrides = append(cp.overrides, override{re: regexp.MustCompile(m.Match), attributes: m.Attributes, topK: m.TopK})
+	}
+	switch cfg.GaugeAggregation {
+	case gaugeAggregationSum:
+		cp.aggregate = addNumber
+	case gaugeAggregationMin:
+		cp.aggregate = func(into, dp pmetric.NumberDataPoint) {
+			if numberValue(dp) < numberValue(into) {
+				setNumber(into, dp)
+			}
+		}
+	case gaugeAggregationMax:
+		cp.aggregate = func(into, dp pmetric.NumberDataPoint) {
+			if numberValue(dp) > numberValue(into) {
+				setNumber(into, dp)
+			}
+		}
+	default:
+		cp.aggregate = func(into, dp pmetric.NumberDataPoint) {
+			if dp.Timestamp() > into.Timestamp() {
+				setNumber(into, dp)
+			}
+		}
+	}
+	return cp
+}
+
+// limits returns the limited attributes and K of the metric.
+func (cp *cardinalityLimitProcessor) limits(name string) ([]string, int) {
+	attributes, topK := cp.config.Attributes, cp.config.TopK
+	for _, o := range cp.overrides {
+		if !o.re.MatchString(name) {
+			continue
+		}
+		if len(o.attributes) > 0 {
+			attributes = o.attributes
+		}
+		if o.topK > 0 {
+			topK = o.topK
+		}
+		break
+	}
+	return attributes, topK
+}
+
+func (cp *cardinalityLimitProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if now := cp.now(); now.Sub(cp.rolled) >= cp.config.Window {
+		for key, l := range cp.limiters {
+			if !l.roll() {
+				delete(cp.limiters, key)
+			}
+		}
+		cp.rolled = now
+	}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		sms := md.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				cp.limitMetric(ms.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (cp *cardinalityLimitProcessor) limitMetric(m pmetric.Metric) {
+	attributes, topK := cp.limits(m.Name())
+	if len(attributes) == 0 {
+		return
+	}
+	limit := func(attrs pcommon.Map) bool {
+		return cp.limitAttributes(m.Name(), attributes, topK, attrs)
+	}
+	switch m.Type() {
+	case pmetric.MetricTypeSum:
+		if limitNumbers(m.Sum().DataPoints(), limit) {
+			mergeNumbers(m.Sum().DataPoints(), addNumber)
+		}
+	case pmetric.MetricTypeGauge:
+		if limitNumbers(m.Gauge().DataPoints(), limit) {
+			mergeNumbers(m.Gauge().DataPoints(), cp.aggregate)
+		}
+	case pmetric.MetricTypeHistogram:
+		changed := false
+		for i := 0; i < m.Histogram().DataPoints().Len(); i++ {
+			changed = limit(m.Histogram().DataPoints().At(i).Attributes()) || changed
+		}
+		if changed {
+			mergeHistograms(m.Histogram().DataPoints())
+		}
+	}
+}
+
+// limitAttributes replaces the values of the attributes that aren't kept by the other value,
+// and returns whether any was replaced. Must be called with the lock held.
+func (cp *cardinalityLimitProcessor) limitAttributes(metric string, attributes []string, topK int, attrs pcommon.Map) bool {
+	replaced := false
+	for _, attribute := range attributes {
+		v, ok := attrs.Get(attribute)
+		if !ok {
+			continue
+		}
+		key := limiterKey{metric: metric, attribute: attribute}
+		l, ok := cp.limiters[key]
+		if !ok {
+			l = newLimiter(topK)
+			cp.limiters[key] = l
+		}
+		if !l.keep(v.AsString()) {
+			attrs.PutStr(attribute, cp.config.OtherValue)
+			replaced = true
+		}
+	}
+	return replaced
+}
+
+func limitNumbers(dps pmetric.NumberDataPointSlice, limit func(pcommon.Map) bool) bool {
+	changed := false
+	for i := 0; i < dps.Len(); i++ {
+		changed = limit(dps.At(i).Attributes()) || changed
+	}
+	return changed
+}
+
+// mergeNumbers aggregates the data points with the same attributes into the first of them.
+func mergeNumbers(dps pmetric.NumberDataPointSlice, aggregate func(into, dp pmetric.NumberDataPoint)) {
+	merged := map[string]pmetric.NumberDataPoint{}
+	dps.RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+		key := attributesKey(dp.Attributes(), nil)
+		into, ok := merged[key]
+		if !ok {
+			merged[key] = dp
+			return false
+		}
+		aggregate(into, dp)
+		into.SetStartTimestamp(minStart(into.StartTimestamp(), dp.StartTimestamp()))
+		into.SetTimestamp(max(into.Timestamp(), dp.Timestamp()))
+		dp.Exemplars().MoveAndAppendTo(into.Exemplars())
+		return true
+	})
+}
+
+// mergeHistograms aggregates the data points with the same attributes and bounds into the first
+// of them.
+func mergeHistograms(dps pmetric.HistogramDataPointSlice) {
+	merged := map[string]pmetric.HistogramDataPoint{}
+	dps.RemoveIf(func(dp pmetric.HistogramDataPoint) bool {
+		key := attributesKey(dp.Attributes(), dp.ExplicitBounds().AsRaw())
+		into, ok := merged[key]
+		if !ok || into.BucketCounts().Len() != dp.BucketCounts().Len() {
+			merged[key] = dp
+			return false
+		}
+		into.SetCount(into.Count() + dp.Count())
+		if into.HasSum() && dp.HasSum() {
+			into.SetSum(into.Sum() + dp.Sum())
+		} else {
+			into.RemoveSum()
+		}
+		if into.HasMin() && dp.HasMin() {
+			into.SetMin(min(into.Min(), dp.Min()))
+		} else {
+			into.RemoveMin()
+		}
+		if into.HasMax() && dp.HasMax() {
+			into.SetMax(max(into.Max(), dp.Max()))
+		} else {
+			into.RemoveMax()
+		}
+		for i := 0; i < dp.BucketCounts().Len(); i++ {
+			into.BucketCounts().SetAt(i, into.BucketCounts().At(i)+dp.BucketCounts().At(i))
+		}
+		into.SetStartTimestamp(minStart(into.StartTimestamp(), dp.StartTimestamp()))
+		into.SetTimestamp(max(into.Timestamp(), dp.Timestamp()))
+		dp.Exemplars().MoveAndAppendTo(into.Exemplars())
+		return true
+	})
+}
+
+func attributesKey(attrs pcommon.Map, bounds []float64) string {
+	// maps are marshaled with sorted keys
+	key, _ := json.Marshal([]any{attrs.AsRaw(), bounds})
+	return string(key)
+}
+
+// minStart returns the earliest of the start timestamps, ignoring unset ones.
+func minStart(a, b pcommon.Timestamp) pcommon.Timestamp {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
+
+func setNumber(into, dp pmetric.NumberDataPoint) {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		into.SetIntValue(dp.IntValue())
+	} else {
+		into.SetDoubleValue(dp.DoubleValue())
+	}
+}
+
+func addNumber(into, dp pmetric.NumberDataPoint) {
+	if into.ValueType() == pmetric.NumberDataPointValueTypeInt && dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		into.SetIntValue(into.IntValue() + dp.IntValue())
+		return
+	}
+	into.SetDoubleValue(numberValue(into) + numberValue(dp))
+}
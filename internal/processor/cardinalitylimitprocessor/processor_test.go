@@ -0,0 +1,224 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimitprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestProcessor(cfg *Config) (*cardinalityLimitProcessor, *time.Time) {
+	cp := newCardinalityLimitProcessor(cfg)
+	var now *time.Time
+	cp.now, now = testclock.New(epoch)
+	cp.rolled = epoch
+	return cp, now
+}
+
+type point struct {
+	user  string
+	value int64
+}
+
+func newMetrics(name string, newMetric func(pmetric.Metric) pmetric.NumberDataPointSlice, points ...point) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName(name)
+	dps := newMetric(m)
+	for i, p := range points {
+		dp := dps.AppendEmpty()
+		dp.Attributes().PutStr("user.id", p.user)
+		dp.Attributes().PutStr("region", "eu")
+		dp.SetTimestamp(pcommon.Timestamp(i + 1))
+		dp.SetIntValue(p.value)
+	}
+	return md
+}
+
+func newSum(m pmetric.Metric) pmetric.NumberDataPointSlice {
+	m.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	return m.Sum().DataPoints()
+}
+
+func newGauge(m pmetric.Metric) pmetric.NumberDataPointSlice {
+	return m.SetEmptyGauge().DataPoints()
+}
+
+func points(t *testing.T, md pmetric.Metrics) map[string]int64 {
+	m := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	var dps pmetric.NumberDataPointSlice
+	if m.Type() == pmetric.MetricTypeSum {
+		dps = m.Sum().DataPoints()
+	} else {
+		dps = m.Gauge().DataPoints()
+	}
+	values := map[string]int64{}
+	for i := 0; i < dps.Len(); i++ {
+		user, _ := dps.At(i).Attributes().Get("user.id")
+		assert.NotContains(t, values, user.Str())
+		values[user.Str()] = dps.At(i).IntValue()
+	}
+	return values
+}
+
+func TestSums(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []string{"user.id"}
+	cfg.TopK = 2
+	cfg.Window = time.Minute
+	cp, now := newTestProcessor(cfg)
+
+	// the first values are kept until the end of the first window
+	md, err := cp.processMetrics(context.Background(), newMetrics("requests", newSum, point{"a", 1}, point{"b", 2}, point{"c", 3}, point{"d", 4}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 1, "b": 2, "other": 7}, points(t, md))
+	dp := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(2)
+	assert.Equal(t, pcommon.Timestamp(4), dp.Timestamp())
+	assert.Equal(t, map[string]any{"user.id": "other", "region": "eu"}, dp.Attributes().AsRaw())
+
+	md, err = cp.processMetrics(context.Background(), newMetrics("requests", newSum, point{"c", 1}, point{"d", 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"other": 2}, points(t, md))
+
+	// the values with the most data points in the window are kept in the next
+	*now = now.Add(time.Minute)
+	md, err = cp.processMetrics(context.Background(), newMetrics("requests", newSum, point{"a", 1}, point{"b", 1}, point{"c", 1}, point{"d", 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"c": 1, "d": 1, "other": 2}, points(t, md))
+
+	// other metrics are limited separately
+	md, err = cp.processMetrics(context.Background(), newMetrics("errors", newSum, point{"a", 1}, point{"b", 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 1, "b": 1}, points(t, md))
+}
+
+func TestGaugeAggregation(t *testing.T) {
+	for _, tt := range []struct {
+		aggregation string
+		expected    int64
+	}{
+		{aggregation: gaugeAggregationSum, expected: 9},
+		{aggregation: gaugeAggregationMin, expected: 1},
+		{aggregation: gaugeAggregationMax, expected: 5},
+		{aggregation: gaugeAggregationLast, expected: 3},
+	} {
+		t.Run(tt.aggregation, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Attributes = []string{"user.id"}
+			cfg.TopK = 1
+			cfg.GaugeAggregation = tt.aggregation
+			cp, _ := newTestProcessor(cfg)
+
+			md, err := cp.processMetrics(context.Background(), newMetrics("sessions", newGauge, point{"a", 1}, point{"b", 1}, point{"c", 5}, point{"d", 3}))
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int64{"a": 1, "other": tt.expected}, points(t, md))
+		})
+	}
+}
+
+func TestHistograms(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []string{"user.id"}
+	cfg.TopK = 1
+	cp, _ := newTestProcessor(cfg)
+
+	md := pmetric.NewMetrics()
+	h := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyHistogram()
+	for i, user := range []string{"a", "b", "c"} {
+		dp := h.DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("user.id", user)
+		dp.ExplicitBounds().FromRaw([]float64{10})
+		dp.BucketCounts().FromRaw([]uint64{uint64(i), 1})
+		dp.SetCount(uint64(i) + 1)
+		dp.SetSum(float64(i * 10))
+		dp.SetMin(float64(i))
+		dp.SetMax(float64(i * 20))
+		dp.SetStartTimestamp(pcommon.Timestamp(10 - i))
+		dp.SetTimestamp(pcommon.Timestamp(10 + i))
+	}
+
+	_, err := cp.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+	require.Equal(t, 2, h.DataPoints().Len())
+	dp := h.DataPoints().At(1)
+	assert.Equal(t, map[string]any{"user.id": "other"}, dp.Attributes().AsRaw())
+	assert.Equal(t, []uint64{3, 2}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(5), dp.Count())
+	assert.InDelta(t, 30, dp.Sum(), 0)
+	assert.InDelta(t, 1, dp.Min(), 0)
+	assert.InDelta(t, 40, dp.Max(), 0)
+	assert.Equal(t, pcommon.Timestamp(8), dp.StartTimestamp())
+	assert.Equal(t, pcommon.Timestamp(12), dp.Timestamp())
+}
+
+func TestOverrides(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []string{"user.id"}
+	cfg.TopK = 1
+	cfg.Metrics = []MetricConfig{
+		{Match: "^checkout", TopK: 2},
+		{Match: "^http", Attributes: []string{"http.route"}},
+	}
+	cp, _ := newTestProcessor(cfg)
+
+	md, err := cp.processMetrics(context.Background(), newMetrics("checkout.orders", newSum, point{"a", 1}, point{"b", 1}, point{"c", 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 1, "b": 1, "other": 1}, points(t, md))
+
+	md, err = cp.processMetrics(context.Background(), newMetrics("http.requests", newSum, point{"a", 1}, point{"b", 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 1, "b": 1}, points(t, md))
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(2)
+	assert.True(t, l.keep("a"))
+	assert.True(t, l.keep("b"))
+	assert.False(t, l.keep("c"))
+	assert.False(t, l.keep("c"))
+	assert.False(t, l.keep("c"))
+	assert.True(t, l.keep("a"))
+
+	require.True(t, l.roll())
+	assert.Equal(t, map[string]struct{}{"a": {}, "c": {}}, l.kept)
+	// b fell below one data point
+	assert.Equal(t, map[string]float64{"a": 1, "c": 1.5}, l.volumes)
+
+	// the kept values stay kept until the next roll, after which nothing is tracked
+	assert.True(t, l.keep("a"))
+	assert.False(t, l.keep("b"))
+	require.True(t, l.roll())
+	assert.Equal(t, map[string]struct{}{"a": {}, "c": {}}, l.kept)
+	require.False(t, l.roll())
+	assert.Empty(t, l.volumes)
+}
+
+func TestLimiterBoundsTrackedValues(t *testing.T) {
+	l := newLimiter(1)
+	for i := 0; i < 100; i++ {
+		l.keep(string(rune('a' + i)))
+	}
+	assert.Len(t, l.volumes, maxTrackedFactor)
+}
@@ -0,0 +1,135 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the unix epoch.
+const ntpEpochOffset = 2208988800
+
+// clock is the reference clock the timestamps of the telemetry are compared to: the local clock,
+// corrected by its offset to an NTP server if configured.
+type clock struct {
+	now    func() time.Time
+	query  func(server string, timeout time.Duration) (time.Duration, error)
+	logger *zap.Logger
+	cfg    ClockSourceConfig
+	offset atomic.Int64
+}
+
+func newClock(cfg ClockSourceConfig, logger *zap.Logger) *clock {
+	return &clock{now: time.Now, query: queryNTPOffset, logger: logger, cfg: cfg}
+}
+
+// Now returns the current time of the reference clock.
+func (c *clock) Now() time.Time {
+	return c.now().Add(time.Duration(c.offset.Load()))
+}
+
+// run measures the offset of the local clock to the NTP server every interval until ctx is done.
+// Failed measurements keep the last measured offset.
+func (c *clock) run(ctx context.Context) {
+	if c.cfg.Type != clockSourceNTP {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		c.measure()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *clock) measure() {
+	offset, err := c.query(c.cfg.NTPServer, c.cfg.Timeout)
+	if err != nil {
+		c.logger.Warn("Failed to measure the local clock offset", zap.String("ntp_server", c.cfg.NTPServer), zap.Error(err))
+		return
+	}
+	c.offset.Store(int64(offset))
+	c.logger.Debug("Measured the local clock offset", zap.String("ntp_server", c.cfg.NTPServer), zap.Duration("offset", offset))
+}
+
+// queryNTPOffset returns the offset of the local clock to the NTP server with a single SNTP query
+// (RFC 4330): ((t2 - t1) + (t3 - t4)) / 2, with t1 and t4 the local send and receive times and
+// t2 and t3 the server's receive and transmit times.
+func queryNTPOffset(server string, timeout time.Duration) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	req := make([]byte, 48)
+	// leap indicator 0, version 4, client mode
+	req[0] = 0x23
+	t1 := time.Now()
+	putNTPTime(req[40:], t1)
+	if _, err = conn.Write(req); err != nil {
+		return 0, err
+	}
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	t4 := time.Now()
+	if err != nil {
+		return 0, err
+	}
+	return ntpOffset(resp[:n], req[40:48], t1, t4)
+}
+
+// ntpOffset returns the clock offset of the NTP response to the request with the originate timestamp.
+func ntpOffset(resp, originate []byte, t1, t4 time.Time) (time.Duration, error) {
+	if len(resp) < 48 {
+		return 0, errors.New("short NTP response")
+	}
+	if mode := resp[0] & 0x7; mode != 4 {
+		return 0, errors.New("NTP response isn't in server mode")
+	}
+	if stratum := resp[1]; stratum == 0 || stratum > 15 {
+		return 0, errors.New("NTP server is unsynchronized")
+	}
+	if string(resp[24:32]) != string(originate) {
+		return 0, errors.New("NTP response doesn't match the request")
+	}
+	t2, t3 := ntpTime(resp[32:40]), ntpTime(resp[40:48])
+	return (t2.Sub(t1) + t3.Sub(t4)) / 2, nil
+}
+
+func ntpTime(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[:4])) - ntpEpochOffset
+	fraction := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(seconds, fraction*int64(time.Second)>>32)
+}
+
+func putNTPTime(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/int64(time.Second)))
+}
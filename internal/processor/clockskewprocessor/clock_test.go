@@ -0,0 +1,71 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNTPOffset(t *testing.T) {
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t4 := t1.Add(100 * time.Millisecond)
+	originate := make([]byte, 8)
+	putNTPTime(originate, t1)
+
+	// the server's clock is 2s ahead, with a symmetric 50ms network delay
+	resp := make([]byte, 48)
+	resp[0] = 0x24
+	resp[1] = 2
+	copy(resp[24:32], originate)
+	putNTPTime(resp[32:40], t1.Add(2*time.Second+50*time.Millisecond))
+	putNTPTime(resp[40:48], t1.Add(2*time.Second+50*time.Millisecond))
+	offset, err := ntpOffset(resp, originate, t1, t4)
+	require.NoError(t, err)
+	assert.InDelta(t, float64(2*time.Second), float64(offset), float64(time.Microsecond))
+
+	resp[1] = 0
+	_, err = ntpOffset(resp, originate, t1, t4)
+	assert.EqualError(t, err, "NTP server is unsynchronized")
+
+	resp[1] = 2
+	putNTPTime(resp[24:32], t1.Add(time.Second))
+	_, err = ntpOffset(resp, originate, t1, t4)
+	assert.EqualError(t, err, "NTP response doesn't match the request")
+
+	_, err = ntpOffset(resp[:47], originate, t1, t4)
+	assert.EqualError(t, err, "short NTP response")
+}
+
+func TestClockMeasure(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newClock(ClockSourceConfig{Type: clockSourceNTP, NTPServer: "ntp:123"}, zap.NewNop())
+	c.now = func() time.Time { return now }
+	assert.Equal(t, now, c.Now())
+
+	c.query = func(string, time.Duration) (time.Duration, error) { return -3 * time.Second, nil }
+	c.measure()
+	assert.Equal(t, now.Add(-3*time.Second), c.Now())
+
+	// failed measurements keep the last offset
+	c.query = func(string, time.Duration) (time.Duration, error) { return 0, errors.New("timeout") }
+	c.measure()
+	assert.Equal(t, now.Add(-3*time.Second), c.Now())
+}
@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	clockSourceSystem = "system"
+	clockSourceNTP    = "ntp"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the reference clock and the bounds of the timestamp corrections.
+type Config struct {
+	// ClockSource is the clock the timestamps of the telemetry are compared to.
+	ClockSource ClockSourceConfig `mapstructure:"clock_source"`
+	// Tolerance is the skew below which timestamps aren't corrected.
+	Tolerance time.Duration `mapstructure:"tolerance"`
+	// MaxCorrection bounds the correction of timestamps. Larger skews are only corrected by it.
+	MaxCorrection time.Duration `mapstructure:"max_correction"`
+	// CorrectPast also corrects timestamps behind the clock. Since these are indistinguishable
+	// from delayed data, e.g. sent from a sender's buffer after an outage, only timestamps ahead
+	// of the clock are corrected by default.
+	CorrectPast bool `mapstructure:"correct_past"`
+}
+
+// ClockSourceConfig configures the clock the timestamps of the telemetry are compared to.
+type ClockSourceConfig struct {
+	// Type is system, the local clock, or ntp, the local clock corrected by its offset to an NTP server.
+	Type string `mapstructure:"type"`
+	// NTPServer is the host and port of the NTP server.
+	NTPServer string `mapstructure:"ntp_server"`
+	// Interval is the interval at which the offset to the NTP server is measured.
+	Interval time.Duration `mapstructure:"interval"`
+	// Timeout is the timeout of a query to the NTP server.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	switch cfg.ClockSource.Type {
+	case clockSourceSystem:
+	case clockSourceNTP:
+		if cfg.ClockSource.NTPServer == "" {
+			errs = errors.Join(errs, errors.New("clock_source::ntp_server must not be empty"))
+		}
+		if cfg.ClockSource.Interval <= 0 {
+			errs = errors.Join(errs, errors.New("clock_source::interval must be positive"))
+		}
+		if cfg.ClockSource.Timeout <= 0 {
+			errs = errors.Join(errs, errors.New("clock_source::timeout must be positive"))
+		}
+	default:
+		errs = errors.Join(errs, fmt.Errorf("clock_source::type must be %q or %q", clockSourceSystem, clockSourceNTP))
+	}
+	if cfg.Tolerance < 0 {
+		errs = errors.Join(errs, errors.New("tolerance must not be negative"))
+	}
+	if cfg.MaxCorrection <= 0 {
+		errs = errors.Join(errs, errors.New("max_correction must be positive"))
+	}
+	return errs
+}
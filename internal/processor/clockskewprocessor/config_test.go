@@ -0,0 +1,89 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				ClockSource: ClockSourceConfig{
+					Type:      "system",
+					NTPServer: "pool.ntp.org:123",
+					Interval:  10 * time.Minute,
+					Timeout:   5 * time.Second,
+				},
+				Tolerance:     time.Minute,
+				MaxCorrection: time.Hour,
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				ClockSource: ClockSourceConfig{
+					Type:      "ntp",
+					NTPServer: "time.example.com:123",
+					Interval:  time.Minute,
+					Timeout:   2 * time.Second,
+				},
+				Tolerance:     30 * time.Second,
+				MaxCorrection: 2 * time.Hour,
+				CorrectPast:   true,
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid_ntp"),
+			expectedErr: "clock_source::ntp_server must not be empty\n" +
+				"clock_source::interval must be positive\n" +
+				"clock_source::timeout must be positive\n" +
+				"tolerance must not be negative\n" +
+				"max_correction must be positive",
+		},
+		{
+			id:          component.MustNewIDWithName(typeStr, "invalid_type"),
+			expectedErr: `clock_source::type must be "system" or "ntp"`,
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
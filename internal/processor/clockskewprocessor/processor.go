@@ -0,0 +1,189 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// clockSkewProcessor shifts the timestamps of each resource by the skew of its newest timestamp
+// to the reference clock. Resources usually correspond to a single sender, and the newest data of
+// a batch is expected to be about as recent as the clock.
+type clockSkewProcessor struct {
+	clock  *clock
+	cancel context.CancelFunc
+	cfg    *Config
+}
+
+func newClockSkewProcessor(cfg *Config, logger *zap.Logger) *clockSkewProcessor {
+	return &clockSkewProcessor{clock: newClock(cfg.ClockSource, logger), cfg: cfg}
+}
+
+func (p *clockSkewProcessor) start(context.Context, component.Host) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.clock.run(ctx)
+	return nil
+}
+
+func (p *clockSkewProcessor) shutdown(context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
+
+// correction returns by how much the timestamps of a resource whose newest timestamp is newest
+// are shifted.
+func (p *clockSkewProcessor) correction(newest pcommon.Timestamp) time.Duration {
+	if newest == 0 {
+		return 0
+	}
+	skew := newest.AsTime().Sub(p.clock.Now())
+	switch {
+	case skew > p.cfg.Tolerance:
+		return min(skew, p.cfg.MaxCorrection)
+	case skew < -p.cfg.Tolerance && p.cfg.CorrectPast:
+		return max(skew, -p.cfg.MaxCorrection)
+	}
+	return 0
+}
+
+// correct calls walk to find the newest timestamp, then again to shift all timestamps by the
+// correction if any. Unset timestamps are kept.
+func (p *clockSkewProcessor) correct(walk func(func(pcommon.Timestamp) pcommon.Timestamp)) {
+	var newest pcommon.Timestamp
+	walk(func(ts pcommon.Timestamp) pcommon.Timestamp {
+		newest = max(newest, ts)
+		return ts
+	})
+	correction := p.correction(newest)
+	if correction == 0 {
+		return
+	}
+	walk(func(ts pcommon.Timestamp) pcommon.Timestamp {
+		if ts == 0 {
+			return ts
+		}
+		return pcommon.NewTimestampFromTime(ts.AsTime().Add(-correction))
+	})
+}
+
+func (p *clockSkewProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		p.correct(func(f func(pcommon.Timestamp) pcommon.Timestamp) { walkMetrics(rm, f) })
+	}
+	return md, nil
+}
+
+func (p *clockSkewProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		rs := td.ResourceSpans().At(i)
+		p.correct(func(f func(pcommon.Timestamp) pcommon.Timestamp) { walkSpans(rs, f) })
+	}
+	return td, nil
+}
+
+func (p *clockSkewProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		p.correct(func(f func(pcommon.Timestamp) pcommon.Timestamp) { walkLogs(rl, f) })
+	}
+	return ld, nil
+}
+
+// dataPoint is implemented by the data points of all metric types.
+type dataPoint interface {
+	Timestamp() pcommon.Timestamp
+	SetTimestamp(pcommon.Timestamp)
+	StartTimestamp() pcommon.Timestamp
+	SetStartTimestamp(pcommon.Timestamp)
+}
+
+type dataPointSlice[P dataPoint] interface {
+	Len() int
+	At(int) P
+}
+
+func walkDataPoints[P dataPoint](dps dataPointSlice[P], f func(pcommon.Timestamp) pcommon.Timestamp) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		dp.SetTimestamp(f(dp.Timestamp()))
+		dp.SetStartTimestamp(f(dp.StartTimestamp()))
+		if withExemplars, ok := any(dp).(interface{ Exemplars() pmetric.ExemplarSlice }); ok {
+			exemplars := withExemplars.Exemplars()
+			for j := 0; j < exemplars.Len(); j++ {
+				exemplars.At(j).SetTimestamp(f(exemplars.At(j).Timestamp()))
+			}
+		}
+	}
+}
+
+func walkMetrics(rm pmetric.ResourceMetrics, f func(pcommon.Timestamp) pcommon.Timestamp) {
+	for i := 0; i < rm.ScopeMetrics().Len(); i++ {
+		metrics := rm.ScopeMetrics().At(i).Metrics()
+		for j := 0; j < metrics.Len(); j++ {
+			m := metrics.At(j)
+			switch m.Type() {
+			case pmetric.MetricTypeGauge:
+				walkDataPoints[pmetric.NumberDataPoint](m.Gauge().DataPoints(), f)
+			case pmetric.MetricTypeSum:
+				walkDataPoints[pmetric.NumberDataPoint](m.Sum().DataPoints(), f)
+			case pmetric.MetricTypeHistogram:
+				walkDataPoints[pmetric.HistogramDataPoint](m.Histogram().DataPoints(), f)
+			case pmetric.MetricTypeExponentialHistogram:
+				walkDataPoints[pmetric.ExponentialHistogramDataPoint](m.ExponentialHistogram().DataPoints(), f)
+			case pmetric.MetricTypeSummary:
+				walkDataPoints[pmetric.SummaryDataPoint](m.Summary().DataPoints(), f)
+			}
+		}
+	}
+}
+
+func walkSpans(rs ptrace.ResourceSpans, f func(pcommon.Timestamp) pcommon.Timestamp) {
+	for i := 0; i < rs.ScopeSpans().Len(); i++ {
+		spans := rs.ScopeSpans().At(i).Spans()
+		for j := 0; j < spans.Len(); j++ {
+			span := spans.At(j)
+			span.SetStartTimestamp(f(span.StartTimestamp()))
+			span.SetEndTimestamp(f(span.EndTimestamp()))
+			for k := 0; k < span.Events().Len(); k++ {
+				event := span.Events().At(k)
+				event.SetTimestamp(f(event.Timestamp()))
+			}
+		}
+	}
+}
+
+// walkLogs only walks the timestamps of log records. Their observed timestamps are set by
+// collectors, not by the sender.
+func walkLogs(rl plog.ResourceLogs, f func(pcommon.Timestamp) pcommon.Timestamp) {
+	for i := 0; i < rl.ScopeLogs().Len(); i++ {
+		logs := rl.ScopeLogs().At(i).LogRecords()
+		for j := 0; j < logs.Len(); j++ {
+			lr := logs.At(j)
+			lr.SetTimestamp(f(lr.Timestamp()))
+		}
+	}
+}
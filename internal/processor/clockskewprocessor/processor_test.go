@@ -0,0 +1,116 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clockskewprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestProcessor(cfg *Config) *clockSkewProcessor {
+	p := newClockSkewProcessor(cfg, zap.NewNop())
+	p.clock.now = func() time.Time { return now }
+	return p
+}
+
+func ts(d time.Duration) pcommon.Timestamp {
+	return pcommon.NewTimestampFromTime(now.Add(d))
+}
+
+func TestCorrection(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	p := newTestProcessor(cfg)
+	assert.Equal(t, time.Duration(0), p.correction(0))
+	assert.Equal(t, time.Duration(0), p.correction(ts(30*time.Second)))
+	assert.Equal(t, 10*time.Minute, p.correction(ts(10*time.Minute)))
+	// corrections are bounded by max_correction
+	assert.Equal(t, time.Hour, p.correction(ts(3*time.Hour)))
+	// timestamps behind the clock are only corrected with correct_past
+	assert.Equal(t, time.Duration(0), p.correction(ts(-10*time.Minute)))
+
+	cfg.CorrectPast = true
+	assert.Equal(t, -10*time.Minute, p.correction(ts(-10*time.Minute)))
+	assert.Equal(t, -time.Hour, p.correction(ts(-3*time.Hour)))
+}
+
+func TestProcessMetrics(t *testing.T) {
+	p := newTestProcessor(createDefaultConfig().(*Config))
+	md := pmetric.NewMetrics()
+	// a sender 10 minutes ahead
+	ahead := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	sum := ahead.AppendEmpty().SetEmptySum().DataPoints().AppendEmpty()
+	sum.SetStartTimestamp(ts(9 * time.Minute))
+	sum.SetTimestamp(ts(10 * time.Minute))
+	hist := ahead.AppendEmpty().SetEmptyHistogram().DataPoints().AppendEmpty()
+	hist.SetTimestamp(ts(5 * time.Minute))
+	hist.Exemplars().AppendEmpty().SetTimestamp(ts(4 * time.Minute))
+	// a sender in sync
+	inSync := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := inSync.AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+	gauge.SetTimestamp(ts(-time.Second))
+
+	md, err := p.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, ts(-time.Minute), sum.StartTimestamp())
+	assert.Equal(t, ts(0), sum.Timestamp())
+	assert.Equal(t, ts(-5*time.Minute), hist.Timestamp())
+	assert.Equal(t, pcommon.Timestamp(0), hist.StartTimestamp())
+	assert.Equal(t, ts(-6*time.Minute), hist.Exemplars().At(0).Timestamp())
+	assert.Equal(t, ts(-time.Second), gauge.Timestamp())
+	assert.Equal(t, 2, md.ResourceMetrics().Len())
+}
+
+func TestProcessTraces(t *testing.T) {
+	p := newTestProcessor(createDefaultConfig().(*Config))
+	td := ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetStartTimestamp(ts(2 * time.Hour))
+	span.SetEndTimestamp(ts(2*time.Hour + time.Second))
+	span.Events().AppendEmpty().SetTimestamp(ts(2*time.Hour + 500*time.Millisecond))
+
+	_, err := p.processTraces(context.Background(), td)
+	require.NoError(t, err)
+	// the 2h skew is only corrected by max_correction
+	assert.Equal(t, ts(time.Hour), span.StartTimestamp())
+	assert.Equal(t, ts(time.Hour+time.Second), span.EndTimestamp())
+	assert.Equal(t, ts(time.Hour+500*time.Millisecond), span.Events().At(0).Timestamp())
+}
+
+func TestProcessLogs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CorrectPast = true
+	p := newTestProcessor(cfg)
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetTimestamp(ts(-20 * time.Minute))
+	lr.SetObservedTimestamp(ts(0))
+
+	_, err := p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, ts(0), lr.Timestamp())
+	// observed timestamps are set by collectors and kept
+	assert.Equal(t, ts(0), lr.ObservedTimestamp())
+}
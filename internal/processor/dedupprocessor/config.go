@@ -0,0 +1,52 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedupprocessor
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines how duplicate records are identified and for how long they're remembered.
+type Config struct {
+	// IdentityAttributes are the resource attributes identifying the source of the records, like
+	// the host the redundant agents collect from. The other resource attributes, like the ones
+	// identifying each agent, are ignored.
+	IdentityAttributes []string `mapstructure:"identity_attributes"`
+	// Window is how long a record is remembered after it was first received. The records with the
+	// same identity and content received within the window are dropped.
+	Window time.Duration `mapstructure:"window"`
+	// MaxEntries is the maximum number of remembered records. The oldest ones are forgotten
+	// when it's reached.
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.IdentityAttributes) == 0 {
+		errs = errors.Join(errs, errors.New("identity_attributes must not be empty"))
+	}
+	if cfg.Window <= 0 {
+		errs = errors.Join(errs, errors.New("window must be positive"))
+	}
+	if cfg.MaxEntries <= 0 {
+		errs = errors.Join(errs, errors.New("max_entries must be positive"))
+	}
+	return errs
+}
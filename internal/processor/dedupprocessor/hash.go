@@ -0,0 +1,118 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedupprocessor
+
+import (
+	"encoding/binary"
+	"hash"
+	"math"
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// hasher writes the fields of records to a hash, prefixing variable length values with their
+// length so that different records can't write the same bytes.
+type hasher struct {
+	hash.Hash64
+	buf [8]byte
+}
+
+func (h *hasher) writeUint64(v uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], v)
+	_, _ = h.Write(h.buf[:])
+}
+
+func (h *hasher) writeFloat64(v float64) {
+	h.writeUint64(math.Float64bits(v))
+}
+
+func (h *hasher) writeBytes(b []byte) {
+	h.writeUint64(uint64(len(b)))
+	_, _ = h.Write(b)
+}
+
+func (h *hasher) writeString(s string) {
+	h.writeBytes([]byte(s))
+}
+
+func (h *hasher) writeUint64s(s pcommon.UInt64Slice) {
+	h.writeUint64(uint64(s.Len()))
+	for i := 0; i < s.Len(); i++ {
+		h.writeUint64(s.At(i))
+	}
+}
+
+func (h *hasher) writeFloat64s(s pcommon.Float64Slice) {
+	h.writeUint64(uint64(s.Len()))
+	for i := 0; i < s.Len(); i++ {
+		h.writeFloat64(s.At(i))
+	}
+}
+
+func (h *hasher) writeValue(v pcommon.Value) {
+	h.writeUint64(uint64(v.Type()))
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		h.writeString(v.Str())
+	case pcommon.ValueTypeInt:
+		h.writeUint64(uint64(v.Int()))
+	case pcommon.ValueTypeDouble:
+		h.writeFloat64(v.Double())
+	case pcommon.ValueTypeBool:
+		if v.Bool() {
+			h.writeUint64(1)
+		} else {
+			h.writeUint64(0)
+		}
+	case pcommon.ValueTypeBytes:
+		h.writeBytes(v.Bytes().AsRaw())
+	case pcommon.ValueTypeMap:
+		h.writeMap(v.Map())
+	case pcommon.ValueTypeSlice:
+		h.writeUint64(uint64(v.Slice().Len()))
+		for i := 0; i < v.Slice().Len(); i++ {
+			h.writeValue(v.Slice().At(i))
+		}
+	}
+}
+
+// writeMap writes the entries of the map sorted by key, so that maps with the same entries in
+// different orders have the same hash.
+func (h *hasher) writeMap(m pcommon.Map) {
+	keys := make([]string, 0, m.Len())
+	m.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+	h.writeUint64(uint64(len(keys)))
+	for _, k := range keys {
+		v, _ := m.Get(k)
+		h.writeString(k)
+		h.writeValue(v)
+	}
+}
+
+// writeIdentity writes the values of the identity attributes, or an empty value for the missing ones.
+func (h *hasher) writeIdentity(attributes []string, resource pcommon.Map) {
+	for _, attribute := range attributes {
+		if v, ok := resource.Get(attribute); ok {
+			h.writeValue(v)
+		} else {
+			h.writeValue(pcommon.NewValueEmpty())
+		}
+	}
+}
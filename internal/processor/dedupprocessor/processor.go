@@ -0,0 +1,179 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedupprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+// dedupProcessor drops the log records and metric data points received more than once within
+// the window, like the ones of redundant agents collecting from the same source. Records are
+// duplicates when their identity attributes, scope name and content are the same.
+type dedupProcessor struct {
+	config *Config
+	now    func() time.Time
+	window *window
+	hasher *hasher
+	mu     sync.Mutex
+}
+
+func newDedupProcessor(cfg *Config) *dedupProcessor {
+	return &dedupProcessor{
+		config: cfg,
+		now:    time.Now,
+		window: newWindow(cfg.Window, cfg.MaxEntries),
+		hasher: &hasher{Hash64: fnv.New64a()},
+	}
+}
+
+// duplicate returns whether the record whose content is written by write was already received
+// in the window. Must be called with the lock held.
+func (dp *dedupProcessor) duplicate(now time.Time, resource pcommon.Resource, scope pcommon.InstrumentationScope, write func(h *hasher)) bool {
+	h := dp.hasher
+	h.Reset()
+	h.writeIdentity(dp.config.IdentityAttributes, resource.Attributes())
+	h.writeString(scope.Name())
+	write(h)
+	return dp.window.seen(h.Sum64(), now)
+}
+
+func (dp *dedupProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	now := dp.now()
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				return dp.duplicate(now, rl.Resource(), sl.Scope(), func(h *hasher) {
+					h.writeUint64(uint64(lr.Timestamp()))
+					h.writeUint64(uint64(lr.SeverityNumber()))
+					h.writeString(lr.SeverityText())
+					h.writeValue(lr.Body())
+					h.writeMap(lr.Attributes())
+					traceID, spanID := lr.TraceID(), lr.SpanID()
+					h.writeBytes(traceID[:])
+					h.writeBytes(spanID[:])
+				})
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+func (dp *dedupProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	now := dp.now()
+	md.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			sm.Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				duplicate := func(attributes pcommon.Map, timestamp pcommon.Timestamp, write func(h *hasher)) bool {
+					return dp.duplicate(now, rm.Resource(), sm.Scope(), func(h *hasher) {
+						h.writeString(m.Name())
+						h.writeUint64(uint64(m.Type()))
+						h.writeMap(attributes)
+						h.writeUint64(uint64(timestamp))
+						write(h)
+					})
+				}
+				return removeDuplicateDataPoints(m, duplicate)
+			})
+			return sm.Metrics().Len() == 0
+		})
+		return rm.ScopeMetrics().Len() == 0
+	})
+	if md.ResourceMetrics().Len() == 0 {
+		return md, processorhelper.ErrSkipProcessingData
+	}
+	return md, nil
+}
+
+// removeDuplicateDataPoints removes the duplicate data points of the metric, and returns whether
+// none is left. The start timestamps of data points aren't part of their content, since each
+// agent reports its own.
+func removeDuplicateDataPoints(m pmetric.Metric, duplicate func(pcommon.Map, pcommon.Timestamp, func(h *hasher)) bool) bool {
+	writeNumber := func(dp pmetric.NumberDataPoint) func(h *hasher) {
+		return func(h *hasher) {
+			if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+				h.writeUint64(uint64(dp.IntValue()))
+			} else {
+				h.writeFloat64(dp.DoubleValue())
+			}
+		}
+	}
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		m.Gauge().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+			return duplicate(dp.Attributes(), dp.Timestamp(), writeNumber(dp))
+		})
+		return m.Gauge().DataPoints().Len() == 0
+	case pmetric.MetricTypeSum:
+		m.Sum().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+			return duplicate(dp.Attributes(), dp.Timestamp(), writeNumber(dp))
+		})
+		return m.Sum().DataPoints().Len() == 0
+	case pmetric.MetricTypeHistogram:
+		m.Histogram().DataPoints().RemoveIf(func(dp pmetric.HistogramDataPoint) bool {
+			return duplicate(dp.Attributes(), dp.Timestamp(), func(h *hasher) {
+				h.writeUint64(dp.Count())
+				h.writeFloat64(dp.Sum())
+				h.writeFloat64s(dp.ExplicitBounds())
+				h.writeUint64s(dp.BucketCounts())
+			})
+		})
+		return m.Histogram().DataPoints().Len() == 0
+	case pmetric.MetricTypeExponentialHistogram:
+		m.ExponentialHistogram().DataPoints().RemoveIf(func(dp pmetric.ExponentialHistogramDataPoint) bool {
+			return duplicate(dp.Attributes(), dp.Timestamp(), func(h *hasher) {
+				h.writeUint64(dp.Count())
+				h.writeFloat64(dp.Sum())
+				h.writeUint64(uint64(dp.Scale()))
+				h.writeUint64(dp.ZeroCount())
+				h.writeUint64(uint64(dp.Positive().Offset()))
+				h.writeUint64s(dp.Positive().BucketCounts())
+				h.writeUint64(uint64(dp.Negative().Offset()))
+				h.writeUint64s(dp.Negative().BucketCounts())
+			})
+		})
+		return m.ExponentialHistogram().DataPoints().Len() == 0
+	case pmetric.MetricTypeSummary:
+		m.Summary().DataPoints().RemoveIf(func(dp pmetric.SummaryDataPoint) bool {
+			return duplicate(dp.Attributes(), dp.Timestamp(), func(h *hasher) {
+				h.writeUint64(dp.Count())
+				h.writeFloat64(dp.Sum())
+				h.writeUint64(uint64(dp.QuantileValues().Len()))
+				for i := 0; i < dp.QuantileValues().Len(); i++ {
+					h.writeFloat64(dp.QuantileValues().At(i).Quantile())
+					h.writeFloat64(dp.QuantileValues().At(i).Value())
+				}
+			})
+		})
+		return m.Summary().DataPoints().Len() == 0
+	}
+	return false
+}
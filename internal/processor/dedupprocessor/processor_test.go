@@ -0,0 +1,200 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedupprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestProcessor(cfg *Config) (*dedupProcessor, *time.Time) {
+	dp := newDedupProcessor(cfg)
+	var now *time.Time
+	dp.now, now = testclock.New(epoch)
+	return dp, now
+}
+
+// newLogs returns the logs an agent sends, with its own agent.id resource attribute.
+func newLogs(agent string, bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "db-1")
+	rl.Resource().Attributes().PutStr("agent.id", agent)
+	sl := rl.ScopeLogs().AppendEmpty()
+	for i, body := range bodies {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.SetTimestamp(pcommon.Timestamp(i + 1))
+		lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+		lr.Body().SetStr(body)
+		lr.Attributes().PutStr("log.file.name", "db.log")
+		lr.Attributes().PutInt("line", int64(i))
+	}
+	return ld
+}
+
+func bodies(ld plog.Logs) []string {
+	var bodies []string
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sls := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				bodies = append(bodies, lrs.At(k).Body().Str())
+			}
+		}
+	}
+	return bodies
+}
+
+func TestLogs(t *testing.T) {
+	dp, now := newTestProcessor(createDefaultConfig().(*Config))
+
+	ld, err := dp.processLogs(context.Background(), newLogs("a", "started", "ready"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"started", "ready"}, bodies(ld))
+
+	// the records of the other agent are duplicates, whatever their observed timestamp
+	_, err = dp.processLogs(context.Background(), newLogs("b", "started", "ready"))
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+
+	ld, err = dp.processLogs(context.Background(), newLogs("b", "started", "ready", "stopped"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stopped"}, bodies(ld))
+
+	// records of other hosts aren't duplicates
+	ld = newLogs("b", "started")
+	ld.ResourceLogs().At(0).Resource().Attributes().PutStr("host.name", "db-2")
+	ld, err = dp.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"started"}, bodies(ld))
+
+	// records with other attributes aren't duplicates
+	ld = newLogs("b", "started")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().PutStr("log.file.name", "other.log")
+	ld, err = dp.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"started"}, bodies(ld))
+
+	// records are forgotten after the window
+	*now = now.Add(30 * time.Second)
+	ld, err = dp.processLogs(context.Background(), newLogs("b", "started", "ready"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"started", "ready"}, bodies(ld))
+}
+
+func TestAttributesOrder(t *testing.T) {
+	dp, _ := newTestProcessor(createDefaultConfig().(*Config))
+
+	first := newLogs("a")
+	lr := first.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().AppendEmpty()
+	lr.Body().SetEmptyMap().PutStr("msg", "started")
+	lr.Body().Map().PutInt("pid", 42)
+	_, err := dp.processLogs(context.Background(), first)
+	require.NoError(t, err)
+
+	second := newLogs("b")
+	lr = second.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().AppendEmpty()
+	lr.Body().SetEmptyMap().PutInt("pid", 42)
+	lr.Body().Map().PutStr("msg", "started")
+	_, err = dp.processLogs(context.Background(), second)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func newMetrics(agent string, value float64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("host.name", "db-1")
+	rm.Resource().Attributes().PutStr("agent.id", agent)
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := ms.AppendEmpty()
+	gauge.SetName("system.cpu.utilization")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(10)
+	dp.SetDoubleValue(value)
+	dp.Attributes().PutStr("cpu", "0")
+
+	histogram := ms.AppendEmpty()
+	histogram.SetName("db.query.duration")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	hdp.SetTimestamp(10)
+	hdp.SetCount(3)
+	hdp.SetSum(12)
+	hdp.ExplicitBounds().FromRaw([]float64{1, 10})
+	hdp.BucketCounts().FromRaw([]uint64{1, 1, 1})
+	return md
+}
+
+func names(md pmetric.Metrics) []string {
+	var names []string
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		sms := md.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				names = append(names, ms.At(k).Name())
+			}
+		}
+	}
+	return names
+}
+
+func TestMetrics(t *testing.T) {
+	dp, _ := newTestProcessor(createDefaultConfig().(*Config))
+
+	md, err := dp.processMetrics(context.Background(), newMetrics("a", 0.5))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system.cpu.utilization", "db.query.duration"}, names(md))
+
+	// the histogram is a duplicate, whatever its start timestamp, but not the gauge with another value
+	md, err = dp.processMetrics(context.Background(), newMetrics("b", 0.6))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system.cpu.utilization"}, names(md))
+
+	_, err = dp.processMetrics(context.Background(), newMetrics("b", 0.5))
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func TestWindow(t *testing.T) {
+	w := newWindow(time.Minute, 2)
+
+	assert.False(t, w.seen(1, epoch))
+	assert.True(t, w.seen(1, epoch.Add(59*time.Second)))
+	assert.False(t, w.seen(2, epoch.Add(30*time.Second)))
+
+	// the first key expires, and is remembered again
+	assert.False(t, w.seen(1, epoch.Add(time.Minute)))
+	assert.True(t, w.seen(2, epoch.Add(time.Minute)))
+	assert.Len(t, w.expiries, 2)
+
+	// the oldest key is forgotten when the window is full
+	assert.False(t, w.seen(3, epoch.Add(time.Minute)))
+	assert.Len(t, w.expiries, 2)
+	assert.False(t, w.seen(2, epoch.Add(time.Minute)))
+	assert.True(t, w.seen(3, epoch.Add(time.Minute)))
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedupprocessor
+
+import "time"
+
+type windowEntry struct {
+	expiry time.Time
+	key    uint64
+}
+
+// window remembers the keys of the records received in a sliding window.
+type window struct {
+	expiries   map[uint64]time.Time
+	entries    []windowEntry
+	length     time.Duration
+	maxEntries int
+}
+
+func newWindow(length time.Duration, maxEntries int) *window {
+	return &window{expiries: map[uint64]time.Time{}, length: length, maxEntries: maxEntries}
+}
+
+// seen returns whether the key was received in the window, and remembers it otherwise.
+func (w *window) seen(key uint64, now time.Time) bool {
+	w.expire(now)
+	if expiry, ok := w.expiries[key]; ok && now.Before(expiry) {
+		return true
+	}
+	for len(w.expiries) >= w.maxEntries {
+		w.evict()
+	}
+	expiry := now.Add(w.length)
+	w.expiries[key] = expiry
+	w.entries = append(w.entries, windowEntry{expiry: expiry, key: key})
+	return false
+}
+
+// expire forgets the keys received before the window.
+func (w *window) expire(now time.Time) {
+	for len(w.entries) > 0 && !now.Before(w.entries[0].expiry) {
+		w.evict()
+	}
+}
+
+// evict forgets the oldest key.
+func (w *window) evict() {
+	e := w.entries[0]
+	w.entries = w.entries[1:]
+	// the key was remembered again if its expiry changed
+	if w.expiries[e.key].Equal(e.expiry) {
+		delete(w.expiries, e.key)
+	}
+}
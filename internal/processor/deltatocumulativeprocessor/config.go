@@ -0,0 +1,54 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deltatocumulativeprocessor
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines where the series are persisted and how many are tracked.
+type Config struct {
+	// StorageID is the storage extension the series are persisted in. Without it, the series
+	// are only kept in memory and reset when the collector restarts.
+	StorageID *component.ID `mapstructure:"storage"`
+	// MaxStale is the time after which a series that wasn't updated is dropped, restarting
+	// from its next data point.
+	MaxStale time.Duration `mapstructure:"max_stale"`
+	// MaxStreams is the maximum number of tracked series. The data points of new series are
+	// dropped when exceeded.
+	MaxStreams int `mapstructure:"max_streams"`
+	// FlushInterval is how often changed series are written to storage. Changes made since the
+	// last flush are lost if the collector stops without shutting down.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.MaxStale <= 0 {
+		errs = errors.Join(errs, errors.New("max_stale must be positive"))
+	}
+	if cfg.MaxStreams <= 0 {
+		errs = errors.Join(errs, errors.New("max_streams must be positive"))
+	}
+	if cfg.FlushInterval <= 0 {
+		errs = errors.Join(errs, errors.New("flush_interval must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,263 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deltatocumulativeprocessor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// series is the cumulative state of a delta series, as persisted.
+type series struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// Start is the start timestamp of the cumulative series and Last the timestamp of its
+	// last accumulated data point.
+	Start pcommon.Timestamp `json:"start"`
+	Last  pcommon.Timestamp `json:"last"`
+	// Seen is when the series was last updated, in unix nanoseconds of the collector's clock.
+	Seen    int64     `json:"seen"`
+	Int     int64     `json:"int,omitempty"`
+	Double  float64   `json:"double,omitempty"`
+	Count   uint64    `json:"count,omitempty"`
+	Sum     float64   `json:"sum,omitempty"`
+	Bounds  []float64 `json:"bounds,omitempty"`
+	Buckets []uint64  `json:"buckets,omitempty"`
+}
+
+// deltaToCumulativeProcessor converts delta sums and histograms to cumulative ones, by
+// accumulating their data points per series. The series are persisted in a storage extension
+// if configured, so cumulative values don't reset when the collector restarts.
+type deltaToCumulativeProcessor struct {
+	client storage.Client
+	logger *zap.Logger
+	config *Config
+	cancel context.CancelFunc
+	now    func() time.Time
+	series map[string]*series
+	// dirty are the series changed since the last flush, and removed the series whose
+	// persisted state is still to be deleted.
+	dirty        map[string]struct{}
+	removed      map[string]struct{}
+	id           component.ID
+	wg           sync.WaitGroup
+	mu           sync.Mutex
+	flushMu      sync.Mutex
+	indexChanged bool
+}
+
+func newDeltaToCumulativeProcessor(config *Config, id component.ID, logger *zap.Logger) *deltaToCumulativeProcessor {
+	return &deltaToCumulativeProcessor{
+		config:  config,
+		id:      id,
+		logger:  logger,
+		now:     time.Now,
+		series:  map[string]*series{},
+		dirty:   map[string]struct{}{},
+		removed: map[string]struct{}{},
+	}
+}
+
+func (p *deltaToCumulativeProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now().UnixNano()
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			sm.Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				switch metric.Type() {
+				case pmetric.MetricTypeSum:
+					sum := metric.Sum()
+					if sum.AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						return false
+					}
+					prefix := seriesPrefix(rm.Resource(), sm.Scope(), metric)
+					sum.DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+						return !p.accumulateNumber(seriesKey(prefix, dp.Attributes()), dp, now)
+					})
+					sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+					return sum.DataPoints().Len() == 0
+				case pmetric.MetricTypeHistogram:
+					histogram := metric.Histogram()
+					if histogram.AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+						return false
+					}
+					prefix := seriesPrefix(rm.Resource(), sm.Scope(), metric)
+					histogram.DataPoints().RemoveIf(func(dp pmetric.HistogramDataPoint) bool {
+						return !p.accumulateHistogram(seriesKey(prefix, dp.Attributes()), dp, now)
+					})
+					histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+					return histogram.DataPoints().Len() == 0
+				}
+				return false
+			})
+		}
+	}
+	return md, nil
+}
+
+// lookup returns the series of the key, and starts it at the data point if it's unknown or
+// reset is true. It returns nil if the series is unknown and max_streams is reached, or if the
+// data point isn't after the last accumulated one, since it was already accumulated or is out
+// of order. Must be called with the lock held.
+func (p *deltaToCumulativeProcessor) lookup(key string, start, timestamp pcommon.Timestamp, reset bool) *series {
+	s, ok := p.series[key]
+	if ok && !reset {
+		if timestamp <= s.Last {
+			return nil
+		}
+		return s
+	}
+	if !ok && len(p.series) >= p.config.MaxStreams {
+		p.logger.Debug("Dropping data point of a new series, max_streams reached", zap.Int("max_streams", p.config.MaxStreams))
+		return nil
+	}
+	if start == 0 {
+		start = timestamp
+	}
+	s = &series{Start: start}
+	p.series[key] = s
+	delete(p.removed, key)
+	p.indexChanged = p.indexChanged || !ok
+	return s
+}
+
+// accumulateNumber adds the data point to its series and sets it to the cumulative value. It
+// returns false if the data point must be dropped. Must be called with the lock held.
+func (p *deltaToCumulativeProcessor) accumulateNumber(key string, dp pmetric.NumberDataPoint, now int64) bool {
+	s := p.lookup(key, dp.StartTimestamp(), dp.Timestamp(), false)
+	if s == nil {
+		return false
+	}
+	switch dp.ValueType() {
+	case pmetric.NumberDataPointValueTypeInt:
+		s.Int += dp.IntValue()
+		dp.SetIntValue(s.Int)
+	case pmetric.NumberDataPointValueTypeDouble:
+		s.Double += dp.DoubleValue()
+		dp.SetDoubleValue(s.Double)
+	}
+	p.updated(key, s, dp.Timestamp(), now)
+	dp.SetStartTimestamp(s.Start)
+	return true
+}
+
+// accumulateHistogram adds the data point to its series and sets it to the cumulative
+// histogram. The series is restarted if the bucket bounds change. It returns false if the data
+// point must be dropped. Must be called with the lock held.
+func (p *deltaToCumulativeProcessor) accumulateHistogram(key string, dp pmetric.HistogramDataPoint, now int64) bool {
+	bounds := dp.ExplicitBounds().AsRaw()
+	if dp.BucketCounts().Len() != 0 && dp.BucketCounts().Len() != len(bounds)+1 {
+		return false
+	}
+	reset := false
+	if s, ok := p.series[key]; ok && !slices.Equal(s.Bounds, bounds) {
+		reset = dp.Timestamp() > s.Last
+	}
+	s := p.lookup(key, dp.StartTimestamp(), dp.Timestamp(), reset)
+	if s == nil {
+		return false
+	}
+	if s.Buckets == nil {
+		s.Bounds = bounds
+		s.Buckets = make([]uint64, len(bounds)+1)
+	}
+	s.Count += dp.Count()
+	s.Sum += dp.Sum()
+	for i := 0; i < dp.BucketCounts().Len(); i++ {
+		s.Buckets[i] += dp.BucketCounts().At(i)
+	}
+	if dp.HasMin() && (s.Min == nil || dp.Min() < *s.Min) {
+		s.Min = ptr(dp.Min())
+	}
+	if dp.HasMax() && (s.Max == nil || dp.Max() > *s.Max) {
+		s.Max = ptr(dp.Max())
+	}
+	p.updated(key, s, dp.Timestamp(), now)
+
+	dp.SetStartTimestamp(s.Start)
+	dp.SetCount(s.Count)
+	if dp.HasSum() {
+		dp.SetSum(s.Sum)
+	}
+	dp.BucketCounts().FromRaw(s.Buckets)
+	if s.Min != nil {
+		dp.SetMin(*s.Min)
+	}
+	if s.Max != nil {
+		dp.SetMax(*s.Max)
+	}
+	return true
+}
+
+// updated must be called with the lock held.
+func (p *deltaToCumulativeProcessor) updated(key string, s *series, timestamp pcommon.Timestamp, now int64) {
+	s.Last = timestamp
+	s.Seen = now
+	p.dirty[key] = struct{}{}
+}
+
+// removeStale drops the series that weren't updated for max_stale. Must be called with the
+// lock held.
+func (p *deltaToCumulativeProcessor) removeStale() {
+	staleBefore := p.now().Add(-p.config.MaxStale).UnixNano()
+	for key, s := range p.series {
+		if s.Seen < staleBefore {
+			delete(p.series, key)
+			delete(p.dirty, key)
+			p.removed[key] = struct{}{}
+			p.indexChanged = true
+		}
+	}
+}
+
+// seriesPrefix returns the identity of the metric's series, without the data point attributes.
+func seriesPrefix(resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric) []byte {
+	// map keys are encoded in order
+	prefix, _ := json.Marshal([]any{
+		resource.Attributes().AsRaw(),
+		scope.Name(),
+		scope.Version(),
+		metric.Name(),
+		metric.Unit(),
+		metric.Type().String(),
+	})
+	return prefix
+}
+
+func seriesKey(prefix []byte, attrs pcommon.Map) string {
+	encoded, _ := json.Marshal(attrs.AsRaw())
+	h := sha256.New()
+	h.Write(prefix)
+	h.Write(encoded)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
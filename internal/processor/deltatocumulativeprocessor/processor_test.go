@@ -0,0 +1,186 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deltatocumulativeprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func ts(seconds int) pcommon.Timestamp {
+	return pcommon.NewTimestampFromTime(epoch.Add(time.Duration(seconds) * time.Second))
+}
+
+func newTestProcessor(cfg *Config) (*deltaToCumulativeProcessor, *time.Time) {
+	p := newDeltaToCumulativeProcessor(cfg, component.MustNewID(typeStr), zap.NewNop())
+	var now *time.Time
+	p.now, now = testclock.New(epoch)
+	return p, now
+}
+
+// newSum returns a delta sum of a data point per value of host, from start to end.
+func newSum(temporality pmetric.AggregationTemporality, start, end int, values map[string]int64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("requests")
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(temporality)
+	for host, value := range values {
+		dp := sum.DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("host", host)
+		dp.SetStartTimestamp(ts(start))
+		dp.SetTimestamp(ts(end))
+		dp.SetIntValue(value)
+	}
+	return md
+}
+
+type point struct {
+	start pcommon.Timestamp
+	value int64
+}
+
+func sumPoints(t *testing.T, md pmetric.Metrics) map[string]point {
+	points := map[string]point{}
+	if md.ResourceMetrics().Len() == 0 || md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().Len() == 0 {
+		return points
+	}
+	sum := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum()
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, sum.AggregationTemporality())
+	for i := 0; i < sum.DataPoints().Len(); i++ {
+		dp := sum.DataPoints().At(i)
+		host, _ := dp.Attributes().Get("host")
+		points[host.Str()] = point{start: dp.StartTimestamp(), value: dp.IntValue()}
+	}
+	return points
+}
+
+func TestSums(t *testing.T) {
+	p, _ := newTestProcessor(createDefaultConfig().(*Config))
+	process := func(md pmetric.Metrics) map[string]point {
+		md, err := p.processMetrics(context.Background(), md)
+		require.NoError(t, err)
+		return sumPoints(t, md)
+	}
+
+	assert.Equal(t, map[string]point{"a": {ts(0), 1}, "b": {ts(0), 5}},
+		process(newSum(pmetric.AggregationTemporalityDelta, 0, 10, map[string]int64{"a": 1, "b": 5})))
+	assert.Equal(t, map[string]point{"a": {ts(0), 3}, "c": {ts(10), 1}},
+		process(newSum(pmetric.AggregationTemporalityDelta, 10, 20, map[string]int64{"a": 2, "c": 1})))
+	// data points already accumulated, or out of order, are dropped
+	assert.Equal(t, map[string]point{"b": {ts(0), 12}},
+		process(newSum(pmetric.AggregationTemporalityDelta, 10, 20, map[string]int64{"a": 2, "b": 7})))
+	assert.Empty(t, process(newSum(pmetric.AggregationTemporalityDelta, 0, 10, map[string]int64{"a": 2})))
+
+	// cumulative sums are kept as is
+	md := newSum(pmetric.AggregationTemporalityCumulative, 0, 30, map[string]int64{"a": 100})
+	expected := pmetric.NewMetrics()
+	md.CopyTo(expected)
+	md, err := p.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, expected, md)
+}
+
+func TestMaxStreams(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxStreams = 1
+	p, _ := newTestProcessor(cfg)
+
+	md, err := p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 0, 10, map[string]int64{"a": 1}))
+	require.NoError(t, err)
+	assert.Len(t, sumPoints(t, md), 1)
+	md, err = p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 10, 20, map[string]int64{"a": 1, "b": 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]point{"a": {ts(0), 2}}, sumPoints(t, md))
+}
+
+func TestStaleSeries(t *testing.T) {
+	p, now := newTestProcessor(createDefaultConfig().(*Config))
+
+	_, err := p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 0, 10, map[string]int64{"a": 1, "b": 1}))
+	require.NoError(t, err)
+	*now = now.Add(30 * time.Minute)
+	_, err = p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 10, 20, map[string]int64{"a": 1}))
+	require.NoError(t, err)
+	*now = now.Add(45 * time.Minute)
+	p.removeStale()
+
+	md, err := p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 20, 30, map[string]int64{"a": 1, "b": 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]point{"a": {ts(0), 3}, "b": {ts(20), 1}}, sumPoints(t, md))
+}
+
+func newHistogram(start, end int, bounds []float64, counts []uint64, sum, minimum, maximum float64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("latency")
+	histogram := m.SetEmptyHistogram()
+	histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	dp := histogram.DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(ts(start))
+	dp.SetTimestamp(ts(end))
+	dp.ExplicitBounds().FromRaw(bounds)
+	dp.BucketCounts().FromRaw(counts)
+	var count uint64
+	for _, c := range counts {
+		count += c
+	}
+	dp.SetCount(count)
+	dp.SetSum(sum)
+	dp.SetMin(minimum)
+	dp.SetMax(maximum)
+	return md
+}
+
+func TestHistograms(t *testing.T) {
+	p, _ := newTestProcessor(createDefaultConfig().(*Config))
+	process := func(md pmetric.Metrics) pmetric.HistogramDataPoint {
+		md, err := p.processMetrics(context.Background(), md)
+		require.NoError(t, err)
+		histogram := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram()
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, histogram.AggregationTemporality())
+		return histogram.DataPoints().At(0)
+	}
+
+	process(newHistogram(0, 10, []float64{10, 100}, []uint64{1, 2, 0}, 120, 5, 60))
+	dp := process(newHistogram(10, 20, []float64{10, 100}, []uint64{0, 1, 1}, 250, 50, 200))
+	assert.Equal(t, ts(0), dp.StartTimestamp())
+	assert.Equal(t, []uint64{1, 3, 1}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(5), dp.Count())
+	assert.Equal(t, 370.0, dp.Sum())
+	assert.Equal(t, 5.0, dp.Min())
+	assert.Equal(t, 200.0, dp.Max())
+
+	// the series restarts when its bounds change
+	dp = process(newHistogram(20, 30, []float64{10, 50, 100}, []uint64{0, 1, 0, 0}, 20, 20, 20))
+	assert.Equal(t, ts(20), dp.StartTimestamp())
+	assert.Equal(t, []uint64{0, 1, 0, 0}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(1), dp.Count())
+}
@@ -0,0 +1,208 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deltatocumulativeprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	indexKey        = "series"
+	seriesKeyPrefix = "series/"
+	// loadBatchSize is the number of series read from storage in a single batch on start.
+	loadBatchSize = 1000
+)
+
+func (p *deltaToCumulativeProcessor) start(ctx context.Context, host component.Host) error {
+	if p.config.StorageID != nil {
+		client, err := p.storageClient(ctx, host)
+		if err != nil {
+			return err
+		}
+		if err = p.load(ctx, client); err != nil {
+			return errors.Join(err, client.Close(ctx))
+		}
+		p.client = client
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.wg.Add(1)
+	go p.flushLoop(loopCtx)
+	return nil
+}
+
+func (p *deltaToCumulativeProcessor) shutdown(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	if p.client == nil {
+		return nil
+	}
+	err := p.flush(ctx)
+	return errors.Join(err, p.client.Close(ctx))
+}
+
+func (p *deltaToCumulativeProcessor) storageClient(ctx context.Context, host component.Host) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[*p.config.StorageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", p.config.StorageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", p.config.StorageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindProcessor, p.id, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed creating storage client: %w", err)
+	}
+	return client, nil
+}
+
+// load reads the persisted series. Series that can't be read are restarted from their next
+// data point, and stale ones are deleted on the first flush.
+func (p *deltaToCumulativeProcessor) load(ctx context.Context, client storage.Client) error {
+	data, err := client.Get(ctx, indexKey)
+	if err != nil {
+		return fmt.Errorf("failed reading series: %w", err)
+	}
+	var keys []string
+	if data != nil {
+		if err = json.Unmarshal(data, &keys); err != nil {
+			return fmt.Errorf("failed decoding series: %w", err)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(keys) > 0 {
+		batch := keys[:min(loadBatchSize, len(keys))]
+		keys = keys[len(batch):]
+		ops := make([]storage.Operation, len(batch))
+		for i, key := range batch {
+			ops[i] = storage.GetOperation(seriesKeyPrefix + key)
+		}
+		if err = client.Batch(ctx, ops...); err != nil {
+			return fmt.Errorf("failed reading series: %w", err)
+		}
+		for i, op := range ops {
+			var s series
+			if op.Value == nil {
+				p.indexChanged = true
+				continue
+			}
+			if err = json.Unmarshal(op.Value, &s); err != nil {
+				p.logger.Warn("Failed decoding series, restarting it", zap.Error(err))
+				p.removed[batch[i]] = struct{}{}
+				p.indexChanged = true
+				continue
+			}
+			p.series[batch[i]] = &s
+		}
+	}
+	p.removeStale()
+	return nil
+}
+
+func (p *deltaToCumulativeProcessor) flushLoop(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.removeStale()
+			if p.client == nil {
+				// without storage, there are no changes to persist
+				clear(p.dirty)
+				clear(p.removed)
+				p.mu.Unlock()
+				continue
+			}
+			p.mu.Unlock()
+			if err := p.flush(ctx); err != nil {
+				p.logger.Warn("Failed persisting series", zap.Error(err))
+			}
+		}
+	}
+}
+
+// flush persists the changes since the last flush in a single batch. The changed series are
+// encoded with the lock held, and written after releasing it.
+func (p *deltaToCumulativeProcessor) flush(ctx context.Context) error {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+
+	p.mu.Lock()
+	ops := make([]storage.Operation, 0, len(p.dirty)+len(p.removed)+1)
+	for key := range p.dirty {
+		data, err := json.Marshal(p.series[key])
+		if err != nil {
+			p.mu.Unlock()
+			return err
+		}
+		ops = append(ops, storage.SetOperation(seriesKeyPrefix+key, data))
+	}
+	for key := range p.removed {
+		ops = append(ops, storage.DeleteOperation(seriesKeyPrefix+key))
+	}
+	if p.indexChanged {
+		keys := make([]string, 0, len(p.series))
+		for key := range p.series {
+			keys = append(keys, key)
+		}
+		data, err := json.Marshal(keys)
+		if err != nil {
+			p.mu.Unlock()
+			return err
+		}
+		ops = append(ops, storage.SetOperation(indexKey, data))
+	}
+	dirty, removed, indexChanged := p.dirty, p.removed, p.indexChanged
+	p.dirty, p.removed, p.indexChanged = map[string]struct{}{}, map[string]struct{}{}, false
+	p.mu.Unlock()
+
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := p.client.Batch(ctx, ops...); err != nil {
+		// the changes are retried on the next flush, unless they're superseded by then
+		p.mu.Lock()
+		for key := range dirty {
+			if _, ok := p.series[key]; ok {
+				p.dirty[key] = struct{}{}
+			}
+		}
+		for key := range removed {
+			if _, ok := p.series[key]; !ok {
+				p.removed[key] = struct{}{}
+			}
+		}
+		p.indexChanged = p.indexChanged || indexChanged
+		p.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deltatocumulativeprocessor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+var storageID = component.MustNewID("file_storage")
+
+// memoryStorage is a storage extension whose data outlives the clients it provides.
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	data map[string][]byte
+	err  error
+	mu   sync.Mutex
+}
+
+func (s *memoryStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return &memoryClient{storage: s}, nil
+}
+
+type memoryClient struct {
+	storage *memoryStorage
+}
+
+func (c *memoryClient) Get(_ context.Context, key string) ([]byte, error) {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	return c.storage.data[key], c.storage.err
+}
+
+func (c *memoryClient) Set(_ context.Context, key string, value []byte) error {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	c.storage.data[key] = value
+	return c.storage.err
+}
+
+func (c *memoryClient) Delete(_ context.Context, key string) error {
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	delete(c.storage.data, key)
+	return c.storage.err
+}
+
+func (c *memoryClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	c.storage.mu.Lock()
+	err := c.storage.err
+	c.storage.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error {
+	return nil
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func (h storageHost) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+func startProcessor(t *testing.T, s *memoryStorage, now time.Time) *deltaToCumulativeProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StorageID = &storageID
+	// changes are only flushed on shutdown unless a test flushes them
+	cfg.FlushInterval = time.Hour
+	p, clock := newTestProcessor(cfg)
+	*clock = now
+	host := storageHost{Host: componenttest.NewNopHost(), extensions: map[component.ID]extension.Extension{storageID: s}}
+	require.NoError(t, p.start(context.Background(), host))
+	return p
+}
+
+func TestSeriesSurviveRestart(t *testing.T) {
+	s := &memoryStorage{data: map[string][]byte{}}
+	p := startProcessor(t, s, epoch)
+	_, err := p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 0, 10, map[string]int64{"a": 1, "b": 2}))
+	require.NoError(t, err)
+	require.NoError(t, p.shutdown(context.Background()))
+	assert.Len(t, s.data, 3)
+
+	p = startProcessor(t, s, epoch.Add(time.Minute))
+	md, err := p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 10, 20, map[string]int64{"a": 1, "b": 1}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]point{"a": {ts(0), 2}, "b": {ts(0), 3}}, sumPoints(t, md))
+	require.NoError(t, p.shutdown(context.Background()))
+
+	// series that became stale while the collector was stopped restart, and are deleted
+	p = startProcessor(t, s, epoch.Add(2*time.Hour))
+	require.NoError(t, p.shutdown(context.Background()))
+	assert.Equal(t, map[string][]byte{indexKey: []byte("[]")}, s.data)
+}
+
+func TestFailedFlushIsRetried(t *testing.T) {
+	s := &memoryStorage{data: map[string][]byte{}}
+	p := startProcessor(t, s, epoch)
+	_, err := p.processMetrics(context.Background(), newSum(pmetric.AggregationTemporalityDelta, 0, 10, map[string]int64{"a": 1}))
+	require.NoError(t, err)
+
+	s.mu.Lock()
+	s.err = errors.New("disk full")
+	s.mu.Unlock()
+	require.EqualError(t, p.flush(context.Background()), "disk full")
+	assert.Empty(t, s.data)
+
+	s.mu.Lock()
+	s.err = nil
+	s.mu.Unlock()
+	require.NoError(t, p.shutdown(context.Background()))
+	assert.Len(t, s.data, 2)
+}
+
+func TestStartWithoutStorage(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StorageID = &storageID
+	p, _ := newTestProcessor(cfg)
+	require.EqualError(t, p.start(context.Background(), componenttest.NewNopHost()), `storage extension "file_storage" not found`)
+}
@@ -0,0 +1,123 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hecmappingprocessor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines how the attributes of log records are mapped to Splunk HEC metadata and CIM fields.
+type Config struct {
+	// MappingFile is a YAML file with the mapping, e.g. maintained by the Splunk admins, replacing Mapping.
+	MappingFile string `mapstructure:"mapping_file"`
+	// NamespaceAttribute is the resource attribute of the namespace whose defaults apply.
+	NamespaceAttribute string `mapstructure:"namespace_attribute"`
+	// Mapping is the mapping, if there's no mapping file.
+	Mapping Mapping `mapstructure:"mapping"`
+}
+
+// Mapping maps the attributes of log records to the HEC metadata and the CIM fields of their events.
+type Mapping struct {
+	// Fields renames attributes to CIM fields, e.g. http.request.method to http_method.
+	Fields map[string]string `mapstructure:"fields"`
+	// Namespaces are the defaults of the log records of each namespace, taking precedence over Defaults.
+	Namespaces map[string]Defaults `mapstructure:"namespaces"`
+	// Defaults are the metadata and fields of log records without the attributes of their metadata.
+	Defaults Defaults `mapstructure:"defaults"`
+	// Source are the attributes of the source, the first one set is used.
+	Source []string `mapstructure:"source"`
+	// Sourcetype are the attributes of the sourcetype, the first one set is used.
+	Sourcetype []string `mapstructure:"sourcetype"`
+	// Index are the attributes of the index, the first one set is used.
+	Index []string `mapstructure:"index"`
+	// Host are the attributes of the host, the first one set is used.
+	Host []string `mapstructure:"host"`
+}
+
+// Defaults are the default metadata of log records, and fields added to them.
+type Defaults struct {
+	// Fields are added to the log records without them.
+	Fields     map[string]string `mapstructure:"fields"`
+	Source     string            `mapstructure:"source"`
+	Sourcetype string            `mapstructure:"sourcetype"`
+	Index      string            `mapstructure:"index"`
+	Host       string            `mapstructure:"host"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.NamespaceAttribute == "" {
+		errs = errors.Join(errs, errors.New("namespace_attribute must be specified"))
+	}
+	mapping := &cfg.Mapping
+	if cfg.MappingFile != "" {
+		var err error
+		if mapping, err = loadMapping(cfg.MappingFile); err != nil {
+			return errors.Join(errs, err)
+		}
+	}
+	return errors.Join(errs, mapping.validate())
+}
+
+func (m *Mapping) validate() error {
+	var errs error
+	froms := make([]string, 0, len(m.Fields))
+	for from := range m.Fields {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	fields := map[string]string{}
+	for _, from := range froms {
+		to := m.Fields[from]
+		if to == "" {
+			errs = errors.Join(errs, fmt.Errorf("fields: the field of %q must be specified", from))
+		} else if other, ok := fields[to]; ok {
+			errs = errors.Join(errs, fmt.Errorf("fields: %q and %q are both mapped to %q", other, from, to))
+		}
+		fields[to] = from
+	}
+	for namespace := range m.Namespaces {
+		if namespace == "" {
+			errs = errors.Join(errs, errors.New("namespaces: namespace names must not be empty"))
+		}
+	}
+	return errs
+}
+
+// loadMapping reads a mapping file, rejecting unknown keys like the collector config.
+func loadMapping(path string) (*Mapping, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the mapping file: %w", err)
+	}
+	var raw map[string]any
+	if err = yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("invalid mapping file %q: %w", path, err)
+	}
+	mapping := &Mapping{}
+	if err = confmap.NewFromStringMap(raw).Unmarshal(mapping); err != nil {
+		return nil, fmt.Errorf("invalid mapping file %q: %w", path, err)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,105 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hecmappingprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	fileConfig := createDefaultConfig().(*Config)
+	fileConfig.MappingFile = filepath.Join("testdata", "mapping.yaml")
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id:       component.MustNewID(typeStr),
+			expected: createDefaultConfig().(*Config),
+		},
+		{
+			id:       component.MustNewIDWithName(typeStr, "file"),
+			expected: fileConfig,
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "inline"),
+			expected: &Config{
+				NamespaceAttribute: "service.namespace",
+				Mapping: Mapping{
+					Source:     []string{"log.file.path"},
+					Sourcetype: []string{"sourcetype"},
+					Host:       []string{"k8s.node.name"},
+					Fields:     map[string]string{"http.request.method": "http_method"},
+					Defaults:   Defaults{Sourcetype: "otel", Index: "main"},
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "namespace_attribute must be specified\n" +
+				`fields: "client.address" and "source.address" are both mapped to "src"` + "\n" +
+				`fields: the field of "user.name" must be specified`,
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	mapping, err := loadMapping(filepath.Join("testdata", "mapping.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, &Mapping{
+		Source:     []string{"log.file.path", "service.name"},
+		Sourcetype: []string{"sourcetype"},
+		Index:      []string{"index"},
+		Host:       []string{"k8s.node.name"},
+		Fields: map[string]string{
+			"http.request.method":       "http_method",
+			"http.response.status_code": "status",
+			"client.address":            "src",
+			"user.name":                 "user",
+		},
+		Defaults: Defaults{Sourcetype: "otel", Index: "main", Fields: map[string]string{"env": "prod"}},
+		Namespaces: map[string]Defaults{
+			"payments": {Sourcetype: "kube:payments", Index: "payments", Fields: map[string]string{"team": "payments"}},
+		},
+	}, mapping)
+
+	_, err = loadMapping(filepath.Join("testdata", "invalid_mapping.yaml"))
+	require.ErrorContains(t, err, "has invalid keys: sourcetypes")
+	_, err = loadMapping(filepath.Join("testdata", "missing.yaml"))
+	require.ErrorContains(t, err, "failed to read the mapping file")
+}
@@ -0,0 +1,148 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hecmappingprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	hostNameAttr   = "host.name"
+	sourceAttr     = "com.splunk.source"
+	sourcetypeAttr = "com.splunk.sourcetype"
+	indexAttr      = "com.splunk.index"
+)
+
+// metadataField is a HEC metadata field, set from the first set attribute of its attributes or its defaults.
+type metadataField struct {
+	defaultOf  func(Defaults) string
+	key        string
+	attributes []string
+}
+
+type hecMappingProcessor struct {
+	mapping            *Mapping
+	namespaceAttribute string
+	metadata           []metadataField
+}
+
+func newHECMappingProcessor(cfg *Config) (*hecMappingProcessor, error) {
+	mapping := &cfg.Mapping
+	if cfg.MappingFile != "" {
+		var err error
+		if mapping, err = loadMapping(cfg.MappingFile); err != nil {
+			return nil, err
+		}
+	}
+	return &hecMappingProcessor{
+		mapping:            mapping,
+		namespaceAttribute: cfg.NamespaceAttribute,
+		metadata: []metadataField{
+			{key: sourceAttr, attributes: mapping.Source, defaultOf: func(d Defaults) string { return d.Source }},
+			{key: sourcetypeAttr, attributes: mapping.Sourcetype, defaultOf: func(d Defaults) string { return d.Sourcetype }},
+			{key: indexAttr, attributes: mapping.Index, defaultOf: func(d Defaults) string { return d.Index }},
+			{key: hostNameAttr, attributes: mapping.Host, defaultOf: func(d Defaults) string { return d.Host }},
+		},
+	}, nil
+}
+
+func (p *hecMappingProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		resource := rl.Resource().Attributes()
+		p.renameFields(resource)
+		defaults := []Defaults{p.mapping.Defaults}
+		if ns, ok := resource.Get(p.namespaceAttribute); ok {
+			if nsDefaults, ok := p.mapping.Namespaces[ns.AsString()]; ok {
+				defaults = []Defaults{nsDefaults, p.mapping.Defaults}
+			}
+		}
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				p.mapRecord(lrs.At(k).Attributes(), resource, defaults)
+			}
+		}
+	}
+	return ld, nil
+}
+
+// renameFields renames the attributes mapped to CIM fields, unless the fields are already set.
+func (p *hecMappingProcessor) renameFields(attrs pcommon.Map) {
+	for from, to := range p.mapping.Fields {
+		v, ok := attrs.Get(from)
+		if !ok {
+			continue
+		}
+		if _, exists := attrs.Get(to); exists {
+			continue
+		}
+		v.CopyTo(attrs.PutEmpty(to))
+		attrs.Remove(from)
+	}
+}
+
+// mapRecord sets the HEC metadata of a log record, unless it or its resource already has it, and adds the
+// default fields it doesn't have.
+func (p *hecMappingProcessor) mapRecord(record, resource pcommon.Map, defaults []Defaults) {
+	p.renameFields(record)
+	for _, field := range p.metadata {
+		if has(field.key, record, resource) {
+			continue
+		}
+		if v, ok := firstSet(field.attributes, record, resource); ok {
+			record.PutStr(field.key, v.AsString())
+			continue
+		}
+		for _, d := range defaults {
+			if value := field.defaultOf(d); value != "" {
+				record.PutStr(field.key, value)
+				break
+			}
+		}
+	}
+	for _, d := range defaults {
+		for name, value := range d.Fields {
+			if !has(name, record, resource) {
+				record.PutStr(name, value)
+			}
+		}
+	}
+}
+
+func has(key string, record, resource pcommon.Map) bool {
+	_, ok := record.Get(key)
+	if !ok {
+		_, ok = resource.Get(key)
+	}
+	return ok
+}
+
+// firstSet returns the first attribute set on the record, or else on its resource.
+func firstSet(keys []string, record, resource pcommon.Map) (pcommon.Value, bool) {
+	for _, key := range keys {
+		if v, ok := record.Get(key); ok {
+			return v, true
+		}
+		if v, ok := resource.Get(key); ok {
+			return v, true
+		}
+	}
+	return pcommon.Value{}, false
+}
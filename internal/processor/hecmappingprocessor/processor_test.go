@@ -0,0 +1,93 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hecmappingprocessor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestProcessLogs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MappingFile = filepath.Join("testdata", "mapping.yaml")
+	p, err := newHECMappingProcessor(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	for _, resource := range []map[string]any{
+		{"k8s.namespace.name": "payments", "k8s.node.name": "node-1", "service.name": "checkout"},
+		{"k8s.namespace.name": "search", "host.name": "search-1", "com.splunk.index": "search"},
+		{"client.address": "10.0.0.1"},
+	} {
+		rl := ld.ResourceLogs().AppendEmpty()
+		require.NoError(t, rl.Resource().Attributes().FromRaw(resource))
+		rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	}
+	records := []map[string]any{
+		{"log.file.path": "/var/log/pods/checkout.log", "http.request.method": "GET", "http.response.status_code": 200, "team": "checkout"},
+		{"sourcetype": "search:query", "user.name": "alice", "user": "bob"},
+		{"com.splunk.sourcetype": "custom"},
+	}
+	for i, record := range records {
+		require.NoError(t, ld.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords().At(0).Attributes().FromRaw(record))
+	}
+
+	ld, err = p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	var resources, attrs []map[string]any
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		resources = append(resources, rl.Resource().Attributes().AsRaw())
+		attrs = append(attrs, rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+	}
+	assert.Equal(t, []map[string]any{
+		{"k8s.namespace.name": "payments", "k8s.node.name": "node-1", "service.name": "checkout"},
+		{"k8s.namespace.name": "search", "host.name": "search-1", "com.splunk.index": "search"},
+		{"src": "10.0.0.1"},
+	}, resources)
+	assert.Equal(t, []map[string]any{
+		{
+			// the namespace defaults take precedence over the defaults, and the record's fields over both
+			"log.file.path":         "/var/log/pods/checkout.log",
+			"http_method":           "GET",
+			"status":                int64(200),
+			"team":                  "checkout",
+			"com.splunk.source":     "/var/log/pods/checkout.log",
+			"com.splunk.sourcetype": "kube:payments",
+			"com.splunk.index":      "payments",
+			"host.name":             "node-1",
+			"env":                   "prod",
+		},
+		{
+			// the metadata of the resource is kept, and fields aren't renamed over existing ones
+			"sourcetype":            "search:query",
+			"user.name":             "alice",
+			"user":                  "bob",
+			"com.splunk.sourcetype": "search:query",
+			"env":                   "prod",
+		},
+		{
+			"com.splunk.sourcetype": "custom",
+			"com.splunk.index":      "main",
+			"env":                   "prod",
+		},
+	}, attrs)
+}
@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines how the explicit bucket histograms are re-bucketed.
+type Config struct {
+	// Rules are applied to the histograms whose name they match. Only the first matching rule
+	// is applied to a histogram.
+	Rules []RuleConfig `mapstructure:"rules"`
+}
+
+// RuleConfig re-buckets the histograms it matches to other bounds, or converts them to
+// exponential histograms.
+type RuleConfig struct {
+	// Exponential converts the matched histograms to exponential histograms.
+	Exponential *ExponentialConfig `mapstructure:"exponential"`
+	// Match is a regular expression the metric name must match. All histograms match if empty.
+	Match string `mapstructure:"match"`
+	// Bounds are the explicit bounds the matched histograms are re-bucketed to.
+	Bounds []float64 `mapstructure:"bounds"`
+}
+
+// ExponentialConfig defines the exponential histograms histograms are converted to.
+type ExponentialConfig struct {
+	// MaxSize is the maximum number of positive, and of negative, buckets, 160 if zero. The scale
+	// of the exponential histograms is the highest with which their values fit in MaxSize buckets.
+	MaxSize int `mapstructure:"max_size"`
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Rules) == 0 {
+		return errors.New("at least one rule must be specified")
+	}
+	var errs error
+	for i, r := range cfg.Rules {
+		errs = errors.Join(errs, r.validate(i))
+	}
+	return errs
+}
+
+func (r *RuleConfig) validate(i int) error {
+	var errs error
+	if _, err := regexp.Compile(r.Match); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("rules[%d]: invalid match: %w", i, err))
+	}
+	switch {
+	case r.Exponential != nil && len(r.Bounds) > 0:
+		errs = errors.Join(errs, fmt.Errorf("rules[%d]: bounds and exponential can't both be specified", i))
+	case r.Exponential != nil:
+		if r.Exponential.MaxSize < 0 {
+			errs = errors.Join(errs, fmt.Errorf("rules[%d]: exponential::max_size must not be negative", i))
+		}
+	case len(r.Bounds) == 0:
+		errs = errors.Join(errs, fmt.Errorf("rules[%d]: one of bounds or exponential must be specified", i))
+	}
+	for j := 1; j < len(r.Bounds); j++ {
+		if r.Bounds[j] <= r.Bounds[j-1] {
+			errs = errors.Join(errs, fmt.Errorf("rules[%d]: bounds must be in increasing order", i))
+			break
+		}
+	}
+	return errs
+}
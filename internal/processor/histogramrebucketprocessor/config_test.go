@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Rules: []RuleConfig{
+					{Match: `^http\.server\.`, Bounds: []float64{5, 10, 25, 50, 100, 250, 500, 1000}},
+					{Exponential: &ExponentialConfig{MaxSize: 80}},
+				},
+			},
+		},
+		{
+			id:       component.MustNewIDWithName(typeStr, "default_max_size"),
+			expected: &Config{Rules: []RuleConfig{{Exponential: &ExponentialConfig{}}}},
+		},
+		{
+			id:          component.MustNewIDWithName(typeStr, "no_rules"),
+			expectedErr: "at least one rule must be specified",
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "rules[0]: invalid match: error parsing regexp: missing closing ): `(`\n" +
+				"rules[0]: bounds must be in increasing order\n" +
+				"rules[1]: bounds and exponential can't both be specified\n" +
+				"rules[2]: one of bounds or exponential must be specified\n" +
+				"rules[3]: exponential::max_size must not be negative",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	defaultMaxSize = 160
+	// maxScale and minScale are the scales exponential histograms are converted at, from the
+	// highest down to the lowest allowed by the OpenTelemetry specification.
+	maxScale = 20
+	minScale = -10
+)
+
+// rule is a compiled RuleConfig.
+type rule struct {
+	re      *regexp.Regexp
+	bounds  []float64
+	maxSize int
+}
+
+// histogramRebucketProcessor re-buckets explicit bucket histograms to other bounds, or converts
+// them to exponential histograms, to reduce the number of series of histograms with many buckets.
+type histogramRebucketProcessor struct {
+	rules []rule
+}
+
+func newHistogramRebucketProcessor(cfg *Config) *histogramRebucketProcessor {
+	hp := &histogramRebucketProcessor{}
+	for _, r := range cfg.Rules {
+		compiled := rule{bounds: r.Bounds}
+		if r.Match != "" {
+			compiled.re = regexp.MustCompile(r.Match)
+		}
+		if r.Exponential != nil {
+			compiled.maxSize = r.Exponential.MaxSize
+			if compiled.maxSize == 0 {
+				compiled.maxSize = defaultMaxSize
+			}
+		}
+		hp.rules = append(hp.rules, compiled)
+	}
+	return hp
+}
+
+func (hp *histogramRebucketProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		sms := md.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				if m.Type() != pmetric.MetricTypeHistogram {
+					continue
+				}
+				r := hp.match(m.Name())
+				switch {
+				case r == nil:
+				case r.maxSize > 0:
+					toExponential(m, r.maxSize)
+				default:
+					dps := m.Histogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						rebucket(dps.At(l), r.bounds)
+					}
+				}
+			}
+		}
+	}
+	return md, nil
+}
+
+func (hp *histogramRebucketProcessor) match(name string) *rule {
+	for i := range hp.rules {
+		if hp.rules[i].re == nil || hp.rules[i].re.MatchString(name) {
+			return &hp.rules[i]
+		}
+	}
+	return nil
+}
+
+// distribution approximates the observations of an explicit bucket histogram data point, assuming
+// those of each bucket are uniformly distributed between its bounds. The lower bound of the first
+// bucket and the upper bound of the last one, which are infinite, are taken as the minimum and
+// maximum of the data point if recorded, and otherwise as the bound of their other side.
+type distribution struct {
+	bounds       []float64
+	counts       []uint64
+	lower, upper float64
+	total        uint64
+}
+
+func newDistribution(dp pmetric.HistogramDataPoint) *distribution {
+	d := &distribution{bounds: dp.ExplicitBounds().AsRaw(), counts: dp.BucketCounts().AsRaw()}
+	for _, n := range d.counts {
+		d.total += n
+	}
+	if len(d.bounds) > 0 {
+		d.lower, d.upper = d.bounds[0], d.bounds[len(d.bounds)-1]
+	} else if dp.Count() > 0 {
+		d.lower = dp.Sum() / float64(dp.Count())
+		d.upper = d.lower
+	}
+	if dp.HasMin() && dp.Min() < d.lower {
+		d.lower = dp.Min()
+	}
+	if dp.HasMax() && dp.Max() > d.upper {
+		d.upper = dp.Max()
+	}
+	return d
+}
+
+// bucket returns the lower and upper bound of the bucket i.
+func (d *distribution) bucket(i int) (float64, float64) {
+	lo, hi := d.lower, d.upper
+	if i > 0 {
+		lo = d.bounds[i-1]
+	}
+	if i < len(d.bounds) {
+		hi = d.bounds[i]
+	}
+	return lo, hi
+}
+
+// cumulative returns the approximate number of observations less than or equal to x.
+func (d *distribution) cumulative(x float64) float64 {
+	var c float64
+	for i, n := range d.counts {
+		lo, hi := d.bucket(i)
+		switch {
+		case x <= lo && i > 0:
+			// buckets exclude their lower bound, which matters for the last bucket when its
+			// upper bound is its lower bound
+			return c
+		case x >= hi:
+			c += float64(n)
+		case x > lo:
+			return c + float64(n)*(x-lo)/(hi-lo)
+		default:
+			return c
+		}
+	}
+	return c
+}
+
+// rebucket re-buckets the data point to bounds. The observations of the buckets of the data point
+// are distributed to the new buckets they overlap, in proportion to the overlap, so bounds that are
+// a subset of those of the data point are re-bucketed exactly.
+func rebucket(dp pmetric.HistogramDataPoint, bounds []float64) {
+	if dp.BucketCounts().Len() == 0 || slices.Equal(dp.ExplicitBounds().AsRaw(), bounds) {
+		return
+	}
+	d := newDistribution(dp)
+	counts := make([]uint64, len(bounds)+1)
+	if d.total > 0 {
+		var prev uint64
+		for i, b := range bounds {
+			// rounding the cumulative counts rather than the counts of each bucket keeps the total
+			// count of the data point
+			c := min(max(uint64(math.Round(d.cumulative(b))), prev), d.total)
+			counts[i] = c - prev
+			prev = c
+		}
+		counts[len(bounds)] = d.total - prev
+	}
+	dp.ExplicitBounds().FromRaw(slices.Clone(bounds))
+	dp.BucketCounts().FromRaw(counts)
+}
+
+// toExponential converts the histogram m to an exponential histogram, of at most maxSize positive
+// and negative buckets.
+func toExponential(m pmetric.Metric, maxSize int) {
+	histogram := pmetric.NewHistogram()
+	m.Histogram().MoveTo(histogram)
+	eh := m.SetEmptyExponentialHistogram()
+	eh.SetAggregationTemporality(histogram.AggregationTemporality())
+	dps := histogram.DataPoints()
+	eh.DataPoints().EnsureCapacity(dps.Len())
+	for i := 0; i < dps.Len(); i++ {
+		convertDataPoint(dps.At(i), eh.DataPoints().AppendEmpty(), maxSize)
+	}
+}
+
+// observations are the observations of a bucket, all placed at its midpoint.
+type observations struct {
+	// index is the index of the exponential bucket of the midpoint at maxScale.
+	index int64
+	count uint64
+}
+
+func convertDataPoint(dp pmetric.HistogramDataPoint, edp pmetric.ExponentialHistogramDataPoint, maxSize int) {
+	dp.Attributes().CopyTo(edp.Attributes())
+	edp.SetStartTimestamp(dp.StartTimestamp())
+	edp.SetTimestamp(dp.Timestamp())
+	edp.SetFlags(dp.Flags())
+	edp.SetCount(dp.Count())
+	if dp.HasSum() {
+		edp.SetSum(dp.Sum())
+	}
+	if dp.HasMin() {
+		edp.SetMin(dp.Min())
+	}
+	if dp.HasMax() {
+		edp.SetMax(dp.Max())
+	}
+	dp.Exemplars().CopyTo(edp.Exemplars())
+
+	d := newDistribution(dp)
+	var positive, negative []observations
+	for i, n := range d.counts {
+		if n == 0 {
+			continue
+		}
+		lo, hi := d.bucket(i)
+		switch mid := lo + (hi-lo)/2; {
+		case mid > 0:
+			positive = append(positive, observations{index: index(mid), count: n})
+		case mid < 0:
+			negative = append(negative, observations{index: index(-mid), count: n})
+		default:
+			edp.SetZeroCount(edp.ZeroCount() + n)
+		}
+	}
+	scale := maxScale
+	for scale > minScale && (span(positive, maxScale-scale) > maxSize || span(negative, maxScale-scale) > maxSize) {
+		scale--
+	}
+	edp.SetScale(int32(scale))
+	fill(edp.Positive(), positive, maxScale-scale)
+	fill(edp.Negative(), negative, maxScale-scale)
+}
+
+// index returns the index of the exponential bucket of v, positive, at maxScale.
+func index(v float64) int64 {
+	return int64(math.Ceil(math.Ldexp(math.Log2(v), maxScale))) - 1
+}
+
+// span returns the number of buckets of obs after downscaling their indexes by shift.
+func span(obs []observations, shift int) int {
+	if len(obs) == 0 {
+		return 0
+	}
+	lowest, highest := obs[0].index, obs[0].index
+	for _, o := range obs[1:] {
+		lowest, highest = min(lowest, o.index), max(highest, o.index)
+	}
+	return int(highest>>shift-lowest>>shift) + 1
+}
+
+func fill(buckets pmetric.ExponentialHistogramDataPointBuckets, obs []observations, shift int) {
+	if len(obs) == 0 {
+		return
+	}
+	offset := obs[0].index >> shift
+	for _, o := range obs[1:] {
+		offset = min(offset, o.index>>shift)
+	}
+	counts := make([]uint64, span(obs, shift))
+	for _, o := range obs {
+		counts[o.index>>shift-offset] += o.count
+	}
+	buckets.SetOffset(int32(offset))
+	buckets.BucketCounts().FromRaw(counts)
+}
@@ -0,0 +1,174 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogramrebucketprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newDataPoint(bounds []float64, counts []uint64) pmetric.HistogramDataPoint {
+	dp := pmetric.NewHistogramDataPoint()
+	dp.ExplicitBounds().FromRaw(bounds)
+	dp.BucketCounts().FromRaw(counts)
+	for _, n := range counts {
+		dp.SetCount(dp.Count() + n)
+	}
+	return dp
+}
+
+func TestRebucket(t *testing.T) {
+	for _, tt := range []struct {
+		min, max       *float64
+		name           string
+		bounds         []float64
+		counts         []uint64
+		newBounds      []float64
+		expectedCounts []uint64
+	}{
+		{
+			name:           "subset of the bounds",
+			bounds:         []float64{1, 2, 5, 10},
+			counts:         []uint64{1, 2, 3, 4, 5},
+			newBounds:      []float64{2, 10},
+			expectedCounts: []uint64{3, 7, 5},
+		},
+		{
+			name:           "split buckets",
+			bounds:         []float64{0, 10},
+			counts:         []uint64{0, 10, 0},
+			newBounds:      []float64{2.5, 5},
+			expectedCounts: []uint64{3, 2, 5},
+		},
+		{
+			name:           "unbounded buckets without min and max",
+			bounds:         []float64{10},
+			counts:         []uint64{4, 4},
+			newBounds:      []float64{5, 15},
+			expectedCounts: []uint64{0, 8, 0},
+		},
+		{
+			name:           "unbounded buckets with min and max",
+			min:            ptr(0),
+			max:            ptr(20),
+			bounds:         []float64{10},
+			counts:         []uint64{4, 4},
+			newBounds:      []float64{5, 15},
+			expectedCounts: []uint64{2, 4, 2},
+		},
+		{
+			name:           "no observations",
+			bounds:         []float64{1, 2},
+			counts:         []uint64{0, 0, 0},
+			newBounds:      []float64{1},
+			expectedCounts: []uint64{0, 0},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := newDataPoint(tt.bounds, tt.counts)
+			if tt.min != nil {
+				dp.SetMin(*tt.min)
+			}
+			if tt.max != nil {
+				dp.SetMax(*tt.max)
+			}
+			rebucket(dp, tt.newBounds)
+			assert.Equal(t, tt.newBounds, dp.ExplicitBounds().AsRaw())
+			assert.Equal(t, tt.expectedCounts, dp.BucketCounts().AsRaw())
+		})
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestConvertDataPoint(t *testing.T) {
+	dp := newDataPoint([]float64{1, 2, 4}, []uint64{0, 4, 2, 0})
+	dp.Attributes().PutStr("host", "a")
+	dp.SetSum(12)
+	dp.Exemplars().AppendEmpty().SetDoubleValue(1.5)
+
+	edp := pmetric.NewExponentialHistogramDataPoint()
+	convertDataPoint(dp, edp, 2)
+	assert.Equal(t, map[string]any{"host": "a"}, edp.Attributes().AsRaw())
+	assert.Equal(t, uint64(6), edp.Count())
+	assert.InDelta(t, 12, edp.Sum(), 0)
+	assert.Equal(t, 1, edp.Exemplars().Len())
+	// the midpoints 1.5 and 3 are in adjacent buckets at scale 0
+	assert.Equal(t, int32(0), edp.Scale())
+	assert.Equal(t, int32(0), edp.Positive().Offset())
+	assert.Equal(t, []uint64{4, 2}, edp.Positive().BucketCounts().AsRaw())
+	assert.Equal(t, 0, edp.Negative().BucketCounts().Len())
+
+	// a single bucket per sign keeps the highest scale
+	dp = newDataPoint([]float64{-1, 0, 1}, []uint64{0, 2, 3, 0})
+	edp = pmetric.NewExponentialHistogramDataPoint()
+	convertDataPoint(dp, edp, 160)
+	assert.Equal(t, int32(maxScale), edp.Scale())
+	assert.Equal(t, int32(-(1<<maxScale)-1), edp.Positive().Offset())
+	assert.Equal(t, []uint64{3}, edp.Positive().BucketCounts().AsRaw())
+	assert.Equal(t, int32(-(1<<maxScale)-1), edp.Negative().Offset())
+	assert.Equal(t, []uint64{2}, edp.Negative().BucketCounts().AsRaw())
+
+	// observations of buckets whose midpoint is 0 are counted as zero
+	dp = newDataPoint([]float64{0}, []uint64{5, 1})
+	edp = pmetric.NewExponentialHistogramDataPoint()
+	convertDataPoint(dp, edp, 160)
+	assert.Equal(t, uint64(6), edp.ZeroCount())
+}
+
+func TestProcessMetrics(t *testing.T) {
+	hp := newHistogramRebucketProcessor(&Config{
+		Rules: []RuleConfig{
+			{Match: `^http\.`, Bounds: []float64{2, 10}},
+			{Match: `^rpc\.`, Exponential: &ExponentialConfig{}},
+		},
+	})
+
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	for _, name := range []string{"http.duration", "rpc.duration", "db.duration"} {
+		m := ms.AppendEmpty()
+		m.SetName(name)
+		m.SetUnit("ms")
+		h := m.SetEmptyHistogram()
+		h.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		newDataPoint([]float64{1, 2, 5, 10}, []uint64{1, 2, 3, 4, 5}).CopyTo(h.DataPoints().AppendEmpty())
+	}
+	ms.AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(1)
+
+	md, err := hp.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+	require.Equal(t, 4, ms.Len())
+
+	assert.Equal(t, []uint64{3, 7, 5}, ms.At(0).Histogram().DataPoints().At(0).BucketCounts().AsRaw())
+
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, ms.At(1).Type())
+	assert.Equal(t, "rpc.duration", ms.At(1).Name())
+	assert.Equal(t, "ms", ms.At(1).Unit())
+	eh := ms.At(1).ExponentialHistogram()
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, eh.AggregationTemporality())
+	require.Equal(t, 1, eh.DataPoints().Len())
+	assert.Equal(t, uint64(15), eh.DataPoints().At(0).Count())
+
+	assert.Equal(t, []float64{1, 2, 5, 10}, ms.At(2).Histogram().DataPoints().At(0).ExplicitBounds().AsRaw())
+	assert.Equal(t, pmetric.MetricTypeGauge, ms.At(3).Type())
+	assert.Equal(t, 1, md.ResourceMetrics().Len())
+}
@@ -0,0 +1,105 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	// metricTypeCounter counts matching log records, or sums their value_from value.
+	metricTypeCounter = "counter"
+	// metricTypeGauge reports the value_from value of the last matching log record.
+	metricTypeGauge = "gauge"
+	// metricTypeHistogram distributes the value_from values of matching log records into buckets.
+	metricTypeHistogram = "histogram"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the metrics derived from log records.
+type Config struct {
+	Metrics []MetricConfig `mapstructure:"metrics"`
+}
+
+// MetricConfig defines a metric derived from the log records it matches. Fields, used by
+// value_from and dimensions, are looked up in the named capture groups of match, then the
+// log record attributes, then the resource attributes.
+type MetricConfig struct {
+	// Attributes are values log record or resource attributes must have for the record to match.
+	Attributes map[string]string `mapstructure:"attributes"`
+	// Dimensions map the dimension names of the metric to the fields their values are taken from.
+	Dimensions map[string]string `mapstructure:"dimensions"`
+	// Name is the name of the metric.
+	Name string `mapstructure:"name"`
+	// Type is one of "counter", "gauge" or "histogram".
+	Type string `mapstructure:"type"`
+	// Match is a regular expression the log record body must match.
+	Match string `mapstructure:"match"`
+	// ValueFrom is the field the value of the metric is parsed from. Required for gauges
+	// and histograms.
+	ValueFrom string `mapstructure:"value_from"`
+	// Buckets are the upper bounds of the histogram buckets.
+	Buckets []float64 `mapstructure:"buckets"`
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Metrics) == 0 {
+		return errors.New("at least one metric must be specified")
+	}
+	var errs error
+	names := map[string]struct{}{}
+	for i, m := range cfg.Metrics {
+		if m.Name == "" {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: name must be specified", i))
+		} else if _, ok := names[m.Name]; ok {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: %q is specified more than once", i, m.Name))
+		}
+		names[m.Name] = struct{}{}
+		errs = errors.Join(errs, m.validate(i))
+	}
+	return errs
+}
+
+func (m *MetricConfig) validate(i int) error {
+	var errs error
+	switch m.Type {
+	case metricTypeCounter:
+	case metricTypeGauge, metricTypeHistogram:
+		if m.ValueFrom == "" {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: value_from must be specified for %s metrics", i, m.Type))
+		}
+	default:
+		errs = errors.Join(errs, fmt.Errorf("metrics[%d]: type must be one of %q, %q or %q", i, metricTypeCounter, metricTypeGauge, metricTypeHistogram))
+	}
+	if m.Type == metricTypeHistogram {
+		if len(m.Buckets) == 0 {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: buckets must be specified for histogram metrics", i))
+		} else if !sort.Float64sAreSorted(m.Buckets) {
+			errs = errors.Join(errs, fmt.Errorf("metrics[%d]: buckets must be in increasing order", i))
+		}
+	} else if len(m.Buckets) > 0 {
+		errs = errors.Join(errs, fmt.Errorf("metrics[%d]: buckets are only supported by histogram metrics", i))
+	}
+	if _, err := regexp.Compile(m.Match); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("metrics[%d]: invalid match: %w", i, err))
+	}
+	return errs
+}
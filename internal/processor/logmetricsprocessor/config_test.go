@@ -0,0 +1,99 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Metrics: []MetricConfig{{Name: "logs.count", Type: "counter"}},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				Metrics: []MetricConfig{
+					{
+						Name:       "http.requests",
+						Type:       "counter",
+						Match:      `(?P<method>GET|POST) \S+ (?P<status>\d{3})`,
+						Attributes: map[string]string{"log.file.name": "access.log"},
+						Dimensions: map[string]string{
+							"method":      "method",
+							"status_code": "status",
+							"host":        "host.name",
+						},
+					},
+					{
+						Name:      "http.response_time",
+						Type:      "histogram",
+						Match:     `took (?P<duration>[\d.]+)ms`,
+						ValueFrom: "duration",
+						Buckets:   []float64{10, 100, 1000},
+					},
+					{
+						Name:      "queue.depth",
+						Type:      "gauge",
+						ValueFrom: "queue_depth",
+					},
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "metrics[0]: name must be specified\n" +
+				"metrics[0]: buckets are only supported by histogram metrics\n" +
+				"metrics[1]: value_from must be specified for gauge metrics\n" +
+				"metrics[2]: \"queue.depth\" is specified more than once\n" +
+				"metrics[2]: type must be one of \"counter\", \"gauge\" or \"histogram\"\n" +
+				"metrics[2]: invalid match: error parsing regexp: missing closing ): `(`\n" +
+				"metrics[3]: buckets must be in increasing order",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateWithoutMetrics(t *testing.T) {
+	require.EqualError(t, createDefaultConfig().(*Config).Validate(), "at least one metric must be specified")
+}
@@ -0,0 +1,129 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	typeStr   = "log_metrics"
+	stability = component.StabilityLevelDevelopment
+)
+
+var processorCapabilities = consumer.Capabilities{MutatesData: false}
+
+var (
+	// instances are the logMetrics shared by the logs and metrics pipelines of a processor.
+	instances   = map[*Config]*sharedLogMetrics{}
+	instancesMu sync.Mutex
+)
+
+type sharedLogMetrics struct {
+	*logMetrics
+	refs int
+}
+
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		processor.WithLogs(createLogsProcessor, stability),
+		processor.WithMetrics(createMetricsProcessor, stability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{}
+}
+
+// acquire returns the logMetrics of the processor, which must be released on shutdown.
+func acquire(cfg *Config, set processor.Settings) *logMetrics {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	shared, ok := instances[cfg]
+	if !ok {
+		shared = &sharedLogMetrics{logMetrics: newLogMetrics(cfg, set.Logger)}
+		instances[cfg] = shared
+	}
+	shared.refs++
+	return shared.logMetrics
+}
+
+func release(cfg *Config) {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	if shared, ok := instances[cfg]; ok {
+		if shared.refs--; shared.refs <= 0 {
+			delete(instances, cfg)
+		}
+	}
+}
+
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	oCfg := cfg.(*Config)
+	lm := acquire(oCfg, set)
+	return processorhelper.NewLogs(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		lm.processLogs,
+		processorhelper.WithShutdown(func(context.Context) error {
+			release(oCfg)
+			return nil
+		}),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
+
+func createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	oCfg := cfg.(*Config)
+	lm := acquire(oCfg, set)
+	sink := &metricsSink{Metrics: nextConsumer}
+	return processorhelper.NewMetrics(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		func(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+			return md, nil
+		},
+		processorhelper.WithStart(func(context.Context, component.Host) error {
+			lm.addConsumer(sink)
+			return nil
+		}),
+		processorhelper.WithShutdown(func(context.Context) error {
+			lm.removeConsumer(sink)
+			release(oCfg)
+			return nil
+		}),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
@@ -0,0 +1,193 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// matcher derives a metric from the log records it matches.
+type matcher struct {
+	re     *regexp.Regexp
+	series map[string]*series
+	cfg    MetricConfig
+	// dimensionNames are the configured dimension names in a stable order.
+	dimensionNames []string
+}
+
+// series is the aggregated value of a metric for a set of dimensions.
+type series struct {
+	dimensions   map[string]string
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+	last         float64
+}
+
+func newMatcher(cfg MetricConfig) *matcher {
+	m := &matcher{cfg: cfg, series: map[string]*series{}}
+	if cfg.Match != "" {
+		m.re = regexp.MustCompile(cfg.Match)
+	}
+	for name := range cfg.Dimensions {
+		m.dimensionNames = append(m.dimensionNames, name)
+	}
+	sort.Strings(m.dimensionNames)
+	return m
+}
+
+// fields are the values available to value_from and dimensions for a matched log record.
+type fields struct {
+	re         *regexp.Regexp
+	resource   pcommon.Map
+	record     pcommon.Map
+	submatches []string
+}
+
+func (f fields) get(key string) (string, bool) {
+	if f.re != nil {
+		if i := f.re.SubexpIndex(key); i > 0 && i < len(f.submatches) && f.submatches[i] != "" {
+			return f.submatches[i], true
+		}
+	}
+	if v, ok := f.record.Get(key); ok {
+		return v.AsString(), true
+	}
+	if v, ok := f.resource.Get(key); ok {
+		return v.AsString(), true
+	}
+	return "", false
+}
+
+// consume aggregates the log record into its series if it matches.
+func (m *matcher) consume(resource pcommon.Map, record plog.LogRecord) {
+	f := fields{re: m.re, resource: resource, record: record.Attributes()}
+	for key, expected := range m.cfg.Attributes {
+		if v, ok := f.get(key); !ok || v != expected {
+			return
+		}
+	}
+	if m.re != nil {
+		if f.submatches = m.re.FindStringSubmatch(record.Body().AsString()); f.submatches == nil {
+			return
+		}
+	}
+
+	value := 1.0
+	if m.cfg.ValueFrom != "" {
+		raw, ok := f.get(m.cfg.ValueFrom)
+		if !ok {
+			return
+		}
+		var err error
+		if value, err = strconv.ParseFloat(strings.TrimSpace(raw), 64); err != nil {
+			return
+		}
+	}
+
+	dimensions := make(map[string]string, len(m.dimensionNames))
+	var key strings.Builder
+	for _, name := range m.dimensionNames {
+		v, ok := f.get(m.cfg.Dimensions[name])
+		if !ok {
+			continue
+		}
+		dimensions[name] = v
+		key.WriteString(strconv.Quote(name))
+		key.WriteString(strconv.Quote(v))
+	}
+	s, ok := m.series[key.String()]
+	if !ok {
+		s = &series{dimensions: dimensions}
+		if m.cfg.Type == metricTypeHistogram {
+			s.bucketCounts = make([]uint64, len(m.cfg.Buckets)+1)
+		}
+		m.series[key.String()] = s
+	}
+	s.count++
+	s.sum += value
+	s.last = value
+	if s.bucketCounts != nil {
+		// explicit bucket bounds are inclusive upper bounds
+		s.bucketCounts[sort.SearchFloat64s(m.cfg.Buckets, value)]++
+	}
+}
+
+// flush appends the aggregated series as delta data points to the metric slice and resets them.
+func (m *matcher) flush(metrics pmetric.MetricSlice, start, now pcommon.Timestamp) {
+	if len(m.series) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	metric := metrics.AppendEmpty()
+	metric.SetName(m.cfg.Name)
+	for _, key := range keys {
+		s := m.series[key]
+		var attributes pcommon.Map
+		switch m.cfg.Type {
+		case metricTypeCounter:
+			if metric.Type() != pmetric.MetricTypeSum {
+				sum := metric.SetEmptySum()
+				sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+				sum.SetIsMonotonic(true)
+			}
+			dp := metric.Sum().DataPoints().AppendEmpty()
+			if m.cfg.ValueFrom == "" {
+				dp.SetIntValue(int64(s.count))
+			} else {
+				dp.SetDoubleValue(s.sum)
+			}
+			dp.SetStartTimestamp(start)
+			dp.SetTimestamp(now)
+			attributes = dp.Attributes()
+		case metricTypeGauge:
+			if metric.Type() != pmetric.MetricTypeGauge {
+				metric.SetEmptyGauge()
+			}
+			dp := metric.Gauge().DataPoints().AppendEmpty()
+			dp.SetDoubleValue(s.last)
+			dp.SetTimestamp(now)
+			attributes = dp.Attributes()
+		case metricTypeHistogram:
+			if metric.Type() != pmetric.MetricTypeHistogram {
+				metric.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+			}
+			dp := metric.Histogram().DataPoints().AppendEmpty()
+			dp.SetCount(s.count)
+			dp.SetSum(s.sum)
+			dp.ExplicitBounds().FromRaw(m.cfg.Buckets)
+			dp.BucketCounts().FromRaw(s.bucketCounts)
+			dp.SetStartTimestamp(start)
+			dp.SetTimestamp(now)
+			attributes = dp.Attributes()
+		}
+		for name, v := range s.dimensions {
+			attributes.PutStr(name, v)
+		}
+	}
+	m.series = map[string]*series{}
+}
@@ -0,0 +1,121 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const scopeName = "github.com/signalfx/splunk-otel-collector/internal/processor/logmetricsprocessor"
+
+// logMetrics derives metrics from the log records of the logs pipelines the processor is in
+// and emits them to the metrics pipelines the same processor is in.
+type logMetrics struct {
+	now       func() time.Time
+	lastFlush time.Time
+	logger    *zap.Logger
+	consumers map[*metricsSink]struct{}
+	matchers  []*matcher
+	mu        sync.Mutex
+}
+
+// metricsSink is the next consumer of a metrics pipeline the processor is in.
+type metricsSink struct {
+	consumer.Metrics
+}
+
+func newLogMetrics(cfg *Config, logger *zap.Logger) *logMetrics {
+	lm := &logMetrics{
+		now:       time.Now,
+		logger:    logger,
+		consumers: map[*metricsSink]struct{}{},
+	}
+	for _, m := range cfg.Metrics {
+		lm.matchers = append(lm.matchers, newMatcher(m))
+	}
+	lm.lastFlush = lm.now()
+	return lm
+}
+
+func (lm *logMetrics) addConsumer(sink *metricsSink) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	lm.consumers[sink] = struct{}{}
+}
+
+func (lm *logMetrics) removeConsumer(sink *metricsSink) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	delete(lm.consumers, sink)
+}
+
+// processLogs derives metrics from the log records, which are passed on unchanged.
+func (lm *logMetrics) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	lm.mu.Lock()
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			records := rl.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				for _, m := range lm.matchers {
+					m.consume(rl.Resource().Attributes(), records.At(k))
+				}
+			}
+		}
+	}
+	md := lm.flush()
+	consumers := make([]consumer.Metrics, 0, len(lm.consumers))
+	for sink := range lm.consumers {
+		consumers = append(consumers, sink.Metrics)
+	}
+	lm.mu.Unlock()
+
+	if md.DataPointCount() == 0 {
+		return ld, nil
+	}
+	for i, c := range consumers {
+		toSend := md
+		if i < len(consumers)-1 {
+			toSend = pmetric.NewMetrics()
+			md.CopyTo(toSend)
+		}
+		if err := c.ConsumeMetrics(ctx, toSend); err != nil {
+			lm.logger.Warn("Failed to emit metrics derived from logs", zap.Error(err))
+		}
+	}
+	return ld, nil
+}
+
+// flush returns the metrics aggregated since the last flush. Must be called with the lock held.
+func (lm *logMetrics) flush() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(scopeName)
+	now := lm.now()
+	start, ts := pcommon.NewTimestampFromTime(lm.lastFlush), pcommon.NewTimestampFromTime(now)
+	for _, m := range lm.matchers {
+		m.flush(sm.Metrics(), start, ts)
+	}
+	lm.lastFlush = now
+	return md
+}
@@ -0,0 +1,169 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.uber.org/zap"
+)
+
+var testConfig = &Config{
+	Metrics: []MetricConfig{
+		{
+			Name:       "http.requests",
+			Type:       metricTypeCounter,
+			Match:      `(?P<method>GET|POST) \S+ (?P<status>\d{3})`,
+			Attributes: map[string]string{"log.file.name": "access.log"},
+			Dimensions: map[string]string{"method": "method", "status_code": "status", "host": "host.name"},
+		},
+		{
+			Name:      "http.response_time",
+			Type:      metricTypeHistogram,
+			Match:     `took (?P<duration>[\d.]+)ms`,
+			ValueFrom: "duration",
+			Buckets:   []float64{10, 100},
+		},
+		{
+			Name:      "queue.depth",
+			Type:      metricTypeGauge,
+			ValueFrom: "queue_depth",
+		},
+		{
+			Name:      "bytes.sent",
+			Type:      metricTypeCounter,
+			ValueFrom: "bytes",
+		},
+	},
+}
+
+func newTestLogs() plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "web-1")
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, r := range []struct {
+		attributes map[string]any
+		body       string
+	}{
+		{body: "GET /index.html 200 took 5ms", attributes: map[string]any{"log.file.name": "access.log", "bytes": 100}},
+		{body: "GET /index.html 200 took 50ms", attributes: map[string]any{"log.file.name": "access.log", "bytes": "20"}},
+		{body: "POST /login 500 took 500ms", attributes: map[string]any{"log.file.name": "access.log"}},
+		{body: "GET /index.html 200", attributes: map[string]any{"log.file.name": "other.log", "bytes": "not a number"}},
+		{body: "queue stats", attributes: map[string]any{"queue_depth": 3}},
+		{body: "queue stats", attributes: map[string]any{"queue_depth": 7}},
+	} {
+		lr := records.AppendEmpty()
+		lr.Body().SetStr(r.body)
+		_ = lr.Attributes().FromRaw(r.attributes)
+	}
+	return ld
+}
+
+func TestProcessLogs(t *testing.T) {
+	lm := newLogMetrics(testConfig, zap.NewNop())
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(time.Minute)
+	lm.lastFlush = start
+	lm.now = func() time.Time { return now }
+	sink := &consumertest.MetricsSink{}
+	lm.addConsumer(&metricsSink{Metrics: sink})
+
+	ld := newTestLogs()
+	out, err := lm.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, 6, out.LogRecordCount())
+
+	require.Len(t, sink.AllMetrics(), 1)
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 4, metrics.Len())
+
+	requests := metrics.At(0)
+	assert.Equal(t, "http.requests", requests.Name())
+	require.Equal(t, pmetric.MetricTypeSum, requests.Type())
+	assert.True(t, requests.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, requests.Sum().AggregationTemporality())
+	require.Equal(t, 2, requests.Sum().DataPoints().Len())
+	get, post := requests.Sum().DataPoints().At(0), requests.Sum().DataPoints().At(1)
+	assert.Equal(t, int64(2), get.IntValue())
+	assert.Equal(t, map[string]any{"method": "GET", "status_code": "200", "host": "web-1"}, get.Attributes().AsRaw())
+	assert.Equal(t, pcommon.NewTimestampFromTime(start), get.StartTimestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(now), get.Timestamp())
+	assert.Equal(t, int64(1), post.IntValue())
+	assert.Equal(t, map[string]any{"method": "POST", "status_code": "500", "host": "web-1"}, post.Attributes().AsRaw())
+
+	responseTime := metrics.At(1)
+	assert.Equal(t, "http.response_time", responseTime.Name())
+	require.Equal(t, pmetric.MetricTypeHistogram, responseTime.Type())
+	require.Equal(t, 1, responseTime.Histogram().DataPoints().Len())
+	hdp := responseTime.Histogram().DataPoints().At(0)
+	assert.Equal(t, uint64(3), hdp.Count())
+	assert.Equal(t, 555.0, hdp.Sum())
+	assert.Equal(t, []float64{10, 100}, hdp.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{1, 1, 1}, hdp.BucketCounts().AsRaw())
+
+	queueDepth := metrics.At(2)
+	assert.Equal(t, "queue.depth", queueDepth.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, queueDepth.Type())
+	assert.Equal(t, 7.0, queueDepth.Gauge().DataPoints().At(0).DoubleValue())
+
+	bytesSent := metrics.At(3)
+	assert.Equal(t, "bytes.sent", bytesSent.Name())
+	assert.Equal(t, 120.0, bytesSent.Sum().DataPoints().At(0).DoubleValue())
+
+	// series are reset once emitted
+	_, err = lm.processLogs(context.Background(), plog.NewLogs())
+	require.NoError(t, err)
+	assert.Len(t, sink.AllMetrics(), 1)
+}
+
+func TestFactorySharesProcessorAcrossPipelines(t *testing.T) {
+	f := NewFactory()
+	set := processortest.NewNopSettings()
+	cfg := &Config{Metrics: []MetricConfig{{Name: "logs.count", Type: metricTypeCounter}}}
+
+	logsSink := &consumertest.LogsSink{}
+	lp, err := f.CreateLogs(context.Background(), set, cfg, logsSink)
+	require.NoError(t, err)
+	metricsSink := &consumertest.MetricsSink{}
+	mp, err := f.CreateMetrics(context.Background(), set, cfg, metricsSink)
+	require.NoError(t, err)
+	require.NoError(t, lp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, mp.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, lp.ConsumeLogs(context.Background(), newTestLogs()))
+	assert.Equal(t, 6, logsSink.LogRecordCount())
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	dp := metricsSink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0)
+	assert.Equal(t, int64(6), dp.IntValue())
+
+	// metrics are passed through
+	require.NoError(t, mp.ConsumeMetrics(context.Background(), pmetric.NewMetrics()))
+	assert.Len(t, metricsSink.AllMetrics(), 2)
+
+	require.NoError(t, mp.Shutdown(context.Background()))
+	require.NoError(t, lp.Shutdown(context.Background()))
+	assert.Empty(t, instances)
+}
@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piiredactionprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the detectors whose matches are redacted from log records.
+type Config struct {
+	// Replacement replaces the redacted values. {detector} is replaced by the detector's name.
+	Replacement string `mapstructure:"replacement"`
+	// Detectors are the names of the built-in detectors to apply.
+	Detectors []string `mapstructure:"detectors"`
+	// CustomDetectors are additional detectors, e.g. loaded from a config source.
+	CustomDetectors []CustomDetectorConfig `mapstructure:"custom_detectors"`
+	// RedactAttributes also redacts the string values of log record attributes.
+	RedactAttributes bool `mapstructure:"redact_attributes"`
+}
+
+// CustomDetectorConfig is a detector matching a regular expression.
+type CustomDetectorConfig struct {
+	// Name identifies the detector in the replacement.
+	Name string `mapstructure:"name"`
+	// Pattern is the regular expression of the detector. If it has a capture group named value,
+	// only the group is redacted, e.g. to keep the key of a key=value pair.
+	Pattern string `mapstructure:"pattern"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Detectors) == 0 && len(cfg.CustomDetectors) == 0 {
+		errs = errors.Join(errs, errors.New("at least one detector or custom detector must be specified"))
+	}
+	names := map[string]struct{}{}
+	for _, name := range cfg.Detectors {
+		if _, ok := builtinDetectors[name]; !ok {
+			errs = errors.Join(errs, fmt.Errorf("unknown detector %q", name))
+		}
+		names[name] = struct{}{}
+	}
+	for i, d := range cfg.CustomDetectors {
+		if d.Name == "" {
+			errs = errors.Join(errs, fmt.Errorf("custom_detectors[%d]: name must be specified", i))
+		} else if _, ok := names[d.Name]; ok {
+			errs = errors.Join(errs, fmt.Errorf("custom_detectors[%d]: %q is specified more than once", i, d.Name))
+		}
+		names[d.Name] = struct{}{}
+		if d.Pattern == "" {
+			errs = errors.Join(errs, fmt.Errorf("custom_detectors[%d]: pattern must be specified", i))
+		} else if _, err := regexp.Compile(d.Pattern); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("custom_detectors[%d]: invalid pattern: %w", i, err))
+		}
+	}
+	return errs
+}
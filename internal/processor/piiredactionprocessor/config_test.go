@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piiredactionprocessor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Replacement:      "[REDACTED:{detector}]",
+				Detectors:        []string{"credit_card", "email", "token"},
+				RedactAttributes: true,
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "custom"),
+			expected: &Config{
+				Replacement: "***",
+				Detectors:   []string{"email"},
+				CustomDetectors: []CustomDetectorConfig{
+					{Name: "ssn", Pattern: `\b\d{3}-\d{2}-\d{4}\b`},
+					{Name: "customer_id", Pattern: `customer=(?P<value>\w+)`},
+				},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: `unknown detector "phone"` + "\n" +
+				`custom_detectors[0]: "email" is specified more than once` + "\n" +
+				"custom_detectors[0]: invalid pattern: error parsing regexp: missing closing ): `(`\n" +
+				"custom_detectors[1]: name must be specified\n" +
+				"custom_detectors[1]: pattern must be specified",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateRequiresDetectors(t *testing.T) {
+	cfg := &Config{}
+	require.EqualError(t, cfg.Validate(), "at least one detector or custom detector must be specified")
+}
@@ -0,0 +1,109 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piiredactionprocessor
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	detectorCreditCard = "credit_card"
+	detectorEmail      = "email"
+	detectorToken      = "token"
+
+	// valueGroup is the name of the capture group that is redacted instead of the whole match.
+	valueGroup = "value"
+)
+
+// builtinDetectors are the detectors available by name.
+var builtinDetectors = map[string][]*detector{
+	detectorCreditCard: {
+		// 13 to 19 digits, optionally grouped by spaces or dashes, passing the Luhn check
+		{name: detectorCreditCard, re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+	},
+	detectorEmail: {
+		{name: detectorEmail, re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	},
+	detectorToken: {
+		// JSON web tokens
+		{name: detectorToken, re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+		// bearer and basic authorization credentials
+		{name: detectorToken, re: regexp.MustCompile(`(?i)\bbearer\s+(?P<value>[A-Za-z0-9\-._~+/]{8,}=*)`)},
+		{name: detectorToken, re: regexp.MustCompile(`\bBasic\s+(?P<value>[A-Za-z0-9+/]{8,}={0,2})`)},
+		// AWS access key IDs
+		{name: detectorToken, re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		// values of secret looking keys, e.g. password=... or "api_key": "..."
+		{name: detectorToken, re: regexp.MustCompile(
+			`(?i)\b(?:api[_-]?key|access[_-]?token|auth[_-]?token|x-sf-token|secret|password|passwd)"?\s*[:=]\s*"?(?P<value>[^\s"',;&]+)`)},
+	},
+}
+
+// detector redacts the matches of its regular expression.
+type detector struct {
+	re *regexp.Regexp
+	// valid, if set, filters the matches, e.g. to reduce false positives.
+	valid func(string) bool
+	name  string
+}
+
+// redact returns s with the matches of the detector replaced, and whether any were.
+func (d *detector) redact(s, replacement string) (string, bool) {
+	matches := d.re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s, false
+	}
+	group := d.re.SubexpIndex(valueGroup)
+	var b strings.Builder
+	last, redacted := 0, false
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		if d.valid != nil && !d.valid(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(replacement)
+		last, redacted = end, true
+	}
+	if !redacted {
+		return s, false
+	}
+	b.WriteString(s[last:])
+	return b.String(), true
+}
+
+// luhn returns whether the digits of s pass the Luhn checksum of payment card numbers.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,109 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piiredactionprocessor
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// redaction is a detector with its replacement.
+type redaction struct {
+	*detector
+	replacement string
+}
+
+type piiRedactionProcessor struct {
+	redactions       []redaction
+	redactAttributes bool
+}
+
+func newPIIRedactionProcessor(cfg *Config) (*piiRedactionProcessor, error) {
+	detectors := make([]*detector, 0, len(cfg.Detectors)+len(cfg.CustomDetectors))
+	for _, name := range cfg.Detectors {
+		detectors = append(detectors, builtinDetectors[name]...)
+	}
+	for _, d := range cfg.CustomDetectors {
+		re, err := regexp.Compile(d.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, &detector{name: d.Name, re: re})
+	}
+	p := &piiRedactionProcessor{redactAttributes: cfg.RedactAttributes}
+	for _, d := range detectors {
+		p.redactions = append(p.redactions, redaction{
+			detector:    d,
+			replacement: strings.ReplaceAll(cfg.Replacement, "{detector}", d.name),
+		})
+	}
+	return p, nil
+}
+
+func (p *piiRedactionProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sls := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				p.redactValue(lr.Body())
+				if p.redactAttributes {
+					p.redactMap(lr.Attributes())
+				}
+			}
+		}
+	}
+	return ld, nil
+}
+
+// redactValue redacts the string values of v, including those nested in maps and slices.
+func (p *piiRedactionProcessor) redactValue(v pcommon.Value) {
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		if s, ok := p.redact(v.Str()); ok {
+			v.SetStr(s)
+		}
+	case pcommon.ValueTypeMap:
+		p.redactMap(v.Map())
+	case pcommon.ValueTypeSlice:
+		for i := 0; i < v.Slice().Len(); i++ {
+			p.redactValue(v.Slice().At(i))
+		}
+	}
+}
+
+func (p *piiRedactionProcessor) redactMap(m pcommon.Map) {
+	m.Range(func(_ string, v pcommon.Value) bool {
+		p.redactValue(v)
+		return true
+	})
+}
+
+// redact applies all redactions to s in order, returning whether any matched.
+func (p *piiRedactionProcessor) redact(s string) (string, bool) {
+	redacted := false
+	for _, r := range p.redactions {
+		var ok bool
+		if s, ok = r.redact(s, r.replacement); ok {
+			redacted = true
+		}
+	}
+	return s, redacted
+}
@@ -0,0 +1,68 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piiredactionprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestProcessLogs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CustomDetectors = []CustomDetectorConfig{{Name: "customer_id", Pattern: `customer=(?P<value>\w+)`}}
+	p, err := newPIIRedactionProcessor(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lr := lrs.AppendEmpty()
+	lr.Body().SetStr("customer=c42 registered jane@example.com")
+	lr.Attributes().PutStr("user.email", "jane@example.com")
+	lr.Attributes().PutInt("count", 1)
+	structured := lrs.AppendEmpty()
+	body := structured.Body().SetEmptyMap()
+	body.PutStr("message", "charged 4111111111111111")
+	body.PutEmptySlice("headers").AppendEmpty().SetStr("Authorization: Bearer abc123def456")
+
+	_, err = p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, "customer=[REDACTED:customer_id] registered [REDACTED:email]", lr.Body().Str())
+	assert.Equal(t, map[string]any{"user.email": "[REDACTED:email]", "count": int64(1)}, lr.Attributes().AsRaw())
+	assert.Equal(t, map[string]any{
+		"message": "charged [REDACTED:credit_card]",
+		"headers": []any{"Authorization: Bearer [REDACTED:token]"},
+	}, structured.Body().Map().AsRaw())
+}
+
+func TestProcessLogsKeepsAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RedactAttributes = false
+	p, err := newPIIRedactionProcessor(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStr("jane@example.com")
+	lr.Attributes().PutStr("user.email", "jane@example.com")
+
+	_, err = p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, "[REDACTED:email]", lr.Body().Str())
+	assert.Equal(t, "jane@example.com", lr.Attributes().AsRaw()["user.email"])
+}
@@ -0,0 +1,38 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinepauseprocessor
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+type Config struct {
+	// PipelinePause is the pipeline_pause extension providing the paused state of the pipeline.
+	PipelinePause component.ID `mapstructure:"pipeline_pause"`
+	// Pipeline is the name the pipeline is paused and resumed by. Processors with the same name
+	// are paused together.
+	Pipeline string `mapstructure:"pipeline"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Pipeline == "" {
+		return errors.New("pipeline must be specified")
+	}
+	return nil
+}
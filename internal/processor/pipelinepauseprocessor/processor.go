@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinepauseprocessor
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/signalfx/splunk-otel-collector/internal/extension/pipelinepauseextension"
+)
+
+type pipelinePauseProcessor struct {
+	config *Config
+	pause  pipelinepauseextension.PipelinePause
+}
+
+func newPipelinePauseProcessor(config *Config) *pipelinePauseProcessor {
+	return &pipelinePauseProcessor{config: config}
+}
+
+func (pp *pipelinePauseProcessor) start(_ context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[pp.config.PipelinePause]
+	if !ok {
+		return fmt.Errorf("pipeline_pause extension %q not found", pp.config.PipelinePause)
+	}
+	if pp.pause, ok = ext.(pipelinepauseextension.PipelinePause); !ok {
+		return fmt.Errorf("extension %q is not a pipeline_pause extension", pp.config.PipelinePause)
+	}
+	pp.pause.Register(pp.config.Pipeline)
+	return nil
+}
+
+// paused returns a non-permanent error while the pipeline is paused, which receivers report to
+// senders as retryable.
+func (pp *pipelinePauseProcessor) paused() error {
+	if pp.pause.Paused(pp.config.Pipeline) {
+		return fmt.Errorf("pipeline %q is paused", pp.config.Pipeline)
+	}
+	return nil
+}
+
+func (pp *pipelinePauseProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	return md, pp.paused()
+}
+
+func (pp *pipelinePauseProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	return td, pp.paused()
+}
+
+func (pp *pipelinePauseProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	return ld, pp.paused()
+}
@@ -0,0 +1,117 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelinepauseprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+type fakePipelinePause struct {
+	component.StartFunc
+	component.ShutdownFunc
+	paused     map[string]bool
+	registered []string
+}
+
+func (f *fakePipelinePause) Register(pipeline string) {
+	f.registered = append(f.registered, pipeline)
+}
+
+func (f *fakePipelinePause) Paused(pipeline string) bool {
+	return f.paused[pipeline]
+}
+
+type hostWithExtensions struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func (h hostWithExtensions) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+func newHost(ext extension.Extension) component.Host {
+	return hostWithExtensions{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]extension.Extension{component.MustNewID("pipeline_pause"): ext},
+	}
+}
+
+func TestStartRequiresPipelinePauseExtension(t *testing.T) {
+	cfg := &Config{PipelinePause: component.MustNewID("pipeline_pause"), Pipeline: "logs"}
+	p, err := NewFactory().CreateLogs(context.Background(), processortest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.EqualError(t, p.Start(context.Background(), componenttest.NewNopHost()), `pipeline_pause extension "pipeline_pause" not found`)
+
+	notPipelinePause := struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}
+	require.EqualError(t, p.Start(context.Background(), newHost(notPipelinePause)),
+		`extension "pipeline_pause" is not a pipeline_pause extension`)
+}
+
+func TestPausedPipeline(t *testing.T) {
+	pause := &fakePipelinePause{paused: map[string]bool{}}
+	cfg := &Config{PipelinePause: component.MustNewID("pipeline_pause"), Pipeline: "logs/debug"}
+	sink := &consumertest.LogsSink{}
+	p, err := NewFactory().CreateLogs(context.Background(), processortest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(context.Background(), newHost(pause)))
+	assert.Equal(t, []string{"logs/debug"}, pause.registered)
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("debug")
+	require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+	pause.paused["logs/debug"] = true
+	require.EqualError(t, p.ConsumeLogs(context.Background(), ld), `pipeline "logs/debug" is paused`)
+	pause.paused["logs/debug"] = false
+	require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+	assert.Len(t, sink.AllLogs(), 2)
+}
+
+func TestOtherPipelinesKeepFlowing(t *testing.T) {
+	pause := &fakePipelinePause{paused: map[string]bool{"logs/debug": true}}
+	cfg := &Config{PipelinePause: component.MustNewID("pipeline_pause"), Pipeline: "metrics"}
+	metricsSink := &consumertest.MetricsSink{}
+	mp, err := NewFactory().CreateMetrics(context.Background(), processortest.NewNopSettings(), cfg, metricsSink)
+	require.NoError(t, err)
+	require.NoError(t, mp.Start(context.Background(), newHost(pause)))
+	tracesSink := &consumertest.TracesSink{}
+	tp, err := NewFactory().CreateTraces(context.Background(), processortest.NewNopSettings(), cfg, tracesSink)
+	require.NoError(t, err)
+	require.NoError(t, tp.Start(context.Background(), newHost(pause)))
+
+	require.NoError(t, mp.ConsumeMetrics(context.Background(), pmetric.NewMetrics()))
+	require.NoError(t, tp.ConsumeTraces(context.Background(), ptrace.NewTraces()))
+	assert.Len(t, metricsSink.AllMetrics(), 1)
+	assert.Len(t, tracesSink.AllTraces(), 1)
+
+	pause.paused["metrics"] = true
+	require.Error(t, mp.ConsumeMetrics(context.Background(), pmetric.NewMetrics()))
+	require.Error(t, tp.ConsumeTraces(context.Background(), ptrace.NewTraces()))
+}
@@ -0,0 +1,62 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	// actionDrop drops the telemetry exceeding the quota.
+	actionDrop = "drop"
+	// actionSample keeps sampling_percentage of the telemetry exceeding the quota.
+	actionSample = "sample"
+	// actionTag keeps the telemetry exceeding the quota, with the quota.exceeded resource attribute.
+	actionTag = "tag"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the namespace of the telemetry and what's done with the telemetry exceeding its quota.
+type Config struct {
+	// Quota is the quota extension holding the quotas of the namespaces.
+	Quota component.ID `mapstructure:"quota"`
+	// Attribute is the resource attribute whose value is the namespace of the telemetry, e.g. a
+	// Kubernetes namespace or a team.
+	Attribute string `mapstructure:"attribute"`
+	// Action is one of "drop", "sample" or "tag".
+	Action string `mapstructure:"action"`
+	// SamplingPercentage is the percentage of the telemetry exceeding the quota kept by the sample action.
+	SamplingPercentage float64 `mapstructure:"sampling_percentage"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Attribute == "" {
+		errs = errors.Join(errs, errors.New("attribute must not be empty"))
+	}
+	switch cfg.Action {
+	case actionDrop, actionSample, actionTag:
+	default:
+		errs = errors.Join(errs, fmt.Errorf("action must be one of %q, %q or %q", actionDrop, actionSample, actionTag))
+	}
+	if cfg.SamplingPercentage < 0 || cfg.SamplingPercentage > 100 {
+		errs = errors.Join(errs, errors.New("sampling_percentage must be between 0 and 100"))
+	}
+	return errs
+}
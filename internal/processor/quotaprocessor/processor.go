@@ -0,0 +1,247 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+
+	"github.com/signalfx/splunk-otel-collector/internal/extension/quotaextension"
+)
+
+// quotaExceededAttr is the resource attribute the tag action sets on telemetry exceeding its quota.
+const quotaExceededAttr = "quota.exceeded"
+
+var (
+	logsSizer    = &plog.ProtoMarshaler{}
+	metricsSizer = &pmetric.ProtoMarshaler{}
+	tracesSizer  = &ptrace.ProtoMarshaler{}
+)
+
+// quotaProcessor accounts the telemetry of each resource to the quota of its namespace in the
+// quota extension, and drops, samples or tags the resources exceeding it. Resources without
+// namespace aren't limited.
+type quotaProcessor struct {
+	config *Config
+	quota  quotaextension.Quota
+}
+
+func newQuotaProcessor(config *Config) *quotaProcessor {
+	return &quotaProcessor{config: config}
+}
+
+func (qp *quotaProcessor) start(_ context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[qp.config.Quota]
+	if !ok {
+		return fmt.Errorf("quota extension %q not found", qp.config.Quota)
+	}
+	if qp.quota, ok = ext.(quotaextension.Quota); !ok {
+		return fmt.Errorf("extension %q is not a quota extension", qp.config.Quota)
+	}
+	return nil
+}
+
+// admit returns whether the telemetry of the resource is within the quota of its namespace, and
+// tags the resource if it isn't and the action is tag.
+func (qp *quotaProcessor) admit(ctx context.Context, resource pcommon.Resource, signal pipeline.Signal, items int, size func() int) bool {
+	namespace, ok := resource.Attributes().Get(qp.config.Attribute)
+	if !ok || namespace.AsString() == "" {
+		return true
+	}
+	if qp.quota.Admit(ctx, namespace.AsString(), signal, int64(items), int64(size())) {
+		return true
+	}
+	if qp.config.Action == actionTag {
+		resource.Attributes().PutBool(quotaExceededAttr, true)
+		return true
+	}
+	return false
+}
+
+func (qp *quotaProcessor) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	td.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		if qp.admit(ctx, rs.Resource(), pipeline.SignalTraces, spanCount(rs), func() int { return resourceSpansSize(rs) }) {
+			return false
+		}
+		if qp.config.Action == actionDrop {
+			return true
+		}
+		rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			ss.Spans().RemoveIf(func(span ptrace.Span) bool {
+				return !sampled(span.TraceID(), qp.config.SamplingPercentage)
+			})
+			return ss.Spans().Len() == 0
+		})
+		return rs.ScopeSpans().Len() == 0
+	})
+	if td.ResourceSpans().Len() == 0 {
+		return td, processorhelper.ErrSkipProcessingData
+	}
+	return td, nil
+}
+
+func (qp *quotaProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		if qp.admit(ctx, rl.Resource(), pipeline.SignalLogs, logRecordCount(rl), func() int { return resourceLogsSize(rl) }) {
+			return false
+		}
+		if qp.config.Action == actionDrop {
+			return true
+		}
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				return !sampled(lr.TraceID(), qp.config.SamplingPercentage)
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+	if ld.ResourceLogs().Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+func (qp *quotaProcessor) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	md.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		if qp.admit(ctx, rm.Resource(), pipeline.SignalMetrics, dataPointCount(rm), func() int { return resourceMetricsSize(rm) }) {
+			return false
+		}
+		if qp.config.Action == actionDrop {
+			return true
+		}
+		rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			sm.Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				// data points are sampled randomly
+				return removeDataPoints(m, func() bool {
+					return rand.Float64()*100 >= qp.config.SamplingPercentage // nolint:gosec // sampling doesn't require a secure random number
+				}) == 0
+			})
+			return sm.Metrics().Len() == 0
+		})
+		return rm.ScopeMetrics().Len() == 0
+	})
+	if md.ResourceMetrics().Len() == 0 {
+		return md, processorhelper.ErrSkipProcessingData
+	}
+	return md, nil
+}
+
+// sampled returns whether the item of the trace is kept. All the spans and logs of a trace are
+// kept or dropped together, while items without a trace are sampled randomly.
+func sampled(traceID pcommon.TraceID, percentage float64) bool {
+	var bucket uint64
+	if traceID.IsEmpty() {
+		bucket = rand.Uint64() // nolint:gosec // sampling doesn't require a secure random number
+	} else {
+		bucket = binary.BigEndian.Uint64(traceID[8:])
+	}
+	return float64(bucket%10000) < percentage*100
+}
+
+func spanCount(rs ptrace.ResourceSpans) int {
+	n := 0
+	for i := 0; i < rs.ScopeSpans().Len(); i++ {
+		n += rs.ScopeSpans().At(i).Spans().Len()
+	}
+	return n
+}
+
+func logRecordCount(rl plog.ResourceLogs) int {
+	n := 0
+	for i := 0; i < rl.ScopeLogs().Len(); i++ {
+		n += rl.ScopeLogs().At(i).LogRecords().Len()
+	}
+	return n
+}
+
+func dataPointCount(rm pmetric.ResourceMetrics) int {
+	n := 0
+	for i := 0; i < rm.ScopeMetrics().Len(); i++ {
+		ms := rm.ScopeMetrics().At(i).Metrics()
+		for j := 0; j < ms.Len(); j++ {
+			switch m := ms.At(j); m.Type() {
+			case pmetric.MetricTypeGauge:
+				n += m.Gauge().DataPoints().Len()
+			case pmetric.MetricTypeSum:
+				n += m.Sum().DataPoints().Len()
+			case pmetric.MetricTypeHistogram:
+				n += m.Histogram().DataPoints().Len()
+			case pmetric.MetricTypeExponentialHistogram:
+				n += m.ExponentialHistogram().DataPoints().Len()
+			case pmetric.MetricTypeSummary:
+				n += m.Summary().DataPoints().Len()
+			}
+		}
+	}
+	return n
+}
+
+// removeDataPoints removes the data points of the metric for which remove returns true, and
+// returns the number of remaining data points.
+func removeDataPoints(m pmetric.Metric, remove func() bool) int {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		m.Gauge().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return remove() })
+		return m.Gauge().DataPoints().Len()
+	case pmetric.MetricTypeSum:
+		m.Sum().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return remove() })
+		return m.Sum().DataPoints().Len()
+	case pmetric.MetricTypeHistogram:
+		m.Histogram().DataPoints().RemoveIf(func(pmetric.HistogramDataPoint) bool { return remove() })
+		return m.Histogram().DataPoints().Len()
+	case pmetric.MetricTypeExponentialHistogram:
+		m.ExponentialHistogram().DataPoints().RemoveIf(func(pmetric.ExponentialHistogramDataPoint) bool { return remove() })
+		return m.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricTypeSummary:
+		m.Summary().DataPoints().RemoveIf(func(pmetric.SummaryDataPoint) bool { return remove() })
+		return m.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+// The sizes of resources are measured by moving them to a payload of their own and back, which
+// doesn't copy their telemetry.
+
+func resourceSpansSize(rs ptrace.ResourceSpans) int {
+	td := ptrace.NewTraces()
+	rs.MoveTo(td.ResourceSpans().AppendEmpty())
+	defer td.ResourceSpans().At(0).MoveTo(rs)
+	return tracesSizer.TracesSize(td)
+}
+
+func resourceLogsSize(rl plog.ResourceLogs) int {
+	ld := plog.NewLogs()
+	rl.MoveTo(ld.ResourceLogs().AppendEmpty())
+	defer ld.ResourceLogs().At(0).MoveTo(rl)
+	return logsSizer.LogsSize(ld)
+}
+
+func resourceMetricsSize(rm pmetric.ResourceMetrics) int {
+	md := pmetric.NewMetrics()
+	rm.MoveTo(md.ResourceMetrics().AppendEmpty())
+	defer md.ResourceMetrics().At(0).MoveTo(rm)
+	return metricsSizer.MetricsSize(md)
+}
@@ -0,0 +1,199 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quotaprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pipeline"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+type admission struct {
+	namespace string
+	signal    pipeline.Signal
+	items     int64
+}
+
+// fakeQuota admits the telemetry of all namespaces but over.
+type fakeQuota struct {
+	component.StartFunc
+	component.ShutdownFunc
+	admissions []admission
+}
+
+func (f *fakeQuota) Admit(_ context.Context, namespace string, signal pipeline.Signal, items, bytes int64) bool {
+	f.admissions = append(f.admissions, admission{namespace: namespace, signal: signal, items: items})
+	return bytes > 0 && namespace != "over"
+}
+
+type hostWithExtensions struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func (h hostWithExtensions) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+func newHost(ext extension.Extension) component.Host {
+	return hostWithExtensions{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]extension.Extension{component.MustNewID("quota"): ext},
+	}
+}
+
+func newTestProcessor(t *testing.T, action string, percentage float64) (*quotaProcessor, *fakeQuota) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Action = action
+	cfg.SamplingPercentage = percentage
+	qp := newQuotaProcessor(cfg)
+	quota := &fakeQuota{}
+	require.NoError(t, qp.start(context.Background(), newHost(quota)))
+	return qp, quota
+}
+
+func TestStartRequiresQuotaExtension(t *testing.T) {
+	qp := newQuotaProcessor(createDefaultConfig().(*Config))
+	require.EqualError(t, qp.start(context.Background(), componenttest.NewNopHost()), `quota extension "quota" not found`)
+
+	notQuota := struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}
+	require.EqualError(t, qp.start(context.Background(), newHost(notQuota)), `extension "quota" is not a quota extension`)
+}
+
+// testLogs returns logs of a resource per namespace, with two log records of the trace IDs kept
+// and dropped at 50%.
+func testLogs(namespaces ...string) plog.Logs {
+	ld := plog.NewLogs()
+	for _, namespace := range namespaces {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", "checkout")
+		if namespace != "" {
+			rl.Resource().Attributes().PutStr("k8s.namespace.name", namespace)
+		}
+		records := rl.ScopeLogs().AppendEmpty().LogRecords()
+		for _, traceID := range []pcommon.TraceID{
+			{15: 0x01},           // bucket 1, kept at 50%
+			{14: 0x1f, 15: 0x40}, // bucket 8000, dropped at 50%
+		} {
+			records.AppendEmpty().SetTraceID(traceID)
+		}
+	}
+	return ld
+}
+
+func namespaces(ld plog.Logs) map[string]int {
+	records := map[string]int{}
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		namespace, _ := rl.Resource().Attributes().Get("k8s.namespace.name")
+		if _, ok := rl.Resource().Attributes().Get(quotaExceededAttr); ok {
+			namespace = pcommon.NewValueStr(namespace.Str() + " (exceeded)")
+		}
+		records[namespace.Str()] = logRecordCount(rl)
+	}
+	return records
+}
+
+func TestLogs(t *testing.T) {
+	for _, tt := range []struct {
+		expected   map[string]int
+		action     string
+		percentage float64
+	}{
+		{action: actionDrop, expected: map[string]int{"under": 2, "": 2}},
+		{action: actionSample, percentage: 50, expected: map[string]int{"over": 1, "under": 2, "": 2}},
+		{action: actionSample, expected: map[string]int{"under": 2, "": 2}},
+		{action: actionTag, expected: map[string]int{"over (exceeded)": 2, "under": 2, "": 2}},
+	} {
+		t.Run(tt.action, func(t *testing.T) {
+			qp, quota := newTestProcessor(t, tt.action, tt.percentage)
+			ld, err := qp.processLogs(context.Background(), testLogs("over", "under", ""))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, namespaces(ld))
+			// resources without namespace aren't accounted
+			assert.Equal(t, []admission{
+				{namespace: "over", signal: pipeline.SignalLogs, items: 2},
+				{namespace: "under", signal: pipeline.SignalLogs, items: 2},
+			}, quota.admissions)
+		})
+	}
+}
+
+func TestTraces(t *testing.T) {
+	qp, quota := newTestProcessor(t, actionDrop, 0)
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("k8s.namespace.name", "over")
+	rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("checkout")
+	_, err := qp.processTraces(context.Background(), td)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	assert.Equal(t, []admission{{namespace: "over", signal: pipeline.SignalTraces, items: 1}}, quota.admissions)
+}
+
+func TestMetrics(t *testing.T) {
+	for _, tt := range []struct {
+		action     string
+		percentage float64
+		expected   int
+	}{
+		{action: actionSample, percentage: 100, expected: 3},
+		{action: actionSample, expected: 0},
+	} {
+		t.Run(tt.action, func(t *testing.T) {
+			qp, quota := newTestProcessor(t, tt.action, tt.percentage)
+
+			md := pmetric.NewMetrics()
+			rm := md.ResourceMetrics().AppendEmpty()
+			rm.Resource().Attributes().PutStr("k8s.namespace.name", "over")
+			ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+			ms.AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+			histogram := ms.AppendEmpty().SetEmptyHistogram()
+			histogram.DataPoints().AppendEmpty().SetCount(1)
+			histogram.DataPoints().AppendEmpty().SetCount(2)
+
+			md, err := qp.processMetrics(context.Background(), md)
+			if tt.expected == 0 {
+				require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, md.DataPointCount())
+			assert.Equal(t, []admission{{namespace: "over", signal: pipeline.SignalMetrics, items: 3}}, quota.admissions)
+		})
+	}
+}
+
+func TestResourceSizes(t *testing.T) {
+	ld := testLogs("checkout")
+	size := (&plog.ProtoMarshaler{}).LogsSize(ld)
+	assert.Equal(t, size, resourceLogsSize(ld.ResourceLogs().At(0)))
+	// the resource is moved back
+	assert.Equal(t, 2, ld.LogRecordCount())
+}
@@ -0,0 +1,48 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semconvprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the semantic conventions schema telemetry is stamped with and upgraded to.
+type Config struct {
+	// SchemaURL is the schema URL telemetry is stamped with. Attributes are renamed from the
+	// schema version of the telemetry to this one.
+	SchemaURL string `mapstructure:"schema_url"`
+	// DefaultSourceSchemaURL is the schema URL assumed for telemetry without one, usually the
+	// semantic conventions version of its receiver. Attributes of telemetry without a schema URL
+	// are only renamed if it's set.
+	DefaultSourceSchemaURL string `mapstructure:"default_source_schema_url"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if _, err := parseSchemaURL(cfg.SchemaURL); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("invalid schema_url: %w", err))
+	}
+	if cfg.DefaultSourceSchemaURL != "" {
+		if _, err := parseSchemaURL(cfg.DefaultSourceSchemaURL); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid default_source_schema_url: %w", err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,194 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semconvprocessor
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// semconvProcessor stamps resources and scopes with the configured schema URL and renames
+// their attributes from the schema version they declared, so telemetry of agents and
+// receivers following different semantic conventions versions has consistent attribute names.
+type semconvProcessor struct {
+	config  *Config
+	target  version
+	renames sync.Map
+}
+
+func newSemconvProcessor(config *Config) (*semconvProcessor, error) {
+	target, err := parseSchemaURL(config.SchemaURL)
+	if err != nil {
+		return nil, err
+	}
+	return &semconvProcessor{config: config, target: target}, nil
+}
+
+// translate returns the attribute renames from the source schema URL to the configured one
+// and whether telemetry of the source schema URL is stamped. Telemetry declaring a schema URL
+// without a version isn't following the semantic conventions and is left untouched.
+func (sp *semconvProcessor) translate(source string) ([]rename, bool) {
+	stamp := source == ""
+	if source == "" {
+		source = sp.config.DefaultSourceSchemaURL
+		if source == "" {
+			return nil, true
+		}
+	}
+	if renames, ok := sp.renames.Load(source); ok {
+		return renames.([]rename), true
+	}
+	from, err := parseSchemaURL(source)
+	if err != nil {
+		return nil, stamp
+	}
+	renames := renamesBetween(from, sp.target)
+	sp.renames.Store(source, renames)
+	return renames, true
+}
+
+// resource stamps a resource with the configured schema URL and renames its attributes.
+func (sp *semconvProcessor) resource(schemaURL string, setSchemaURL func(string), resource pcommon.Resource) {
+	renames, stamp := sp.translate(schemaURL)
+	if stamp {
+		setSchemaURL(sp.config.SchemaURL)
+	}
+	renameAttributes(resource.Attributes(), renames)
+}
+
+// scope stamps a scope with the configured schema URL and renames its attributes. Scopes
+// without a schema URL follow the one their resource declared. It returns the renames of the
+// attributes of the scope's telemetry.
+func (sp *semconvProcessor) scope(schemaURL, resourceSchemaURL string, setSchemaURL func(string), scope pcommon.InstrumentationScope) []rename {
+	if schemaURL == "" {
+		schemaURL = resourceSchemaURL
+	}
+	renames, stamp := sp.translate(schemaURL)
+	if stamp {
+		setSchemaURL(sp.config.SchemaURL)
+	}
+	renameAttributes(scope.Attributes(), renames)
+	return renames
+}
+
+func renameAttributes(attrs pcommon.Map, renames []rename) {
+	for _, r := range renames {
+		v, ok := attrs.Get(r.from)
+		if !ok {
+			continue
+		}
+		if _, ok = attrs.Get(r.to); !ok {
+			// Copied first as adding to the map may invalidate v.
+			value := pcommon.NewValueEmpty()
+			v.CopyTo(value)
+			value.CopyTo(attrs.PutEmpty(r.to))
+		}
+		attrs.Remove(r.from)
+	}
+}
+
+func (sp *semconvProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		rs := td.ResourceSpans().At(i)
+		resourceSchemaURL := rs.SchemaUrl()
+		sp.resource(resourceSchemaURL, rs.SetSchemaUrl, rs.Resource())
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			ss := rs.ScopeSpans().At(j)
+			renames := sp.scope(ss.SchemaUrl(), resourceSchemaURL, ss.SetSchemaUrl, ss.Scope())
+			if len(renames) == 0 {
+				continue
+			}
+			for k := 0; k < ss.Spans().Len(); k++ {
+				span := ss.Spans().At(k)
+				renameAttributes(span.Attributes(), renames)
+				for l := 0; l < span.Events().Len(); l++ {
+					renameAttributes(span.Events().At(l).Attributes(), renames)
+				}
+				for l := 0; l < span.Links().Len(); l++ {
+					renameAttributes(span.Links().At(l).Attributes(), renames)
+				}
+			}
+		}
+	}
+	return td, nil
+}
+
+func (sp *semconvProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		resourceSchemaURL := rl.SchemaUrl()
+		sp.resource(resourceSchemaURL, rl.SetSchemaUrl, rl.Resource())
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			renames := sp.scope(sl.SchemaUrl(), resourceSchemaURL, sl.SetSchemaUrl, sl.Scope())
+			if len(renames) == 0 {
+				continue
+			}
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				renameAttributes(sl.LogRecords().At(k).Attributes(), renames)
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (sp *semconvProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		resourceSchemaURL := rm.SchemaUrl()
+		sp.resource(resourceSchemaURL, rm.SetSchemaUrl, rm.Resource())
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			renames := sp.scope(sm.SchemaUrl(), resourceSchemaURL, sm.SetSchemaUrl, sm.Scope())
+			if len(renames) == 0 {
+				continue
+			}
+			for k := 0; k < sm.Metrics().Len(); k++ {
+				renameDataPointAttributes(sm.Metrics().At(k), renames)
+			}
+		}
+	}
+	return md, nil
+}
+
+func renameDataPointAttributes(m pmetric.Metric, renames []rename) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		for i := 0; i < m.Gauge().DataPoints().Len(); i++ {
+			renameAttributes(m.Gauge().DataPoints().At(i).Attributes(), renames)
+		}
+	case pmetric.MetricTypeSum:
+		for i := 0; i < m.Sum().DataPoints().Len(); i++ {
+			renameAttributes(m.Sum().DataPoints().At(i).Attributes(), renames)
+		}
+	case pmetric.MetricTypeHistogram:
+		for i := 0; i < m.Histogram().DataPoints().Len(); i++ {
+			renameAttributes(m.Histogram().DataPoints().At(i).Attributes(), renames)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		for i := 0; i < m.ExponentialHistogram().DataPoints().Len(); i++ {
+			renameAttributes(m.ExponentialHistogram().DataPoints().At(i).Attributes(), renames)
+		}
+	case pmetric.MetricTypeSummary:
+		for i := 0; i < m.Summary().DataPoints().Len(); i++ {
+			renameAttributes(m.Summary().DataPoints().At(i).Attributes(), renames)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semconvprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+const (
+	schema120 = "https://opentelemetry.io/schemas/1.20.0"
+	schema121 = "https://opentelemetry.io/schemas/1.21.0"
+)
+
+func TestProcessTraces(t *testing.T) {
+	sp, err := newSemconvProcessor(&Config{SchemaURL: schema121, DefaultSourceSchemaURL: schema120})
+	require.NoError(t, err)
+
+	td := ptrace.NewTraces()
+	// Without a schema URL, the default source schema URL applies.
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("net.host.name", "host")
+	ss := rs.ScopeSpans().AppendEmpty()
+	span := ss.Spans().AppendEmpty()
+	span.Attributes().PutStr("http.method", "GET")
+	span.Attributes().PutInt("http.status_code", 200)
+	span.Events().AppendEmpty().Attributes().PutStr("http.url", "http://localhost")
+	span.Links().AppendEmpty().Attributes().PutStr("http.scheme", "http")
+	// Scopes follow the schema URL of their resource.
+	rs = td.ResourceSpans().AppendEmpty()
+	rs.SetSchemaUrl("https://opentelemetry.io/schemas/1.22.0")
+	ss = rs.ScopeSpans().AppendEmpty()
+	ss.Spans().AppendEmpty().Attributes().PutInt("http.request.resend_count", 1)
+	// Scopes declaring a schema URL without a version are left untouched.
+	ss = rs.ScopeSpans().AppendEmpty()
+	ss.SetSchemaUrl("https://example.com/schemas/custom")
+	ss.Spans().AppendEmpty().Attributes().PutStr("http.method", "GET")
+
+	td, err = sp.processTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	rs = td.ResourceSpans().At(0)
+	assert.Equal(t, schema121, rs.SchemaUrl())
+	assert.Equal(t, map[string]any{"server.address": "host"}, rs.Resource().Attributes().AsRaw())
+	ss = rs.ScopeSpans().At(0)
+	assert.Equal(t, schema121, ss.SchemaUrl())
+	span = ss.Spans().At(0)
+	assert.Equal(t, map[string]any{"http.request.method": "GET", "http.response.status_code": int64(200)}, span.Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"url.full": "http://localhost"}, span.Events().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"url.scheme": "http"}, span.Links().At(0).Attributes().AsRaw())
+
+	rs = td.ResourceSpans().At(1)
+	assert.Equal(t, schema121, rs.SchemaUrl())
+	ss = rs.ScopeSpans().At(0)
+	assert.Equal(t, schema121, ss.SchemaUrl())
+	assert.Equal(t, map[string]any{"http.resend_count": int64(1)}, ss.Spans().At(0).Attributes().AsRaw())
+	ss = rs.ScopeSpans().At(1)
+	assert.Equal(t, "https://example.com/schemas/custom", ss.SchemaUrl())
+	assert.Equal(t, map[string]any{"http.method": "GET"}, ss.Spans().At(0).Attributes().AsRaw())
+}
+
+func TestProcessLogsOnlyStampsWithoutDefaultSource(t *testing.T) {
+	sp, err := newSemconvProcessor(&Config{SchemaURL: schema121})
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver")
+	sl.LogRecords().AppendEmpty().Attributes().PutStr("http.method", "GET")
+	rl = ld.ResourceLogs().AppendEmpty()
+	sl = rl.ScopeLogs().AppendEmpty()
+	sl.SetSchemaUrl(schema120)
+	sl.LogRecords().AppendEmpty().Attributes().PutStr("http.method", "POST")
+
+	ld, err = sp.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	rl = ld.ResourceLogs().At(0)
+	assert.Equal(t, schema121, rl.SchemaUrl())
+	assert.Equal(t, schema121, rl.ScopeLogs().At(0).SchemaUrl())
+	assert.Equal(t, map[string]any{"http.method": "GET"}, rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+	rl = ld.ResourceLogs().At(1)
+	assert.Equal(t, schema121, rl.SchemaUrl())
+	assert.Equal(t, schema121, rl.ScopeLogs().At(0).SchemaUrl())
+	assert.Equal(t, map[string]any{"http.request.method": "POST"}, rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+}
+
+func TestProcessMetricsDowngrades(t *testing.T) {
+	sp, err := newSemconvProcessor(&Config{SchemaURL: schema120})
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.SetSchemaUrl(schema121)
+	sm := rm.ScopeMetrics().AppendEmpty()
+	m := sm.Metrics().AppendEmpty()
+	m.SetEmptyGauge().DataPoints().AppendEmpty().Attributes().PutStr("http.request.method", "GET")
+	m = sm.Metrics().AppendEmpty()
+	m.SetEmptySum().DataPoints().AppendEmpty().Attributes().PutInt("server.port", 80)
+	m = sm.Metrics().AppendEmpty()
+	m.SetEmptyHistogram().DataPoints().AppendEmpty().Attributes().PutStr("url.scheme", "https")
+	m = sm.Metrics().AppendEmpty()
+	m.SetEmptyExponentialHistogram().DataPoints().AppendEmpty().Attributes().PutStr("url.full", "https://localhost")
+	m = sm.Metrics().AppendEmpty()
+	m.SetEmptySummary().DataPoints().AppendEmpty().Attributes().PutStr("network.protocol.name", "http")
+
+	md, err = sp.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	rm = md.ResourceMetrics().At(0)
+	assert.Equal(t, schema120, rm.SchemaUrl())
+	sm = rm.ScopeMetrics().At(0)
+	assert.Equal(t, schema120, sm.SchemaUrl())
+	metrics := sm.Metrics()
+	assert.Equal(t, map[string]any{"http.method": "GET"}, metrics.At(0).Gauge().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"net.host.port": int64(80)}, metrics.At(1).Sum().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"http.scheme": "https"}, metrics.At(2).Histogram().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"http.url": "https://localhost"}, metrics.At(3).ExponentialHistogram().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"net.protocol.name": "http"}, metrics.At(4).Summary().DataPoints().At(0).Attributes().AsRaw())
+}
+
+func TestRenameAttributesKeepsExistingValues(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("http.method", "GET")
+	attrs.PutStr("http.request.method", "POST")
+	attrs.PutEmptySlice("http.url").AppendEmpty().SetStr("http://localhost")
+
+	renameAttributes(attrs, renamesBetween(version{1, 20, 0}, version{1, 21, 0}))
+
+	assert.Equal(t, map[string]any{
+		"http.request.method": "POST",
+		"url.full":            []any{"http://localhost"},
+	}, attrs.AsRaw())
+}
+
+func TestCreateProcessors(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	set := processortest.NewNopSettings()
+
+	tp, err := factory.CreateTraces(context.Background(), set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, tp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	lp, err := factory.CreateLogs(context.Background(), set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, lp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, lp.Shutdown(context.Background()))
+
+	mp, err := factory.CreateMetrics(context.Background(), set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, mp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, mp.Shutdown(context.Background()))
+
+	_, err = factory.CreateMetrics(context.Background(), set, &Config{}, consumertest.NewNop())
+	require.EqualError(t, err, "schema URL is empty")
+}
@@ -0,0 +1,135 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semconvprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version is a semantic conventions schema version.
+type version [3]int
+
+func (v version) less(o version) bool {
+	for i := range v {
+		if v[i] != o[i] {
+			return v[i] < o[i]
+		}
+	}
+	return false
+}
+
+// parseSchemaURL returns the version of a schema URL like https://opentelemetry.io/schemas/1.26.0.
+func parseSchemaURL(schemaURL string) (version, error) {
+	var v version
+	if schemaURL == "" {
+		return v, fmt.Errorf("schema URL is empty")
+	}
+	parts := strings.Split(schemaURL[strings.LastIndex(schemaURL, "/")+1:], ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("schema URL %q doesn't end with a version", schemaURL)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("schema URL %q doesn't end with a version", schemaURL)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+type rename struct {
+	from, to string
+}
+
+// changes are the attribute renames of the semantic conventions schema versions, in ascending
+// version order. They're applied in reverse to downgrade attributes.
+var changes = []struct {
+	renames []rename
+	version version
+}{
+	{
+		version: version{1, 17, 0},
+		renames: []rename{
+			{"messaging.destination", "messaging.destination.name"},
+			{"messaging.destination_kind", "messaging.destination.kind"},
+			{"messaging.temp_destination", "messaging.destination.temporary"},
+			{"messaging.protocol", "net.app.protocol.name"},
+			{"messaging.protocol_version", "net.app.protocol.version"},
+			{"messaging.conversation_id", "messaging.message.conversation_id"},
+			{"messaging.message_id", "messaging.message.id"},
+			{"messaging.message_payload_size_bytes", "messaging.message.payload_size_bytes"},
+			{"messaging.message_payload_compressed_size_bytes", "messaging.message.payload_compressed_size_bytes"},
+		},
+	},
+	{
+		version: version{1, 20, 0},
+		renames: []rename{
+			{"net.app.protocol.name", "net.protocol.name"},
+			{"net.app.protocol.version", "net.protocol.version"},
+		},
+	},
+	{
+		version: version{1, 21, 0},
+		renames: []rename{
+			{"http.method", "http.request.method"},
+			{"http.status_code", "http.response.status_code"},
+			{"http.url", "url.full"},
+			{"http.scheme", "url.scheme"},
+			{"http.user_agent", "user_agent.original"},
+			{"http.request_content_length", "http.request.body.size"},
+			{"http.response_content_length", "http.response.body.size"},
+			{"net.host.name", "server.address"},
+			{"net.host.port", "server.port"},
+			{"net.sock.peer.addr", "network.peer.address"},
+			{"net.sock.peer.port", "network.peer.port"},
+			{"net.protocol.name", "network.protocol.name"},
+			{"net.protocol.version", "network.protocol.version"},
+		},
+	},
+	{
+		version: version{1, 22, 0},
+		renames: []rename{
+			{"messaging.message.payload_size_bytes", "messaging.message.body.size"},
+			{"http.resend_count", "http.request.resend_count"},
+		},
+	},
+}
+
+// renamesBetween returns the attribute renames translating attributes of the from version to
+// the to version.
+func renamesBetween(from, to version) []rename {
+	var renames []rename
+	switch {
+	case from.less(to):
+		for _, c := range changes {
+			if from.less(c.version) && !to.less(c.version) {
+				renames = append(renames, c.renames...)
+			}
+		}
+	case to.less(from):
+		for i := len(changes) - 1; i >= 0; i-- {
+			c := changes[i]
+			if to.less(c.version) && !from.less(c.version) {
+				for j := len(c.renames) - 1; j >= 0; j-- {
+					renames = append(renames, rename{from: c.renames[j].to, to: c.renames[j].from})
+				}
+			}
+		}
+	}
+	return renames
+}
@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semconvprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchemaURL(t *testing.T) {
+	v, err := parseSchemaURL("https://opentelemetry.io/schemas/1.21.0")
+	require.NoError(t, err)
+	assert.Equal(t, version{1, 21, 0}, v)
+
+	v, err = parseSchemaURL("1.9.10")
+	require.NoError(t, err)
+	assert.Equal(t, version{1, 9, 10}, v)
+
+	_, err = parseSchemaURL("https://example.com/schemas/custom")
+	require.EqualError(t, err, `schema URL "https://example.com/schemas/custom" doesn't end with a version`)
+	_, err = parseSchemaURL("https://opentelemetry.io/schemas/1.-1.0")
+	require.Error(t, err)
+}
+
+func TestVersionLess(t *testing.T) {
+	assert.True(t, version{1, 9, 0}.less(version{1, 21, 0}))
+	assert.True(t, version{1, 21, 0}.less(version{1, 21, 1}))
+	assert.False(t, version{1, 21, 0}.less(version{1, 21, 0}))
+	assert.False(t, version{2, 0, 0}.less(version{1, 26, 0}))
+}
+
+func TestRenamesBetween(t *testing.T) {
+	assert.Empty(t, renamesBetween(version{1, 21, 0}, version{1, 21, 0}))
+	assert.Empty(t, renamesBetween(version{1, 23, 0}, version{1, 26, 0}))
+
+	upgrade := renamesBetween(version{1, 19, 0}, version{1, 21, 0})
+	assert.Contains(t, upgrade, rename{"net.app.protocol.name", "net.protocol.name"})
+	assert.Contains(t, upgrade, rename{"http.method", "http.request.method"})
+	assert.NotContains(t, upgrade, rename{"messaging.destination", "messaging.destination.name"})
+	assert.NotContains(t, upgrade, rename{"http.resend_count", "http.request.resend_count"})
+
+	downgrade := renamesBetween(version{1, 21, 0}, version{1, 19, 0})
+	assert.Len(t, downgrade, len(upgrade))
+	assert.Contains(t, downgrade, rename{"http.request.method", "http.method"})
+	// Renames chained across versions are applied in order in both directions.
+	assert.Equal(t, []rename{
+		{"messaging.destination", "messaging.destination.name"},
+		{"messaging.protocol", "net.app.protocol.name"},
+		{"net.app.protocol.name", "net.protocol.name"},
+		{"net.protocol.name", "network.protocol.name"},
+	}, filter(renamesBetween(version{1, 16, 0}, version{1, 26, 0}), "messaging.destination", "messaging.protocol", "net.app.protocol.name", "net.protocol.name"))
+	assert.Equal(t, []rename{
+		{"network.protocol.name", "net.protocol.name"},
+		{"net.protocol.name", "net.app.protocol.name"},
+		{"net.app.protocol.name", "messaging.protocol"},
+		{"messaging.destination.name", "messaging.destination"},
+	}, filter(renamesBetween(version{1, 26, 0}, version{1, 16, 0}), "messaging.destination.name", "network.protocol.name", "net.protocol.name", "net.app.protocol.name"))
+}
+
+// filter returns the renames from the given attributes.
+func filter(renames []rename, from ...string) []rename {
+	var filtered []rename
+	for _, r := range renames {
+		for _, f := range from {
+			if r.from == f {
+				filtered = append(filtered, r)
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,187 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizebatchprocessor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// signal are the operations the batcher needs on the data of a signal.
+type signal[T any] struct {
+	newData func() T
+	// items returns the number of datapoints, spans or log records of the data.
+	items func(T) int
+	// size returns the size of the data in the encoding of the sizer.
+	size func(sizer string, data T) int
+	// split removes the first n items from the data and returns them.
+	split func(data T, n int) T
+	// moveTo moves the data to the end of dest.
+	moveTo func(data, dest T)
+}
+
+// batcher accumulates data into batches of at most the maximum byte size and number of items,
+// splitting the data that doesn't fit. The byte size of a batch is the sum of the byte sizes of
+// the data it's made of, which is exact for the protobuf encoding since the data is appended at
+// the resource level, and slightly overestimates the JSON encoding.
+type batcher[T any] struct {
+	signal  signal[T]
+	consume func(context.Context, T) error
+	logger  *zap.Logger
+	limits  Config
+
+	mu      sync.Mutex
+	pending T
+	items   int
+	bytes   int
+	// generation identifies the pending batch, so the timer of a sent batch doesn't send the next.
+	generation uint64
+	timer      *time.Timer
+	closed     bool
+}
+
+func newBatcher[T any](limits Config, s signal[T], consume func(context.Context, T) error, logger *zap.Logger) *batcher[T] {
+	return &batcher[T]{
+		signal:  s,
+		consume: consume,
+		logger:  logger,
+		limits:  limits,
+		pending: s.newData(),
+	}
+}
+
+// add adds the data to the pending batch, sending the batches that are full. Batches are sent
+// synchronously, and the errors of sending them are logged since they may contain the data of
+// other requests.
+func (b *batcher[T]) add(ctx context.Context, data T) {
+	b.chunks(data, func(chunk T, items, bytes int) {
+		var full []T
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			b.send(ctx, chunk)
+			return
+		}
+		if b.items > 0 && b.exceeds(b.items+items, b.bytes+bytes) {
+			full = append(full, b.take())
+		}
+		b.signal.moveTo(chunk, b.pending)
+		b.items += items
+		b.bytes += bytes
+		if b.full() {
+			full = append(full, b.take())
+		} else if b.timer == nil {
+			generation := b.generation
+			b.timer = time.AfterFunc(b.limits.Timeout, func() { b.timeout(generation) })
+		}
+		b.mu.Unlock()
+		for _, batch := range full {
+			b.send(ctx, batch)
+		}
+	})
+}
+
+// chunks calls fn with the parts of the data that fit in a batch, with their number of items
+// and byte size. Parts of a single item that don't fit are sent on their own.
+func (b *batcher[T]) chunks(data T, fn func(chunk T, items, bytes int)) {
+	items := b.signal.items(data)
+	var bytes int
+	if b.limits.MaxBytes > 0 {
+		bytes = b.signal.size(b.limits.Sizer, data)
+	}
+	if items <= 1 || !b.exceeds(items, bytes) {
+		fn(data, items, bytes)
+		return
+	}
+	n := items / 2
+	switch {
+	case b.limits.MaxItems > 0 && items > b.limits.MaxItems:
+		n = b.limits.MaxItems
+	case b.limits.MaxBytes > 0 && bytes > b.limits.MaxBytes:
+		// split where the items that fit would end if they were the same size
+		n = int(int64(items) * int64(b.limits.MaxBytes) / int64(bytes))
+	}
+	n = min(max(n, 1), items-1)
+	b.chunks(b.signal.split(data, n), fn)
+	b.chunks(data, fn)
+}
+
+func (b *batcher[T]) exceeds(items, bytes int) bool {
+	return (b.limits.MaxItems > 0 && items > b.limits.MaxItems) || (b.limits.MaxBytes > 0 && bytes > b.limits.MaxBytes)
+}
+
+// full returns whether no item can be added to the pending batch. It must be called with the
+// lock held.
+func (b *batcher[T]) full() bool {
+	return b.items > 0 && ((b.limits.MaxItems > 0 && b.items >= b.limits.MaxItems) || (b.limits.MaxBytes > 0 && b.bytes >= b.limits.MaxBytes))
+}
+
+// take returns the pending batch, replacing it with an empty one. It must be called with the lock
+// held.
+func (b *batcher[T]) take() T {
+	full := b.pending
+	b.pending = b.signal.newData()
+	b.items, b.bytes = 0, 0
+	b.generation++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return full
+}
+
+// timeout sends the pending batch if it's still the batch of the generation.
+func (b *batcher[T]) timeout(generation uint64) {
+	b.mu.Lock()
+	if b.closed || b.generation != generation || b.items == 0 {
+		b.mu.Unlock()
+		return
+	}
+	full := b.take()
+	b.mu.Unlock()
+	b.send(context.Background(), full)
+}
+
+func (b *batcher[T]) send(ctx context.Context, data T) {
+	if err := b.consume(ctx, data); err != nil {
+		b.logger.Warn("Failed to send batch", zap.Error(err), zap.Int("items", b.signal.items(data)))
+	}
+}
+
+// shutdown sends the pending batch. The data added afterward is sent without batching.
+func (b *batcher[T]) shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	var full T
+	hasFull := b.items > 0
+	if hasFull {
+		full = b.take()
+	} else if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+	if hasFull {
+		return b.consume(ctx, full)
+	}
+	return nil
+}
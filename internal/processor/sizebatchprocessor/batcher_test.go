@@ -0,0 +1,137 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizebatchprocessor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type sink struct {
+	mu      sync.Mutex
+	batches []plog.Logs
+	err     error
+}
+
+func (s *sink) consume(_ context.Context, ld plog.Logs) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, ld)
+	return s.err
+}
+
+func (s *sink) counts() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make([]int, 0, len(s.batches))
+	for _, ld := range s.batches {
+		counts = append(counts, ld.LogRecordCount())
+	}
+	return counts
+}
+
+func newLogs(records int, body string) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for i := 0; i < records; i++ {
+		lrs.AppendEmpty().Body().SetStr(body)
+	}
+	return ld
+}
+
+func TestBatcherMaxItems(t *testing.T) {
+	s := &sink{}
+	b := newBatcher(Config{Sizer: sizerProto, MaxItems: 10, Timeout: time.Hour}, logsSignal, s.consume, zap.NewNop())
+
+	b.add(context.Background(), newLogs(4, "a"))
+	b.add(context.Background(), newLogs(4, "a"))
+	assert.Empty(t, s.counts())
+	// the records that don't fit go to the next batch
+	b.add(context.Background(), newLogs(25, "a"))
+	assert.Equal(t, []int{8, 10, 10}, s.counts())
+	require.NoError(t, b.shutdown(context.Background()))
+	assert.Equal(t, []int{8, 10, 10, 5}, s.counts())
+}
+
+func TestBatcherMaxBytes(t *testing.T) {
+	s := &sink{}
+	recordSize := logsSignal.size(sizerJSON, newLogs(2, "0123456789")) - logsSignal.size(sizerJSON, newLogs(1, "0123456789"))
+	maxBytes := logsSignal.size(sizerJSON, newLogs(10, "0123456789"))
+	b := newBatcher(Config{Sizer: sizerJSON, MaxBytes: maxBytes, Timeout: time.Hour}, logsSignal, s.consume, zap.NewNop())
+
+	b.add(context.Background(), newLogs(35, "0123456789"))
+	require.NoError(t, b.shutdown(context.Background()))
+	var total int
+	for _, batch := range s.batches {
+		assert.LessOrEqual(t, logsSignal.size(sizerJSON, batch), maxBytes+recordSize)
+		total += batch.LogRecordCount()
+	}
+	assert.Equal(t, 35, total)
+	assert.Greater(t, len(s.batches), 3)
+}
+
+func TestBatcherItemLargerThanMaxBytes(t *testing.T) {
+	s := &sink{}
+	b := newBatcher(Config{Sizer: sizerProto, MaxBytes: 10, Timeout: time.Hour}, logsSignal, s.consume, zap.NewNop())
+
+	b.add(context.Background(), newLogs(3, "a body larger than the limit"))
+	require.NoError(t, b.shutdown(context.Background()))
+	assert.Equal(t, []int{1, 1, 1}, s.counts())
+}
+
+func TestBatcherTimeout(t *testing.T) {
+	s := &sink{}
+	b := newBatcher(Config{Sizer: sizerProto, MaxItems: 10, Timeout: 10 * time.Millisecond}, logsSignal, s.consume, zap.NewNop())
+
+	b.add(context.Background(), newLogs(3, "a"))
+	require.Eventually(t, func() bool {
+		return len(s.counts()) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []int{3}, s.counts())
+
+	// the timer of a sent batch doesn't send the next one early
+	b.add(context.Background(), newLogs(10, "a"))
+	b.add(context.Background(), newLogs(1, "a"))
+	assert.Equal(t, []int{3, 10}, s.counts())
+	require.Eventually(t, func() bool {
+		return len(s.counts()) == 3
+	}, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, b.shutdown(context.Background()))
+	assert.Equal(t, []int{3, 10, 1}, s.counts())
+}
+
+func TestBatcherShutdown(t *testing.T) {
+	s := &sink{err: errors.New("connection refused")}
+	core, logs := observer.New(zap.WarnLevel)
+	b := newBatcher(Config{Sizer: sizerProto, MaxItems: 2, Timeout: time.Hour}, logsSignal, s.consume, zap.New(core))
+
+	b.add(context.Background(), newLogs(3, "a"))
+	require.Equal(t, 1, logs.FilterMessage("Failed to send batch").Len())
+	require.EqualError(t, b.shutdown(context.Background()), "connection refused")
+	require.NoError(t, b.shutdown(context.Background()))
+
+	// data added after shutdown is sent directly
+	b.add(context.Background(), newLogs(1, "a"))
+	assert.Equal(t, []int{2, 1, 1}, s.counts())
+}
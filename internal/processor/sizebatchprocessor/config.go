@@ -0,0 +1,101 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizebatchprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+const (
+	destinationSplunkHEC = "splunk_hec"
+	destinationSignalFx  = "signalfx"
+	destinationOTLP      = "otlp"
+
+	sizerProto = "proto"
+	sizerJSON  = "json"
+)
+
+// Config defines the limits of the batches sent to the destination.
+type Config struct {
+	// Destination presets the sizer and limits of the batches for the destination: splunk_hec,
+	// signalfx or otlp. The settings configured explicitly override the preset.
+	Destination string `mapstructure:"destination"`
+	// Sizer is the encoding the byte size of batches is measured in: proto, the size of the OTLP
+	// protobuf encoding, or json, the size of the OTLP JSON encoding.
+	Sizer string `mapstructure:"sizer"`
+	// MaxBytes is the maximum byte size of a batch, 0 for no limit.
+	MaxBytes int `mapstructure:"max_bytes"`
+	// MaxItems is the maximum number of datapoints, spans or log records of a batch, 0 for no limit.
+	MaxItems int `mapstructure:"max_items"`
+	// Timeout is the time after which a batch is sent regardless of its size.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// presets are the sizers and limits of the destinations: the default maximum content length of
+// Splunk HEC endpoints, the maximum number of datapoints of SignalFx ingest requests, and the
+// default maximum message size of OTLP gRPC receivers.
+var presets = map[string]Config{
+	destinationSplunkHEC: {Sizer: sizerJSON, MaxBytes: 1_000_000},
+	destinationSignalFx:  {Sizer: sizerProto, MaxItems: 20_000},
+	destinationOTLP:      {Sizer: sizerProto, MaxBytes: 4 << 20},
+}
+
+// limits returns the config with the unset settings set to the ones of the destination preset.
+func (cfg *Config) limits() Config {
+	limits := *cfg
+	preset := presets[cfg.Destination]
+	if limits.Sizer == "" {
+		limits.Sizer = preset.Sizer
+	}
+	if limits.Sizer == "" {
+		limits.Sizer = sizerProto
+	}
+	if limits.MaxBytes == 0 {
+		limits.MaxBytes = preset.MaxBytes
+	}
+	if limits.MaxItems == 0 {
+		limits.MaxItems = preset.MaxItems
+	}
+	return limits
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if _, ok := presets[cfg.Destination]; cfg.Destination != "" && !ok {
+		errs = fmt.Errorf("unsupported destination %q, must be splunk_hec, signalfx or otlp", cfg.Destination)
+	}
+	if cfg.Sizer != "" && cfg.Sizer != sizerProto && cfg.Sizer != sizerJSON {
+		errs = errors.Join(errs, fmt.Errorf("unsupported sizer %q, must be proto or json", cfg.Sizer))
+	}
+	if cfg.MaxBytes < 0 {
+		errs = errors.Join(errs, errors.New("max_bytes must not be negative"))
+	}
+	if cfg.MaxItems < 0 {
+		errs = errors.Join(errs, errors.New("max_items must not be negative"))
+	}
+	if limits := cfg.limits(); limits.MaxBytes <= 0 && limits.MaxItems <= 0 {
+		errs = errors.Join(errs, errors.New("either destination, max_bytes or max_items must be set"))
+	}
+	if cfg.Timeout <= 0 {
+		errs = errors.Join(errs, errors.New("timeout must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizebatchprocessor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id:       component.MustNewID(typeStr),
+			expected: createDefaultConfig().(*Config),
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				Destination: "splunk_hec",
+				Sizer:       "proto",
+				MaxBytes:    500000,
+				MaxItems:    1000,
+				Timeout:     time.Second,
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "unsupported destination \"kafka\", must be splunk_hec, signalfx or otlp\n" +
+				"unsupported sizer \"xml\", must be proto or json\n" +
+				"max_bytes must not be negative\n" +
+				"max_items must not be negative\n" +
+				"either destination, max_bytes or max_items must be set\n" +
+				"timeout must be positive",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		cfg      Config
+		expected Config
+	}{
+		{
+			name:     "splunk_hec",
+			cfg:      Config{Destination: "splunk_hec"},
+			expected: Config{Destination: "splunk_hec", Sizer: "json", MaxBytes: 1_000_000},
+		},
+		{
+			name:     "signalfx",
+			cfg:      Config{Destination: "signalfx"},
+			expected: Config{Destination: "signalfx", Sizer: "proto", MaxItems: 20_000},
+		},
+		{
+			name:     "otlp",
+			cfg:      Config{Destination: "otlp"},
+			expected: Config{Destination: "otlp", Sizer: "proto", MaxBytes: 4 << 20},
+		},
+		{
+			name:     "overridden preset",
+			cfg:      Config{Destination: "signalfx", MaxItems: 5000, MaxBytes: 1 << 20},
+			expected: Config{Destination: "signalfx", Sizer: "proto", MaxItems: 5000, MaxBytes: 1 << 20},
+		},
+		{
+			name:     "no destination",
+			cfg:      Config{MaxItems: 100},
+			expected: Config{Sizer: "proto", MaxItems: 100},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cfg.limits())
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizebatchprocessor
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+const (
+	typeStr   = "size_batch"
+	stability = component.StabilityLevelDevelopment
+)
+
+var processorCapabilities = consumer.Capabilities{MutatesData: true}
+
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		processor.WithLogs(createLogsProcessor, stability),
+		processor.WithMetrics(createMetricsProcessor, stability),
+		processor.WithTraces(createTracesProcessor, stability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{Destination: destinationOTLP, Timeout: 200 * time.Millisecond}
+}
+
+func createLogsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (processor.Logs, error) {
+	b := newBatcher(cfg.(*Config).limits(), logsSignal, nextConsumer.ConsumeLogs, set.Logger)
+	return processorhelper.NewLogs(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		func(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+			b.add(ctx, ld)
+			return ld, processorhelper.ErrSkipProcessingData
+		},
+		processorhelper.WithShutdown(b.shutdown),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
+
+func createMetricsProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (processor.Metrics, error) {
+	b := newBatcher(cfg.(*Config).limits(), metricsSignal, nextConsumer.ConsumeMetrics, set.Logger)
+	return processorhelper.NewMetrics(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		func(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+			b.add(ctx, md)
+			return md, processorhelper.ErrSkipProcessingData
+		},
+		processorhelper.WithShutdown(b.shutdown),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
+
+func createTracesProcessor(
+	ctx context.Context,
+	set processor.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (processor.Traces, error) {
+	b := newBatcher(cfg.(*Config).limits(), tracesSignal, nextConsumer.ConsumeTraces, set.Logger)
+	return processorhelper.NewTraces(
+		ctx,
+		set,
+		cfg,
+		nextConsumer,
+		func(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+			b.add(ctx, td)
+			return td, processorhelper.ErrSkipProcessingData
+		},
+		processorhelper.WithShutdown(b.shutdown),
+		processorhelper.WithCapabilities(processorCapabilities))
+}
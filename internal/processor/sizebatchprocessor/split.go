@@ -0,0 +1,287 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizebatchprocessor
+
+import (
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var logsSignal = signal[plog.Logs]{
+	newData: plog.NewLogs,
+	items:   plog.Logs.LogRecordCount,
+	size: func(sizer string, ld plog.Logs) int {
+		if sizer == sizerJSON {
+			b, _ := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+			return len(b)
+		}
+		return (&plog.ProtoMarshaler{}).LogsSize(ld)
+	},
+	split: splitLogs,
+	moveTo: func(ld, dest plog.Logs) {
+		ld.ResourceLogs().MoveAndAppendTo(dest.ResourceLogs())
+	},
+}
+
+var metricsSignal = signal[pmetric.Metrics]{
+	newData: pmetric.NewMetrics,
+	items:   pmetric.Metrics.DataPointCount,
+	size: func(sizer string, md pmetric.Metrics) int {
+		if sizer == sizerJSON {
+			b, _ := (&pmetric.JSONMarshaler{}).MarshalMetrics(md)
+			return len(b)
+		}
+		return (&pmetric.ProtoMarshaler{}).MetricsSize(md)
+	},
+	split: splitMetrics,
+	moveTo: func(md, dest pmetric.Metrics) {
+		md.ResourceMetrics().MoveAndAppendTo(dest.ResourceMetrics())
+	},
+}
+
+var tracesSignal = signal[ptrace.Traces]{
+	newData: ptrace.NewTraces,
+	items:   ptrace.Traces.SpanCount,
+	size: func(sizer string, td ptrace.Traces) int {
+		if sizer == sizerJSON {
+			b, _ := (&ptrace.JSONMarshaler{}).MarshalTraces(td)
+			return len(b)
+		}
+		return (&ptrace.ProtoMarshaler{}).TracesSize(td)
+	},
+	split: splitTraces,
+	moveTo: func(td, dest ptrace.Traces) {
+		td.ResourceSpans().MoveAndAppendTo(dest.ResourceSpans())
+	},
+}
+
+// pdataSlice is a slice of pdata elements.
+type pdataSlice[E any] interface {
+	Len() int
+	At(int) E
+	AppendEmpty() E
+	RemoveIf(func(E) bool)
+}
+
+// moveFirst moves the first n elements of src to the end of dest.
+func moveFirst[E interface{ MoveTo(E) }, S pdataSlice[E]](src, dest S, n int) {
+	for i := 0; i < n; i++ {
+		src.At(i).MoveTo(dest.AppendEmpty())
+	}
+	var i int
+	src.RemoveIf(func(E) bool {
+		i++
+		return i <= n
+	})
+}
+
+// splitLogs removes the first n log records from the logs and returns them, with copies of the
+// resource and scope of the records of the resource and scope split in two.
+func splitLogs(ld plog.Logs, n int) plog.Logs {
+	dest := plog.NewLogs()
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		if n == 0 {
+			return false
+		}
+		if count := resourceLogRecordCount(rl); count <= n {
+			n -= count
+			rl.MoveTo(dest.ResourceLogs().AppendEmpty())
+			return true
+		}
+		destRl := dest.ResourceLogs().AppendEmpty()
+		rl.Resource().CopyTo(destRl.Resource())
+		destRl.SetSchemaUrl(rl.SchemaUrl())
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			if n == 0 {
+				return false
+			}
+			if count := sl.LogRecords().Len(); count <= n {
+				n -= count
+				sl.MoveTo(destRl.ScopeLogs().AppendEmpty())
+				return true
+			}
+			destSl := destRl.ScopeLogs().AppendEmpty()
+			sl.Scope().CopyTo(destSl.Scope())
+			destSl.SetSchemaUrl(sl.SchemaUrl())
+			moveFirst[plog.LogRecord](sl.LogRecords(), destSl.LogRecords(), n)
+			n = 0
+			return false
+		})
+		return false
+	})
+	return dest
+}
+
+func resourceLogRecordCount(rl plog.ResourceLogs) int {
+	var count int
+	for i := 0; i < rl.ScopeLogs().Len(); i++ {
+		count += rl.ScopeLogs().At(i).LogRecords().Len()
+	}
+	return count
+}
+
+// splitTraces removes the first n spans from the traces and returns them, with copies of the
+// resource and scope of the spans of the resource and scope split in two.
+func splitTraces(td ptrace.Traces, n int) ptrace.Traces {
+	dest := ptrace.NewTraces()
+	td.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		if n == 0 {
+			return false
+		}
+		if count := resourceSpanCount(rs); count <= n {
+			n -= count
+			rs.MoveTo(dest.ResourceSpans().AppendEmpty())
+			return true
+		}
+		destRs := dest.ResourceSpans().AppendEmpty()
+		rs.Resource().CopyTo(destRs.Resource())
+		destRs.SetSchemaUrl(rs.SchemaUrl())
+		rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			if n == 0 {
+				return false
+			}
+			if count := ss.Spans().Len(); count <= n {
+				n -= count
+				ss.MoveTo(destRs.ScopeSpans().AppendEmpty())
+				return true
+			}
+			destSs := destRs.ScopeSpans().AppendEmpty()
+			ss.Scope().CopyTo(destSs.Scope())
+			destSs.SetSchemaUrl(ss.SchemaUrl())
+			moveFirst[ptrace.Span](ss.Spans(), destSs.Spans(), n)
+			n = 0
+			return false
+		})
+		return false
+	})
+	return dest
+}
+
+func resourceSpanCount(rs ptrace.ResourceSpans) int {
+	var count int
+	for i := 0; i < rs.ScopeSpans().Len(); i++ {
+		count += rs.ScopeSpans().At(i).Spans().Len()
+	}
+	return count
+}
+
+// splitMetrics removes the first n datapoints from the metrics and returns them, with copies of
+// the resource, scope and metric of the datapoints of the resource, scope and metric split in two.
+func splitMetrics(md pmetric.Metrics, n int) pmetric.Metrics {
+	dest := pmetric.NewMetrics()
+	md.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		if n == 0 {
+			return false
+		}
+		if count := resourceDataPointCount(rm); count <= n {
+			n -= count
+			rm.MoveTo(dest.ResourceMetrics().AppendEmpty())
+			return true
+		}
+		destRm := dest.ResourceMetrics().AppendEmpty()
+		rm.Resource().CopyTo(destRm.Resource())
+		destRm.SetSchemaUrl(rm.SchemaUrl())
+		rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			if n == 0 {
+				return false
+			}
+			if count := scopeDataPointCount(sm); count <= n {
+				n -= count
+				sm.MoveTo(destRm.ScopeMetrics().AppendEmpty())
+				return true
+			}
+			destSm := destRm.ScopeMetrics().AppendEmpty()
+			sm.Scope().CopyTo(destSm.Scope())
+			destSm.SetSchemaUrl(sm.SchemaUrl())
+			sm.Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				if n == 0 {
+					return false
+				}
+				if count := dataPointCount(m); count <= n {
+					n -= count
+					m.MoveTo(destSm.Metrics().AppendEmpty())
+					return true
+				}
+				moveFirstDataPoints(m, destSm.Metrics().AppendEmpty(), n)
+				n = 0
+				return false
+			})
+			return false
+		})
+		return false
+	})
+	return dest
+}
+
+func resourceDataPointCount(rm pmetric.ResourceMetrics) int {
+	var count int
+	for i := 0; i < rm.ScopeMetrics().Len(); i++ {
+		count += scopeDataPointCount(rm.ScopeMetrics().At(i))
+	}
+	return count
+}
+
+func scopeDataPointCount(sm pmetric.ScopeMetrics) int {
+	var count int
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		count += dataPointCount(sm.Metrics().At(i))
+	}
+	return count
+}
+
+func dataPointCount(m pmetric.Metric) int {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return m.Gauge().DataPoints().Len()
+	case pmetric.MetricTypeSum:
+		return m.Sum().DataPoints().Len()
+	case pmetric.MetricTypeHistogram:
+		return m.Histogram().DataPoints().Len()
+	case pmetric.MetricTypeExponentialHistogram:
+		return m.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricTypeSummary:
+		return m.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+// moveFirstDataPoints moves the first n datapoints of the metric to dest, which gets a copy of
+// the metric without datapoints.
+func moveFirstDataPoints(m, dest pmetric.Metric, n int) {
+	dest.SetName(m.Name())
+	dest.SetDescription(m.Description())
+	dest.SetUnit(m.Unit())
+	m.Metadata().CopyTo(dest.Metadata())
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		moveFirst[pmetric.NumberDataPoint](m.Gauge().DataPoints(), dest.SetEmptyGauge().DataPoints(), n)
+	case pmetric.MetricTypeSum:
+		sum := dest.SetEmptySum()
+		sum.SetAggregationTemporality(m.Sum().AggregationTemporality())
+		sum.SetIsMonotonic(m.Sum().IsMonotonic())
+		moveFirst[pmetric.NumberDataPoint](m.Sum().DataPoints(), sum.DataPoints(), n)
+	case pmetric.MetricTypeHistogram:
+		histogram := dest.SetEmptyHistogram()
+		histogram.SetAggregationTemporality(m.Histogram().AggregationTemporality())
+		moveFirst[pmetric.HistogramDataPoint](m.Histogram().DataPoints(), histogram.DataPoints(), n)
+	case pmetric.MetricTypeExponentialHistogram:
+		histogram := dest.SetEmptyExponentialHistogram()
+		histogram.SetAggregationTemporality(m.ExponentialHistogram().AggregationTemporality())
+		moveFirst[pmetric.ExponentialHistogramDataPoint](m.ExponentialHistogram().DataPoints(), histogram.DataPoints(), n)
+	case pmetric.MetricTypeSummary:
+		moveFirst[pmetric.SummaryDataPoint](m.Summary().DataPoints(), dest.SetEmptySummary().DataPoints(), n)
+	}
+}
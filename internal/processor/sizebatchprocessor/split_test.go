@@ -0,0 +1,107 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sizebatchprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestSplitLogs(t *testing.T) {
+	ld := newLogs(3, "a")
+	rl := ld.ResourceLogs().At(0)
+	rl.Resource().Attributes().PutStr("host.name", "host")
+	rl.SetSchemaUrl("https://opentelemetry.io/schemas/1.26.0")
+	rl.ScopeLogs().At(0).Scope().SetName("scope")
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+
+	first := splitLogs(ld, 2)
+	assert.Equal(t, 2, first.LogRecordCount())
+	assert.Equal(t, 2, ld.LogRecordCount())
+	require.Equal(t, 1, first.ResourceLogs().Len())
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.26.0", first.ResourceLogs().At(0).SchemaUrl())
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.26.0", ld.ResourceLogs().At(0).SchemaUrl())
+	assert.Equal(t, map[string]any{"host.name": "host"}, first.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	assert.Equal(t, "scope", first.ResourceLogs().At(0).ScopeLogs().At(0).Scope().Name())
+	assert.Equal(t, "scope", ld.ResourceLogs().At(0).ScopeLogs().At(0).Scope().Name())
+
+	rest := splitLogs(ld, 2)
+	assert.Equal(t, 2, rest.LogRecordCount())
+	assert.Equal(t, 2, rest.ResourceLogs().Len())
+	assert.Equal(t, 0, ld.ResourceLogs().Len())
+}
+
+func TestSplitTraces(t *testing.T) {
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+	ss.Scope().SetName("scope")
+	for _, name := range []string{"a", "b", "c"} {
+		ss.Spans().AppendEmpty().SetName(name)
+	}
+
+	first := splitTraces(td, 1)
+	require.Equal(t, 1, first.SpanCount())
+	assert.Equal(t, "a", first.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+	assert.Equal(t, "scope", first.ResourceSpans().At(0).ScopeSpans().At(0).Scope().Name())
+	require.Equal(t, 2, td.SpanCount())
+	assert.Equal(t, "b", td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+}
+
+func TestSplitMetrics(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	sum := metrics.AppendEmpty()
+	sum.SetName("requests")
+	sum.SetUnit("1")
+	sum.SetEmptySum().SetIsMonotonic(true)
+	sum.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	for i := 0; i < 3; i++ {
+		sum.Sum().DataPoints().AppendEmpty().SetIntValue(int64(i))
+	}
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("latency")
+	histogram.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	histogram.Histogram().DataPoints().AppendEmpty().SetCount(1)
+	histogram.Histogram().DataPoints().AppendEmpty().SetCount(2)
+	metrics.AppendEmpty().SetEmptySummary().DataPoints().AppendEmpty()
+
+	first := splitMetrics(md, 2)
+	require.Equal(t, 2, first.DataPointCount())
+	m := first.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "requests", m.Name())
+	assert.Equal(t, "1", m.Unit())
+	assert.True(t, m.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, m.Sum().AggregationTemporality())
+	assert.Equal(t, int64(1), m.Sum().DataPoints().At(1).IntValue())
+
+	second := splitMetrics(md, 2)
+	require.Equal(t, 2, second.DataPointCount())
+	secondMetrics := second.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, secondMetrics.Len())
+	assert.Equal(t, int64(2), secondMetrics.At(0).Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, "latency", secondMetrics.At(1).Name())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, secondMetrics.At(1).Histogram().AggregationTemporality())
+	assert.Equal(t, uint64(1), secondMetrics.At(1).Histogram().DataPoints().At(0).Count())
+
+	require.Equal(t, 2, md.DataPointCount())
+	remaining := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, remaining.Len())
+	assert.Equal(t, uint64(2), remaining.At(0).Histogram().DataPoints().At(0).Count())
+	assert.Equal(t, pmetric.MetricTypeSummary, remaining.At(1).Type())
+}
@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wineventlogprocessor
+
+import (
+	"errors"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.uber.org/zap"
+)
+
+var errUnsupported = errors.New("rendering messages is only supported on Windows")
+
+// messageKey identifies the message template of an event.
+type messageKey struct {
+	provider   string
+	eventID    uint32
+	qualifiers uint32
+	version    uint32
+}
+
+// messageSource looks up the message templates of events from the metadata of their publishers.
+type messageSource interface {
+	// template returns the message template of the event, with %1 style inserts of its data.
+	template(key messageKey) (string, error)
+	close() error
+}
+
+// messageCache caches the message templates of events, so the expensive lookup from the publisher
+// metadata happens once per publisher, event and version instead of once per event. Failed lookups
+// are cached too, since they're as expensive and fail the same way until the publisher is updated.
+type messageCache struct {
+	source    messageSource
+	templates *lru.Cache[messageKey, string]
+	logger    *zap.Logger
+}
+
+func newMessageCache(source messageSource, size int, logger *zap.Logger) (*messageCache, error) {
+	templates, err := lru.New[messageKey, string](size)
+	if err != nil {
+		return nil, err
+	}
+	return &messageCache{source: source, templates: templates, logger: logger}, nil
+}
+
+// message returns the message of the event rendered with its data values, if its template is known.
+func (c *messageCache) message(key messageKey, values []string) (string, bool) {
+	template, ok := c.templates.Get(key)
+	if !ok {
+		var err error
+		if template, err = c.source.template(key); err != nil {
+			c.logger.Debug("Failed to look up the message template of an event",
+				zap.String("provider", key.provider), zap.Uint32("event_id", key.eventID), zap.Error(err))
+		}
+		c.templates.Add(key, template)
+	}
+	if template == "" {
+		return "", false
+	}
+	return formatMessage(template, values), true
+}
+
+func (c *messageCache) close() error {
+	c.templates.Purge()
+	return c.source.close()
+}
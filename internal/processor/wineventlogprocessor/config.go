@@ -0,0 +1,77 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wineventlogprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// channelPlaceholder is replaced with the channel of an event in sourcetypes.
+const channelPlaceholder = "{channel}"
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the rendering of event messages and the mapping of events to Splunk sourcetypes.
+type Config struct {
+	// RenderMessages renders the messages of events the windowseventlog receiver didn't render,
+	// i.e. with suppress_rendering_info, from the message templates of their publishers.
+	RenderMessages bool `mapstructure:"render_messages"`
+	// MaxCachedMessages is the number of message templates kept in memory. The least recently
+	// used templates are evicted first.
+	MaxCachedMessages int `mapstructure:"max_cached_messages"`
+	// DefaultSourcetype is the sourcetype of events matching none of the Sourcetypes.
+	DefaultSourcetype string `mapstructure:"default_sourcetype"`
+	// Sourcetypes map events to sourcetypes by their channel and level. The first matching
+	// mapping applies.
+	Sourcetypes []SourcetypeConfig `mapstructure:"sourcetypes"`
+}
+
+// SourcetypeConfig maps events to a sourcetype.
+type SourcetypeConfig struct {
+	// Channel is the channel of the events, e.g. Security. Any channel matches if empty.
+	Channel string `mapstructure:"channel"`
+	// Levels are the levels of the events, e.g. Error. Any level matches if empty.
+	Levels []string `mapstructure:"levels"`
+	// Sourcetype is the sourcetype of the events, e.g. WinEventLog:{channel}.
+	Sourcetype string `mapstructure:"sourcetype"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.RenderMessages && cfg.MaxCachedMessages <= 0 {
+		errs = errors.Join(errs, errors.New("max_cached_messages must be positive"))
+	}
+	if cfg.DefaultSourcetype == "" {
+		errs = errors.Join(errs, errors.New("default_sourcetype must not be empty"))
+	}
+	for i, st := range cfg.Sourcetypes {
+		if st.Sourcetype == "" {
+			errs = errors.Join(errs, fmt.Errorf("sourcetypes[%d]: sourcetype must not be empty", i))
+		}
+		if st.Channel == "" && len(st.Levels) == 0 {
+			errs = errors.Join(errs, fmt.Errorf("sourcetypes[%d]: one of channel or levels must be set", i))
+		}
+		for _, level := range st.Levels {
+			if !isLevelName(level) {
+				errs = errors.Join(errs, fmt.Errorf("sourcetypes[%d]: level %q must be one of %s", i, level, strings.Join(levelNames[1:], ", ")))
+			}
+		}
+	}
+	return errs
+}
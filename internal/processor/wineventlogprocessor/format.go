@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wineventlogprocessor
+
+import (
+	"strconv"
+	"strings"
+)
+
+// levelNames are the names of the standard event levels by their value. Events logged with
+// level 0 (LogAlways) are shown as Information by the Event Viewer.
+var levelNames = []string{"Information", "Critical", "Error", "Warning", "Information", "Verbose"}
+
+func isLevelName(name string) bool {
+	for _, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// levelName returns the name of a standard event level, or the level itself if it isn't one,
+// e.g. because it's already rendered or defined by the publisher.
+func levelName(level string) string {
+	if n, err := strconv.Atoi(level); err == nil && n >= 0 && n < len(levelNames) {
+		return levelNames[n]
+	}
+	return level
+}
+
+// formatMessage inserts the event data values into a message template as FormatMessage does,
+// e.g. "%1 logged on%n" with ["alice"] becomes "alice logged on\r\n". Inserts without a value,
+// like the %%1234 parameter inserts resolved from the parameter messages of publishers, are kept.
+func formatMessage(template string, values []string) string {
+	var b strings.Builder
+	b.Grow(len(template))
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '%' || i+1 == len(template) {
+			b.WriteByte(c)
+			continue
+		}
+		next := template[i+1]
+		switch {
+		case next >= '1' && next <= '9':
+			end := i + 2
+			if end < len(template) && template[end] >= '0' && template[end] <= '9' {
+				end++
+			}
+			n, _ := strconv.Atoi(template[i+1 : end])
+			// skip the printf format of the insert, e.g. !s! in %1!s!
+			if end < len(template) && template[end] == '!' {
+				if spec := strings.IndexByte(template[end+1:], '!'); spec >= 0 {
+					end += spec + 2
+				}
+			}
+			if n <= len(values) {
+				b.WriteString(values[n-1])
+			} else {
+				b.WriteString(template[i:end])
+			}
+			i = end - 1
+		case next == '0':
+			return b.String()
+		case next == '%' && i+2 < len(template) && template[i+2] >= '0' && template[i+2] <= '9':
+			b.WriteString("%%")
+			i++
+		default:
+			if s, ok := escapes[next]; ok {
+				b.WriteString(s)
+			} else {
+				b.WriteByte(c)
+				b.WriteByte(next)
+			}
+			i++
+		}
+	}
+	return b.String()
+}
+
+// escapes are the escape sequences of message templates by the character following the %.
+var escapes = map[byte]string{
+	'n': "\r\n",
+	'r': "\r",
+	't': "\t",
+	'b': " ",
+	'.': ".",
+	'!': "!",
+	'%': "%",
+}
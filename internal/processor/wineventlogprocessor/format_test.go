@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wineventlogprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMessage(t *testing.T) {
+	for _, tt := range []struct {
+		template string
+		values   []string
+		expected string
+	}{
+		{template: "no inserts", expected: "no inserts"},
+		{template: "%1 logged on%n", values: []string{"alice"}, expected: "alice logged on\r\n"},
+		{template: "%2 before %1", values: []string{"a", "b"}, expected: "b before a"},
+		{template: "%10.", values: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, expected: "10."},
+		{template: "%1!s! at %2!d!", values: []string{"x", "5"}, expected: "x at 5"},
+		{template: "missing %3", values: []string{"a"}, expected: "missing %3"},
+		{template: "%t%b%.%!%r%%", expected: "\t .!\r%"},
+		{template: "parameter %%1833", expected: "parameter %%1833"},
+		{template: "ends with %", expected: "ends with %"},
+		{template: "terminated%0ignored", expected: "terminated"},
+		{template: "unknown %z", expected: "unknown %z"},
+	} {
+		assert.Equal(t, tt.expected, formatMessage(tt.template, tt.values), tt.template)
+	}
+}
+
+func TestLevelName(t *testing.T) {
+	assert.Equal(t, "Information", levelName("0"))
+	assert.Equal(t, "Critical", levelName("1"))
+	assert.Equal(t, "Verbose", levelName("5"))
+	assert.Equal(t, "16", levelName("16"))
+	assert.Equal(t, "Warning", levelName("Warning"))
+	assert.True(t, isLevelName("error"))
+	assert.False(t, isLevelName("fatal"))
+}
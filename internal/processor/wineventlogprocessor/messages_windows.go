@@ -0,0 +1,196 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package wineventlogprocessor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// EVT_EVENT_METADATA_PROPERTY_ID values
+	// https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_event_metadata_property_id
+	eventMetadataEventID        = 0
+	eventMetadataEventVersion   = 1
+	eventMetadataEventMessageID = 8
+
+	// evtFormatMessageID formats the message of a message ID, without the values of an event.
+	evtFormatMessageID = 8
+
+	// noMessageID is the message ID of events without a message.
+	noMessageID = 0xFFFFFFFF
+
+	errorEvtUnresolvedValueInsert     = windows.Errno(15029)
+	errorEvtUnresolvedParameterInsert = windows.Errno(15030)
+)
+
+var (
+	wevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	// https://learn.microsoft.com/en-us/windows/win32/wes/windows-event-log-functions
+	procEvtOpenPublisherMetadata    = wevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtOpenEventMetadataEnum    = wevtapi.NewProc("EvtOpenEventMetadataEnum")
+	procEvtNextEventMetadata        = wevtapi.NewProc("EvtNextEventMetadata")
+	procEvtGetEventMetadataProperty = wevtapi.NewProc("EvtGetEventMetadataProperty")
+	procEvtFormatMessage            = wevtapi.NewProc("EvtFormatMessage")
+	procEvtClose                    = wevtapi.NewProc("EvtClose")
+)
+
+// evtVariant is the EVT_VARIANT holding the event metadata properties.
+type evtVariant struct {
+	value uint64
+	count uint32
+	typ   uint32
+}
+
+type eventVersion struct {
+	eventID uint32
+	version uint32
+}
+
+// publisher is the opened metadata of a publisher, with the message IDs of its manifest events.
+type publisher struct {
+	handle     uintptr
+	messageIDs map[eventVersion]uint32
+}
+
+// windowsMessageSource looks up message templates from the publisher metadata, which is opened
+// once per publisher and kept open until the processor shuts down.
+type windowsMessageSource struct {
+	publishers map[string]*publisher
+	mu         sync.Mutex
+}
+
+func newMessageSource() (messageSource, error) {
+	if err := wevtapi.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load wevtapi.dll: %w", err)
+	}
+	return &windowsMessageSource{publishers: map[string]*publisher{}}, nil
+}
+
+func (s *windowsMessageSource) template(key messageKey) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.publishers[key.provider]
+	if !ok {
+		var err error
+		p, err = openPublisher(key.provider)
+		// publishers failing to open aren't retried, e.g. since they were uninstalled
+		s.publishers[key.provider] = p
+		if err != nil {
+			return "", fmt.Errorf("failed to open the publisher metadata: %w", err)
+		}
+	}
+	if p == nil {
+		return "", errors.New("the publisher metadata isn't available")
+	}
+
+	messageID, ok := p.messageIDs[eventVersion{eventID: key.eventID, version: key.version}]
+	if !ok {
+		// classic publishers identify messages by the event ID including its qualifiers
+		messageID = key.qualifiers<<16 | key.eventID
+	}
+	return formatMessageID(p.handle, messageID)
+}
+
+func (s *windowsMessageSource) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for provider, p := range s.publishers {
+		if p != nil {
+			evtClose(p.handle)
+		}
+		delete(s.publishers, provider)
+	}
+	return nil
+}
+
+func openPublisher(provider string) (*publisher, error) {
+	name, err := windows.UTF16PtrFromString(provider)
+	if err != nil {
+		return nil, err
+	}
+	handle, _, err := procEvtOpenPublisherMetadata.Call(0, uintptr(unsafe.Pointer(name)), 0, 0, 0)
+	if handle == 0 {
+		return nil, err
+	}
+	p := &publisher{handle: handle, messageIDs: map[eventVersion]uint32{}}
+
+	enum, _, _ := procEvtOpenEventMetadataEnum.Call(handle, 0)
+	if enum == 0 {
+		// classic publishers don't have event metadata
+		return p, nil
+	}
+	defer evtClose(enum)
+	for {
+		meta, _, _ := procEvtNextEventMetadata.Call(enum, 0)
+		if meta == 0 {
+			return p, nil
+		}
+		eventID, errID := eventMetadataProperty(meta, eventMetadataEventID)
+		version, errVersion := eventMetadataProperty(meta, eventMetadataEventVersion)
+		messageID, errMessageID := eventMetadataProperty(meta, eventMetadataEventMessageID)
+		evtClose(meta)
+		if errID == nil && errVersion == nil && errMessageID == nil && messageID != noMessageID {
+			p.messageIDs[eventVersion{eventID: eventID, version: version}] = messageID
+		}
+	}
+}
+
+func eventMetadataProperty(meta uintptr, property uint32) (uint32, error) {
+	var v evtVariant
+	var used uint32
+	r, _, err := procEvtGetEventMetadataProperty.Call(
+		meta, uintptr(property), 0, unsafe.Sizeof(v), uintptr(unsafe.Pointer(&v)), uintptr(unsafe.Pointer(&used)))
+	if r == 0 {
+		return 0, err
+	}
+	return uint32(v.value), nil
+}
+
+// formatMessageID returns the message template of the message ID. Its inserts are kept since
+// no values are passed.
+func formatMessageID(handle uintptr, messageID uint32) (string, error) {
+	buf := make([]uint16, 1024)
+	for {
+		var used uint32
+		r, _, err := procEvtFormatMessage.Call(
+			handle, 0, uintptr(messageID), 0, 0, evtFormatMessageID,
+			uintptr(len(buf)), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&used)))
+		if r == 0 {
+			switch {
+			case errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) && int(used) > len(buf):
+				buf = make([]uint16, used)
+				continue
+			case errors.Is(err, errorEvtUnresolvedValueInsert), errors.Is(err, errorEvtUnresolvedParameterInsert):
+				// the template has inserts, which aren't resolved without values
+			default:
+				return "", err
+			}
+		}
+		return windows.UTF16ToString(buf), nil
+	}
+}
+
+func evtClose(handle uintptr) {
+	_, _, _ = procEvtClose.Call(handle)
+}
@@ -0,0 +1,196 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wineventlogprocessor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// sourcetypeAttribute is the log record attribute the splunk_hec exporter sends as the sourcetype.
+const sourcetypeAttribute = "com.splunk.sourcetype"
+
+// wineventlogProcessor renders the messages and level names of the events of the windowseventlog
+// receiver and sets their sourcetype. Log records without a Windows event body are left as is.
+type wineventlogProcessor struct {
+	cfg       *Config
+	logger    *zap.Logger
+	newSource func() (messageSource, error)
+	messages  *messageCache
+}
+
+func newWineventlogProcessor(cfg *Config, logger *zap.Logger) *wineventlogProcessor {
+	return &wineventlogProcessor{cfg: cfg, logger: logger, newSource: newMessageSource}
+}
+
+func (p *wineventlogProcessor) start(context.Context, component.Host) error {
+	if !p.cfg.RenderMessages {
+		return nil
+	}
+	source, err := p.newSource()
+	if err != nil {
+		p.logger.Warn("Event messages won't be rendered", zap.Error(err))
+		return nil
+	}
+	p.messages, err = newMessageCache(source, p.cfg.MaxCachedMessages, p.logger)
+	return err
+}
+
+func (p *wineventlogProcessor) shutdown(context.Context) error {
+	if p.messages != nil {
+		return p.messages.close()
+	}
+	return nil
+}
+
+func (p *wineventlogProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				p.processLogRecord(lrs.At(k))
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (p *wineventlogProcessor) processLogRecord(lr plog.LogRecord) {
+	if lr.Body().Type() != pcommon.ValueTypeMap {
+		return
+	}
+	event := lr.Body().Map()
+	channel, ok := event.Get("channel")
+	if !ok {
+		return
+	}
+	provider, ok := event.Get("provider")
+	if !ok || provider.Type() != pcommon.ValueTypeMap {
+		return
+	}
+
+	if p.messages != nil && stringOf(event, "message") == "" {
+		p.renderMessage(event, provider.Map())
+	}
+
+	var level string
+	if l, ok := event.Get("level"); ok {
+		level = levelName(l.AsString())
+		event.PutStr("level", level)
+	}
+
+	if _, ok := lr.Attributes().Get(sourcetypeAttribute); !ok {
+		lr.Attributes().PutStr(sourcetypeAttribute, p.sourcetype(channel.AsString(), level))
+	}
+}
+
+func (p *wineventlogProcessor) renderMessage(event, provider pcommon.Map) {
+	key := messageKey{provider: stringOf(provider, "name")}
+	if key.provider == "" {
+		return
+	}
+	if eventID, ok := event.Get("event_id"); ok && eventID.Type() == pcommon.ValueTypeMap {
+		key.eventID = uint32Of(eventID.Map(), "id")
+		key.qualifiers = uint32Of(eventID.Map(), "qualifiers")
+	}
+	key.version = uint32Of(event, "version")
+	if message, ok := p.messages.message(key, eventDataValues(event)); ok {
+		event.PutStr("message", message)
+	}
+}
+
+// sourcetype returns the sourcetype of the first mapping matching the channel and level, else
+// the default sourcetype.
+func (p *wineventlogProcessor) sourcetype(channel, level string) string {
+	sourcetype := p.cfg.DefaultSourcetype
+	for _, st := range p.cfg.Sourcetypes {
+		if st.Channel != "" && !strings.EqualFold(st.Channel, channel) {
+			continue
+		}
+		if len(st.Levels) > 0 && !containsFold(st.Levels, level) {
+			continue
+		}
+		sourcetype = st.Sourcetype
+		break
+	}
+	return strings.ReplaceAll(sourcetype, channelPlaceholder, channel)
+}
+
+// eventDataValues returns the values of the event data in their order, which are the values of
+// the %1 style inserts of the message template. The receiver represents the data as a list of
+// single entry maps from the data names, which are empty for unnamed data, to their values.
+func eventDataValues(event pcommon.Map) []string {
+	eventData, ok := event.Get("event_data")
+	if !ok || eventData.Type() != pcommon.ValueTypeMap {
+		return nil
+	}
+	data, ok := eventData.Map().Get("data")
+	if !ok || data.Type() != pcommon.ValueTypeSlice {
+		return nil
+	}
+	values := make([]string, 0, data.Slice().Len())
+	for i := 0; i < data.Slice().Len(); i++ {
+		var value string
+		if d := data.Slice().At(i); d.Type() == pcommon.ValueTypeMap {
+			d.Map().Range(func(_ string, v pcommon.Value) bool {
+				value = v.AsString()
+				return false
+			})
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func stringOf(m pcommon.Map, key string) string {
+	if v, ok := m.Get(key); ok {
+		return v.AsString()
+	}
+	return ""
+}
+
+func uint32Of(m pcommon.Map, key string) uint32 {
+	v, ok := m.Get(key)
+	if !ok {
+		return 0
+	}
+	switch v.Type() {
+	case pcommon.ValueTypeInt:
+		return uint32(v.Int())
+	case pcommon.ValueTypeDouble:
+		return uint32(v.Double())
+	case pcommon.ValueTypeStr:
+		n, _ := strconv.ParseUint(v.Str(), 10, 32)
+		return uint32(n)
+	}
+	return 0
+}
+
+func containsFold(s []string, v string) bool {
+	for _, e := range s {
+		if strings.EqualFold(e, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wineventlogprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+type fakeMessageSource struct {
+	templates map[messageKey]string
+	lookups   int
+	closed    bool
+}
+
+func (s *fakeMessageSource) template(key messageKey) (string, error) {
+	s.lookups++
+	if template, ok := s.templates[key]; ok {
+		return template, nil
+	}
+	return "", errors.New("message not found")
+}
+
+func (s *fakeMessageSource) close() error {
+	s.closed = true
+	return nil
+}
+
+func newTestProcessor(t *testing.T, cfg *Config, source *fakeMessageSource) *wineventlogProcessor {
+	p := newWineventlogProcessor(cfg, zap.NewNop())
+	p.newSource = func() (messageSource, error) { return source, nil }
+	require.NoError(t, p.start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, p.shutdown(context.Background())) })
+	return p
+}
+
+// appendEvent appends a log record with the body of a windowseventlog receiver event rendered
+// without rendering info.
+func appendEvent(lrs plog.LogRecordSlice, channel, provider string, eventID int64, level string, data ...string) plog.LogRecord {
+	lr := lrs.AppendEmpty()
+	body := lr.Body().SetEmptyMap()
+	body.PutStr("channel", channel)
+	body.PutEmptyMap("provider").PutStr("name", provider)
+	id := body.PutEmptyMap("event_id")
+	id.PutInt("id", eventID)
+	id.PutInt("qualifiers", 0)
+	body.PutStr("level", level)
+	body.PutStr("message", "")
+	values := body.PutEmptyMap("event_data").PutEmptySlice("data")
+	for i, d := range data {
+		name := ""
+		if i == 0 {
+			name = "TargetUserName"
+		}
+		values.AppendEmpty().SetEmptyMap().PutStr(name, d)
+	}
+	return lr
+}
+
+func TestProcessLogs(t *testing.T) {
+	source := &fakeMessageSource{templates: map[messageKey]string{
+		{provider: "Microsoft-Windows-Security-Auditing", eventID: 4624}: "An account was successfully logged on.%n%nAccount Name:%t%1%nLogon Type:%t%2",
+	}}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Sourcetypes = []SourcetypeConfig{
+		{Channel: "security", Sourcetype: "WinEventLog:Security"},
+		{Levels: []string{"critical", "error"}, Sourcetype: "WinEventLog:{channel}:errors"},
+	}
+	p := newTestProcessor(t, cfg, source)
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	logon := appendEvent(lrs, "Security", "Microsoft-Windows-Security-Auditing", 4624, "0", "alice", "3")
+	logon2 := appendEvent(lrs, "Security", "Microsoft-Windows-Security-Auditing", 4624, "0", "bob", "10")
+	appErr := appendEvent(lrs, "Application", "Application Error", 1000, "2")
+	rendered := appendEvent(lrs, "System", "Service Control Manager", 7036, "Information")
+	rendered.Body().Map().PutStr("message", "The Windows Update service entered the stopped state.")
+	sourcetypeSet := appendEvent(lrs, "System", "Service Control Manager", 7036, "4")
+	sourcetypeSet.Attributes().PutStr("com.splunk.sourcetype", "custom")
+	notAnEvent := lrs.AppendEmpty()
+	notAnEvent.Body().SetStr("not a windows event")
+
+	_, err := p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	message, _ := logon.Body().Map().Get("message")
+	assert.Equal(t, "An account was successfully logged on.\r\n\r\nAccount Name:\talice\r\nLogon Type:\t3", message.Str())
+	message, _ = logon2.Body().Map().Get("message")
+	assert.Equal(t, "An account was successfully logged on.\r\n\r\nAccount Name:\tbob\r\nLogon Type:\t10", message.Str())
+	level, _ := logon.Body().Map().Get("level")
+	assert.Equal(t, "Information", level.Str())
+	sourcetype, _ := logon.Attributes().Get("com.splunk.sourcetype")
+	assert.Equal(t, "WinEventLog:Security", sourcetype.Str())
+
+	// events without a known template keep their empty message
+	message, _ = appErr.Body().Map().Get("message")
+	assert.Equal(t, "", message.Str())
+	level, _ = appErr.Body().Map().Get("level")
+	assert.Equal(t, "Error", level.Str())
+	sourcetype, _ = appErr.Attributes().Get("com.splunk.sourcetype")
+	assert.Equal(t, "WinEventLog:Application:errors", sourcetype.Str())
+
+	message, _ = rendered.Body().Map().Get("message")
+	assert.Equal(t, "The Windows Update service entered the stopped state.", message.Str())
+	sourcetype, _ = rendered.Attributes().Get("com.splunk.sourcetype")
+	assert.Equal(t, "WinEventLog:System", sourcetype.Str())
+
+	sourcetype, _ = sourcetypeSet.Attributes().Get("com.splunk.sourcetype")
+	assert.Equal(t, "custom", sourcetype.Str())
+
+	assert.Equal(t, pcommon.ValueTypeStr, notAnEvent.Body().Type())
+	assert.Equal(t, 0, notAnEvent.Attributes().Len())
+
+	// templates are looked up once per event, including the ones that weren't found
+	assert.Equal(t, 3, source.lookups)
+	_, err = p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, 3, source.lookups)
+}
+
+func TestProcessLogsWithoutRendering(t *testing.T) {
+	source := &fakeMessageSource{}
+	cfg := createDefaultConfig().(*Config)
+	cfg.RenderMessages = false
+	p := newTestProcessor(t, cfg, source)
+
+	ld := plog.NewLogs()
+	lr := appendEvent(ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords(), "Application", "MsiInstaller", 1033, "4")
+	_, err := p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, 0, source.lookups)
+	sourcetype, _ := lr.Attributes().Get("com.splunk.sourcetype")
+	assert.Equal(t, "WinEventLog:Application", sourcetype.Str())
+}
+
+func TestMessageCacheEviction(t *testing.T) {
+	source := &fakeMessageSource{templates: map[messageKey]string{
+		{provider: "a", eventID: 1}: "a %1",
+		{provider: "b", eventID: 1}: "b %1",
+	}}
+	cache, err := newMessageCache(source, 1, zap.NewNop())
+	require.NoError(t, err)
+
+	message, ok := cache.message(messageKey{provider: "a", eventID: 1}, []string{"1"})
+	assert.True(t, ok)
+	assert.Equal(t, "a 1", message)
+	message, ok = cache.message(messageKey{provider: "b", eventID: 1}, []string{"2"})
+	assert.True(t, ok)
+	assert.Equal(t, "b 2", message)
+	_, _ = cache.message(messageKey{provider: "a", eventID: 1}, nil)
+	assert.Equal(t, 3, source.lookups)
+
+	require.NoError(t, cache.close())
+	assert.True(t, source.closed)
+}
@@ -0,0 +1,130 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realm
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// FailoverConfig configures the failover of requests to fallback realms or endpoints.
+type FailoverConfig struct {
+	// FallbackRealms are realms whose endpoints are used, in order, when the primary
+	// endpoint fails. Only applies to requests to realm endpoints.
+	FallbackRealms []string
+	// FallbackEndpoints are the scheme and host of endpoints, e.g. https://gateway:4318,
+	// used in order after the fallback realms. The request paths are preserved.
+	FallbackEndpoints []*url.URL
+	// FailureThreshold is the number of consecutive 5xx responses or request errors
+	// after which requests fail over to the next endpoint.
+	FailureThreshold int
+	// RecoveryInterval is how long requests are sent to a fallback before the primary
+	// endpoint is tried again.
+	RecoveryInterval time.Duration
+}
+
+// FailoverRoundTripper sends requests to their primary endpoint until it returns sustained
+// 5xx responses or errors, after which they are sent to the next fallback realm or endpoint.
+type FailoverRoundTripper struct {
+	base     http.RoundTripper
+	now      func() time.Time
+	failedAt time.Time
+	cfg      FailoverConfig
+	active   int
+	failures int
+	mu       sync.Mutex
+}
+
+// NewFailoverRoundTripper returns a FailoverRoundTripper sending the requests with base.
+func NewFailoverRoundTripper(base http.RoundTripper, cfg FailoverConfig) *FailoverRoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &FailoverRoundTripper{base: base, cfg: cfg, now: time.Now}
+}
+
+func (f *FailoverRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	targets := f.targets(req.URL)
+	active := f.activeTarget(len(targets))
+	if active > 0 {
+		req = req.Clone(req.Context())
+		req.URL = targets[active]
+		req.Host = ""
+	}
+	resp, err := f.base.RoundTrip(req)
+	f.record(active, len(targets), err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
+
+// targets returns the primary url followed by those of the applicable fallbacks.
+func (f *FailoverRoundTripper) targets(primary *url.URL) []*url.URL {
+	targets := []*url.URL{primary}
+	for _, realm := range f.cfg.FallbackRealms {
+		if host, ok := replaceHostRealm(primary.Hostname(), realm); ok {
+			target := *primary
+			target.Host = host
+			if port := primary.Port(); port != "" {
+				target.Host = fmt.Sprintf("%s:%s", host, port)
+			}
+			targets = append(targets, &target)
+		}
+	}
+	for _, endpoint := range f.cfg.FallbackEndpoints {
+		target := *primary
+		target.Scheme = endpoint.Scheme
+		target.Host = endpoint.Host
+		targets = append(targets, &target)
+	}
+	return targets
+}
+
+// activeTarget returns the index of the target requests are currently sent to, returning
+// to the primary once the recovery interval has elapsed.
+func (f *FailoverRoundTripper) activeTarget(numTargets int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active > 0 && f.now().Sub(f.failedAt) >= f.cfg.RecoveryInterval {
+		f.active = 0
+		f.failures = 0
+	}
+	if f.active >= numTargets {
+		// the fallback isn't applicable to this request
+		return 0
+	}
+	return f.active
+}
+
+func (f *FailoverRoundTripper) record(target, numTargets int, failed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if target != f.active {
+		// the active target changed while the request was in flight
+		return
+	}
+	if !failed {
+		f.failures = 0
+		return
+	}
+	f.failures++
+	if f.failures < f.cfg.FailureThreshold || numTargets < 2 {
+		return
+	}
+	f.failures = 0
+	f.active = (f.active + 1) % numTargets
+	f.failedAt = f.now()
+}
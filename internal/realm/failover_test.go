@@ -0,0 +1,128 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realm
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFailoverRoundTripper(t *testing.T) {
+	var hosts []string
+	statuses := map[string]int{"ingest.us0.signalfx.com": http.StatusServiceUnavailable}
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		if req.URL.Host == "gateway:9943" {
+			return nil, errors.New("connection refused")
+		}
+		status, ok := statuses[req.URL.Host]
+		if !ok {
+			status = http.StatusOK
+		}
+		return &http.Response{StatusCode: status}, nil
+	})
+
+	now := time.Now()
+	f := NewFailoverRoundTripper(base, FailoverConfig{
+		FallbackRealms:    []string{"us1"},
+		FallbackEndpoints: []*url.URL{{Scheme: "http", Host: "gateway:9943"}},
+		FailureThreshold:  2,
+		RecoveryInterval:  time.Minute,
+	})
+	f.now = func() time.Time { return now }
+
+	send := func() {
+		req, err := http.NewRequest(http.MethodPost, "https://ingest.us0.signalfx.com/v2/datapoint", http.NoBody)
+		require.NoError(t, err)
+		_, _ = f.RoundTrip(req)
+	}
+
+	// the primary fails over to the fallback realm after two consecutive 5xx
+	send()
+	send()
+	send()
+	send()
+	assert.Equal(t, []string{
+		"ingest.us0.signalfx.com", "ingest.us0.signalfx.com", "ingest.us1.signalfx.com", "ingest.us1.signalfx.com",
+	}, hosts)
+	assert.Equal(t, 1, f.active)
+
+	// the primary is retried after the recovery interval
+	hosts = nil
+	now = now.Add(time.Minute)
+	statuses["ingest.us0.signalfx.com"] = http.StatusOK
+	send()
+	assert.Equal(t, []string{"ingest.us0.signalfx.com"}, hosts)
+	assert.Equal(t, 0, f.active)
+
+	// sustained failures of the fallback realm move on to the fallback endpoint and then back to the primary
+	f.active = 1
+	f.failedAt = now
+	statuses["ingest.us1.signalfx.com"] = http.StatusBadGateway
+	hosts = nil
+	send()
+	send()
+	send()
+	send()
+	send()
+	assert.Equal(t, []string{
+		"ingest.us1.signalfx.com", "ingest.us1.signalfx.com", "gateway:9943", "gateway:9943", "ingest.us0.signalfx.com",
+	}, hosts)
+}
+
+func TestFailoverRoundTripperIgnoresClientErrors(t *testing.T) {
+	var hosts []string
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		return &http.Response{StatusCode: http.StatusBadRequest}, nil
+	})
+	f := NewFailoverRoundTripper(base, FailoverConfig{FallbackRealms: []string{"us1"}, FailureThreshold: 1, RecoveryInterval: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodPost, "https://ingest.us0.signalfx.com/v2/datapoint", http.NoBody)
+		require.NoError(t, err)
+		_, err = f.RoundTrip(req)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"ingest.us0.signalfx.com", "ingest.us0.signalfx.com", "ingest.us0.signalfx.com"}, hosts)
+}
+
+func TestFailoverRoundTripperWithoutApplicableFallback(t *testing.T) {
+	var hosts []string
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		return &http.Response{StatusCode: http.StatusInternalServerError}, nil
+	})
+	f := NewFailoverRoundTripper(base, FailoverConfig{FallbackRealms: []string{"us1"}, FailureThreshold: 1, RecoveryInterval: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:9943/v2/datapoint", http.NoBody)
+		require.NoError(t, err)
+		_, _ = f.RoundTrip(req)
+	}
+	assert.Equal(t, []string{"localhost:9943", "localhost:9943"}, hosts)
+}
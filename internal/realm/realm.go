@@ -0,0 +1,102 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package realm derives the Splunk Observability Cloud endpoints of a realm and
+// provides failover of requests to the endpoints of other realms.
+package realm
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const domain = "signalfx.com"
+
+// Endpoints are the per-signal endpoints of a realm.
+type Endpoints struct {
+	// API is the REST API endpoint, e.g. https://api.us0.signalfx.com
+	API string
+	// Ingest is the metrics and events ingest endpoint, e.g. https://ingest.us0.signalfx.com
+	Ingest string
+	// Trace is the trace ingest endpoint, e.g. https://ingest.us0.signalfx.com/v2/trace
+	Trace string
+	// HEC is the log ingest endpoint, e.g. https://ingest.us0.signalfx.com/v1/log
+	HEC string
+}
+
+// ForRealm returns the endpoints of the realm. Endpoints of individual signals are
+// overridden with their SPLUNK_*_URL environment variables, which aren't replaced
+// by the endpoints of SPLUNK_REALM.
+func ForRealm(realm string) Endpoints {
+	ingest := fmt.Sprintf("https://ingest.%s.%s", realm, domain)
+	return Endpoints{
+		API:    fmt.Sprintf("https://api.%s.%s", realm, domain),
+		Ingest: ingest,
+		Trace:  ingest + "/v2/trace",
+		HEC:    ingest + "/v1/log",
+	}
+}
+
+// FromHost returns the realm of an endpoint host like "ingest.us0.signalfx.com".
+func FromHost(host string) (string, bool) {
+	service, rest, ok := strings.Cut(host, ".")
+	if !ok || service == "" {
+		return "", false
+	}
+	realm, d, ok := strings.Cut(rest, ".")
+	if !ok || realm == "" || d != domain {
+		return "", false
+	}
+	return realm, true
+}
+
+// replaceHostRealm returns the host with its realm replaced, if it is a realm endpoint host.
+func replaceHostRealm(host, realm string) (string, bool) {
+	current, ok := FromHost(host)
+	if !ok {
+		return "", false
+	}
+	service := strings.TrimSuffix(host, fmt.Sprintf(".%s.%s", current, domain))
+	return fmt.Sprintf("%s.%s.%s", service, realm, domain), true
+}
+
+// ReplaceEndpointRealm returns the endpoint, a URL or host:port, with the realm of its host
+// replaced, if it is a realm endpoint.
+func ReplaceEndpointRealm(endpoint, realm string) (string, bool) {
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", false
+		}
+		host, ok := replaceHostRealm(u.Hostname(), realm)
+		if !ok {
+			return "", false
+		}
+		if port := u.Port(); port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+		u.Host = host
+		return u.String(), true
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return replaceHostRealm(endpoint, realm)
+	}
+	if host, ok := replaceHostRealm(host, realm); ok {
+		return net.JoinHostPort(host, port), true
+	}
+	return "", false
+}
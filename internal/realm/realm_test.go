@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForRealm(t *testing.T) {
+	assert.Equal(t, Endpoints{
+		API:    "https://api.us1.signalfx.com",
+		Ingest: "https://ingest.us1.signalfx.com",
+		Trace:  "https://ingest.us1.signalfx.com/v2/trace",
+		HEC:    "https://ingest.us1.signalfx.com/v1/log",
+	}, ForRealm("us1"))
+}
+
+func TestFromHost(t *testing.T) {
+	for _, tt := range []struct {
+		host  string
+		realm string
+		ok    bool
+	}{
+		{host: "ingest.us0.signalfx.com", realm: "us0", ok: true},
+		{host: "api.eu0.signalfx.com", realm: "eu0", ok: true},
+		{host: "ingest.signalfx.com"},
+		{host: "ingest.us0.example.com"},
+		{host: "localhost"},
+		{host: ".us0.signalfx.com"},
+	} {
+		t.Run(tt.host, func(t *testing.T) {
+			realm, ok := FromHost(tt.host)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.realm, realm)
+		})
+	}
+}
+
+func TestReplaceHostRealm(t *testing.T) {
+	host, ok := replaceHostRealm("ingest.us0.signalfx.com", "us1")
+	assert.True(t, ok)
+	assert.Equal(t, "ingest.us1.signalfx.com", host)
+
+	_, ok = replaceHostRealm("gateway", "us1")
+	assert.False(t, ok)
+}
+
+func TestReplaceEndpointRealm(t *testing.T) {
+	for _, tt := range []struct {
+		endpoint string
+		expected string
+		ok       bool
+	}{
+		{endpoint: "https://ingest.us0.signalfx.com/v2/trace/otlp", expected: "https://ingest.eu0.signalfx.com/v2/trace/otlp", ok: true},
+		{endpoint: "https://ingest.us0.signalfx.com:443", expected: "https://ingest.eu0.signalfx.com:443", ok: true},
+		{endpoint: "ingest.us0.signalfx.com:443", expected: "ingest.eu0.signalfx.com:443", ok: true},
+		{endpoint: "api.us0.signalfx.com", expected: "api.eu0.signalfx.com", ok: true},
+		{endpoint: "http://gateway:4318"},
+		{endpoint: "gateway:4317"},
+	} {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			endpoint, ok := ReplaceEndpointRealm(tt.endpoint, "eu0")
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, endpoint)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatchmetricstreamsreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
+)
+
+const (
+	formatOpenTelemetry10 = "opentelemetry1.0"
+	formatOpenTelemetry07 = "opentelemetry0.7"
+	formatJSON            = "json"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines the Firehose HTTP endpoint the metric streams are delivered to.
+type Config struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+	// Limits limits the delivery requests served concurrently.
+	transport.Limits `mapstructure:",squash"`
+	// AccessKey is the access key of the Firehose HTTP endpoint destination. Requests with another
+	// access key are rejected. Requests aren't authenticated if empty.
+	AccessKey configopaque.String `mapstructure:"access_key"`
+	// Format is the output format of the metric streams: opentelemetry1.0, opentelemetry0.7 or json.
+	Format string `mapstructure:"format"`
+	// Path is the path the delivery requests are served on.
+	Path string `mapstructure:"path"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("endpoint must be specified"))
+	}
+	if !strings.HasPrefix(cfg.Path, "/") {
+		errs = errors.Join(errs, errors.New("path must start with /"))
+	}
+	switch cfg.Format {
+	case formatOpenTelemetry10, formatOpenTelemetry07, formatJSON:
+	default:
+		errs = errors.Join(errs, fmt.Errorf("format must be one of %q, %q or %q", formatOpenTelemetry10, formatOpenTelemetry07, formatJSON))
+	}
+	if err := cfg.Limits.Validate(); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,120 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatchmetricstreamsreceiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// cwMetric is a metric of a CloudWatch metric stream in the json format.
+type cwMetric struct {
+	Dimensions       map[string]string  `json:"dimensions"`
+	Value            map[string]float64 `json:"value"`
+	MetricStreamName string             `json:"metric_stream_name"`
+	AccountID        string             `json:"account_id"`
+	Region           string             `json:"region"`
+	Namespace        string             `json:"namespace"`
+	MetricName       string             `json:"metric_name"`
+	Unit             string             `json:"unit"`
+	Timestamp        int64              `json:"timestamp"`
+}
+
+type cwResourceKey struct {
+	stream, account, region, namespace string
+}
+
+// unmarshalJSON appends the metrics of a record in the json format, made of newline delimited
+// metrics, to md. Each metric is converted to a summary whose min and max are its 0 and 1
+// quantiles, alongside the percentiles of the additional statistics of the stream.
+func unmarshalJSON(data []byte, md pmetric.Metrics) error {
+	resources := map[cwResourceKey]pmetric.ScopeMetrics{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var m cwMetric
+		if err := decoder.Decode(&m); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if m.MetricName == "" || m.Namespace == "" {
+			return errors.New("metric_name and namespace must not be empty")
+		}
+		key := cwResourceKey{stream: m.MetricStreamName, account: m.AccountID, region: m.Region, namespace: m.Namespace}
+		sm, ok := resources[key]
+		if !ok {
+			rm := md.ResourceMetrics().AppendEmpty()
+			attrs := rm.Resource().Attributes()
+			attrs.PutStr("cloud.provider", "aws")
+			attrs.PutStr("cloud.account.id", m.AccountID)
+			attrs.PutStr("cloud.region", m.Region)
+			attrs.PutStr("aws.cloudwatch.namespace", m.Namespace)
+			attrs.PutStr("aws.cloudwatch.metric_stream_name", m.MetricStreamName)
+			sm = rm.ScopeMetrics().AppendEmpty()
+			resources[key] = sm
+		}
+		metric := sm.Metrics().AppendEmpty()
+		metric.SetName("amazonaws.com/" + m.Namespace + "/" + m.MetricName)
+		metric.SetUnit(m.Unit)
+		dp := metric.SetEmptySummary().DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.Timestamp(m.Timestamp * 1e6))
+		for k, v := range m.Dimensions {
+			dp.Attributes().PutStr(k, v)
+		}
+		if err := setSummaryValue(dp, m.Value); err != nil {
+			return fmt.Errorf("metric %q: %w", m.MetricName, err)
+		}
+	}
+}
+
+func setSummaryValue(dp pmetric.SummaryDataPoint, value map[string]float64) error {
+	type quantile struct{ quantile, value float64 }
+	var quantiles []quantile
+	for statistic, v := range value {
+		switch statistic {
+		case "count":
+			dp.SetCount(uint64(v))
+		case "sum":
+			dp.SetSum(v)
+		case "min":
+			quantiles = append(quantiles, quantile{quantile: 0, value: v})
+		case "max":
+			quantiles = append(quantiles, quantile{quantile: 1, value: v})
+		default:
+			// percentiles like p99 or p99.9
+			percentile, err := strconv.ParseFloat(strings.TrimPrefix(statistic, "p"), 64)
+			if !strings.HasPrefix(statistic, "p") || err != nil || percentile < 0 || percentile > 100 {
+				return fmt.Errorf("unsupported statistic %q", statistic)
+			}
+			quantiles = append(quantiles, quantile{quantile: percentile / 100, value: v})
+		}
+	}
+	sort.Slice(quantiles, func(i, j int) bool { return quantiles[i].quantile < quantiles[j].quantile })
+	for _, q := range quantiles {
+		qv := dp.QuantileValues().AppendEmpty()
+		qv.SetQuantile(q.quantile)
+		qv.SetValue(q.value)
+	}
+	return nil
+}
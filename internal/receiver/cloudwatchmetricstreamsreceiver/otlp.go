@@ -0,0 +1,198 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatchmetricstreamsreceiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+)
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// unmarshalOpenTelemetry10 appends the metrics of a record in the opentelemetry1.0 format, made of
+// length delimited OTLP ExportMetricsServiceRequest messages, to md.
+func unmarshalOpenTelemetry10(data []byte, md pmetric.Metrics) error {
+	return unmarshalDelimited(data, md, nil)
+}
+
+// unmarshalOpenTelemetry07 appends the metrics of a record in the opentelemetry0.7 format to md.
+// Its messages are the same as the opentelemetry1.0 ones, except for the dimensions of data points,
+// which are converted from the string labels of OTLP 0.7 to attributes.
+func unmarshalOpenTelemetry07(data []byte, md pmetric.Metrics) error {
+	return unmarshalDelimited(data, md, convertOTLP07)
+}
+
+func unmarshalDelimited(data []byte, md pmetric.Metrics, convert func([]byte) ([]byte, error)) error {
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return errTruncated
+		}
+		msg := data[n : n+int(size)]
+		data = data[n+int(size):]
+		if convert != nil {
+			var err error
+			if msg, err = convert(msg); err != nil {
+				return err
+			}
+		}
+		req := pmetricotlp.NewExportRequest()
+		if err := req.UnmarshalProto(msg); err != nil {
+			return err
+		}
+		req.Metrics().ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+	}
+	return nil
+}
+
+// convertOTLP07 converts an OTLP 0.7 ExportMetricsServiceRequest to OTLP 1.0. The messages of both
+// versions have the same field numbers, except for the labels of data points (field 1), replaced by
+// attributes (field 9 for histograms, 7 otherwise). The int gauges and sums of OTLP 0.7 aren't
+// converted, and are dropped.
+func convertOTLP07(msg []byte) ([]byte, error) {
+	dataPoints := func(attributesField uint64) func([]byte) ([]byte, error) {
+		dataPoint := func(b []byte) ([]byte, error) {
+			return convertLabels(b, attributesField)
+		}
+		return func(b []byte) ([]byte, error) {
+			return rewriteFields(b, map[uint64]func([]byte) ([]byte, error){1: dataPoint})
+		}
+	}
+	// the double gauge, sum, histogram and summary data of metrics
+	metric := func(b []byte) ([]byte, error) {
+		return rewriteFields(b, map[uint64]func([]byte) ([]byte, error){
+			5:  dataPoints(7),
+			7:  dataPoints(7),
+			9:  dataPoints(9),
+			11: dataPoints(7),
+		})
+	}
+	libraryMetrics := func(b []byte) ([]byte, error) {
+		return rewriteFields(b, map[uint64]func([]byte) ([]byte, error){2: metric})
+	}
+	resourceMetrics := func(b []byte) ([]byte, error) {
+		return rewriteFields(b, map[uint64]func([]byte) ([]byte, error){2: libraryMetrics})
+	}
+	return rewriteFields(msg, map[uint64]func([]byte) ([]byte, error){1: resourceMetrics})
+}
+
+// convertLabels converts the StringKeyValue labels (field 1) of a data point to KeyValue attributes
+// with a string AnyValue.
+func convertLabels(dataPoint []byte, attributesField uint64) ([]byte, error) {
+	var out []byte
+	err := rangeFields(dataPoint, func(num, typ uint64, field, value []byte) error {
+		if num != 1 || typ != wireBytes {
+			out = append(out, field...)
+			return nil
+		}
+		var key, val []byte
+		if err := rangeFields(value, func(num, typ uint64, _, value []byte) error {
+			switch {
+			case num == 1 && typ == wireBytes:
+				key = value
+			case num == 2 && typ == wireBytes:
+				val = value
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		anyValue := appendBytesField(nil, 1, val)
+		keyValue := appendBytesField(appendBytesField(nil, 1, key), 2, anyValue)
+		out = appendBytesField(out, attributesField, keyValue)
+		return nil
+	})
+	return out, err
+}
+
+// rewriteFields copies the fields of a message, replacing the embedded messages of the fields
+// with a rewrite function by their rewritten message.
+func rewriteFields(msg []byte, rewrites map[uint64]func([]byte) ([]byte, error)) ([]byte, error) {
+	var out []byte
+	err := rangeFields(msg, func(num, typ uint64, field, value []byte) error {
+		rewrite, ok := rewrites[num]
+		if !ok || typ != wireBytes {
+			out = append(out, field...)
+			return nil
+		}
+		rewritten, err := rewrite(value)
+		if err != nil {
+			return err
+		}
+		out = appendBytesField(out, num, rewritten)
+		return nil
+	})
+	return out, err
+}
+
+// rangeFields calls f with the number, wire type, encoding and value of each field of a message.
+// The value is only set for length delimited fields.
+func rangeFields(msg []byte, f func(num, typ uint64, field, value []byte) error) error {
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return errTruncated
+		}
+		num, typ := tag>>3, tag&7
+		var value []byte
+		switch typ {
+		case wireVarint:
+			_, m := binary.Uvarint(msg[n:])
+			if m <= 0 {
+				return errTruncated
+			}
+			n += m
+		case wireFixed64:
+			n += 8
+		case wireFixed32:
+			n += 4
+		case wireBytes:
+			size, m := binary.Uvarint(msg[n:])
+			if m <= 0 || uint64(len(msg)-n-m) < size {
+				return errTruncated
+			}
+			value = msg[n+m : n+m+int(size)]
+			n += m + int(size)
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", typ)
+		}
+		if n > len(msg) {
+			return errTruncated
+		}
+		if err := f(num, typ, msg[:n], value); err != nil {
+			return err
+		}
+		msg = msg[n:]
+	}
+	return nil
+}
+
+func appendBytesField(b []byte, num uint64, value []byte) []byte {
+	b = binary.AppendUvarint(b, num<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
@@ -0,0 +1,181 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatchmetricstreamsreceiver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
+)
+
+const (
+	requestIDHeader        = "X-Amz-Firehose-Request-Id"
+	accessKeyHeader        = "X-Amz-Firehose-Access-Key"
+	commonAttributesHeader = "X-Amz-Firehose-Common-Attributes"
+)
+
+var _ receiver.Metrics = (*metricStreamsReceiver)(nil)
+
+// firehoseRequest is the body of a Firehose HTTP endpoint delivery request.
+type firehoseRequest struct {
+	RequestID string           `json:"requestId"`
+	Records   []firehoseRecord `json:"records"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+type firehoseRecord struct {
+	// Data is base64 encoded in the request.
+	Data []byte `json:"data"`
+}
+
+// firehoseResponse is the body of the response to a delivery request. Firehose retries the
+// requests answered with another status than 200, and reports their error message.
+type firehoseResponse struct {
+	RequestID    string `json:"requestId"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// commonAttributes are the attributes of the Firehose HTTP endpoint destination, sent with each request.
+type commonAttributes struct {
+	CommonAttributes map[string]string `json:"commonAttributes"`
+}
+
+// metricStreamsReceiver accepts the CloudWatch metric streams delivered by Firehose to an HTTP endpoint.
+type metricStreamsReceiver struct {
+	consumer  consumer.Metrics
+	config    *Config
+	logger    *zap.Logger
+	server    *transport.Server
+	unmarshal func(data []byte, md pmetric.Metrics) error
+	now       func() time.Time
+}
+
+func newReceiver(settings receiver.Settings, config *Config, consumer consumer.Metrics) (*metricStreamsReceiver, error) {
+	r := &metricStreamsReceiver{
+		consumer: consumer,
+		config:   config,
+		logger:   settings.Logger,
+		now:      time.Now,
+	}
+	switch config.Format {
+	case formatJSON:
+		r.unmarshal = unmarshalJSON
+	case formatOpenTelemetry07:
+		r.unmarshal = unmarshalOpenTelemetry07
+	default:
+		r.unmarshal = unmarshalOpenTelemetry10
+	}
+	server, err := transport.NewServer(settings.TelemetrySettings, config.ServerConfig, config.Limits, typeStr)
+	if err != nil {
+		return nil, err
+	}
+	server.Handle(config.Path, r)
+	r.server = server
+	return r, nil
+}
+
+func (r *metricStreamsReceiver) Start(ctx context.Context, host component.Host) error {
+	return r.server.Start(ctx, host)
+}
+
+func (r *metricStreamsReceiver) Shutdown(ctx context.Context) error {
+	return r.server.Shutdown(ctx)
+}
+
+func (r *metricStreamsReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	requestID := req.Header.Get(requestIDHeader)
+	if req.Method != http.MethodPost {
+		r.respond(w, requestID, http.StatusMethodNotAllowed, "only POST requests are accepted")
+		return
+	}
+	if key := string(r.config.AccessKey); key != "" &&
+		subtle.ConstantTimeCompare([]byte(req.Header.Get(accessKeyHeader)), []byte(key)) != 1 {
+		r.respond(w, requestID, http.StatusUnauthorized, "invalid access key")
+		return
+	}
+	if requestID == "" {
+		r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("missing %s header", requestIDHeader))
+		return
+	}
+	var body firehoseRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if body.RequestID != requestID {
+		r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("request ID %q doesn't match the %s header", body.RequestID, requestIDHeader))
+		return
+	}
+	var attributes commonAttributes
+	if header := req.Header.Get(commonAttributesHeader); header != "" {
+		if err := json.Unmarshal([]byte(header), &attributes); err != nil {
+			r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("invalid %s header: %v", commonAttributesHeader, err))
+			return
+		}
+	}
+
+	// the records are only consumed if all of them are valid, since Firehose retries whole requests
+	md := pmetric.NewMetrics()
+	for i, record := range body.Records {
+		if err := r.unmarshal(record.Data, md); err != nil {
+			r.respond(w, requestID, http.StatusBadRequest, fmt.Sprintf("invalid record %d: %v", i, err))
+			return
+		}
+	}
+	if md.DataPointCount() == 0 {
+		r.respond(w, requestID, http.StatusOK, "")
+		return
+	}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		resource := md.ResourceMetrics().At(i).Resource().Attributes()
+		for k, v := range attributes.CommonAttributes {
+			resource.PutStr(k, v)
+		}
+	}
+	if err := r.consumer.ConsumeMetrics(req.Context(), md); err != nil {
+		r.logger.Debug("Failed consuming metric streams", zap.String("request_id", requestID), zap.Error(err))
+		status := http.StatusServiceUnavailable
+		if consumererror.IsPermanent(err) {
+			status = http.StatusBadRequest
+		}
+		r.respond(w, requestID, status, err.Error())
+		return
+	}
+	r.respond(w, requestID, http.StatusOK, "")
+}
+
+// respond answers a delivery request, successfully if errorMessage is empty.
+func (r *metricStreamsReceiver) respond(w http.ResponseWriter, requestID string, status int, errorMessage string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(firehoseResponse{
+		RequestID:    requestID,
+		ErrorMessage: errorMessage,
+		Timestamp:    r.now().UnixMilli(),
+	})
+}
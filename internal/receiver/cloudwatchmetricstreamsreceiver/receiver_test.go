@@ -0,0 +1,140 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatchmetricstreamsreceiver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+func newTestReceiver(next consumer.Metrics) *metricStreamsReceiver {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AccessKey = "secret"
+	return &metricStreamsReceiver{
+		consumer:  next,
+		config:    cfg,
+		logger:    zap.NewNop(),
+		unmarshal: unmarshalJSON,
+		now:       func() time.Time { return time.UnixMilli(1700000000123) },
+	}
+}
+
+func newRequest(requestID, accessKey string, records ...string) *http.Request {
+	var data []string
+	for _, record := range records {
+		data = append(data, `{"data":"`+base64.StdEncoding.EncodeToString([]byte(record))+`"}`)
+	}
+	body := `{"requestId":"` + requestID + `","timestamp":1700000000000,"records":[` + strings.Join(data, ",") + `]}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(requestIDHeader, requestID)
+	req.Header.Set(accessKeyHeader, accessKey)
+	return req
+}
+
+func TestServeHTTP(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	r := newTestReceiver(sink)
+
+	req := newRequest("req-1", "secret", jsonRecord)
+	req.Header.Set(commonAttributesHeader, `{"commonAttributes":{"deployment.environment":"prod"}}`)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp firehoseResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, firehoseResponse{RequestID: "req-1", Timestamp: 1700000000123}, resp)
+	require.Len(t, sink.AllMetrics(), 1)
+	md := sink.AllMetrics()[0]
+	assert.Equal(t, 2, md.DataPointCount())
+	env, _ := md.ResourceMetrics().At(0).Resource().Attributes().Get("deployment.environment")
+	assert.Equal(t, "prod", env.Str())
+
+	invalidBody := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{"))
+	invalidBody.Header.Set(requestIDHeader, "req-1")
+	invalidBody.Header.Set(accessKeyHeader, "secret")
+	invalidAttributes := newRequest("req-1", "secret", jsonRecord)
+	invalidAttributes.Header.Set(commonAttributesHeader, "{")
+	get := newRequest("req-1", "secret")
+	get.Method = http.MethodGet
+	for _, tt := range []struct {
+		req                  *http.Request
+		name                 string
+		expectedErrorMessage string
+		expectedCode         int
+	}{
+		{name: "invalid method", req: get, expectedCode: http.StatusMethodNotAllowed, expectedErrorMessage: "only POST requests are accepted"},
+		{name: "invalid access key", req: newRequest("req-1", "other"), expectedCode: http.StatusUnauthorized, expectedErrorMessage: "invalid access key"},
+		{name: "missing request id", req: newRequest("", "secret"), expectedCode: http.StatusBadRequest, expectedErrorMessage: "missing X-Amz-Firehose-Request-Id header"},
+		{name: "invalid body", req: invalidBody, expectedCode: http.StatusBadRequest, expectedErrorMessage: "invalid request body: unexpected EOF"},
+		{
+			name:                 "invalid common attributes",
+			req:                  invalidAttributes,
+			expectedCode:         http.StatusBadRequest,
+			expectedErrorMessage: "invalid X-Amz-Firehose-Common-Attributes header: unexpected end of JSON input",
+		},
+		{
+			name:                 "invalid record",
+			req:                  newRequest("req-1", "secret", jsonRecord, `{"namespace":"AWS/EC2"}`),
+			expectedCode:         http.StatusBadRequest,
+			expectedErrorMessage: "invalid record 1: metric_name and namespace must not be empty",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sink.Reset()
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, tt.req)
+			require.Equal(t, tt.expectedCode, rec.Code)
+			var resp firehoseResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedErrorMessage, resp.ErrorMessage)
+			assert.Empty(t, sink.AllMetrics())
+		})
+	}
+}
+
+func TestServeHTTPConsumerErrors(t *testing.T) {
+	for _, tt := range []struct {
+		err          error
+		name         string
+		expectedCode int
+	}{
+		{name: "retryable", err: errors.New("export failed"), expectedCode: http.StatusServiceUnavailable},
+		{name: "permanent", err: consumererror.NewPermanent(errors.New("invalid data")), expectedCode: http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReceiver(consumertest.NewErr(tt.err))
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, newRequest("req-1", "secret", jsonRecord))
+			require.Equal(t, tt.expectedCode, rec.Code)
+			var resp firehoseResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "req-1", resp.RequestID)
+			assert.Equal(t, tt.err.Error(), resp.ErrorMessage)
+		})
+	}
+}
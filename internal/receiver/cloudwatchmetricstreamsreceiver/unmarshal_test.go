@@ -0,0 +1,130 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudwatchmetricstreamsreceiver
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+)
+
+const jsonRecord = `{"metric_stream_name":"all","account_id":"123456789012","region":"us-east-1","namespace":"AWS/EC2","metric_name":"CPUUtilization","dimensions":{"InstanceId":"i-123"},"timestamp":1700000000000,"value":{"max":80,"min":10,"sum":150,"count":3,"p99":79.5},"unit":"Percent"}
+{"metric_stream_name":"all","account_id":"123456789012","region":"us-east-1","namespace":"AWS/EC2","metric_name":"NetworkIn","dimensions":{"InstanceId":"i-123"},"timestamp":1700000000000,"value":{"max":2048,"min":0,"sum":4096,"count":3},"unit":"Bytes"}
+`
+
+func TestUnmarshalJSON(t *testing.T) {
+	md := pmetric.NewMetrics()
+	require.NoError(t, unmarshalJSON([]byte(jsonRecord), md))
+
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, map[string]any{
+		"cloud.provider":                    "aws",
+		"cloud.account.id":                  "123456789012",
+		"cloud.region":                      "us-east-1",
+		"aws.cloudwatch.namespace":          "AWS/EC2",
+		"aws.cloudwatch.metric_stream_name": "all",
+	}, rm.Resource().Attributes().AsRaw())
+	ms := rm.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+	assert.Equal(t, "amazonaws.com/AWS/EC2/CPUUtilization", ms.At(0).Name())
+	assert.Equal(t, "Percent", ms.At(0).Unit())
+	dp := ms.At(0).Summary().DataPoints().At(0)
+	assert.Equal(t, pcommon.Timestamp(1700000000000000000), dp.Timestamp())
+	assert.Equal(t, uint64(3), dp.Count())
+	assert.Equal(t, 150.0, dp.Sum())
+	assert.Equal(t, map[string]any{"InstanceId": "i-123"}, dp.Attributes().AsRaw())
+	var quantiles [][2]float64
+	for i := 0; i < dp.QuantileValues().Len(); i++ {
+		quantiles = append(quantiles, [2]float64{dp.QuantileValues().At(i).Quantile(), dp.QuantileValues().At(i).Value()})
+	}
+	assert.Equal(t, [][2]float64{{0, 10}, {0.99, 79.5}, {1, 80}}, quantiles)
+	assert.Equal(t, "amazonaws.com/AWS/EC2/NetworkIn", ms.At(1).Name())
+
+	require.EqualError(t, unmarshalJSON([]byte(`{"namespace":"AWS/EC2","metric_name":"CPUUtilization","value":{"avg":1}}`), md),
+		`metric "CPUUtilization": unsupported statistic "avg"`)
+	require.EqualError(t, unmarshalJSON([]byte(`{"namespace":"AWS/EC2"}`), md), "metric_name and namespace must not be empty")
+	require.Error(t, unmarshalJSON([]byte(`{"namespace":`), md))
+}
+
+func newSummaryMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("cloud.region", "us-east-1")
+	m := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("amazonaws.com/AWS/EC2/CPUUtilization")
+	dp := m.SetEmptySummary().DataPoints().AppendEmpty()
+	dp.Attributes().PutStr("InstanceId", "i-123")
+	dp.SetTimestamp(1700000000000000000)
+	dp.SetCount(3)
+	dp.SetSum(150)
+	return md
+}
+
+func delimited(messages ...[]byte) []byte {
+	var data []byte
+	for _, msg := range messages {
+		data = binary.AppendUvarint(data, uint64(len(msg)))
+		data = append(data, msg...)
+	}
+	return data
+}
+
+func TestUnmarshalOpenTelemetry10(t *testing.T) {
+	msg, err := pmetricotlp.NewExportRequestFromMetrics(newSummaryMetrics()).MarshalProto()
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	require.NoError(t, unmarshalOpenTelemetry10(delimited(msg, msg), md))
+	expected := newSummaryMetrics()
+	newSummaryMetrics().ResourceMetrics().MoveAndAppendTo(expected.ResourceMetrics())
+	assert.Equal(t, expected, md)
+
+	require.ErrorIs(t, unmarshalOpenTelemetry10(delimited(msg)[:10], md), errTruncated)
+}
+
+func TestUnmarshalOpenTelemetry07(t *testing.T) {
+	fixed64 := func(b []byte, num uint64, v uint64) []byte {
+		b = binary.AppendUvarint(b, num<<3|wireFixed64)
+		return binary.LittleEndian.AppendUint64(b, v)
+	}
+	str := func(b []byte, num uint64, s string) []byte {
+		return appendBytesField(b, num, []byte(s))
+	}
+	// an OTLP 0.7 request with a double summary data point with a label
+	label := str(str(nil, 1, "InstanceId"), 2, "i-123")
+	dataPoint := appendBytesField(nil, 1, label)
+	dataPoint = fixed64(dataPoint, 3, 1700000000000000000)
+	dataPoint = fixed64(dataPoint, 4, 3)
+	dataPoint = fixed64(dataPoint, 5, math.Float64bits(150))
+	summary := appendBytesField(nil, 1, dataPoint)
+	metric := appendBytesField(str(nil, 1, "amazonaws.com/AWS/EC2/CPUUtilization"), 11, summary)
+	libraryMetrics := appendBytesField(nil, 2, metric)
+	resource := appendBytesField(nil, 1, str(appendBytesField(nil, 2, str(nil, 1, "us-east-1")), 1, "cloud.region"))
+	resourceMetrics := appendBytesField(appendBytesField(nil, 1, resource), 2, libraryMetrics)
+	msg := appendBytesField(nil, 1, resourceMetrics)
+
+	md := pmetric.NewMetrics()
+	require.NoError(t, unmarshalOpenTelemetry07(delimited(msg), md))
+	assert.Equal(t, newSummaryMetrics(), md)
+
+	require.ErrorIs(t, unmarshalOpenTelemetry07(delimited(msg[:len(msg)-4]), md), errTruncated)
+}
@@ -50,8 +50,16 @@ type Config struct {
 	// Warning: these values will include the literal receiver subconfig from the parent Collector config.
 	// The feature provides no secret redaction and its output is easily decodable into plaintext.
 	EmbedReceiverConfig bool `mapstructure:"embed_receiver_config"`
+	// Whether to include the receiver config instantiated for each endpoint, with its endpoint
+	// expressions evaluated and the values of keys that may hold secrets redacted, as a yaml
+	// "discovery.receiver.evaluated_config" resource attribute string value.
+	EmbedEvaluatedConfig bool `mapstructure:"embed_evaluated_config"`
 	// The duration to maintain "removed" endpoints since their last updated timestamp.
 	CorrelationTTL time.Duration `mapstructure:"correlation_ttl"`
+	// The duration to wait after an endpoint is removed by its observer before emitting its entity
+	// delete event and shutting down its receiver. Endpoints added again during this period are
+	// treated as never having been removed. Zero, the default, removes endpoints immediately.
+	EndpointRemovalGracePeriod time.Duration `mapstructure:"endpoint_removal_grace_period"`
 }
 
 // ReceiverEntry is a definition for a receiver instance to instantiate for each Endpoint matching
@@ -103,6 +111,10 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.EndpointRemovalGracePeriod < 0 {
+		err = multierr.Combine(err, fmt.Errorf("`endpoint_removal_grace_period` must not be negative"))
+	}
+
 	if len(cfg.WatchObservers) == 0 {
 		err = multierr.Combine(err, fmt.Errorf("`watch_observers` must be defined and include at least one configured observer extension"))
 	}
@@ -85,8 +85,9 @@ func TestValidConfig(t *testing.T) {
 				Rule: mustNewRule(`type == "container" && name matches "(?i)redis"`),
 			},
 		},
-		EmbedReceiverConfig: true,
-		CorrelationTTL:      25 * time.Second,
+		EmbedReceiverConfig:  true,
+		EmbedEvaluatedConfig: true,
+		CorrelationTTL:       25 * time.Second,
 		WatchObservers: []component.ID{
 			component.MustNewID("an_observer"),
 			component.MustNewIDWithName("another_observer", "with_name"),
@@ -105,6 +106,7 @@ func TestInvalidConfigs(t *testing.T) {
 		{name: "invalid_status_types", expectedError: `receiver "a_receiver" validation failure: "metrics" status match validation failed: invalid status "unsupported". must be one of [successful partial failed]; "statements" status match validation failed: invalid status "another_unsupported". must be one of [successful partial failed]`},
 		{name: "multiple_status_match_types", expectedError: "receiver \"a_receiver\" validation failure: \"metrics\" status match validation failed. Must provide one of [regexp strict expr] but received [strict regexp]; \"statements\" status match validation failed. Must provide one of [regexp strict expr] but received [strict expr]"},
 		{name: "reserved_receiver_creator", expectedError: `receiver "receiver_creator/with-name" validation failure: receiver cannot be a receiver_creator`},
+		{name: "negative_endpoint_removal_grace_period", expectedError: "`endpoint_removal_grace_period` must not be negative"},
 		{name: "reserved_receiver_name", expectedError: "receiver \"a_receiver/with-receiver_creator/in-name\" validation failure: receiver name cannot contain \"receiver_creator/\""},
 	}
 
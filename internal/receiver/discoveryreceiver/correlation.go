@@ -15,6 +15,7 @@
 package discoveryreceiver
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+	"github.com/signalfx/splunk-otel-collector/internal/status"
 )
 
 // correlation is a grouping of an endpoint, the observing
@@ -121,6 +123,60 @@ func (s *correlationStore) Endpoints(updatedBefore time.Time) []observer.Endpoin
 	return endpoints
 }
 
+// EndpointCounts returns the number of active endpoints and of removed endpoints yet to be reaped.
+func (s *correlationStore) EndpointCounts() (active, removed int) {
+	s.correlations.Range(func(eID, c any) bool {
+		endpointID := eID.(observer.EndpointID)
+		endpointUnlock := s.endpointLocks.Lock(endpointID)
+		defer endpointUnlock()
+		corr := c.(*correlation)
+		switch {
+		case corr.endpoint.ID == "":
+			// created by GetOrCreate before any endpoint event and not yet observed
+		case corr.stale:
+			removed++
+		default:
+			active++
+		}
+		return true
+	})
+	return active, removed
+}
+
+// DiscoveredEndpoints returns the active endpoints whose receiver was evaluated to a status, for
+// the status document of the status extension.
+func (s *correlationStore) DiscoveredEndpoints() []status.DiscoveredEndpoint {
+	var correlations []correlation
+	s.correlations.Range(func(eID, c any) bool {
+		endpointUnlock := s.endpointLocks.Lock(eID.(observer.EndpointID))
+		defer endpointUnlock()
+		if corr := c.(*correlation); !corr.stale && corr.endpoint.ID != "" {
+			correlations = append(correlations, *corr)
+		}
+		return true
+	})
+	endpoints := make([]status.DiscoveredEndpoint, 0, len(correlations))
+	for _, corr := range correlations {
+		attrs := s.Attrs(corr.endpoint.ID)
+		if attrs[discovery.StatusAttr] == "" {
+			continue
+		}
+		endpoint := status.DiscoveredEndpoint{
+			ID:       string(corr.endpoint.ID),
+			Target:   corr.endpoint.Target,
+			Receiver: corr.receiverID.String(),
+			Status:   attrs[discovery.StatusAttr],
+			Message:  attrs[discovery.MessageAttr],
+		}
+		if corr.observerID != discovery.NoType {
+			endpoint.Observer = corr.observerID.String()
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+	return endpoints
+}
+
 // GetOrCreate returns an existing receiver/endpoint correlation or creates a new one.
 func (s *correlationStore) GetOrCreate(endpointID observer.EndpointID, receiverID component.ID) correlation {
 	endpointUnlock := s.endpointLocks.Lock(endpointID)
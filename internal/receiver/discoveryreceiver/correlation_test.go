@@ -24,6 +24,7 @@ import (
 	"go.uber.org/zap/zaptest"
 
 	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+	"github.com/signalfx/splunk-otel-collector/internal/status"
 )
 
 func TestNewCorrelationStore(t *testing.T) {
@@ -188,3 +189,41 @@ func TestReaperLoop(t *testing.T) {
 		return !hasCorrelations
 	}, 100*time.Millisecond, time.Millisecond) // windows test seems to require more time.
 }
+
+func TestEndpointCounts(t *testing.T) {
+	cs := newCorrelationStore(zaptest.NewLogger(t), time.Hour)
+	observerID := component.MustNewID("an_observer")
+	receiverID := component.MustNewID("a_receiver")
+	for _, id := range []observer.EndpointID{"endpoint.one", "endpoint.two", "endpoint.three"} {
+		cs.UpdateEndpoint(observer.Endpoint{ID: id}, receiverID, observerID)
+	}
+	// not yet observed
+	cs.GetOrCreate("endpoint.four", receiverID)
+
+	active, removed := cs.EndpointCounts()
+	require.Equal(t, 3, active)
+	require.Zero(t, removed)
+
+	cs.MarkStale("endpoint.two")
+	active, removed = cs.EndpointCounts()
+	require.Equal(t, 2, active)
+	require.Equal(t, 1, removed)
+}
+
+func TestDiscoveredEndpoints(t *testing.T) {
+	cs := newCorrelationStore(zaptest.NewLogger(t), time.Hour)
+	observerID := component.MustNewIDWithName("host_observer", "local")
+	receiverID := component.MustNewID("postgresql")
+	for _, id := range []observer.EndpointID{"endpoint.two", "endpoint.one", "endpoint.three"} {
+		cs.UpdateEndpoint(observer.Endpoint{ID: id, Target: "localhost:5432"}, receiverID, observerID)
+		cs.UpdateAttrs(id, map[string]string{discovery.StatusAttr: "successful", discovery.MessageAttr: "PostgreSQL receiver is working!"})
+	}
+	// not yet evaluated
+	cs.UpdateEndpoint(observer.Endpoint{ID: "endpoint.four"}, receiverID, observerID)
+	cs.MarkStale("endpoint.three")
+
+	require.Equal(t, []status.DiscoveredEndpoint{
+		{ID: "endpoint.one", Target: "localhost:5432", Observer: "host_observer/local", Receiver: "postgresql", Status: "successful", Message: "PostgreSQL receiver is working!"},
+		{ID: "endpoint.two", Target: "localhost:5432", Observer: "host_observer/local", Receiver: "postgresql", Status: "successful", Message: "PostgreSQL receiver is working!"},
+	}, cs.DiscoveredEndpoints())
+}
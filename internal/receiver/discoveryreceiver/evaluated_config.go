@@ -0,0 +1,163 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antonmedv/expr"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+const redactedValue = "<redacted>"
+
+// secretKeyFragments are the config key fragments whose string values are redacted.
+var secretKeyFragments = []string{
+	"access", "api_key", "apikey", "auth", "credential", "creds",
+	"login", "password", "pwd", "secret", "token", "user",
+}
+
+// evaluateReceiverConfig renders the receiver entry's config and resource attributes for the endpoint
+// the way the receiver creator does when instantiating the receiver: backtick delimited expressions are
+// evaluated against the endpoint environment and the endpoint target is the default "endpoint" value.
+// String values of keys that may hold secrets are redacted.
+func evaluateReceiverConfig(entry ReceiverEntry, endpoint observer.Endpoint) (map[string]any, error) {
+	env, err := endpoint.Env()
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving endpoint environment: %w", err)
+	}
+	config, err := evaluateValue(entry.Config, env)
+	if err != nil {
+		return nil, fmt.Errorf("failed evaluating config: %w", err)
+	}
+	configMap, _ := config.(map[string]any)
+	if configMap == nil {
+		configMap = map[string]any{}
+	}
+	if _, ok := configMap["endpoint"]; !ok && endpoint.Target != "" {
+		configMap["endpoint"] = endpoint.Target
+	}
+	resourceAttributes := map[string]any{}
+	for k, v := range entry.ResourceAttributes {
+		var attr any
+		if attr, err = evaluateValue(v, env); err != nil {
+			return nil, fmt.Errorf("failed evaluating resource attribute %q: %w", k, err)
+		}
+		resourceAttributes[k] = attr
+	}
+	evaluated := map[string]any{"config": redactSecrets(configMap)}
+	if len(resourceAttributes) > 0 {
+		evaluated["resource_attributes"] = resourceAttributes
+	}
+	return evaluated, nil
+}
+
+func evaluateValue(value any, env observer.EndpointEnv) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return evaluateString(v, env)
+	case map[string]any:
+		evaluated := make(map[string]any, len(v))
+		for key, val := range v {
+			e, err := evaluateValue(val, env)
+			if err != nil {
+				return nil, err
+			}
+			evaluated[key] = e
+		}
+		return evaluated, nil
+	case []any:
+		evaluated := make([]any, 0, len(v))
+		for _, val := range v {
+			e, err := evaluateValue(val, env)
+			if err != nil {
+				return nil, err
+			}
+			evaluated = append(evaluated, e)
+		}
+		return evaluated, nil
+	default:
+		return value, nil
+	}
+}
+
+// evaluateString evaluates the backtick delimited expressions of the value. A value consisting of
+// a single expression evaluates to the expression's result, retaining its type. Escaped backticks
+// are literal.
+func evaluateString(value string, env observer.EndpointEnv) (any, error) {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == '`':
+			sb.WriteByte('`')
+			i++
+		case value[i] == '`':
+			end := strings.IndexByte(value[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("expression in %q is missing its closing backtick", value)
+			}
+			code := value[i+1 : i+1+end]
+			result, err := expr.Eval(code, map[string]any(env))
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 && i+end+2 == len(value) {
+				return result, nil
+			}
+			sb.WriteString(fmt.Sprint(result))
+			i += end + 1
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// redactSecrets replaces the string values of keys that may hold secrets.
+func redactSecrets(config map[string]any) map[string]any {
+	redacted := make(map[string]any, len(config))
+	for k, v := range config {
+		switch value := v.(type) {
+		case string:
+			if isSecretKey(k) {
+				v = redactedValue
+			}
+		case map[string]any:
+			v = redactSecrets(value)
+		case []any:
+			values := make([]any, 0, len(value))
+			for _, item := range value {
+				if m, ok := item.(map[string]any); ok {
+					item = redactSecrets(m)
+				}
+				values = append(values, item)
+			}
+			v = values
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range secretKeyFragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/discovery"
+)
+
+var redisEndpoint = observer.Endpoint{
+	ID:      "port.redis",
+	Target:  "localhost:6379",
+	Details: &observer.Port{Name: "redis", Port: 6379, Transport: observer.ProtocolTCP},
+}
+
+func TestEvaluateReceiverConfig(t *testing.T) {
+	evaluated, err := evaluateReceiverConfig(ReceiverEntry{
+		Rule: mustNewRule(`type == "port" && name == "redis"`),
+		Config: map[string]any{
+			"password": "`name`-secret",
+			"port":     "`port`",
+			"url":      "redis://`endpoint`/0",
+			"escaped":  "\\`literal\\`",
+			"nested": map[string]any{
+				"auth_token": "abc",
+				"databases":  []any{"`name`-0", 1},
+			},
+		},
+		ResourceAttributes: map[string]string{"service.name": "`name`"},
+	}, redisEndpoint)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"config": map[string]any{
+			"endpoint": "localhost:6379",
+			"password": redactedValue,
+			"port":     uint16(6379),
+			"url":      "redis://localhost:6379/0",
+			"escaped":  "`literal`",
+			"nested": map[string]any{
+				"auth_token": redactedValue,
+				"databases":  []any{"redis-0", 1},
+			},
+		},
+		"resource_attributes": map[string]any{"service.name": "redis"},
+	}, evaluated)
+}
+
+func TestEvaluateReceiverConfigKeepsConfiguredEndpoint(t *testing.T) {
+	evaluated, err := evaluateReceiverConfig(ReceiverEntry{
+		Config: map[string]any{"endpoint": "`endpoint`/metrics"},
+	}, redisEndpoint)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"config": map[string]any{"endpoint": "localhost:6379/metrics"}}, evaluated)
+}
+
+func TestEvaluateReceiverConfigInvalidExpression(t *testing.T) {
+	_, err := evaluateReceiverConfig(ReceiverEntry{Config: map[string]any{"endpoint": "`not_a_thing(`"}}, redisEndpoint)
+	require.ErrorContains(t, err, "failed evaluating config")
+
+	_, err = evaluateReceiverConfig(ReceiverEntry{Config: map[string]any{"endpoint": "`endpoint"}}, redisEndpoint)
+	require.ErrorContains(t, err, "missing its closing backtick")
+}
+
+func TestCorrelateEvaluatedConfig(t *testing.T) {
+	eval, _, _ := setup(t)
+	eval.config.EmbedEvaluatedConfig = true
+
+	observerID := component.MustNewIDWithName("type", "name")
+	receiverID := component.MustNewIDWithName("receiver", "name")
+	eval.correlations.UpdateEndpoint(redisEndpoint, receiverID, observerID)
+	corr := eval.correlations.GetOrCreate(redisEndpoint.ID, receiverID)
+
+	cfg := &Config{
+		Receivers: map[component.ID]ReceiverEntry{
+			receiverID: {
+				Rule:   mustNewRule(`type == "port"`),
+				Config: map[string]any{"username": "admin", "collection_interval": "10s"},
+			},
+		},
+	}
+
+	to := map[string]string{}
+	eval.correlateResourceAttributes(cfg, to, corr)
+	assert.Equal(t, map[string]string{
+		discovery.ObserverIDAttr: "type/name",
+		discovery.EvaluatedConfigAttr: `config:
+  collection_interval: 10s
+  endpoint: localhost:6379
+  username: <redacted>
+`,
+	}, to)
+}
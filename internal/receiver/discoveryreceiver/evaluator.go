@@ -141,4 +141,18 @@ func (e *evaluator) correlateResourceAttributes(cfg *Config, to map[string]strin
 		}
 		to[discovery.ReceiverConfigAttr] = base64.StdEncoding.EncodeToString(cfgYaml)
 	}
+
+	if e.config.EmbedEvaluatedConfig {
+		evaluated, err := evaluateReceiverConfig(cfg.Receivers[corr.receiverID], corr.endpoint)
+		if err != nil {
+			e.logger.Error("failed evaluating receiver config", zap.String("receiver", corr.receiverID.String()), zap.Error(err))
+			return
+		}
+		cfgYaml, err := yaml.Marshal(evaluated)
+		if err != nil {
+			e.logger.Error("failed embedding evaluated receiver config", zap.String("receiver", corr.receiverID.String()), zap.Error(err))
+			return
+		}
+		to[discovery.EvaluatedConfigAttr] = string(cfgYaml)
+	}
 }
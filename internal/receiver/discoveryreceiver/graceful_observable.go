@@ -0,0 +1,186 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+)
+
+var (
+	_ observer.Observable = (*gracefulObservable)(nil)
+	_ observer.Notify     = (*gracefulNotify)(nil)
+)
+
+// gracefulObservable wraps an observer.Observable, delaying the removal notifications of its
+// endpoints by the configured grace period. Endpoints added again during their grace period
+// are never reported as removed, so briefly unavailable endpoints don't have their receivers
+// torn down and recreated or their entities deleted.
+type gracefulObservable struct {
+	observer.Observable
+	notifies    map[observer.NotifyID]*gracefulNotify
+	gracePeriod time.Duration
+	mu          sync.Mutex
+}
+
+func newGracefulObservable(observable observer.Observable, gracePeriod time.Duration) *gracefulObservable {
+	return &gracefulObservable{
+		Observable:  observable,
+		gracePeriod: gracePeriod,
+		notifies:    map[observer.NotifyID]*gracefulNotify{},
+	}
+}
+
+func (o *gracefulObservable) ListAndWatch(notify observer.Notify) {
+	gn := &gracefulNotify{
+		Notify:      notify,
+		gracePeriod: o.gracePeriod,
+		pending:     map[observer.EndpointID]*pendingRemoval{},
+	}
+	o.mu.Lock()
+	o.notifies[notify.ID()] = gn
+	o.mu.Unlock()
+	o.Observable.ListAndWatch(gn)
+}
+
+func (o *gracefulObservable) Unsubscribe(notify observer.Notify) {
+	o.mu.Lock()
+	gn, ok := o.notifies[notify.ID()]
+	delete(o.notifies, notify.ID())
+	o.mu.Unlock()
+	if !ok {
+		o.Observable.Unsubscribe(notify)
+		return
+	}
+	gn.stop()
+	o.Observable.Unsubscribe(gn)
+}
+
+// pendingRemoval is a removed endpoint that hasn't been reported to the wrapped Notify yet.
+type pendingRemoval struct {
+	timer    *time.Timer
+	endpoint observer.Endpoint
+}
+
+type gracefulNotify struct {
+	observer.Notify
+	pending     map[observer.EndpointID]*pendingRemoval
+	gracePeriod time.Duration
+	mu          sync.Mutex
+}
+
+func (n *gracefulNotify) OnAdd(added []observer.Endpoint) {
+	var toAdd, toChange []observer.Endpoint
+	n.mu.Lock()
+	for _, endpoint := range added {
+		p, ok := n.pending[endpoint.ID]
+		if !ok {
+			toAdd = append(toAdd, endpoint)
+			continue
+		}
+		p.timer.Stop()
+		delete(n.pending, endpoint.ID)
+		// the wrapped Notify was never told of the removal so only report actual changes
+		if !reflect.DeepEqual(p.endpoint, endpoint) {
+			toChange = append(toChange, endpoint)
+		}
+	}
+	n.mu.Unlock()
+	if len(toAdd) > 0 {
+		n.Notify.OnAdd(toAdd)
+	}
+	if len(toChange) > 0 {
+		n.Notify.OnChange(toChange)
+	}
+}
+
+func (n *gracefulNotify) OnRemove(removed []observer.Endpoint) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, endpoint := range removed {
+		if _, ok := n.pending[endpoint.ID]; ok {
+			continue
+		}
+		p := &pendingRemoval{endpoint: endpoint}
+		p.timer = time.AfterFunc(n.gracePeriod, func() {
+			n.mu.Lock()
+			// the endpoint may have been added again while this timer was firing
+			current, ok := n.pending[p.endpoint.ID]
+			ok = ok && current == p
+			if ok {
+				delete(n.pending, p.endpoint.ID)
+			}
+			n.mu.Unlock()
+			if ok {
+				n.Notify.OnRemove([]observer.Endpoint{p.endpoint})
+			}
+		})
+		n.pending[endpoint.ID] = p
+	}
+}
+
+// stop cancels all pending removals.
+func (n *gracefulNotify) stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, p := range n.pending {
+		p.timer.Stop()
+		delete(n.pending, id)
+	}
+}
+
+// gracefulHost provides the internal receiver_creator with the graceful observables
+// in place of the configured observer extensions.
+type gracefulHost struct {
+	component.Host
+	extensions map[component.ID]extension.Extension
+}
+
+func newGracefulHost(host component.Host, observables map[component.ID]*gracefulObservable) *gracefulHost {
+	extensions := map[component.ID]extension.Extension{}
+	for id, ext := range host.GetExtensions() {
+		extensions[id] = ext
+	}
+	for id, observable := range observables {
+		if ext, ok := extensions[id]; ok {
+			extensions[id] = gracefulExtension{Extension: ext, gracefulObservable: observable}
+		}
+	}
+	return &gracefulHost{Host: host, extensions: extensions}
+}
+
+func (h *gracefulHost) GetExtensions() map[component.ID]extension.Extension {
+	return h.extensions
+}
+
+func (h *gracefulHost) GetFactory(kind component.Kind, componentType component.Type) component.Factory {
+	if fh, ok := h.Host.(interface {
+		GetFactory(component.Kind, component.Type) component.Factory
+	}); ok {
+		return fh.GetFactory(kind, componentType)
+	}
+	return nil
+}
+
+// gracefulExtension is an observer extension whose Observable methods are provided by a gracefulObservable.
+type gracefulExtension struct {
+	extension.Extension
+	*gracefulObservable
+}
@@ -0,0 +1,151 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoveryreceiver
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+)
+
+type capturingObservable struct {
+	notify       observer.Notify
+	unsubscribed observer.Notify
+}
+
+func (c *capturingObservable) ListAndWatch(notify observer.Notify) {
+	c.notify = notify
+}
+
+func (c *capturingObservable) Unsubscribe(notify observer.Notify) {
+	c.unsubscribed = notify
+}
+
+type recordingNotify struct {
+	added, removed, changed []observer.Endpoint
+	mu                      sync.Mutex
+}
+
+func (r *recordingNotify) ID() observer.NotifyID {
+	return "recording"
+}
+
+func (r *recordingNotify) OnAdd(added []observer.Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.added = append(r.added, added...)
+}
+
+func (r *recordingNotify) OnRemove(removed []observer.Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removed = append(r.removed, removed...)
+}
+
+func (r *recordingNotify) OnChange(changed []observer.Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changed = append(r.changed, changed...)
+}
+
+func (r *recordingNotify) counts() (added, removed, changed int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.added), len(r.removed), len(r.changed)
+}
+
+func TestGracefulObservableDelaysRemoval(t *testing.T) {
+	underlying := &capturingObservable{}
+	observable := newGracefulObservable(underlying, 50*time.Millisecond)
+	recorder := &recordingNotify{}
+	observable.ListAndWatch(recorder)
+	require.NotNil(t, underlying.notify)
+	require.Equal(t, recorder.ID(), underlying.notify.ID())
+
+	endpoint := observer.Endpoint{ID: "endpoint.id", Target: "localhost:1234"}
+	underlying.notify.OnAdd([]observer.Endpoint{endpoint})
+	underlying.notify.OnRemove([]observer.Endpoint{endpoint})
+
+	added, removed, _ := recorder.counts()
+	assert.Equal(t, 1, added)
+	assert.Zero(t, removed)
+
+	require.Eventually(t, func() bool {
+		_, removed, _ = recorder.counts()
+		return removed == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []observer.Endpoint{endpoint}, recorder.removed)
+}
+
+func TestGracefulObservableReaddedEndpoint(t *testing.T) {
+	underlying := &capturingObservable{}
+	observable := newGracefulObservable(underlying, time.Hour)
+	recorder := &recordingNotify{}
+	observable.ListAndWatch(recorder)
+
+	endpoint := observer.Endpoint{ID: "endpoint.id", Target: "localhost:1234"}
+	underlying.notify.OnAdd([]observer.Endpoint{endpoint})
+
+	// unchanged endpoints are never reported as removed or added again
+	underlying.notify.OnRemove([]observer.Endpoint{endpoint})
+	underlying.notify.OnAdd([]observer.Endpoint{endpoint})
+	added, removed, changed := recorder.counts()
+	assert.Equal(t, 1, added)
+	assert.Zero(t, removed)
+	assert.Zero(t, changed)
+
+	// changed endpoints are reported as such
+	underlying.notify.OnRemove([]observer.Endpoint{endpoint})
+	changedEndpoint := observer.Endpoint{ID: "endpoint.id", Target: "localhost:4321"}
+	underlying.notify.OnAdd([]observer.Endpoint{changedEndpoint})
+	added, removed, changed = recorder.counts()
+	assert.Equal(t, 1, added)
+	assert.Zero(t, removed)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, []observer.Endpoint{changedEndpoint}, recorder.changed)
+
+	underlying.notify.OnRemove([]observer.Endpoint{changedEndpoint})
+	observable.Unsubscribe(recorder)
+	assert.Same(t, underlying.notify, underlying.unsubscribed)
+	assert.Empty(t, underlying.notify.(*gracefulNotify).pending)
+}
+
+func TestGracefulHost(t *testing.T) {
+	observableID := component.MustNewID("nop_observable")
+	otherID := component.MustNewID("nop_observer")
+	nopObsvble := &nopObservable{}
+	nopObs := &nopObserver{}
+	host := mockHost{extensions: map[component.ID]extension.Extension{
+		observableID: nopObsvble,
+		otherID:      nopObs,
+	}}
+	observable := newGracefulObservable(nopObsvble, time.Minute)
+	gh := newGracefulHost(host, map[component.ID]*gracefulObservable{observableID: observable})
+
+	extensions := gh.GetExtensions()
+	require.Len(t, extensions, 2)
+	assert.Same(t, nopObs, extensions[otherID])
+	wrapped, ok := extensions[observableID].(observer.Observable)
+	require.True(t, ok)
+	assert.Equal(t, gracefulExtension{Extension: nopObsvble, gracefulObservable: observable}, wrapped)
+	// the configured extensions are unchanged
+	assert.Same(t, nopObsvble, host.GetExtensions()[observableID])
+}
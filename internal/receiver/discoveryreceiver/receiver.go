@@ -26,16 +26,21 @@ import (
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	mnoop "go.opentelemetry.io/otel/metric/noop"
 	tnoop "go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/status"
 )
 
 const (
 	observerNameAttr = "discovery.observer.name"
 	observerTypeAttr = "discovery.observer.type"
 	matchedLogAttr   = "discovery.matched_log"
+
+	scopeName = "github.com/signalfx/splunk-otel-collector/internal/receiver/discoveryreceiver"
 )
 
 var (
@@ -58,6 +63,8 @@ type discoveryReceiver struct {
 	observables         map[component.ID]observer.Observable
 	loopFinished        *sync.WaitGroup
 	settings            receiver.Settings
+	// unregisterStatus removes the discovered endpoints from the status document.
+	unregisterStatus func()
 }
 
 func newDiscoveryReceiver(
@@ -92,6 +99,16 @@ func (d *discoveryReceiver) Start(ctx context.Context, host component.Host) (err
 		return fmt.Errorf("failed obtaining observables from host: %w", err)
 	}
 
+	if d.config.EndpointRemovalGracePeriod > 0 {
+		gracefulObservables := map[component.ID]*gracefulObservable{}
+		for id, observable := range d.observables {
+			gracefulObservables[id] = newGracefulObservable(observable, d.config.EndpointRemovalGracePeriod)
+			d.observables[id] = gracefulObservables[id]
+		}
+		// the internal receiver_creator must observe the same delayed removals
+		host = newGracefulHost(host, gracefulObservables)
+	}
+
 	var correlations *correlationStore
 	if d.nextLogsConsumer != nil {
 		correlations = newCorrelationStore(d.logger, d.config.CorrelationTTL)
@@ -103,6 +120,11 @@ func (d *discoveryReceiver) Start(ctx context.Context, host component.Host) (err
 		if d.statementEvaluator, err = newStatementEvaluator(d.logger, d.settings.ID, d.config, correlations); err != nil {
 			return fmt.Errorf("failed creating statement evaluator: %w", err)
 		}
+
+		if err = d.registerEndpointTelemetry(correlations); err != nil {
+			return fmt.Errorf("failed registering endpoint telemetry: %w", err)
+		}
+		d.unregisterStatus = status.RegisterDiscovery(d.settings.ID, correlations.DiscoveredEndpoints)
 	}
 
 	d.metricsConsumer = newMetricsConsumer(d.logger, d.config, correlations, d.nextMetricsConsumer)
@@ -130,6 +152,9 @@ func (d *discoveryReceiver) Start(ctx context.Context, host component.Host) (err
 }
 
 func (d *discoveryReceiver) Shutdown(ctx context.Context) error {
+	if d.unregisterStatus != nil {
+		d.unregisterStatus()
+	}
 	if d.endpointTracker != nil {
 		d.endpointTracker.stop()
 		d.logger.Debug("discovery receiver shutting down")
@@ -205,6 +230,28 @@ func (d *discoveryReceiver) createAndSetReceiverCreator() error {
 	return nil
 }
 
+// registerEndpointTelemetry reports the number of active endpoints and of removed endpoints
+// retained until the correlation_ttl elapses.
+func (d *discoveryReceiver) registerEndpointTelemetry(correlations *correlationStore) error {
+	if d.settings.TelemetrySettings.LeveledMeterProvider == nil {
+		return nil
+	}
+	meter := d.settings.TelemetrySettings.LeveledMeterProvider(configtelemetry.LevelBasic).Meter(scopeName)
+	receiverAttr := attribute.String("receiver", d.settings.ID.String())
+	_, err := meter.Int64ObservableGauge(
+		"discovery_receiver_endpoints",
+		metric.WithDescription("Number of endpoints tracked by the discovery receiver by state (active or removed)."),
+		metric.WithUnit("{endpoints}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			active, removed := correlations.EndpointCounts()
+			o.Observe(int64(active), metric.WithAttributes(receiverAttr, attribute.String("state", "active")))
+			o.Observe(int64(removed), metric.WithAttributes(receiverAttr, attribute.String("state", "removed")))
+			return nil
+		}),
+	)
+	return err
+}
+
 // observablesFromHost finds configured `watch_observers` extension instances from the host
 // by their ComponentID. It is based on the equivalent logic in the Receiver Creator:
 // https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/d6042eda45ec9d8a5df1ae553388eaca67d9d16c/receiver/receivercreator/receiver.go#L79
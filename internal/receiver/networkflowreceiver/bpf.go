@@ -0,0 +1,376 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The program traces the sock:inet_sock_set_state tracepoint, which reports the TCP state changes
+// of sockets, into a hash map from the addresses and ports of a connection to its value:
+//
+//	struct connection_key {
+//	        __u8  saddr[16];      // local address, IPv4 addresses are IPv4-mapped
+//	        __u8  daddr[16];      // remote address
+//	        __u16 sport;          // local port
+//	        __u16 dport;          // remote port
+//	        __u32 pad;
+//	};
+//
+//	struct connection {
+//	        __u8  saddr[16];
+//	        __u8  daddr[16];
+//	        __u16 sport;
+//	        __u16 dport;
+//	        __u16 family;
+//	        __u8  direction;
+//	        __u8  pad;
+//	        __u64 established_ns; // monotonic time the connection was established
+//	        __u64 closed_ns;      // monotonic time the connection was closed, 0 while open
+//	};
+//
+// Connections are added when established, from SYN_SENT for outbound and from SYN_RECV for inbound
+// connections, and marked closed once they're closed. Connections are keyed by their addresses and
+// ports rather than their socket, since the memory of a closed socket is reused by new sockets before
+// the closed connection is collected. Only the addresses and ports of connections are read, never
+// their payloads.
+const (
+	connectionKeySize   = 40
+	connectionValueSize = 56
+
+	offsetKeyPad = 36
+
+	offsetSaddr         = 0
+	offsetDaddr         = 16
+	offsetSport         = 32
+	offsetDport         = 34
+	offsetFamily        = 36
+	offsetDirection     = 38
+	offsetPad           = 39
+	offsetEstablishedNS = 40
+	offsetClosedNS      = 48
+
+	directionOutbound = 1
+	directionInbound  = 2
+
+	ipprotoTCP = 6
+
+	// TCP states, include/net/tcp_states.h
+	tcpEstablished = 1
+	tcpSynSent     = 2
+	tcpSynRecv     = 3
+	tcpClose       = 7
+)
+
+// tracepointFields are the fields of the tracepoint read by the program.
+var tracepointFields = []string{"oldstate", "newstate", "sport", "dport", "family", "protocol", "saddr_v6", "daddr_v6"}
+
+// eBPF instruction encoding, include/uapi/linux/bpf.h and bpf_common.h
+const (
+	bpfLD    = 0x00
+	bpfLDX   = 0x01
+	bpfST    = 0x02
+	bpfSTX   = 0x03
+	bpfJMP   = 0x05
+	bpfALU64 = 0x07
+
+	bpfW  = 0x00
+	bpfH  = 0x08
+	bpfB  = 0x10
+	bpfDW = 0x18
+
+	bpfIMM = 0x00
+	bpfMEM = 0x60
+
+	bpfK = 0x00
+	bpfX = 0x08
+
+	bpfADD = 0x00
+	bpfMOV = 0xb0
+
+	bpfJA   = 0x00
+	bpfJEQ  = 0x10
+	bpfJNE  = 0x50
+	bpfCALL = 0x80
+	bpfEXIT = 0x90
+
+	bpfPseudoMapFD = 1
+
+	// helper functions
+	bpfMapLookupElemFn = 1
+	bpfMapUpdateElemFn = 2
+	bpfKtimeGetNsFn    = 5
+
+	bpfAny = 0
+)
+
+// registers
+const (
+	r0 uint8 = iota
+	r1
+	r2
+	r3
+	r4
+	r5
+	r6
+	r7
+	r8
+	r9
+	r10
+)
+
+// bpfInsn is an eBPF instruction, struct bpf_insn.
+type bpfInsn struct {
+	code uint8
+	dst  uint8
+	src  uint8
+	off  int16
+	imm  int32
+}
+
+func (i bpfInsn) marshal(b []byte) {
+	b[0] = i.code
+	b[1] = i.src<<4 | i.dst&0x0f
+	binary.LittleEndian.PutUint16(b[2:], uint16(i.off))
+	binary.LittleEndian.PutUint32(b[4:], uint32(i.imm))
+}
+
+// marshalInsns returns the instructions in the layout of the bpf syscall.
+func marshalInsns(insns []bpfInsn) []byte {
+	b := make([]byte, 8*len(insns))
+	for i, insn := range insns {
+		insn.marshal(b[8*i:])
+	}
+	return b
+}
+
+// assembler builds a program, resolving the jumps to its labels.
+type assembler struct {
+	labels map[string]int
+	jumps  map[int]string
+	insns  []bpfInsn
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: map[string]int{}, jumps: map[int]string{}}
+}
+
+func (a *assembler) emit(insn bpfInsn) {
+	a.insns = append(a.insns, insn)
+}
+
+func (a *assembler) label(name string) {
+	a.labels[name] = len(a.insns)
+}
+
+func (a *assembler) movImm(dst uint8, imm int32) {
+	a.emit(bpfInsn{code: bpfALU64 | bpfMOV | bpfK, dst: dst, imm: imm})
+}
+
+func (a *assembler) movReg(dst, src uint8) {
+	a.emit(bpfInsn{code: bpfALU64 | bpfMOV | bpfX, dst: dst, src: src})
+}
+
+func (a *assembler) addImm(dst uint8, imm int32) {
+	a.emit(bpfInsn{code: bpfALU64 | bpfADD | bpfK, dst: dst, imm: imm})
+}
+
+// load loads the size bytes at src+off into dst.
+func (a *assembler) load(size, dst, src uint8, off int16) {
+	a.emit(bpfInsn{code: bpfLDX | bpfMEM | size, dst: dst, src: src, off: off})
+}
+
+// store stores the size bytes of src at dst+off.
+func (a *assembler) store(size, dst uint8, off int16, src uint8) {
+	a.emit(bpfInsn{code: bpfSTX | bpfMEM | size, dst: dst, src: src, off: off})
+}
+
+// storeImm stores the size bytes of imm at dst+off.
+func (a *assembler) storeImm(size, dst uint8, off int16, imm int32) {
+	a.emit(bpfInsn{code: bpfST | bpfMEM | size, dst: dst, off: off, imm: imm})
+}
+
+// loadMapFD loads the map into dst, which takes two instructions.
+func (a *assembler) loadMapFD(dst uint8, fd int) {
+	a.emit(bpfInsn{code: bpfLD | bpfIMM | bpfDW, dst: dst, src: bpfPseudoMapFD, imm: int32(fd)})
+	a.emit(bpfInsn{})
+}
+
+func (a *assembler) jump(op, dst uint8, imm int32, label string) {
+	a.jumps[len(a.insns)] = label
+	a.emit(bpfInsn{code: bpfJMP | op | bpfK, dst: dst, imm: imm})
+}
+
+func (a *assembler) call(fn int32) {
+	a.emit(bpfInsn{code: bpfJMP | bpfCALL, imm: fn})
+}
+
+func (a *assembler) exit() {
+	a.emit(bpfInsn{code: bpfJMP | bpfEXIT})
+}
+
+func (a *assembler) assemble() ([]bpfInsn, error) {
+	for i, label := range a.jumps {
+		target, ok := a.labels[label]
+		if !ok {
+			return nil, fmt.Errorf("undefined label %q", label)
+		}
+		a.insns[i].off = int16(target - i - 1)
+	}
+	return a.insns, nil
+}
+
+// tracepointField is a field of the tracepoint's context.
+type tracepointField struct {
+	offset int
+	size   int
+}
+
+var formatFieldRegexp = regexp.MustCompile(`field:([^;]+);\s*offset:(\d+);\s*size:(\d+);`)
+
+// parseTracepointFormat parses the fields from the format file of a tracepoint, e.g.
+// "field:__u16 sport;	offset:24;	size:2;	signed:0;". Array fields are named without their length.
+func parseTracepointFormat(r io.Reader) (map[string]tracepointField, error) {
+	fields := map[string]tracepointField{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := formatFieldRegexp.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		decl := strings.Fields(m[1])
+		name, _, _ := strings.Cut(decl[len(decl)-1], "[")
+		offset, _ := strconv.Atoi(m[2])
+		size, _ := strconv.Atoi(m[3])
+		fields[name] = tracepointField{offset: offset, size: size}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, name := range tracepointFields {
+		if _, ok := fields[name]; !ok {
+			return nil, fmt.Errorf("tracepoint has no %s field", name)
+		}
+	}
+	return fields, nil
+}
+
+// buildProgram returns the program tracing the connections into the map with the tracepoint's fields.
+func buildProgram(fields map[string]tracepointField, mapFD int) ([]bpfInsn, error) {
+	// loads from the context must be aligned to their size, the addresses are loaded in words
+	for _, check := range []struct {
+		name   string
+		size   int
+		access int
+	}{
+		{"oldstate", 4, 4}, {"newstate", 4, 4}, {"sport", 2, 2}, {"dport", 2, 2}, {"family", 2, 2},
+		{"saddr_v6", 16, 4}, {"daddr_v6", 16, 4},
+	} {
+		if f := fields[check.name]; f.size != check.size || f.offset%check.access != 0 {
+			return nil, fmt.Errorf("unexpected layout of the tracepoint's %s field", check.name)
+		}
+	}
+	// the protocol is a __u8 on older and a __u16 on newer kernels
+	var protocolSize uint8
+	switch protocol := fields["protocol"]; {
+	case protocol.size == 1:
+		protocolSize = bpfB
+	case protocol.size == 2 && protocol.offset%2 == 0:
+		protocolSize = bpfH
+	default:
+		return nil, errors.New("unexpected layout of the tracepoint's protocol field")
+	}
+	off := func(name string) int16 { return int16(fields[name].offset) }
+
+	// the key is at r10-96 and the value at r10-56 on the stack
+	const (
+		value = -connectionValueSize
+		key   = value - connectionKeySize
+	)
+	a := newAssembler()
+	a.movReg(r6, r1)
+	a.load(protocolSize, r1, r6, off("protocol"))
+	a.jump(bpfJNE, r1, ipprotoTCP, "out")
+	a.load(bpfW, r7, r6, off("newstate"))
+	a.jump(bpfJEQ, r7, tcpClose, "key")
+	a.jump(bpfJNE, r7, tcpEstablished, "out")
+	a.load(bpfW, r8, r6, off("oldstate"))
+	a.movImm(r9, directionOutbound)
+	a.jump(bpfJEQ, r8, tcpSynSent, "key")
+	a.movImm(r9, directionInbound)
+	a.jump(bpfJNE, r8, tcpSynRecv, "out")
+
+	a.label("key")
+	for i := int16(0); i < 16; i += 4 {
+		a.load(bpfW, r1, r6, off("saddr_v6")+i)
+		a.store(bpfW, r10, key+offsetSaddr+i, r1)
+		a.load(bpfW, r1, r6, off("daddr_v6")+i)
+		a.store(bpfW, r10, key+offsetDaddr+i, r1)
+	}
+	a.load(bpfH, r1, r6, off("sport"))
+	a.store(bpfH, r10, key+offsetSport, r1)
+	a.load(bpfH, r1, r6, off("dport"))
+	a.store(bpfH, r10, key+offsetDport, r1)
+	a.storeImm(bpfW, r10, key+offsetKeyPad, 0)
+	a.jump(bpfJEQ, r7, tcpClose, "close")
+
+	for i := int16(0); i < 16; i += 4 {
+		a.load(bpfW, r1, r6, off("saddr_v6")+i)
+		a.store(bpfW, r10, value+offsetSaddr+i, r1)
+		a.load(bpfW, r1, r6, off("daddr_v6")+i)
+		a.store(bpfW, r10, value+offsetDaddr+i, r1)
+	}
+	a.load(bpfH, r1, r6, off("sport"))
+	a.store(bpfH, r10, value+offsetSport, r1)
+	a.load(bpfH, r1, r6, off("dport"))
+	a.store(bpfH, r10, value+offsetDport, r1)
+	a.load(bpfH, r1, r6, off("family"))
+	a.store(bpfH, r10, value+offsetFamily, r1)
+	a.store(bpfB, r10, value+offsetDirection, r9)
+	a.storeImm(bpfB, r10, value+offsetPad, 0)
+	a.call(bpfKtimeGetNsFn)
+	a.store(bpfDW, r10, value+offsetEstablishedNS, r0)
+	a.storeImm(bpfDW, r10, value+offsetClosedNS, 0)
+	a.loadMapFD(r1, mapFD)
+	a.movReg(r2, r10)
+	a.addImm(r2, key)
+	a.movReg(r3, r10)
+	a.addImm(r3, value)
+	a.movImm(r4, bpfAny)
+	a.call(bpfMapUpdateElemFn)
+	a.jump(bpfJA, 0, 0, "out")
+
+	a.label("close")
+	a.loadMapFD(r1, mapFD)
+	a.movReg(r2, r10)
+	a.addImm(r2, key)
+	a.call(bpfMapLookupElemFn)
+	a.jump(bpfJEQ, r0, 0, "out")
+	a.movReg(r7, r0)
+	a.call(bpfKtimeGetNsFn)
+	a.store(bpfDW, r7, offsetClosedNS, r0)
+
+	a.label("out")
+	a.movImm(r0, 0)
+	a.exit()
+	return a.assemble()
+}
@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTracepointFormat(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "inet_sock_set_state_format"))
+	require.NoError(t, err)
+	defer f.Close()
+	fields, err := parseTracepointFormat(f)
+	require.NoError(t, err)
+	assert.Equal(t, tracepointField{offset: 30, size: 2}, fields["protocol"])
+	assert.Equal(t, tracepointField{offset: 24, size: 2}, fields["sport"])
+	assert.Equal(t, tracepointField{offset: 40, size: 16}, fields["saddr_v6"])
+
+	_, err = parseTracepointFormat(strings.NewReader("field:int oldstate;	offset:16;	size:4;	signed:1;"))
+	require.EqualError(t, err, "tracepoint has no newstate field")
+}
+
+func TestBuildProgram(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "inet_sock_set_state_format"))
+	require.NoError(t, err)
+	defer f.Close()
+	fields, err := parseTracepointFormat(f)
+	require.NoError(t, err)
+
+	insns, err := buildProgram(fields, 42)
+	require.NoError(t, err)
+	for i, insn := range insns {
+		if insn.code&0x07 == bpfJMP && insn.code != bpfJMP|bpfCALL && insn.code != bpfJMP|bpfEXIT {
+			target := i + 1 + int(insn.off)
+			assert.True(t, target > i && target < len(insns), "jump %d out of the program", i)
+		}
+		if insn.code == bpfLD|bpfIMM|bpfDW {
+			assert.Equal(t, int32(42), insn.imm)
+			assert.Equal(t, uint8(bpfPseudoMapFD), insn.src)
+		}
+	}
+	// the __u16 protocol is loaded as a half word
+	assert.Equal(t, bpfInsn{code: bpfLDX | bpfMEM | bpfH, dst: r1, src: r6, off: 30}, insns[1])
+	// the program ends with r0 = 0 and exit
+	assert.Equal(t, bpfInsn{code: bpfALU64 | bpfMOV | bpfK}, insns[len(insns)-2])
+	assert.Equal(t, bpfInsn{code: bpfJMP | bpfEXIT}, insns[len(insns)-1])
+
+	unaligned := map[string]tracepointField{}
+	for name, field := range fields {
+		unaligned[name] = field
+	}
+	unaligned["oldstate"] = tracepointField{offset: 18, size: 4}
+	_, err = buildProgram(unaligned, 42)
+	require.EqualError(t, err, "unexpected layout of the tracepoint's oldstate field")
+
+	// older kernels have a __u8 protocol
+	byteProtocol := map[string]tracepointField{}
+	for name, field := range fields {
+		byteProtocol[name] = field
+	}
+	byteProtocol["protocol"] = tracepointField{offset: 31, size: 1}
+	insns, err = buildProgram(byteProtocol, 42)
+	require.NoError(t, err)
+	assert.Equal(t, bpfInsn{code: bpfLDX | bpfMEM | bpfB, dst: r1, src: r6, off: 31}, insns[1])
+}
+
+func TestMarshalInsns(t *testing.T) {
+	b := marshalInsns([]bpfInsn{
+		{code: bpfLDX | bpfMEM | bpfW, dst: r7, src: r6, off: 20},
+		{code: bpfJMP | bpfJEQ | bpfK, dst: r7, off: -3, imm: 7},
+	})
+	assert.Equal(t, []byte{
+		0x61, 0x67, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x15, 0x07, 0xfd, 0xff, 0x07, 0x00, 0x00, 0x00,
+	}, b)
+}
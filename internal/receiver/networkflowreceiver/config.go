@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// maxMaxConnections bounds the memory of the connections map, about 100 bytes per connection.
+const maxMaxConnections = 1 << 24
+
+// Config defines the collection of the TCP connections of the host.
+type Config struct {
+	// WatchObservers are the observer extensions whose endpoints resolve the peers of connections
+	// to the services they belong to.
+	WatchObservers []component.ID `mapstructure:"watch_observers"`
+	// CollectionInterval is the interval at which the connections are collected.
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+	// MaxConnections is the number of connections tracked between collections. Connections
+	// established while the limit is reached aren't tracked.
+	MaxConnections int `mapstructure:"max_connections"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.CollectionInterval <= 0 {
+		errs = errors.Join(errs, errors.New("collection_interval must be positive"))
+	}
+	if cfg.MaxConnections <= 0 {
+		errs = errors.Join(errs, errors.New("max_connections must be positive"))
+	} else if cfg.MaxConnections > maxMaxConnections {
+		errs = errors.Join(errs, fmt.Errorf("max_connections must be at most %d", maxMaxConnections))
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/sharedcomponent"
+)
+
+const (
+	typeStr   = "network_flow"
+	stability = component.StabilityLevelDevelopment
+)
+
+// receivers are the created receivers by their config, so metrics and logs pipelines share the
+// tracking of the connections.
+var receivers = sharedcomponent.NewSharedComponents()
+
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithMetrics(createMetricsReceiver, stability),
+		receiver.WithLogs(createLogsReceiver, stability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		CollectionInterval: 10 * time.Second,
+		MaxConnections:     65536,
+	}
+}
+
+func createMetricsReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (receiver.Metrics, error) {
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		return newFlowReceiver(cfg.(*Config), set)
+	})
+	r.Unwrap().(*flowReceiver).nextMetrics = nextConsumer
+	return r, nil
+}
+
+func createLogsReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
+) (receiver.Logs, error) {
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		return newFlowReceiver(cfg.(*Config), set)
+	})
+	r.Unwrap().(*flowReceiver).nextLogs = nextConsumer
+	return r, nil
+}
@@ -0,0 +1,217 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	directionAttr     = "network.flow.direction"
+	localAddressAttr  = "network.local.address"
+	localPortAttr     = "network.local.port"
+	peerAddressAttr   = "network.peer.address"
+	peerPortAttr      = "network.peer.port"
+	serverPortAttr    = "server.port"
+	networkTypeAttr   = "network.type"
+	networkTransAttr  = "network.transport"
+	peerServiceAttr   = "peer.service"
+	flowDurationAttr  = "network.flow.duration"
+	openedMetric      = "network.flow.connections.opened"
+	closedMetric      = "network.flow.connections.closed"
+	activeMetric      = "network.flow.connections.active"
+	durationMetric    = "network.flow.connection.duration"
+	connectionsUnit   = "{connections}"
+	inboundDirection  = "inbound"
+	outboundDirection = "outbound"
+)
+
+// flowKey identifies a flow, the connections between a local address and a remote address to the
+// same server port, in the same direction. The client ports are left out, since clients use a new
+// ephemeral port for each connection.
+type flowKey struct {
+	localAddr  netip.Addr
+	remoteAddr netip.Addr
+	serverPort uint16
+	inbound    bool
+}
+
+// flowStats are the statistics of a flow in a collection interval.
+type flowStats struct {
+	opened   int64
+	closed   int64
+	active   int64
+	duration time.Duration
+}
+
+// flowTable aggregates the collected connections into flows.
+type flowTable struct {
+	// open are the connections that were open at the previous collection.
+	open map[uint64]struct{}
+}
+
+func newFlowTable() *flowTable {
+	return &flowTable{open: map[uint64]struct{}{}}
+}
+
+// update returns the statistics of the flows of the collected connections since the previous
+// collection.
+func (t *flowTable) update(conns []connection) map[flowKey]*flowStats {
+	flows := map[flowKey]*flowStats{}
+	for _, c := range conns {
+		key := flowKey{localAddr: c.localAddr, remoteAddr: c.remoteAddr, serverPort: c.serverPort(), inbound: c.inbound}
+		stats, ok := flows[key]
+		if !ok {
+			stats = &flowStats{}
+			flows[key] = stats
+		}
+		if _, ok := t.open[c.id]; !ok {
+			stats.opened++
+		}
+		if c.closed {
+			delete(t.open, c.id)
+			stats.closed++
+			stats.duration += c.duration
+			continue
+		}
+		t.open[c.id] = struct{}{}
+		stats.active++
+	}
+	return flows
+}
+
+// flowAttributes sets the attributes identifying the flow and the service of its peer, if resolved.
+func flowAttributes(attrs pcommon.Map, key flowKey, peerService string) {
+	direction := outboundDirection
+	if key.inbound {
+		direction = inboundDirection
+	}
+	attrs.PutStr(directionAttr, direction)
+	attrs.PutStr(localAddressAttr, key.localAddr.String())
+	attrs.PutStr(peerAddressAttr, key.remoteAddr.String())
+	attrs.PutInt(serverPortAttr, int64(key.serverPort))
+	attrs.PutStr(networkTransAttr, "tcp")
+	if key.localAddr.Is4() {
+		attrs.PutStr(networkTypeAttr, "ipv4")
+	} else {
+		attrs.PutStr(networkTypeAttr, "ipv6")
+	}
+	if peerService != "" {
+		attrs.PutStr(peerServiceAttr, peerService)
+	}
+}
+
+// peerService resolves the service of the peer of a flow. The servers of outbound flows are
+// resolved by their address and port, the clients of inbound flows by their address.
+func peerService(peers *peerResolver, key flowKey) string {
+	if key.inbound {
+		return peers.resolve(key.remoteAddr, 0)
+	}
+	return peers.resolve(key.remoteAddr, key.serverPort)
+}
+
+// buildMetrics returns the metrics of the flows, with the collection interval from start to now.
+func buildMetrics(flows map[flowKey]*flowStats, peers *peerResolver, start, now pcommon.Timestamp) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	opened := newDeltaSum(ms.AppendEmpty(), openedMetric, "The number of TCP connections established.", connectionsUnit)
+	closed := newDeltaSum(ms.AppendEmpty(), closedMetric, "The number of TCP connections closed.", connectionsUnit)
+	duration := newDeltaSum(ms.AppendEmpty(), durationMetric, "The total duration of the closed TCP connections.", "s")
+	active := ms.AppendEmpty()
+	active.SetName(activeMetric)
+	active.SetDescription("The number of open TCP connections.")
+	active.SetUnit(connectionsUnit)
+	activeDps := active.SetEmptyGauge().DataPoints()
+
+	for key, stats := range flows {
+		service := peerService(peers, key)
+		if stats.opened > 0 {
+			dp := opened.AppendEmpty()
+			dp.SetIntValue(stats.opened)
+			setDataPoint(dp, key, service, start, now)
+		}
+		if stats.closed > 0 {
+			dp := closed.AppendEmpty()
+			dp.SetIntValue(stats.closed)
+			setDataPoint(dp, key, service, start, now)
+			dp = duration.AppendEmpty()
+			dp.SetDoubleValue(stats.duration.Seconds())
+			setDataPoint(dp, key, service, start, now)
+		}
+		if stats.active > 0 {
+			dp := activeDps.AppendEmpty()
+			dp.SetIntValue(stats.active)
+			setDataPoint(dp, key, service, 0, now)
+		}
+	}
+	ms.RemoveIf(func(m pmetric.Metric) bool {
+		if m.Type() == pmetric.MetricTypeGauge {
+			return m.Gauge().DataPoints().Len() == 0
+		}
+		return m.Sum().DataPoints().Len() == 0
+	})
+	return md
+}
+
+func newDeltaSum(m pmetric.Metric, name, description, unit string) pmetric.NumberDataPointSlice {
+	m.SetName(name)
+	m.SetDescription(description)
+	m.SetUnit(unit)
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	return sum.DataPoints()
+}
+
+func setDataPoint(dp pmetric.NumberDataPoint, key flowKey, service string, start, now pcommon.Timestamp) {
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(now)
+	flowAttributes(dp.Attributes(), key, service)
+}
+
+// buildLogs returns a log record for each of the closed connections.
+func buildLogs(conns []connection, peers *peerResolver, now pcommon.Timestamp) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, c := range conns {
+		if !c.closed {
+			continue
+		}
+		key := flowKey{localAddr: c.localAddr, remoteAddr: c.remoteAddr, serverPort: c.serverPort(), inbound: c.inbound}
+		lr := lrs.AppendEmpty()
+		lr.SetTimestamp(now)
+		lr.SetObservedTimestamp(now)
+		lr.SetSeverityNumber(plog.SeverityNumberInfo)
+		local := netip.AddrPortFrom(c.localAddr, c.localPort)
+		remote := netip.AddrPortFrom(c.remoteAddr, c.remotePort)
+		client, server := local, remote
+		if c.inbound {
+			client, server = remote, local
+		}
+		lr.Body().SetStr(fmt.Sprintf("TCP connection %s -> %s closed after %s", client, server, c.duration))
+		attrs := lr.Attributes()
+		flowAttributes(attrs, key, peerService(peers, key))
+		attrs.PutInt(localPortAttr, int64(c.localPort))
+		attrs.PutInt(peerPortAttr, int64(c.remotePort))
+		attrs.PutDouble(flowDurationAttr, c.duration.Seconds())
+	}
+	return ld
+}
@@ -0,0 +1,150 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+var (
+	hostAddr   = netip.MustParseAddr("10.0.0.1")
+	dbAddr     = netip.MustParseAddr("10.0.0.2")
+	clientAddr = netip.MustParseAddr("10.0.0.3")
+)
+
+func outboundConn(id uint64, port uint16) connection {
+	return connection{id: id, localAddr: hostAddr, localPort: port, remoteAddr: dbAddr, remotePort: 5432}
+}
+
+func inboundConn(id uint64, port uint16) connection {
+	return connection{id: id, localAddr: hostAddr, localPort: 8080, remoteAddr: clientAddr, remotePort: port, inbound: true}
+}
+
+func closedConn(c connection, d time.Duration) connection {
+	c.closed = true
+	c.duration = d
+	return c
+}
+
+func TestFlowTable(t *testing.T) {
+	table := newFlowTable()
+	dbFlow := flowKey{localAddr: hostAddr, remoteAddr: dbAddr, serverPort: 5432}
+	clientFlow := flowKey{localAddr: hostAddr, remoteAddr: clientAddr, serverPort: 8080, inbound: true}
+
+	flows := table.update([]connection{
+		outboundConn(1, 40001),
+		outboundConn(2, 40002),
+		closedConn(outboundConn(3, 40003), time.Second),
+		inboundConn(4, 50001),
+	})
+	assert.Equal(t, map[flowKey]*flowStats{
+		dbFlow:     {opened: 3, closed: 1, active: 2, duration: time.Second},
+		clientFlow: {opened: 1, active: 1},
+	}, flows)
+
+	// connections open at the previous collection aren't opened again
+	flows = table.update([]connection{
+		outboundConn(1, 40001),
+		closedConn(outboundConn(2, 40002), 2*time.Second),
+		closedConn(inboundConn(4, 50001), 3*time.Second),
+	})
+	assert.Equal(t, map[flowKey]*flowStats{
+		dbFlow:     {closed: 1, active: 1, duration: 2 * time.Second},
+		clientFlow: {closed: 1, duration: 3 * time.Second},
+	}, flows)
+	assert.Equal(t, map[uint64]struct{}{1: {}}, table.open)
+}
+
+func TestBuildMetrics(t *testing.T) {
+	peers := newPeerResolver()
+	peers.OnAdd([]observer.Endpoint{{
+		ID:      "postgres",
+		Target:  "10.0.0.2:5432",
+		Details: &observer.Port{Pod: observer.Pod{Name: "postgres-0"}, Port: 5432},
+	}})
+	flows := newFlowTable().update([]connection{
+		outboundConn(1, 40001),
+		closedConn(outboundConn(2, 40002), 1500*time.Millisecond),
+	})
+	start := pcommon.NewTimestampFromTime(time.Unix(100, 0))
+	now := pcommon.NewTimestampFromTime(time.Unix(110, 0))
+	md := buildMetrics(flows, peers, start, now)
+
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 4, ms.Len())
+	byName := map[string]pmetric.NumberDataPoint{}
+	for i := 0; i < ms.Len(); i++ {
+		m := ms.At(i)
+		if m.Type() == pmetric.MetricTypeGauge {
+			byName[m.Name()] = m.Gauge().DataPoints().At(0)
+			continue
+		}
+		assert.Equal(t, pmetric.AggregationTemporalityDelta, m.Sum().AggregationTemporality())
+		byName[m.Name()] = m.Sum().DataPoints().At(0)
+	}
+	assert.Equal(t, int64(2), byName[openedMetric].IntValue())
+	assert.Equal(t, start, byName[openedMetric].StartTimestamp())
+	assert.Equal(t, now, byName[openedMetric].Timestamp())
+	assert.Equal(t, int64(1), byName[closedMetric].IntValue())
+	assert.Equal(t, int64(1), byName[activeMetric].IntValue())
+	assert.Equal(t, 1.5, byName[durationMetric].DoubleValue())
+	assert.Equal(t, map[string]any{
+		directionAttr:    "outbound",
+		localAddressAttr: "10.0.0.1",
+		peerAddressAttr:  "10.0.0.2",
+		serverPortAttr:   int64(5432),
+		networkTransAttr: "tcp",
+		networkTypeAttr:  "ipv4",
+		peerServiceAttr:  "postgres-0",
+	}, byName[openedMetric].Attributes().AsRaw())
+}
+
+func TestBuildMetricsWithoutClosedConnections(t *testing.T) {
+	flows := newFlowTable().update([]connection{inboundConn(1, 50001)})
+	md := buildMetrics(flows, newPeerResolver(), 0, 0)
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+	assert.Equal(t, openedMetric, ms.At(0).Name())
+	assert.Equal(t, activeMetric, ms.At(1).Name())
+}
+
+func TestBuildLogs(t *testing.T) {
+	ld := buildLogs([]connection{
+		inboundConn(1, 50001),
+		closedConn(inboundConn(2, 50002), 2*time.Second),
+	}, newPeerResolver(), pcommon.NewTimestampFromTime(time.Unix(110, 0)))
+	require.Equal(t, 1, ld.LogRecordCount())
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "TCP connection 10.0.0.3:50002 -> 10.0.0.1:8080 closed after 2s", lr.Body().Str())
+	assert.Equal(t, map[string]any{
+		directionAttr:    "inbound",
+		localAddressAttr: "10.0.0.1",
+		peerAddressAttr:  "10.0.0.3",
+		serverPortAttr:   int64(8080),
+		networkTransAttr: "tcp",
+		networkTypeAttr:  "ipv4",
+		localPortAttr:    int64(8080),
+		peerPortAttr:     int64(50002),
+		flowDurationAttr: 2.0,
+	}, lr.Attributes().AsRaw())
+}
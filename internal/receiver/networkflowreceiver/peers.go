@@ -0,0 +1,139 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+)
+
+var _ observer.Notify = (*peerResolver)(nil)
+
+// peerResolver resolves the addresses of peers to the services of the endpoints discovered by
+// the watched observers, e.g. pods, containers and host processes.
+type peerResolver struct {
+	endpoints  map[observer.EndpointID]peerEndpoint
+	byAddrPort map[netip.AddrPort]string
+	byAddr     map[netip.Addr]string
+	mu         sync.Mutex
+	// dirty is set when the endpoints changed since the indexes were built.
+	dirty bool
+}
+
+// peerEndpoint is the address, and port if any, of a service.
+type peerEndpoint struct {
+	addrPort netip.AddrPort
+	service  string
+}
+
+func newPeerResolver() *peerResolver {
+	return &peerResolver{endpoints: map[observer.EndpointID]peerEndpoint{}}
+}
+
+func (r *peerResolver) ID() observer.NotifyID {
+	return observer.NotifyID(fmt.Sprintf("%p::network_flow", r))
+}
+
+func (r *peerResolver) OnAdd(added []observer.Endpoint) {
+	r.update(added)
+}
+
+func (r *peerResolver) OnChange(changed []observer.Endpoint) {
+	r.update(changed)
+}
+
+func (r *peerResolver) OnRemove(removed []observer.Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range removed {
+		delete(r.endpoints, e.ID)
+	}
+	r.dirty = true
+}
+
+func (r *peerResolver) update(endpoints []observer.Endpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range endpoints {
+		if pe, ok := toPeerEndpoint(e); ok {
+			r.endpoints[e.ID] = pe
+		} else {
+			delete(r.endpoints, e.ID)
+		}
+	}
+	r.dirty = true
+}
+
+// resolve returns the service listening on the address and port, or of the address if port is 0
+// or no service is known to listen on the port.
+func (r *peerResolver) resolve(addr netip.Addr, port uint16) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dirty {
+		r.byAddrPort = map[netip.AddrPort]string{}
+		r.byAddr = map[netip.Addr]string{}
+		// addresses shared by several services, like the host address of host processes, are
+		// only resolved with their port
+		ambiguous := map[netip.Addr]bool{}
+		for _, pe := range r.endpoints {
+			if pe.addrPort.Port() != 0 {
+				r.byAddrPort[pe.addrPort] = pe.service
+			}
+			addr := pe.addrPort.Addr()
+			if service, ok := r.byAddr[addr]; ok && service != pe.service {
+				ambiguous[addr] = true
+			}
+			r.byAddr[addr] = pe.service
+		}
+		for addr := range ambiguous {
+			delete(r.byAddr, addr)
+		}
+		r.dirty = false
+	}
+	if port != 0 {
+		if service, ok := r.byAddrPort[netip.AddrPortFrom(addr, port)]; ok {
+			return service
+		}
+	}
+	return r.byAddr[addr]
+}
+
+// toPeerEndpoint returns the address and service of the endpoint, if its target is an IP address.
+func toPeerEndpoint(e observer.Endpoint) (peerEndpoint, bool) {
+	var service string
+	switch details := e.Details.(type) {
+	case *observer.Pod:
+		service = details.Name
+	case *observer.Port:
+		service = details.Pod.Name
+	case *observer.Container:
+		service = details.Name
+	case *observer.HostPort:
+		service = details.ProcessName
+	}
+	if service == "" {
+		return peerEndpoint{}, false
+	}
+	if addrPort, err := netip.ParseAddrPort(e.Target); err == nil {
+		return peerEndpoint{addrPort: netip.AddrPortFrom(addrPort.Addr().Unmap(), addrPort.Port()), service: service}, true
+	}
+	if addr, err := netip.ParseAddr(e.Target); err == nil {
+		return peerEndpoint{addrPort: netip.AddrPortFrom(addr.Unmap(), 0), service: service}, true
+	}
+	return peerEndpoint{}, false
+}
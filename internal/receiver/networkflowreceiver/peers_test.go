@@ -0,0 +1,50 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerResolver(t *testing.T) {
+	r := newPeerResolver()
+	r.OnAdd([]observer.Endpoint{
+		{ID: "pod", Target: "10.1.0.5", Details: &observer.Pod{Name: "frontend-7d9f8-abcde"}},
+		{ID: "port", Target: "10.1.0.6:6379", Details: &observer.Port{Pod: observer.Pod{Name: "redis-0"}, Port: 6379}},
+		{ID: "nginx", Target: "192.168.1.10:80", Details: &observer.HostPort{ProcessName: "nginx", Port: 80}},
+		{ID: "sshd", Target: "192.168.1.10:22", Details: &observer.HostPort{ProcessName: "sshd", Port: 22}},
+		{ID: "hostname", Target: "db.example.com:5432", Details: &observer.HostPort{ProcessName: "postgres", Port: 5432}},
+	})
+
+	assert.Equal(t, "frontend-7d9f8-abcde", r.resolve(netip.MustParseAddr("10.1.0.5"), 0))
+	assert.Equal(t, "redis-0", r.resolve(netip.MustParseAddr("10.1.0.6"), 6379))
+	assert.Equal(t, "redis-0", r.resolve(netip.MustParseAddr("10.1.0.6"), 0))
+	assert.Equal(t, "nginx", r.resolve(netip.MustParseAddr("192.168.1.10"), 80))
+	// the host address is shared by nginx and sshd
+	assert.Equal(t, "", r.resolve(netip.MustParseAddr("192.168.1.10"), 0))
+	assert.Equal(t, "", r.resolve(netip.MustParseAddr("10.1.0.7"), 80))
+
+	r.OnChange([]observer.Endpoint{
+		{ID: "port", Target: "10.1.0.6:6379", Details: &observer.Port{Pod: observer.Pod{Name: "redis-1"}, Port: 6379}},
+	})
+	assert.Equal(t, "redis-1", r.resolve(netip.MustParseAddr("10.1.0.6"), 6379))
+
+	r.OnRemove([]observer.Endpoint{{ID: "pod"}})
+	assert.Equal(t, "", r.resolve(netip.MustParseAddr("10.1.0.5"), 0))
+}
@@ -0,0 +1,138 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/observer"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+var _ receiver.Metrics = (*flowReceiver)(nil)
+
+// flowReceiver collects the TCP connections of the host at every collection interval and emits
+// the metrics of their flows and a log record for each closed connection.
+type flowReceiver struct {
+	nextMetrics consumer.Metrics
+	nextLogs    consumer.Logs
+	tracker     connectionTracker
+	newTracker  func(maxConnections int) (connectionTracker, error)
+	cfg         *Config
+	peers       *peerResolver
+	flows       *flowTable
+	cancel      context.CancelFunc
+	done        chan struct{}
+	observables []observer.Observable
+	set         receiver.Settings
+	lastCollect pcommon.Timestamp
+}
+
+func newFlowReceiver(cfg *Config, set receiver.Settings) *flowReceiver {
+	return &flowReceiver{
+		cfg:        cfg,
+		set:        set,
+		newTracker: newConnectionTracker,
+		peers:      newPeerResolver(),
+		flows:      newFlowTable(),
+	}
+}
+
+func (r *flowReceiver) Start(_ context.Context, host component.Host) error {
+	for _, id := range r.cfg.WatchObservers {
+		ext, ok := host.GetExtensions()[id]
+		if !ok {
+			return fmt.Errorf("failed to find observer %q as a configured extension", id)
+		}
+		observable, ok := ext.(observer.Observable)
+		if !ok {
+			return fmt.Errorf("extension %q in watch_observers is not an observer", id)
+		}
+		r.observables = append(r.observables, observable)
+	}
+
+	tracker, err := r.newTracker(r.cfg.MaxConnections)
+	if err != nil {
+		return fmt.Errorf("failed to track the TCP connections: %w", err)
+	}
+	r.tracker = tracker
+	for _, observable := range r.observables {
+		go observable.ListAndWatch(r.peers)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	r.lastCollect = pcommon.NewTimestampFromTime(time.Now())
+	go r.run(ctx)
+	return nil
+}
+
+func (r *flowReceiver) Shutdown(context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	<-r.done
+	for _, observable := range r.observables {
+		observable.Unsubscribe(r.peers)
+	}
+	return r.tracker.close()
+}
+
+func (r *flowReceiver) run(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.CollectionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.collect(ctx)
+		}
+	}
+}
+
+func (r *flowReceiver) collect(ctx context.Context) {
+	conns, err := r.tracker.connections()
+	if err != nil {
+		r.set.Logger.Warn("Failed to collect the TCP connections", zap.Error(err))
+		return
+	}
+	now := pcommon.NewTimestampFromTime(time.Now())
+	start := r.lastCollect
+	r.lastCollect = now
+	flows := r.flows.update(conns)
+
+	if r.nextMetrics != nil && len(flows) > 0 {
+		if err = r.nextMetrics.ConsumeMetrics(ctx, buildMetrics(flows, r.peers, start, now)); err != nil {
+			r.set.Logger.Warn("Failed to consume the TCP flow metrics", zap.Error(err))
+		}
+	}
+	if r.nextLogs != nil {
+		if ld := buildLogs(conns, r.peers, now); ld.LogRecordCount() > 0 {
+			if err = r.nextLogs.ConsumeLogs(ctx, ld); err != nil {
+				r.set.Logger.Warn("Failed to consume the TCP connection logs", zap.Error(err))
+			}
+		}
+	}
+}
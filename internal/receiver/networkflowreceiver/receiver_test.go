@@ -0,0 +1,88 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+type fakeTracker struct {
+	conns  [][]connection
+	mu     sync.Mutex
+	closed bool
+}
+
+func (f *fakeTracker) connections() ([]connection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.conns) == 0 {
+		return nil, nil
+	}
+	conns := f.conns[0]
+	f.conns = f.conns[1:]
+	return conns, nil
+}
+
+func (f *fakeTracker) close() error {
+	f.closed = true
+	return nil
+}
+
+func TestReceiver(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectionInterval = 10 * time.Millisecond
+	tracker := &fakeTracker{conns: [][]connection{
+		{outboundConn(1, 40001), closedConn(outboundConn(2, 40002), time.Second)},
+	}}
+	metricsSink := &consumertest.MetricsSink{}
+	logsSink := &consumertest.LogsSink{}
+
+	r := newFlowReceiver(cfg, receivertest.NewNopSettings())
+	r.newTracker = func(maxConnections int) (connectionTracker, error) {
+		assert.Equal(t, 65536, maxConnections)
+		return tracker, nil
+	}
+	r.nextMetrics = metricsSink
+	r.nextLogs = logsSink
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.Eventually(t, func() bool {
+		return len(metricsSink.AllMetrics()) == 1 && len(logsSink.AllLogs()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 4, metricsSink.AllMetrics()[0].MetricCount())
+	assert.Equal(t, 1, logsSink.AllLogs()[0].LogRecordCount())
+
+	require.NoError(t, r.Shutdown(context.Background()))
+	assert.True(t, tracker.closed)
+}
+
+func TestReceiverUnknownObserver(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.WatchObservers = []component.ID{component.MustNewID("k8s_observer")}
+	r := newFlowReceiver(cfg, receivertest.NewNopSettings())
+	require.EqualError(t, r.Start(context.Background(), componenttest.NewNopHost()),
+		`failed to find observer "k8s_observer" as a configured extension`)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkflowreceiver
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"net/netip"
+	"time"
+)
+
+// connection is a TCP connection of the host.
+type connection struct {
+	localAddr  netip.Addr
+	remoteAddr netip.Addr
+	// id identifies the connection while it's tracked.
+	id         uint64
+	duration   time.Duration
+	localPort  uint16
+	remotePort uint16
+	inbound    bool
+	closed     bool
+}
+
+// serverPort returns the port the connection was accepted on.
+func (c connection) serverPort() uint16 {
+	if c.inbound {
+		return c.localPort
+	}
+	return c.remotePort
+}
+
+// connectionTracker tracks the TCP connections of the host.
+type connectionTracker interface {
+	// connections returns the tracked connections. Closed connections are only returned once.
+	connections() ([]connection, error)
+	close() error
+}
+
+// connectionID returns the id of the connection with the key of the program, see bpf.go.
+func connectionID(key []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	return h.Sum64()
+}
+
+// parseConnection parses the connection value of the program, see bpf.go.
+func parseConnection(id uint64, value []byte) connection {
+	c := connection{
+		id:         id,
+		localAddr:  netip.AddrFrom16([16]byte(value[offsetSaddr : offsetSaddr+16])).Unmap(),
+		remoteAddr: netip.AddrFrom16([16]byte(value[offsetDaddr : offsetDaddr+16])).Unmap(),
+		localPort:  binary.NativeEndian.Uint16(value[offsetSport:]),
+		remotePort: binary.NativeEndian.Uint16(value[offsetDport:]),
+		inbound:    value[offsetDirection] == directionInbound,
+	}
+	if closedNS := binary.NativeEndian.Uint64(value[offsetClosedNS:]); closedNS != 0 {
+		c.closed = true
+		c.duration = time.Duration(closedNS - binary.NativeEndian.Uint64(value[offsetEstablishedNS:]))
+	}
+	return c
+}
@@ -0,0 +1,275 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package networkflowreceiver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// bpf syscall commands and types, include/uapi/linux/bpf.h
+const (
+	bpfMapCreate        = 0
+	bpfMapLookupElem    = 1
+	bpfMapDeleteElem    = 3
+	bpfMapGetNextKey    = 4
+	bpfProgLoad         = 5
+	bpfMapTypeHash      = 1
+	bpfProgTypeTracepnt = 5
+
+	bpfLogSize = 1 << 16
+)
+
+// tracingDirs are the mount points of the tracefs.
+var tracingDirs = []string{"/sys/kernel/tracing", "/sys/kernel/debug/tracing"}
+
+type bpfMapCreateAttr struct {
+	mapType    uint32
+	keySize    uint32
+	valueSize  uint32
+	maxEntries uint32
+	mapFlags   uint32
+}
+
+type bpfMapElemAttr struct {
+	mapFD uint32
+	_     uint32
+	key   uint64
+	value uint64
+	flags uint64
+}
+
+type bpfProgLoadAttr struct {
+	progType    uint32
+	insnCnt     uint32
+	insns       uint64
+	license     uint64
+	logLevel    uint32
+	logSize     uint32
+	logBuf      uint64
+	kernVersion uint32
+	_           uint32
+}
+
+// ebpfTracker tracks the connections with the program of bpf.go attached to the
+// sock:inet_sock_set_state tracepoint.
+type ebpfTracker struct {
+	key     []byte
+	nextKey []byte
+	value   []byte
+	mapFD   int
+	progFD  int
+	eventFD int
+}
+
+func newConnectionTracker(maxConnections int) (connectionTracker, error) {
+	dir, err := tracepointDir()
+	if err != nil {
+		return nil, err
+	}
+	format, err := os.Open(filepath.Join(dir, "format"))
+	if err != nil {
+		return nil, err
+	}
+	defer format.Close()
+	fields, err := parseTracepointFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	idFile, err := os.ReadFile(filepath.Join(dir, "id"))
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(string(idFile)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracepoint id: %w", err)
+	}
+
+	t := &ebpfTracker{
+		key:     make([]byte, connectionKeySize),
+		nextKey: make([]byte, connectionKeySize),
+		value:   make([]byte, connectionValueSize),
+		mapFD:   -1,
+		progFD:  -1,
+		eventFD: -1,
+	}
+	if t.mapFD, err = bpfCreateMap(maxConnections); err != nil {
+		return nil, privilegeError("failed to create the connections map", err)
+	}
+	insns, err := buildProgram(fields, t.mapFD)
+	if err != nil {
+		_ = t.close()
+		return nil, err
+	}
+	if t.progFD, err = bpfLoadProgram(insns); err != nil {
+		_ = t.close()
+		return nil, privilegeError("failed to load the program", err)
+	}
+	attr := &unix.PerfEventAttr{
+		Type:   unix.PERF_TYPE_TRACEPOINT,
+		Config: id,
+		Size:   uint32(unsafe.Sizeof(unix.PerfEventAttr{})),
+	}
+	// the program of a tracepoint runs on all CPUs, regardless of the CPU of its event
+	if t.eventFD, err = unix.PerfEventOpen(attr, -1, 0, -1, unix.PERF_FLAG_FD_CLOEXEC); err != nil {
+		_ = t.close()
+		return nil, privilegeError("failed to open the tracepoint", err)
+	}
+	if err = unix.IoctlSetInt(t.eventFD, unix.PERF_EVENT_IOC_SET_BPF, t.progFD); err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("failed to attach the program: %w", err)
+	}
+	if err = unix.IoctlSetInt(t.eventFD, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("failed to enable the tracepoint: %w", err)
+	}
+	return t, nil
+}
+
+func tracepointDir() (string, error) {
+	for _, dir := range tracingDirs {
+		tp := filepath.Join(dir, "events", "sock", "inet_sock_set_state")
+		if _, err := os.Stat(tp); err == nil {
+			return tp, nil
+		}
+	}
+	return "", fmt.Errorf("the sock:inet_sock_set_state tracepoint isn't available in %s, it requires Linux 4.16 or later and a mounted tracefs", strings.Join(tracingDirs, " or "))
+}
+
+func privilegeError(msg string, err error) error {
+	if errors.Is(err, unix.EPERM) {
+		return fmt.Errorf("%s, the collector requires the CAP_BPF and CAP_PERFMON, or CAP_SYS_ADMIN capabilities: %w", msg, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+func (t *ebpfTracker) connections() ([]connection, error) {
+	// the keys are collected first, since deleting the current key restarts the iteration
+	var keys [][connectionKeySize]byte
+	for hasKey := false; ; hasKey = true {
+		if hasKey {
+			copy(t.key, t.nextKey)
+		}
+		var key uint64
+		if hasKey {
+			key = uint64(uintptr(unsafe.Pointer(&t.key[0])))
+		}
+		err := bpfMapElem(bpfMapGetNextKey, t.mapFD, key, uint64(uintptr(unsafe.Pointer(&t.nextKey[0]))))
+		if errors.Is(err, unix.ENOENT) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate the connections map: %w", err)
+		}
+		keys = append(keys, [connectionKeySize]byte(t.nextKey))
+	}
+
+	conns := make([]connection, 0, len(keys))
+	for _, key := range keys {
+		copy(t.key, key[:])
+		keyPtr := uint64(uintptr(unsafe.Pointer(&t.key[0])))
+		err := bpfMapElem(bpfMapLookupElem, t.mapFD, keyPtr, uint64(uintptr(unsafe.Pointer(&t.value[0]))))
+		if errors.Is(err, unix.ENOENT) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up a connection: %w", err)
+		}
+		c := parseConnection(connectionID(key[:]), t.value)
+		if c.closed {
+			if err = bpfMapElem(bpfMapDeleteElem, t.mapFD, keyPtr, 0); err != nil && !errors.Is(err, unix.ENOENT) {
+				return nil, fmt.Errorf("failed to delete a closed connection: %w", err)
+			}
+		}
+		conns = append(conns, c)
+	}
+	return conns, nil
+}
+
+func (t *ebpfTracker) close() error {
+	var errs error
+	for _, fd := range []int{t.eventFD, t.progFD, t.mapFD} {
+		if fd >= 0 {
+			errs = errors.Join(errs, unix.Close(fd))
+		}
+	}
+	t.eventFD, t.progFD, t.mapFD = -1, -1, -1
+	return errs
+}
+
+func bpf(cmd int, attr unsafe.Pointer, size uintptr) (int, error) {
+	fd, _, errno := unix.Syscall(unix.SYS_BPF, uintptr(cmd), uintptr(attr), size)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(fd), nil
+}
+
+func bpfCreateMap(maxEntries int) (int, error) {
+	if maxEntries <= 0 || uint64(maxEntries) > math.MaxUint32 {
+		return -1, fmt.Errorf("invalid number of map entries %d", maxEntries)
+	}
+	attr := bpfMapCreateAttr{
+		mapType:    bpfMapTypeHash,
+		keySize:    connectionKeySize,
+		valueSize:  connectionValueSize,
+		maxEntries: uint32(maxEntries),
+	}
+	return bpf(bpfMapCreate, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+}
+
+func bpfMapElem(cmd int, mapFD int, key, value uint64) error {
+	attr := bpfMapElemAttr{mapFD: uint32(mapFD), key: key, value: value}
+	_, err := bpf(cmd, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	return err
+}
+
+func bpfLoadProgram(insns []bpfInsn) (int, error) {
+	code := marshalInsns(insns)
+	// the program only calls helpers available to any license
+	license := []byte("Apache-2.0\x00")
+	attr := bpfProgLoadAttr{
+		progType: bpfProgTypeTracepnt,
+		insnCnt:  uint32(len(insns)),
+		insns:    uint64(uintptr(unsafe.Pointer(&code[0]))),
+		license:  uint64(uintptr(unsafe.Pointer(&license[0]))),
+	}
+	fd, err := bpf(bpfProgLoad, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if err != nil && !errors.Is(err, unix.EPERM) {
+		// load again with the verifier log to report why the program was rejected
+		log := make([]byte, bpfLogSize)
+		attr.logLevel = 1
+		attr.logSize = uint32(len(log))
+		attr.logBuf = uint64(uintptr(unsafe.Pointer(&log[0])))
+		if _, logErr := bpf(bpfProgLoad, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); logErr != nil {
+			err = fmt.Errorf("%w: %s", err, strings.TrimRight(string(log), "\x00"))
+		}
+		runtime.KeepAlive(log)
+	}
+	runtime.KeepAlive(code)
+	runtime.KeepAlive(license)
+	return fd, err
+}
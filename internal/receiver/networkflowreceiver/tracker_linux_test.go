@@ -0,0 +1,106 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package networkflowreceiver
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// TestEBPFTracker loads and attaches the program, which requires the privileges of the receiver,
+// and checks it tracks a loopback connection.
+func TestEBPFTracker(t *testing.T) {
+	if _, err := tracepointDir(); err != nil {
+		t.Skip(err)
+	}
+	tracker, err := newConnectionTracker(1024)
+	if errors.Is(err, unix.EPERM) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	defer func() { require.NoError(t, tracker.close()) }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, acceptErr := listener.Accept()
+		if acceptErr == nil {
+			accepted <- conn
+		}
+		close(accepted)
+	}()
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	server := <-accepted
+	require.NotNil(t, server)
+	serverPort := uint16(listener.Addr().(*net.TCPAddr).Port)
+	clientPort := uint16(client.LocalAddr().(*net.TCPAddr).Port)
+	loopback := netip.MustParseAddr("127.0.0.1")
+
+	find := func(conns []connection, inbound bool) *connection {
+		for _, c := range conns {
+			if c.inbound == inbound && c.serverPort() == serverPort &&
+				(inbound && c.remotePort == clientPort || !inbound && c.localPort == clientPort) {
+				return &c
+			}
+		}
+		return nil
+	}
+
+	var conns []connection
+	require.Eventually(t, func() bool {
+		conns, err = tracker.connections()
+		require.NoError(t, err)
+		return find(conns, false) != nil && find(conns, true) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	outbound := find(conns, false)
+	assert.Equal(t, loopback, outbound.localAddr)
+	assert.Equal(t, loopback, outbound.remoteAddr)
+	assert.False(t, outbound.closed)
+
+	require.NoError(t, client.Close())
+	require.NoError(t, server.Close())
+	require.Eventually(t, func() bool {
+		conns, err = tracker.connections()
+		require.NoError(t, err)
+		c := find(conns, false)
+		return c != nil && c.closed
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, outbound.id, find(conns, false).id)
+	assert.Positive(t, find(conns, false).duration)
+
+	// closed connections are only returned once
+	conns, err = tracker.connections()
+	require.NoError(t, err)
+	assert.Nil(t, find(conns, false))
+}
+
+func TestBPFCreateMapInvalidEntries(t *testing.T) {
+	_, err := bpfCreateMap(0)
+	require.EqualError(t, err, "invalid number of map entries 0")
+	_, err = bpfCreateMap(-1)
+	require.EqualError(t, err, "invalid number of map entries -1")
+}
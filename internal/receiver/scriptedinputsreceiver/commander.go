@@ -82,6 +82,7 @@ func (c *commander) watch() {
 	err := c.cmd.Wait()
 	if err != nil {
 		c.logger.Error("Error in cmd wait: %v", zap.Error(err))
+		atomic.StoreInt64(&c.running, 0)
 		return
 	}
 	c.doneCh <- struct{}{}
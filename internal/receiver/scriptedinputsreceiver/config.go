@@ -28,6 +28,9 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/split"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
 )
 
 const (
@@ -54,8 +57,21 @@ type Config struct {
 	CollectionInterval string       `mapstructure:"collection_interval"`
 	helper.InputConfig `mapstructure:",squash"`
 	MaxLogSize         helper.ByteSize `mapstructure:"max_log_size,omitempty"`
-	interval           time.Duration
-	AddAttributes      bool `mapstructure:"add_attributes,omitempty"`
+	// Status configures the endpoint reporting the runs of the script.
+	Status   StatusConfig `mapstructure:"status"`
+	interval time.Duration
+	// runs records the runs of the script if the status endpoint is enabled.
+	runs          *runs
+	AddAttributes bool `mapstructure:"add_attributes,omitempty"`
+}
+
+// StatusConfig configures the HTTP endpoint reporting the runs of the script, served at
+// /status with the auth and limits of the endpoint.
+type StatusConfig struct {
+	confighttp.ServerConfig `mapstructure:",squash"`
+	transport.Limits        `mapstructure:",squash"`
+	// Enabled toggles the endpoint.
+	Enabled bool `mapstructure:"enabled"`
 }
 
 func createDefaultConfig() *Config {
@@ -92,6 +108,15 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid 'collection_interval': %w", err)
 	}
 
+	if c.Status.Enabled {
+		if c.Status.Endpoint == "" {
+			return errors.New("'status' endpoint must be specified")
+		}
+		if err = c.Status.Limits.Validate(); err != nil {
+			return fmt.Errorf("invalid 'status': %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -135,6 +160,7 @@ func (c *Config) Build(set component.TelemetrySettings) (operator.Operator, erro
 		decoder:       decode.New(enc),
 		splitFunc:     splitFunc,
 		scriptContent: scriptContent,
+		runs:          c.runs,
 	}, nil
 }
 
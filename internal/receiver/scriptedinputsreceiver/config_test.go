@@ -114,6 +114,20 @@ func TestCreateWithMissingExecFile(t *testing.T) {
 	assert.Equal(t, err.Error(), "'script_name' must be specified")
 }
 
+func TestValidateStatus(t *testing.T) {
+	config := createDefaultConfig()
+	config.ScriptName = "df"
+	config.Status.Enabled = true
+	assert.EqualError(t, config.Validate(), "'status' endpoint must be specified")
+
+	config.Status.Endpoint = "localhost:8006"
+	config.Status.MaxConcurrentRequests = -1
+	assert.EqualError(t, config.Validate(), "invalid 'status': max_concurrent_requests must not be negative")
+
+	config.Status.MaxConcurrentRequests = 1
+	assert.NoError(t, config.Validate())
+}
+
 func TestCreateWithNonEmptyMultiline(t *testing.T) {
 	tmpScript(t)
 	config := Config{}
@@ -15,9 +15,12 @@
 package scriptedinputsreceiver
 
 import (
+	"context"
+
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/adapter"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
 )
 
@@ -27,7 +30,26 @@ const (
 )
 
 func NewFactory() receiver.Factory {
-	return adapter.NewFactory(scriptedInputsReceiver{}, stability)
+	factory := adapter.NewFactory(scriptedInputsReceiver{}, stability)
+	return receiver.NewFactory(factory.Type(), factory.CreateDefaultConfig, receiver.WithLogs(
+		func(ctx context.Context, set receiver.Settings, cfg component.Config, next consumer.Logs) (receiver.Logs, error) {
+			return createLogs(ctx, set, cfg.(*Config), next, factory)
+		}, stability))
+}
+
+// createLogs creates the stanza receiver running the script, and serves its status endpoint
+// if enabled.
+func createLogs(ctx context.Context, set receiver.Settings, cfg *Config, next consumer.Logs, factory receiver.Factory) (receiver.Logs, error) {
+	if !cfg.Status.Enabled {
+		return factory.CreateLogs(ctx, set, cfg, next)
+	}
+	withRuns := *cfg
+	withRuns.runs = newRuns(cfg.ScriptName)
+	logs, err := factory.CreateLogs(ctx, set, &withRuns, next)
+	if err != nil {
+		return nil, err
+	}
+	return newStatusReceiver(set.TelemetrySettings, cfg.Status, logs, withRuns.runs)
 }
 
 var _ adapter.LogReceiverType = (*scriptedInputsReceiver)(nil)
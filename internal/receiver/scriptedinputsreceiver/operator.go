@@ -17,6 +17,8 @@ package scriptedinputsreceiver
 import (
 	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"sync"
 	"time"
@@ -46,6 +48,7 @@ type stdoutOperator struct {
 	splitFunc     bufio.SplitFunc
 	decoder       *decode.Decoder
 	scriptContent string
+	runs          *runs
 	helper.InputOperator
 	wg sync.WaitGroup
 }
@@ -85,7 +88,9 @@ func (i *stdoutOperator) beginCycle(ctx context.Context) error {
 	stdOutReader, stdOutWriter := io.Pipe()
 	commander := newCommander(i.logger.Desugar(), i.cfg.ScriptName, i.scriptContent, stdOutWriter)
 
+	start := time.Now()
 	if err := commander.Start(ctx); err != nil {
+		i.runs.record(start, err)
 		return err
 	}
 
@@ -98,6 +103,7 @@ func (i *stdoutOperator) beginCycle(ctx context.Context) error {
 		select {
 		case <-commander.Done():
 			i.logger.Debug("Script finished", zap.String("script_name", i.cfg.ScriptName))
+			i.runs.record(start, nil)
 			// Close the write pipe. This will result in subsequent read by scanner to return EOF and finish
 			// the goroutine that processes the script output.
 			err := stdOutWriter.Close()
@@ -106,7 +112,12 @@ func (i *stdoutOperator) beginCycle(ctx context.Context) error {
 			}
 
 		case <-ctx.Done():
-			i.logger.Warn("Script didn't complete within configured interval.", zap.String("script_name", i.cfg.ScriptName))
+			if commander.IsRunning() {
+				i.logger.Warn("Script didn't complete within configured interval.", zap.String("script_name", i.cfg.ScriptName))
+				i.runs.record(start, errors.New("script didn't complete within the collection interval"))
+			} else {
+				i.runs.record(start, fmt.Errorf("script exited with code %d", commander.ExitCode()))
+			}
 			err := commander.Stop(context.Background())
 			if err != nil {
 				return
@@ -0,0 +1,111 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scriptedinputsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
+)
+
+// statusPath is the route of the status endpoint.
+const statusPath = "/status"
+
+// statusReport is the document served by the status endpoint.
+type statusReport struct {
+	LastRun  *runReport `json:"last_run,omitempty"`
+	Script   string     `json:"script"`
+	Runs     int64      `json:"runs"`
+	Failures int64      `json:"failures"`
+}
+
+type runReport struct {
+	Start    time.Time `json:"start"`
+	Error    string    `json:"error,omitempty"`
+	Duration string    `json:"duration"`
+}
+
+// runs records the runs of a script and serves them as a statusReport. Its methods are no-ops
+// on a nil runs, so runs are only recorded if the status endpoint is enabled.
+type runs struct {
+	report statusReport
+	mu     sync.Mutex
+}
+
+func newRuns(script string) *runs {
+	return &runs{report: statusReport{Script: script}}
+}
+
+// record records a run started at start, failed if err isn't nil.
+func (r *runs) record(start time.Time, err error) {
+	if r == nil {
+		return
+	}
+	run := &runReport{Start: start, Duration: time.Since(start).String()}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Runs++
+	if err != nil {
+		r.report.Failures++
+		run.Error = err.Error()
+	}
+	r.report.LastRun = run
+}
+
+func (r *runs) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	r.mu.Lock()
+	report := r.report
+	r.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// statusReceiver serves the status endpoint of the script alongside the receiver running it.
+type statusReceiver struct {
+	receiver.Logs
+	server *transport.Server
+}
+
+func newStatusReceiver(set component.TelemetrySettings, cfg StatusConfig, logs receiver.Logs, runs *runs) (*statusReceiver, error) {
+	server, err := transport.NewServer(set, cfg.ServerConfig, cfg.Limits, "scripted_inputs")
+	if err != nil {
+		return nil, err
+	}
+	server.Handle(statusPath, runs)
+	return &statusReceiver{Logs: logs, server: server}, nil
+}
+
+func (r *statusReceiver) Start(ctx context.Context, host component.Host) error {
+	if err := r.server.Start(ctx, host); err != nil {
+		return err
+	}
+	return r.Logs.Start(ctx, host)
+}
+
+func (r *statusReceiver) Shutdown(ctx context.Context) error {
+	return errors.Join(r.Logs.Shutdown(ctx), r.server.Shutdown(ctx))
+}
@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scriptedinputsreceiver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuns(t *testing.T) {
+	var disabled *runs
+	disabled.record(time.Now(), nil)
+
+	r := newRuns("df")
+	start := time.Now()
+	r.record(start, nil)
+	r.record(start, errors.New("script exited with code 1"))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, statusPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report statusReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "df", report.Script)
+	assert.Equal(t, int64(2), report.Runs)
+	assert.Equal(t, int64(1), report.Failures)
+	require.NotNil(t, report.LastRun)
+	assert.Equal(t, "script exited with code 1", report.LastRun.Error)
+	assert.True(t, start.Equal(report.LastRun.Start))
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, statusPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
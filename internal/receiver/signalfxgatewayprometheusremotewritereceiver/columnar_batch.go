@@ -0,0 +1,455 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal/metadata"
+)
+
+var errBatchClosed = errors.New("receiver is shutting down")
+
+type batchMetricKey struct {
+	name       string
+	metricType prompb.MetricMetadata_MetricType
+}
+
+type batchMetric struct {
+	batchMetricKey
+	// help and unit are the ones of the metadata of the first series of the metric.
+	help    string
+	unit    string
+	samples int
+	// histograms is the number of native histograms of the metric, and gaugeHistogram whether the
+	// first one hinted it's a gauge histogram.
+	histograms     int
+	gaugeHistogram bool
+}
+
+type batchHistogram struct {
+	histogram prompb.Histogram
+	series    int32
+}
+
+type batchSeries struct {
+	labels []prompb.Label
+	// exemplars are the exemplars of the series in the batch.
+	exemplars []prompb.Exemplar
+	// createdTimestamp is the latest created timestamp of the series, 0 if unknown.
+	createdTimestamp int64
+	metric           int32
+}
+
+// columnarBatch accumulates the samples of write requests in columns referencing the series
+// they belong to, and translates them in a single pass per batch: each metric name becomes one
+// metric sized for all its samples, and the attributes of each series are built once and copied
+// to its datapoints, instead of a metric per series and attributes per sample.
+type columnarBatch struct {
+	parser  *prometheusRemoteOtelParser
+	mc      chan<- pmetric.Metrics
+	columns *batchColumns
+	// spare holds the columns of the last sent batch for reuse.
+	spare         *batchColumns
+	keyBuf        []byte
+	sending       sync.WaitGroup
+	maxSamples    int
+	flushInterval time.Duration
+	mu            sync.Mutex
+	closed        bool
+	// done is closed to stop sending batches once the pipeline no longer consumes them.
+	done      chan struct{}
+	abortOnce sync.Once
+}
+
+// batchColumns are the accumulated samples of a batch.
+type batchColumns struct {
+	metricIndex map[batchMetricKey]int32
+	seriesIndex map[string]int32
+	metrics     []batchMetric
+	series      []batchSeries
+	// sampleSeries, timestamps and values are the sample columns.
+	sampleSeries []int32
+	timestamps   []int64
+	values       []float64
+	// histograms are the native histograms, translated to exponential histograms.
+	histograms []batchHistogram
+	// exemplars is the number of exemplars of the series.
+	exemplars int
+}
+
+func newBatchColumns(maxSamples int) *batchColumns {
+	return &batchColumns{
+		metricIndex:  map[batchMetricKey]int32{},
+		seriesIndex:  map[string]int32{},
+		sampleSeries: make([]int32, 0, maxSamples),
+		timestamps:   make([]int64, 0, maxSamples),
+		values:       make([]float64, 0, maxSamples),
+	}
+}
+
+func (c *batchColumns) reset() {
+	clear(c.metricIndex)
+	clear(c.seriesIndex)
+	c.metrics = c.metrics[:0]
+	c.series = c.series[:0]
+	c.sampleSeries = c.sampleSeries[:0]
+	c.timestamps = c.timestamps[:0]
+	c.values = c.values[:0]
+	c.histograms = c.histograms[:0]
+	c.exemplars = 0
+}
+
+func newColumnarBatch(cfg ColumnarBatchingConfig, parser *prometheusRemoteOtelParser, mc chan<- pmetric.Metrics) *columnarBatch {
+	return &columnarBatch{
+		parser:        parser,
+		mc:            mc,
+		columns:       newBatchColumns(cfg.MaxSamples),
+		maxSamples:    cfg.MaxSamples,
+		flushInterval: cfg.FlushInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+// add appends the samples of a write request to the batch, sending the batch once it has
+// max_samples samples. Requests are validated like when translated on their own, and rejected
+// as a whole if invalid, counting their bad metrics and NaN samples.
+func (cb *columnarBatch) add(req *prompb.WriteRequest) error {
+	return cb.addWriteRequest(req, nil)
+}
+
+// addWriteRequest adds the write request, with the created timestamps of its series by index,
+// nil if none has one.
+func (cb *columnarBatch) addWriteRequest(req *prompb.WriteRequest, createdTimestamps []int64) error {
+	names := make([]string, len(req.Timeseries))
+	var errs error
+	for i, ts := range req.Timeseries {
+		metricName, err := internal.ExtractMetricNameLabel(ts.Labels)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+		} else if metricName == "" {
+			errs = multierr.Append(errs, errors.New("empty metric name"))
+		}
+		if len(ts.Samples) < 1 && len(ts.Histograms) < 1 {
+			errs = multierr.Append(errs, fmt.Errorf("no samples found for  %s", metricName))
+			cb.parser.totalInvalidRequests.Add(1)
+		}
+		names[i] = metricName
+	}
+	if errs != nil {
+		cb.countRejected(req, names)
+		return errs
+	}
+
+	cb.parser.metadata.update(req.Metadata)
+	cb.mu.Lock()
+	if cb.closed {
+		cb.mu.Unlock()
+		return errBatchClosed
+	}
+	columns := cb.columns
+	for i, ts := range req.Timeseries {
+		series := cb.seriesRef(names[i], ts.Labels)
+		if createdTimestamps != nil && createdTimestamps[i] > columns.series[series].createdTimestamp {
+			columns.series[series].createdTimestamp = createdTimestamps[i]
+		}
+		if len(ts.Exemplars) > 0 {
+			columns.series[series].exemplars = append(columns.series[series].exemplars, ts.Exemplars...)
+			columns.exemplars += len(ts.Exemplars)
+		}
+		for _, sample := range ts.Samples {
+			if math.IsNaN(sample.Value) && !cb.parser.keepStaleMarker(sample.Value) {
+				cb.parser.totalNans.Add(1)
+				continue
+			}
+			columns.sampleSeries = append(columns.sampleSeries, series)
+			columns.timestamps = append(columns.timestamps, sample.Timestamp)
+			columns.values = append(columns.values, sample.Value)
+			columns.metrics[columns.series[series].metric].samples++
+		}
+		for _, h := range ts.Histograms {
+			metric := &columns.metrics[columns.series[series].metric]
+			if metric.histograms == 0 {
+				metric.gaugeHistogram = h.ResetHint == prompb.Histogram_GAUGE
+			}
+			metric.histograms++
+			columns.histograms = append(columns.histograms, batchHistogram{histogram: h, series: series})
+		}
+	}
+	var full *batchColumns
+	if len(columns.values)+len(columns.histograms) >= cb.maxSamples {
+		full = cb.take()
+	}
+	cb.mu.Unlock()
+	cb.send(full)
+	return nil
+}
+
+// countRejected counts the series without metric name and the NaN samples of a rejected write
+// request, like its translation would.
+func (cb *columnarBatch) countRejected(req *prompb.WriteRequest, names []string) {
+	for i, ts := range req.Timeseries {
+		if names[i] == "" {
+			cb.parser.totalBadMetrics.Add(1)
+			continue
+		}
+		for _, sample := range ts.Samples {
+			if math.IsNaN(sample.Value) && !cb.parser.keepStaleMarker(sample.Value) {
+				cb.parser.totalNans.Add(1)
+			}
+		}
+		for _, h := range ts.Histograms {
+			if math.IsNaN(h.Sum) && !cb.parser.keepStaleMarker(h.Sum) {
+				cb.parser.totalNans.Add(1)
+			}
+		}
+	}
+}
+
+// seriesRef returns the index of the series with the labels, adding it if it's new to the batch.
+func (cb *columnarBatch) seriesRef(metricName string, labels []prompb.Label) int32 {
+	cb.keyBuf = cb.keyBuf[:0]
+	for _, label := range labels {
+		cb.keyBuf = append(cb.keyBuf, label.Name...)
+		cb.keyBuf = append(cb.keyBuf, '\xff')
+		cb.keyBuf = append(cb.keyBuf, label.Value...)
+		cb.keyBuf = append(cb.keyBuf, '\xff')
+	}
+	columns := cb.columns
+	if series, ok := columns.seriesIndex[string(cb.keyBuf)]; ok {
+		return series
+	}
+	metricMetadata := cb.parser.metricMetadata(metricName, labels)
+	key := batchMetricKey{name: metricName, metricType: metricMetadata.Type}
+	metric, ok := columns.metricIndex[key]
+	if !ok {
+		metric = int32(len(columns.metrics)) //nolint:gosec
+		columns.metricIndex[key] = metric
+		columns.metrics = append(columns.metrics, batchMetric{batchMetricKey: key, help: metricMetadata.Help, unit: metricMetadata.Unit})
+	}
+	series := int32(len(columns.series)) //nolint:gosec
+	columns.seriesIndex[string(cb.keyBuf)] = series
+	columns.series = append(columns.series, batchSeries{labels: labels, metric: metric})
+	return series
+}
+
+// take returns the accumulated samples, or nil if there are none, replacing them with empty
+// columns. It must be called with the lock held, and the returned columns passed to send.
+func (cb *columnarBatch) take() *batchColumns {
+	if len(cb.columns.values) == 0 && len(cb.columns.histograms) == 0 {
+		return nil
+	}
+	columns := cb.columns
+	cb.columns, cb.spare = cb.spare, nil
+	if cb.columns == nil {
+		cb.columns = newBatchColumns(cb.maxSamples)
+	}
+	cb.sending.Add(1)
+	return columns
+}
+
+// send translates and sends the columns returned by take, if any, keeping them for reuse.
+// It must be called without the lock held so write requests aren't blocked while the
+// pipeline is busy. The columns are dropped if the batch is aborted before they're received.
+func (cb *columnarBatch) send(columns *batchColumns) {
+	if columns == nil {
+		return
+	}
+	defer cb.sending.Done()
+	select {
+	case cb.mc <- cb.translate(columns):
+	case <-cb.done:
+	}
+	columns.reset()
+	cb.mu.Lock()
+	cb.spare = columns
+	cb.mu.Unlock()
+}
+
+// translate translates the columns to metrics.
+func (cb *columnarBatch) translate(columns *batchColumns) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(metadata.ScopeName)
+	sm.Scope().SetVersion("0.1")
+	sm.Metrics().EnsureCapacity(len(columns.metrics) + 3)
+
+	dataPoints := make([]pmetric.NumberDataPointSlice, len(columns.metrics))
+	cumulative := make([]bool, len(columns.metrics))
+	for i, m := range columns.metrics {
+		if m.samples == 0 && m.histograms > 0 {
+			continue
+		}
+		nm := sm.Metrics().AppendEmpty()
+		nm.SetName(m.name)
+		nm.SetDescription(m.help)
+		nm.SetUnit(m.unit)
+		switch m.metricType {
+		case prompb.MetricMetadata_COUNTER, prompb.MetricMetadata_HISTOGRAM, prompb.MetricMetadata_GAUGEHISTOGRAM:
+			sum := nm.SetEmptySum()
+			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			sum.SetIsMonotonic(true)
+			dataPoints[i] = sum.DataPoints()
+			cumulative[i] = true
+		default:
+			dataPoints[i] = nm.SetEmptyGauge().DataPoints()
+		}
+		dataPoints[i].EnsureCapacity(m.samples)
+	}
+
+	attributes := make([]pcommon.Map, len(columns.series))
+	for i, series := range columns.series {
+		attributes[i] = pcommon.NewMap()
+		attributes[i].EnsureCapacity(len(series.labels))
+		cb.parser.putAttributes(attributes[i], series.labels)
+	}
+
+	// the datapoints of the series with exemplars, to add them once all datapoints are translated
+	var seriesDataPoints []dataPointList[pmetric.NumberDataPoint]
+	var seriesHistogramDataPoints []dataPointList[pmetric.ExponentialHistogramDataPoint]
+	if columns.exemplars > 0 {
+		seriesDataPoints = make([]dataPointList[pmetric.NumberDataPoint], len(columns.series))
+		seriesHistogramDataPoints = make([]dataPointList[pmetric.ExponentialHistogramDataPoint], len(columns.series))
+	}
+
+	minTimestamp, maxTimestamp := int64(math.MaxInt64), int64(math.MinInt64)
+	for i, series := range columns.sampleSeries {
+		timestamp := columns.timestamps[i]
+		minTimestamp, maxTimestamp = min(minTimestamp, timestamp), max(maxTimestamp, timestamp)
+		metric := columns.series[series].metric
+		dp := dataPoints[metric].AppendEmpty()
+		dp.SetTimestamp(prometheusToOtelTimestamp(timestamp))
+		if cumulative[metric] {
+			dp.SetStartTimestamp(prometheusToOtelTimestamp(cb.parser.cumulativeStart(columns.metrics[metric].name,
+				columns.series[series].labels, columns.series[series].createdTimestamp, timestamp, columns.values[i])))
+		} else {
+			dp.SetStartTimestamp(prometheusToOtelTimestamp(timestamp))
+		}
+		cb.parser.setFloatOrInt(dp, prompb.Sample{Value: columns.values[i]})
+		attributes[series].CopyTo(dp.Attributes())
+		if seriesDataPoints != nil && len(columns.series[series].exemplars) > 0 {
+			seriesDataPoints[series] = append(seriesDataPoints[series], dp)
+		}
+	}
+	histogramDataPoints := make([]pmetric.ExponentialHistogramDataPointSlice, len(columns.metrics))
+	gaugeHistograms := make([]bool, len(columns.metrics))
+	for i, m := range columns.metrics {
+		if m.histograms == 0 {
+			continue
+		}
+		nm := sm.Metrics().AppendEmpty()
+		nm.SetName(m.name)
+		nm.SetDescription(m.help)
+		nm.SetUnit(m.unit)
+		histogram := nm.SetEmptyExponentialHistogram()
+		gaugeHistograms[i] = m.metricType == prompb.MetricMetadata_GAUGEHISTOGRAM || m.gaugeHistogram
+		if gaugeHistograms[i] {
+			histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		} else {
+			histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		}
+		histogramDataPoints[i] = histogram.DataPoints()
+		histogramDataPoints[i].EnsureCapacity(m.histograms)
+	}
+	for _, bh := range columns.histograms {
+		series := columns.series[bh.series]
+		minTimestamp, maxTimestamp = min(minTimestamp, bh.histogram.Timestamp), max(maxTimestamp, bh.histogram.Timestamp)
+		dp, ok := cb.parser.appendNativeHistogram(histogramDataPoints[series.metric], bh.histogram,
+			columns.metrics[series.metric].name, series.labels, series.createdTimestamp, gaugeHistograms[series.metric])
+		if ok {
+			attributes[bh.series].CopyTo(dp.Attributes())
+			if seriesHistogramDataPoints != nil && len(series.exemplars) > 0 {
+				seriesHistogramDataPoints[bh.series] = append(seriesHistogramDataPoints[bh.series], dp)
+			}
+		}
+	}
+	for i := range seriesDataPoints {
+		// the exemplars of series with samples are added to their samples, like when translating
+		// write requests on their own
+		if len(seriesDataPoints[i]) > 0 {
+			addExemplars[pmetric.NumberDataPoint](seriesDataPoints[i], columns.series[i].exemplars)
+		} else {
+			addExemplars[pmetric.ExponentialHistogramDataPoint](seriesHistogramDataPoints[i], columns.series[i].exemplars)
+		}
+	}
+	start, end := time.UnixMilli(minTimestamp), time.UnixMilli(maxTimestamp)
+	cb.parser.addBadRequests(sm, start, end)
+	cb.parser.addNanDataPoints(sm, start, end)
+	cb.parser.addMetricsWithMissingName(sm, start, end)
+
+	return md
+}
+
+// run sends the accumulated samples every flush interval until the context is done, aborting
+// the batch since its metrics are no longer consumed.
+func (cb *columnarBatch) run(ctx context.Context) {
+	ticker := time.NewTicker(cb.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			var columns *batchColumns
+			cb.mu.Lock()
+			if !cb.closed {
+				columns = cb.take()
+			}
+			cb.mu.Unlock()
+			cb.send(columns)
+		case <-ctx.Done():
+			cb.abort()
+			return
+		}
+	}
+}
+
+// abort drops the batches being sent, and any sent afterward.
+func (cb *columnarBatch) abort() {
+	cb.abortOnce.Do(func() { close(cb.done) })
+}
+
+// close sends the accumulated samples and rejects the samples of further write requests. It
+// returns once every batch has been sent, or aborts the batch once the context is done.
+func (cb *columnarBatch) close(ctx context.Context) {
+	var columns *batchColumns
+	cb.mu.Lock()
+	if !cb.closed {
+		columns = cb.take()
+		cb.closed = true
+	}
+	cb.mu.Unlock()
+	sent := make(chan struct{})
+	go func() {
+		cb.send(columns)
+		cb.sending.Wait()
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-ctx.Done():
+		cb.abort()
+		<-sent
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newTestColumnarBatch(maxSamples int) (*columnarBatch, chan pmetric.Metrics) {
+	mc := make(chan pmetric.Metrics, 10)
+	cfg := ColumnarBatchingConfig{MaxSamples: maxSamples, FlushInterval: time.Hour}
+	return newColumnarBatch(cfg, newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), mc), mc
+}
+
+func TestColumnarBatch(t *testing.T) {
+	cb, mc := newTestColumnarBatch(100)
+
+	require.NoError(t, cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "code", Value: "200"}},
+			Samples: []prompb.Sample{{Value: 10, Timestamp: 1000}, {Value: 20, Timestamp: 2000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+			Samples: []prompb.Sample{{Value: 1.5, Timestamp: 1000}, {Value: math.NaN(), Timestamp: 2000}},
+		},
+	}}))
+	require.NoError(t, cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "code", Value: "500"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 3000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "code", Value: "200"}},
+			Samples: []prompb.Sample{{Value: 30, Timestamp: 3000}},
+		},
+	}}))
+	require.Empty(t, mc)
+
+	cb.close(context.Background())
+	require.Len(t, mc, 1)
+	md := <-mc
+
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 5, metrics.Len())
+
+	counter := metrics.At(0)
+	assert.Equal(t, "http_requests_total", counter.Name())
+	require.Equal(t, pmetric.MetricTypeSum, counter.Type())
+	assert.True(t, counter.Sum().IsMonotonic())
+	dps := counter.Sum().DataPoints()
+	require.Equal(t, 4, dps.Len())
+	for i, expected := range []struct {
+		code      string
+		value     int64
+		timestamp int64
+	}{
+		{code: "200", value: 10, timestamp: 1000},
+		{code: "200", value: 20, timestamp: 2000},
+		{code: "500", value: 1, timestamp: 3000},
+		{code: "200", value: 30, timestamp: 3000},
+	} {
+		assert.Equal(t, map[string]any{"code": expected.code}, dps.At(i).Attributes().AsRaw())
+		assert.Equal(t, expected.value, dps.At(i).IntValue())
+		assert.Equal(t, prometheusToOtelTimestamp(expected.timestamp), dps.At(i).Timestamp())
+	}
+
+	gauge := metrics.At(1)
+	assert.Equal(t, "temperature", gauge.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, gauge.Type())
+	require.Equal(t, 1, gauge.Gauge().DataPoints().Len())
+	assert.Equal(t, 1.5, gauge.Gauge().DataPoints().At(0).DoubleValue())
+	assert.Empty(t, gauge.Gauge().DataPoints().At(0).Attributes().AsRaw())
+
+	assert.Equal(t, "prometheus.invalid_requests", metrics.At(2).Name())
+	assert.Equal(t, "prometheus.total_NAN_samples", metrics.At(3).Name())
+	assert.Equal(t, int64(1), metrics.At(3).Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, prometheusToOtelTimestamp(1000), metrics.At(3).Sum().DataPoints().At(0).StartTimestamp())
+	assert.Equal(t, prometheusToOtelTimestamp(3000), metrics.At(3).Sum().DataPoints().At(0).Timestamp())
+	assert.Equal(t, "prometheus.total_bad_datapoints", metrics.At(4).Name())
+
+	assert.ErrorIs(t, cb.add(sampleGaugeWq()), errBatchClosed)
+}
+
+func TestColumnarBatchMaxSamples(t *testing.T) {
+	cb, mc := newTestColumnarBatch(3)
+
+	ts := prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: 2, Timestamp: 2000}},
+	}
+	require.NoError(t, cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{ts}}))
+	require.Empty(t, mc)
+	require.NoError(t, cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{ts}}))
+	require.Len(t, mc, 1)
+	md := <-mc
+	assert.Equal(t, 4, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().Len())
+
+	// The batch is reset after being sent.
+	require.NoError(t, cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{ts}}))
+	cb.close(context.Background())
+	require.Len(t, mc, 1)
+	md = <-mc
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, 4, metrics.Len())
+	assert.Equal(t, 2, metrics.At(0).Gauge().DataPoints().Len())
+}
+
+func TestColumnarBatchAcceptsWritesWhileSending(t *testing.T) {
+	mc := make(chan pmetric.Metrics)
+	cfg := ColumnarBatchingConfig{MaxSamples: 2, FlushInterval: time.Hour}
+	cb := newColumnarBatch(cfg, newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), mc)
+
+	ts := prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: 2, Timestamp: 2000}},
+	}
+	full := cb.columns
+	sent := make(chan error)
+	go func() {
+		// blocks sending the full batch until it's received
+		sent <- cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{ts}})
+	}()
+	require.Eventually(t, func() bool {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+		return cb.columns != full
+	}, 5*time.Second, time.Millisecond)
+
+	ts.Samples = ts.Samples[:1]
+	require.NoError(t, cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{ts}}))
+
+	md := <-mc
+	require.NoError(t, <-sent)
+	assert.Equal(t, 2, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().Len())
+
+	go cb.close(context.Background())
+	md = <-mc
+	assert.Equal(t, 1, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().Len())
+}
+
+func TestColumnarBatchRejectsInvalidRequests(t *testing.T) {
+	cb, mc := newTestColumnarBatch(100)
+
+	err := cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "le", Value: "1"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+	}})
+	require.ErrorContains(t, err, "did not find a label with `__name__`")
+
+	err = cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+			Samples: []prompb.Sample{{Value: math.NaN(), Timestamp: 1000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: ""}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+	}})
+	require.ErrorContains(t, err, "empty metric name")
+	assert.Equal(t, int64(2), cb.parser.totalBadMetrics.Load())
+	assert.Equal(t, int64(1), cb.parser.totalNans.Load())
+
+	cb.close(context.Background())
+	assert.Empty(t, mc)
+}
+
+func TestColumnarBatchShutdownWhileConsumerStalled(t *testing.T) {
+	mc := make(chan pmetric.Metrics)
+	cfg := ColumnarBatchingConfig{MaxSamples: 1, FlushInterval: time.Hour}
+	cb := newColumnarBatch(cfg, newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), mc)
+
+	full := cb.columns
+	sent := make(chan error)
+	go func() {
+		// blocks sending the full batch since nothing receives it
+		sent <- cb.add(sampleGaugeWq())
+	}()
+	require.Eventually(t, func() bool {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+		return cb.columns != full
+	}, 5*time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	closed := make(chan struct{})
+	go func() {
+		cb.close(ctx)
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("close didn't return while the consumer was stalled")
+	}
+	require.NoError(t, <-sent)
+	assert.ErrorIs(t, cb.add(sampleGaugeWq()), errBatchClosed)
+}
+
+func TestColumnarBatchFlushInterval(t *testing.T) {
+	mc := make(chan pmetric.Metrics, 10)
+	cfg := ColumnarBatchingConfig{MaxSamples: 100, FlushInterval: 10 * time.Millisecond}
+	cb := newColumnarBatch(cfg, newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), mc)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cb.run(ctx)
+
+	require.NoError(t, cb.add(sampleGaugeWq()))
+	select {
+	case md := <-mc:
+		assert.Positive(t, md.DataPointCount())
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch wasn't sent after its flush interval")
+	}
+}
+
+// benchmarkWriteRequests returns write requests of series with a sample each, like the
+// requests of a Prometheus instance remote writing a very high series count.
+func benchmarkWriteRequests(requests, seriesPerRequest int) []*prompb.WriteRequest {
+	writeRequests := make([]*prompb.WriteRequest, requests)
+	for r := range writeRequests {
+		req := &prompb.WriteRequest{Timeseries: make([]prompb.TimeSeries, seriesPerRequest)}
+		for s := range req.Timeseries {
+			req.Timeseries[s] = prompb.TimeSeries{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: fmt.Sprintf("metric_%d_total", s%50)},
+					{Name: "instance", Value: fmt.Sprintf("host-%d:9100", s/50)},
+					{Name: "job", Value: "node"},
+					{Name: "region", Value: "us-west-2"},
+				},
+				Samples: []prompb.Sample{{Value: float64(r*seriesPerRequest + s), Timestamp: int64(1700000000000 + r*15000)}},
+			}
+		}
+		writeRequests[r] = req
+	}
+	return writeRequests
+}
+
+func BenchmarkTranslation(b *testing.B) {
+	writeRequests := benchmarkWriteRequests(10, 2000)
+
+	b.Run("per_request", func(b *testing.B) {
+		parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, req := range writeRequests {
+				if _, err := parser.fromPrometheusWriteRequestMetrics(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("columnar", func(b *testing.B) {
+		mc := make(chan pmetric.Metrics, 1)
+		cb := newColumnarBatch(
+			ColumnarBatchingConfig{MaxSamples: len(writeRequests) * 2000, FlushInterval: time.Hour},
+			newPrometheusRemoteOtelParser(AttributeLimitsConfig{}),
+			mc,
+		)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, req := range writeRequests {
+				if err := cb.add(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+			<-mc
+		}
+	})
+}
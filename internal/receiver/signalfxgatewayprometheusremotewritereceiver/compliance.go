@@ -0,0 +1,248 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal"
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
+)
+
+const (
+	// futureTimestampTolerance is how far ahead of the receiver's clock sample timestamps may be.
+	futureTimestampTolerance = 10 * time.Minute
+	// staleTimestampThreshold is how far behind the receiver's clock sample timestamps may be.
+	staleTimestampThreshold = time.Hour
+)
+
+// ComplianceIssues are the number of occurrences of each remote write specification violation
+// or questionable sender behavior.
+type ComplianceIssues struct {
+	// UndecodableRequests are requests that aren't snappy compressed protobuf write requests.
+	UndecodableRequests int64 `json:"undecodable_requests"`
+	// MissingMetricName are series without a __name__ label.
+	MissingMetricName int64 `json:"missing_metric_name"`
+	// UnsortedLabels are series whose labels aren't sorted by name.
+	UnsortedLabels int64 `json:"unsorted_labels"`
+	// DuplicateLabels are series with the same label name more than once.
+	DuplicateLabels int64 `json:"duplicate_labels"`
+	// EmptyLabelValues are labels with an empty value, which must be omitted instead.
+	EmptyLabelValues int64 `json:"empty_label_values"`
+	// EmptySeries are series without samples or histograms.
+	EmptySeries int64 `json:"empty_series"`
+	// OutOfOrderSamples are samples older than the previous sample of their series in the same request.
+	OutOfOrderSamples int64 `json:"out_of_order_samples"`
+	// ZeroTimestamps are samples without a timestamp.
+	ZeroTimestamps int64 `json:"zero_timestamps"`
+	// FutureTimestamps are samples more than 10 minutes ahead of the receiver's clock.
+	FutureTimestamps int64 `json:"future_timestamps"`
+	// StaleTimestamps are samples more than an hour behind the receiver's clock.
+	StaleTimestamps int64 `json:"stale_timestamps"`
+	// RequestsWithoutMetadata are requests not carrying any metric metadata.
+	RequestsWithoutMetadata int64 `json:"requests_without_metadata"`
+	// BucketsWithoutLe are classic histogram _bucket series without an le label.
+	BucketsWithoutLe int64 `json:"buckets_without_le"`
+}
+
+func (ci ComplianceIssues) total() int64 {
+	return ci.UndecodableRequests + ci.MissingMetricName + ci.UnsortedLabels + ci.DuplicateLabels +
+		ci.EmptyLabelValues + ci.EmptySeries + ci.OutOfOrderSamples + ci.ZeroTimestamps +
+		ci.FutureTimestamps + ci.StaleTimestamps + ci.RequestsWithoutMetadata + ci.BucketsWithoutLe
+}
+
+// SenderComplianceReport is the compliance report of a single sender.
+type SenderComplianceReport struct {
+	FirstSeen              time.Time        `json:"first_seen"`
+	LastSeen               time.Time        `json:"last_seen"`
+	Sender                 string           `json:"sender"`
+	UserAgent              string           `json:"user_agent"`
+	RemoteWriteVersion     string           `json:"remote_write_version"`
+	Issues                 ComplianceIssues `json:"issues"`
+	Requests               int64            `json:"requests"`
+	Series                 int64            `json:"series"`
+	Samples                int64            `json:"samples"`
+	ClassicHistogramSeries int64            `json:"classic_histogram_series"`
+	NativeHistograms       int64            `json:"native_histograms"`
+	Exemplars              int64            `json:"exemplars"`
+	Compliant              bool             `json:"compliant"`
+}
+
+// ComplianceReport is the document served by the compliance report endpoint.
+type ComplianceReport struct {
+	Senders []SenderComplianceReport `json:"senders"`
+}
+
+// compliance analyzes write requests for remote write specification compliance and accumulates
+// a report per sender, identified by the configured header or else by the remote address.
+type compliance struct {
+	now     func() time.Time
+	senders map[string]*SenderComplianceReport
+	header  string
+	mu      sync.Mutex
+}
+
+func newCompliance(cfg ComplianceConfig) *compliance {
+	return &compliance{
+		now:     time.Now,
+		senders: map[string]*SenderComplianceReport{},
+		header:  cfg.SenderHeader,
+	}
+}
+
+// sender returns the report of the request's sender. Must be called with the lock held.
+func (c *compliance) sender(r *http.Request) *SenderComplianceReport {
+	id := transport.Sender(r, c.header)
+	now := c.now()
+	report, ok := c.senders[id]
+	if !ok {
+		report = &SenderComplianceReport{Sender: id, FirstSeen: now}
+		c.senders[id] = report
+	}
+	report.LastSeen = now
+	report.Requests++
+	report.UserAgent = r.Header.Get("User-Agent")
+	report.RemoteWriteVersion = r.Header.Get("X-Prometheus-Remote-Write-Version")
+	return report
+}
+
+// recordUndecodable accounts a request that couldn't be decoded to its sender.
+func (c *compliance) recordUndecodable(r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sender(r).Issues.UndecodableRequests++
+}
+
+// analyze accounts the series and samples of the request and their compliance issues to its sender.
+func (c *compliance) analyze(r *http.Request, req *prompb.WriteRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report := c.sender(r)
+	now := c.now()
+	if len(req.Metadata) == 0 {
+		report.Issues.RequestsWithoutMetadata++
+	}
+	for _, ts := range req.Timeseries {
+		report.Series++
+		report.Samples += int64(len(ts.Samples))
+		report.NativeHistograms += int64(len(ts.Histograms))
+		report.Exemplars += int64(len(ts.Exemplars))
+		analyzeLabels(&report.Issues, ts.Labels)
+
+		name, err := internal.ExtractMetricNameLabel(ts.Labels)
+		if err != nil {
+			report.Issues.MissingMetricName++
+		} else if strings.HasSuffix(name, "_bucket") {
+			report.ClassicHistogramSeries++
+			if !hasLabel(ts.Labels, "le") {
+				report.Issues.BucketsWithoutLe++
+			}
+		}
+
+		if len(ts.Samples) == 0 && len(ts.Histograms) == 0 {
+			report.Issues.EmptySeries++
+		}
+		var previous int64
+		for i, sample := range ts.Samples {
+			if i > 0 && sample.Timestamp < previous {
+				report.Issues.OutOfOrderSamples++
+			}
+			previous = sample.Timestamp
+			analyzeTimestamp(&report.Issues, sample.Timestamp, now)
+		}
+		for _, histogram := range ts.Histograms {
+			analyzeTimestamp(&report.Issues, histogram.Timestamp, now)
+		}
+	}
+}
+
+func analyzeLabels(issues *ComplianceIssues, labels []prompb.Label) {
+	unsorted := false
+	for i, label := range labels {
+		if label.Value == "" {
+			issues.EmptyLabelValues++
+		}
+		if i == 0 {
+			continue
+		}
+		if previous := labels[i-1].Name; label.Name == previous {
+			issues.DuplicateLabels++
+		} else if label.Name < previous {
+			unsorted = true
+		}
+	}
+	if unsorted {
+		issues.UnsortedLabels++
+	}
+}
+
+func analyzeTimestamp(issues *ComplianceIssues, timestamp int64, now time.Time) {
+	if timestamp == 0 {
+		issues.ZeroTimestamps++
+		return
+	}
+	t := time.UnixMilli(timestamp)
+	switch {
+	case t.After(now.Add(futureTimestampTolerance)):
+		issues.FutureTimestamps++
+	case t.Before(now.Add(-staleTimestampThreshold)):
+		issues.StaleTimestamps++
+	}
+}
+
+func hasLabel(labels []prompb.Label, name string) bool {
+	for _, label := range labels {
+		if label.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// report returns the reports of all senders, or only of the given one, ordered by sender.
+func (c *compliance) report(sender string) ComplianceReport {
+	c.mu.Lock()
+	senders := make([]SenderComplianceReport, 0, len(c.senders))
+	for id, report := range c.senders {
+		if sender == "" || sender == id {
+			senders = append(senders, *report)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(senders, func(i, j int) bool { return senders[i].Sender < senders[j].Sender })
+	for i := range senders {
+		senders[i].Compliant = senders[i].Issues.total() == 0
+	}
+	return ComplianceReport{Senders: senders}
+}
+
+// handler serves the compliance report as json. The "sender" query parameter restricts it to
+// a single sender.
+func (c *compliance) handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c.report(r.URL.Query().Get("sender")))
+}
@@ -0,0 +1,153 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestComplianceAnalyze(t *testing.T) {
+	c := newCompliance(ComplianceConfig{SenderHeader: "X-Sender"})
+	c.now = func() time.Time { return jan20 }
+	now := jan20.UnixMilli()
+
+	r := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+	r.Header.Set("X-Sender", "team-a")
+	r.Header.Set("User-Agent", "Prometheus/2.53.0")
+	r.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	c.analyze(r, &prompb.WriteRequest{
+		Metadata: []prompb.MetricMetadata{{MetricFamilyName: "http_requests_total", Type: prompb.MetricMetadata_COUNTER}},
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "method", Value: "GET"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: now - 1000}, {Value: 2, Timestamp: now}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "request_duration_seconds_bucket"}, {Name: "le", Value: "0.5"}},
+				Samples: []prompb.Sample{{Value: 3, Timestamp: now}},
+			},
+		},
+	})
+
+	r = httptest.NewRequest(http.MethodPost, "/metrics", nil)
+	r.RemoteAddr = "10.0.0.1:4321"
+	c.analyze(r, &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{{Name: "method", Value: "GET"}, {Name: "__name__", Value: "http_requests_total"}, {Name: "status", Value: ""}},
+				Samples: []prompb.Sample{
+					{Value: 1, Timestamp: now},
+					{Value: 2, Timestamp: now - 1000},
+					{Value: 3, Timestamp: 0},
+					{Value: 4, Timestamp: now + time.Hour.Milliseconds()},
+					{Value: 5, Timestamp: now - 2*time.Hour.Milliseconds()},
+				},
+			},
+			{Labels: []prompb.Label{{Name: "__name__", Value: "latency_bucket"}, {Name: "job", Value: "a"}, {Name: "job", Value: "b"}}},
+			{Labels: []prompb.Label{{Name: "job", Value: "a"}}, Histograms: []prompb.Histogram{{Timestamp: now}}},
+		},
+	})
+	c.recordUndecodable(r)
+
+	report := c.report("")
+	require.Len(t, report.Senders, 2)
+
+	sender := report.Senders[0]
+	assert.Equal(t, "10.0.0.1", sender.Sender)
+	assert.False(t, sender.Compliant)
+	assert.Equal(t, int64(2), sender.Requests)
+	assert.Equal(t, int64(3), sender.Series)
+	assert.Equal(t, int64(5), sender.Samples)
+	assert.Equal(t, int64(1), sender.NativeHistograms)
+	assert.Equal(t, int64(1), sender.ClassicHistogramSeries)
+	assert.Equal(t, ComplianceIssues{
+		UndecodableRequests:     1,
+		MissingMetricName:       1,
+		UnsortedLabels:          1,
+		DuplicateLabels:         1,
+		EmptyLabelValues:        1,
+		EmptySeries:             1,
+		OutOfOrderSamples:       3,
+		ZeroTimestamps:          1,
+		FutureTimestamps:        1,
+		StaleTimestamps:         1,
+		RequestsWithoutMetadata: 1,
+		BucketsWithoutLe:        1,
+	}, sender.Issues)
+
+	sender = report.Senders[1]
+	assert.Equal(t, SenderComplianceReport{
+		FirstSeen:              jan20,
+		LastSeen:               jan20,
+		Sender:                 "team-a",
+		UserAgent:              "Prometheus/2.53.0",
+		RemoteWriteVersion:     "0.1.0",
+		Requests:               1,
+		Series:                 2,
+		Samples:                3,
+		ClassicHistogramSeries: 1,
+		Compliant:              true,
+	}, sender)
+
+	assert.Len(t, c.report("team-a").Senders, 1)
+	assert.Empty(t, c.report("team-b").Senders)
+}
+
+func TestComplianceHandlers(t *testing.T) {
+	c := newCompliance(ComplianceConfig{})
+	mc := make(chan pmetric.Metrics, 1)
+	handler := newHandler(newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), &serverConfig{
+		Reporter:   newMockReporter(),
+		Compliance: c,
+	}, mc)
+
+	data, err := proto.Marshal(sampleCounterWq())
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(snappy.Encode(nil, data))))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	// nothing is forwarded in compliance report mode
+	assert.Empty(t, mc)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/metrics", strings.NewReader("not snappy")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	c.handler(rec, httptest.NewRequest(http.MethodGet, "/debug/compliance", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report ComplianceReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.Senders, 1)
+	assert.Equal(t, int64(2), report.Senders[0].Requests)
+	assert.Equal(t, int64(1), report.Senders[0].Issues.UndecodableRequests)
+
+	rec = httptest.NewRecorder()
+	c.handler(rec, httptest.NewRequest(http.MethodPost, "/debug/compliance", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
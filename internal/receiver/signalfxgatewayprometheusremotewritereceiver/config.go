@@ -16,18 +16,285 @@ package signalfxgatewayprometheusremotewritereceiver
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.uber.org/multierr"
+
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
 )
 
 var _ component.Config = (*Config)(nil)
 
+const (
+	// StaleMarkersDrop drops staleness markers, counting them like other NaN samples.
+	StaleMarkersDrop = "drop"
+	// StaleMarkersNoRecordedValue translates staleness markers as datapoints flagged with no
+	// recorded value, the OTLP equivalent of Prometheus staleness markers.
+	StaleMarkersNoRecordedValue = "no_recorded_value"
+)
+
 type Config struct {
 	ListenPath              string `mapstructure:"path"`
 	confighttp.ServerConfig `mapstructure:",squash"`
-	BufferSize              int `mapstructure:"buffer_size"`
+	// Limits limits the write requests served concurrently.
+	transport.Limits `mapstructure:",squash"`
+	BufferSize       int `mapstructure:"buffer_size"`
+	// IngestStats configures an endpoint reporting per-metric-name ingest volume.
+	IngestStats IngestStatsConfig `mapstructure:"ingest_stats"`
+	// TLSMetadata configures recording the TLS connection metadata of senders.
+	TLSMetadata TLSMetadataConfig `mapstructure:"tls_metadata"`
+	// Quotas configures ingest quotas per write path or tenant.
+	Quotas QuotasConfig `mapstructure:"quotas"`
+	// ResourceDetection configures merging detected host and cloud resource attributes.
+	ResourceDetection ResourceDetectionConfig `mapstructure:"resource_detection"`
+	// AttributeLimits caps the length and number of datapoint attributes.
+	AttributeLimits AttributeLimitsConfig `mapstructure:"attribute_limits"`
+	// TypeRules type the series of families the sender sent no metadata for by their metric name,
+	// before the naming conventions.
+	TypeRules []TypeRuleConfig `mapstructure:"type_rules"`
+	// IncludeMetrics are regexes matched against whole metric names. If set, only the series of
+	// the matching metrics are translated.
+	IncludeMetrics []string `mapstructure:"include_metrics"`
+	// ExcludeMetrics are regexes matched against whole metric names. The series of the matching
+	// metrics are dropped before they're translated.
+	ExcludeMetrics []string `mapstructure:"exclude_metrics"`
+	// RelabelConfigs are Prometheus relabel configs applied to the labels of the received series,
+	// rewriting or removing labels and dropping series before they're translated.
+	RelabelConfigs []RelabelConfig `mapstructure:"relabel_configs"`
+	// StaleMarkers is how the staleness markers Prometheus sends once a series disappears are
+	// translated, StaleMarkersDrop or StaleMarkersNoRecordedValue.
+	StaleMarkers string `mapstructure:"stale_markers"`
+	// PromoteJobInstance translates the job and instance labels to the service.name and
+	// service.instance.id resource attributes instead of datapoint attributes.
+	PromoteJobInstance bool `mapstructure:"promote_job_instance"`
+	// TargetInfo configures folding the labels of target_info metrics into the resource attributes
+	// of the series of their target.
+	TargetInfo TargetInfoConfig `mapstructure:"target_info"`
+	// ExtraAttributes are added to the resource attributes of all the received metrics, e.g. to tag
+	// them by the environment or cluster of their senders.
+	ExtraAttributes map[string]string `mapstructure:"extra_attributes"`
+	// Compliance configures the sender compliance report mode.
+	Compliance ComplianceConfig `mapstructure:"compliance"`
+	// JSONWrite configures a write path accepting timeseries in JSON.
+	JSONWrite JSONWriteConfig `mapstructure:"json_write"`
+	// ColumnarBatching configures the experimental columnar batching mode.
+	ColumnarBatching ColumnarBatchingConfig `mapstructure:"columnar_batching"`
+	// Reassembly configures reassembling the series of Prometheus summaries and histograms into
+	// OTLP summaries and histograms.
+	Reassembly ReassemblyConfig `mapstructure:"reassembly"`
+	// SenderHeartbeat configures the per sender heartbeat internal metrics.
+	SenderHeartbeat SenderHeartbeatConfig `mapstructure:"sender_heartbeat"`
+	// StartTimestamps configures tracking the start timestamps of cumulative series.
+	StartTimestamps StartTimestampsConfig `mapstructure:"start_timestamps"`
+	// SeriesCache is the series_cache extension the metadata of metric families and the start
+	// timestamps of series are kept in, bounding and persisting them. Without it, they're kept
+	// in memory.
+	SeriesCache *component.ID `mapstructure:"series_cache"`
+	// ReportConsumerErrors answers write requests only once the next consumer consumed their data,
+	// failing them if it returned an error, instead of once the data was buffered.
+	ReportConsumerErrors bool `mapstructure:"report_consumer_errors"`
+}
+
+// TypeRuleConfig types the series whose metric name matches its regex or ends with its suffix,
+// accommodating site-specific naming conventions.
+type TypeRuleConfig struct {
+	// Regex is matched against whole metric names.
+	Regex string `mapstructure:"regex"`
+	// Suffix is matched against the end of metric names.
+	Suffix string `mapstructure:"suffix"`
+	// Type is the type of the matching series, one of gauge, counter or histogram.
+	Type string `mapstructure:"type"`
+}
+
+// RelabelConfig is a Prometheus relabel config, with the same fields and defaults as the
+// relabel_configs of Prometheus scrape configs.
+type RelabelConfig struct {
+	// Replacement is the value of the target label of the replace action, with the capture groups
+	// of the regex, "$1" by default.
+	Replacement *string `mapstructure:"replacement"`
+	// Separator joins the values of the source labels, ";" by default.
+	Separator string `mapstructure:"separator"`
+	// Regex is matched against the joined values of the source labels, or the names of the labels
+	// of the labelmap, labeldrop and labelkeep actions, "(.*)" by default.
+	Regex string `mapstructure:"regex"`
+	// TargetLabel is the label set by the replace, hashmod, lowercase and uppercase actions.
+	TargetLabel string `mapstructure:"target_label"`
+	// Action is the relabel action, replace by default.
+	Action string `mapstructure:"action"`
+	// SourceLabels are the labels whose values are joined and matched against the regex.
+	SourceLabels []string `mapstructure:"source_labels"`
+	// Modulus is the modulus of the hashmod action.
+	Modulus uint64 `mapstructure:"modulus"`
+}
+
+// SenderHeartbeatConfig configures the prw.sender.up and prw.sender.last_write internal metrics,
+// reporting per sender whether and when it last wrote, so Prometheus instances that silently stop
+// remote writing can be alerted on.
+type SenderHeartbeatConfig struct {
+	// SenderHeader is the request header identifying the sender of a write request. Requests
+	// without it are identified by their authenticated principal, else by their remote IP.
+	SenderHeader string `mapstructure:"sender_header"`
+	// StaleAfter is the duration without write requests after which a sender is reported down.
+	StaleAfter time.Duration `mapstructure:"stale_after"`
+	// ExpireAfter is the duration without write requests after which a sender isn't reported anymore.
+	ExpireAfter time.Duration `mapstructure:"expire_after"`
+	// Enabled toggles the heartbeat metrics.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// TargetInfoConfig configures folding the labels of the target_info metric Prometheus exposes the
+// resource attributes of OpenTelemetry instrumented targets as into the resource attributes of all the
+// series sharing its job and instance, instead of translating it as a gauge. The labels of the
+// target_info of each target are kept until it isn't received for the expiry duration.
+type TargetInfoConfig struct {
+	// ExpireAfter is the duration without target_info after which the labels of a target are forgotten.
+	ExpireAfter time.Duration `mapstructure:"expire_after"`
+	// Enabled toggles folding target_info.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// StartTimestampsConfig configures tracking the start timestamps of cumulative series whose
+// sender doesn't send created timestamps, like remote write 1.0 senders. Their sums and histograms
+// are otherwise translated with the timestamp of each sample as start timestamp, which breaks
+// computing deltas downstream. The start timestamp of a series is the timestamp it was first seen
+// at, and is reset to the timestamp of a sample whose value decreased.
+type StartTimestampsConfig struct {
+	// ExpireAfter is the duration without samples after which a series kept in memory is
+	// forgotten. It doesn't apply to the series kept in the series_cache extension.
+	ExpireAfter time.Duration `mapstructure:"expire_after"`
+	// Enabled toggles tracking the start timestamps.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ColumnarBatchingConfig configures the experimental columnar batching mode. The samples of
+// write requests are accumulated into columns of series, timestamps and values and translated
+// in batches, with a single metric per metric name and the attributes of each series built
+// once. This is cheaper than translating each write request for very high series counts, and
+// produces the large, uniform batches columnar exporters like OTel-Arrow compress best.
+type ColumnarBatchingConfig struct {
+	// MaxSamples is the number of samples from which a batch is sent.
+	MaxSamples int `mapstructure:"max_samples"`
+	// FlushInterval is the maximum duration samples are accumulated before being sent.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// Enabled toggles the columnar batching mode.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ReassemblyConfig configures reassembling the quantile, _sum and _count series of Prometheus
+// summaries and the _bucket, _sum and _count series of Prometheus histograms, translated as
+// independent gauges and counters by default, into OTLP summaries and explicit bucket histograms.
+// Senders shard the series of a metric across write requests, so they're collected for a window
+// before being reassembled.
+type ReassemblyConfig struct {
+	// Window is the duration the series of a sample are collected for since the first of them
+	// was received.
+	Window time.Duration `mapstructure:"window"`
+	// Summaries toggles reassembling summaries.
+	Summaries bool `mapstructure:"summaries"`
+	// Histograms toggles reassembling histograms.
+	Histograms bool `mapstructure:"histograms"`
+}
+
+// ComplianceConfig configures the sender compliance report mode. Instead of forwarding the
+// received data, write requests are analyzed for remote write specification compliance and a
+// report per sender is served, e.g. to validate Prometheus instances before onboarding them.
+type ComplianceConfig struct {
+	// Path on which the report is served. Must differ from the write path.
+	Path string `mapstructure:"path"`
+	// SenderHeader is the request header identifying the sender of a write request. Requests
+	// without it are identified by their remote address.
+	SenderHeader string `mapstructure:"sender_header"`
+	// Enabled toggles the compliance report mode.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// JSONWriteConfig configures a write path accepting timeseries in a simple JSON representation,
+// translated like remote write requests, so tests and scripts can write metrics with curl instead
+// of building snappy compressed protobuf payloads.
+type JSONWriteConfig struct {
+	// Path on which the JSON write requests are served. Must differ from the write path.
+	Path string `mapstructure:"path"`
+	// Enabled toggles the JSON write path.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// AttributeLimitsConfig caps the length of datapoint attribute values and the number of attributes
+// per datapoint, protecting downstream systems from senders that put unbounded content like entire
+// SQL queries into labels.
+type AttributeLimitsConfig struct {
+	// TruncationMarker is appended to truncated attribute values.
+	TruncationMarker string `mapstructure:"truncation_marker"`
+	// MaxValueLength is the maximum length in bytes of attribute values, excluding the truncation
+	// marker. 0 doesn't limit it.
+	MaxValueLength int `mapstructure:"max_value_length"`
+	// MaxCount is the maximum number of attributes per datapoint. Labels are kept in the order they
+	// were sent, which remote write senders are required to sort by name. 0 doesn't limit it.
+	MaxCount int `mapstructure:"max_count"`
+}
+
+// ResourceDetectionConfig configures merging the resource attributes detected by resourcedetection
+// processor detectors into the ingested metrics, so samples pushed by local Prometheus sidecars
+// get the same host and cloud context as natively collected metrics.
+type ResourceDetectionConfig struct {
+	// Detectors are the resourcedetection processor detectors to run, e.g. env, system, ec2 or gcp.
+	Detectors []string `mapstructure:"detectors"`
+	// Timeout is the timeout of the detection.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Override replaces the resource attributes of the ingested metrics with the detected ones.
+	// By default attributes the metrics already have are kept.
+	Override bool `mapstructure:"override"`
+	// Enabled toggles the detection.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// QuotasConfig configures the number of samples each write path or tenant may write per hour,
+// to enforce fair use of shared gateways. Writes exceeding the quota are rejected with a 429.
+type QuotasConfig struct {
+	// TenantHeader is the request header identifying the tenant of a write request, e.g.
+	// X-Scope-OrgID. Requests without it are accounted to their path.
+	TenantHeader string `mapstructure:"tenant_header"`
+	// Limits are the quotas of specific write paths or tenants.
+	Limits []QuotaLimitConfig `mapstructure:"limits"`
+	// DefaultSamplesPerHour is the quota of every path without a specific limit, and the quota
+	// shared by all tenants without a specific limit. 0 doesn't limit them.
+	DefaultSamplesPerHour int64 `mapstructure:"default_samples_per_hour"`
+	// Enabled toggles the quotas.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// QuotaLimitConfig is the quota of a write path or tenant.
+type QuotaLimitConfig struct {
+	// Path is the write path the quota applies to. Paths other than the receiver's path
+	// are served as additional write paths.
+	Path string `mapstructure:"path"`
+	// Tenant is the value of the tenant header the quota applies to.
+	Tenant string `mapstructure:"tenant"`
+	// SamplesPerHour is the number of samples that may be written per hour.
+	SamplesPerHour int64 `mapstructure:"samples_per_hour"`
+}
+
+// TLSMetadataConfig configures recording the negotiated TLS version and cipher suite and the
+// client certificate subject of each write request as resource attributes, e.g. to audit which
+// senders still use outdated TLS versions before enforcing a minimum version.
+type TLSMetadataConfig struct {
+	// Enabled toggles recording the TLS connection metadata. Has no effect without TLS.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// IngestStatsConfig configures the per-metric-name ingest statistics endpoint, which reports the
+// top metric names by samples/sec and series count observed over the last completed interval.
+type IngestStatsConfig struct {
+	// Path on which the statistics are served. Must differ from the write path.
+	Path string `mapstructure:"path"`
+	// Interval over which samples and series are accumulated before being reported.
+	Interval time.Duration `mapstructure:"interval"`
+	// TopN is the default number of metric names reported.
+	TopN int `mapstructure:"top_n"`
+	// Enabled toggles the statistics endpoint and its tracking.
+	Enabled bool `mapstructure:"enabled"`
 }
 
 func (c *Config) Validate() error {
@@ -38,8 +305,160 @@ func (c *Config) Validate() error {
 	if c.BufferSize < 0 {
 		errs = append(errs, errors.New("buffer size must be non-negative"))
 	}
+	if err := c.Limits.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.IngestStats.Enabled {
+		if c.IngestStats.Path == "" {
+			errs = append(errs, errors.New("ingest_stats path must not be empty"))
+		} else if c.IngestStats.Path == c.ListenPath {
+			errs = append(errs, errors.New("ingest_stats path must differ from the write path"))
+		}
+		if c.IngestStats.Interval <= 0 {
+			errs = append(errs, errors.New("ingest_stats interval must be positive"))
+		}
+		if c.IngestStats.TopN <= 0 {
+			errs = append(errs, errors.New("ingest_stats top_n must be positive"))
+		}
+	}
+	if c.Quotas.Enabled {
+		errs = append(errs, c.validateQuotas()...)
+	}
+	if c.ResourceDetection.Enabled {
+		if len(c.ResourceDetection.Detectors) == 0 {
+			errs = append(errs, errors.New("resource_detection detectors must not be empty"))
+		}
+		if c.ResourceDetection.Timeout <= 0 {
+			errs = append(errs, errors.New("resource_detection timeout must be positive"))
+		}
+	}
+	if c.Compliance.Enabled {
+		switch c.Compliance.Path {
+		case "":
+			errs = append(errs, errors.New("compliance path must not be empty"))
+		case c.ListenPath:
+			errs = append(errs, errors.New("compliance path must differ from the write path"))
+		}
+		if c.IngestStats.Enabled && c.Compliance.Path == c.IngestStats.Path {
+			errs = append(errs, errors.New("compliance path must differ from the ingest_stats path"))
+		}
+	}
+	if c.JSONWrite.Enabled {
+		switch c.JSONWrite.Path {
+		case "":
+			errs = append(errs, errors.New("json_write path must not be empty"))
+		case c.ListenPath:
+			errs = append(errs, errors.New("json_write path must differ from the write path"))
+		}
+		if c.IngestStats.Enabled && c.JSONWrite.Path == c.IngestStats.Path {
+			errs = append(errs, errors.New("json_write path must differ from the ingest_stats path"))
+		}
+		if c.Compliance.Enabled && c.JSONWrite.Path == c.Compliance.Path {
+			errs = append(errs, errors.New("json_write path must differ from the compliance path"))
+		}
+	}
+	if c.ColumnarBatching.Enabled {
+		if c.ColumnarBatching.MaxSamples <= 0 {
+			errs = append(errs, errors.New("columnar_batching max_samples must be positive"))
+		}
+		if c.ColumnarBatching.FlushInterval <= 0 {
+			errs = append(errs, errors.New("columnar_batching flush_interval must be positive"))
+		}
+		if c.TLSMetadata.Enabled {
+			errs = append(errs, errors.New("columnar_batching can't be combined with tls_metadata"))
+		}
+		if c.ReportConsumerErrors {
+			errs = append(errs, errors.New("columnar_batching can't be combined with report_consumer_errors"))
+		}
+	}
+	if c.Reassembly.Summaries || c.Reassembly.Histograms {
+		if c.Reassembly.Window <= 0 {
+			errs = append(errs, errors.New("reassembly window must be positive"))
+		}
+		if c.ColumnarBatching.Enabled {
+			errs = append(errs, errors.New("reassembly can't be combined with columnar_batching"))
+		}
+	}
+	if c.SenderHeartbeat.Enabled {
+		if c.SenderHeartbeat.StaleAfter <= 0 {
+			errs = append(errs, errors.New("sender_heartbeat stale_after must be positive"))
+		}
+		if c.SenderHeartbeat.ExpireAfter <= c.SenderHeartbeat.StaleAfter {
+			errs = append(errs, errors.New("sender_heartbeat expire_after must be greater than stale_after"))
+		}
+	}
+	if c.StartTimestamps.Enabled && c.StartTimestamps.ExpireAfter <= 0 {
+		errs = append(errs, errors.New("start_timestamps expire_after must be positive"))
+	}
+	if c.TargetInfo.Enabled && c.TargetInfo.ExpireAfter <= 0 {
+		errs = append(errs, errors.New("target_info expire_after must be positive"))
+	}
+	if _, ok := c.ExtraAttributes[""]; ok {
+		errs = append(errs, errors.New("extra_attributes keys must not be empty"))
+	}
+	if c.AttributeLimits.MaxValueLength < 0 {
+		errs = append(errs, errors.New("attribute_limits max_value_length must be non-negative"))
+	}
+	if c.AttributeLimits.MaxCount < 0 {
+		errs = append(errs, errors.New("attribute_limits max_count must be non-negative"))
+	}
+	for i, rule := range c.TypeRules {
+		if (rule.Regex == "") == (rule.Suffix == "") {
+			errs = append(errs, fmt.Errorf("type_rules[%d] must specify exactly one of regex or suffix", i))
+		} else if rule.Regex != "" {
+			if _, err := compileTypeRuleRegex(rule.Regex); err != nil {
+				errs = append(errs, fmt.Errorf("type_rules[%d] regex is invalid: %w", i, err))
+			}
+		}
+		if _, ok := typeRuleTypes[rule.Type]; !ok {
+			errs = append(errs, fmt.Errorf(`type_rules[%d] type must be one of "gauge", "counter" or "histogram"`, i))
+		}
+	}
+	if _, err := newMetricFilter(c.IncludeMetrics, c.ExcludeMetrics); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newRelabelConfigs(c.RelabelConfigs); err != nil {
+		errs = append(errs, err)
+	}
+	if c.StaleMarkers != StaleMarkersDrop && c.StaleMarkers != StaleMarkersNoRecordedValue {
+		errs = append(errs, fmt.Errorf("stale_markers must be one of %q or %q", StaleMarkersDrop, StaleMarkersNoRecordedValue))
+	}
 	if errs != nil {
 		return multierr.Combine(errs...)
 	}
 	return nil
 }
+
+func (c *Config) validateQuotas() []error {
+	var errs []error
+	if c.Quotas.DefaultSamplesPerHour < 0 {
+		errs = append(errs, errors.New("quotas default_samples_per_hour must be non-negative"))
+	}
+	paths, tenants := map[string]struct{}{}, map[string]struct{}{}
+	for i, limit := range c.Quotas.Limits {
+		switch {
+		case (limit.Path == "") == (limit.Tenant == ""):
+			errs = append(errs, fmt.Errorf("quotas limits[%d] must specify exactly one of path or tenant", i))
+		case limit.Path != "":
+			if _, ok := paths[limit.Path]; ok {
+				errs = append(errs, fmt.Errorf("quotas limits[%d] path %q is specified more than once", i, limit.Path))
+			}
+			paths[limit.Path] = struct{}{}
+			if c.IngestStats.Enabled && limit.Path == c.IngestStats.Path {
+				errs = append(errs, fmt.Errorf("quotas limits[%d] path must differ from the ingest_stats path", i))
+			}
+		default:
+			if _, ok := tenants[limit.Tenant]; ok {
+				errs = append(errs, fmt.Errorf("quotas limits[%d] tenant %q is specified more than once", i, limit.Tenant))
+			}
+			tenants[limit.Tenant] = struct{}{}
+			if c.Quotas.TenantHeader == "" {
+				errs = append(errs, fmt.Errorf("quotas limits[%d] tenant requires tenant_header", i))
+			}
+		}
+		if limit.SamplesPerHour <= 0 {
+			errs = append(errs, fmt.Errorf("quotas limits[%d] samples_per_hour must be positive", i))
+		}
+	}
+	return errs
+}
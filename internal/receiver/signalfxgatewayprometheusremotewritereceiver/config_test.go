@@ -16,6 +16,7 @@ package signalfxgatewayprometheusremotewritereceiver
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,6 +34,270 @@ func TestValidateConfigAndDefaults(t *testing.T) {
 	assert.Equal(t, "localhost:19291", cfg.ServerConfig.Endpoint)
 	assert.Equal(t, "/metrics", cfg.ListenPath)
 	assert.Equal(t, 100, cfg.BufferSize)
+	assert.Zero(t, cfg.MaxConcurrentRequests)
+	assert.Equal(t, IngestStatsConfig{Path: "/debug/ingest_stats", Interval: time.Minute, TopN: 20}, cfg.IngestStats)
+	assert.False(t, cfg.TLSMetadata.Enabled)
+	assert.Equal(t, QuotasConfig{}, cfg.Quotas)
+	assert.Equal(t, ResourceDetectionConfig{Detectors: []string{"env", "system"}, Timeout: 5 * time.Second}, cfg.ResourceDetection)
+	assert.Equal(t, AttributeLimitsConfig{TruncationMarker: "..."}, cfg.AttributeLimits)
+	assert.Empty(t, cfg.TypeRules)
+	assert.Empty(t, cfg.IncludeMetrics)
+	assert.Empty(t, cfg.ExcludeMetrics)
+	assert.Empty(t, cfg.RelabelConfigs)
+	assert.Equal(t, StaleMarkersDrop, cfg.StaleMarkers)
+	assert.False(t, cfg.PromoteJobInstance)
+	assert.Equal(t, TargetInfoConfig{ExpireAfter: time.Hour}, cfg.TargetInfo)
+	assert.Empty(t, cfg.ExtraAttributes)
+	assert.Equal(t, ComplianceConfig{Path: "/debug/compliance"}, cfg.Compliance)
+	assert.Equal(t, JSONWriteConfig{Path: "/write/json"}, cfg.JSONWrite)
+	assert.Equal(t, ColumnarBatchingConfig{MaxSamples: 8192, FlushInterval: time.Second}, cfg.ColumnarBatching)
+	assert.Equal(t, ReassemblyConfig{Window: 5 * time.Second}, cfg.Reassembly)
+	assert.Equal(t, SenderHeartbeatConfig{StaleAfter: 5 * time.Minute, ExpireAfter: 24 * time.Hour}, cfg.SenderHeartbeat)
+	assert.Equal(t, StartTimestampsConfig{ExpireAfter: time.Hour}, cfg.StartTimestamps)
+	assert.Nil(t, cfg.SeriesCache)
+	assert.False(t, cfg.ReportConsumerErrors)
+}
+
+func TestValidateSenderHeartbeat(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SenderHeartbeat.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.SenderHeartbeat.ExpireAfter = cfg.SenderHeartbeat.StaleAfter
+	assert.ErrorContains(t, cfg.Validate(), "sender_heartbeat expire_after must be greater than stale_after")
+
+	cfg.SenderHeartbeat.StaleAfter = 0
+	assert.ErrorContains(t, cfg.Validate(), "sender_heartbeat stale_after must be positive")
+}
+
+func TestValidateColumnarBatching(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ColumnarBatching.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.ColumnarBatching.MaxSamples = 0
+	cfg.ColumnarBatching.FlushInterval = 0
+	cfg.TLSMetadata.Enabled = true
+	cfg.ReportConsumerErrors = true
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "columnar_batching max_samples must be positive")
+	assert.ErrorContains(t, err, "columnar_batching flush_interval must be positive")
+	assert.ErrorContains(t, err, "columnar_batching can't be combined with tls_metadata")
+	assert.ErrorContains(t, err, "columnar_batching can't be combined with report_consumer_errors")
+}
+
+func TestValidateReassembly(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Reassembly.Summaries = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Reassembly.Window = 0
+	cfg.ColumnarBatching.Enabled = true
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "reassembly window must be positive")
+	assert.ErrorContains(t, err, "reassembly can't be combined with columnar_batching")
+
+	cfg.Reassembly.Summaries = false
+	cfg.Reassembly.Histograms = true
+	assert.ErrorContains(t, cfg.Validate(), "reassembly window must be positive")
+}
+
+func TestValidateCompliance(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Compliance.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Compliance.Path = cfg.ListenPath
+	assert.ErrorContains(t, cfg.Validate(), "compliance path must differ from the write path")
+
+	cfg.IngestStats.Enabled = true
+	cfg.Compliance.Path = cfg.IngestStats.Path
+	assert.ErrorContains(t, cfg.Validate(), "compliance path must differ from the ingest_stats path")
+
+	cfg.Compliance.Path = ""
+	assert.ErrorContains(t, cfg.Validate(), "compliance path must not be empty")
+}
+
+func TestValidateJSONWrite(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.JSONWrite.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.JSONWrite.Path = cfg.ListenPath
+	assert.ErrorContains(t, cfg.Validate(), "json_write path must differ from the write path")
+
+	cfg.IngestStats.Enabled = true
+	cfg.JSONWrite.Path = cfg.IngestStats.Path
+	assert.ErrorContains(t, cfg.Validate(), "json_write path must differ from the ingest_stats path")
+
+	cfg.Compliance.Enabled = true
+	cfg.JSONWrite.Path = cfg.Compliance.Path
+	assert.ErrorContains(t, cfg.Validate(), "json_write path must differ from the compliance path")
+
+	cfg.JSONWrite.Path = ""
+	assert.ErrorContains(t, cfg.Validate(), "json_write path must not be empty")
+}
+
+func TestValidateAttributeLimits(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AttributeLimits.MaxValueLength = -1
+	cfg.AttributeLimits.MaxCount = -1
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "attribute_limits max_value_length must be non-negative")
+	assert.ErrorContains(t, err, "attribute_limits max_count must be non-negative")
+}
+
+func TestValidateStaleMarkers(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StaleMarkers = StaleMarkersNoRecordedValue
+	assert.NoError(t, cfg.Validate())
+
+	cfg.StaleMarkers = "close"
+	assert.EqualError(t, cfg.Validate(), `stale_markers must be one of "drop" or "no_recorded_value"`)
+}
+
+func TestValidateTypeRules(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TypeRules = []TypeRuleConfig{
+		{Suffix: "_requests", Type: "counter"},
+		{Regex: "legacy_.*_(current|max)", Type: "gauge"},
+		{Suffix: "_latency", Type: "histogram"},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.TypeRules = []TypeRuleConfig{
+		{Type: "counter"},
+		{Suffix: "_requests", Regex: ".*_requests", Type: "counter"},
+		{Regex: "legacy_(", Type: "gauge"},
+		{Suffix: "_info", Type: "info"},
+	}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "type_rules[0] must specify exactly one of regex or suffix")
+	assert.ErrorContains(t, err, "type_rules[1] must specify exactly one of regex or suffix")
+	assert.ErrorContains(t, err, "type_rules[2] regex is invalid")
+	assert.ErrorContains(t, err, `type_rules[3] type must be one of "gauge", "counter" or "histogram"`)
+}
+
+func TestValidateMetricFilter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.IncludeMetrics = []string{"http_.*"}
+	cfg.ExcludeMetrics = []string{"go_.*", "process_.*"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.IncludeMetrics = []string{"http_("}
+	assert.ErrorContains(t, cfg.Validate(), "include_metrics[0] regex is invalid")
+}
+
+func TestValidateRelabelConfigs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RelabelConfigs = []RelabelConfig{
+		{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: "drop"},
+		{Regex: "pod_uid", Action: "labeldrop"},
+		{SourceLabels: []string{"instance"}, Regex: "([^:]+):.*", TargetLabel: "host"},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RelabelConfigs = []RelabelConfig{{SourceLabels: []string{"instance"}, Regex: "(", TargetLabel: "host"}}
+	assert.ErrorContains(t, cfg.Validate(), "relabel_configs[0] is invalid: regex is invalid")
+	cfg.RelabelConfigs = []RelabelConfig{{Action: "rename"}}
+	assert.EqualError(t, cfg.Validate(), `relabel_configs[0] is invalid: unknown action "rename"`)
+	cfg.RelabelConfigs = []RelabelConfig{{SourceLabels: []string{"instance"}}}
+	assert.ErrorContains(t, cfg.Validate(), "relabel_configs[0] is invalid: relabel configuration for replace action requires 'target_label' value")
+}
+
+func TestValidateStartTimestamps(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StartTimestamps.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.StartTimestamps.ExpireAfter = 0
+	assert.EqualError(t, cfg.Validate(), "start_timestamps expire_after must be positive")
+}
+
+func TestValidateTargetInfo(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TargetInfo.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.TargetInfo.ExpireAfter = 0
+	assert.EqualError(t, cfg.Validate(), "target_info expire_after must be positive")
+}
+
+func TestValidateExtraAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ExtraAttributes = map[string]string{"k8s.cluster.name": "east"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.ExtraAttributes[""] = "prod"
+	assert.EqualError(t, cfg.Validate(), "extra_attributes keys must not be empty")
+}
+
+func TestValidateResourceDetection(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ResourceDetection.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.ResourceDetection.Detectors = nil
+	cfg.ResourceDetection.Timeout = 0
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "resource_detection detectors must not be empty")
+	assert.ErrorContains(t, err, "resource_detection timeout must be positive")
+}
+
+func TestValidateIngestStats(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.IngestStats.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.IngestStats.Path = cfg.ListenPath
+	cfg.IngestStats.Interval = 0
+	cfg.IngestStats.TopN = -1
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "ingest_stats path must differ from the write path")
+	assert.ErrorContains(t, err, "ingest_stats interval must be positive")
+	assert.ErrorContains(t, err, "ingest_stats top_n must be positive")
+}
+
+func TestValidateQuotas(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Quotas = QuotasConfig{
+		Enabled:               true,
+		TenantHeader:          "X-Scope-OrgID",
+		DefaultSamplesPerHour: 1000,
+		Limits: []QuotaLimitConfig{
+			{Path: "/metrics", SamplesPerHour: 100},
+			{Tenant: "team-a", SamplesPerHour: 100},
+		},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.IngestStats.Enabled = true
+	cfg.Quotas = QuotasConfig{
+		Enabled:               true,
+		DefaultSamplesPerHour: -1,
+		Limits: []QuotaLimitConfig{
+			{Path: "/metrics", Tenant: "team-a", SamplesPerHour: 100},
+			{SamplesPerHour: 100},
+			{Path: "/debug/ingest_stats", SamplesPerHour: 100},
+			{Path: "/debug/ingest_stats", SamplesPerHour: 100},
+			{Tenant: "team-a"},
+		},
+	}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "quotas default_samples_per_hour must be non-negative")
+	assert.ErrorContains(t, err, "quotas limits[0] must specify exactly one of path or tenant")
+	assert.ErrorContains(t, err, "quotas limits[1] must specify exactly one of path or tenant")
+	assert.ErrorContains(t, err, "quotas limits[2] path must differ from the ingest_stats path")
+	assert.ErrorContains(t, err, `quotas limits[3] path "/debug/ingest_stats" is specified more than once`)
+	assert.ErrorContains(t, err, "quotas limits[4] tenant requires tenant_header")
+	assert.ErrorContains(t, err, "quotas limits[4] samples_per_hour must be positive")
 }
 
 func TestLoadConfigFromFactory(t *testing.T) {
@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	// traceIDLabel and spanIDLabel are the exemplar labels of the trace and span the exemplar
+	// was recorded in, by the convention of Prometheus client libraries.
+	traceIDLabel = "trace_id"
+	spanIDLabel  = "span_id"
+)
+
+type exemplarDataPoint interface {
+	Timestamp() pcommon.Timestamp
+	Exemplars() pmetric.ExemplarSlice
+}
+
+// dataPointList is a list of datapoints, like the datapoints of a series in a metric shared
+// with other series.
+type dataPointList[D exemplarDataPoint] []D
+
+func (l dataPointList[D]) Len() int   { return len(l) }
+func (l dataPointList[D]) At(i int) D { return l[i] }
+
+// addExemplars adds the exemplars of a series to its datapoints. An exemplar is added to the
+// first datapoint at or after its timestamp, or to the last datapoint if it's after all of them,
+// since remote write senders send the exemplars recorded up to the last sample of the series.
+// The exemplars of a series without datapoints are dropped.
+func addExemplars[D exemplarDataPoint, S interface {
+	Len() int
+	At(int) D
+}](dps S, exemplars []prompb.Exemplar) {
+	if dps.Len() == 0 {
+		return
+	}
+	for _, exemplar := range exemplars {
+		timestamp := prometheusToOtelTimestamp(exemplar.Timestamp)
+		target := dps.At(dps.Len() - 1)
+		for i := 0; i < dps.Len(); i++ {
+			if dps.At(i).Timestamp() >= timestamp {
+				target = dps.At(i)
+				break
+			}
+		}
+		setExemplar(target.Exemplars().AppendEmpty(), exemplar)
+	}
+}
+
+// setExemplar sets the OTLP exemplar to the remote write exemplar, with the trace and span ids
+// of its trace_id and span_id labels. The other labels, and ids that aren't hex encoded, are
+// filtered attributes.
+func setExemplar(e pmetric.Exemplar, exemplar prompb.Exemplar) {
+	e.SetTimestamp(prometheusToOtelTimestamp(exemplar.Timestamp))
+	e.SetDoubleValue(exemplar.Value)
+	for _, label := range exemplar.Labels {
+		switch label.Name {
+		case traceIDLabel:
+			var traceID pcommon.TraceID
+			if decodeID(traceID[:], label.Value) {
+				e.SetTraceID(traceID)
+				continue
+			}
+		case spanIDLabel:
+			var spanID pcommon.SpanID
+			if decodeID(spanID[:], label.Value) {
+				e.SetSpanID(spanID)
+				continue
+			}
+		}
+		e.FilteredAttributes().PutStr(label.Name, label.Value)
+	}
+}
+
+// decodeID decodes the hex encoded id into dst, left padded with zeros like the 64-bit trace ids
+// of some tracers, and returns whether it's a valid non-zero id.
+func decodeID(dst []byte, value string) bool {
+	if value == "" || len(value) > 2*len(dst) {
+		return false
+	}
+	if len(value) < 2*len(dst) {
+		value = strings.Repeat("0", 2*len(dst)-len(value)) + value
+	}
+	if _, err := hex.Decode(dst, []byte(value)); err != nil {
+		return false
+	}
+	for _, b := range dst {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestSetExemplar(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		labels             []prompb.Label
+		expectedTraceID    pcommon.TraceID
+		expectedSpanID     pcommon.SpanID
+		expectedAttributes map[string]any
+	}{
+		{
+			name: "trace and span ids",
+			labels: []prompb.Label{
+				{Name: "trace_id", Value: "4bf92f3577b34da6a3ce929d0e0e4736"},
+				{Name: "span_id", Value: "00f067aa0ba902b7"},
+				{Name: "pod", Value: "api-0"},
+			},
+			expectedTraceID:    pcommon.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			expectedSpanID:     pcommon.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			expectedAttributes: map[string]any{"pod": "api-0"},
+		},
+		{
+			name:            "64-bit trace id",
+			labels:          []prompb.Label{{Name: "trace_id", Value: "a3ce929d0e0e4736"}},
+			expectedTraceID: pcommon.TraceID{8: 0xa3, 9: 0xce, 10: 0x92, 11: 0x9d, 12: 0x0e, 13: 0x0e, 14: 0x47, 15: 0x36},
+		},
+		{
+			name: "invalid ids",
+			labels: []prompb.Label{
+				{Name: "trace_id", Value: "not-a-trace-id"},
+				{Name: "span_id", Value: "0000000000000000"},
+			},
+			expectedAttributes: map[string]any{"trace_id": "not-a-trace-id", "span_id": "0000000000000000"},
+		},
+		{
+			name:               "span id too long",
+			labels:             []prompb.Label{{Name: "span_id", Value: "4bf92f3577b34da6a3"}},
+			expectedAttributes: map[string]any{"span_id": "4bf92f3577b34da6a3"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := pmetric.NewExemplar()
+			setExemplar(e, prompb.Exemplar{Labels: tt.labels, Value: 0.25, Timestamp: jan20.UnixMilli()})
+			assert.Equal(t, 0.25, e.DoubleValue())
+			assert.Equal(t, pcommon.NewTimestampFromTime(jan20), e.Timestamp())
+			assert.Equal(t, tt.expectedTraceID, e.TraceID())
+			assert.Equal(t, tt.expectedSpanID, e.SpanID())
+			if tt.expectedAttributes == nil {
+				tt.expectedAttributes = map[string]any{}
+			}
+			assert.Equal(t, tt.expectedAttributes, e.FilteredAttributes().AsRaw())
+		})
+	}
+}
+
+func exemplarWriteRequest() *prompb.WriteRequest {
+	exemplar := func(timestamp int64, traceID string) prompb.Exemplar {
+		return prompb.Exemplar{Labels: []prompb.Label{{Name: "trace_id", Value: traceID}}, Value: 0.5, Timestamp: timestamp}
+	}
+	return &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{{Name: "__name__", Value: "request_duration_seconds_bucket"}, {Name: "le", Value: "1"}},
+				Samples: []prompb.Sample{
+					{Value: 10, Timestamp: jan20.UnixMilli()},
+					{Value: 12, Timestamp: jan20.UnixMilli() + 10000},
+				},
+				Exemplars: []prompb.Exemplar{
+					exemplar(jan20.UnixMilli()-1000, "01"),
+					exemplar(jan20.UnixMilli()+5000, "02"),
+					exemplar(jan20.UnixMilli()+20000, "03"),
+				},
+			},
+			{
+				Labels:     []prompb.Label{{Name: "__name__", Value: "queue_wait_seconds"}},
+				Histograms: []prompb.Histogram{{Count: &prompb.Histogram_CountInt{CountInt: 1}, Sum: 0.5, Timestamp: jan20.UnixMilli()}},
+				Exemplars:  []prompb.Exemplar{exemplar(jan20.UnixMilli(), "04")},
+			},
+			{
+				Labels:    []prompb.Label{{Name: "__name__", Value: "temperature"}},
+				Samples:   []prompb.Sample{{Value: 21.5, Timestamp: jan20.UnixMilli()}},
+				Exemplars: []prompb.Exemplar{exemplar(jan20.UnixMilli(), "05")},
+			},
+		},
+	}
+}
+
+func exemplarTraceIDs(exemplars pmetric.ExemplarSlice) []byte {
+	var ids []byte
+	for i := 0; i < exemplars.Len(); i++ {
+		id := exemplars.At(i).TraceID()
+		ids = append(ids, id[15])
+	}
+	return ids
+}
+
+func assertExemplars(t *testing.T, md pmetric.Metrics) {
+	dps := findMetric(t, md, "request_duration_seconds_bucket").Sum().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	assert.Equal(t, []byte{1}, exemplarTraceIDs(dps.At(0).Exemplars()))
+	assert.Equal(t, []byte{2, 3}, exemplarTraceIDs(dps.At(1).Exemplars()))
+	e := dps.At(0).Exemplars().At(0)
+	assert.Equal(t, 0.5, e.DoubleValue())
+	assert.Equal(t, prometheusToOtelTimestamp(jan20.UnixMilli()-1000), e.Timestamp())
+
+	histogramDps := findMetric(t, md, "queue_wait_seconds").ExponentialHistogram().DataPoints()
+	require.Equal(t, 1, histogramDps.Len())
+	assert.Equal(t, []byte{4}, exemplarTraceIDs(histogramDps.At(0).Exemplars()))
+
+	dps = findMetric(t, md, "temperature").Gauge().DataPoints()
+	assert.Equal(t, []byte{5}, exemplarTraceIDs(dps.At(0).Exemplars()))
+}
+
+func TestExemplars(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	md, err := parser.fromPrometheusWriteRequestMetrics(exemplarWriteRequest())
+	require.NoError(t, err)
+	assertExemplars(t, md)
+}
+
+func TestColumnarBatchExemplars(t *testing.T) {
+	cb, mc := newTestColumnarBatch(100)
+	req := exemplarWriteRequest()
+	// the exemplars of a series are added to its datapoints of every request of the batch
+	second := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{req.Timeseries[0]}}
+	second.Timeseries[0].Samples = []prompb.Sample{{Value: 15, Timestamp: jan20.UnixMilli() + 30000}}
+	second.Timeseries[0].Exemplars = []prompb.Exemplar{{Labels: []prompb.Label{{Name: "trace_id", Value: "06"}}, Timestamp: jan20.UnixMilli() + 25000}}
+	require.NoError(t, cb.add(req))
+	require.NoError(t, cb.add(second))
+	cb.close(context.Background())
+	md := <-mc
+
+	dps := findMetric(t, md, "request_duration_seconds_bucket").Sum().DataPoints()
+	require.Equal(t, 3, dps.Len())
+	assert.Equal(t, []byte{1}, exemplarTraceIDs(dps.At(0).Exemplars()))
+	assert.Equal(t, []byte{2}, exemplarTraceIDs(dps.At(1).Exemplars()))
+	assert.Equal(t, []byte{3, 6}, exemplarTraceIDs(dps.At(2).Exemplars()))
+	histogramDps := findMetric(t, md, "queue_wait_seconds").ExponentialHistogram().DataPoints()
+	assert.Equal(t, []byte{4}, exemplarTraceIDs(histogramDps.At(0).Exemplars()))
+	dps = findMetric(t, md, "temperature").Gauge().DataPoints()
+	assert.Equal(t, []byte{5}, exemplarTraceIDs(dps.At(0).Exemplars()))
+
+	// a batch without exemplars
+	cb, mc = newTestColumnarBatch(100)
+	require.NoError(t, cb.add(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+		Samples: []prompb.Sample{{Value: 21.5, Timestamp: jan20.UnixMilli()}},
+	}}}))
+	cb.close(context.Background())
+	md = <-mc
+	assert.Equal(t, 0, findMetric(t, md, "temperature").Gauge().DataPoints().At(0).Exemplars().Len())
+}
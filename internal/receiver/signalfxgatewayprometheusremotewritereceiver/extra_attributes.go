@@ -0,0 +1,32 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// putExtraAttributes adds the configured extra attributes to the resource attributes of the metrics,
+// keeping the attributes the resources already have.
+func putExtraAttributes(md pmetric.Metrics, extra map[string]string) {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		attrs := md.ResourceMetrics().At(i).Resource().Attributes()
+		for k, v := range extra {
+			if _, ok := attrs.Get(k); !ok {
+				attrs.PutStr(k, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestPutExtraAttributes(t *testing.T) {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().Resource().Attributes().PutStr("deployment.environment", "staging")
+	md.ResourceMetrics().AppendEmpty()
+
+	putExtraAttributes(md, map[string]string{"deployment.environment": "prod", "k8s.cluster.name": "east"})
+
+	assert.Equal(t, map[string]any{"deployment.environment": "staging", "k8s.cluster.name": "east"},
+		md.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"deployment.environment": "prod", "k8s.cluster.name": "east"},
+		md.ResourceMetrics().At(1).Resource().Attributes().AsRaw())
+}
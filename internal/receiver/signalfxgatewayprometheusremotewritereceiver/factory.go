@@ -16,6 +16,7 @@ package signalfxgatewayprometheusremotewritereceiver
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
@@ -50,5 +51,41 @@ func createDefaultConfig() component.Config {
 		},
 		ListenPath: "/metrics",
 		BufferSize: 100,
+		IngestStats: IngestStatsConfig{
+			Path:     "/debug/ingest_stats",
+			Interval: time.Minute,
+			TopN:     20,
+		},
+		ResourceDetection: ResourceDetectionConfig{
+			Detectors: []string{"env", "system"},
+			Timeout:   5 * time.Second,
+		},
+		AttributeLimits: AttributeLimitsConfig{
+			TruncationMarker: "...",
+		},
+		StaleMarkers: StaleMarkersDrop,
+		Compliance: ComplianceConfig{
+			Path: "/debug/compliance",
+		},
+		JSONWrite: JSONWriteConfig{
+			Path: "/write/json",
+		},
+		ColumnarBatching: ColumnarBatchingConfig{
+			MaxSamples:    8192,
+			FlushInterval: time.Second,
+		},
+		Reassembly: ReassemblyConfig{
+			Window: 5 * time.Second,
+		},
+		SenderHeartbeat: SenderHeartbeatConfig{
+			StaleAfter:  5 * time.Minute,
+			ExpireAfter: 24 * time.Hour,
+		},
+		StartTimestamps: StartTimestampsConfig{
+			ExpireAfter: time.Hour,
+		},
+		TargetInfo: TargetInfoConfig{
+			ExpireAfter: time.Hour,
+		},
 	}
 }
@@ -0,0 +1,196 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal"
+)
+
+const (
+	sortBySamples = "samples"
+	sortBySeries  = "series"
+)
+
+// metricNameStats is the accumulated ingest volume of a single metric name for the current interval.
+type metricNameStats struct {
+	series  map[uint64]struct{}
+	samples int64
+}
+
+// MetricNameStats is the reported ingest volume of a single metric name over the last completed interval.
+type MetricNameStats struct {
+	Name             string  `json:"name"`
+	Samples          int64   `json:"samples"`
+	SamplesPerSecond float64 `json:"samples_per_second"`
+	Series           int     `json:"series"`
+}
+
+// IngestStatsReport is the document served by the ingest statistics endpoint.
+type IngestStatsReport struct {
+	IntervalStart time.Time         `json:"interval_start"`
+	IntervalEnd   time.Time         `json:"interval_end"`
+	Metrics       []MetricNameStats `json:"metrics"`
+	TotalMetrics  int               `json:"total_metric_names"`
+}
+
+// ingestStats tracks samples and distinct series per metric name. Counts are accumulated
+// for the current interval and reported for the last completed one, so the served document
+// is stable for the duration of an interval.
+type ingestStats struct {
+	now           func() time.Time
+	current       map[string]*metricNameStats
+	intervalStart time.Time
+	last          IngestStatsReport
+	interval      time.Duration
+	topN          int
+	mu            sync.Mutex
+}
+
+func newIngestStats(cfg IngestStatsConfig) *ingestStats {
+	is := &ingestStats{
+		now:      time.Now,
+		current:  map[string]*metricNameStats{},
+		interval: cfg.Interval,
+		topN:     cfg.TopN,
+	}
+	is.intervalStart = is.now()
+	return is
+}
+
+// record accounts for every named time series in the request.
+func (is *ingestStats) record(req *prompb.WriteRequest) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	is.rotate()
+	for _, ts := range req.Timeseries {
+		name, err := internal.ExtractMetricNameLabel(ts.Labels)
+		if err != nil {
+			continue
+		}
+		stats, ok := is.current[name]
+		if !ok {
+			stats = &metricNameStats{series: map[uint64]struct{}{}}
+			is.current[name] = stats
+		}
+		stats.samples += int64(len(ts.Samples) + len(ts.Histograms))
+		stats.series[labelsHash(ts.Labels)] = struct{}{}
+	}
+}
+
+// rotate moves the current interval into the last reported one once it has elapsed.
+// Must be called with the lock held.
+func (is *ingestStats) rotate() {
+	now := is.now()
+	elapsed := now.Sub(is.intervalStart)
+	if elapsed < is.interval {
+		return
+	}
+	seconds := elapsed.Seconds()
+	report := IngestStatsReport{
+		IntervalStart: is.intervalStart,
+		IntervalEnd:   now,
+		Metrics:       make([]MetricNameStats, 0, len(is.current)),
+		TotalMetrics:  len(is.current),
+	}
+	for name, stats := range is.current {
+		report.Metrics = append(report.Metrics, MetricNameStats{
+			Name:             name,
+			Samples:          stats.samples,
+			SamplesPerSecond: float64(stats.samples) / seconds,
+			Series:           len(stats.series),
+		})
+	}
+	is.last = report
+	is.current = map[string]*metricNameStats{}
+	is.intervalStart = now
+}
+
+// report returns the top n metric names of the last completed interval ordered by sortBy.
+func (is *ingestStats) report(sortBy string, n int) IngestStatsReport {
+	is.mu.Lock()
+	is.rotate()
+	report := is.last
+	metrics := make([]MetricNameStats, len(report.Metrics))
+	copy(metrics, report.Metrics)
+	is.mu.Unlock()
+
+	sort.Slice(metrics, func(i, j int) bool {
+		a, b := metrics[i], metrics[j]
+		if sortBy == sortBySeries && a.Series != b.Series {
+			return a.Series > b.Series
+		}
+		if a.Samples != b.Samples {
+			return a.Samples > b.Samples
+		}
+		return a.Name < b.Name
+	})
+	if n > 0 && len(metrics) > n {
+		metrics = metrics[:n]
+	}
+	report.Metrics = metrics
+	return report
+}
+
+// handler serves the last completed interval as json. The "sort" query parameter accepts
+// "samples" (default) or "series", and "limit" overrides the configured top_n.
+func (is *ingestStats) handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	sortBy := sortBySamples
+	if s := query.Get("sort"); s != "" {
+		if s != sortBySamples && s != sortBySeries {
+			http.Error(w, "sort must be one of \"samples\" or \"series\"", http.StatusBadRequest)
+			return
+		}
+		sortBy = s
+	}
+	n := is.topN
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		n = limit
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(is.report(sortBy, n))
+}
+
+// labelsHash identifies a series by its label set. Remote write senders are required to
+// send labels sorted by name, so no additional ordering is applied here.
+func labelsHash(labels []prompb.Label) uint64 {
+	h := fnv.New64a()
+	for _, label := range labels {
+		_, _ = h.Write([]byte(label.Name))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(label.Value))
+		_, _ = h.Write([]byte{0xff})
+	}
+	return h.Sum64()
+}
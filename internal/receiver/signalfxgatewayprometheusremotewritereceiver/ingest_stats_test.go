@@ -0,0 +1,117 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+func newTestIngestStats() (*ingestStats, *time.Time) {
+	is := newIngestStats(IngestStatsConfig{Interval: time.Minute, TopN: 2})
+	var now *time.Time
+	is.now, now = testclock.New(jan20)
+	is.intervalStart = *now
+	return is, now
+}
+
+func TestIngestStatsReportsLastInterval(t *testing.T) {
+	is, now := newTestIngestStats()
+
+	is.record(flattenWriteRequests(getWriteRequestsOfAllTypesWithoutMetadata()))
+	is.record(sampleCounterWq())
+	is.record(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{Labels: []prompb.Label{{Name: "method", Value: "GET"}}, Samples: []prompb.Sample{{Value: 1}}},
+	}})
+
+	// nothing is reported until the first interval completes
+	report := is.report(sortBySamples, 0)
+	assert.Empty(t, report.Metrics)
+
+	*now = now.Add(time.Minute)
+	report = is.report(sortBySamples, 0)
+	assert.Equal(t, jan20, report.IntervalStart)
+	assert.Equal(t, jan20.Add(time.Minute), report.IntervalEnd)
+	assert.Equal(t, 8, report.TotalMetrics)
+	require.Len(t, report.Metrics, 8)
+	assert.Equal(t, MetricNameStats{Name: "api_request_duration_seconds_bucket", Samples: 2, SamplesPerSecond: 2.0 / 60, Series: 2}, report.Metrics[0])
+	assert.Equal(t, MetricNameStats{Name: "http_requests_total", Samples: 2, SamplesPerSecond: 2.0 / 60, Series: 1}, report.Metrics[1])
+
+	bySeries := is.report(sortBySeries, 3)
+	require.Len(t, bySeries.Metrics, 3)
+	assert.Equal(t, "api_request_duration_seconds_bucket", bySeries.Metrics[0].Name)
+	assert.Equal(t, "request_duration_seconds", bySeries.Metrics[1].Name)
+	assert.Equal(t, 2, bySeries.Metrics[1].Series)
+
+	// the following interval had no traffic
+	*now = now.Add(time.Minute)
+	assert.Empty(t, is.report(sortBySamples, 0).Metrics)
+}
+
+func TestIngestStatsHandler(t *testing.T) {
+	is, now := newTestIngestStats()
+	is.record(flattenWriteRequests(getWriteRequestsOfAllTypesWithoutMetadata()))
+	*now = now.Add(time.Minute)
+
+	for _, tt := range []struct {
+		name          string
+		method        string
+		query         string
+		expectedNames []string
+		expectedCode  int
+	}{
+		{
+			name:          "default top_n",
+			method:        http.MethodGet,
+			expectedCode:  http.StatusOK,
+			expectedNames: []string{"api_request_duration_seconds_bucket", "request_duration_seconds"},
+		},
+		{
+			name:          "limit override",
+			method:        http.MethodGet,
+			query:         "?sort=series&limit=1",
+			expectedCode:  http.StatusOK,
+			expectedNames: []string{"api_request_duration_seconds_bucket"},
+		},
+		{name: "invalid sort", method: http.MethodGet, query: "?sort=bytes", expectedCode: http.StatusBadRequest},
+		{name: "invalid limit", method: http.MethodGet, query: "?limit=0", expectedCode: http.StatusBadRequest},
+		{name: "invalid method", method: http.MethodPost, expectedCode: http.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			is.handler(rec, httptest.NewRequest(tt.method, "/debug/ingest_stats"+tt.query, nil))
+			require.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			var report IngestStatsReport
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			var names []string
+			for _, m := range report.Metrics {
+				names = append(names, m.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
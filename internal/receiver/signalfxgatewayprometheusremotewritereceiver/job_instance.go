@@ -0,0 +1,183 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Labels and resource attributes of the Prometheus and OpenTelemetry compatibility specification.
+const (
+	jobLabel              = "job"
+	instanceLabel         = "instance"
+	serviceNameAttr       = "service.name"
+	serviceNamespaceAttr  = "service.namespace"
+	serviceInstanceIDAttr = "service.instance.id"
+	jobNamespaceSeparator = "/"
+)
+
+// jobInstance is the job and instance of a datapoint, empty if it has none.
+type jobInstance struct {
+	job      string
+	instance string
+}
+
+// readJobInstance returns the job and instance attributes of a datapoint, and removes them if take.
+func readJobInstance(attrs pcommon.Map, take bool) jobInstance {
+	var key jobInstance
+	if v, ok := attrs.Get(jobLabel); ok {
+		key.job = v.AsString()
+	}
+	if v, ok := attrs.Get(instanceLabel); ok {
+		key.instance = v.AsString()
+	}
+	if take {
+		attrs.Remove(jobLabel)
+		attrs.Remove(instanceLabel)
+	}
+	return key
+}
+
+// putResourceAttributes sets the service.name, service.namespace and service.instance.id resource
+// attributes of the job and instance. A job containing a slash is split into the service.namespace
+// before it and the service.name after it.
+func (j jobInstance) putResourceAttributes(attrs pcommon.Map) {
+	if j.job != "" {
+		if namespace, name, ok := strings.Cut(j.job, jobNamespaceSeparator); ok {
+			attrs.PutStr(serviceNamespaceAttr, namespace)
+			attrs.PutStr(serviceNameAttr, name)
+		} else {
+			attrs.PutStr(serviceNameAttr, j.job)
+		}
+	}
+	if j.instance != "" {
+		attrs.PutStr(serviceInstanceIDAttr, j.instance)
+	}
+}
+
+// groupByJobInstance regroups the datapoints in a resource per job and instance, with the attributes
+// of their original resource. If promote, the job and instance attributes of the datapoints are moved
+// to the service.name and service.instance.id attributes of their resource, as the OpenTelemetry
+// Prometheus compatibility specification translates them. If targets isn't nil, the resources get
+// the attributes of the target_info of their job and instance. Datapoints without job nor instance
+// keep their original resource.
+func groupByJobInstance(md pmetric.Metrics, promote bool, targets *targetInfoCache) pmetric.Metrics {
+	out := pmetric.NewMetrics()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resources := map[jobInstance]pmetric.ResourceMetrics{}
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			scopes := map[jobInstance]pmetric.ScopeMetrics{}
+			for k := 0; k < sm.Metrics().Len(); k++ {
+				m := sm.Metrics().At(k)
+				metrics := map[jobInstance]pmetric.Metric{}
+				target := func(attrs pcommon.Map) pmetric.Metric {
+					key := readJobInstance(attrs, promote)
+					if tm, ok := metrics[key]; ok {
+						return tm
+					}
+					ts, ok := scopes[key]
+					if !ok {
+						tr, ok := resources[key]
+						if !ok {
+							tr = out.ResourceMetrics().AppendEmpty()
+							tr.SetSchemaUrl(rm.SchemaUrl())
+							rm.Resource().CopyTo(tr.Resource())
+							if promote {
+								key.putResourceAttributes(tr.Resource().Attributes())
+							}
+							if targets != nil {
+								targets.putResourceAttributes(key, tr.Resource().Attributes())
+							}
+							resources[key] = tr
+						}
+						ts = tr.ScopeMetrics().AppendEmpty()
+						ts.SetSchemaUrl(sm.SchemaUrl())
+						sm.Scope().CopyTo(ts.Scope())
+						scopes[key] = ts
+					}
+					tm := ts.Metrics().AppendEmpty()
+					copyMetricWithoutDataPoints(m, tm)
+					metrics[key] = tm
+					return tm
+				}
+				moveDataPoints(m, target)
+				if len(metrics) == 0 {
+					target(pcommon.NewMap())
+				}
+			}
+		}
+	}
+	return out
+}
+
+// moveDataPoints copies the datapoints of the metric to the metric target returns for their attributes.
+func moveDataPoints(m pmetric.Metric, target func(pcommon.Map) pmetric.Metric) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := m.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dps.At(i).CopyTo(target(dps.At(i).Attributes()).Gauge().DataPoints().AppendEmpty())
+		}
+	case pmetric.MetricTypeSum:
+		dps := m.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dps.At(i).CopyTo(target(dps.At(i).Attributes()).Sum().DataPoints().AppendEmpty())
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dps.At(i).CopyTo(target(dps.At(i).Attributes()).Histogram().DataPoints().AppendEmpty())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dps.At(i).CopyTo(target(dps.At(i).Attributes()).ExponentialHistogram().DataPoints().AppendEmpty())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dps.At(i).CopyTo(target(dps.At(i).Attributes()).Summary().DataPoints().AppendEmpty())
+		}
+	case pmetric.MetricTypeEmpty:
+	}
+}
+
+// copyMetricWithoutDataPoints copies the metric to dest, with the type but none of the datapoints of the metric.
+func copyMetricWithoutDataPoints(m, dest pmetric.Metric) {
+	dest.SetName(m.Name())
+	dest.SetDescription(m.Description())
+	dest.SetUnit(m.Unit())
+	m.Metadata().CopyTo(dest.Metadata())
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		dest.SetEmptyGauge()
+	case pmetric.MetricTypeSum:
+		dest.SetEmptySum().SetAggregationTemporality(m.Sum().AggregationTemporality())
+		dest.Sum().SetIsMonotonic(m.Sum().IsMonotonic())
+	case pmetric.MetricTypeHistogram:
+		dest.SetEmptyHistogram().SetAggregationTemporality(m.Histogram().AggregationTemporality())
+	case pmetric.MetricTypeExponentialHistogram:
+		dest.SetEmptyExponentialHistogram().SetAggregationTemporality(m.ExponentialHistogram().AggregationTemporality())
+	case pmetric.MetricTypeSummary:
+		dest.SetEmptySummary()
+	case pmetric.MetricTypeEmpty:
+	}
+}
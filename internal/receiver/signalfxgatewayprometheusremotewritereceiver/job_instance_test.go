@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestPromoteJobInstance(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	md, err := parser.fromPrometheusWriteRequestMetrics(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "instance", Value: "db-1:9100"}, {Name: "job", Value: "node"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "instance", Value: "db-2:9100"}, {Name: "job", Value: "node"}},
+				Samples: []prompb.Sample{{Value: 0, Timestamp: 1000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "code", Value: "200"}, {Name: "instance", Value: "api-1:8080"}, {Name: "job", Value: "shop/api"}},
+				Samples: []prompb.Sample{{Value: 10, Timestamp: 1000}, {Value: 12, Timestamp: 2000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "queue_size"}, {Name: "queue", Value: "orders"}},
+				Samples: []prompb.Sample{{Value: 7, Timestamp: 1000}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	dataPoints := md.DataPointCount()
+	md.ResourceMetrics().At(0).Resource().Attributes().PutStr("tls.protocol.name", "tls")
+
+	promoted := groupByJobInstance(md, true, nil)
+	assert.Equal(t, dataPoints, promoted.DataPointCount())
+
+	resources := map[string]pmetric.ResourceMetrics{}
+	for i := 0; i < promoted.ResourceMetrics().Len(); i++ {
+		rm := promoted.ResourceMetrics().At(i)
+		var instance string
+		if v, ok := rm.Resource().Attributes().Get("service.instance.id"); ok {
+			instance = v.Str()
+		}
+		resources[instance] = rm
+	}
+	require.Len(t, resources, 4)
+
+	assert.Equal(t, map[string]any{"service.name": "node", "service.instance.id": "db-1:9100", "tls.protocol.name": "tls"},
+		resources["db-1:9100"].Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"service.name": "node", "service.instance.id": "db-2:9100", "tls.protocol.name": "tls"},
+		resources["db-2:9100"].Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"service.namespace": "shop", "service.name": "api", "service.instance.id": "api-1:8080", "tls.protocol.name": "tls"},
+		resources["api-1:8080"].Resource().Attributes().AsRaw())
+	// the datapoints without job nor instance, like the internal metrics, keep their resource
+	assert.Equal(t, map[string]any{"tls.protocol.name": "tls"}, resources[""].Resource().Attributes().AsRaw())
+
+	up := resources["db-2:9100"].ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, up.Len())
+	assert.Equal(t, "up", up.At(0).Name())
+	require.Equal(t, 1, up.At(0).Gauge().DataPoints().Len())
+	assert.Empty(t, up.At(0).Gauge().DataPoints().At(0).Attributes().AsRaw())
+
+	requests := resources["api-1:8080"].ScopeMetrics().At(0)
+	assert.Equal(t, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Scope().Name(), requests.Scope().Name())
+	require.Equal(t, 1, requests.Metrics().Len())
+	sum := requests.Metrics().At(0).Sum()
+	assert.True(t, sum.IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, sum.AggregationTemporality())
+	require.Equal(t, 2, sum.DataPoints().Len())
+	assert.Equal(t, map[string]any{"code": "200"}, sum.DataPoints().At(1).Attributes().AsRaw())
+
+	others := resources[""].ScopeMetrics().At(0).Metrics()
+	var queueSize pmetric.Metric
+	for i := 0; i < others.Len(); i++ {
+		if others.At(i).Name() == "queue_size" {
+			queueSize = others.At(i)
+		}
+	}
+	assert.Equal(t, map[string]any{"queue": "orders"}, queueSize.Gauge().DataPoints().At(0).Attributes().AsRaw())
+}
+
+func TestPromoteJobInstanceKeepsMetricsWithoutDataPoints(t *testing.T) {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("empty")
+	m.SetEmptySummary()
+
+	promoted := groupByJobInstance(md, true, nil)
+	require.Equal(t, 1, promoted.ResourceMetrics().Len())
+	metrics := promoted.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metrics.Len())
+	assert.Equal(t, "empty", metrics.At(0).Name())
+	assert.Equal(t, pmetric.MetricTypeSummary, metrics.At(0).Type())
+}
@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// jsonWriteRequest is the JSON representation of a write request, e.g.
+// {"timeseries": [{"name": "up", "labels": {"job": "test"}, "samples": [{"value": 1}]}]}
+type jsonWriteRequest struct {
+	Timeseries []jsonTimeseries `json:"timeseries"`
+}
+
+type jsonTimeseries struct {
+	Labels  map[string]string `json:"labels"`
+	Name    string            `json:"name"`
+	Samples []jsonSample      `json:"samples"`
+}
+
+type jsonSample struct {
+	// Timestamp is in milliseconds since the epoch, and defaults to the time the request is received.
+	Timestamp *int64  `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// decodeJSONWriteRequest decodes a JSON write request into a prompb.WriteRequest, with the labels of
+// each series sorted by name like remote write senders do.
+func decodeJSONWriteRequest(r io.Reader) (*prompb.WriteRequest, error) {
+	var jsonReq jsonWriteRequest
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&jsonReq); err != nil {
+		return nil, fmt.Errorf("invalid JSON write request: %w", err)
+	}
+	now := time.Now().UnixMilli()
+	req := &prompb.WriteRequest{Timeseries: make([]prompb.TimeSeries, 0, len(jsonReq.Timeseries))}
+	for i, ts := range jsonReq.Timeseries {
+		if ts.Name == "" {
+			return nil, fmt.Errorf("timeseries[%d]: name must not be empty", i)
+		}
+		if _, ok := ts.Labels["__name__"]; ok {
+			return nil, fmt.Errorf("timeseries[%d]: labels must not contain __name__, use name instead", i)
+		}
+		series := prompb.TimeSeries{
+			Labels:  make([]prompb.Label, 0, len(ts.Labels)+1),
+			Samples: make([]prompb.Sample, 0, len(ts.Samples)),
+		}
+		series.Labels = append(series.Labels, prompb.Label{Name: "__name__", Value: ts.Name})
+		for name, value := range ts.Labels {
+			series.Labels = append(series.Labels, prompb.Label{Name: name, Value: value})
+		}
+		sort.Slice(series.Labels, func(a, b int) bool { return series.Labels[a].Name < series.Labels[b].Name })
+		for _, sample := range ts.Samples {
+			timestamp := now
+			if sample.Timestamp != nil {
+				timestamp = *sample.Timestamp
+			}
+			series.Samples = append(series.Samples, prompb.Sample{Value: sample.Value, Timestamp: timestamp})
+		}
+		req.Timeseries = append(req.Timeseries, series)
+	}
+	return req, nil
+}
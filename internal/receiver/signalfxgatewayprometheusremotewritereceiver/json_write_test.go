@@ -0,0 +1,104 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestDecodeJSONWriteRequest(t *testing.T) {
+	before := time.Now().UnixMilli()
+	req, err := decodeJSONWriteRequest(strings.NewReader(`{"timeseries": [
+		{"name": "http_requests_total", "labels": {"path": "/", "code": "200"}, "samples": [{"value": 3, "timestamp": 1700000000000}]},
+		{"name": "up", "samples": [{"value": 1}]}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, req.Timeseries, 2)
+	assert.Equal(t, prompb.TimeSeries{
+		Labels: []prompb.Label{
+			{Name: "__name__", Value: "http_requests_total"},
+			{Name: "code", Value: "200"},
+			{Name: "path", Value: "/"},
+		},
+		Samples: []prompb.Sample{{Value: 3, Timestamp: 1700000000000}},
+	}, req.Timeseries[0])
+	assert.Equal(t, []prompb.Label{{Name: "__name__", Value: "up"}}, req.Timeseries[1].Labels)
+	require.Len(t, req.Timeseries[1].Samples, 1)
+	// samples without timestamp are timestamped when received
+	assert.GreaterOrEqual(t, req.Timeseries[1].Samples[0].Timestamp, before)
+
+	for _, tt := range []struct {
+		name        string
+		body        string
+		expectedErr string
+	}{
+		{name: "invalid json", body: `{"timeseries": [`, expectedErr: "invalid JSON write request: unexpected EOF"},
+		{name: "unknown field", body: `{"series": []}`, expectedErr: `invalid JSON write request: json: unknown field "series"`},
+		{name: "no name", body: `{"timeseries": [{"samples": [{"value": 1}]}]}`, expectedErr: "timeseries[0]: name must not be empty"},
+		{
+			name:        "name label",
+			body:        `{"timeseries": [{"name": "up", "labels": {"__name__": "down"}}]}`,
+			expectedErr: "timeseries[0]: labels must not contain __name__, use name instead",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeJSONWriteRequest(strings.NewReader(tt.body))
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestJSONWriteHandler(t *testing.T) {
+	var consumed []pmetric.Metrics
+	handler := newDecodingHandler(decodeJSONWriteRequest, newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), &serverConfig{
+		Reporter: newMockReporter(),
+		Consume: func(_ context.Context, md pmetric.Metrics) error {
+			consumed = append(consumed, md)
+			return nil
+		},
+	}, nil)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/write/json",
+		strings.NewReader(`{"timeseries": [{"name": "queue_size", "labels": {"queue": "a"}, "samples": [{"value": 7, "timestamp": 1700000000000}]}]}`)))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Len(t, consumed, 1)
+	ms := consumed[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	m := pmetric.NewMetric()
+	for i := 0; i < ms.Len(); i++ {
+		if ms.At(i).Name() == "queue_size" {
+			m = ms.At(i)
+		}
+	}
+	require.Equal(t, pmetric.MetricTypeGauge, m.Type())
+	dp := m.Gauge().DataPoints().At(0)
+	assert.Equal(t, int64(7), dp.IntValue())
+	queue, _ := dp.Attributes().Get("queue")
+	assert.Equal(t, "a", queue.Str())
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/write/json", strings.NewReader(`{"timeseries": [{"samples": []}]}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// metricFilter drops the series whose metric name no include_metrics regex matches, or an
+// exclude_metrics regex matches, before they're translated.
+type metricFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// newMetricFilter returns the filter of the regexes, nil if there are none.
+func newMetricFilter(include, exclude []string) (*metricFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	filter := &metricFilter{}
+	var err error
+	if filter.include, err = compileMetricRegexes("include_metrics", include); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = compileMetricRegexes("exclude_metrics", exclude); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func compileMetricRegexes(key string, regexes []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(regexes))
+	for i, regex := range regexes {
+		r, err := compileTypeRuleRegex(regex)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d] regex is invalid: %w", key, i, err)
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+// keeps returns whether the series of the metric name are kept. Series without metric name are
+// kept, to be counted as such.
+func (f *metricFilter) keeps(metricName string) bool {
+	if metricName == "" {
+		return true
+	}
+	if len(f.include) > 0 && !matchesAny(f.include, metricName) {
+		return false
+	}
+	return !matchesAny(f.exclude, metricName)
+}
+
+func matchesAny(regexes []*regexp.Regexp, metricName string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(metricName) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterWriteRequest returns the write request without the series the filter drops, and the created
+// timestamps of the kept series. The write request itself isn't modified.
+func (f *metricFilter) filterWriteRequest(req *prompb.WriteRequest, createdTimestamps []int64) (*prompb.WriteRequest, []int64) {
+	filtered := &prompb.WriteRequest{Metadata: req.Metadata, Timeseries: make([]prompb.TimeSeries, 0, len(req.Timeseries))}
+	var filteredCreatedTimestamps []int64
+	if createdTimestamps != nil {
+		filteredCreatedTimestamps = make([]int64, 0, len(createdTimestamps))
+	}
+	for i, ts := range req.Timeseries {
+		if !f.keeps(metricNameLabel(ts.Labels)) {
+			continue
+		}
+		filtered.Timeseries = append(filtered.Timeseries, ts)
+		if createdTimestamps != nil {
+			filteredCreatedTimestamps = append(filteredCreatedTimestamps, createdTimestamps[i])
+		}
+	}
+	return filtered, filteredCreatedTimestamps
+}
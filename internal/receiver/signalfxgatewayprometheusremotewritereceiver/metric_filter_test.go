@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricFilter(t *testing.T) {
+	filter, err := newMetricFilter(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, filter)
+
+	filter, err = newMetricFilter([]string{"http_.*", "process_cpu_seconds_total"}, []string{"http_debug_.*"})
+	require.NoError(t, err)
+	assert.True(t, filter.keeps("http_requests_total"))
+	assert.True(t, filter.keeps("process_cpu_seconds_total"))
+	assert.True(t, filter.keeps(""))
+	assert.False(t, filter.keeps("http_debug_handlers"))
+	assert.False(t, filter.keeps("process_open_fds"))
+	assert.False(t, filter.keeps("go_goroutines"))
+
+	filter, err = newMetricFilter(nil, []string{"go_.*", "process_.*"})
+	require.NoError(t, err)
+	assert.True(t, filter.keeps("http_requests_total"))
+	assert.False(t, filter.keeps("go_goroutines"))
+	assert.True(t, filter.keeps("legacy_go_goroutines"), "regexes match whole metric names")
+
+	_, err = newMetricFilter(nil, []string{"go_.*", "process_("})
+	assert.ErrorContains(t, err, "exclude_metrics[1] regex is invalid")
+}
+
+func TestMetricFilterWriteRequest(t *testing.T) {
+	filter, err := newMetricFilter(nil, []string{"go_.*"})
+	require.NoError(t, err)
+	req := &prompb.WriteRequest{
+		Metadata: []prompb.MetricMetadata{{MetricFamilyName: "http_requests_total", Type: prompb.MetricMetadata_COUNTER}},
+		Timeseries: []prompb.TimeSeries{
+			{Labels: []prompb.Label{{Name: "__name__", Value: "go_goroutines"}}, Samples: []prompb.Sample{{Value: 12, Timestamp: 1000}}},
+			{Labels: []prompb.Label{{Name: "__name__", Value: "http_requests_total"}}, Samples: []prompb.Sample{{Value: 7, Timestamp: 1000}}},
+			{Labels: []prompb.Label{{Name: "job", Value: "api"}}, Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}}},
+		},
+	}
+
+	filtered, createdTimestamps := filter.filterWriteRequest(req, []int64{500, 600, 0})
+	assert.Equal(t, []int64{600, 0}, createdTimestamps)
+	assert.Equal(t, req.Metadata, filtered.Metadata)
+	assert.Equal(t, req.Timeseries[1:], filtered.Timeseries)
+	assert.Len(t, req.Timeseries, 3, "the write request must not be modified")
+
+	_, createdTimestamps = filter.filterWriteRequest(req, nil)
+	assert.Nil(t, createdTimestamps)
+}
@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/signalfx/splunk-otel-collector/internal/extension/seriescacheextension"
+)
+
+// familySuffixes are the suffixes of the series of metric families, by family type. The series
+// of a family are only matched by the suffixes of its type, so that e.g. the metadata of a
+// `requests` gauge isn't applied to an unrelated `requests_count` counter.
+var familySuffixes = map[prompb.MetricMetadata_MetricType][]string{
+	prompb.MetricMetadata_COUNTER:        {"_total", "_created"},
+	prompb.MetricMetadata_HISTOGRAM:      {"_bucket", "_sum", "_count", "_created"},
+	prompb.MetricMetadata_SUMMARY:        {"_sum", "_count", "_created"},
+	prompb.MetricMetadata_GAUGEHISTOGRAM: {"_bucket", "_gsum", "_gcount"},
+	prompb.MetricMetadata_INFO:           {"_info"},
+}
+
+// metricMetadataCache keeps the metadata of the metric families of write requests, by family
+// name. Senders like Prometheus send the metadata of their metric families periodically in
+// write requests of their own, so the metadata is applied to the series of subsequent requests.
+// The metadata is kept in the namespace of a series_cache extension if configured, bounding the
+// number of families kept and persisting them across restarts.
+type metricMetadataCache struct {
+	families map[string]prompb.MetricMetadata
+	store    seriescacheextension.Cache
+	mu       sync.RWMutex
+}
+
+func newMetricMetadataCache() *metricMetadataCache {
+	return &metricMetadataCache{families: map[string]prompb.MetricMetadata{}}
+}
+
+// update records the metadata of the families, replacing their previous metadata.
+func (c *metricMetadataCache) update(families []prompb.MetricMetadata) {
+	if len(families) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, family := range families {
+		if family.MetricFamilyName == "" {
+			continue
+		}
+		if c.store == nil {
+			c.families[family.MetricFamilyName] = family
+		} else if data, err := family.Marshal(); err == nil {
+			c.store.Set(family.MetricFamilyName, data)
+		}
+	}
+}
+
+// family returns the metadata of the family with the name. It must be called with the lock held.
+func (c *metricMetadataCache) family(name string) (prompb.MetricMetadata, bool) {
+	if c.store == nil {
+		family, ok := c.families[name]
+		return family, ok
+	}
+	data, ok := c.store.Get(name)
+	if !ok {
+		return prompb.MetricMetadata{}, false
+	}
+	var family prompb.MetricMetadata
+	if err := family.Unmarshal(data); err != nil {
+		return prompb.MetricMetadata{}, false
+	}
+	return family, true
+}
+
+// familyType returns the type of the family with the name, unknown if its metadata wasn't sent.
+func (c *metricMetadataCache) familyType(name string) prompb.MetricMetadata_MetricType {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	family, _ := c.family(name)
+	return family.Type
+}
+
+// lookup returns the metadata of the family of the series with the metric name, with the type of
+// the series rather than the family, e.g. a counter for the `_count` series of a histogram.
+func (c *metricMetadataCache) lookup(metricName string) (prompb.MetricMetadata, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if family, ok := c.family(metricName); ok {
+		return withSeriesType(family, ""), true
+	}
+	for familyType, suffixes := range familySuffixes {
+		for _, suffix := range suffixes {
+			if !strings.HasSuffix(metricName, suffix) {
+				continue
+			}
+			family, ok := c.family(strings.TrimSuffix(metricName, suffix))
+			if ok && family.Type == familyType {
+				return withSeriesType(family, suffix), true
+			}
+		}
+	}
+	return prompb.MetricMetadata{}, false
+}
+
+// withSeriesType returns the metadata with the type of the family's series with the suffix. The
+// buckets of histograms keep their family type, while their sums and counts are counters, or
+// gauges for gauge histograms.
+func withSeriesType(family prompb.MetricMetadata, suffix string) prompb.MetricMetadata {
+	switch family.Type {
+	case prompb.MetricMetadata_HISTOGRAM, prompb.MetricMetadata_SUMMARY:
+		if suffix != "_bucket" && suffix != "" {
+			family.Type = prompb.MetricMetadata_COUNTER
+		}
+	case prompb.MetricMetadata_GAUGEHISTOGRAM:
+		if suffix != "_bucket" && suffix != "" {
+			family.Type = prompb.MetricMetadata_GAUGE
+		}
+	}
+	return family
+}
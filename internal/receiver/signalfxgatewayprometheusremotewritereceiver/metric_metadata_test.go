@@ -0,0 +1,197 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestMetricMetadataCacheLookup(t *testing.T) {
+	cache := newMetricMetadataCache()
+	cache.update([]prompb.MetricMetadata{
+		{MetricFamilyName: "http_requests", Type: prompb.MetricMetadata_COUNTER, Help: "Requests served.", Unit: "requests"},
+		{MetricFamilyName: "request_duration_seconds", Type: prompb.MetricMetadata_HISTOGRAM},
+		{MetricFamilyName: "rpc_duration_seconds", Type: prompb.MetricMetadata_SUMMARY},
+		{MetricFamilyName: "queue_size", Type: prompb.MetricMetadata_GAUGEHISTOGRAM},
+		{MetricFamilyName: "temperature", Type: prompb.MetricMetadata_GAUGE},
+		{MetricFamilyName: "mystery", Type: prompb.MetricMetadata_UNKNOWN},
+		{Type: prompb.MetricMetadata_GAUGE},
+	})
+
+	for _, tt := range []struct {
+		name         string
+		expectedType prompb.MetricMetadata_MetricType
+		found        bool
+	}{
+		{name: "http_requests", expectedType: prompb.MetricMetadata_COUNTER, found: true},
+		{name: "http_requests_total", expectedType: prompb.MetricMetadata_COUNTER, found: true},
+		{name: "request_duration_seconds_bucket", expectedType: prompb.MetricMetadata_HISTOGRAM, found: true},
+		{name: "request_duration_seconds_sum", expectedType: prompb.MetricMetadata_COUNTER, found: true},
+		{name: "request_duration_seconds_count", expectedType: prompb.MetricMetadata_COUNTER, found: true},
+		{name: "rpc_duration_seconds", expectedType: prompb.MetricMetadata_SUMMARY, found: true},
+		{name: "rpc_duration_seconds_count", expectedType: prompb.MetricMetadata_COUNTER, found: true},
+		{name: "queue_size_bucket", expectedType: prompb.MetricMetadata_GAUGEHISTOGRAM, found: true},
+		{name: "queue_size_gsum", expectedType: prompb.MetricMetadata_GAUGE, found: true},
+		{name: "temperature", expectedType: prompb.MetricMetadata_GAUGE, found: true},
+		{name: "mystery", expectedType: prompb.MetricMetadata_UNKNOWN, found: true},
+		// the suffixes of other family types don't match
+		{name: "temperature_count"},
+		{name: "http_requests_bucket"},
+		{name: "unknown_total"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			metricMetadata, ok := cache.lookup(tt.name)
+			require.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.expectedType, metricMetadata.Type)
+		})
+	}
+
+	metricMetadata, _ := cache.lookup("http_requests_total")
+	assert.Equal(t, "Requests served.", metricMetadata.Help)
+	assert.Equal(t, "requests", metricMetadata.Unit)
+}
+
+// mapCache is a series_cache namespace kept in a map.
+type mapCache map[string][]byte
+
+func (c mapCache) Get(key string) ([]byte, bool) {
+	value, ok := c[key]
+	return value, ok
+}
+
+func (c mapCache) Set(key string, value []byte) {
+	c[key] = value
+}
+
+func (c mapCache) Delete(key string) {
+	delete(c, key)
+}
+
+func TestMetricMetadataCacheStore(t *testing.T) {
+	store := mapCache{}
+	cache := newMetricMetadataCache()
+	cache.store = store
+	cache.update([]prompb.MetricMetadata{
+		{MetricFamilyName: "http_requests", Type: prompb.MetricMetadata_COUNTER, Help: "Requests served.", Unit: "requests"},
+		{MetricFamilyName: "temperature", Type: prompb.MetricMetadata_GAUGE},
+	})
+	assert.Empty(t, cache.families)
+	assert.Len(t, store, 2)
+
+	// the metadata is looked up in the store, e.g. after a restart
+	cache = newMetricMetadataCache()
+	cache.store = store
+	metricMetadata, ok := cache.lookup("http_requests_total")
+	require.True(t, ok)
+	assert.Equal(t, prompb.MetricMetadata{
+		MetricFamilyName: "http_requests",
+		Type:             prompb.MetricMetadata_COUNTER,
+		Help:             "Requests served.",
+		Unit:             "requests",
+	}, metricMetadata)
+	_, ok = cache.lookup("temperature_total")
+	assert.False(t, ok)
+
+	// metadata that can't be decoded is ignored
+	store["pressure"] = []byte{0xff}
+	_, ok = cache.lookup("pressure")
+	assert.False(t, ok)
+}
+
+func findMetric(t *testing.T, md pmetric.Metrics, name string) pmetric.Metric {
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		if metrics.At(i).Name() == name {
+			return metrics.At(i)
+		}
+	}
+	require.Failf(t, "metric not found", "no metric %q", name)
+	return pmetric.NewMetric()
+}
+
+func TestMetricMetadataApplied(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+
+	// the metadata is sent on its own, like Prometheus does
+	_, err := parser.fromPrometheusWriteRequestMetrics(&prompb.WriteRequest{
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "jobs_done", Type: prompb.MetricMetadata_COUNTER, Help: "Jobs done.", Unit: "jobs"},
+			{MetricFamilyName: "queue_depth_total", Type: prompb.MetricMetadata_GAUGE, Help: "Queued jobs."},
+		},
+	})
+	require.NoError(t, err)
+
+	md, err := parser.fromPrometheusWriteRequestMetrics(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "jobs_done"}},
+				Samples: []prompb.Sample{{Value: 5, Timestamp: jan20.UnixMilli()}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "queue_depth_total"}},
+				Samples: []prompb.Sample{{Value: 3, Timestamp: jan20.UnixMilli()}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "retries_total"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: jan20.UnixMilli()}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// typed as a counter by its metadata, despite not having a counter suffix
+	jobs := findMetric(t, md, "jobs_done")
+	require.Equal(t, pmetric.MetricTypeSum, jobs.Type())
+	assert.Equal(t, "Jobs done.", jobs.Description())
+	assert.Equal(t, "jobs", jobs.Unit())
+
+	// typed as a gauge by its metadata, despite its counter suffix
+	queue := findMetric(t, md, "queue_depth_total")
+	require.Equal(t, pmetric.MetricTypeGauge, queue.Type())
+	assert.Equal(t, "Queued jobs.", queue.Description())
+
+	// typed by its suffix without metadata
+	retries := findMetric(t, md, "retries_total")
+	require.Equal(t, pmetric.MetricTypeSum, retries.Type())
+	assert.Empty(t, retries.Description())
+}
+
+func TestColumnarBatchMetricMetadata(t *testing.T) {
+	cb, mc := newTestColumnarBatch(100)
+
+	require.NoError(t, cb.add(&prompb.WriteRequest{
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "jobs_done", Type: prompb.MetricMetadata_COUNTER, Help: "Jobs done.", Unit: "jobs"},
+		},
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "jobs_done"}},
+				Samples: []prompb.Sample{{Value: 5, Timestamp: 1000}},
+			},
+		},
+	}))
+	cb.close(context.Background())
+
+	jobs := findMetric(t, <-mc, "jobs_done")
+	require.Equal(t, pmetric.MetricTypeSum, jobs.Type())
+	assert.Equal(t, "Jobs done.", jobs.Description())
+	assert.Equal(t, "jobs", jobs.Unit())
+}
@@ -0,0 +1,210 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	// minSchema and maxSchema are the schemas of exponential native histograms, which are the
+	// scales of OTLP exponential histograms.
+	minSchema = -4
+	maxSchema = 8
+	// maxBucketRange is the maximum number of buckets between the lowest and highest populated
+	// buckets of a histogram, since OTLP buckets aren't sparse.
+	maxBucketRange = 1 << 16
+)
+
+// isGaugeHistogram returns whether the native histograms of the series are gauge histograms,
+// by their family type or reset hint.
+func isGaugeHistogram(metricsData metricData) bool {
+	return metricsData.MetricMetadata.Type == prompb.MetricMetadata_GAUGEHISTOGRAM ||
+		(len(metricsData.Histograms) > 0 && metricsData.Histograms[0].ResetHint == prompb.Histogram_GAUGE)
+}
+
+// addNativeHistogramMetrics handles the native histograms of the series, as exponential histograms.
+func (prwParser *prometheusRemoteOtelParser) addNativeHistogramMetrics(ilm pmetric.ScopeMetrics, metrics []metricData) {
+	for _, metricsData := range metrics {
+		if metricsData.MetricName == "" || len(metricsData.Histograms) == 0 {
+			continue
+		}
+		nm := prwParser.scaffoldNewMetric(ilm, metricsData)
+		histogram := nm.SetEmptyExponentialHistogram()
+		gauge := isGaugeHistogram(metricsData)
+		if gauge {
+			histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		} else {
+			histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		}
+		for _, h := range metricsData.Histograms {
+			if dp, ok := prwParser.appendNativeHistogram(histogram.DataPoints(), h, metricsData.MetricName, metricsData.Labels,
+				metricsData.CreatedTimestamp, gauge); ok {
+				prwParser.putAttributes(dp.Attributes(), metricsData.Labels)
+			}
+		}
+		if len(metricsData.Samples) == 0 {
+			// the exemplars of series with samples are added to their samples
+			addExemplars[pmetric.ExponentialHistogramDataPoint](histogram.DataPoints(), metricsData.Exemplars)
+		}
+	}
+}
+
+// appendNativeHistogram appends the datapoint of the native histogram, or counts it as a NaN
+// sample or bad datapoint and returns false if it can't be translated. The start timestamp of
+// cumulative histograms is the one of their series, or their timestamp if the sender hinted the
+// counts were reset, and the timestamp of the histogram for gauge histograms. Staleness markers,
+// whose sum is the stale NaN, are appended without counts if kept.
+func (prwParser *prometheusRemoteOtelParser) appendNativeHistogram(
+	dps pmetric.ExponentialHistogramDataPointSlice,
+	h prompb.Histogram,
+	name string,
+	labels []prompb.Label,
+	createdTimestamp int64,
+	gauge bool,
+) (pmetric.ExponentialHistogramDataPoint, bool) {
+	dp := pmetric.NewExponentialHistogramDataPoint()
+	count := math.NaN()
+	switch {
+	case prwParser.keepStaleMarker(h.Sum):
+		dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+	case math.IsNaN(h.Sum):
+		prwParser.totalNans.Add(1)
+		return pmetric.ExponentialHistogramDataPoint{}, false
+	default:
+		if err := setExponentialHistogram(dp, h); err != nil {
+			prwParser.totalBadMetrics.Add(1)
+			return pmetric.ExponentialHistogramDataPoint{}, false
+		}
+		count = float64(dp.Count())
+	}
+	start := h.Timestamp
+	switch {
+	case gauge:
+	case h.ResetHint == prompb.Histogram_YES:
+		if prwParser.startTimestamps != nil {
+			// records the reset
+			start = prwParser.startTimestamps.start(seriesKey(name, labels), h.Timestamp, count, true)
+		}
+	default:
+		start = prwParser.cumulativeStart(name, labels, createdTimestamp, h.Timestamp, count)
+	}
+	dp.SetStartTimestamp(prometheusToOtelTimestamp(start))
+	dp.SetTimestamp(prometheusToOtelTimestamp(h.Timestamp))
+	dp.MoveTo(dps.AppendEmpty())
+	return dps.At(dps.Len() - 1), true
+}
+
+// setExponentialHistogram sets the counts, sum, zero bucket and buckets of the datapoint to the
+// ones of the native histogram, whose schema is the scale of the datapoint.
+func setExponentialHistogram(dp pmetric.ExponentialHistogramDataPoint, h prompb.Histogram) error {
+	if h.Schema < minSchema || h.Schema > maxSchema {
+		return fmt.Errorf("unsupported native histogram schema %d", h.Schema)
+	}
+	dp.SetScale(h.Schema)
+	count := h.GetCountInt()
+	if c, ok := h.Count.(*prompb.Histogram_CountFloat); ok {
+		var err error
+		if count, err = roundCount(c.CountFloat); err != nil {
+			return err
+		}
+	}
+	dp.SetCount(count)
+	dp.SetSum(h.Sum)
+	zeroCount := h.GetZeroCountInt()
+	if c, ok := h.ZeroCount.(*prompb.Histogram_ZeroCountFloat); ok {
+		var err error
+		if zeroCount, err = roundCount(c.ZeroCountFloat); err != nil {
+			return err
+		}
+	}
+	dp.SetZeroCount(zeroCount)
+	dp.SetZeroThreshold(h.ZeroThreshold)
+	if err := setBuckets(dp.Positive(), h.PositiveSpans, h.PositiveDeltas, h.PositiveCounts); err != nil {
+		return err
+	}
+	return setBuckets(dp.Negative(), h.NegativeSpans, h.NegativeDeltas, h.NegativeCounts)
+}
+
+// roundCount returns the count of float histograms rounded to the nearest integer.
+func roundCount(count float64) (uint64, error) {
+	if math.IsNaN(count) || count < 0 {
+		return 0, fmt.Errorf("invalid native histogram count %v", count)
+	}
+	return uint64(math.Round(count)), nil
+}
+
+// setBuckets sets the dense buckets of the sparse native histogram buckets of the spans, with
+// either delta encoded integer counts or float counts. The bucket with index i of a native
+// histogram is the bucket with index i-1 of an exponential histogram of the same scale: the
+// upper bound of native histogram buckets is inclusive, and the lower bound of OTLP's.
+func setBuckets(buckets pmetric.ExponentialHistogramDataPointBuckets, spans []prompb.BucketSpan, deltas []int64, counts []float64) error {
+	var n int
+	var first, end int64
+	for i, span := range spans {
+		if i == 0 {
+			first, end = int64(span.Offset), int64(span.Offset)
+		} else {
+			end += int64(span.Offset)
+		}
+		end += int64(span.Length)
+		n += int(span.Length)
+	}
+	if n == 0 {
+		return nil
+	}
+	if (deltas == nil) == (counts == nil) || len(deltas)+len(counts) != n {
+		return errors.New("native histogram bucket counts don't match its spans")
+	}
+	if end-first > maxBucketRange {
+		return fmt.Errorf("native histogram buckets span more than %d buckets", maxBucketRange)
+	}
+
+	bucketCounts := make([]uint64, end-first)
+	index := first
+	var k int
+	var current int64
+	for i, span := range spans {
+		if i > 0 {
+			index += int64(span.Offset)
+		}
+		for j := uint32(0); j < span.Length; j++ {
+			var count uint64
+			if deltas != nil {
+				current += deltas[k]
+				if current < 0 {
+					return errors.New("negative native histogram bucket count")
+				}
+				count = uint64(current)
+			} else {
+				var err error
+				if count, err = roundCount(counts[k]); err != nil {
+					return err
+				}
+			}
+			bucketCounts[index-first] = count
+			index++
+			k++
+		}
+	}
+	buckets.SetOffset(int32(first - 1)) //nolint:gosec
+	buckets.BucketCounts().FromRaw(bucketCounts)
+	return nil
+}
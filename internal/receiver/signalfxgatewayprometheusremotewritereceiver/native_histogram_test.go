@@ -0,0 +1,225 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestSetExponentialHistogram(t *testing.T) {
+	for _, tt := range []struct {
+		name              string
+		histogram         prompb.Histogram
+		expectedPositive  []uint64
+		expectedNegative  []uint64
+		expectedErr       string
+		expectedCount     uint64
+		expectedZeroCount uint64
+		expectedPosOffset int32
+		expectedNegOffset int32
+	}{
+		{
+			name: "integer histogram",
+			histogram: prompb.Histogram{
+				Count:          &prompb.Histogram_CountInt{CountInt: 9},
+				ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 2},
+				PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 2}, {Offset: 1, Length: 1}},
+				PositiveDeltas: []int64{1, 1, -1},
+				NegativeSpans:  []prompb.BucketSpan{{Offset: -2, Length: 1}},
+				NegativeDeltas: []int64{3},
+			},
+			expectedCount:     9,
+			expectedZeroCount: 2,
+			expectedPositive:  []uint64{1, 2, 0, 1},
+			expectedPosOffset: -1,
+			expectedNegative:  []uint64{3},
+			expectedNegOffset: -3,
+		},
+		{
+			name: "float histogram",
+			histogram: prompb.Histogram{
+				Count:          &prompb.Histogram_CountFloat{CountFloat: 4.6},
+				ZeroCount:      &prompb.Histogram_ZeroCountFloat{ZeroCountFloat: 0.4},
+				PositiveSpans:  []prompb.BucketSpan{{Offset: 3, Length: 2}},
+				PositiveCounts: []float64{1.5, 2.5},
+			},
+			expectedCount:     5,
+			expectedPositive:  []uint64{2, 3},
+			expectedPosOffset: 2,
+		},
+		{
+			name:        "custom buckets",
+			histogram:   prompb.Histogram{Schema: -53},
+			expectedErr: "unsupported native histogram schema -53",
+		},
+		{
+			name: "missing bucket counts",
+			histogram: prompb.Histogram{
+				PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 2}},
+				PositiveDeltas: []int64{1},
+			},
+			expectedErr: "native histogram bucket counts don't match its spans",
+		},
+		{
+			name: "negative bucket count",
+			histogram: prompb.Histogram{
+				PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 2}},
+				PositiveDeltas: []int64{1, -2},
+			},
+			expectedErr: "negative native histogram bucket count",
+		},
+		{
+			name: "NaN float count",
+			histogram: prompb.Histogram{
+				Count: &prompb.Histogram_CountFloat{CountFloat: math.NaN()},
+			},
+			expectedErr: "invalid native histogram count NaN",
+		},
+		{
+			name: "sparse buckets too far apart",
+			histogram: prompb.Histogram{
+				PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 1}, {Offset: 1 << 20, Length: 1}},
+				PositiveDeltas: []int64{1, 0},
+			},
+			expectedErr: "native histogram buckets span more than 65536 buckets",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.histogram.Sum = 12.5
+			tt.histogram.ZeroThreshold = 0.001
+			dp := pmetric.NewExponentialHistogramDataPoint()
+			err := setExponentialHistogram(dp, tt.histogram)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.histogram.Schema, dp.Scale())
+			assert.Equal(t, tt.expectedCount, dp.Count())
+			assert.Equal(t, 12.5, dp.Sum())
+			assert.Equal(t, tt.expectedZeroCount, dp.ZeroCount())
+			assert.Equal(t, 0.001, dp.ZeroThreshold())
+			assert.Equal(t, tt.expectedPosOffset, dp.Positive().Offset())
+			assert.Equal(t, tt.expectedPositive, nilIfEmpty(dp.Positive().BucketCounts().AsRaw()))
+			assert.Equal(t, tt.expectedNegOffset, dp.Negative().Offset())
+			assert.Equal(t, tt.expectedNegative, nilIfEmpty(dp.Negative().BucketCounts().AsRaw()))
+		})
+	}
+}
+
+func nilIfEmpty(counts []uint64) []uint64 {
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
+
+func nativeHistogramWriteRequest() *prompb.WriteRequest {
+	histogram := func(timestamp int64, resetHint prompb.Histogram_ResetHint) prompb.Histogram {
+		return prompb.Histogram{
+			Count:          &prompb.Histogram_CountInt{CountInt: 3},
+			Sum:            4.5,
+			Schema:         3,
+			PositiveSpans:  []prompb.BucketSpan{{Offset: 1, Length: 2}},
+			PositiveDeltas: []int64{1, 1},
+			ResetHint:      resetHint,
+			Timestamp:      timestamp,
+		}
+	}
+	return &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{{Name: "__name__", Value: "request_duration_seconds"}, {Name: "path", Value: "/"}},
+				Histograms: []prompb.Histogram{
+					histogram(jan20.UnixMilli(), prompb.Histogram_UNKNOWN),
+					histogram(jan20.UnixMilli()+1000, prompb.Histogram_YES),
+					{Sum: math.NaN(), Timestamp: jan20.UnixMilli() + 2000},
+					{Schema: -53, Timestamp: jan20.UnixMilli() + 3000},
+				},
+			},
+			{
+				Labels:     []prompb.Label{{Name: "__name__", Value: "queue_wait_seconds"}},
+				Histograms: []prompb.Histogram{histogram(jan20.UnixMilli(), prompb.Histogram_GAUGE)},
+			},
+		},
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "request_duration_seconds", Type: prompb.MetricMetadata_HISTOGRAM, Help: "Request duration."},
+		},
+	}
+}
+
+func assertNativeHistogramMetrics(t *testing.T, md pmetric.Metrics, createdTimestamp int64) {
+	metric := findMetric(t, md, "request_duration_seconds")
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, metric.Type())
+	assert.Equal(t, "Request duration.", metric.Description())
+	histogram := metric.ExponentialHistogram()
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, histogram.AggregationTemporality())
+	require.Equal(t, 2, histogram.DataPoints().Len())
+	dp := histogram.DataPoints().At(0)
+	assert.Equal(t, int32(3), dp.Scale())
+	assert.Equal(t, uint64(3), dp.Count())
+	assert.Equal(t, 4.5, dp.Sum())
+	assert.Equal(t, int32(0), dp.Positive().Offset())
+	assert.Equal(t, []uint64{1, 2}, dp.Positive().BucketCounts().AsRaw())
+	assert.Equal(t, pcommon.NewTimestampFromTime(jan20), dp.Timestamp())
+	assert.Equal(t, prometheusToOtelTimestamp(startTimestamp(createdTimestamp, jan20.UnixMilli())), dp.StartTimestamp())
+	path, _ := dp.Attributes().Get("path")
+	assert.Equal(t, "/", path.Str())
+	// the start of histograms hinted as reset is their timestamp
+	dp = histogram.DataPoints().At(1)
+	assert.Equal(t, dp.Timestamp(), dp.StartTimestamp())
+
+	metric = findMetric(t, md, "queue_wait_seconds")
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, metric.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, metric.ExponentialHistogram().AggregationTemporality())
+	dp = metric.ExponentialHistogram().DataPoints().At(0)
+	assert.Equal(t, dp.Timestamp(), dp.StartTimestamp())
+
+	assert.Equal(t, int64(1), findMetric(t, md, "prometheus.total_NAN_samples").Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, int64(1), findMetric(t, md, "prometheus.total_bad_datapoints").Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, pcommon.NewTimestampFromTime(jan20), findMetric(t, md, "prometheus.invalid_requests").Sum().DataPoints().At(0).StartTimestamp())
+}
+
+func TestNativeHistogramMetrics(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	md, err := parser.fromPrometheusWriteRequestMetrics(nativeHistogramWriteRequest())
+	require.NoError(t, err)
+	assertNativeHistogramMetrics(t, md, 0)
+	assert.Equal(t, 2+3, md.MetricCount())
+
+	parser = newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	createdTimestamp := jan20.UnixMilli() - 60000
+	md, err = parser.fromWriteRequest(nativeHistogramWriteRequest(), []int64{createdTimestamp, createdTimestamp})
+	require.NoError(t, err)
+	assertNativeHistogramMetrics(t, md, createdTimestamp)
+}
+
+func TestColumnarBatchNativeHistograms(t *testing.T) {
+	cb, mc := newTestColumnarBatch(100)
+	createdTimestamp := jan20.UnixMilli() - 60000
+	require.NoError(t, cb.addWriteRequest(nativeHistogramWriteRequest(), []int64{createdTimestamp, createdTimestamp}))
+	cb.close(context.Background())
+	md := <-mc
+	assertNativeHistogramMetrics(t, md, createdTimestamp)
+	assert.Equal(t, 2+3, md.MetricCount())
+}
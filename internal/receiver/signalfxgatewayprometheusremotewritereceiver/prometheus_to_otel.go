@@ -19,7 +19,9 @@ import (
 	"math"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -36,24 +38,50 @@ type metricData struct {
 	Exemplars      []prompb.Exemplar
 	Histograms     []prompb.Histogram
 	MetricMetadata prompb.MetricMetadata
+	// CreatedTimestamp is the created timestamp in milliseconds of the series sent by remote
+	// write 2.0 senders, 0 if unknown.
+	CreatedTimestamp int64
 }
 
+// droppedAttributesKey is the datapoint attribute recording how many labels were dropped
+// because they exceeded the configured attribute_limits max_count.
+const droppedAttributesKey = "prometheus.dropped_attributes"
+
 type prometheusRemoteOtelParser struct {
 	totalNans            *atomic.Int64
 	totalInvalidRequests *atomic.Int64
 	totalBadMetrics      *atomic.Int64
+	metadata             *metricMetadataCache
+	// reassembly reassembles the series of summaries, nil if they're translated on their own.
+	reassembly *reassembly
+	// startTimestamps tracks the start timestamps of cumulative series, nil if they're the
+	// timestamps of their samples unless the sender sent created timestamps.
+	startTimestamps *startTimestampCache
+	// typeRules type the series of families without metadata before the naming conventions.
+	typeRules []typeRule
+	// staleMarkers is how staleness markers are translated, one of the StaleMarkers values.
+	staleMarkers    string
+	attributeLimits AttributeLimitsConfig
 }
 
-func newPrometheusRemoteOtelParser() *prometheusRemoteOtelParser {
+func newPrometheusRemoteOtelParser(attributeLimits AttributeLimitsConfig) *prometheusRemoteOtelParser {
 	return &prometheusRemoteOtelParser{
 		totalNans:            &atomic.Int64{},
 		totalInvalidRequests: &atomic.Int64{},
 		totalBadMetrics:      &atomic.Int64{},
+		metadata:             newMetricMetadataCache(),
+		attributeLimits:      attributeLimits,
 	}
 }
 
 func (prwParser *prometheusRemoteOtelParser) fromPrometheusWriteRequestMetrics(request *prompb.WriteRequest) (pmetric.Metrics, error) {
-	metricFamiliesAndData, err := prwParser.partitionWriteRequest(request)
+	return prwParser.fromWriteRequest(request, nil)
+}
+
+// fromWriteRequest translates the write request, with the created timestamps of its series by
+// index, nil if none has one.
+func (prwParser *prometheusRemoteOtelParser) fromWriteRequest(request *prompb.WriteRequest, createdTimestamps []int64) (pmetric.Metrics, error) {
+	metricFamiliesAndData, err := prwParser.partitionWriteRequestWithCreatedTimestamps(request, createdTimestamps)
 	otelMetrics := prwParser.transformPrometheusRemoteWriteToOtel(metricFamiliesAndData)
 	startTime, endTime := getWriteRequestTimestampBounds(request)
 	scope := otelMetrics.ResourceMetrics().At(0).ScopeMetrics().At(0)
@@ -76,18 +104,21 @@ func (prwParser *prometheusRemoteOtelParser) transformPrometheusRemoteWriteToOte
 }
 
 func (prwParser *prometheusRemoteOtelParser) partitionWriteRequest(writeReq *prompb.WriteRequest) (map[prompb.MetricMetadata_MetricType][]metricData, error) {
+	return prwParser.partitionWriteRequestWithCreatedTimestamps(writeReq, nil)
+}
+
+func (prwParser *prometheusRemoteOtelParser) partitionWriteRequestWithCreatedTimestamps(writeReq *prompb.WriteRequest, createdTimestamps []int64) (map[prompb.MetricMetadata_MetricType][]metricData, error) {
 	partitions := make(map[prompb.MetricMetadata_MetricType][]metricData)
 	var translationErrors error
+	prwParser.metadata.update(writeReq.Metadata)
 	for index, ts := range writeReq.Timeseries {
 		metricName, err := internal.ExtractMetricNameLabel(ts.Labels)
 		if err != nil {
 			translationErrors = multierr.Append(translationErrors, err)
 		}
 
-		metricType := internal.DetermineMetricTypeByConvention(metricName, ts.Labels)
-		metricMetadata := prompb.MetricMetadata{
-			Type: metricType,
-		}
+		metricMetadata := prwParser.metricMetadata(metricName, ts.Labels)
+		metricType := metricMetadata.Type
 		md := metricData{
 			Labels:         ts.Labels,
 			Samples:        writeReq.Timeseries[index].Samples,
@@ -96,16 +127,38 @@ func (prwParser *prometheusRemoteOtelParser) partitionWriteRequest(writeReq *pro
 			MetricName:     metricName,
 			MetricMetadata: metricMetadata,
 		}
-		if len(md.Samples) < 1 {
+		if createdTimestamps != nil {
+			md.CreatedTimestamp = createdTimestamps[index]
+		}
+		if len(md.Samples) < 1 && len(md.Histograms) < 1 {
 			translationErrors = multierr.Append(translationErrors, fmt.Errorf("no samples found for  %s", metricName))
 			prwParser.totalInvalidRequests.Add(1)
 		}
+		if prwParser.reassembly != nil && prwParser.reassembly.add(md) {
+			continue
+		}
 		partitions[metricType] = append(partitions[metricType], md)
 	}
 
 	return partitions, translationErrors
 }
 
+// metricMetadata returns the metadata of the series, with the type, help and unit of the metadata
+// of its family if the sender sent it, and otherwise the type of the first matching type rule, or
+// the type determined by convention.
+func (prwParser *prometheusRemoteOtelParser) metricMetadata(metricName string, labels []prompb.Label) prompb.MetricMetadata {
+	metricMetadata, ok := prwParser.metadata.lookup(metricName)
+	if ok && metricMetadata.Type != prompb.MetricMetadata_UNKNOWN {
+		return metricMetadata
+	}
+	if metricType, matched := prwParser.typeByRules(metricName); matched {
+		metricMetadata.Type = metricType
+	} else {
+		metricMetadata.Type = internal.DetermineMetricTypeByConvention(metricName, labels)
+	}
+	return metricMetadata
+}
+
 // This actually converts from a prometheus prompdb.MetaDataType to the closest equivalent otel type
 // See https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/13bcae344506fe2169b59d213361d04094c651f6/receiver/prometheusreceiver/internal/util.go#L106
 func (prwParser *prometheusRemoteOtelParser) addMetrics(ilm pmetric.ScopeMetrics, metricType prompb.MetricMetadata_MetricType, metrics []metricData) {
@@ -116,11 +169,14 @@ func (prwParser *prometheusRemoteOtelParser) addMetrics(ilm pmetric.ScopeMetrics
 	default:
 		prwParser.addGaugeMetrics(ilm, metrics)
 	}
+	prwParser.addNativeHistogramMetrics(ilm, metrics)
 }
 
-func (prwParser *prometheusRemoteOtelParser) scaffoldNewMetric(ilm pmetric.ScopeMetrics, name string) pmetric.Metric {
+func (prwParser *prometheusRemoteOtelParser) scaffoldNewMetric(ilm pmetric.ScopeMetrics, metricsData metricData) pmetric.Metric {
 	nm := ilm.Metrics().AppendEmpty()
-	nm.SetName(name)
+	nm.SetName(metricsData.MetricName)
+	nm.SetDescription(metricsData.MetricMetadata.Help)
+	nm.SetUnit(metricsData.MetricMetadata.Unit)
 	return nm
 }
 
@@ -171,11 +227,14 @@ func (prwParser *prometheusRemoteOtelParser) addGaugeMetrics(ilm pmetric.ScopeMe
 			prwParser.totalBadMetrics.Add(1)
 			continue
 		}
-		nm := prwParser.scaffoldNewMetric(ilm, metricsData.MetricName)
-		nm.SetName(metricsData.MetricName)
+		if len(metricsData.Samples) == 0 && len(metricsData.Histograms) > 0 {
+			// native histogram series are handled by addNativeHistogramMetrics
+			continue
+		}
+		nm := prwParser.scaffoldNewMetric(ilm, metricsData)
 		gauge := nm.SetEmptyGauge()
 		for _, sample := range metricsData.Samples {
-			if math.IsNaN(sample.Value) {
+			if math.IsNaN(sample.Value) && !prwParser.keepStaleMarker(sample.Value) {
 				prwParser.totalNans.Add(1)
 				continue
 			}
@@ -185,6 +244,7 @@ func (prwParser *prometheusRemoteOtelParser) addGaugeMetrics(ilm pmetric.ScopeMe
 			prwParser.setFloatOrInt(dp, sample)
 			prwParser.setAttributes(dp, metricsData.Labels)
 		}
+		addExemplars[pmetric.NumberDataPoint](gauge.DataPoints(), metricsData.Exemplars)
 	}
 }
 
@@ -195,24 +255,39 @@ func (prwParser *prometheusRemoteOtelParser) addCounterMetrics(ilm pmetric.Scope
 			prwParser.totalBadMetrics.Add(1)
 			continue
 		}
-		nm := prwParser.scaffoldNewMetric(ilm, metricsData.MetricName)
+		if len(metricsData.Samples) == 0 && len(metricsData.Histograms) > 0 {
+			// native histogram series are handled by addNativeHistogramMetrics
+			continue
+		}
+		nm := prwParser.scaffoldNewMetric(ilm, metricsData)
 		sumMetric := nm.SetEmptySum()
 		sumMetric.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
 		sumMetric.SetIsMonotonic(true)
 		for _, sample := range metricsData.Samples {
-			if math.IsNaN(sample.Value) {
+			if math.IsNaN(sample.Value) && !prwParser.keepStaleMarker(sample.Value) {
 				prwParser.totalNans.Add(1)
 				continue
 			}
 			dp := nm.Sum().DataPoints().AppendEmpty()
 			dp.SetTimestamp(prometheusToOtelTimestamp(sample.GetTimestamp()))
-			dp.SetStartTimestamp(prometheusToOtelTimestamp(sample.GetTimestamp()))
+			dp.SetStartTimestamp(prometheusToOtelTimestamp(prwParser.cumulativeStart(metricsData.MetricName, metricsData.Labels,
+				metricsData.CreatedTimestamp, sample.GetTimestamp(), sample.Value)))
 			prwParser.setFloatOrInt(dp, sample)
 			prwParser.setAttributes(dp, metricsData.Labels)
 		}
+		addExemplars[pmetric.NumberDataPoint](sumMetric.DataPoints(), metricsData.Exemplars)
 	}
 }
 
+// startTimestamp returns the start timestamp of a cumulative sample: the created timestamp of its
+// series if known and not after the sample, and otherwise the timestamp of the sample.
+func startTimestamp(createdTimestamp, timestamp int64) int64 {
+	if createdTimestamp != 0 && createdTimestamp <= timestamp {
+		return createdTimestamp
+	}
+	return timestamp
+}
+
 func getSampleTimestampBounds(samples []prompb.Sample) (int64, int64) {
 	if len(samples) < 1 {
 		return -1, -1
@@ -234,18 +309,33 @@ func getWriteRequestTimestampBounds(request *prompb.WriteRequest) (time.Time, ti
 	minTimestamp := int64(math.MaxInt64)
 	maxTimestamp := int64(math.MinInt64)
 	for _, ts := range request.Timeseries {
-		sampleMin, sampleMax := getSampleTimestampBounds(ts.Samples)
-		if sampleMin < minTimestamp {
-			minTimestamp = sampleMin
+		if len(ts.Samples) > 0 || len(ts.Histograms) == 0 {
+			sampleMin, sampleMax := getSampleTimestampBounds(ts.Samples)
+			if sampleMin < minTimestamp {
+				minTimestamp = sampleMin
+			}
+			if sampleMax > maxTimestamp {
+				maxTimestamp = sampleMax
+			}
 		}
-		if sampleMax > maxTimestamp {
-			maxTimestamp = sampleMax
+		for _, h := range ts.Histograms {
+			minTimestamp, maxTimestamp = min(minTimestamp, h.Timestamp), max(maxTimestamp, h.Timestamp)
 		}
 	}
 	return time.UnixMilli(minTimestamp), time.UnixMilli(maxTimestamp)
 }
 
+// keepStaleMarker returns whether the value is a staleness marker translated as a datapoint with
+// no recorded value, rather than dropped and counted like other NaN samples.
+func (prwParser *prometheusRemoteOtelParser) keepStaleMarker(v float64) bool {
+	return prwParser.staleMarkers == StaleMarkersNoRecordedValue && value.IsStaleNaN(v)
+}
+
 func (prwParser *prometheusRemoteOtelParser) setFloatOrInt(dp pmetric.NumberDataPoint, sample prompb.Sample) error {
+	if prwParser.keepStaleMarker(sample.Value) {
+		dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		return nil
+	}
 	if math.IsNaN(sample.Value) {
 		return fmt.Errorf("NAN value found")
 	}
@@ -262,9 +352,36 @@ func prometheusToOtelTimestamp(ts int64) pcommon.Timestamp {
 }
 
 func (prwParser *prometheusRemoteOtelParser) setAttributes(dp pmetric.NumberDataPoint, labels []prompb.Label) {
+	prwParser.putAttributes(dp.Attributes(), labels)
+}
+
+func (prwParser *prometheusRemoteOtelParser) putAttributes(attrs pcommon.Map, labels []prompb.Label) {
+	limits := prwParser.attributeLimits
+	dropped := 0
 	for _, attr := range labels {
-		if attr.Name != "__name__" {
-			dp.Attributes().PutStr(attr.Name, attr.Value)
+		if attr.Name == "__name__" {
+			continue
 		}
+		if limits.MaxCount > 0 && attrs.Len() >= limits.MaxCount {
+			dropped++
+			continue
+		}
+		attrs.PutStr(attr.Name, truncateValue(attr.Value, limits.MaxValueLength, limits.TruncationMarker))
+	}
+	if dropped > 0 {
+		attrs.PutInt(droppedAttributesKey, int64(dropped))
+	}
+}
+
+// truncateValue cuts value to at most maxLength bytes, without splitting a multi-byte character,
+// and appends the marker. A maxLength of 0 doesn't limit the value.
+func truncateValue(value string, maxLength int, marker string) string {
+	if maxLength <= 0 || len(value) <= maxLength {
+		return value
+	}
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
 	}
+	return value[:end] + marker
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,7 +32,7 @@ import (
 func TestParseAndPartitionPrometheusRemoteWriteRequest(t *testing.T) {
 	reporter := newMockReporter()
 	require.NotNil(t, reporter)
-	parser := newPrometheusRemoteOtelParser()
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
 
 	sampleWriteRequests := flattenWriteRequests(getWriteRequestsOfAllTypesWithoutMetadata())
 	noMdPartitions, err := parser.partitionWriteRequest(sampleWriteRequests)
@@ -182,7 +183,7 @@ func TestAddMetrics(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			reporter := newMockReporter()
 			require.NotNil(t, reporter)
-			parser := newPrometheusRemoteOtelParser()
+			parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
 			actual, err := parser.fromPrometheusWriteRequestMetrics(tc.sample)
 			if tc.errWanted {
 				assert.Error(t, err)
@@ -198,3 +199,110 @@ func TestAddMetrics(t *testing.T) {
 
 	}
 }
+
+func staleMarkersWriteRequest() *prompb.WriteRequest {
+	staleNaN := math.Float64frombits(value.StaleNaN)
+	return &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "instance", Value: "api-0"}},
+			Samples: []prompb.Sample{
+				{Value: 1, Timestamp: jan20.UnixMilli()},
+				{Value: staleNaN, Timestamp: jan20.UnixMilli() + 1000},
+				{Value: math.NaN(), Timestamp: jan20.UnixMilli() + 2000},
+			},
+		},
+		{
+			Labels:     []prompb.Label{{Name: "__name__", Value: "rpc_duration_seconds"}, {Name: "instance", Value: "api-0"}},
+			Histograms: []prompb.Histogram{{Sum: staleNaN, Timestamp: jan20.UnixMilli()}},
+		},
+	}}
+}
+
+func TestStaleMarkers(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	parser.staleMarkers = StaleMarkersNoRecordedValue
+	md, err := parser.fromPrometheusWriteRequestMetrics(staleMarkersWriteRequest())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, parser.totalNans.Load())
+
+	dps := findMetric(t, md, "up").Gauge().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	assert.False(t, dps.At(0).Flags().NoRecordedValue())
+	assert.True(t, dps.At(1).Flags().NoRecordedValue())
+	assert.Equal(t, pmetric.NumberDataPointValueTypeEmpty, dps.At(1).ValueType())
+	assert.Equal(t, prometheusToOtelTimestamp(jan20.UnixMilli()+1000), dps.At(1).Timestamp())
+	assert.Equal(t, map[string]any{"instance": "api-0"}, dps.At(1).Attributes().AsRaw())
+
+	histogramDps := findMetric(t, md, "rpc_duration_seconds").ExponentialHistogram().DataPoints()
+	require.Equal(t, 1, histogramDps.Len())
+	assert.True(t, histogramDps.At(0).Flags().NoRecordedValue())
+	assert.Zero(t, histogramDps.At(0).Count())
+	assert.Equal(t, map[string]any{"instance": "api-0"}, histogramDps.At(0).Attributes().AsRaw())
+
+	// staleness markers are dropped like other NaN samples by default
+	parser = newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	parser.staleMarkers = StaleMarkersDrop
+	md, err = parser.fromPrometheusWriteRequestMetrics(staleMarkersWriteRequest())
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, parser.totalNans.Load())
+	assert.Equal(t, 1, findMetric(t, md, "up").Gauge().DataPoints().Len())
+	assert.Zero(t, findMetric(t, md, "rpc_duration_seconds").ExponentialHistogram().DataPoints().Len())
+}
+
+func TestSetAttributesLimits(t *testing.T) {
+	labels := []prompb.Label{
+		{Name: "__name__", Value: "db_query_duration_seconds"},
+		{Name: "db", Value: "orders"},
+		{Name: "host", Value: "db-1"},
+		{Name: "query", Value: "SELECT * FROM orders WHERE id = 1"},
+		{Name: "region", Value: "us-west-2"},
+	}
+	for _, tt := range []struct {
+		expected map[string]any
+		name     string
+		limits   AttributeLimitsConfig
+	}{
+		{
+			name:   "unlimited",
+			limits: AttributeLimitsConfig{TruncationMarker: "..."},
+			expected: map[string]any{
+				"db": "orders", "host": "db-1", "query": "SELECT * FROM orders WHERE id = 1", "region": "us-west-2",
+			},
+		},
+		{
+			name:   "value length",
+			limits: AttributeLimitsConfig{TruncationMarker: "...", MaxValueLength: 8},
+			expected: map[string]any{
+				"db": "orders", "host": "db-1", "query": "SELECT *...", "region": "us-west...",
+			},
+		},
+		{
+			name:   "count",
+			limits: AttributeLimitsConfig{MaxCount: 2},
+			expected: map[string]any{
+				"db": "orders", "host": "db-1", droppedAttributesKey: int64(2),
+			},
+		},
+		{
+			name:   "value length and count",
+			limits: AttributeLimitsConfig{TruncationMarker: "~", MaxValueLength: 4, MaxCount: 1},
+			expected: map[string]any{
+				"db": "orde~", droppedAttributesKey: int64(3),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := pmetric.NewNumberDataPoint()
+			newPrometheusRemoteOtelParser(tt.limits).setAttributes(dp, labels)
+			assert.Equal(t, tt.expected, dp.Attributes().AsRaw())
+		})
+	}
+}
+
+func TestTruncateValue(t *testing.T) {
+	assert.Equal(t, "héllo", truncateValue("héllo", 0, "..."))
+	assert.Equal(t, "héllo", truncateValue("héllo", 6, "..."))
+	// "é" is 2 bytes, so cutting at 2 bytes would split it
+	assert.Equal(t, "h...", truncateValue("héllo", 2, "..."))
+	assert.Equal(t, "hé...", truncateValue("héllo", 3, "..."))
+}
@@ -0,0 +1,199 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal/metadata"
+)
+
+const (
+	quotaWindow = time.Hour
+
+	// defaultTenantKey is the quota all tenants without a specific limit share, so arbitrary
+	// tenant header values don't each create a quota.
+	defaultTenantKey = "default_tenant"
+)
+
+// quotaUsage is the number of samples written by a path or tenant in the current window.
+type quotaUsage struct {
+	start time.Time
+	used  int64
+}
+
+// quotas enforces the hourly sample quotas of write paths and tenants. Each path or tenant
+// has a fixed one hour window starting with its first write.
+type quotas struct {
+	now       func() time.Time
+	lastSweep time.Time
+	usage     map[string]*quotaUsage
+	paths     map[string]int64
+	tenants   map[string]int64
+	accepted  metric.Int64Counter
+	rejected  metric.Int64Counter
+	header    string
+	defLimit  int64
+	mu        sync.Mutex
+}
+
+// quotaDecision is the outcome of accounting a write request to its quota.
+type quotaDecision struct {
+	key       string
+	limit     int64
+	remaining int64
+	reset     time.Duration
+	admitted  bool
+}
+
+func newQuotas(cfg QuotasConfig, set component.TelemetrySettings) (*quotas, error) {
+	q := &quotas{
+		now:      time.Now,
+		usage:    map[string]*quotaUsage{},
+		paths:    map[string]int64{},
+		tenants:  map[string]int64{},
+		header:   cfg.TenantHeader,
+		defLimit: cfg.DefaultSamplesPerHour,
+	}
+	q.lastSweep = q.now()
+	for _, limit := range cfg.Limits {
+		if limit.Path != "" {
+			q.paths[limit.Path] = limit.SamplesPerHour
+		} else {
+			q.tenants[limit.Tenant] = limit.SamplesPerHour
+		}
+	}
+
+	meterProvider := set.MeterProvider
+	if set.LeveledMeterProvider != nil {
+		meterProvider = set.LeveledMeterProvider(configtelemetry.LevelBasic)
+	}
+	meter := meterProvider.Meter(metadata.ScopeName)
+	var err error
+	if q.accepted, err = meter.Int64Counter(
+		"prometheus_remote_write_quota_samples_accepted",
+		metric.WithDescription("Number of samples accepted per write path or tenant quota."),
+		metric.WithUnit("{samples}"),
+	); err != nil {
+		return nil, err
+	}
+	if q.rejected, err = meter.Int64Counter(
+		"prometheus_remote_write_quota_samples_rejected",
+		metric.WithDescription("Number of samples rejected for exceeding their write path or tenant quota."),
+		metric.WithUnit("{samples}"),
+	); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// writePaths returns the write paths with a quota.
+func (q *quotas) writePaths() []string {
+	paths := make([]string, 0, len(q.paths))
+	for path := range q.paths {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// admit accounts the samples of the request to the quota of its tenant, if identified by
+// the tenant header, or else of its path. Tenants without a specific limit share the default
+// quota. Requests exceeding the quota aren't accounted.
+func (q *quotas) admit(ctx context.Context, r *http.Request, samples int64) quotaDecision {
+	key, limit := "path:"+r.URL.Path, q.defLimit
+	if tenant := r.Header.Get(q.header); q.header != "" && tenant != "" {
+		key = defaultTenantKey
+		if l, ok := q.tenants[tenant]; ok {
+			key, limit = "tenant:"+tenant, l
+		}
+	} else if l, ok := q.paths[r.URL.Path]; ok {
+		limit = l
+	}
+	if limit == 0 {
+		return quotaDecision{key: key, admitted: true}
+	}
+
+	q.mu.Lock()
+	now := q.now()
+	q.sweep(now)
+	u, ok := q.usage[key]
+	if !ok || now.Sub(u.start) >= quotaWindow {
+		u = &quotaUsage{start: now}
+		q.usage[key] = u
+	}
+	d := quotaDecision{key: key, limit: limit, reset: u.start.Add(quotaWindow).Sub(now)}
+	if u.used+samples <= limit {
+		u.used += samples
+		d.admitted = true
+	}
+	d.remaining = limit - u.used
+	q.mu.Unlock()
+
+	attrs := metric.WithAttributes(attribute.String("quota", key))
+	if d.admitted {
+		q.accepted.Add(ctx, samples, attrs)
+	} else {
+		q.rejected.Add(ctx, samples, attrs)
+	}
+	return d
+}
+
+// sweep drops the usage of expired windows once per window, so paths and tenants that
+// stopped writing aren't retained. Must be called with the lock held.
+func (q *quotas) sweep(now time.Time) {
+	if now.Sub(q.lastSweep) < quotaWindow {
+		return
+	}
+	for key, u := range q.usage {
+		if now.Sub(u.start) >= quotaWindow {
+			delete(q.usage, key)
+		}
+	}
+	q.lastSweep = now
+}
+
+// setHeaders sets the X-RateLimit-* headers of limited requests, and Retry-After if rejected.
+func (d quotaDecision) setHeaders(w http.ResponseWriter) {
+	if d.limit == 0 {
+		return
+	}
+	reset := strconv.FormatInt(int64(math.Ceil(d.reset.Seconds())), 10)
+	w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(d.limit, 10))
+	w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(d.remaining, 10))
+	w.Header().Set("X-RateLimit-Reset", reset)
+	if !d.admitted {
+		w.Header().Set("Retry-After", reset)
+	}
+}
+
+// sampleCount returns the number of samples and histograms in the request.
+func sampleCount(req *prompb.WriteRequest) int64 {
+	var n int64
+	for _, ts := range req.Timeseries {
+		n += int64(len(ts.Samples) + len(ts.Histograms))
+	}
+	return n
+}
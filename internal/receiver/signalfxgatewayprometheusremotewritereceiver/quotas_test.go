@@ -0,0 +1,141 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+func newTestQuotas(t *testing.T) (*quotas, *time.Time) {
+	q, err := newQuotas(QuotasConfig{
+		Enabled:      true,
+		TenantHeader: "X-Scope-OrgID",
+		Limits: []QuotaLimitConfig{
+			{Path: "/metrics/team-a", SamplesPerHour: 10},
+			{Tenant: "team-b", SamplesPerHour: 5},
+		},
+	}, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	var now *time.Time
+	q.now, now = testclock.New(jan20)
+	q.lastSweep = *now
+	return q, now
+}
+
+func TestQuotasAdmit(t *testing.T) {
+	q, now := newTestQuotas(t)
+	ctx := context.Background()
+	teamA := httptest.NewRequest(http.MethodPost, "/metrics/team-a", nil)
+
+	d := q.admit(ctx, teamA, 6)
+	assert.True(t, d.admitted)
+	assert.Equal(t, int64(10), d.limit)
+	assert.Equal(t, int64(4), d.remaining)
+	assert.Equal(t, time.Hour, d.reset)
+
+	*now = now.Add(30 * time.Minute)
+	d = q.admit(ctx, teamA, 6)
+	assert.False(t, d.admitted)
+	assert.Equal(t, int64(4), d.remaining)
+	assert.Equal(t, 30*time.Minute, d.reset)
+	assert.True(t, q.admit(ctx, teamA, 4).admitted)
+
+	// the window resets an hour after the first write
+	*now = now.Add(30 * time.Minute)
+	d = q.admit(ctx, teamA, 6)
+	assert.True(t, d.admitted)
+	assert.Equal(t, int64(4), d.remaining)
+
+	// tenants are accounted regardless of their path
+	teamB := httptest.NewRequest(http.MethodPost, "/metrics/team-a", nil)
+	teamB.Header.Set("X-Scope-OrgID", "team-b")
+	assert.False(t, q.admit(ctx, teamB, 6).admitted)
+	assert.True(t, q.admit(ctx, teamB, 5).admitted)
+
+	// paths and tenants without a limit aren't limited without a default quota
+	d = q.admit(ctx, httptest.NewRequest(http.MethodPost, "/metrics", nil), 1000)
+	assert.True(t, d.admitted)
+	assert.Equal(t, int64(0), d.limit)
+	other := httptest.NewRequest(http.MethodPost, "/metrics/team-a", nil)
+	other.Header.Set("X-Scope-OrgID", "team-c")
+	assert.True(t, q.admit(ctx, other, 1000).admitted)
+}
+
+func TestQuotasDefaultLimitAndSweep(t *testing.T) {
+	q, now := newTestQuotas(t)
+	q.defLimit = 3
+	ctx := context.Background()
+	assert.True(t, q.admit(ctx, httptest.NewRequest(http.MethodPost, "/metrics", nil), 3).admitted)
+	assert.False(t, q.admit(ctx, httptest.NewRequest(http.MethodPost, "/metrics", nil), 1).admitted)
+	// tenants without a limit share the default quota
+	teamC := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+	teamC.Header.Set("X-Scope-OrgID", "team-c")
+	teamD := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+	teamD.Header.Set("X-Scope-OrgID", "team-d")
+	d := q.admit(ctx, teamC, 2)
+	assert.True(t, d.admitted)
+	assert.Equal(t, defaultTenantKey, d.key)
+	assert.False(t, q.admit(ctx, teamD, 2).admitted)
+	assert.True(t, q.admit(ctx, teamD, 1).admitted)
+	assert.Len(t, q.usage, 2)
+
+	*now = now.Add(time.Hour)
+	assert.True(t, q.admit(ctx, teamC, 1).admitted)
+	assert.Len(t, q.usage, 1)
+}
+
+func TestQuotasHandler(t *testing.T) {
+	q, _ := newTestQuotas(t)
+	req := sampleCounterWq()
+	q.paths["/metrics"] = sampleCount(req)
+	mc := make(chan pmetric.Metrics, 2)
+	handler := newHandler(newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), &serverConfig{
+		Reporter: newMockReporter(),
+		Quotas:   q,
+	}, mc)
+
+	data, err := proto.Marshal(req)
+	require.NoError(t, err)
+	body := snappy.Encode(nil, data)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "3600", rec.Header().Get("X-RateLimit-Reset"))
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+	assert.Len(t, mc, 1)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "3600", rec.Header().Get("Retry-After"))
+	assert.Len(t, mc, 1)
+}
@@ -0,0 +1,488 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal/metadata"
+)
+
+const (
+	quantileLabel = "quantile"
+	bucketLabel   = "le"
+)
+
+// seriesPart is the part of a datapoint a series of a reassembled family holds.
+type seriesPart int
+
+const (
+	partQuantile seriesPart = iota
+	partBucket
+	partSum
+	partCount
+)
+
+// reassembledSeries is a series of a summary or histogram family.
+type reassembledSeries struct {
+	family     string
+	familyType prompb.MetricMetadata_MetricType
+	part       seriesPart
+	// bound is the quantile of quantile series and the upper bound of bucket series.
+	bound float64
+}
+
+// reassembledPoint is a datapoint being reassembled from the series of a metric family with the
+// same labels, other than the quantile or le label distinguishing them, and the same timestamp.
+type reassembledPoint struct {
+	collectedSince time.Time
+	// exemplars are the exemplars of the bucket series, nil if there are none.
+	exemplars  *pmetric.ExemplarSlice
+	key        string
+	family     string
+	familyType prompb.MetricMetadata_MetricType
+	// labels are the labels of the series, without the metric name and distinguishing labels.
+	labels []prompb.Label
+	// values are the values of the quantiles, or the cumulative counts of the buckets.
+	values    []boundValue
+	timestamp int64
+	// createdTimestamp is the latest created timestamp of the series, 0 if unknown.
+	createdTimestamp int64
+	sum              float64
+	count            float64
+	hasSum           bool
+	hasCount         bool
+	// stale is whether a series of the datapoint was marked stale, so the family disappeared.
+	stale bool
+}
+
+type boundValue struct {
+	bound float64
+	value float64
+}
+
+// reassembly collects the quantile, _sum and _count series of Prometheus summaries and the
+// _bucket, _sum and _count series of Prometheus histograms, which are otherwise translated as
+// independent gauges and counters, and reassembles them into OTLP summary and explicit bucket
+// histogram datapoints. Senders shard series across write requests, so the series of a sample
+// are collected for a window since the first of them was received, and the reassembled
+// datapoints of each window are sent on their own.
+type reassembly struct {
+	mc     chan<- pmetric.Metrics
+	parser *prometheusRemoteOtelParser
+	// points are the datapoints being reassembled, by family, labels and timestamp.
+	points map[string]*reassembledPoint
+	// families are the types of the families known from their quantile or bucket series, so
+	// their _sum and _count series are recognized without metadata.
+	families map[string]prompb.MetricMetadata_MetricType
+	// metadata is the metadata of the reassembled families.
+	metadata map[string]prompb.MetricMetadata
+	now      func() time.Time
+	keyBuf   []byte
+	// queue are the datapoints being reassembled, in the order they were first received.
+	queue      []*reassembledPoint
+	sending    sync.WaitGroup
+	window     time.Duration
+	mu         sync.Mutex
+	summaries  bool
+	histograms bool
+	closed     bool
+	// done is closed to stop sending datapoints once the pipeline no longer consumes them.
+	done      chan struct{}
+	abortOnce sync.Once
+}
+
+func newReassembly(cfg ReassemblyConfig, parser *prometheusRemoteOtelParser, mc chan<- pmetric.Metrics) *reassembly {
+	return &reassembly{
+		mc:         mc,
+		parser:     parser,
+		points:     map[string]*reassembledPoint{},
+		families:   map[string]prompb.MetricMetadata_MetricType{},
+		metadata:   map[string]prompb.MetricMetadata{},
+		now:        time.Now,
+		window:     cfg.Window,
+		summaries:  cfg.Summaries,
+		histograms: cfg.Histograms,
+		done:       make(chan struct{}),
+	}
+}
+
+func (p *reassembledPoint) Timestamp() pcommon.Timestamp {
+	return prometheusToOtelTimestamp(p.timestamp)
+}
+
+func (p *reassembledPoint) Exemplars() pmetric.ExemplarSlice {
+	if p.exemplars == nil {
+		exemplars := pmetric.NewExemplarSlice()
+		p.exemplars = &exemplars
+	}
+	return *p.exemplars
+}
+
+// setValue sets the value of the quantile or bucket, replacing the value of a resent series.
+func (p *reassembledPoint) setValue(bound, value float64) {
+	for i := range p.values {
+		if p.values[i].bound == bound {
+			p.values[i].value = value
+			return
+		}
+	}
+	p.values = append(p.values, boundValue{bound: bound, value: value})
+}
+
+// add collects the samples of the series if it's a series of a reassembled family, returning
+// whether it was collected rather than having to be translated on its own.
+func (r *reassembly) add(md metricData) bool {
+	if md.MetricName == "" || len(md.Samples) == 0 {
+		return false
+	}
+	series, ok := r.series(md)
+	if !ok {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+	if series.part == partQuantile || series.part == partBucket {
+		r.families[series.family] = series.familyType
+	}
+	if _, ok = r.metadata[series.family]; !ok || md.MetricMetadata.Help != "" || md.MetricMetadata.Unit != "" {
+		r.metadata[series.family] = md.MetricMetadata
+	}
+	points := make([]*reassembledPoint, 0, len(md.Samples))
+	for _, sample := range md.Samples {
+		stale := r.parser.keepStaleMarker(sample.Value)
+		if math.IsNaN(sample.Value) && !stale {
+			r.parser.totalNans.Add(1)
+			continue
+		}
+		point := r.point(series, md.Labels, sample.Timestamp)
+		point.createdTimestamp = max(point.createdTimestamp, md.CreatedTimestamp)
+		switch {
+		case stale:
+			point.stale = true
+		case series.part == partQuantile, series.part == partBucket:
+			point.setValue(series.bound, sample.Value)
+		case series.part == partSum:
+			point.sum, point.hasSum = sample.Value, true
+		case series.part == partCount:
+			point.count, point.hasCount = sample.Value, true
+		}
+		points = append(points, point)
+	}
+	if series.part == partBucket {
+		// OTLP summaries have no exemplars, and the ones of _sum and _count series are dropped
+		addExemplars[*reassembledPoint](dataPointList[*reassembledPoint](points), md.Exemplars)
+	}
+	return true
+}
+
+// series returns the family of the series if it's a quantile, _sum or _count series of a summary
+// or a _bucket, _sum or _count series of a histogram, and their reassembly is enabled. Without
+// metadata, the _sum and _count series of a family are only recognized once one of its quantile
+// or bucket series was received.
+func (r *reassembly) series(md metricData) (reassembledSeries, bool) {
+	switch {
+	case md.MetricMetadata.Type == prompb.MetricMetadata_SUMMARY && r.summaries:
+		if quantile, ok := boundLabel(md.Labels, quantileLabel); ok {
+			return reassembledSeries{family: md.MetricName, familyType: prompb.MetricMetadata_SUMMARY, part: partQuantile, bound: quantile}, true
+		}
+	case md.MetricMetadata.Type == prompb.MetricMetadata_HISTOGRAM && r.histograms:
+		family, isBucket := strings.CutSuffix(md.MetricName, "_bucket")
+		if le, ok := boundLabel(md.Labels, bucketLabel); ok && isBucket {
+			return reassembledSeries{family: family, familyType: prompb.MetricMetadata_HISTOGRAM, part: partBucket, bound: le}, true
+		}
+	}
+	for _, suffix := range []struct {
+		suffix string
+		part   seriesPart
+	}{{"_sum", partSum}, {"_count", partCount}} {
+		family, ok := strings.CutSuffix(md.MetricName, suffix.suffix)
+		if !ok {
+			continue
+		}
+		familyType := r.parser.metadata.familyType(family)
+		if familyType != prompb.MetricMetadata_SUMMARY && familyType != prompb.MetricMetadata_HISTOGRAM {
+			r.mu.Lock()
+			familyType = r.families[family]
+			r.mu.Unlock()
+		}
+		if familyType == prompb.MetricMetadata_SUMMARY && r.summaries || familyType == prompb.MetricMetadata_HISTOGRAM && r.histograms {
+			return reassembledSeries{family: family, familyType: familyType, part: suffix.part}, true
+		}
+		return reassembledSeries{}, false
+	}
+	return reassembledSeries{}, false
+}
+
+// boundLabel returns the value of the quantile or le label, if the series has a valid one.
+func boundLabel(labels []prompb.Label, name string) (float64, bool) {
+	for _, label := range labels {
+		if label.Name == name {
+			bound, err := strconv.ParseFloat(label.Value, 64)
+			return bound, err == nil && !math.IsNaN(bound)
+		}
+	}
+	return 0, false
+}
+
+// distinguishingLabel returns the label distinguishing the series of a datapoint of the family type.
+func distinguishingLabel(familyType prompb.MetricMetadata_MetricType) string {
+	if familyType == prompb.MetricMetadata_HISTOGRAM {
+		return bucketLabel
+	}
+	return quantileLabel
+}
+
+// point returns the datapoint of the family of the series with the labels at the timestamp,
+// adding it if it isn't being reassembled yet. It must be called with the lock held.
+func (r *reassembly) point(series reassembledSeries, labels []prompb.Label, timestamp int64) *reassembledPoint {
+	distinguishing := distinguishingLabel(series.familyType)
+	key := append(r.keyBuf[:0], series.family...)
+	key = append(key, 0xff)
+	for _, label := range labels {
+		if label.Name == "__name__" || label.Name == distinguishing {
+			continue
+		}
+		key = append(key, label.Name...)
+		key = append(key, 0xfe)
+		key = append(key, label.Value...)
+		key = append(key, 0xff)
+	}
+	key = strconv.AppendInt(key, timestamp, 10)
+	r.keyBuf = key
+	if point, ok := r.points[string(key)]; ok {
+		return point
+	}
+	point := &reassembledPoint{
+		collectedSince: r.now(),
+		key:            string(key),
+		family:         series.family,
+		familyType:     series.familyType,
+		timestamp:      timestamp,
+		labels: slices.DeleteFunc(slices.Clone(labels), func(label prompb.Label) bool {
+			return label.Name == "__name__" || label.Name == distinguishing
+		}),
+	}
+	r.points[point.key] = point
+	r.queue = append(r.queue, point)
+	return point
+}
+
+// takeCollected returns the datapoints collected for a whole window, or every datapoint if all
+// is set, removing them. It must be called with the lock held, and the returned datapoints
+// passed to send.
+func (r *reassembly) takeCollected(all bool) []*reassembledPoint {
+	collected := len(r.queue)
+	if !all {
+		since := r.now().Add(-r.window)
+		collected, _ = slices.BinarySearchFunc(r.queue, since, func(point *reassembledPoint, since time.Time) int {
+			if point.collectedSince.After(since) {
+				return 1
+			}
+			return -1
+		})
+	}
+	if collected == 0 {
+		return nil
+	}
+	points := r.queue[:collected:collected]
+	r.queue = slices.Clone(r.queue[collected:])
+	for _, point := range points {
+		delete(r.points, point.key)
+	}
+	r.sending.Add(1)
+	return points
+}
+
+// send translates and sends the datapoints returned by takeCollected, if any. The datapoints
+// are dropped if the reassembly is aborted before they're received.
+func (r *reassembly) send(points []*reassembledPoint) {
+	if len(points) == 0 {
+		return
+	}
+	defer r.sending.Done()
+	select {
+	case r.mc <- r.translate(points):
+	case <-r.done:
+	}
+}
+
+// translate translates the datapoints to a summary or histogram per family.
+func (r *reassembly) translate(points []*reassembledPoint) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(metadata.ScopeName)
+	sm.Scope().SetVersion("0.1")
+	metrics := map[string]pmetric.Metric{}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, point := range points {
+		metric, ok := metrics[point.family]
+		if !ok {
+			family := r.metadata[point.family]
+			metric = sm.Metrics().AppendEmpty()
+			metric.SetName(point.family)
+			metric.SetDescription(family.Help)
+			metric.SetUnit(family.Unit)
+			if point.familyType == prompb.MetricMetadata_HISTOGRAM {
+				metric.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			} else {
+				metric.SetEmptySummary()
+			}
+			metrics[point.family] = metric
+		}
+		slices.SortFunc(point.values, func(a, b boundValue) int {
+			return cmp.Compare(a.bound, b.bound)
+		})
+		if metric.Type() == pmetric.MetricTypeHistogram {
+			r.translateHistogram(metric.Histogram().DataPoints().AppendEmpty(), point)
+		} else {
+			r.translateSummary(metric.Summary().DataPoints().AppendEmpty(), point)
+		}
+	}
+	return md
+}
+
+func (r *reassembly) translateSummary(dp pmetric.SummaryDataPoint, point *reassembledPoint) {
+	dp.SetTimestamp(point.Timestamp())
+	dp.SetStartTimestamp(r.pointStart(point, point.count))
+	r.parser.putAttributes(dp.Attributes(), point.labels)
+	if point.stale {
+		dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		return
+	}
+	dp.SetSum(point.sum)
+	dp.SetCount(uint64(point.count))
+	for _, q := range point.values {
+		qv := dp.QuantileValues().AppendEmpty()
+		qv.SetQuantile(q.bound)
+		qv.SetValue(q.value)
+	}
+}
+
+// translateHistogram translates the cumulative bucket counts of the datapoint to the counts of
+// explicit buckets. The count of the +Inf bucket is the one of the _count series, or of the +Inf
+// bucket series if the _count series wasn't received.
+func (r *reassembly) translateHistogram(dp pmetric.HistogramDataPoint, point *reassembledPoint) {
+	dp.SetTimestamp(point.Timestamp())
+	r.parser.putAttributes(dp.Attributes(), point.labels)
+	if point.stale {
+		dp.SetStartTimestamp(r.pointStart(point, 0))
+		dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+		return
+	}
+	if point.hasSum {
+		dp.SetSum(point.sum)
+	}
+	buckets := point.values
+	total := point.count
+	if n := len(buckets); n > 0 && math.IsInf(buckets[n-1].bound, 1) {
+		if !point.hasCount {
+			total = buckets[n-1].value
+		}
+		buckets = buckets[:n-1]
+	} else if !point.hasCount && n > 0 {
+		total = buckets[n-1].value
+	}
+	dp.ExplicitBounds().EnsureCapacity(len(buckets))
+	dp.BucketCounts().EnsureCapacity(len(buckets) + 1)
+	previous := 0.0
+	for _, bucket := range buckets {
+		dp.ExplicitBounds().Append(bucket.bound)
+		dp.BucketCounts().Append(uint64(max(bucket.value-previous, 0)))
+		previous = max(bucket.value, previous)
+	}
+	dp.BucketCounts().Append(uint64(max(total-previous, 0)))
+	dp.SetCount(uint64(total))
+	dp.SetStartTimestamp(r.pointStart(point, total))
+	if point.exemplars != nil {
+		point.exemplars.MoveAndAppendTo(dp.Exemplars())
+	}
+}
+
+// pointStart returns the start timestamp of the datapoint with the count, whose series are
+// tracked as the series of its family.
+func (r *reassembly) pointStart(point *reassembledPoint, count float64) pcommon.Timestamp {
+	if point.stale {
+		count = math.NaN()
+	}
+	return prometheusToOtelTimestamp(r.parser.cumulativeStart(point.family, point.labels, point.createdTimestamp, point.timestamp, count))
+}
+
+// run sends the datapoints collected for a whole window every window until the context is done,
+// aborting the reassembly since its metrics are no longer consumed.
+func (r *reassembly) run(ctx context.Context) {
+	ticker := time.NewTicker(r.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			var points []*reassembledPoint
+			r.mu.Lock()
+			if !r.closed {
+				points = r.takeCollected(false)
+			}
+			r.mu.Unlock()
+			r.send(points)
+		case <-ctx.Done():
+			r.abort()
+			return
+		}
+	}
+}
+
+// abort drops the datapoints being sent, and any sent afterward.
+func (r *reassembly) abort() {
+	r.abortOnce.Do(func() { close(r.done) })
+}
+
+// close sends every datapoint being reassembled, and translates the series of further write
+// requests on their own. It returns once every datapoint has been sent, or aborts the
+// reassembly once the context is done.
+func (r *reassembly) close(ctx context.Context) {
+	var points []*reassembledPoint
+	r.mu.Lock()
+	if !r.closed {
+		points = r.takeCollected(true)
+		r.closed = true
+	}
+	r.mu.Unlock()
+	sent := make(chan struct{})
+	go func() {
+		r.send(points)
+		r.sending.Wait()
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-ctx.Done():
+		r.abort()
+		<-sent
+	}
+}
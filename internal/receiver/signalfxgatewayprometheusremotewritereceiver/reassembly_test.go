@@ -0,0 +1,269 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newTestReassembly() (*prometheusRemoteOtelParser, *time.Time, chan pmetric.Metrics) {
+	mc := make(chan pmetric.Metrics, 10)
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	parser.reassembly = newReassembly(ReassemblyConfig{Window: 5 * time.Second, Summaries: true, Histograms: true}, parser, mc)
+	now := jan20
+	parser.reassembly.now = func() time.Time { return now }
+	return parser, &now, mc
+}
+
+func summarySeries(name, pod string, value float64, extraLabels ...prompb.Label) prompb.TimeSeries {
+	labels := append([]prompb.Label{{Name: "__name__", Value: name}, {Name: "pod", Value: pod}}, extraLabels...)
+	return prompb.TimeSeries{Labels: labels, Samples: []prompb.Sample{{Value: value, Timestamp: jan20.UnixMilli()}}}
+}
+
+func metricNames(md pmetric.Metrics) []string {
+	var names []string
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		names = append(names, metrics.At(i).Name())
+	}
+	return names
+}
+
+func TestReassembleSummaries(t *testing.T) {
+	parser, now, mc := newTestReassembly()
+
+	// the series of a summary may be sent in different write requests
+	md, err := parser.fromWriteRequest(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			summarySeries("rpc_duration_seconds", "api-0", 0.25, prompb.Label{Name: "quantile", Value: "0.99"}),
+			summarySeries("rpc_duration_seconds", "api-0", 0.1, prompb.Label{Name: "quantile", Value: "0.5"}),
+			summarySeries("rpc_duration_seconds", "api-1", math.NaN(), prompb.Label{Name: "quantile", Value: "0.5"}),
+			summarySeries("temperature", "api-0", 21),
+		},
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "rpc_duration_seconds", Type: prompb.MetricMetadata_SUMMARY, Help: "RPC latency", Unit: "seconds"},
+		},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"temperature", "prometheus.invalid_requests", "prometheus.total_NAN_samples", "prometheus.total_bad_datapoints"}, metricNames(md))
+	assert.EqualValues(t, 1, parser.totalNans.Load())
+
+	md, err = parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("rpc_duration_seconds_sum", "api-0", 12.5),
+		summarySeries("rpc_duration_seconds_count", "api-0", 100),
+		summarySeries("rpc_duration_seconds_sum", "api-1", 0),
+		summarySeries("rpc_duration_seconds_count", "api-1", 0),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prometheus.invalid_requests", "prometheus.total_NAN_samples", "prometheus.total_bad_datapoints"}, metricNames(md))
+
+	// datapoints are only sent once collected for a whole window
+	parser.reassembly.mu.Lock()
+	assert.Nil(t, parser.reassembly.takeCollected(false))
+	*now = now.Add(5 * time.Second)
+	points := parser.reassembly.takeCollected(false)
+	parser.reassembly.mu.Unlock()
+	require.Len(t, points, 2)
+	parser.reassembly.send(points)
+	require.Len(t, mc, 1)
+
+	metrics := (<-mc).ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metrics.Len())
+	summary := metrics.At(0)
+	assert.Equal(t, "rpc_duration_seconds", summary.Name())
+	assert.Equal(t, "RPC latency", summary.Description())
+	assert.Equal(t, "seconds", summary.Unit())
+	require.Equal(t, pmetric.MetricTypeSummary, summary.Type())
+	dps := summary.Summary().DataPoints()
+	require.Equal(t, 2, dps.Len())
+
+	dp := dps.At(0)
+	assert.Equal(t, map[string]any{"pod": "api-0"}, dp.Attributes().AsRaw())
+	assert.Equal(t, prometheusToOtelTimestamp(jan20.UnixMilli()), dp.Timestamp())
+	assert.Equal(t, 12.5, dp.Sum())
+	assert.EqualValues(t, 100, dp.Count())
+	require.Equal(t, 2, dp.QuantileValues().Len())
+	assert.Equal(t, 0.5, dp.QuantileValues().At(0).Quantile())
+	assert.Equal(t, 0.1, dp.QuantileValues().At(0).Value())
+	assert.Equal(t, 0.99, dp.QuantileValues().At(1).Quantile())
+	assert.Equal(t, 0.25, dp.QuantileValues().At(1).Value())
+
+	dp = dps.At(1)
+	assert.Equal(t, map[string]any{"pod": "api-1"}, dp.Attributes().AsRaw())
+	assert.Zero(t, dp.Count())
+	assert.Zero(t, dp.QuantileValues().Len())
+}
+
+func TestReassembleSummariesWithoutMetadata(t *testing.T) {
+	parser, _, mc := newTestReassembly()
+
+	// _sum and _count series are only known to be of a summary once a quantile series was received
+	md, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("gc_duration_seconds_count", "api-0", 5),
+		summarySeries("gc_duration_seconds", "api-0", 0.01, prompb.Label{Name: "quantile", Value: "0.5"}),
+		summarySeries("http_request_duration_seconds_count", "api-0", 3),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gc_duration_seconds_count", "http_request_duration_seconds_count", "prometheus.invalid_requests", "prometheus.total_NAN_samples", "prometheus.total_bad_datapoints"}, metricNames(md))
+
+	md, err = parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("gc_duration_seconds_count", "api-0", 5),
+		summarySeries("gc_duration_seconds_sum", "api-0", 0.05),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prometheus.invalid_requests", "prometheus.total_NAN_samples", "prometheus.total_bad_datapoints"}, metricNames(md))
+
+	parser.reassembly.close(context.Background())
+	require.Len(t, mc, 1)
+	dps := (<-mc).ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Summary().DataPoints()
+	require.Equal(t, 1, dps.Len())
+	assert.Equal(t, 0.05, dps.At(0).Sum())
+	assert.EqualValues(t, 5, dps.At(0).Count())
+	assert.Equal(t, 1, dps.At(0).QuantileValues().Len())
+
+	// series of further write requests are translated on their own
+	md, err = parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("gc_duration_seconds", "api-0", 0.01, prompb.Label{Name: "quantile", Value: "0.5"}),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "gc_duration_seconds", metricNames(md)[0])
+}
+
+func TestReassembleHistograms(t *testing.T) {
+	parser, _, mc := newTestReassembly()
+
+	bucket := func(pod, le string, value float64) prompb.TimeSeries {
+		return summarySeries("http_request_duration_seconds_bucket", pod, value, prompb.Label{Name: "le", Value: le})
+	}
+	withExemplar := bucket("api-0", "0.5", 7)
+	withExemplar.Exemplars = []prompb.Exemplar{{
+		Labels:    []prompb.Label{{Name: "trace_id", Value: "0102030405060708090a0b0c0d0e0f10"}},
+		Value:     0.3,
+		Timestamp: jan20.UnixMilli(),
+	}}
+	md, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		bucket("api-0", "+Inf", 10),
+		withExemplar,
+		bucket("api-0", "0.1", 4),
+		summarySeries("http_request_duration_seconds_sum", "api-0", 2.5),
+		bucket("api-1", "0.1", 1),
+		bucket("api-1", "+Inf", 3),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prometheus.invalid_requests", "prometheus.total_NAN_samples", "prometheus.total_bad_datapoints"}, metricNames(md))
+
+	md, err = parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("http_request_duration_seconds_count", "api-0", 10),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prometheus.invalid_requests", "prometheus.total_NAN_samples", "prometheus.total_bad_datapoints"}, metricNames(md))
+
+	parser.reassembly.close(context.Background())
+	require.Len(t, mc, 1)
+	metrics := (<-mc).ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metrics.Len())
+	histogram := metrics.At(0)
+	assert.Equal(t, "http_request_duration_seconds", histogram.Name())
+	require.Equal(t, pmetric.MetricTypeHistogram, histogram.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, histogram.Histogram().AggregationTemporality())
+	dps := histogram.Histogram().DataPoints()
+	require.Equal(t, 2, dps.Len())
+
+	dp := dps.At(0)
+	assert.Equal(t, map[string]any{"pod": "api-0"}, dp.Attributes().AsRaw())
+	assert.Equal(t, prometheusToOtelTimestamp(jan20.UnixMilli()), dp.Timestamp())
+	assert.True(t, dp.HasSum())
+	assert.Equal(t, 2.5, dp.Sum())
+	assert.EqualValues(t, 10, dp.Count())
+	assert.Equal(t, []float64{0.1, 0.5}, dp.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{4, 3, 3}, dp.BucketCounts().AsRaw())
+	require.Equal(t, 1, dp.Exemplars().Len())
+	assert.Equal(t, 0.3, dp.Exemplars().At(0).DoubleValue())
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", dp.Exemplars().At(0).TraceID().String())
+
+	// without a _count series, the count is the one of the +Inf bucket
+	dp = dps.At(1)
+	assert.Equal(t, map[string]any{"pod": "api-1"}, dp.Attributes().AsRaw())
+	assert.False(t, dp.HasSum())
+	assert.EqualValues(t, 3, dp.Count())
+	assert.Equal(t, []float64{0.1}, dp.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{1, 2}, dp.BucketCounts().AsRaw())
+}
+
+func TestReassembleHistogramsDisabled(t *testing.T) {
+	parser, _, _ := newTestReassembly()
+	parser.reassembly.histograms = false
+
+	md, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("http_request_duration_seconds_bucket", "api-0", 4, prompb.Label{Name: "le", Value: "0.1"}),
+		summarySeries("http_request_duration_seconds_count", "api-0", 10),
+	}}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"http_request_duration_seconds_bucket", "http_request_duration_seconds_count", "prometheus.invalid_requests", "prometheus.total_NAN_samples", "prometheus.total_bad_datapoints"}, metricNames(md))
+}
+
+func TestReassembleStaleMarkers(t *testing.T) {
+	parser, _, mc := newTestReassembly()
+	parser.staleMarkers = StaleMarkersNoRecordedValue
+
+	staleNaN := math.Float64frombits(value.StaleNaN)
+	_, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("http_request_duration_seconds_bucket", "api-0", staleNaN, prompb.Label{Name: "le", Value: "0.1"}),
+		summarySeries("http_request_duration_seconds_bucket", "api-0", staleNaN, prompb.Label{Name: "le", Value: "+Inf"}),
+		summarySeries("http_request_duration_seconds_count", "api-0", staleNaN),
+	}}, nil)
+	require.NoError(t, err)
+	assert.Zero(t, parser.totalNans.Load())
+
+	parser.reassembly.close(context.Background())
+	require.Len(t, mc, 1)
+	dps := (<-mc).ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints()
+	require.Equal(t, 1, dps.Len())
+	assert.True(t, dps.At(0).Flags().NoRecordedValue())
+	assert.Zero(t, dps.At(0).BucketCounts().Len())
+	assert.Equal(t, map[string]any{"pod": "api-0"}, dps.At(0).Attributes().AsRaw())
+}
+
+func TestReassemblyRun(t *testing.T) {
+	parser, now, mc := newTestReassembly()
+	parser.reassembly.window = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go parser.reassembly.run(ctx)
+
+	_, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		summarySeries("rpc_duration_seconds", "api-0", 0.25, prompb.Label{Name: "quantile", Value: "0.99"}),
+	}}, nil)
+	require.NoError(t, err)
+	parser.reassembly.mu.Lock()
+	*now = now.Add(time.Second)
+	parser.reassembly.mu.Unlock()
+
+	select {
+	case md := <-mc:
+		assert.Equal(t, 1, md.DataPointCount())
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the reassembled summary wasn't sent")
+	}
+}
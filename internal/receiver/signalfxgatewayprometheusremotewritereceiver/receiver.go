@@ -16,14 +16,17 @@ package signalfxgatewayprometheusremotewritereceiver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/receiver"
 
+	"github.com/signalfx/splunk-otel-collector/internal/extension/seriescacheextension"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal/metadata"
 )
 
@@ -34,9 +37,15 @@ type prometheusRemoteWriteReceiver struct {
 	server       *prometheusRemoteWriteServer
 	reporter     reporter
 	nextConsumer consumer.Metrics
-	cancel       context.CancelFunc
-	config       *Config
-	settings     receiver.Settings
+	// resourceDetection, if enabled, merges the detected resource attributes into the
+	// metrics before passing them to the next consumer.
+	resourceDetection processor.Metrics
+	// targetInfo, if enabled, keeps the target_info labels folded into the resource attributes
+	// of the series of each target.
+	targetInfo *targetInfoCache
+	cancel     context.CancelFunc
+	config     *Config
+	settings   receiver.Settings
 }
 
 func newReceiver(
@@ -63,15 +72,82 @@ func (receiver *prometheusRemoteWriteReceiver) Start(ctx context.Context, host c
 	metricsChannel := make(chan pmetric.Metrics, receiver.config.BufferSize)
 	cfg := &serverConfig{
 		ServerConfig:      receiver.config.ServerConfig,
+		Limits:            receiver.config.Limits,
 		Path:              receiver.config.ListenPath,
 		Mc:                metricsChannel,
 		TelemetrySettings: receiver.settings.TelemetrySettings,
 		Reporter:          receiver.reporter,
 		Host:              host,
-		Parser:            newPrometheusRemoteOtelParser(),
+		Parser:            newPrometheusRemoteOtelParser(receiver.config.AttributeLimits),
+		TLSMetadata:       receiver.config.TLSMetadata.Enabled,
+	}
+	if receiver.config.SeriesCache != nil {
+		store, err := receiver.seriesCacheNamespace(host, "metadata")
+		if err != nil {
+			return err
+		}
+		cfg.Parser.metadata.store = store
+	}
+	if receiver.config.StartTimestamps.Enabled {
+		cfg.Parser.startTimestamps = newStartTimestampCache(receiver.config.StartTimestamps)
+		if receiver.config.SeriesCache != nil {
+			store, err := receiver.seriesCacheNamespace(host, "start_timestamps")
+			if err != nil {
+				return err
+			}
+			cfg.Parser.startTimestamps.store = store
+		}
+	}
+	receiver.targetInfo = nil
+	if receiver.config.TargetInfo.Enabled {
+		receiver.targetInfo = newTargetInfoCache(receiver.config.TargetInfo)
+	}
+	typeRules, err := newTypeRules(receiver.config.TypeRules)
+	if err != nil {
+		return err
+	}
+	cfg.Parser.typeRules = typeRules
+	if cfg.MetricFilter, err = newMetricFilter(receiver.config.IncludeMetrics, receiver.config.ExcludeMetrics); err != nil {
+		return err
+	}
+	if cfg.RelabelConfigs, err = newRelabelConfigs(receiver.config.RelabelConfigs); err != nil {
+		return err
+	}
+	cfg.Parser.staleMarkers = receiver.config.StaleMarkers
+	if receiver.config.IngestStats.Enabled {
+		cfg.IngestStats = newIngestStats(receiver.config.IngestStats)
+		cfg.StatsPath = receiver.config.IngestStats.Path
+	}
+	if receiver.config.Compliance.Enabled {
+		cfg.Compliance = newCompliance(receiver.config.Compliance)
+		cfg.CompliancePath = receiver.config.Compliance.Path
+	}
+	if receiver.config.JSONWrite.Enabled {
+		cfg.JSONWritePath = receiver.config.JSONWrite.Path
+	}
+	if receiver.config.ColumnarBatching.Enabled {
+		cfg.Batch = newColumnarBatch(receiver.config.ColumnarBatching, cfg.Parser, metricsChannel)
+	}
+	if receiver.config.Reassembly.Summaries || receiver.config.Reassembly.Histograms {
+		cfg.Reassembly = newReassembly(receiver.config.Reassembly, cfg.Parser, metricsChannel)
+		cfg.Parser.reassembly = cfg.Reassembly
+	}
+	if receiver.config.SenderHeartbeat.Enabled {
+		h, err := newSenderHeartbeats(receiver.config.SenderHeartbeat, receiver.settings.TelemetrySettings)
+		if err != nil {
+			return err
+		}
+		cfg.Heartbeats = h
+	}
+	if receiver.config.Quotas.Enabled {
+		q, err := newQuotas(receiver.config.Quotas, receiver.settings.TelemetrySettings)
+		if err != nil {
+			return err
+		}
+		cfg.Quotas = q
 	}
 	if receiver.server != nil {
-		err := receiver.server.close()
+		err := receiver.server.close(ctx)
 		if err != nil {
 			return err
 		}
@@ -79,6 +155,18 @@ func (receiver *prometheusRemoteWriteReceiver) Start(ctx context.Context, host c
 	if receiver.cancel != nil {
 		receiver.cancel()
 	}
+	if err := receiver.startResourceDetection(ctx, host); err != nil {
+		return err
+	}
+	var next consumer.Metrics = receiver.nextConsumer
+	if receiver.resourceDetection != nil {
+		next = receiver.resourceDetection
+	}
+	if receiver.config.ReportConsumerErrors {
+		cfg.Consume = func(ctx context.Context, metrics pmetric.Metrics) error {
+			return receiver.flush(receiver.reporter.StartMetricsOp(ctx), next, metrics)
+		}
+	}
 	ctx, receiver.cancel = context.WithCancel(ctx)
 	server, err := newPrometheusRemoteWriteServer(ctx, cfg)
 	if err != nil {
@@ -87,11 +175,31 @@ func (receiver *prometheusRemoteWriteReceiver) Start(ctx context.Context, host c
 	receiver.server = server
 
 	go receiver.startServer(ctx, host)
-	go receiver.manageServerLifecycle(ctx, metricsChannel)
+	if cfg.Batch != nil {
+		go cfg.Batch.run(ctx)
+	}
+	if cfg.Reassembly != nil {
+		go cfg.Reassembly.run(ctx)
+	}
+	go receiver.manageServerLifecycle(ctx, metricsChannel, next)
 
 	return nil
 }
 
+// seriesCacheNamespace returns the namespace of the receiver with the name in the configured
+// series_cache extension.
+func (receiver *prometheusRemoteWriteReceiver) seriesCacheNamespace(host component.Host, name string) (seriescacheextension.Cache, error) {
+	ext, ok := host.GetExtensions()[*receiver.config.SeriesCache]
+	if !ok {
+		return nil, fmt.Errorf("series_cache extension %q not found", receiver.config.SeriesCache)
+	}
+	seriesCache, ok := ext.(seriescacheextension.SeriesCache)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a series_cache extension", receiver.config.SeriesCache)
+	}
+	return seriesCache.Namespace(receiver.settings.ID.String() + "/" + name), nil
+}
+
 func (receiver *prometheusRemoteWriteReceiver) startServer(ctx context.Context, host component.Host) {
 	prometheusRemoteWriteServer := receiver.server
 	if prometheusRemoteWriteServer == nil {
@@ -104,7 +212,7 @@ func (receiver *prometheusRemoteWriteReceiver) startServer(ctx context.Context,
 	}
 }
 
-func (receiver *prometheusRemoteWriteReceiver) manageServerLifecycle(ctx context.Context, metricsChannel <-chan pmetric.Metrics) {
+func (receiver *prometheusRemoteWriteReceiver) manageServerLifecycle(ctx context.Context, metricsChannel <-chan pmetric.Metrics, next consumer.Metrics) {
 	for {
 		select {
 		case metrics, stillOpen := <-metricsChannel:
@@ -112,10 +220,10 @@ func (receiver *prometheusRemoteWriteReceiver) manageServerLifecycle(ctx context
 				return
 			}
 			metricContext := receiver.reporter.StartMetricsOp(ctx)
-			err := receiver.flush(metricContext, metrics)
+			err := receiver.flush(metricContext, next, metrics)
 			if err != nil {
+				// keep consuming, a failing consumer mustn't stall all subsequent write requests
 				receiver.reporter.OnError(metricContext, "flush_error", err)
-				return
 			}
 		case <-ctx.Done():
 			return
@@ -123,20 +231,56 @@ func (receiver *prometheusRemoteWriteReceiver) manageServerLifecycle(ctx context
 	}
 }
 
+// startResourceDetection replaces any previously started resource detection with a new one,
+// detecting the resource attributes again.
+func (receiver *prometheusRemoteWriteReceiver) startResourceDetection(ctx context.Context, host component.Host) error {
+	if receiver.resourceDetection != nil {
+		if err := receiver.resourceDetection.Shutdown(ctx); err != nil {
+			return err
+		}
+		receiver.resourceDetection = nil
+	}
+	if !receiver.config.ResourceDetection.Enabled {
+		return nil
+	}
+	resourceDetection, err := newResourceDetection(ctx, receiver.config.ResourceDetection, receiver.settings, receiver.nextConsumer)
+	if err != nil {
+		return err
+	}
+	if err = resourceDetection.Start(ctx, host); err != nil {
+		return fmt.Errorf("failed detecting resource attributes: %w", err)
+	}
+	receiver.resourceDetection = resourceDetection
+	return nil
+}
+
 // Shutdown stops the PrometheusSimpleRemoteWrite receiver.
-func (receiver *prometheusRemoteWriteReceiver) Shutdown(context.Context) error {
+func (receiver *prometheusRemoteWriteReceiver) Shutdown(ctx context.Context) error {
 	if receiver.cancel == nil {
 		return nil
 	}
 	defer receiver.cancel()
+	var err error
 	if receiver.server != nil {
-		return receiver.server.close()
+		err = receiver.server.close(ctx)
 	}
-	return nil
+	if receiver.resourceDetection != nil {
+		err = errors.Join(err, receiver.resourceDetection.Shutdown(ctx))
+	}
+	return err
 }
 
-func (receiver *prometheusRemoteWriteReceiver) flush(ctx context.Context, metrics pmetric.Metrics) error {
-	err := receiver.nextConsumer.ConsumeMetrics(ctx, metrics)
+func (receiver *prometheusRemoteWriteReceiver) flush(ctx context.Context, next consumer.Metrics, metrics pmetric.Metrics) error {
+	if receiver.targetInfo != nil {
+		receiver.targetInfo.record(metrics)
+	}
+	if receiver.config.PromoteJobInstance || receiver.targetInfo != nil {
+		metrics = groupByJobInstance(metrics, receiver.config.PromoteJobInstance, receiver.targetInfo)
+	}
+	if len(receiver.config.ExtraAttributes) > 0 {
+		putExtraAttributes(metrics, receiver.config.ExtraAttributes)
+	}
+	err := next.ConsumeMetrics(ctx, metrics)
 	receiver.reporter.OnMetricsProcessed(ctx, metrics.DataPointCount(), err)
 	return err
 }
@@ -0,0 +1,117 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// relabelActions are the supported relabel actions.
+var relabelActions = map[relabel.Action]struct{}{
+	relabel.Replace:   {},
+	relabel.Keep:      {},
+	relabel.Drop:      {},
+	relabel.KeepEqual: {},
+	relabel.DropEqual: {},
+	relabel.HashMod:   {},
+	relabel.LabelMap:  {},
+	relabel.LabelDrop: {},
+	relabel.LabelKeep: {},
+	relabel.Lowercase: {},
+	relabel.Uppercase: {},
+}
+
+func newRelabelConfigs(configs []RelabelConfig) ([]*relabel.Config, error) {
+	relabelConfigs := make([]*relabel.Config, 0, len(configs))
+	for i, config := range configs {
+		relabelConfig, err := config.relabelConfig()
+		if err != nil {
+			return nil, fmt.Errorf("relabel_configs[%d] is invalid: %w", i, err)
+		}
+		relabelConfigs = append(relabelConfigs, relabelConfig)
+	}
+	return relabelConfigs, nil
+}
+
+// relabelConfig returns the Prometheus relabel config, with the Prometheus defaults of the unset fields.
+func (c RelabelConfig) relabelConfig() (*relabel.Config, error) {
+	config := relabel.DefaultRelabelConfig
+	for _, sourceLabel := range c.SourceLabels {
+		config.SourceLabels = append(config.SourceLabels, model.LabelName(sourceLabel))
+	}
+	if c.Separator != "" {
+		config.Separator = c.Separator
+	}
+	if c.Regex != "" {
+		regex, err := relabel.NewRegexp(c.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("regex is invalid: %w", err)
+		}
+		config.Regex = regex
+	}
+	config.Modulus = c.Modulus
+	config.TargetLabel = c.TargetLabel
+	if c.Replacement != nil {
+		config.Replacement = *c.Replacement
+	}
+	if c.Action != "" {
+		config.Action = relabel.Action(strings.ToLower(c.Action))
+	}
+	if _, ok := relabelActions[config.Action]; !ok {
+		return nil, fmt.Errorf("unknown action %q", c.Action)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// relabelWriteRequest returns the write request with the relabel configs applied to the labels of its
+// series, without the series they drop, and the created timestamps of the kept series. The write request
+// itself isn't modified.
+func relabelWriteRequest(req *prompb.WriteRequest, createdTimestamps []int64, configs []*relabel.Config) (*prompb.WriteRequest, []int64) {
+	relabeled := &prompb.WriteRequest{Metadata: req.Metadata, Timeseries: make([]prompb.TimeSeries, 0, len(req.Timeseries))}
+	var relabeledCreatedTimestamps []int64
+	if createdTimestamps != nil {
+		relabeledCreatedTimestamps = make([]int64, 0, len(createdTimestamps))
+	}
+	builder := labels.NewScratchBuilder(0)
+	for i, ts := range req.Timeseries {
+		builder.Reset()
+		for _, label := range ts.Labels {
+			builder.Add(label.Name, label.Value)
+		}
+		builder.Sort()
+		lbls, keep := relabel.Process(builder.Labels(), configs...)
+		if !keep {
+			continue
+		}
+		ts.Labels = make([]prompb.Label, 0, lbls.Len())
+		lbls.Range(func(l labels.Label) {
+			ts.Labels = append(ts.Labels, prompb.Label{Name: l.Name, Value: l.Value})
+		})
+		relabeled.Timeseries = append(relabeled.Timeseries, ts)
+		if createdTimestamps != nil {
+			relabeledCreatedTimestamps = append(relabeledCreatedTimestamps, createdTimestamps[i])
+		}
+	}
+	return relabeled, relabeledCreatedTimestamps
+}
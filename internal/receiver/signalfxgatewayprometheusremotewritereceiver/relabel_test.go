@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelabelWriteRequest(t *testing.T) {
+	empty := ""
+	configs, err := newRelabelConfigs([]RelabelConfig{
+		{SourceLabels: []string{"__name__"}, Regex: "go_.*", Action: "drop"},
+		{Regex: "pod_uid", Action: "labeldrop"},
+		{SourceLabels: []string{"instance"}, Regex: "([^:]+):.*", TargetLabel: "host"},
+		{Regex: "k8s_(.+)", Action: "labelmap"},
+		{SourceLabels: []string{"tier"}, Regex: "canary", TargetLabel: "tier", Replacement: &empty},
+	})
+	require.NoError(t, err)
+	req := &prompb.WriteRequest{
+		Metadata: []prompb.MetricMetadata{{MetricFamilyName: "http_requests_total", Type: prompb.MetricMetadata_COUNTER}},
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "go_goroutines"}, {Name: "instance", Value: "api-0:8080"}},
+				Samples: []prompb.Sample{{Value: 12, Timestamp: 1000}},
+			},
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "http_requests_total"},
+					{Name: "instance", Value: "api-0:8080"},
+					{Name: "k8s_namespace", Value: "shop"},
+					{Name: "pod_uid", Value: "8f2c"},
+					{Name: "tier", Value: "canary"},
+				},
+				Samples: []prompb.Sample{{Value: 7, Timestamp: 1000}},
+			},
+		},
+	}
+
+	relabeled, createdTimestamps := relabelWriteRequest(req, []int64{500, 600}, configs)
+	assert.Equal(t, []int64{600}, createdTimestamps)
+	assert.Equal(t, req.Metadata, relabeled.Metadata)
+	require.Len(t, relabeled.Timeseries, 1)
+	assert.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "http_requests_total"},
+		{Name: "host", Value: "api-0"},
+		{Name: "instance", Value: "api-0:8080"},
+		{Name: "k8s_namespace", Value: "shop"},
+		{Name: "namespace", Value: "shop"},
+	}, relabeled.Timeseries[0].Labels)
+	assert.Equal(t, req.Timeseries[1].Samples, relabeled.Timeseries[0].Samples)
+	assert.Len(t, req.Timeseries, 2, "the write request must not be modified")
+	assert.Len(t, req.Timeseries[1].Labels, 5, "the write request must not be modified")
+
+	relabeled, createdTimestamps = relabelWriteRequest(req, nil, configs)
+	assert.Nil(t, createdTimestamps)
+	assert.Len(t, relabeled.Timeseries, 1)
+}
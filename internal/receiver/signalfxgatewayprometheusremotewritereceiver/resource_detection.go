@@ -0,0 +1,52 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+// newResourceDetection returns a resourcedetection processor merging the detected resource
+// attributes into the metrics before passing them to the next consumer. The detection runs
+// when the processor is started.
+func newResourceDetection(ctx context.Context, cfg ResourceDetectionConfig, settings receiver.Settings, next consumer.Metrics) (processor.Metrics, error) {
+	factory := resourcedetectionprocessor.NewFactory()
+	processorConfig := factory.CreateDefaultConfig()
+	detectorsConfig := confmap.NewFromStringMap(map[string]any{
+		"detectors": cfg.Detectors,
+		"timeout":   cfg.Timeout,
+		"override":  cfg.Override,
+	})
+	if err := detectorsConfig.Unmarshal(processorConfig); err != nil {
+		return nil, fmt.Errorf("invalid resource_detection config: %w", err)
+	}
+	if err := component.ValidateConfig(processorConfig); err != nil {
+		return nil, fmt.Errorf("invalid resource_detection config: %w", err)
+	}
+	processorSettings := processor.Settings{
+		ID:                component.NewIDWithName(factory.Type(), settings.ID.Name()),
+		TelemetrySettings: settings.TelemetrySettings,
+		BuildInfo:         settings.BuildInfo,
+	}
+	return factory.CreateMetrics(ctx, processorSettings, processorConfig, next)
+}
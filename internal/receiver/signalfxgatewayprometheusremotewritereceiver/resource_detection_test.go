@@ -0,0 +1,64 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestResourceDetectionMergesDetectedAttributes(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "host.name=detected-host,cloud.provider=aws")
+
+	for _, tt := range []struct {
+		name             string
+		expectedHostName string
+		override         bool
+	}{
+		{name: "merge", expectedHostName: "sidecar-host"},
+		{name: "override", override: true, expectedHostName: "detected-host"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &consumertest.MetricsSink{}
+			cfg := ResourceDetectionConfig{Enabled: true, Detectors: []string{"env"}, Timeout: time.Second, Override: tt.override}
+			p, err := newResourceDetection(context.Background(), cfg, receivertest.NewNopSettings(), sink)
+			require.NoError(t, err)
+			require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+			defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+
+			md := pmetric.NewMetrics()
+			md.ResourceMetrics().AppendEmpty().Resource().Attributes().PutStr("host.name", "sidecar-host")
+			require.NoError(t, p.ConsumeMetrics(context.Background(), md))
+
+			require.Len(t, sink.AllMetrics(), 1)
+			attrs := sink.AllMetrics()[0].ResourceMetrics().At(0).Resource().Attributes().AsRaw()
+			assert.Equal(t, map[string]any{"host.name": tt.expectedHostName, "cloud.provider": "aws"}, attrs)
+		})
+	}
+}
+
+func TestResourceDetectionInvalidDetector(t *testing.T) {
+	cfg := ResourceDetectionConfig{Enabled: true, Detectors: []string{"unknown"}, Timeout: time.Second}
+	_, err := newResourceDetection(context.Background(), cfg, receivertest.NewNopSettings(), consumertest.NewNop())
+	require.ErrorContains(t, err, "unknown")
+}
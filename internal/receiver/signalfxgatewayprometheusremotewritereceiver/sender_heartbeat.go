@@ -0,0 +1,129 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxgatewayprometheusremotewritereceiver/internal/metadata"
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
+)
+
+// senderState is whether a sender is up and the time of its last write request.
+type senderState struct {
+	lastWrite time.Time
+	sender    string
+	up        bool
+}
+
+// senderHeartbeats tracks the last write request of each sender and reports it with the
+// prw.sender.up and prw.sender.last_write internal metrics, so senders that silently stop
+// remote writing can be alerted on.
+type senderHeartbeats struct {
+	now          func() time.Time
+	registration metric.Registration
+	lastWrite    map[string]time.Time
+	header       string
+	staleAfter   time.Duration
+	expireAfter  time.Duration
+	mu           sync.Mutex
+}
+
+func newSenderHeartbeats(cfg SenderHeartbeatConfig, set component.TelemetrySettings) (*senderHeartbeats, error) {
+	h := &senderHeartbeats{
+		now:         time.Now,
+		lastWrite:   map[string]time.Time{},
+		header:      cfg.SenderHeader,
+		staleAfter:  cfg.StaleAfter,
+		expireAfter: cfg.ExpireAfter,
+	}
+
+	meterProvider := set.MeterProvider
+	if set.LeveledMeterProvider != nil {
+		meterProvider = set.LeveledMeterProvider(configtelemetry.LevelBasic)
+	}
+	meter := meterProvider.Meter(metadata.ScopeName)
+	up, err := meter.Int64ObservableGauge(
+		"prw.sender.up",
+		metric.WithDescription("Whether a sender wrote within the stale_after duration (1) or not (0)."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	lastWrite, err := meter.Int64ObservableGauge(
+		"prw.sender.last_write",
+		metric.WithDescription("Unix timestamp of the last write request of a sender."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	h.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, state := range h.observe() {
+			attrs := metric.WithAttributes(attribute.String("sender", state.sender))
+			var value int64
+			if state.up {
+				value = 1
+			}
+			o.ObserveInt64(up, value, attrs)
+			o.ObserveInt64(lastWrite, state.lastWrite.Unix(), attrs)
+		}
+		return nil
+	}, up, lastWrite)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// record records a write request of the request's sender.
+func (h *senderHeartbeats) record(r *http.Request) {
+	sender := transport.Sender(r, h.header)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastWrite[sender] = h.now()
+}
+
+// observe returns the state of the tracked senders sorted by sender, forgetting the ones
+// that haven't written within the expire_after duration.
+func (h *senderHeartbeats) observe() []senderState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	states := make([]senderState, 0, len(h.lastWrite))
+	for sender, lastWrite := range h.lastWrite {
+		silence := now.Sub(lastWrite)
+		if silence >= h.expireAfter {
+			delete(h.lastWrite, sender)
+			continue
+		}
+		states = append(states, senderState{sender: sender, lastWrite: lastWrite, up: silence < h.staleAfter})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].sender < states[j].sender })
+	return states
+}
+
+func (h *senderHeartbeats) close() error {
+	return h.registration.Unregister()
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/common/testclock"
+)
+
+func newTestSenderHeartbeats(t *testing.T) (*senderHeartbeats, *time.Time) {
+	h, err := newSenderHeartbeats(SenderHeartbeatConfig{
+		Enabled:      true,
+		SenderHeader: "X-Sender",
+		StaleAfter:   5 * time.Minute,
+		ExpireAfter:  time.Hour,
+	}, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, h.close()) })
+	var now *time.Time
+	h.now, now = testclock.New(jan20)
+	return h, now
+}
+
+func TestSenderHeartbeatsObserve(t *testing.T) {
+	h, now := newTestSenderHeartbeats(t)
+	for _, sender := range []string{"b", "a"} {
+		r := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+		r.Header.Set("X-Sender", sender)
+		h.record(r)
+		*now = now.Add(3 * time.Minute)
+	}
+
+	assert.Equal(t, []senderState{
+		{sender: "a", lastWrite: jan20.Add(3 * time.Minute), up: true},
+		{sender: "b", lastWrite: jan20, up: false},
+	}, h.observe())
+
+	// silent senders are forgotten after expire_after
+	*now = jan20.Add(time.Hour)
+	assert.Equal(t, []senderState{
+		{sender: "a", lastWrite: jan20.Add(3 * time.Minute), up: false},
+	}, h.observe())
+	*now = jan20.Add(time.Hour + 3*time.Minute)
+	assert.Empty(t, h.observe())
+	assert.Empty(t, h.lastWrite)
+}
@@ -24,10 +24,14 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/prompb"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/transport"
 )
 
 type prometheusRemoteWriteServer struct {
@@ -41,16 +45,51 @@ type serverConfig struct {
 	component.TelemetrySettings
 	Reporter reporter
 	component.Host
-	Mc     chan<- pmetric.Metrics
-	Parser *prometheusRemoteOtelParser
-	Path   string
+	Mc             chan<- pmetric.Metrics
+	Consume        func(context.Context, pmetric.Metrics) error
+	Parser         *prometheusRemoteOtelParser
+	MetricFilter   *metricFilter
+	RelabelConfigs []*relabel.Config
+	IngestStats    *ingestStats
+	Quotas         *quotas
+	Compliance     *compliance
+	Batch          *columnarBatch
+	Reassembly     *reassembly
+	Heartbeats     *senderHeartbeats
+	Path           string
+	StatsPath      string
+	CompliancePath string
+	JSONWritePath  string
 	confighttp.ServerConfig
+	Limits      transport.Limits
+	TLSMetadata bool
 }
 
 func newPrometheusRemoteWriteServer(ctx context.Context, config *serverConfig) (*prometheusRemoteWriteServer, error) {
+	mw, err := transport.NewMiddleware(config.TelemetrySettings, "prometheus_remote_write", config.Limits)
+	if err != nil {
+		return nil, err
+	}
 	mx := mux.NewRouter()
 	handler := newHandler(config.Parser, config, config.Mc)
-	mx.HandleFunc(config.Path, handler)
+	mx.Handle(config.Path, mw.Wrap(config.Path, handler))
+	if config.Quotas != nil {
+		for _, path := range config.Quotas.writePaths() {
+			if path != config.Path {
+				mx.Handle(path, mw.Wrap(path, handler))
+			}
+		}
+	}
+	if config.JSONWritePath != "" {
+		jsonHandler := newDecodingHandler(decodeJSONWriteRequest, config.Parser, config, config.Mc)
+		mx.Handle(config.JSONWritePath, mw.Wrap(config.JSONWritePath, jsonHandler))
+	}
+	if config.IngestStats != nil {
+		mx.Handle(config.StatsPath, mw.Wrap(config.StatsPath, http.HandlerFunc(config.IngestStats.handler)))
+	}
+	if config.Compliance != nil {
+		mx.Handle(config.CompliancePath, mw.Wrap(config.CompliancePath, http.HandlerFunc(config.Compliance.handler)))
+	}
 	mx.Host(config.ServerConfig.Endpoint)
 	server, err := config.ServerConfig.ToServer(ctx, config.Host, config.TelemetrySettings, mx,
 		// ensure we support the snappy Content-Encoding, but leave it to the prometheus remotewrite lib to decompress.
@@ -71,9 +110,19 @@ func newPrometheusRemoteWriteServer(ctx context.Context, config *serverConfig) (
 	return prwServer, nil
 }
 
-func (prw *prometheusRemoteWriteServer) close() error {
+func (prw *prometheusRemoteWriteServer) close(ctx context.Context) error {
 	defer prw.closeChannel.Do(func() { close(prw.Mc) })
-	return prw.Server.Close()
+	err := prw.Server.Close()
+	if prw.Batch != nil {
+		prw.Batch.close(ctx)
+	}
+	if prw.Reassembly != nil {
+		prw.Reassembly.close(ctx)
+	}
+	if prw.Heartbeats != nil {
+		err = errors.Join(err, prw.Heartbeats.close())
+	}
+	return err
 }
 
 func (prw *prometheusRemoteWriteServer) listenAndServe(ctx context.Context) error {
@@ -92,27 +141,133 @@ func (prw *prometheusRemoteWriteServer) listenAndServe(ctx context.Context) erro
 	return err
 }
 
+// newHandler returns a handler of remote write 1.0 and 2.0 requests, negotiated by their Content-Type.
 func newHandler(parser *prometheusRemoteOtelParser, sc *serverConfig, mc chan<- pmetric.Metrics) http.HandlerFunc {
+	return newWriteHandler(decodeRemoteWriteRequest, parser, sc, mc)
+}
+
+// newDecodingHandler returns a handler of the write requests decoded by decode.
+func newDecodingHandler(
+	decode func(io.Reader) (*prompb.WriteRequest, error),
+	parser *prometheusRemoteOtelParser,
+	sc *serverConfig,
+	mc chan<- pmetric.Metrics,
+) http.HandlerFunc {
+	return newWriteHandler(func(r *http.Request) (*writeRequest, error) {
+		req, err := decode(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &writeRequest{WriteRequest: req}, nil
+	}, parser, sc, mc)
+}
+
+// newWriteHandler returns a handler of the write requests decoded by decode.
+func newWriteHandler(
+	decode func(*http.Request) (*writeRequest, error),
+	parser *prometheusRemoteOtelParser,
+	sc *serverConfig,
+	mc chan<- pmetric.Metrics,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sc.Reporter.OnDebugf("Processing write request %s", r.RequestURI)
-		req, err := DecodeWriteRequest(r.Body)
+		wr, err := decode(r)
+		if errors.Is(err, errUnsupportedProto) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
 		if err != nil {
+			if sc.Compliance != nil {
+				sc.Compliance.recordUndecodable(r)
+			}
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		req, createdTimestamps := wr.WriteRequest, wr.createdTimestamps
+		if sc.Heartbeats != nil {
+			sc.Heartbeats.record(r)
+		}
+		if sc.Compliance != nil {
+			// in compliance report mode the data is only analyzed, never forwarded
+			sc.Compliance.analyze(r, req)
+			wr.writeStatus(w, http.StatusNoContent)
+			return
+		}
+		// the series dropped by the metric filter and relabeling are still reported written to
+		// remote write 2.0 senders
+		if sc.MetricFilter != nil {
+			req, createdTimestamps = sc.MetricFilter.filterWriteRequest(req, createdTimestamps)
+		}
+		if len(sc.RelabelConfigs) > 0 {
+			req, createdTimestamps = relabelWriteRequest(req, createdTimestamps, sc.RelabelConfigs)
+		}
 		if len(req.Timeseries) == 0 && len(req.Metadata) == 0 {
-			w.WriteHeader(http.StatusNoContent)
+			wr.writeStatus(w, http.StatusNoContent)
 			return
 		}
-		results, err := parser.fromPrometheusWriteRequestMetrics(req)
+		if sc.Quotas != nil {
+			decision := sc.Quotas.admit(r.Context(), r, sampleCount(req))
+			decision.setHeaders(w)
+			if !decision.admitted {
+				http.Error(w, "samples per hour quota exceeded", http.StatusTooManyRequests)
+				return
+			}
+		}
+		if sc.IngestStats != nil {
+			sc.IngestStats.record(req)
+		}
+		if sc.Batch != nil {
+			if err = sc.Batch.addWriteRequest(req, createdTimestamps); err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, errBatchClosed) {
+					status = http.StatusServiceUnavailable
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+			wr.writeStatus(w, http.StatusAccepted)
+			return
+		}
+		results, err := parser.fromWriteRequest(req, createdTimestamps)
 		if nil != err {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			sc.Reporter.OnDebugf("prometheus_translation", err)
 			return
 		}
+		if sc.TLSMetadata && r.TLS != nil {
+			addTLSMetadata(results, r.TLS)
+		}
+		if sc.Consume != nil {
+			if err = sc.Consume(r.Context(), results); err != nil {
+				status := http.StatusServiceUnavailable
+				if consumererror.IsPermanent(err) {
+					status = http.StatusBadRequest
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+			wr.writeStatus(w, http.StatusAccepted)
+			return
+		}
 		mc <- results
-		w.WriteHeader(http.StatusAccepted)
+		wr.writeStatus(w, http.StatusAccepted)
+	}
+}
+
+// decodeRemoteWriteRequest decodes the remote write 1.0 or 2.0 request of r by its Content-Type.
+func decodeRemoteWriteRequest(r *http.Request) (*writeRequest, error) {
+	protoMessage, err := remoteWriteProto(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if protoMessage == protoV2 {
+		return decodeWriteRequestV2(r.Body)
+	}
+	req, err := DecodeWriteRequest(r.Body)
+	if err != nil {
+		return nil, err
 	}
+	return &writeRequest{WriteRequest: req}, nil
 }
 
 // DecodeWriteRequest from an io.Reader into a prompb.WriteRequest, handling
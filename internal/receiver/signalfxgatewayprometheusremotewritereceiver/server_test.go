@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestHandlerReportsConsumerErrors(t *testing.T) {
+	data, err := proto.Marshal(sampleCounterWq())
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		err    error
+		name   string
+		status int
+	}{
+		{name: "consumed", status: http.StatusAccepted},
+		{name: "retryable", err: errors.New("export failed"), status: http.StatusServiceUnavailable},
+		{name: "permanent", err: consumererror.NewPermanent(errors.New("invalid data")), status: http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var consumed []pmetric.Metrics
+			mc := make(chan pmetric.Metrics, 1)
+			handler := newHandler(newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), &serverConfig{
+				Reporter: newMockReporter(),
+				Consume: func(_ context.Context, md pmetric.Metrics) error {
+					consumed = append(consumed, md)
+					return tt.err
+				},
+			}, mc)
+
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(snappy.Encode(nil, data))))
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Len(t, consumed, 1)
+			// the metrics are consumed synchronously instead of being buffered
+			assert.Empty(t, mc)
+		})
+	}
+}
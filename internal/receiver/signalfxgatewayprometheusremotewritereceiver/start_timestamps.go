@@ -0,0 +1,152 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/signalfx/splunk-otel-collector/internal/extension/seriescacheextension"
+)
+
+// seriesStart is the start of a cumulative series, with its last sample to detect resets.
+type seriesStart struct {
+	lastSeen      time.Time
+	start         int64
+	lastTimestamp int64
+	lastValue     float64
+}
+
+// startTimestampCache tracks the start timestamps of cumulative series whose sender doesn't send
+// created timestamps, like remote write 1.0 senders: the timestamp a series was first seen at, or
+// reset at when its value decreased. The series are kept in the namespace of a series_cache
+// extension if configured, bounding their number and persisting them across restarts, and
+// otherwise in memory until they expire.
+type startTimestampCache struct {
+	lastExpiry  time.Time
+	series      map[string]seriesStart
+	store       seriescacheextension.Cache
+	now         func() time.Time
+	expireAfter time.Duration
+	mu          sync.Mutex
+}
+
+func newStartTimestampCache(config StartTimestampsConfig) *startTimestampCache {
+	return &startTimestampCache{
+		series:      map[string]seriesStart{},
+		now:         time.Now,
+		lastExpiry:  time.Now(),
+		expireAfter: config.ExpireAfter,
+	}
+}
+
+// start returns the start timestamp of the sample of the series with the cumulative value, and
+// records the sample. The sample is a reset if the sender hinted so or its value decreased.
+// Samples older than the last one of the series and NaN values, like staleness markers, aren't
+// recorded.
+func (c *startTimestampCache) start(key string, timestamp int64, value float64, reset bool) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	c.expire(now)
+	s, ok := c.get(key)
+	if ok && (math.IsNaN(value) || timestamp < s.lastTimestamp) {
+		return min(s.start, timestamp)
+	}
+	if math.IsNaN(value) {
+		return timestamp
+	}
+	if !ok || reset || value < s.lastValue {
+		s.start = timestamp
+	}
+	s.lastSeen, s.lastTimestamp, s.lastValue = now, timestamp, value
+	c.set(key, s)
+	return s.start
+}
+
+func (c *startTimestampCache) get(key string) (seriesStart, bool) {
+	if c.store == nil {
+		s, ok := c.series[key]
+		return s, ok
+	}
+	data, ok := c.store.Get(key)
+	if !ok || len(data) != 24 {
+		return seriesStart{}, false
+	}
+	return seriesStart{
+		start:         int64(binary.BigEndian.Uint64(data)),     //nolint:gosec
+		lastTimestamp: int64(binary.BigEndian.Uint64(data[8:])), //nolint:gosec
+		lastValue:     math.Float64frombits(binary.BigEndian.Uint64(data[16:])),
+	}, true
+}
+
+func (c *startTimestampCache) set(key string, s seriesStart) {
+	if c.store == nil {
+		c.series[key] = s
+		return
+	}
+	data := make([]byte, 24)
+	binary.BigEndian.PutUint64(data, uint64(s.start))             //nolint:gosec
+	binary.BigEndian.PutUint64(data[8:], uint64(s.lastTimestamp)) //nolint:gosec
+	binary.BigEndian.PutUint64(data[16:], math.Float64bits(s.lastValue))
+	c.store.Set(key, data)
+}
+
+// expire forgets the series kept in memory that weren't seen for the expiry duration, checked
+// at most once per expiry duration.
+func (c *startTimestampCache) expire(now time.Time) {
+	if c.store != nil || now.Sub(c.lastExpiry) < c.expireAfter {
+		return
+	}
+	c.lastExpiry = now
+	for key, s := range c.series {
+		if now.Sub(s.lastSeen) >= c.expireAfter {
+			delete(c.series, key)
+		}
+	}
+}
+
+// seriesKey identifies the series with the metric name and labels, whose order is kept since
+// senders are required to sort them by name.
+func seriesKey(name string, labels []prompb.Label) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, label := range labels {
+		if label.Name == "__name__" {
+			continue
+		}
+		sb.WriteByte(0xff)
+		sb.WriteString(label.Name)
+		sb.WriteByte(0xff)
+		sb.WriteString(label.Value)
+	}
+	return sb.String()
+}
+
+// cumulativeStart returns the start timestamp of the sample of the cumulative series with the
+// value: the created timestamp of the series if known and not after the sample, else the
+// timestamp the series was first seen or reset at if start timestamps are tracked, and otherwise
+// the timestamp of the sample.
+func (prwParser *prometheusRemoteOtelParser) cumulativeStart(name string, labels []prompb.Label, createdTimestamp, timestamp int64, value float64) int64 {
+	if prwParser.startTimestamps == nil || (createdTimestamp != 0 && createdTimestamp <= timestamp) {
+		return startTimestamp(createdTimestamp, timestamp)
+	}
+	return prwParser.startTimestamps.start(seriesKey(name, labels), timestamp, value, false)
+}
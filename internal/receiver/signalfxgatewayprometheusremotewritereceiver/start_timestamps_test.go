@@ -0,0 +1,164 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestStartTimestampCache(t *testing.T) {
+	cache := newStartTimestampCache(StartTimestampsConfig{ExpireAfter: time.Hour})
+	now := jan20
+	cache.now = func() time.Time { return now }
+	cache.lastExpiry = now
+
+	assert.EqualValues(t, 1000, cache.start("requests", 1000, 5, false))
+	assert.EqualValues(t, 1000, cache.start("requests", 2000, 7, false))
+	// out of order samples and NaN values aren't recorded
+	assert.EqualValues(t, 500, cache.start("requests", 500, 1, false))
+	assert.EqualValues(t, 1000, cache.start("requests", 2500, math.Float64frombits(value.StaleNaN), false))
+	assert.EqualValues(t, 1000, cache.start("requests", 3000, 7, false))
+	// a decreasing value is a reset
+	assert.EqualValues(t, 4000, cache.start("requests", 4000, 2, false))
+	assert.EqualValues(t, 4000, cache.start("requests", 5000, 3, false))
+	assert.EqualValues(t, 6000, cache.start("requests", 6000, 4, true))
+	assert.EqualValues(t, 3000, cache.start("errors", 3000, math.NaN(), false))
+	assert.EqualValues(t, 7000, cache.start("errors", 7000, 1, false))
+
+	// series not seen for the expiry duration are forgotten
+	now = now.Add(30 * time.Minute)
+	assert.EqualValues(t, 7000, cache.start("errors", 8000, 1, false))
+	now = now.Add(45 * time.Minute)
+	assert.EqualValues(t, 9000, cache.start("requests", 9000, 4, false))
+	assert.Len(t, cache.series, 2)
+	now = now.Add(time.Hour)
+	assert.EqualValues(t, 10000, cache.start("errors", 10000, 5, false))
+	assert.Len(t, cache.series, 1)
+}
+
+func TestStartTimestampCacheStore(t *testing.T) {
+	store := mapCache{}
+	cache := newStartTimestampCache(StartTimestampsConfig{ExpireAfter: time.Hour})
+	cache.store = store
+	assert.EqualValues(t, 1000, cache.start("requests", 1000, 5, false))
+	assert.Empty(t, cache.series)
+	assert.Len(t, store, 1)
+
+	// the series are looked up in the store, e.g. after a restart
+	cache = newStartTimestampCache(StartTimestampsConfig{ExpireAfter: time.Hour})
+	cache.store = store
+	assert.EqualValues(t, 1000, cache.start("requests", 2000, 6, false))
+	assert.EqualValues(t, 3000, cache.start("requests", 3000, 1, false))
+
+	// series that can't be decoded start again
+	store["errors"] = []byte{0xff}
+	assert.EqualValues(t, 4000, cache.start("errors", 4000, 1, false))
+}
+
+func counterSeries(pod string, timestamp int64, v float64) prompb.TimeSeries {
+	return prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "pod", Value: pod}},
+		Samples: []prompb.Sample{{Value: v, Timestamp: timestamp}},
+	}
+}
+
+func TestStartTimestamps(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	parser.startTimestamps = newStartTimestampCache(StartTimestampsConfig{ExpireAfter: time.Hour})
+	start := jan20.UnixMilli()
+
+	startTimestamps := func(createdTimestamps []int64, series ...prompb.TimeSeries) map[string]int64 {
+		md, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: series}, createdTimestamps)
+		require.NoError(t, err)
+		starts := map[string]int64{}
+		metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		for i := 0; i < metrics.Len(); i++ {
+			if metrics.At(i).Name() != "http_requests_total" {
+				continue
+			}
+			dp := metrics.At(i).Sum().DataPoints().At(0)
+			pod, _ := dp.Attributes().Get("pod")
+			starts[pod.Str()] = dp.StartTimestamp().AsTime().UnixMilli()
+		}
+		return starts
+	}
+
+	assert.Equal(t, map[string]int64{"api-0": start, "api-1": start},
+		startTimestamps(nil, counterSeries("api-0", start, 10), counterSeries("api-1", start, 20)))
+	assert.Equal(t, map[string]int64{"api-0": start, "api-1": start + 15000},
+		startTimestamps(nil, counterSeries("api-0", start+15000, 12), counterSeries("api-1", start+15000, 3)))
+	// created timestamps sent by the sender take precedence
+	assert.Equal(t, map[string]int64{"api-0": start - 60000},
+		startTimestamps([]int64{start - 60000}, counterSeries("api-0", start+30000, 15)))
+
+	md, err := parser.fromWriteRequest(nativeHistogramWriteRequest(), nil)
+	require.NoError(t, err)
+	dps := findMetric(t, md, "request_duration_seconds").ExponentialHistogram().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	assert.Equal(t, prometheusToOtelTimestamp(start), dps.At(0).StartTimestamp())
+	// the histogram the sender hinted was reset starts the series again
+	assert.Equal(t, dps.At(1).Timestamp(), dps.At(1).StartTimestamp())
+	series, ok := parser.startTimestamps.get(seriesKey("request_duration_seconds", []prompb.Label{{Name: "path", Value: "/"}}))
+	require.True(t, ok)
+	assert.Equal(t, start+1000, series.start)
+}
+
+func TestStartTimestampsDisabled(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	for _, timestamp := range []int64{jan20.UnixMilli(), jan20.UnixMilli() + 15000} {
+		md, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{counterSeries("api-0", timestamp, 10)}}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, prometheusToOtelTimestamp(timestamp), findMetric(t, md, "http_requests_total").Sum().DataPoints().At(0).StartTimestamp())
+	}
+}
+
+func TestReassembleStartTimestamps(t *testing.T) {
+	parser, now, mc := newTestReassembly()
+	parser.startTimestamps = newStartTimestampCache(StartTimestampsConfig{ExpireAfter: time.Hour})
+
+	reassemble := func(count float64) pmetric.SummaryDataPoint {
+		_, err := parser.fromWriteRequest(&prompb.WriteRequest{
+			Timeseries: []prompb.TimeSeries{
+				summarySeries("rpc_duration_seconds", "api-0", 0.1, prompb.Label{Name: "quantile", Value: "0.5"}),
+				summarySeries("rpc_duration_seconds_count", "api-0", count),
+			},
+			Metadata: []prompb.MetricMetadata{{MetricFamilyName: "rpc_duration_seconds", Type: prompb.MetricMetadata_SUMMARY}},
+		}, nil)
+		require.NoError(t, err)
+		*now = now.Add(5 * time.Second)
+		parser.reassembly.mu.Lock()
+		points := parser.reassembly.takeCollected(false)
+		parser.reassembly.mu.Unlock()
+		parser.reassembly.send(points)
+		return (<-mc).ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Summary().DataPoints().At(0)
+	}
+
+	// summarySeries samples are at jan20, so the reassembled points are too, and a decreasing
+	// count is a reset at the same timestamp
+	assert.Equal(t, prometheusToOtelTimestamp(jan20.UnixMilli()), reassemble(100).StartTimestamp())
+	assert.Equal(t, prometheusToOtelTimestamp(jan20.UnixMilli()), reassemble(100).StartTimestamp())
+	assert.Equal(t, prometheusToOtelTimestamp(jan20.UnixMilli()), reassemble(50).StartTimestamp())
+	start, ok := parser.startTimestamps.get(seriesKey("rpc_duration_seconds", []prompb.Label{{Name: "pod", Value: "api-0"}}))
+	require.True(t, ok)
+	assert.Equal(t, 50.0, start.lastValue)
+}
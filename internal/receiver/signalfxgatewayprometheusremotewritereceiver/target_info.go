@@ -0,0 +1,119 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// targetInfoMetric is the metric Prometheus exposes the resource attributes of OpenTelemetry
+// instrumented targets as.
+const targetInfoMetric = "target_info"
+
+// targetInfo is the labels of the target_info of a target, other than its job and instance.
+type targetInfo struct {
+	lastSeen   time.Time
+	attributes pcommon.Map
+}
+
+// targetInfoCache keeps the labels of the target_info metric of each target, identified by its job
+// and instance, to fold them into the resource attributes of the series of the target, until the
+// target isn't seen for the expiry duration.
+type targetInfoCache struct {
+	lastExpiry  time.Time
+	targets     map[jobInstance]targetInfo
+	now         func() time.Time
+	expireAfter time.Duration
+	mu          sync.Mutex
+}
+
+func newTargetInfoCache(config TargetInfoConfig) *targetInfoCache {
+	return &targetInfoCache{
+		targets:     map[jobInstance]targetInfo{},
+		now:         time.Now,
+		lastExpiry:  time.Now(),
+		expireAfter: config.ExpireAfter,
+	}
+}
+
+// record removes the target_info metrics of md and records the labels of their datapoints as the
+// target info of their job and instance. A datapoint without recorded value, like a translated
+// staleness marker, forgets its target.
+func (c *targetInfoCache) record(md pmetric.Metrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	c.expire(now)
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sms.At(j).Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				if m.Name() != targetInfoMetric || m.Type() != pmetric.MetricTypeGauge {
+					return false
+				}
+				dps := m.Gauge().DataPoints()
+				for k := 0; k < dps.Len(); k++ {
+					dp := dps.At(k)
+					key := readJobInstance(dp.Attributes(), false)
+					if dp.Flags().NoRecordedValue() {
+						delete(c.targets, key)
+						continue
+					}
+					attributes := pcommon.NewMap()
+					dp.Attributes().CopyTo(attributes)
+					readJobInstance(attributes, true)
+					c.targets[key] = targetInfo{lastSeen: now, attributes: attributes}
+				}
+				return true
+			})
+		}
+	}
+}
+
+// putResourceAttributes sets the target info of the job and instance, if known, as resource
+// attributes, without replacing the attributes the resource already has.
+func (c *targetInfoCache) putResourceAttributes(key jobInstance, attrs pcommon.Map) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target, ok := c.targets[key]
+	if !ok {
+		return
+	}
+	target.attributes.Range(func(k string, v pcommon.Value) bool {
+		if _, exists := attrs.Get(k); !exists {
+			v.CopyTo(attrs.PutEmpty(k))
+		}
+		return true
+	})
+}
+
+// expire forgets the targets that weren't seen for the expiry duration, checked at most once per
+// expiry duration.
+func (c *targetInfoCache) expire(now time.Time) {
+	if now.Sub(c.lastExpiry) < c.expireAfter {
+		return
+	}
+	c.lastExpiry = now
+	for key, target := range c.targets {
+		if now.Sub(target.lastSeen) >= c.expireAfter {
+			delete(c.targets, key)
+		}
+	}
+}
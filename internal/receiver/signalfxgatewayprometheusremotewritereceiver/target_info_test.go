@@ -0,0 +1,139 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func targetInfoRequest(instance string, targetInfoValue float64) *prompb.WriteRequest {
+	return &prompb.WriteRequest{
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "target_info", Type: prompb.MetricMetadata_INFO},
+		},
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_in_flight"}, {Name: "instance", Value: instance}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: 3, Timestamp: 1000}},
+			},
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "target_info"},
+					{Name: "instance", Value: instance},
+					{Name: "job", Value: "api"},
+					{Name: "k8s_pod_name", Value: "api-" + instance},
+					{Name: "service_version", Value: "1.2.0"},
+				},
+				Samples: []prompb.Sample{{Value: targetInfoValue, Timestamp: 1000}},
+			},
+		},
+	}
+}
+
+// resourceOf returns the attributes of the resource of the metric with the name.
+func resourceOf(t *testing.T, md pmetric.Metrics, name string) map[string]any {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			metrics := rm.ScopeMetrics().At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				if metrics.At(k).Name() == name {
+					return rm.Resource().Attributes().AsRaw()
+				}
+			}
+		}
+	}
+	require.Failf(t, "metric not found", "no %s metric", name)
+	return nil
+}
+
+func TestTargetInfo(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	cache := newTargetInfoCache(TargetInfoConfig{ExpireAfter: time.Hour})
+
+	md, err := parser.fromPrometheusWriteRequestMetrics(targetInfoRequest("api-1:8080", 1))
+	require.NoError(t, err)
+	cache.record(md)
+	grouped := groupByJobInstance(md, false, cache)
+
+	assert.Equal(t, map[string]any{"k8s_pod_name": "api-api-1:8080", "service_version": "1.2.0"},
+		resourceOf(t, grouped, "http_requests_in_flight"))
+	for i := 0; i < grouped.ResourceMetrics().Len(); i++ {
+		metrics := grouped.ResourceMetrics().At(i).ScopeMetrics().At(0).Metrics()
+		for j := 0; j < metrics.Len(); j++ {
+			assert.NotEqual(t, "target_info", metrics.At(j).Name())
+		}
+	}
+	// the job and instance are kept as datapoint attributes without promote_job_instance
+	inFlight := grouped.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, map[string]any{"job": "api", "instance": "api-1:8080"}, inFlight.Gauge().DataPoints().At(0).Attributes().AsRaw())
+
+	// the target info applies to the series of the target received later, and the attributes of the
+	// resource have precedence
+	md, err = parser.fromPrometheusWriteRequestMetrics(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests_in_flight"}, {Name: "instance", Value: "api-1:8080"}, {Name: "job", Value: "api"}},
+				Samples: []prompb.Sample{{Value: 2, Timestamp: 2000}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	md.ResourceMetrics().At(0).Resource().Attributes().PutStr("k8s_pod_name", "from-resource")
+	cache.record(md)
+	assert.Equal(t,
+		map[string]any{"k8s_pod_name": "from-resource", "service_version": "1.2.0", "service.name": "api", "service.instance.id": "api-1:8080"},
+		resourceOf(t, groupByJobInstance(md, true, cache), "http_requests_in_flight"))
+}
+
+func TestTargetInfoForgetsTargets(t *testing.T) {
+	now := time.Now()
+	cache := newTargetInfoCache(TargetInfoConfig{ExpireAfter: time.Hour})
+	cache.now = func() time.Time { return now }
+	cache.lastExpiry = now
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	parser.staleMarkers = StaleMarkersNoRecordedValue
+
+	for _, instance := range []string{"api-1:8080", "api-2:8080"} {
+		md, err := parser.fromPrometheusWriteRequestMetrics(targetInfoRequest(instance, 1))
+		require.NoError(t, err)
+		cache.record(md)
+	}
+	require.Len(t, cache.targets, 2)
+
+	// a staleness marker forgets the target
+	md, err := parser.fromPrometheusWriteRequestMetrics(targetInfoRequest("api-2:8080", math.Float64frombits(value.StaleNaN)))
+	require.NoError(t, err)
+	cache.record(md)
+	assert.Equal(t, map[string]any{}, resourceOf(t, groupByJobInstance(md, false, cache), "http_requests_in_flight"))
+	require.Len(t, cache.targets, 1)
+
+	// targets without target_info for the expiry duration are forgotten
+	now = now.Add(time.Hour)
+	cache.record(pmetric.NewMetrics())
+	assert.Empty(t, cache.targets)
+	attrs := pcommon.NewMap()
+	cache.putResourceAttributes(jobInstance{job: "api", instance: "api-1:8080"}, attrs)
+	assert.Zero(t, attrs.Len())
+}
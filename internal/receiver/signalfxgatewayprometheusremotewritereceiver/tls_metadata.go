@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Resource attributes of the TLS connection metadata, following the OpenTelemetry semantic conventions.
+const (
+	tlsProtocolNameAttr    = "tls.protocol.name"
+	tlsProtocolVersionAttr = "tls.protocol.version"
+	tlsCipherAttr          = "tls.cipher"
+	tlsClientSubjectAttr   = "tls.client.subject"
+)
+
+// addTLSMetadata records the negotiated TLS version and cipher suite and the client certificate
+// subject, if one was presented, as resource attributes of all metrics.
+func addTLSMetadata(md pmetric.Metrics, state *tls.ConnectionState) {
+	var subject string
+	if len(state.PeerCertificates) > 0 {
+		subject = state.PeerCertificates[0].Subject.String()
+	}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		attrs := rms.At(i).Resource().Attributes()
+		attrs.PutStr(tlsProtocolNameAttr, "tls")
+		attrs.PutStr(tlsProtocolVersionAttr, tlsVersion(state.Version))
+		attrs.PutStr(tlsCipherAttr, tls.CipherSuiteName(state.CipherSuite))
+		if subject != "" {
+			attrs.PutStr(tlsClientSubjectAttr, subject)
+		}
+	}
+}
+
+// tlsVersion returns the semantic conventions' form of a TLS version, e.g. "1.2".
+func tlsVersion(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestAddTLSMetadata(t *testing.T) {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().Resource().Attributes().PutStr("service.name", "prw")
+	md.ResourceMetrics().AppendEmpty()
+
+	addTLSMetadata(md, &tls.ConnectionState{
+		Version:     tls.VersionTLS10,
+		CipherSuite: tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+		PeerCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: "sender", Organization: []string{"Splunk"}}},
+		},
+	})
+
+	expected := map[string]any{
+		"tls.protocol.name":    "tls",
+		"tls.protocol.version": "1.0",
+		"tls.cipher":           "TLS_RSA_WITH_AES_128_CBC_SHA",
+		"tls.client.subject":   "CN=sender,O=Splunk",
+	}
+	withServiceName := map[string]any{"service.name": "prw"}
+	for k, v := range expected {
+		withServiceName[k] = v
+	}
+	assert.Equal(t, withServiceName, md.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+	assert.Equal(t, expected, md.ResourceMetrics().At(1).Resource().Attributes().AsRaw())
+}
+
+func TestAddTLSMetadataWithoutClientCertificate(t *testing.T) {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty()
+
+	addTLSMetadata(md, &tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256})
+
+	assert.Equal(t, map[string]any{
+		"tls.protocol.name":    "tls",
+		"tls.protocol.version": "1.3",
+		"tls.cipher":           "TLS_AES_128_GCM_SHA256",
+	}, md.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+}
+
+func TestTLSVersion(t *testing.T) {
+	assert.Equal(t, "1.1", tlsVersion(tls.VersionTLS11))
+	assert.Equal(t, "1.2", tlsVersion(tls.VersionTLS12))
+	assert.Equal(t, "0x0300", tlsVersion(0x0300))
+}
@@ -0,0 +1,76 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// typeRuleTypes are the metric types type rules can set, by their configured name.
+var typeRuleTypes = map[string]prompb.MetricMetadata_MetricType{
+	"gauge":     prompb.MetricMetadata_GAUGE,
+	"counter":   prompb.MetricMetadata_COUNTER,
+	"histogram": prompb.MetricMetadata_HISTOGRAM,
+}
+
+// typeRule types the series whose metric name it matches, instead of the naming conventions.
+type typeRule struct {
+	regex      *regexp.Regexp
+	suffix     string
+	metricType prompb.MetricMetadata_MetricType
+}
+
+func newTypeRules(configs []TypeRuleConfig) ([]typeRule, error) {
+	rules := make([]typeRule, 0, len(configs))
+	for i, config := range configs {
+		rule := typeRule{suffix: config.Suffix, metricType: typeRuleTypes[config.Type]}
+		if config.Regex != "" {
+			regex, err := compileTypeRuleRegex(config.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("type_rules[%d] regex is invalid: %w", i, err)
+			}
+			rule.regex = regex
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// compileTypeRuleRegex compiles the regex, anchored to match whole metric names like Prometheus
+// relabeling regexes.
+func compileTypeRuleRegex(regex string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + regex + ")$")
+}
+
+func (r typeRule) matches(metricName string) bool {
+	if r.regex != nil {
+		return r.regex.MatchString(metricName)
+	}
+	return strings.HasSuffix(metricName, r.suffix)
+}
+
+// typeByRules returns the type of the first type rule matching the metric name.
+func (prwParser *prometheusRemoteOtelParser) typeByRules(metricName string) (prompb.MetricMetadata_MetricType, bool) {
+	for _, rule := range prwParser.typeRules {
+		if rule.matches(metricName) {
+			return rule.metricType, true
+		}
+	}
+	return prompb.MetricMetadata_UNKNOWN, false
+}
@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestTypeRules(t *testing.T) {
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	var err error
+	parser.typeRules, err = newTypeRules([]TypeRuleConfig{
+		{Suffix: "_requests", Type: "counter"},
+		{Regex: "legacy_.*_(current|max)", Type: "gauge"},
+		{Regex: "legacy_.*", Type: "counter"},
+		{Suffix: "_latency", Type: "histogram"},
+	})
+	require.NoError(t, err)
+	parser.metadata.update([]prompb.MetricMetadata{
+		{MetricFamilyName: "typed_requests", Type: prompb.MetricMetadata_GAUGE},
+		{MetricFamilyName: "mystery_requests", Type: prompb.MetricMetadata_UNKNOWN},
+	})
+
+	for _, tt := range []struct {
+		name         string
+		expectedType prompb.MetricMetadata_MetricType
+	}{
+		{name: "http_requests", expectedType: prompb.MetricMetadata_COUNTER},
+		{name: "legacy_sessions_current", expectedType: prompb.MetricMetadata_GAUGE},
+		{name: "legacy_sessions_opened", expectedType: prompb.MetricMetadata_COUNTER},
+		// the regex matches whole metric names
+		{name: "app_legacy_sessions_opened", expectedType: prompb.MetricMetadata_GAUGE},
+		{name: "rpc_latency", expectedType: prompb.MetricMetadata_HISTOGRAM},
+		// the metadata sent by the sender takes precedence, unless its type is unknown
+		{name: "typed_requests", expectedType: prompb.MetricMetadata_GAUGE},
+		{name: "mystery_requests", expectedType: prompb.MetricMetadata_COUNTER},
+		// series no rule matches are typed by the conventions
+		{name: "errors_total", expectedType: prompb.MetricMetadata_COUNTER},
+		{name: "temperature", expectedType: prompb.MetricMetadata_GAUGE},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedType, parser.metricMetadata(tt.name, []prompb.Label{{Name: "__name__", Value: tt.name}}).Type)
+		})
+	}
+
+	md, err := parser.fromWriteRequest(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "http_requests"}},
+			Samples: []prompb.Sample{{Value: 10, Timestamp: jan20.UnixMilli()}},
+		},
+	}}, nil)
+	require.NoError(t, err)
+	metric := findMetric(t, md, "http_requests")
+	require.Equal(t, pmetric.MetricTypeSum, metric.Type())
+	assert.True(t, metric.Sum().IsMonotonic())
+}
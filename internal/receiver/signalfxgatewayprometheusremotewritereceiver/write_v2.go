@@ -0,0 +1,406 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	protoV1 = "prometheus.WriteRequest"
+	protoV2 = "io.prometheus.write.v2.Request"
+
+	samplesWrittenHeader    = "X-Prometheus-Remote-Write-Samples-Written"
+	histogramsWrittenHeader = "X-Prometheus-Remote-Write-Histograms-Written"
+	exemplarsWrittenHeader  = "X-Prometheus-Remote-Write-Exemplars-Written"
+)
+
+var errUnsupportedProto = errors.New("unsupported remote write protobuf message")
+
+// writeRequest is a decoded write request. Remote write 2.0 requests are converted to a
+// prompb.WriteRequest, with their inlined metadata as its metadata, and the created timestamps
+// of their series alongside since it has no field for them.
+type writeRequest struct {
+	*prompb.WriteRequest
+	// createdTimestamps are the created timestamps in milliseconds of the series by index, nil if
+	// no series has one.
+	createdTimestamps []int64
+	// v2 is set for remote write 2.0 requests, whose responses report the data written.
+	v2 bool
+}
+
+// remoteWriteProto returns the protobuf message of the write request from its Content-Type.
+// Requests without, or with another, Content-Type are remote write 1.0 requests, like before
+// remote write 2.0 existed.
+func remoteWriteProto(contentType string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "application/x-protobuf" {
+		return protoV1, nil
+	}
+	switch params["proto"] {
+	case "", protoV1:
+		return protoV1, nil
+	case protoV2:
+		return protoV2, nil
+	}
+	return "", fmt.Errorf("%w %q, supported messages are %s and %s", errUnsupportedProto, params["proto"], protoV1, protoV2)
+}
+
+// decodeWriteRequestV2 decodes a snappy compressed io.prometheus.write.v2.Request.
+func decodeWriteRequestV2(r io.Reader) (*writeRequest, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalWriteRequestV2(b)
+}
+
+// unmarshalWriteRequestV2 converts the io.prometheus.write.v2.Request message to a prompb.WriteRequest,
+// resolving the references of its series to its symbol table.
+func unmarshalWriteRequestV2(b []byte) (*writeRequest, error) {
+	var symbols []string
+	var series [][]byte
+	err := forEachField(b, func(num protowire.Number, _ uint64, data []byte) error {
+		switch num {
+		case 4:
+			symbols = append(symbols, string(data))
+		case 5:
+			series = append(series, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote write 2.0 request: %w", err)
+	}
+
+	req := &writeRequest{WriteRequest: &prompb.WriteRequest{Timeseries: make([]prompb.TimeSeries, len(series))}, v2: true}
+	families := map[string]int{}
+	for i, data := range series {
+		ts, md, createdTimestamp, err := unmarshalTimeSeriesV2(data, symbols)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote write 2.0 request: timeseries[%d]: %w", i, err)
+		}
+		req.Timeseries[i] = ts
+		if createdTimestamp != 0 {
+			if req.createdTimestamps == nil {
+				req.createdTimestamps = make([]int64, len(series))
+			}
+			req.createdTimestamps[i] = createdTimestamp
+		}
+		if md == nil {
+			continue
+		}
+		md.MetricFamilyName = familyName(metricNameLabel(ts.Labels), md.Type)
+		if index, ok := families[md.MetricFamilyName]; ok {
+			req.Metadata[index] = *md
+		} else {
+			families[md.MetricFamilyName] = len(req.Metadata)
+			req.Metadata = append(req.Metadata, *md)
+		}
+	}
+	return req, nil
+}
+
+// unmarshalTimeSeriesV2 converts the io.prometheus.write.v2.TimeSeries message to a
+// prompb.TimeSeries, returning its metadata, nil if it has none, and its created timestamp.
+func unmarshalTimeSeriesV2(b []byte, symbols []string) (prompb.TimeSeries, *prompb.MetricMetadata, int64, error) {
+	var ts prompb.TimeSeries
+	var labelsRefs []uint64
+	var md *prompb.MetricMetadata
+	var createdTimestamp int64
+	err := forEachField(b, func(num protowire.Number, v uint64, data []byte) error {
+		var err error
+		switch num {
+		case 1:
+			labelsRefs, err = appendPacked(labelsRefs, data, v, protowire.ConsumeVarint)
+		case 2:
+			var sample prompb.Sample
+			err = forEachField(data, func(num protowire.Number, v uint64, _ []byte) error {
+				switch num {
+				case 1:
+					sample.Value = math.Float64frombits(v)
+				case 2:
+					sample.Timestamp = int64(v) //nolint:gosec
+				}
+				return nil
+			})
+			ts.Samples = append(ts.Samples, sample)
+		case 3:
+			var histogram prompb.Histogram
+			histogram, err = unmarshalHistogram(data)
+			ts.Histograms = append(ts.Histograms, histogram)
+		case 4:
+			var exemplar prompb.Exemplar
+			var exemplarRefs []uint64
+			err = forEachField(data, func(num protowire.Number, v uint64, data []byte) error {
+				var err error
+				switch num {
+				case 1:
+					exemplarRefs, err = appendPacked(exemplarRefs, data, v, protowire.ConsumeVarint)
+				case 2:
+					exemplar.Value = math.Float64frombits(v)
+				case 3:
+					exemplar.Timestamp = int64(v) //nolint:gosec
+				}
+				return err
+			})
+			if err == nil {
+				exemplar.Labels, err = resolveLabels(exemplarRefs, symbols)
+			}
+			ts.Exemplars = append(ts.Exemplars, exemplar)
+		case 5:
+			md, err = unmarshalMetadataV2(data, symbols)
+		case 6:
+			createdTimestamp = int64(v) //nolint:gosec
+		}
+		return err
+	})
+	if err != nil {
+		return ts, nil, 0, err
+	}
+	ts.Labels, err = resolveLabels(labelsRefs, symbols)
+	return ts, md, createdTimestamp, err
+}
+
+// unmarshalMetadataV2 converts the io.prometheus.write.v2.Metadata message, whose metric types
+// have the same values as the prompb ones, returning nil if it's empty.
+func unmarshalMetadataV2(b []byte, symbols []string) (*prompb.MetricMetadata, error) {
+	var md prompb.MetricMetadata
+	err := forEachField(b, func(num protowire.Number, v uint64, _ []byte) error {
+		var err error
+		switch num {
+		case 1:
+			md.Type = prompb.MetricMetadata_MetricType(v) //nolint:gosec
+		case 3:
+			md.Help, err = symbol(symbols, v)
+		case 4:
+			md.Unit, err = symbol(symbols, v)
+		}
+		return err
+	})
+	if err != nil || md == (prompb.MetricMetadata{}) {
+		return nil, err
+	}
+	return &md, nil
+}
+
+// unmarshalHistogram decodes a native histogram, whose message is the same in both remote write versions.
+func unmarshalHistogram(b []byte) (prompb.Histogram, error) {
+	var h prompb.Histogram
+	err := forEachField(b, func(num protowire.Number, v uint64, data []byte) error {
+		var err error
+		switch num {
+		case 1:
+			h.Count = &prompb.Histogram_CountInt{CountInt: v}
+		case 2:
+			h.Count = &prompb.Histogram_CountFloat{CountFloat: math.Float64frombits(v)}
+		case 3:
+			h.Sum = math.Float64frombits(v)
+		case 4:
+			h.Schema = int32(protowire.DecodeZigZag(v)) //nolint:gosec
+		case 5:
+			h.ZeroThreshold = math.Float64frombits(v)
+		case 6:
+			h.ZeroCount = &prompb.Histogram_ZeroCountInt{ZeroCountInt: v}
+		case 7:
+			h.ZeroCount = &prompb.Histogram_ZeroCountFloat{ZeroCountFloat: math.Float64frombits(v)}
+		case 8, 11:
+			var span prompb.BucketSpan
+			err = forEachField(data, func(num protowire.Number, v uint64, _ []byte) error {
+				switch num {
+				case 1:
+					span.Offset = int32(protowire.DecodeZigZag(v)) //nolint:gosec
+				case 2:
+					span.Length = uint32(v) //nolint:gosec
+				}
+				return nil
+			})
+			if num == 8 {
+				h.NegativeSpans = append(h.NegativeSpans, span)
+			} else {
+				h.PositiveSpans = append(h.PositiveSpans, span)
+			}
+		case 9:
+			h.NegativeDeltas, err = appendDeltas(h.NegativeDeltas, data, v)
+		case 10:
+			h.NegativeCounts, err = appendCounts(h.NegativeCounts, data, v)
+		case 12:
+			h.PositiveDeltas, err = appendDeltas(h.PositiveDeltas, data, v)
+		case 13:
+			h.PositiveCounts, err = appendCounts(h.PositiveCounts, data, v)
+		case 14:
+			h.ResetHint = prompb.Histogram_ResetHint(v) //nolint:gosec
+		case 15:
+			h.Timestamp = int64(v) //nolint:gosec
+		}
+		return err
+	})
+	return h, err
+}
+
+// resolveLabels returns the labels of the symbol references of the name and value of each label.
+func resolveLabels(refs []uint64, symbols []string) ([]prompb.Label, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	if len(refs)%2 != 0 {
+		return nil, errors.New("odd number of label references")
+	}
+	labels := make([]prompb.Label, len(refs)/2)
+	var err error
+	for i := range labels {
+		if labels[i].Name, err = symbol(symbols, refs[2*i]); err != nil {
+			return nil, err
+		}
+		if labels[i].Value, err = symbol(symbols, refs[2*i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
+
+func symbol(symbols []string, ref uint64) (string, error) {
+	if ref >= uint64(len(symbols)) {
+		return "", fmt.Errorf("symbol reference %d out of the %d symbols", ref, len(symbols))
+	}
+	return symbols[ref], nil
+}
+
+func appendDeltas(dst []int64, data []byte, v uint64) ([]int64, error) {
+	values, err := appendPacked(nil, data, v, protowire.ConsumeVarint)
+	for _, value := range values {
+		dst = append(dst, protowire.DecodeZigZag(value))
+	}
+	return dst, err
+}
+
+func appendCounts(dst []float64, data []byte, v uint64) ([]float64, error) {
+	values, err := appendPacked(nil, data, v, protowire.ConsumeFixed64)
+	for _, value := range values {
+		dst = append(dst, math.Float64frombits(value))
+	}
+	return dst, err
+}
+
+// appendPacked appends the values of a repeated scalar field, either the packed values of data
+// or the single value v if data is nil, since senders may encode them either way.
+func appendPacked(dst []uint64, data []byte, v uint64, consume func([]byte) (uint64, int)) ([]uint64, error) {
+	if data == nil {
+		return append(dst, v), nil
+	}
+	for len(data) > 0 {
+		value, n := consume(data)
+		if n < 0 {
+			return dst, protowire.ParseError(n)
+		}
+		dst = append(dst, value)
+		data = data[n:]
+	}
+	return dst, nil
+}
+
+// forEachField calls fn with the number and value of each field of the message: the value of
+// varint and fixed size fields as v, and the bytes of length delimited fields, never nil, as data.
+func forEachField(b []byte, fn func(num protowire.Number, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		var v uint64
+		var data []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v32 uint32
+			v32, n = protowire.ConsumeFixed32(b)
+			v = uint64(v32)
+		case protowire.BytesType:
+			data, n = protowire.ConsumeBytes(b)
+			if data == nil {
+				data = []byte{}
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ == protowire.StartGroupType {
+			continue
+		}
+		if err := fn(num, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// familyName returns the name of the family of the series with the metric name and type of
+// its family, e.g. `http_request_duration_seconds` for its `_bucket` series.
+func familyName(metricName string, familyType prompb.MetricMetadata_MetricType) string {
+	for _, suffix := range familySuffixes[familyType] {
+		if name, ok := strings.CutSuffix(metricName, suffix); ok && name != "" {
+			return name
+		}
+	}
+	return metricName
+}
+
+func metricNameLabel(labels []prompb.Label) string {
+	for _, label := range labels {
+		if label.Name == "__name__" {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+// writeStatus writes the success status of the request, with the data written of remote write 2.0
+// requests.
+func (req *writeRequest) writeStatus(w http.ResponseWriter, status int) {
+	if req.v2 {
+		var samples, histograms, exemplars int
+		for _, ts := range req.Timeseries {
+			samples += len(ts.Samples)
+			histograms += len(ts.Histograms)
+			exemplars += len(ts.Exemplars)
+		}
+		w.Header().Set(samplesWrittenHeader, strconv.Itoa(samples))
+		w.Header().Set(histogramsWrittenHeader, strconv.Itoa(histograms))
+		w.Header().Set(exemplarsWrittenHeader, strconv.Itoa(exemplars))
+	}
+	w.WriteHeader(status)
+}
@@ -0,0 +1,332 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxgatewayprometheusremotewritereceiver
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const contentTypeV2 = "application/x-protobuf;proto=io.prometheus.write.v2.Request"
+
+// testSeriesV2 is a remote write 2.0 series to encode, referencing the symbols of its request.
+type testSeriesV2 struct {
+	labelsRefs       []uint64
+	samples          []prompb.Sample
+	exemplarRefs     []uint64
+	metadataType     prompb.MetricMetadata_MetricType
+	helpRef          uint64
+	unitRef          uint64
+	createdTimestamp int64
+}
+
+func encodeWriteRequestV2(symbols []string, series []testSeriesV2) []byte {
+	var b []byte
+	for _, symbol := range symbols {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, symbol)
+	}
+	for _, s := range series {
+		var ts []byte
+		var refs []byte
+		for _, ref := range s.labelsRefs {
+			refs = protowire.AppendVarint(refs, ref)
+		}
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, refs)
+		for _, sample := range s.samples {
+			var sb []byte
+			sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+			sb = protowire.AppendFixed64(sb, math.Float64bits(sample.Value))
+			sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(sample.Timestamp))
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, sb)
+		}
+		if s.exemplarRefs != nil {
+			var eb []byte
+			for _, ref := range s.exemplarRefs {
+				// unpacked references are accepted as well
+				eb = protowire.AppendTag(eb, 1, protowire.VarintType)
+				eb = protowire.AppendVarint(eb, ref)
+			}
+			eb = protowire.AppendTag(eb, 2, protowire.Fixed64Type)
+			eb = protowire.AppendFixed64(eb, math.Float64bits(0.5))
+			ts = protowire.AppendTag(ts, 4, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, eb)
+		}
+		if s.metadataType != 0 || s.helpRef != 0 || s.unitRef != 0 {
+			var mb []byte
+			mb = protowire.AppendTag(mb, 1, protowire.VarintType)
+			mb = protowire.AppendVarint(mb, uint64(s.metadataType))
+			mb = protowire.AppendTag(mb, 3, protowire.VarintType)
+			mb = protowire.AppendVarint(mb, s.helpRef)
+			mb = protowire.AppendTag(mb, 4, protowire.VarintType)
+			mb = protowire.AppendVarint(mb, s.unitRef)
+			ts = protowire.AppendTag(ts, 5, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, mb)
+		}
+		if s.createdTimestamp != 0 {
+			ts = protowire.AppendTag(ts, 6, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(s.createdTimestamp))
+		}
+		b = protowire.AppendTag(b, 5, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return b
+}
+
+var testSymbolsV2 = []string{
+	"", "__name__", "http_requests_total", "method", "GET", "Requests served.", "requests",
+	"temperature", "trace_id", "abc",
+}
+
+func testWriteRequestV2() []byte {
+	return encodeWriteRequestV2(testSymbolsV2, []testSeriesV2{
+		{
+			labelsRefs:       []uint64{1, 2, 3, 4},
+			samples:          []prompb.Sample{{Value: 10, Timestamp: 1700000060000}},
+			exemplarRefs:     []uint64{8, 9},
+			metadataType:     prompb.MetricMetadata_COUNTER,
+			helpRef:          5,
+			unitRef:          6,
+			createdTimestamp: 1700000000000,
+		},
+		{
+			labelsRefs: []uint64{1, 7},
+			samples:    []prompb.Sample{{Value: 21.5, Timestamp: 1700000060000}, {Value: 22, Timestamp: 1700000070000}},
+		},
+	})
+}
+
+func TestUnmarshalWriteRequestV2(t *testing.T) {
+	req, err := unmarshalWriteRequestV2(testWriteRequestV2())
+	require.NoError(t, err)
+	assert.True(t, req.v2)
+	assert.Equal(t, []prompb.TimeSeries{
+		{
+			Labels:    []prompb.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "method", Value: "GET"}},
+			Samples:   []prompb.Sample{{Value: 10, Timestamp: 1700000060000}},
+			Exemplars: []prompb.Exemplar{{Labels: []prompb.Label{{Name: "trace_id", Value: "abc"}}, Value: 0.5}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+			Samples: []prompb.Sample{{Value: 21.5, Timestamp: 1700000060000}, {Value: 22, Timestamp: 1700000070000}},
+		},
+	}, req.Timeseries)
+	assert.Equal(t, []prompb.MetricMetadata{
+		{MetricFamilyName: "http_requests", Type: prompb.MetricMetadata_COUNTER, Help: "Requests served.", Unit: "requests"},
+	}, req.Metadata)
+	assert.Equal(t, []int64{1700000000000, 0}, req.createdTimestamps)
+
+	req, err = unmarshalWriteRequestV2(encodeWriteRequestV2(testSymbolsV2, []testSeriesV2{{labelsRefs: []uint64{1, 7}}}))
+	require.NoError(t, err)
+	assert.Nil(t, req.createdTimestamps)
+	assert.Empty(t, req.Metadata)
+}
+
+func TestUnmarshalWriteRequestV2Invalid(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		data        []byte
+		expectedErr string
+	}{
+		{
+			name:        "symbol reference out of range",
+			data:        encodeWriteRequestV2(testSymbolsV2, []testSeriesV2{{labelsRefs: []uint64{1, 10}}}),
+			expectedErr: "invalid remote write 2.0 request: timeseries[0]: symbol reference 10 out of the 10 symbols",
+		},
+		{
+			name:        "odd label references",
+			data:        encodeWriteRequestV2(testSymbolsV2, []testSeriesV2{{labelsRefs: []uint64{1, 2, 3}}}),
+			expectedErr: "invalid remote write 2.0 request: timeseries[0]: odd number of label references",
+		},
+		{
+			name:        "help reference out of range",
+			data:        encodeWriteRequestV2(testSymbolsV2, []testSeriesV2{{labelsRefs: []uint64{1, 7}, helpRef: 42}}),
+			expectedErr: "invalid remote write 2.0 request: timeseries[0]: symbol reference 42 out of the 10 symbols",
+		},
+		{
+			name:        "truncated",
+			data:        testWriteRequestV2()[:20],
+			expectedErr: "invalid remote write 2.0 request: unexpected EOF",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unmarshalWriteRequestV2(tt.data)
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestUnmarshalHistogram(t *testing.T) {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, 5)
+	b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(12.5))
+	b = protowire.AppendTag(b, 4, protowire.VarintType)
+	b = protowire.AppendVarint(b, protowire.EncodeZigZag(-1))
+	b = protowire.AppendTag(b, 6, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.VarintType)
+	span = protowire.AppendVarint(span, protowire.EncodeZigZag(-2))
+	span = protowire.AppendTag(span, 2, protowire.VarintType)
+	span = protowire.AppendVarint(span, 2)
+	b = protowire.AppendTag(b, 11, protowire.BytesType)
+	b = protowire.AppendBytes(b, span)
+	var deltas []byte
+	deltas = protowire.AppendVarint(deltas, protowire.EncodeZigZag(3))
+	deltas = protowire.AppendVarint(deltas, protowire.EncodeZigZag(-2))
+	b = protowire.AppendTag(b, 12, protowire.BytesType)
+	b = protowire.AppendBytes(b, deltas)
+	b = protowire.AppendTag(b, 14, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(prompb.Histogram_NO))
+	b = protowire.AppendTag(b, 15, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1700000060000)
+
+	h, err := unmarshalHistogram(b)
+	require.NoError(t, err)
+	assert.Equal(t, prompb.Histogram{
+		Count:          &prompb.Histogram_CountInt{CountInt: 5},
+		Sum:            12.5,
+		Schema:         -1,
+		ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 1},
+		PositiveSpans:  []prompb.BucketSpan{{Offset: -2, Length: 2}},
+		PositiveDeltas: []int64{3, -2},
+		ResetHint:      prompb.Histogram_NO,
+		Timestamp:      1700000060000,
+	}, h)
+}
+
+func TestRemoteWriteProto(t *testing.T) {
+	for _, tt := range []struct {
+		contentType string
+		expected    string
+		expectedErr bool
+	}{
+		{contentType: "", expected: protoV1},
+		{contentType: "application/x-protobuf", expected: protoV1},
+		{contentType: "application/x-protobuf;proto=prometheus.WriteRequest", expected: protoV1},
+		{contentType: contentTypeV2, expected: protoV2},
+		{contentType: `application/x-protobuf; proto="io.prometheus.write.v2.Request"`, expected: protoV2},
+		{contentType: "text/plain", expected: protoV1},
+		{contentType: "application/x-protobuf;proto=io.prometheus.write.v3.Request", expectedErr: true},
+	} {
+		t.Run(tt.contentType, func(t *testing.T) {
+			protoMessage, err := remoteWriteProto(tt.contentType)
+			if tt.expectedErr {
+				require.ErrorIs(t, err, errUnsupportedProto)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, protoMessage)
+		})
+	}
+}
+
+func TestCreatedTimestamps(t *testing.T) {
+	req, err := unmarshalWriteRequestV2(testWriteRequestV2())
+	require.NoError(t, err)
+
+	parser := newPrometheusRemoteOtelParser(AttributeLimitsConfig{})
+	md, err := parser.fromWriteRequest(req.WriteRequest, req.createdTimestamps)
+	require.NoError(t, err)
+	counter := findMetric(t, md, "http_requests_total")
+	assert.Equal(t, "Requests served.", counter.Description())
+	assert.Equal(t, "requests", counter.Unit())
+	dp := counter.Sum().DataPoints().At(0)
+	assert.Equal(t, time.UnixMilli(1700000000000).UTC(), dp.StartTimestamp().AsTime())
+	dp = findMetric(t, md, "temperature").Gauge().DataPoints().At(0)
+	assert.Equal(t, dp.Timestamp(), dp.StartTimestamp())
+
+	// created timestamps after the sample are ignored
+	createdTimestamps := []int64{1700000090000, 0}
+	md, err = parser.fromWriteRequest(req.WriteRequest, createdTimestamps)
+	require.NoError(t, err)
+	dp = findMetric(t, md, "http_requests_total").Sum().DataPoints().At(0)
+	assert.Equal(t, dp.Timestamp(), dp.StartTimestamp())
+
+	cb, mc := newTestColumnarBatch(100)
+	require.NoError(t, cb.addWriteRequest(req.WriteRequest, req.createdTimestamps))
+	cb.close(context.Background())
+	md = <-mc
+	dp = findMetric(t, md, "http_requests_total").Sum().DataPoints().At(0)
+	assert.Equal(t, time.UnixMilli(1700000000000).UTC(), dp.StartTimestamp().AsTime())
+	dp = findMetric(t, md, "temperature").Gauge().DataPoints().At(0)
+	assert.Equal(t, dp.Timestamp(), dp.StartTimestamp())
+}
+
+func TestWriteV2Handler(t *testing.T) {
+	var consumed []pmetric.Metrics
+	handler := newHandler(newPrometheusRemoteOtelParser(AttributeLimitsConfig{}), &serverConfig{
+		Reporter: newMockReporter(),
+		Consume: func(_ context.Context, md pmetric.Metrics) error {
+			consumed = append(consumed, md)
+			return nil
+		},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(snappy.Encode(nil, testWriteRequestV2())))
+	req.Header.Set("Content-Type", contentTypeV2)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get(samplesWrittenHeader))
+	assert.Equal(t, "0", rec.Header().Get(histogramsWrittenHeader))
+	assert.Equal(t, "1", rec.Header().Get(exemplarsWrittenHeader))
+	require.Len(t, consumed, 1)
+	assert.Equal(t, "Requests served.", findMetric(t, consumed[0], "http_requests_total").Description())
+
+	// remote write 1.0 responses have no written headers
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "temperature"}},
+		Samples: []prompb.Sample{{Value: 21.5, Timestamp: 1700000060000}},
+	}}})
+	require.NoError(t, err)
+	req = httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(snappy.Encode(nil, data)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Header().Get(samplesWrittenHeader))
+
+	req = httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(snappy.Encode(nil, data)))
+	req.Header.Set("Content-Type", "application/x-protobuf;proto=io.prometheus.write.v3.Request")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(snappy.Encode(nil, []byte{0xff})))
+	req.Header.Set("Content-Type", contentTypeV2)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get(samplesWrittenHeader))
+}
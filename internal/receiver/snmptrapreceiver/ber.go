@@ -0,0 +1,180 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BER tags of the SNMP messages and values.
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagNull        = 0x05
+	tagOID         = 0x06
+	tagSequence    = 0x30
+	tagIPAddress   = 0x40
+	tagCounter32   = 0x41
+	tagGauge32     = 0x42
+	tagTimeTicks   = 0x43
+	tagOpaque      = 0x44
+	tagCounter64   = 0x46
+	tagUInteger32  = 0x47
+
+	tagNoSuchObject   = 0x80
+	tagNoSuchInstance = 0x81
+	tagEndOfMibView   = 0x82
+
+	tagInformRequest = 0xa6
+	tagSNMPv2Trap    = 0xa7
+)
+
+var errTruncated = errors.New("truncated message")
+
+// element is a decoded BER element, whose content starts at offset start of the message.
+type element struct {
+	content []byte
+	start   int
+	tag     byte
+}
+
+// decoder decodes the BER elements of the message between off and end, keeping their offsets in
+// the message so they can be located for authentication.
+type decoder struct {
+	buf []byte
+	off int
+	end int
+}
+
+func newDecoder(buf []byte) *decoder {
+	return &decoder{buf: buf, end: len(buf)}
+}
+
+func (d *decoder) more() bool {
+	return d.off < d.end
+}
+
+func (d *decoder) next() (element, error) {
+	if d.off+2 > d.end {
+		return element{}, errTruncated
+	}
+	tag := d.buf[d.off]
+	length := int(d.buf[d.off+1])
+	start := d.off + 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || start+n > d.end {
+			return element{}, fmt.Errorf("invalid length of element 0x%02x", tag)
+		}
+		length = 0
+		for _, b := range d.buf[start : start+n] {
+			length = length<<8 | int(b)
+		}
+		start += n
+	}
+	if length < 0 || start+length > d.end {
+		return element{}, errTruncated
+	}
+	d.off = start + length
+	return element{tag: tag, start: start, content: d.buf[start:d.off]}, nil
+}
+
+// expect decodes the next element, which must have the tag.
+func (d *decoder) expect(tag byte) (element, error) {
+	e, err := d.next()
+	if err != nil {
+		return e, err
+	}
+	if e.tag != tag {
+		return e, fmt.Errorf("unexpected element 0x%02x instead of 0x%02x", e.tag, tag)
+	}
+	return e, nil
+}
+
+// sub returns a decoder of the content of the element.
+func (d *decoder) sub(e element) *decoder {
+	return &decoder{buf: d.buf, off: e.start, end: e.start + len(e.content)}
+}
+
+func (d *decoder) integer() (int64, error) {
+	e, err := d.expect(tagInteger)
+	if err != nil {
+		return 0, err
+	}
+	return decodeInteger(e.content)
+}
+
+func (d *decoder) octetString() ([]byte, error) {
+	e, err := d.expect(tagOctetString)
+	return e.content, err
+}
+
+func decodeInteger(content []byte) (int64, error) {
+	if len(content) == 0 || len(content) > 8 {
+		return 0, fmt.Errorf("invalid integer length %d", len(content))
+	}
+	// sign extension
+	v := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+func decodeUnsigned(content []byte) (uint64, error) {
+	if len(content) == 0 || len(content) > 9 || len(content) == 9 && content[0] != 0 {
+		return 0, fmt.Errorf("invalid unsigned integer length %d", len(content))
+	}
+	var v uint64
+	for _, b := range content {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// decodeOID returns the dotted form of an object identifier.
+func decodeOID(content []byte) (string, error) {
+	if len(content) == 0 {
+		return "", errors.New("empty object identifier")
+	}
+	var sb strings.Builder
+	var v uint64
+	first := true
+	for i, b := range content {
+		v = v<<7 | uint64(b&0x7f)
+		if b&0x80 != 0 {
+			if i == len(content)-1 || v > 1<<32 {
+				return "", errors.New("invalid object identifier")
+			}
+			continue
+		}
+		if first {
+			// the first subidentifier encodes the first two arcs
+			arc := min(v/40, 2)
+			sb.WriteString(strconv.FormatUint(arc, 10))
+			sb.WriteByte('.')
+			sb.WriteString(strconv.FormatUint(v-arc*40, 10))
+			first = false
+		} else {
+			sb.WriteByte('.')
+			sb.WriteString(strconv.FormatUint(v, 10))
+		}
+		v = 0
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOID(t *testing.T) {
+	for _, tt := range []struct {
+		content     []byte
+		expected    string
+		expectedErr string
+	}{
+		{content: []byte{0x2b, 0x06, 0x01, 0x02, 0x01}, expected: "1.3.6.1.2.1"},
+		{content: []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x86, 0x8d, 0x1f, 0x00}, expected: "1.3.6.1.4.1.99999.0"},
+		{content: []byte{0x88, 0x37, 0x03}, expected: "2.999.3"},
+		{content: []byte{0x2b, 0x86}, expectedErr: "invalid object identifier"},
+		{content: nil, expectedErr: "empty object identifier"},
+	} {
+		oid, err := decodeOID(tt.content)
+		if tt.expectedErr != "" {
+			assert.EqualError(t, err, tt.expectedErr)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, oid)
+	}
+}
+
+func TestDecodeInteger(t *testing.T) {
+	for _, tt := range []struct {
+		content  []byte
+		expected int64
+	}{
+		{content: []byte{0x00}, expected: 0},
+		{content: []byte{0x7f}, expected: 127},
+		{content: []byte{0x00, 0x80}, expected: 128},
+		{content: []byte{0xff}, expected: -1},
+		{content: []byte{0xff, 0x7f}, expected: -129},
+	} {
+		v, err := decodeInteger(tt.content)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, v)
+	}
+	_, err := decodeInteger(make([]byte, 9))
+	assert.Error(t, err)
+}
+
+func TestDecoder(t *testing.T) {
+	content := make([]byte, 200)
+	d := newDecoder(append([]byte{tagOctetString, 0x81, 200}, content...))
+	e, err := d.expect(tagOctetString)
+	require.NoError(t, err)
+	assert.Equal(t, 3, e.start)
+	assert.Len(t, e.content, 200)
+	assert.False(t, d.more())
+
+	_, err = newDecoder([]byte{tagOctetString, 0x81, 200, 0}).next()
+	assert.ErrorIs(t, err, errTruncated)
+	_, err = newDecoder([]byte{tagOctetString, 0x85, 0, 0, 0, 0, 1}).next()
+	assert.EqualError(t, err, "invalid length of element 0x04")
+	_, err = newDecoder([]byte{tagInteger, 1, 1}).expect(tagOctetString)
+	assert.EqualError(t, err, "unexpected element 0x02 instead of 0x04")
+}
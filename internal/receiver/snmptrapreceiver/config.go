@@ -0,0 +1,99 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines where agents send traps to and how they're authenticated and decoded.
+type Config struct {
+	// Endpoint is the UDP address agents send traps to, usually port 162.
+	Endpoint string `mapstructure:"endpoint"`
+	// Communities are the community strings accepted from SNMPv2c agents.
+	Communities []configopaque.String `mapstructure:"communities"`
+	// Users are the SNMPv3 users accepted from SNMPv3 agents.
+	Users []User `mapstructure:"users"`
+	// MIBDirs are the directories of the MIB files used to decode the varbinds of traps.
+	MIBDirs []string `mapstructure:"mib_dirs"`
+}
+
+// User is an SNMPv3 user of the User-based Security Model.
+type User struct {
+	// Name is the security name of the user.
+	Name string `mapstructure:"name"`
+	// AuthProtocol is the authentication protocol of the user: MD5, SHA, SHA224, SHA256, SHA384 or SHA512.
+	AuthProtocol string `mapstructure:"auth_protocol"`
+	// AuthPassword is the authentication password of the user.
+	AuthPassword configopaque.String `mapstructure:"auth_password"`
+	// PrivProtocol is the privacy protocol of the user: DES or AES. It requires an AuthProtocol.
+	PrivProtocol string `mapstructure:"priv_protocol"`
+	// PrivPassword is the privacy password of the user.
+	PrivPassword configopaque.String `mapstructure:"priv_password"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("endpoint must be specified"))
+	}
+	if len(cfg.Communities) == 0 && len(cfg.Users) == 0 {
+		errs = errors.Join(errs, errors.New("at least one of communities or users must be specified"))
+	}
+	names := map[string]bool{}
+	for i, user := range cfg.Users {
+		if err := user.validate(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("users[%d]: %w", i, err))
+		}
+		if names[user.Name] {
+			errs = errors.Join(errs, fmt.Errorf("users[%d]: duplicate user %q", i, user.Name))
+		}
+		names[user.Name] = true
+	}
+	return errs
+}
+
+func (u *User) validate() error {
+	var errs error
+	if u.Name == "" {
+		errs = errors.Join(errs, errors.New("name must be specified"))
+	}
+	if u.AuthProtocol != "" {
+		if _, ok := authProtocols[u.AuthProtocol]; !ok {
+			errs = errors.Join(errs, fmt.Errorf("unsupported auth_protocol %q", u.AuthProtocol))
+		}
+		if len(u.AuthPassword) < 8 {
+			errs = errors.Join(errs, errors.New("auth_password must be at least 8 characters"))
+		}
+	}
+	if u.PrivProtocol != "" {
+		if _, ok := privProtocols[u.PrivProtocol]; !ok {
+			errs = errors.Join(errs, fmt.Errorf("unsupported priv_protocol %q", u.PrivProtocol))
+		}
+		if u.AuthProtocol == "" {
+			errs = errors.Join(errs, errors.New("priv_protocol requires an auth_protocol"))
+		}
+		if len(u.PrivPassword) < 8 {
+			errs = errors.Join(errs, errors.New("priv_password must be at least 8 characters"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id: component.MustNewID(typeStr),
+			expected: &Config{
+				Endpoint:    "localhost:162",
+				Communities: []configopaque.String{"public"},
+			},
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "all_settings"),
+			expected: &Config{
+				Endpoint:    "0.0.0.0:1162",
+				Communities: []configopaque.String{"public", "private"},
+				Users: []User{
+					{
+						Name:         "alice",
+						AuthProtocol: "SHA",
+						AuthPassword: "alice-auth-password",
+						PrivProtocol: "AES",
+						PrivPassword: "alice-priv-password",
+					},
+					{Name: "bob"},
+				},
+				MIBDirs: []string{"/usr/share/snmp/mibs"},
+			},
+		},
+		{
+			id:          component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "endpoint must be specified\nat least one of communities or users must be specified",
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "invalid_users"),
+			expectedErr: "users[0]: unsupported auth_protocol \"SHA1\"\nauth_password must be at least 8 characters\n" +
+				"priv_password must be at least 8 characters\nusers[1]: priv_protocol requires an auth_protocol\n" +
+				"users[1]: duplicate user \"alice\"",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+)
+
+const (
+	snmpVersion2c = 1
+	snmpVersion3  = 3
+
+	usmSecurityModel = 3
+
+	flagAuth = 0x01
+	flagPriv = 0x02
+)
+
+var (
+	errUnknownCommunity = errors.New("unknown community")
+	errUnknownUser      = errors.New("unknown user")
+	errSecurityLevel    = errors.New("security level of the message doesn't match the user's")
+)
+
+// trap is a decoded trap.
+type trap struct {
+	// version is the SNMP version of the trap, i.e. 2c or 3.
+	version string
+	// user is the security name of SNMPv3 traps.
+	user     string
+	varbinds []varbind
+}
+
+// varbind is a variable binding of a trap.
+type varbind struct {
+	// value is an int64, uint64, string or []byte, or nil for a null value.
+	value any
+	oid   string
+	tag   byte
+}
+
+// security authenticates the messages of agents.
+type security struct {
+	communities [][]byte
+	users       map[string]*usmUser
+}
+
+func newSecurity(cfg *Config) *security {
+	s := &security{users: map[string]*usmUser{}}
+	for _, community := range cfg.Communities {
+		s.communities = append(s.communities, []byte(community))
+	}
+	for _, user := range cfg.Users {
+		s.users[user.Name] = newUSMUser(user)
+	}
+	return s
+}
+
+// decode authenticates and decodes a message.
+func (s *security) decode(buf []byte) (*trap, error) {
+	d := newDecoder(buf)
+	msg, err := d.expect(tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	md := d.sub(msg)
+	version, err := md.integer()
+	if err != nil {
+		return nil, err
+	}
+	switch version {
+	case snmpVersion2c:
+		return s.decodeV2c(md)
+	case snmpVersion3:
+		return s.decodeV3(buf[:d.off], md)
+	default:
+		return nil, fmt.Errorf("unsupported SNMP version %d", version)
+	}
+}
+
+func (s *security) decodeV2c(d *decoder) (*trap, error) {
+	community, err := d.octetString()
+	if err != nil {
+		return nil, err
+	}
+	accepted := 0
+	for _, c := range s.communities {
+		accepted |= subtle.ConstantTimeCompare(c, community)
+	}
+	if accepted == 0 {
+		return nil, errUnknownCommunity
+	}
+	varbinds, err := decodePDU(d)
+	if err != nil {
+		return nil, err
+	}
+	return &trap{version: "2c", varbinds: varbinds}, nil
+}
+
+func (s *security) decodeV3(msg []byte, d *decoder) (*trap, error) {
+	global, err := d.expect(tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	gd := d.sub(global)
+	// msgID and msgMaxSize
+	for i := 0; i < 2; i++ {
+		if _, err = gd.integer(); err != nil {
+			return nil, err
+		}
+	}
+	flags, err := gd.octetString()
+	if err != nil {
+		return nil, err
+	}
+	if len(flags) != 1 {
+		return nil, errors.New("invalid message flags")
+	}
+	model, err := gd.integer()
+	if err != nil {
+		return nil, err
+	}
+	if model != usmSecurityModel {
+		return nil, fmt.Errorf("unsupported security model %d", model)
+	}
+
+	securityParams, err := d.expect(tagOctetString)
+	if err != nil {
+		return nil, err
+	}
+	sd := d.sub(securityParams)
+	params, err := sd.expect(tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	pd := sd.sub(params)
+	engineID, err := pd.octetString()
+	if err != nil {
+		return nil, err
+	}
+	engineBoots, err := pd.integer()
+	if err != nil {
+		return nil, err
+	}
+	engineTime, err := pd.integer()
+	if err != nil {
+		return nil, err
+	}
+	userName, err := pd.octetString()
+	if err != nil {
+		return nil, err
+	}
+	authParams, err := pd.expect(tagOctetString)
+	if err != nil {
+		return nil, err
+	}
+	privParams, err := pd.octetString()
+	if err != nil {
+		return nil, err
+	}
+	if len(engineID) < 5 || len(engineID) > 32 {
+		return nil, errors.New("invalid engine ID")
+	}
+	if engineBoots < 0 || engineBoots > math.MaxInt32 || engineTime < 0 || engineTime > math.MaxInt32 {
+		return nil, errors.New("invalid engine boots or time")
+	}
+
+	user, ok := s.users[string(userName)]
+	if !ok {
+		return nil, errUnknownUser
+	}
+	auth, priv := flags[0]&flagAuth != 0, flags[0]&flagPriv != 0
+	if auth != (user.auth != nil) || priv != (user.priv != nil) {
+		return nil, errSecurityLevel
+	}
+	if auth {
+		if err = user.authenticate(msg, authParams, engineID); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	scoped := d
+	if priv {
+		if data.tag != tagOctetString {
+			return nil, errors.New("unexpected unencrypted scoped PDU")
+		}
+		var plaintext []byte
+		plaintext, err = user.decrypt(engineID, privParams, uint32(engineBoots), uint32(engineTime), data.content) //nolint:gosec // validated above
+		if err != nil {
+			return nil, err
+		}
+		// the plaintext may be padded after the scoped PDU
+		scoped = newDecoder(plaintext)
+		if data, err = scoped.next(); err != nil {
+			return nil, errDecryption
+		}
+	}
+	if data.tag != tagSequence {
+		return nil, errors.New("invalid scoped PDU")
+	}
+	spd := scoped.sub(data)
+	// contextEngineID and contextName
+	for i := 0; i < 2; i++ {
+		if _, err = spd.octetString(); err != nil {
+			return nil, err
+		}
+	}
+	varbinds, err := decodePDU(spd)
+	if err != nil {
+		return nil, err
+	}
+	return &trap{version: "3", user: string(userName), varbinds: varbinds}, nil
+}
+
+// decodePDU decodes the variable bindings of an SNMPv2-Trap PDU.
+func decodePDU(d *decoder) ([]varbind, error) {
+	pdu, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	if pdu.tag != tagSNMPv2Trap {
+		if pdu.tag == tagInformRequest {
+			return nil, errors.New("inform requests aren't supported")
+		}
+		return nil, fmt.Errorf("unsupported PDU 0x%02x", pdu.tag)
+	}
+	pd := d.sub(pdu)
+	// request-id, error-status and error-index
+	for i := 0; i < 3; i++ {
+		if _, err = pd.integer(); err != nil {
+			return nil, err
+		}
+	}
+	list, err := pd.expect(tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	ld := pd.sub(list)
+	var varbinds []varbind
+	for ld.more() {
+		vb, err := ld.expect(tagSequence)
+		if err != nil {
+			return nil, err
+		}
+		vd := ld.sub(vb)
+		name, err := vd.expect(tagOID)
+		if err != nil {
+			return nil, err
+		}
+		oid, err := decodeOID(name.content)
+		if err != nil {
+			return nil, err
+		}
+		value, err := vd.next()
+		if err != nil {
+			return nil, err
+		}
+		v, err := decodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", oid, err)
+		}
+		varbinds = append(varbinds, varbind{oid: oid, tag: value.tag, value: v})
+	}
+	return varbinds, nil
+}
+
+func decodeValue(e element) (any, error) {
+	switch e.tag {
+	case tagInteger:
+		return decodeInteger(e.content)
+	case tagOctetString, tagOpaque:
+		return e.content, nil
+	case tagNull:
+		return nil, nil
+	case tagOID:
+		return decodeOID(e.content)
+	case tagIPAddress:
+		if len(e.content) != net.IPv4len {
+			return nil, errors.New("invalid IP address")
+		}
+		return net.IP(e.content).String(), nil
+	case tagCounter32, tagGauge32, tagTimeTicks, tagUInteger32, tagCounter64:
+		return decodeUnsigned(e.content)
+	case tagNoSuchObject:
+		return "noSuchObject", nil
+	case tagNoSuchInstance:
+		return "noSuchInstance", nil
+	case tagEndOfMibView:
+		return "endOfMibView", nil
+	default:
+		return nil, fmt.Errorf("unsupported type 0x%02x", e.tag)
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des" //nolint:gosec
+	"crypto/hmac"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+const (
+	testPortDownOID   = "1.3.6.1.4.1.99999.0.1"
+	testPortNameOID   = "1.3.6.1.4.1.99999.1.1.1.2"
+	testPortStatusOID = "1.3.6.1.4.1.99999.1.1.1.3"
+	testSeverityOID   = "1.3.6.1.4.1.99999.1.2"
+)
+
+func tlv(tag byte, content ...[]byte) []byte {
+	c := bytes.Join(content, nil)
+	var length []byte
+	switch {
+	case len(c) < 0x80:
+		length = []byte{byte(len(c))}
+	case len(c) < 0x100:
+		length = []byte{0x81, byte(len(c))}
+	default:
+		length = []byte{0x82, byte(len(c) >> 8), byte(len(c))}
+	}
+	return append(append([]byte{tag}, length...), c...)
+}
+
+func integer(v int64) []byte {
+	b := binary.BigEndian.AppendUint64(nil, uint64(v))
+	for len(b) > 1 && (b[0] == 0 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0) {
+		b = b[1:]
+	}
+	return tlv(tagInteger, b)
+}
+
+func unsigned(tag byte, v uint64) []byte {
+	b := binary.BigEndian.AppendUint64(nil, v)
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return tlv(tag, b)
+}
+
+func octets(b []byte) []byte {
+	return tlv(tagOctetString, b)
+}
+
+func encodeOID(oid string) []byte {
+	var arcs []uint64
+	for _, arc := range strings.Split(oid, ".") {
+		v, _ := strconv.ParseUint(arc, 10, 32)
+		arcs = append(arcs, v)
+	}
+	var out []byte
+	for _, arc := range append([]uint64{arcs[0]*40 + arcs[1]}, arcs[2:]...) {
+		b := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			b = append([]byte{byte(arc&0x7f | 0x80)}, b...)
+		}
+		out = append(out, b...)
+	}
+	return tlv(tagOID, out)
+}
+
+func vb(oid string, value []byte) []byte {
+	return tlv(tagSequence, encodeOID(oid), value)
+}
+
+func trapPDU(varbinds ...[]byte) []byte {
+	return tlv(tagSNMPv2Trap, integer(42), integer(0), integer(0), tlv(tagSequence, varbinds...))
+}
+
+// testTrapPDU is a trap of the test MIB with a varbind of each type.
+func testTrapPDU() []byte {
+	return trapPDU(
+		vb(sysUpTimeOID, unsigned(tagTimeTicks, 12345)),
+		vb(snmpTrapOIDOID, encodeOID(testPortDownOID)),
+		vb(testPortNameOID+".3", octets([]byte("eth3"))),
+		vb(testPortStatusOID+".3", integer(2)),
+		vb(testSeverityOID+".0", integer(2)),
+		vb("1.3.6.1.4.1.12345.1.0", tlv(tagIPAddress, []byte{10, 0, 0, 1})),
+		vb("1.3.6.1.4.1.12345.2.0", octets([]byte{0x00, 0x1a, 0x2b, 0xff})),
+		vb("1.3.6.1.4.1.12345.3.0", unsigned(tagCounter64, 1<<63)),
+		vb("1.3.6.1.4.1.12345.4.0", integer(-5)),
+		vb("1.3.6.1.4.1.12345.5.0", tlv(tagNull)),
+	)
+}
+
+func v2cMessage(community string, pdu []byte) []byte {
+	return tlv(tagSequence, integer(snmpVersion2c), octets([]byte(community)), pdu)
+}
+
+// v3Message encodes, encrypts and authenticates a message like an agent of the engine ID with
+// the user's security level.
+type v3Message struct {
+	user     User
+	engineID []byte
+	boots    int64
+	time     int64
+}
+
+func (m v3Message) encode(t *testing.T, pdu []byte) []byte {
+	var flags byte
+	msgData := tlv(tagSequence, octets(m.engineID), octets(nil), pdu)
+	var authParams, privParams []byte
+	if m.user.AuthProtocol != "" {
+		flags |= flagAuth
+		authParams = make([]byte, authProtocols[m.user.AuthProtocol].macLen)
+	}
+	if m.user.PrivProtocol != "" {
+		flags |= flagPriv
+		privParams = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+		auth := authProtocols[m.user.AuthProtocol]
+		key := localizeKey(auth.hash, passwordToKey(auth.hash, string(m.user.PrivPassword)), m.engineID)
+		msgData = octets(encrypt(t, m.user.PrivProtocol, key, privParams, uint32(m.boots), uint32(m.time), msgData)) //nolint:gosec
+	}
+	build := func(authParams []byte) []byte {
+		securityParams := tlv(tagSequence, octets(m.engineID), integer(m.boots), integer(m.time),
+			octets([]byte(m.user.Name)), octets(authParams), octets(privParams))
+		return tlv(tagSequence, integer(snmpVersion3),
+			tlv(tagSequence, integer(1), integer(65507), octets([]byte{flags}), integer(usmSecurityModel)),
+			octets(securityParams), msgData)
+	}
+	msg := build(authParams)
+	if flags&flagAuth != 0 {
+		auth := authProtocols[m.user.AuthProtocol]
+		mac := hmac.New(auth.hash, localizeKey(auth.hash, passwordToKey(auth.hash, string(m.user.AuthPassword)), m.engineID))
+		mac.Write(msg)
+		msg = build(mac.Sum(nil)[:auth.macLen])
+	}
+	return msg
+}
+
+func encrypt(t *testing.T, protocol string, key, salt []byte, boots, engineTime uint32, plaintext []byte) []byte {
+	switch protocol {
+	case "DES":
+		block, err := des.NewCipher(key[:8]) //nolint:gosec
+		require.NoError(t, err)
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = key[8+i] ^ salt[i]
+		}
+		padded := append(plaintext, make([]byte, (des.BlockSize-len(plaintext)%des.BlockSize)%des.BlockSize)...)
+		ciphertext := make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+		return ciphertext
+	default:
+		block, err := aes.NewCipher(key[:16])
+		require.NoError(t, err)
+		iv := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, boots), engineTime)
+		iv = append(iv, salt...)
+		ciphertext := make([]byte, len(plaintext))
+		cipher.NewCFBEncrypter(block, iv).XORKeyStream(ciphertext, plaintext)
+		return ciphertext
+	}
+}
+
+var (
+	testEngineID = []byte{0x80, 0x00, 0x1f, 0x88, 0x04, 't', 'e', 's', 't'}
+	alice        = User{Name: "alice", AuthProtocol: "SHA", AuthPassword: "alice-auth-password", PrivProtocol: "AES", PrivPassword: "alice-priv-password"}
+	bob          = User{Name: "bob", AuthProtocol: "MD5", AuthPassword: "bob-auth-password", PrivProtocol: "DES", PrivPassword: "bob-priv-password"}
+	carol        = User{Name: "carol", AuthProtocol: "SHA256", AuthPassword: "carol-auth-password"}
+	dave         = User{Name: "dave"}
+)
+
+func TestDecode(t *testing.T) {
+	s := newSecurity(&Config{
+		Communities: []configopaque.String{"public", "private"},
+		Users:       []User{alice, bob, carol, dave},
+	})
+	pdu := testTrapPDU()
+	wrongPassword := carol
+	wrongPassword.AuthPassword = "wrong-auth-password"
+	noPriv := alice
+	noPriv.PrivProtocol = ""
+	unknown := dave
+	unknown.Name = "eve"
+
+	for _, tt := range []struct {
+		name        string
+		message     []byte
+		version     string
+		user        string
+		expectedErr string
+	}{
+		{
+			name:    "v2c",
+			message: v2cMessage("private", pdu),
+			version: "2c",
+		},
+		{
+			name:        "v2c unknown community",
+			message:     v2cMessage("secret", pdu),
+			expectedErr: errUnknownCommunity.Error(),
+		},
+		{
+			name:    "v3 SHA AES",
+			message: v3Message{user: alice, engineID: testEngineID, boots: 3, time: 1000}.encode(t, pdu),
+			version: "3",
+			user:    "alice",
+		},
+		{
+			name:    "v3 MD5 DES",
+			message: v3Message{user: bob, engineID: testEngineID, boots: 3, time: 1000}.encode(t, pdu),
+			version: "3",
+			user:    "bob",
+		},
+		{
+			name:    "v3 SHA256 without privacy",
+			message: v3Message{user: carol, engineID: testEngineID}.encode(t, pdu),
+			version: "3",
+			user:    "carol",
+		},
+		{
+			name:    "v3 without authentication",
+			message: v3Message{user: dave, engineID: testEngineID}.encode(t, pdu),
+			version: "3",
+			user:    "dave",
+		},
+		{
+			name:        "v3 wrong password",
+			message:     v3Message{user: wrongPassword, engineID: testEngineID}.encode(t, pdu),
+			expectedErr: errAuthentication.Error(),
+		},
+		{
+			name:        "v3 lower security level",
+			message:     v3Message{user: noPriv, engineID: testEngineID}.encode(t, pdu),
+			expectedErr: errSecurityLevel.Error(),
+		},
+		{
+			name:        "v3 unknown user",
+			message:     v3Message{user: unknown, engineID: testEngineID}.encode(t, pdu),
+			expectedErr: errUnknownUser.Error(),
+		},
+		{
+			name:        "v3 invalid engine ID",
+			message:     v3Message{user: dave, engineID: []byte{1}}.encode(t, pdu),
+			expectedErr: "invalid engine ID",
+		},
+		{
+			name:        "v1",
+			message:     tlv(tagSequence, integer(0), octets([]byte("public")), tlv(0xa4)),
+			expectedErr: "unsupported SNMP version 0",
+		},
+		{
+			name:        "inform",
+			message:     v2cMessage("public", tlv(tagInformRequest, integer(1), integer(0), integer(0), tlv(tagSequence))),
+			expectedErr: "inform requests aren't supported",
+		},
+		{
+			name:        "truncated",
+			message:     v2cMessage("public", pdu)[:40],
+			expectedErr: errTruncated.Error(),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			trap, err := s.decode(tt.message)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, trap.version)
+			assert.Equal(t, tt.user, trap.user)
+			assert.Equal(t, []varbind{
+				{oid: sysUpTimeOID, tag: tagTimeTicks, value: uint64(12345)},
+				{oid: snmpTrapOIDOID, tag: tagOID, value: testPortDownOID},
+				{oid: testPortNameOID + ".3", tag: tagOctetString, value: []byte("eth3")},
+				{oid: testPortStatusOID + ".3", tag: tagInteger, value: int64(2)},
+				{oid: testSeverityOID + ".0", tag: tagInteger, value: int64(2)},
+				{oid: "1.3.6.1.4.1.12345.1.0", tag: tagIPAddress, value: "10.0.0.1"},
+				{oid: "1.3.6.1.4.1.12345.2.0", tag: tagOctetString, value: []byte{0x00, 0x1a, 0x2b, 0xff}},
+				{oid: "1.3.6.1.4.1.12345.3.0", tag: tagCounter64, value: uint64(1 << 63)},
+				{oid: "1.3.6.1.4.1.12345.4.0", tag: tagInteger, value: int64(-5)},
+				{oid: "1.3.6.1.4.1.12345.5.0", tag: tagNull},
+			}, trap.varbinds)
+		})
+	}
+}
+
+func TestLocalizeKey(t *testing.T) {
+	// RFC 3414, A.3
+	engineID, err := hex.DecodeString("000000000000000000000002")
+	require.NoError(t, err)
+	for _, tt := range []struct {
+		protocol  string
+		key       string
+		localized string
+	}{
+		{protocol: "MD5", key: "9faf3283884e92834ebc9847d8edd963", localized: "526f5eed9fcce26f8964c2930787d82b"},
+		{protocol: "SHA", key: "9fb5cc0381497b3793528939ff788d5d79145211", localized: "6695febc9288e36282235fc7151f128497b38f3f"},
+	} {
+		t.Run(tt.protocol, func(t *testing.T) {
+			auth := authProtocols[tt.protocol]
+			key := passwordToKey(auth.hash, "maplesyrup")
+			assert.Equal(t, tt.key, hex.EncodeToString(key))
+			assert.Equal(t, tt.localized, hex.EncodeToString(localizeKey(auth.hash, key, engineID)))
+		})
+	}
+}
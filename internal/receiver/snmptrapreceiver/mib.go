@@ -0,0 +1,347 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// mibObject is a named object of a MIB.
+type mibObject struct {
+	// enums are the labels of the values of enumerated integer objects.
+	enums map[int64]string
+	name  string
+}
+
+// mib holds the objects of the loaded MIB files by OID.
+type mib struct {
+	// oids are the OIDs of all the names, including the nodes of the tree that aren't objects.
+	oids    map[string]string
+	objects map[string]*mibObject
+}
+
+// wellKnown are the nodes and objects of SNMPv2-SMI and SNMPv2-MIB that MIB files import and traps
+// refer to, so they don't need to be loaded.
+var wellKnown = []struct {
+	name   string
+	oid    string
+	object bool
+}{
+	{name: "ccitt", oid: "0"},
+	{name: "iso", oid: "1"},
+	{name: "joint-iso-ccitt", oid: "2"},
+	{name: "org", oid: "1.3"},
+	{name: "dod", oid: "1.3.6"},
+	{name: "internet", oid: "1.3.6.1"},
+	{name: "directory", oid: "1.3.6.1.1"},
+	{name: "mgmt", oid: "1.3.6.1.2"},
+	{name: "mib-2", oid: "1.3.6.1.2.1"},
+	{name: "system", oid: "1.3.6.1.2.1.1"},
+	{name: "sysUpTime", oid: "1.3.6.1.2.1.1.3", object: true},
+	{name: "transmission", oid: "1.3.6.1.2.1.10"},
+	{name: "experimental", oid: "1.3.6.1.3"},
+	{name: "private", oid: "1.3.6.1.4"},
+	{name: "enterprises", oid: "1.3.6.1.4.1"},
+	{name: "security", oid: "1.3.6.1.5"},
+	{name: "snmpV2", oid: "1.3.6.1.6"},
+	{name: "snmpDomains", oid: "1.3.6.1.6.1"},
+	{name: "snmpProxys", oid: "1.3.6.1.6.2"},
+	{name: "snmpModules", oid: "1.3.6.1.6.3"},
+	{name: "snmpMIB", oid: "1.3.6.1.6.3.1"},
+	{name: "snmpMIBObjects", oid: "1.3.6.1.6.3.1.1"},
+	{name: "snmpTrap", oid: "1.3.6.1.6.3.1.1.4"},
+	{name: "snmpTrapOID", oid: "1.3.6.1.6.3.1.1.4.1", object: true},
+	{name: "snmpTrapEnterprise", oid: "1.3.6.1.6.3.1.1.4.3", object: true},
+	{name: "snmpTraps", oid: "1.3.6.1.6.3.1.1.5"},
+	{name: "coldStart", oid: "1.3.6.1.6.3.1.1.5.1", object: true},
+	{name: "warmStart", oid: "1.3.6.1.6.3.1.1.5.2", object: true},
+	{name: "linkDown", oid: "1.3.6.1.6.3.1.1.5.3", object: true},
+	{name: "linkUp", oid: "1.3.6.1.6.3.1.1.5.4", object: true},
+	{name: "authenticationFailure", oid: "1.3.6.1.6.3.1.1.5.5", object: true},
+}
+
+// macros are the SMI macros assigning an OID to a name, and whether the name is an object
+// traps may refer to.
+var macros = map[string]bool{
+	"OBJECT-TYPE":        true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-IDENTITY":    true,
+	"MODULE-IDENTITY":    false,
+	"OBJECT-GROUP":       false,
+	"NOTIFICATION-GROUP": false,
+	"MODULE-COMPLIANCE":  false,
+	"AGENT-CAPABILITIES": false,
+}
+
+// definition is the OID assignment of a name in a MIB file.
+type definition struct {
+	enums  map[int64]string
+	parent string
+	// syntax is the type of objects, to look up the enumerations of textual conventions.
+	syntax string
+	arcs   []string
+	object bool
+}
+
+// loadMIB loads the MIB files of the directories. Files are parsed leniently: only the OID
+// assignments and integer enumerations are read, and names whose parent can't be resolved are
+// ignored.
+func loadMIB(dirs []string) (*mib, error) {
+	definitions := map[string]*definition{}
+	conventions := map[string]map[int64]string{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			parseMIB(string(content), definitions, conventions)
+		}
+	}
+
+	m := &mib{oids: map[string]string{}, objects: map[string]*mibObject{}}
+	for _, node := range wellKnown {
+		m.oids[node.name] = node.oid
+		if node.object {
+			m.objects[node.oid] = &mibObject{name: node.name}
+		}
+	}
+	var resolve func(name string, depth int) (string, bool)
+	resolve = func(name string, depth int) (string, bool) {
+		if oid, ok := m.oids[name]; ok {
+			return oid, true
+		}
+		def, ok := definitions[name]
+		// the depth guards against cyclic definitions
+		if !ok || depth > 128 {
+			return "", false
+		}
+		oid := strings.Join(def.arcs, ".")
+		if def.parent != "" {
+			parent, ok := resolve(def.parent, depth+1)
+			if !ok {
+				return "", false
+			}
+			oid = strings.Join(append([]string{parent}, def.arcs...), ".")
+		}
+		m.oids[name] = oid
+		if def.object {
+			enums := def.enums
+			if enums == nil {
+				enums = conventions[def.syntax]
+			}
+			m.objects[oid] = &mibObject{name: name, enums: enums}
+		}
+		return oid, true
+	}
+	for name := range definitions {
+		resolve(name, 0)
+	}
+	return m, nil
+}
+
+// lookup returns the object of the OID, or of the longest prefix of the OID with the remaining
+// arcs as instance index, e.g. the ifDescr object and the 2 index for 1.3.6.1.2.1.2.2.1.2.2.
+func (m *mib) lookup(oid string) (*mibObject, string) {
+	for prefix := oid; ; {
+		if object, ok := m.objects[prefix]; ok {
+			return object, strings.TrimPrefix(oid[len(prefix):], ".")
+		}
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			return nil, ""
+		}
+		prefix = prefix[:i]
+	}
+}
+
+// name returns the name of the OID with its index, e.g. ifDescr.2, or the OID if it isn't known.
+// Scalar objects, whose index is 0, are named without it.
+func (m *mib) name(oid string) string {
+	object, index := m.lookup(oid)
+	switch {
+	case object == nil:
+		return oid
+	case index == "" || index == "0":
+		return object.name
+	default:
+		return object.name + "." + index
+	}
+}
+
+// parseMIB adds the OID assignments and the enumerations of the textual conventions of a MIB
+// file to the definitions and conventions.
+func parseMIB(content string, definitions map[string]*definition, conventions map[string]map[int64]string) {
+	tokens := tokenize(content)
+	for i := 0; i+1 < len(tokens); i++ {
+		name, next := tokens[i], tokens[i+1]
+		switch {
+		case isValueName(name) && next == "OBJECT" && i+3 < len(tokens) && tokens[i+2] == "IDENTIFIER" && tokens[i+3] == "::=":
+			def, end := parseDefinition(tokens, i+3)
+			if def != nil {
+				definitions[name] = def
+			}
+			i = end
+		case isValueName(name):
+			object, ok := macros[next]
+			if !ok {
+				continue
+			}
+			def, end := parseDefinition(tokens, i+1)
+			if def != nil {
+				def.object = object
+				definitions[name] = def
+			}
+			i = end
+		case isTypeName(name) && next == "::=":
+			enums, end := parseTypeAssignment(tokens, i+2)
+			if enums != nil {
+				conventions[name] = enums
+			}
+			i = end
+		}
+	}
+}
+
+// parseDefinition parses a definition from its macro to the end of its OID value.
+func parseDefinition(tokens []string, i int) (*definition, int) {
+	def := &definition{}
+	for ; i < len(tokens) && tokens[i] != "::="; i++ {
+		if tokens[i] == "SYNTAX" && def.syntax == "" && def.enums == nil && i+1 < len(tokens) {
+			def.syntax, def.enums, i = parseSyntax(tokens, i+1)
+		}
+	}
+	if i+1 >= len(tokens) || tokens[i+1] != "{" {
+		return nil, i
+	}
+	for i += 2; i < len(tokens) && tokens[i] != "}"; i++ {
+		arc := tokens[i]
+		// name(number) arcs
+		if i+3 < len(tokens) && tokens[i+1] == "(" && tokens[i+3] == ")" {
+			arc = tokens[i+2]
+			i += 3
+		}
+		if _, err := strconv.ParseUint(arc, 10, 32); err == nil {
+			def.arcs = append(def.arcs, arc)
+		} else if def.parent == "" && len(def.arcs) == 0 {
+			def.parent = arc
+		} else {
+			return nil, i
+		}
+	}
+	if len(def.arcs) == 0 {
+		return nil, i
+	}
+	return def, i
+}
+
+// parseTypeAssignment parses the enumerations of textual conventions and integer types.
+func parseTypeAssignment(tokens []string, i int) (map[int64]string, int) {
+	if i >= len(tokens) {
+		return nil, i
+	}
+	if tokens[i] == "TEXTUAL-CONVENTION" {
+		for ; i < len(tokens) && tokens[i] != "SYNTAX"; i++ {
+		}
+		i++
+	}
+	if i >= len(tokens) {
+		return nil, i
+	}
+	_, enums, end := parseSyntax(tokens, i)
+	return enums, end
+}
+
+// parseSyntax returns the type name of a syntax, or its enumerations if it's an enumerated
+// integer.
+func parseSyntax(tokens []string, i int) (string, map[int64]string, int) {
+	if tokens[i] != "INTEGER" || i+1 >= len(tokens) || tokens[i+1] != "{" {
+		return tokens[i], nil, i
+	}
+	enums := map[int64]string{}
+	for i += 2; i < len(tokens) && tokens[i] != "}"; i++ {
+		if i+3 < len(tokens) && tokens[i+1] == "(" && tokens[i+3] == ")" {
+			if v, err := strconv.ParseInt(tokens[i+2], 10, 64); err == nil {
+				enums[v] = tokens[i]
+			}
+			i += 3
+		}
+	}
+	return "", enums, i
+}
+
+// tokenize splits the content of a MIB file into words, strings, "::=" and single characters,
+// skipping comments.
+func tokenize(content string) []string {
+	var tokens []string
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(content[i:], "--"):
+			// comments end at the end of the line or with "--"
+			i += 2
+			for i < len(content) && content[i] != '\n' && !strings.HasPrefix(content[i:], "--") {
+				i++
+			}
+			if strings.HasPrefix(content[i:], "--") {
+				i += 2
+			}
+		case c == '"':
+			end := strings.IndexByte(content[i+1:], '"')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, content[i:i+end+2])
+			i += end + 2
+		case strings.HasPrefix(content[i:], "::="):
+			tokens = append(tokens, "::=")
+			i += 3
+		case isWordChar(c):
+			start := i
+			for i < len(content) && isWordChar(content[i]) && !strings.HasPrefix(content[i:], "--") {
+				i++
+			}
+			tokens = append(tokens, content[start:i])
+		default:
+			tokens = append(tokens, content[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func isValueName(token string) bool {
+	return token[0] >= 'a' && token[0] <= 'z'
+}
+
+func isTypeName(token string) bool {
+	return token[0] >= 'A' && token[0] <= 'Z'
+}
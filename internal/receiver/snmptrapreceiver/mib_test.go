@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMIB(t *testing.T) {
+	m, err := loadMIB([]string{filepath.Join("testdata", "mibs")})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.3.6.1.4.1.99999", m.oids["testMIB"])
+	assert.Equal(t, testPortNameOID, m.oids["testPortName"])
+	assert.NotContains(t, m.oids, "testPortSpecific")
+	assert.NotContains(t, m.oids, "testOrphan")
+
+	for _, tt := range []struct {
+		oid      string
+		expected string
+	}{
+		{oid: testPortDownOID, expected: "testPortDown"},
+		{oid: testPortNameOID + ".3", expected: "testPortName.3"},
+		{oid: testSeverityOID + ".0", expected: "testSeverity"},
+		{oid: "1.3.6.1.6.3.1.1.5.3", expected: "linkDown"},
+		{oid: "1.3.6.1.2.1.1.3.0", expected: "sysUpTime"},
+		// nodes that aren't objects
+		{oid: "1.3.6.1.4.1.99999.1", expected: "1.3.6.1.4.1.99999.1"},
+		{oid: "1.3.6.1.4.1.12345.1.0", expected: "1.3.6.1.4.1.12345.1.0"},
+	} {
+		assert.Equal(t, tt.expected, m.name(tt.oid))
+	}
+
+	status, _ := m.lookup(testPortStatusOID + ".3")
+	require.NotNil(t, status)
+	assert.Equal(t, map[int64]string{1: "up", 2: "down", 3: "testing"}, status.enums)
+	severity, index := m.lookup(testSeverityOID + ".0")
+	require.NotNil(t, severity)
+	assert.Equal(t, "0", index)
+	assert.Equal(t, map[int64]string{0: "info", 1: "warning", 2: "critical"}, severity.enums)
+
+	_, err = loadMIB([]string{filepath.Join("testdata", "missing")})
+	assert.Error(t, err)
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t,
+		[]string{"a", "OBJECT-TYPE", `"x -- y"`, "::=", "{", "b", "1", "}", "c", "(", "-1", ")"},
+		tokenize("a OBJECT-TYPE -- comment\n \"x -- y\" -- inline -- ::= { b 1 }\nc(-1)"),
+	)
+}
@@ -0,0 +1,228 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+const (
+	sysUpTimeOID   = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOIDOID = "1.3.6.1.6.3.1.1.4.1.0"
+
+	versionAttr   = "snmp.version"
+	userAttr      = "snmp.user"
+	trapOIDAttr   = "snmp.trap.oid"
+	trapNameAttr  = "snmp.trap.name"
+	uptimeAttr    = "snmp.uptime"
+	varbindsAttr  = "snmp.varbinds"
+	peerIPAttr    = "net.peer.ip"
+	peerPortAttr  = "net.peer.port"
+	maxPacketSize = 65535
+)
+
+var _ receiver.Logs = (*snmpTrapReceiver)(nil)
+
+// snmpTrapReceiver converts the traps sent by SNMP agents to log records.
+type snmpTrapReceiver struct {
+	consumer consumer.Logs
+	config   *Config
+	security *security
+	mib      *mib
+	conn     net.PacketConn
+	logger   *zap.Logger
+	now      func() time.Time
+	wg       sync.WaitGroup
+}
+
+func newReceiver(settings receiver.Settings, config *Config, consumer consumer.Logs) *snmpTrapReceiver {
+	return &snmpTrapReceiver{
+		consumer: consumer,
+		config:   config,
+		logger:   settings.Logger,
+		now:      time.Now,
+	}
+}
+
+func (r *snmpTrapReceiver) Start(context.Context, component.Host) error {
+	m, err := loadMIB(r.config.MIBDirs)
+	if err != nil {
+		return err
+	}
+	r.mib = m
+	r.logger.Debug("Loaded MIB objects", zap.Int("objects", len(m.objects)))
+	// deriving the keys of SNMPv3 users hashes a megabyte per password
+	r.security = newSecurity(r.config)
+
+	conn, err := net.ListenPacket("udp", r.config.Endpoint)
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+	r.wg.Add(1)
+	go r.serve()
+	return nil
+}
+
+func (r *snmpTrapReceiver) Shutdown(context.Context) error {
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.wg.Wait()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (r *snmpTrapReceiver) serve() {
+	defer r.wg.Done()
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			r.logger.Warn("Failed reading trap", zap.Error(err))
+			continue
+		}
+		t, err := r.security.decode(buf[:n])
+		if err != nil {
+			r.logger.Debug("Dropped trap", zap.Stringer("remote", addr), zap.Error(err))
+			continue
+		}
+		if err = r.consumer.ConsumeLogs(context.Background(), r.logs(t, addr)); err != nil {
+			r.logger.Error("Failed consuming trap", zap.Error(err))
+		}
+	}
+}
+
+// logs converts a trap to a log record whose body is the name of the trap.
+func (r *snmpTrapReceiver) logs(t *trap, addr net.Addr) plog.Logs {
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(r.now()))
+	attrs := lr.Attributes()
+	attrs.PutStr(versionAttr, t.version)
+	if t.user != "" {
+		attrs.PutStr(userAttr, t.user)
+	}
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		attrs.PutStr(peerIPAttr, udpAddr.IP.String())
+		attrs.PutInt(peerPortAttr, int64(udpAddr.Port))
+	}
+	varbinds := attrs.PutEmptyMap(varbindsAttr)
+	for _, vb := range t.varbinds {
+		switch vb.oid {
+		case sysUpTimeOID:
+			if ticks, ok := vb.value.(uint64); ok && vb.tag == tagTimeTicks && ticks <= math.MaxUint32 {
+				attrs.PutInt(uptimeAttr, int64(ticks))
+				continue
+			}
+		case snmpTrapOIDOID:
+			if oid, ok := vb.value.(string); ok && vb.tag == tagOID {
+				name := r.mib.name(oid)
+				attrs.PutStr(trapOIDAttr, oid)
+				attrs.PutStr(trapNameAttr, name)
+				lr.Body().SetStr(name)
+				continue
+			}
+		}
+		r.putValue(varbinds, vb)
+	}
+	return logs
+}
+
+// putValue puts the value of the varbind in the map, by the name of its object. Enumerated
+// integers are put as their label, and object identifiers as their name.
+func (r *snmpTrapReceiver) putValue(m pcommon.Map, vb varbind) {
+	object, _ := r.mib.lookup(vb.oid)
+	key := r.mib.name(vb.oid)
+	switch v := vb.value.(type) {
+	case int64:
+		if label, ok := object.enum(v); ok {
+			m.PutStr(key, label)
+		} else {
+			m.PutInt(key, v)
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			m.PutInt(key, int64(v)) //nolint:gosec
+		} else {
+			m.PutStr(key, strconv.FormatUint(v, 10))
+		}
+	case string:
+		if vb.tag == tagOID {
+			v = r.mib.name(v)
+		}
+		m.PutStr(key, v)
+	case []byte:
+		m.PutStr(key, formatOctets(v))
+	default:
+		m.PutEmpty(key)
+	}
+}
+
+func (o *mibObject) enum(v int64) (string, bool) {
+	if o == nil {
+		return "", false
+	}
+	label, ok := o.enums[v]
+	return label, ok
+}
+
+// formatOctets returns printable octet strings as is, without trailing NUL characters, and
+// others as colon separated hex, e.g. MAC addresses.
+func formatOctets(octets []byte) string {
+	s := strings.TrimRight(string(octets), "\x00")
+	printable := utf8.ValidString(s)
+	for _, c := range s {
+		if !printable {
+			break
+		}
+		printable = unicode.IsPrint(c) || unicode.IsSpace(c)
+	}
+	if printable {
+		return s
+	}
+	encoded := hex.EncodeToString(octets)
+	var sb strings.Builder
+	for i := 0; i < len(encoded); i += 2 {
+		if i > 0 {
+			sb.WriteByte(':')
+		}
+		sb.WriteString(encoded[i : i+2])
+	}
+	return sb.String()
+}
@@ -0,0 +1,89 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestReceiver(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	cfg := &Config{
+		Endpoint:    "localhost:0",
+		Communities: []configopaque.String{"public"},
+		Users:       []User{alice},
+		MIBDirs:     []string{filepath.Join("testdata", "mibs")},
+	}
+	r := newReceiver(receivertest.NewNopSettings(), cfg, sink)
+	now := time.Unix(1700000000, 0)
+	r.now = func() time.Time { return now }
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, r.Shutdown(context.Background())) }()
+
+	conn, err := net.Dial("udp", r.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	for _, msg := range [][]byte{
+		v2cMessage("secret", testTrapPDU()),
+		v2cMessage("public", testTrapPDU()),
+		v3Message{user: alice, engineID: testEngineID, boots: 1, time: 10}.encode(t, testTrapPDU()),
+	} {
+		_, err = conn.Write(msg)
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	for i, version := range []string{"2c", "3"} {
+		lr := sink.AllLogs()[i].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		assert.Equal(t, "testPortDown", lr.Body().Str())
+		assert.Equal(t, pcommon.NewTimestampFromTime(now), lr.ObservedTimestamp())
+		attrs := lr.Attributes().AsRaw()
+		assert.Equal(t, version, attrs[versionAttr])
+		assert.Equal(t, testPortDownOID, attrs[trapOIDAttr])
+		assert.Equal(t, "testPortDown", attrs[trapNameAttr])
+		assert.Equal(t, int64(12345), attrs[uptimeAttr])
+		assert.Equal(t, "127.0.0.1", attrs[peerIPAttr])
+		assert.Equal(t, map[string]any{
+			"testPortName.3":        "eth3",
+			"testPortStatus.3":      "down",
+			"testSeverity":          "critical",
+			"1.3.6.1.4.1.12345.1.0": "10.0.0.1",
+			"1.3.6.1.4.1.12345.2.0": "00:1a:2b:ff",
+			"1.3.6.1.4.1.12345.3.0": "9223372036854775808",
+			"1.3.6.1.4.1.12345.4.0": int64(-5),
+			"1.3.6.1.4.1.12345.5.0": nil,
+		}, attrs[varbindsAttr])
+	}
+	assert.Equal(t, "alice", sink.AllLogs()[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw()[userAttr])
+}
+
+func TestFormatOctets(t *testing.T) {
+	assert.Equal(t, "eth0", formatOctets([]byte("eth0\x00")))
+	assert.Equal(t, "port 1\tup", formatOctets([]byte("port 1\tup")))
+	assert.Equal(t, "00:1a:2b", formatOctets([]byte{0x00, 0x1a, 0x2b}))
+	assert.Equal(t, "ff:fe", formatOctets([]byte{0xff, 0xfe}))
+}
@@ -0,0 +1,166 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des" //nolint:gosec // DES is an SNMPv3 privacy protocol
+	"crypto/hmac"
+	"crypto/md5"  //nolint:gosec // MD5 is an SNMPv3 authentication protocol
+	"crypto/sha1" //nolint:gosec // SHA-1 is an SNMPv3 authentication protocol
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+var (
+	errAuthentication = errors.New("authentication failed")
+	errDecryption     = errors.New("decryption failed")
+)
+
+// authProtocol is a User-based Security Model authentication protocol (RFC 3414 and RFC 7860).
+type authProtocol struct {
+	hash func() hash.Hash
+	// macLen is the length of the truncated HMAC of the messages.
+	macLen int
+}
+
+var authProtocols = map[string]authProtocol{
+	"MD5":    {hash: md5.New, macLen: 12},
+	"SHA":    {hash: sha1.New, macLen: 12},
+	"SHA224": {hash: sha256.New224, macLen: 16},
+	"SHA256": {hash: sha256.New, macLen: 24},
+	"SHA384": {hash: sha512.New384, macLen: 32},
+	"SHA512": {hash: sha512.New, macLen: 48},
+}
+
+// privProtocol is a User-based Security Model privacy protocol (RFC 3414 and RFC 3826).
+type privProtocol struct {
+	decrypt func(key, salt []byte, engineBoots, engineTime uint32, ciphertext []byte) ([]byte, error)
+}
+
+var privProtocols = map[string]privProtocol{
+	"DES": {decrypt: decryptDES},
+	"AES": {decrypt: decryptAES},
+}
+
+// usmUser authenticates and decrypts the messages of an SNMPv3 user.
+type usmUser struct {
+	auth *authProtocol
+	priv *privProtocol
+	// authKey and privKey are the keys derived from the passwords, which are localized to the
+	// engine ID of each message.
+	authKey []byte
+	privKey []byte
+}
+
+func newUSMUser(user User) *usmUser {
+	u := &usmUser{}
+	if user.AuthProtocol == "" {
+		return u
+	}
+	auth := authProtocols[user.AuthProtocol]
+	u.auth = &auth
+	u.authKey = passwordToKey(auth.hash, string(user.AuthPassword))
+	if user.PrivProtocol != "" {
+		priv := privProtocols[user.PrivProtocol]
+		u.priv = &priv
+		u.privKey = passwordToKey(auth.hash, string(user.PrivPassword))
+	}
+	return u
+}
+
+// authenticate verifies the HMAC of the message, whose authentication parameters are zeroed to
+// compute it.
+func (u *usmUser) authenticate(msg []byte, authParams element, engineID []byte) error {
+	if len(authParams.content) != u.auth.macLen {
+		return errAuthentication
+	}
+	zeroed := append([]byte(nil), msg...)
+	clear(zeroed[authParams.start : authParams.start+len(authParams.content)])
+	mac := hmac.New(u.auth.hash, localizeKey(u.auth.hash, u.authKey, engineID))
+	mac.Write(zeroed)
+	if !hmac.Equal(mac.Sum(nil)[:u.auth.macLen], authParams.content) {
+		return errAuthentication
+	}
+	return nil
+}
+
+func (u *usmUser) decrypt(engineID, salt []byte, engineBoots, engineTime uint32, ciphertext []byte) ([]byte, error) {
+	return u.priv.decrypt(localizeKey(u.auth.hash, u.privKey, engineID), salt, engineBoots, engineTime, ciphertext)
+}
+
+// passwordToKey derives a key from the password by hashing a megabyte of its repetitions
+// (RFC 3414, A.2).
+func passwordToKey(newHash func() hash.Hash, password string) []byte {
+	h := newHash()
+	var chunk [64]byte
+	for i := 0; i < 1<<20; i += len(chunk) {
+		for j := range chunk {
+			chunk[j] = password[(i+j)%len(password)]
+		}
+		h.Write(chunk[:])
+	}
+	return h.Sum(nil)
+}
+
+// localizeKey localizes the key to the authoritative engine ID, i.e. the one of the sending agent
+// for traps.
+func localizeKey(newHash func() hash.Hash, key, engineID []byte) []byte {
+	h := newHash()
+	h.Write(key)
+	h.Write(engineID)
+	h.Write(key)
+	return h.Sum(nil)
+}
+
+// decryptDES decrypts CBC-DES scoped PDUs (RFC 3414, 8.1.1).
+func decryptDES(key, salt []byte, _, _ uint32, ciphertext []byte) ([]byte, error) {
+	if len(key) < 16 || len(salt) != des.BlockSize || len(ciphertext)%des.BlockSize != 0 {
+		return nil, errDecryption
+	}
+	block, err := des.NewCipher(key[:8]) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, des.BlockSize)
+	for i := range iv {
+		iv[i] = key[8+i] ^ salt[i]
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+	return plaintext, nil
+}
+
+// decryptAES decrypts CFB128-AES-128 scoped PDUs (RFC 3826).
+func decryptAES(key, salt []byte, engineBoots, engineTime uint32, ciphertext []byte) ([]byte, error) {
+	if len(key) < 16 || len(salt) != 8 {
+		return nil, errDecryption
+	}
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint32(iv, engineBoots)
+	binary.BigEndian.PutUint32(iv[4:], engineTime)
+	copy(iv[8:], salt)
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCFBDecrypter(block, iv).XORKeyStream(plaintext, ciphertext)
+	return plaintext, nil
+}
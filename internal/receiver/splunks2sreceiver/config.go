@@ -0,0 +1,45 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunks2sreceiver
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines where forwarders connect to the receiver.
+type Config struct {
+	// TLS configures the listener to accept forwarders using SSL.
+	TLS *configtls.ServerConfig `mapstructure:"tls"`
+	// Endpoint is the address forwarders send data to, usually port 9997.
+	Endpoint string `mapstructure:"endpoint"`
+	// MaxMessageSize is the largest message accepted from forwarders, in bytes.
+	MaxMessageSize int `mapstructure:"max_message_size"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("endpoint must be specified"))
+	}
+	if cfg.MaxMessageSize <= 0 {
+		errs = errors.Join(errs, errors.New("max_message_size must be positive"))
+	}
+	return errs
+}
@@ -0,0 +1,176 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunks2sreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// The signature sent by forwarders when connecting consists of the null padded
+	// protocol signature, server name and management port.
+	signatureSize      = 128
+	serverNameSize     = 256
+	managementPortSize = 16
+	handshakeSize      = signatureSize + serverNameSize + managementPortSize
+
+	signaturePrefix = "--splunk-cooked-mode-v"
+
+	capabilitiesKey = "__s2s_capabilities"
+	controlMsgKey   = "__s2s_control_msg"
+	// capabilitiesResponse accepts the forwarder's connection without acknowledgements or
+	// compression. Forwarders supporting S2S v4 fall back to the v3 message format when
+	// the receiver doesn't advertise it.
+	capabilitiesResponse = "cap_response=success;cap_flush_key=true;idx_can_send_hb=true;idx_can_recv_token=true;v4=false;channel_limit=300"
+
+	rawKey         = "_raw"
+	timeKey        = "_time"
+	subsecondKey   = "_subsecond"
+	channelKey     = "_channel"
+	doneKey        = "_done"
+	lineBreakerKey = "_linebreaker"
+	hostKey        = "MetaData:Host"
+	sourceKey      = "MetaData:Source"
+	sourcetypeKey  = "MetaData:Sourcetype"
+	indexKey       = "_MetaData:Index"
+)
+
+var errMessageTooLarge = errors.New("message exceeds max_message_size")
+
+// handshake is the signature sent by a forwarder when connecting.
+type handshake struct {
+	Signature      string
+	ServerName     string
+	ManagementPort string
+}
+
+// readHandshake reads and validates the forwarder's signature. Cooked mode v2 and v3
+// signatures use the same message format and are both accepted.
+func readHandshake(r io.Reader) (handshake, error) {
+	var buf [handshakeSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return handshake{}, fmt.Errorf("failed reading signature: %w", err)
+	}
+	hs := handshake{
+		Signature:      nullTerminated(buf[:signatureSize]),
+		ServerName:     nullTerminated(buf[signatureSize : signatureSize+serverNameSize]),
+		ManagementPort: nullTerminated(buf[signatureSize+serverNameSize:]),
+	}
+	version := strings.TrimSuffix(strings.TrimPrefix(hs.Signature, signaturePrefix), "--")
+	if !strings.HasPrefix(hs.Signature, signaturePrefix) || (version != "2" && version != "3") {
+		return handshake{}, fmt.Errorf("unsupported signature %q", hs.Signature)
+	}
+	return hs, nil
+}
+
+func nullTerminated(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// message is a decoded S2S message, whose fields are null terminated key value pairs.
+type message map[string]string
+
+// readMessage reads a length prefixed message consisting of the number of fields followed
+// by the length prefixed keys and values. The trailer following the fields is ignored.
+func readMessage(r *bufio.Reader, maxSize int) (message, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if int64(size) > int64(maxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", errMessageTooLarge, size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("failed reading message: %w", err)
+	}
+
+	d := decoder{buf: buf}
+	count := d.uint32()
+	msg := make(message, min(int(count), 64))
+	for i := uint32(0); i < count && d.err == nil; i++ {
+		key := d.string()
+		msg[key] = d.string()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return msg, nil
+}
+
+type decoder struct {
+	err error
+	buf []byte
+}
+
+func (d *decoder) uint32() uint32 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.buf) < 4 {
+		d.err = errors.New("truncated message")
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.buf)
+	d.buf = d.buf[4:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.uint32()
+	if d.err != nil {
+		return ""
+	}
+	if uint64(len(d.buf)) < uint64(n) {
+		d.err = errors.New("truncated message")
+		return ""
+	}
+	s := nullTerminated(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+// encodeMessage encodes the fields in the order given, followed by the "_raw" trailer.
+func encodeMessage(fields ...[2]string) []byte {
+	var body bytes.Buffer
+	writeUint32 := func(v uint32) {
+		_ = binary.Write(&body, binary.BigEndian, v)
+	}
+	writeString := func(s string) {
+		writeUint32(uint32(len(s) + 1))
+		body.WriteString(s)
+		body.WriteByte(0)
+	}
+	writeUint32(uint32(len(fields)))
+	for _, f := range fields {
+		writeString(f[0])
+		writeString(f[1])
+	}
+	writeUint32(0)
+	writeString(rawKey)
+
+	out := make([]byte, 4, 4+body.Len())
+	binary.BigEndian.PutUint32(out, uint32(body.Len()))
+	return append(out, body.Bytes()...)
+}
@@ -0,0 +1,75 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunks2sreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signature(sig, serverName, port string) []byte {
+	buf := make([]byte, handshakeSize)
+	copy(buf, sig)
+	copy(buf[signatureSize:], serverName)
+	copy(buf[signatureSize+serverNameSize:], port)
+	return buf
+}
+
+func TestReadHandshake(t *testing.T) {
+	hs, err := readHandshake(bytes.NewReader(signature("--splunk-cooked-mode-v3--", "uf-host", "8089")))
+	require.NoError(t, err)
+	assert.Equal(t, handshake{Signature: "--splunk-cooked-mode-v3--", ServerName: "uf-host", ManagementPort: "8089"}, hs)
+
+	_, err = readHandshake(bytes.NewReader(signature("--splunk-cooked-mode-v2--", "uf-host", "8089")))
+	require.NoError(t, err)
+
+	_, err = readHandshake(bytes.NewReader(signature("GET / HTTP/1.1", "", "")))
+	require.EqualError(t, err, `unsupported signature "GET / HTTP/1.1"`)
+
+	_, err = readHandshake(bytes.NewReader([]byte("--splunk-cooked-mode-v3--")))
+	require.EqualError(t, err, "failed reading signature: unexpected EOF")
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	encoded := encodeMessage(
+		[2]string{rawKey, "a line\n"},
+		[2]string{hostKey, "host::uf-host"},
+		[2]string{channelKey, "42"},
+	)
+	msg, err := readMessage(bufio.NewReader(bytes.NewReader(encoded)), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, message{rawKey: "a line\n", hostKey: "host::uf-host", channelKey: "42"}, msg)
+
+	_, err = readMessage(bufio.NewReader(bytes.NewReader(encoded)), 10)
+	require.ErrorIs(t, err, errMessageTooLarge)
+}
+
+func TestReadTruncatedMessage(t *testing.T) {
+	// a message claiming two fields but containing only one
+	var buf bytes.Buffer
+	body := encodeMessage([2]string{rawKey, "event"})[4:]
+	binary.BigEndian.PutUint32(body, 2)
+	body = body[:len(body)-13] // drop the trailer
+	require.NoError(t, binary.Write(&buf, binary.BigEndian, uint32(len(body))))
+	buf.Write(body)
+
+	_, err := readMessage(bufio.NewReader(&buf), 1024)
+	require.EqualError(t, err, "truncated message")
+}
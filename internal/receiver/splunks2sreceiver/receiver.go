@@ -0,0 +1,311 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunks2sreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+const (
+	hostNameAttr   = "host.name"
+	sourceAttr     = "com.splunk.source"
+	sourcetypeAttr = "com.splunk.sourcetype"
+	indexAttr      = "com.splunk.index"
+)
+
+var _ receiver.Logs = (*s2sReceiver)(nil)
+
+// s2sReceiver accepts cooked data from Splunk forwarders over the S2S protocol.
+type s2sReceiver struct {
+	consumer consumer.Logs
+	config   *Config
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	logger   *zap.Logger
+	now      func() time.Time
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+func newReceiver(settings receiver.Settings, config *Config, consumer consumer.Logs) *s2sReceiver {
+	return &s2sReceiver{
+		consumer: consumer,
+		config:   config,
+		conns:    map[net.Conn]struct{}{},
+		logger:   settings.Logger,
+		now:      time.Now,
+	}
+}
+
+func (r *s2sReceiver) Start(ctx context.Context, _ component.Host) error {
+	listener, err := net.Listen("tcp", r.config.Endpoint)
+	if err != nil {
+		return err
+	}
+	if r.config.TLS != nil {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = r.config.TLS.LoadTLSConfig(ctx); err != nil {
+			_ = listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	r.listener = listener
+	r.wg.Add(1)
+	go r.accept()
+	return nil
+}
+
+func (r *s2sReceiver) Shutdown(context.Context) error {
+	var err error
+	if r.listener != nil {
+		err = r.listener.Close()
+	}
+	r.mu.Lock()
+	for conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (r *s2sReceiver) accept() {
+	defer r.wg.Done()
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				r.logger.Error("Failed accepting forwarder connection", zap.Error(err))
+			}
+			return
+		}
+		r.mu.Lock()
+		r.conns[conn] = struct{}{}
+		r.mu.Unlock()
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.handle(conn)
+			r.mu.Lock()
+			delete(r.conns, conn)
+			r.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// handle reads the messages of a forwarder connection until it's closed.
+func (r *s2sReceiver) handle(conn net.Conn) {
+	logger := r.logger.With(zap.String("remote", conn.RemoteAddr().String()))
+	reader := bufio.NewReader(conn)
+	hs, err := readHandshake(reader)
+	if err != nil {
+		logger.Warn("Rejected forwarder connection", zap.Error(err))
+		return
+	}
+	logger = logger.With(zap.String("forwarder", hs.ServerName))
+	logger.Debug("Forwarder connected", zap.String("signature", hs.Signature))
+
+	c := &connection{handshake: hs, channels: map[string]*channel{}, maxSize: r.config.MaxMessageSize, now: r.now}
+	for {
+		msg, err := readMessage(reader, r.config.MaxMessageSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				logger.Warn("Failed reading forwarder message", zap.Error(err))
+			}
+			break
+		}
+		if _, ok := msg[capabilitiesKey]; ok {
+			if _, err = conn.Write(encodeMessage([2]string{controlMsgKey, capabilitiesResponse})); err != nil {
+				logger.Warn("Failed responding to forwarder capabilities", zap.Error(err))
+				break
+			}
+			continue
+		}
+		r.consume(logger, c.logs(msg))
+	}
+	// the forwarder may not have ended its channels before disconnecting
+	r.consume(logger, c.flush())
+}
+
+func (r *s2sReceiver) consume(logger *zap.Logger, logs plog.Logs) {
+	if logs.LogRecordCount() == 0 {
+		return
+	}
+	if err := r.consumer.ConsumeLogs(context.Background(), logs); err != nil {
+		logger.Error("Failed consuming forwarder events", zap.Error(err))
+	}
+}
+
+// metadata are the fields of the events of a channel.
+type metadata struct {
+	host       string
+	source     string
+	sourcetype string
+	index      string
+}
+
+// channel is a stream of data, e.g. a monitored file, sent by a forwarder.
+type channel struct {
+	metadata metadata
+	// partial is the last line of unparsed data that wasn't terminated yet.
+	partial []byte
+}
+
+// connection converts the messages of a forwarder to logs.
+type connection struct {
+	now       func() time.Time
+	channels  map[string]*channel
+	handshake handshake
+	maxSize   int
+}
+
+// logs converts a message to logs. Parsed events, e.g. from heavy forwarders, are converted
+// to a single log record. Unparsed data from universal forwarders is broken into a log record
+// per line, buffering lines split across messages until the channel is done.
+func (c *connection) logs(msg message) plog.Logs {
+	logs := plog.NewLogs()
+	raw, hasRaw := msg[rawKey]
+	_, done := msg[doneKey]
+	if !hasRaw && !done {
+		return logs
+	}
+	ch, ok := c.channels[msg[channelKey]]
+	if !ok {
+		ch = &channel{metadata: metadata{host: c.handshake.ServerName}}
+		c.channels[msg[channelKey]] = ch
+	}
+	ch.metadata.update(msg)
+	if done {
+		delete(c.channels, msg[channelKey])
+	}
+
+	var lines [][]byte
+	_, hasTime := msg[timeKey]
+	_, lineBroken := msg[lineBreakerKey]
+	if hasTime || lineBroken {
+		lines = [][]byte{bytes.TrimRight([]byte(raw), "\r\n")}
+	} else {
+		data := append(ch.partial, raw...)
+		last := bytes.LastIndexByte(data, '\n')
+		ch.partial = append([]byte(nil), data[last+1:]...)
+		if last >= 0 {
+			lines = bytes.Split(data[:last], []byte{'\n'})
+		}
+		if done || len(ch.partial) >= c.maxSize {
+			lines = append(lines, ch.partial)
+			ch.partial = nil
+		}
+	}
+
+	sl := ch.metadata.resourceLogs(logs).ScopeLogs().AppendEmpty()
+	observed := pcommon.NewTimestampFromTime(c.now())
+	timestamp, hasTimestamp := eventTime(msg)
+	for _, line := range lines {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			continue
+		}
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Body().SetStr(string(line))
+		lr.SetObservedTimestamp(observed)
+		if hasTimestamp {
+			lr.SetTimestamp(timestamp)
+		}
+	}
+	return logs
+}
+
+// flush converts the buffered partial lines of all channels to logs.
+func (c *connection) flush() plog.Logs {
+	logs := plog.NewLogs()
+	observed := pcommon.NewTimestampFromTime(c.now())
+	for id, ch := range c.channels {
+		delete(c.channels, id)
+		if line := bytes.TrimRight(ch.partial, "\r"); len(line) > 0 {
+			lr := ch.metadata.resourceLogs(logs).ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			lr.Body().SetStr(string(line))
+			lr.SetObservedTimestamp(observed)
+		}
+	}
+	return logs
+}
+
+func (m *metadata) update(msg message) {
+	if v, ok := msg[hostKey]; ok {
+		m.host = strings.TrimPrefix(v, "host::")
+	}
+	if v, ok := msg[sourceKey]; ok {
+		m.source = strings.TrimPrefix(v, "source::")
+	}
+	if v, ok := msg[sourcetypeKey]; ok {
+		m.sourcetype = strings.TrimPrefix(v, "sourcetype::")
+	}
+	if v, ok := msg[indexKey]; ok {
+		m.index = v
+	}
+}
+
+func (m *metadata) resourceLogs(logs plog.Logs) plog.ResourceLogs {
+	rl := logs.ResourceLogs().AppendEmpty()
+	attrs := rl.Resource().Attributes()
+	for _, attr := range [][2]string{
+		{hostNameAttr, m.host},
+		{sourceAttr, m.source},
+		{sourcetypeAttr, m.sourcetype},
+		{indexAttr, m.index},
+	} {
+		if attr[1] != "" {
+			attrs.PutStr(attr[0], attr[1])
+		}
+	}
+	return rl
+}
+
+// eventTime returns the time of a parsed event from its epoch seconds and subsecond fields.
+func eventTime(msg message) (pcommon.Timestamp, bool) {
+	seconds, err := strconv.ParseInt(msg[timeKey], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	t := time.Unix(seconds, 0)
+	if s := msg[subsecondKey]; s != "" {
+		if subsecond, err := strconv.ParseFloat("0"+s, 64); err == nil {
+			t = t.Add(time.Duration(subsecond * float64(time.Second)))
+		}
+	}
+	return pcommon.NewTimestampFromTime(t), true
+}
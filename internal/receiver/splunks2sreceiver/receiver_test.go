@@ -0,0 +1,180 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunks2sreceiver
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+var observed = time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+
+func startReceiver(t *testing.T) (*s2sReceiver, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:0"
+	sink := &consumertest.LogsSink{}
+	r := newReceiver(receivertest.NewNopSettings(), cfg, sink)
+	r.now = func() time.Time { return observed }
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, r.Shutdown(context.Background())) })
+	return r, sink
+}
+
+type record struct {
+	resource  map[string]any
+	body      string
+	timestamp time.Time
+}
+
+func records(sink *consumertest.LogsSink) []record {
+	var out []record
+	for _, logs := range sink.AllLogs() {
+		for i := 0; i < logs.ResourceLogs().Len(); i++ {
+			rl := logs.ResourceLogs().At(i)
+			for j := 0; j < rl.ScopeLogs().Len(); j++ {
+				lrs := rl.ScopeLogs().At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					lr := lrs.At(k)
+					r := record{resource: rl.Resource().Attributes().AsRaw(), body: lr.Body().Str()}
+					if lr.Timestamp() != 0 {
+						r.timestamp = lr.Timestamp().AsTime()
+					}
+					out = append(out, r)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func TestReceiveForwarderData(t *testing.T) {
+	r, sink := startReceiver(t)
+
+	conn, err := net.Dial("tcp", r.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(signature("--splunk-cooked-mode-v3--", "uf-host", "8089"))
+	require.NoError(t, err)
+
+	// capabilities are negotiated down to v3 without acknowledgements
+	_, err = conn.Write(encodeMessage([2]string{capabilitiesKey, "ack=0;compression=0"}))
+	require.NoError(t, err)
+	response, err := readMessage(bufio.NewReader(conn), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, message{controlMsgKey: capabilitiesResponse}, response)
+
+	for _, msg := range [][][2]string{
+		{
+			{sourceKey, "source::/var/log/app.log"},
+			{sourcetypeKey, "sourcetype::app"},
+			{indexKey, "main"},
+			{channelKey, "1"},
+			{rawKey, "first line\r\nsecond "},
+		},
+		{
+			{channelKey, "1"},
+			{rawKey, "line\nunterminated"},
+		},
+		{
+			{channelKey, "1"},
+			{doneKey, "_done"},
+			{rawKey, ""},
+		},
+		{
+			{hostKey, "host::hf-host"},
+			{sourceKey, "source::udp:514"},
+			{sourcetypeKey, "sourcetype::syslog"},
+			{indexKey, "network"},
+			{channelKey, "2"},
+			{timeKey, "1705752000"},
+			{subsecondKey, ".250"},
+			{rawKey, "parsed event\n"},
+		},
+	} {
+		_, err = conn.Write(encodeMessage(msg...))
+		require.NoError(t, err)
+	}
+
+	appResource := map[string]any{
+		"host.name":             "uf-host",
+		"com.splunk.source":     "/var/log/app.log",
+		"com.splunk.sourcetype": "app",
+		"com.splunk.index":      "main",
+	}
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []record{
+		{resource: appResource, body: "first line"},
+		{resource: appResource, body: "second line"},
+		{resource: appResource, body: "unterminated"},
+		{
+			resource: map[string]any{
+				"host.name":             "hf-host",
+				"com.splunk.source":     "udp:514",
+				"com.splunk.sourcetype": "syslog",
+				"com.splunk.index":      "network",
+			},
+			body:      "parsed event",
+			timestamp: time.Unix(1705752000, 250_000_000).UTC(),
+		},
+	}, records(sink))
+}
+
+func TestFlushOnDisconnect(t *testing.T) {
+	c := &connection{
+		handshake: handshake{ServerName: "uf-host"},
+		channels:  map[string]*channel{},
+		maxSize:   1024,
+		now:       func() time.Time { return observed },
+	}
+	logs := c.logs(message{channelKey: "1", rawKey: "complete\npartial"})
+	require.Equal(t, 1, logs.LogRecordCount())
+
+	logs = c.flush()
+	require.Equal(t, 1, logs.LogRecordCount())
+	lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "partial", lr.Body().Str())
+	assert.Equal(t, pcommon.NewTimestampFromTime(observed), lr.ObservedTimestamp())
+	assert.Empty(t, c.channels)
+	assert.Equal(t, 0, c.flush().LogRecordCount())
+}
+
+func TestRejectInvalidSignature(t *testing.T) {
+	r, sink := startReceiver(t)
+
+	conn, err := net.Dial("tcp", r.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(signature("--splunk-cooked-mode-v9--", "uf-host", "8089"))
+	require.NoError(t, err)
+
+	// the receiver closes the connection
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	assert.Equal(t, 0, sink.LogRecordCount())
+}
@@ -0,0 +1,53 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunksyslogreceiver
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+var _ component.Config = (*Config)(nil)
+
+// Config defines where syslog senders connect to the receiver and the Splunk fields of their messages.
+type Config struct {
+	// TLS configures the listener to accept senders using TLS, and to authenticate them with their
+	// certificate if client_ca_file is set.
+	TLS *configtls.ServerConfig `mapstructure:"tls"`
+	// Endpoint is the TCP address senders connect to.
+	Endpoint string `mapstructure:"endpoint"`
+	// Sourcetype is the Splunk sourcetype of the messages.
+	Sourcetype string `mapstructure:"sourcetype"`
+	// Index is the Splunk index of the messages, the index of the HEC token if empty.
+	Index string `mapstructure:"index"`
+	// MaxMessageSize is the largest message accepted from senders, in bytes.
+	MaxMessageSize int `mapstructure:"max_message_size"`
+}
+
+func (cfg *Config) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = errors.Join(errs, errors.New("endpoint must be specified"))
+	}
+	if cfg.Sourcetype == "" {
+		errs = errors.Join(errs, errors.New("sourcetype must be specified"))
+	}
+	if cfg.MaxMessageSize <= 0 {
+		errs = errors.Join(errs, errors.New("max_message_size must be positive"))
+	}
+	return errs
+}
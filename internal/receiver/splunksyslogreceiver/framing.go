@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunksyslogreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errMessageTooLarge = errors.New("message exceeds max_message_size")
+
+// readFrame reads the next message of a connection. Messages framed by octet counting, prefixed
+// with their length as in RFC 6587 section 3.4.1, are detected by their leading digit, since
+// messages start with their priority otherwise. Other messages are terminated by a newline.
+func readFrame(r *bufio.Reader, maxSize int) ([]byte, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if first[0] >= '0' && first[0] <= '9' {
+		return readOctetCounted(r, maxSize)
+	}
+	return readLine(r, maxSize)
+}
+
+func readOctetCounted(r *bufio.Reader, maxSize int) ([]byte, error) {
+	var n int
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, truncated(err)
+		}
+		if c == ' ' {
+			break
+		}
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("invalid octet count character %q", c)
+		}
+		if n = n*10 + int(c-'0'); n > maxSize {
+			return nil, errMessageTooLarge
+		}
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, truncated(err)
+	}
+	return msg, nil
+}
+
+// readLine reads a message terminated by a newline, or by the end of the connection.
+func readLine(r *bufio.Reader, maxSize int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		// the newline isn't part of the message
+		if len(line) > maxSize+1 {
+			return nil, errMessageTooLarge
+		}
+		if err == nil || (errors.Is(err, io.EOF) && len(line) > 0) {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// truncated returns io.ErrUnexpectedEOF for a connection closed in the middle of a message.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunksyslogreceiver
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFrame(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		data        string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:     "newline framing",
+			data:     "<13>first\r\n<13>second\nlast",
+			expected: []string{"<13>first", "<13>second", "last"},
+		},
+		{
+			name:     "octet counting",
+			data:     "9 <13>first10 <13>second",
+			expected: []string{"<13>first", "<13>second"},
+		},
+		{
+			name:     "mixed framing",
+			data:     "9 <13>first<13>second\n",
+			expected: []string{"<13>first", "<13>second"},
+		},
+		{
+			name:     "octet counted newlines",
+			data:     "10 <13>a\nb\r\nc",
+			expected: []string{"<13>a\nb\r\nc"},
+		},
+		{
+			name:     "empty lines",
+			data:     "\n\r\n<13>message\n",
+			expected: []string{"", "", "<13>message"},
+		},
+		{
+			name:        "truncated octet counted message",
+			data:        "20 <13>message",
+			expectedErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:        "octet count too large",
+			data:        "33 <13>message",
+			expectedErr: errMessageTooLarge,
+		},
+		{
+			name:        "line too large",
+			data:        "<13>" + strings.Repeat("a", 29) + "\n",
+			expectedErr: errMessageTooLarge,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// a small buffer reads lines in several chunks
+			r := bufio.NewReaderSize(strings.NewReader(tt.data), 16)
+			var frames []string
+			for {
+				frame, err := readFrame(r, 32)
+				if err != nil {
+					if tt.expectedErr != nil {
+						require.ErrorIs(t, err, tt.expectedErr)
+						return
+					}
+					require.ErrorIs(t, err, io.EOF)
+					break
+				}
+				frames = append(frames, string(frame))
+			}
+			assert.Equal(t, tt.expected, frames)
+		})
+	}
+}
+
+func TestReadFrameInvalidOctetCount(t *testing.T) {
+	_, err := readFrame(bufio.NewReader(strings.NewReader("12a <13>message")), 32)
+	require.EqualError(t, err, `invalid octet count character 'a'`)
+}
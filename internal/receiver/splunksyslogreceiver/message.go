@@ -0,0 +1,263 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunksyslogreceiver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	formatRFC3164 = "rfc3164"
+	formatRFC5424 = "rfc5424"
+
+	// defaultPriority is the priority of messages without one, user.notice as per RFC 3164.
+	defaultPriority = 13
+	maxPriority     = 191
+	// rfc3164TimestampLayout is the timestamp of RFC 3164 messages, e.g. "Jan  2 15:04:05".
+	rfc3164TimestampLayout = "Jan _2 15:04:05"
+	nilValue               = "-"
+)
+
+var (
+	facilities = []string{
+		"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp", "ntp",
+		"security", "console", "solaris-cron", "local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+	}
+	severities = []struct {
+		text   string
+		number plog.SeverityNumber
+	}{
+		{"emerg", plog.SeverityNumberFatal2},
+		{"alert", plog.SeverityNumberFatal},
+		{"crit", plog.SeverityNumberError2},
+		{"err", plog.SeverityNumberError},
+		{"warning", plog.SeverityNumberWarn},
+		{"notice", plog.SeverityNumberInfo2},
+		{"info", plog.SeverityNumberInfo},
+		{"debug", plog.SeverityNumberDebug},
+	}
+	utf8BOM = []byte("\xef\xbb\xbf")
+)
+
+// message is a parsed syslog message. Nil values are empty.
+type message struct {
+	timestamp      time.Time
+	structuredData map[string]map[string]string
+	format         string
+	hostname       string
+	appName        string
+	procID         string
+	msgID          string
+	text           string
+	priority       int
+}
+
+func (m *message) facility() string {
+	return facilities[m.priority/8]
+}
+
+// parseMessage parses a syslog message, detecting its format: RFC 5424 messages have a version
+// after their priority, which RFC 3164 messages don't. RFC 3164 messages are parsed leniently,
+// since their format is only a convention, and the text of messages that don't follow it is the
+// whole message. now is the reception time, which completes the year of RFC 3164 timestamps.
+func parseMessage(b []byte, now time.Time) (*message, error) {
+	priority, rest, err := parsePriority(b)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(rest, []byte("1 ")) {
+		// RFC 3164 text can start with "1 " too, so messages that aren't valid RFC 5424 ones are
+		// parsed as RFC 3164 ones
+		if m, err := parseRFC5424(priority, rest[2:]); err == nil {
+			return m, nil
+		}
+	}
+	return parseRFC3164(priority, rest, now), nil
+}
+
+// parsePriority parses the "<PRI>" of a message, returning the default priority if it has none.
+func parsePriority(b []byte) (int, []byte, error) {
+	if len(b) == 0 || b[0] != '<' {
+		return defaultPriority, b, nil
+	}
+	end := bytes.IndexByte(b, '>')
+	if end < 2 || end > 4 {
+		return 0, nil, errors.New("invalid priority")
+	}
+	priority, err := strconv.Atoi(string(b[1:end]))
+	if err != nil || priority < 0 || priority > maxPriority {
+		return 0, nil, fmt.Errorf("invalid priority %q", b[1:end])
+	}
+	return priority, b[end+1:], nil
+}
+
+// parseRFC5424 parses the rest of an RFC 5424 message after its version, e.g.
+// `2003-10-11T22:14:15.003Z host app 1234 ID47 [exampleSDID@32473 iut="3"] text`.
+func parseRFC5424(priority int, b []byte) (*message, error) {
+	m := &message{format: formatRFC5424, priority: priority}
+	fields := make([]string, 5)
+	for i := range fields {
+		end := bytes.IndexByte(b, ' ')
+		if end <= 0 {
+			return nil, errors.New("missing RFC 5424 header fields")
+		}
+		if field := string(b[:end]); field != nilValue {
+			fields[i] = field
+		}
+		b = b[end+1:]
+	}
+	if fields[0] != "" {
+		timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		m.timestamp = timestamp
+	}
+	m.hostname, m.appName, m.procID, m.msgID = fields[1], fields[2], fields[3], fields[4]
+
+	var err error
+	if m.structuredData, b, err = parseStructuredData(b); err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if b[0] != ' ' {
+			return nil, errors.New("missing space after structured data")
+		}
+		m.text = string(bytes.TrimPrefix(b[1:], utf8BOM))
+	}
+	return m, nil
+}
+
+// parseStructuredData parses the structured data elements at the start of b, returning the rest.
+func parseStructuredData(b []byte) (map[string]map[string]string, []byte, error) {
+	if bytes.HasPrefix(b, []byte(nilValue)) {
+		return nil, b[1:], nil
+	}
+	sd := map[string]map[string]string{}
+	for len(b) > 0 && b[0] == '[' {
+		end := bytes.IndexAny(b, " ]")
+		if end <= 1 {
+			return nil, nil, errors.New("invalid structured data element")
+		}
+		params := map[string]string{}
+		sd[string(b[1:end])] = params
+		b = b[end:]
+		for len(b) > 0 && b[0] == ' ' {
+			eq := bytes.IndexByte(b, '=')
+			if eq <= 1 || eq+1 >= len(b) || b[eq+1] != '"' {
+				return nil, nil, errors.New("invalid structured data parameter")
+			}
+			name := string(b[1:eq])
+			value, n, err := parseParamValue(b[eq+2:])
+			if err != nil {
+				return nil, nil, err
+			}
+			params[name] = value
+			b = b[eq+2+n:]
+		}
+		if len(b) == 0 || b[0] != ']' {
+			return nil, nil, errors.New("unterminated structured data element")
+		}
+		b = b[1:]
+	}
+	if len(sd) == 0 {
+		return nil, nil, errors.New("invalid structured data")
+	}
+	return sd, b, nil
+}
+
+// parseParamValue parses a parameter value after its opening quote, unescaping `\"`, `\\` and
+// `\]`, and returns the number of bytes read including the closing quote.
+func parseParamValue(b []byte) (string, int, error) {
+	var sb strings.Builder
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == '"':
+			return sb.String(), i + 1, nil
+		case c == '\\' && i+1 < len(b) && (b[i+1] == '"' || b[i+1] == '\\' || b[i+1] == ']'):
+			i++
+			sb.WriteByte(b[i])
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated structured data parameter value")
+}
+
+// parseRFC3164 parses the rest of an RFC 3164 message after its priority, e.g.
+// `Oct 11 22:14:15 host su[1234]: text`. Messages without timestamp are received at now.
+// RFC 3339 timestamps, which some senders use in place of the RFC 3164 ones, are accepted too.
+func parseRFC3164(priority int, b []byte, now time.Time) *message {
+	m := &message{format: formatRFC3164, priority: priority, timestamp: now}
+	s := string(b)
+	if timestamp, rest, ok := parseRFC3164Timestamp(s, now); ok {
+		m.timestamp = timestamp
+		s = rest
+		// the hostname is omitted by some senders, whose tag follows the timestamp
+		if host, rest, found := strings.Cut(s, " "); found && host != "" && !strings.ContainsAny(host, ":[") {
+			m.hostname = host
+			s = rest
+		}
+	}
+	m.appName, m.procID, s = parseTag(s)
+	m.text = s
+	return m
+}
+
+func parseRFC3164Timestamp(s string, now time.Time) (time.Time, string, bool) {
+	if len(s) > len(rfc3164TimestampLayout) && s[len(rfc3164TimestampLayout)] == ' ' {
+		if t, err := time.Parse(rfc3164TimestampLayout, s[:len(rfc3164TimestampLayout)]); err == nil {
+			// the timestamp has no year, it's the closest to the reception time
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.AddDate(0, 0, 1)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			return t, s[len(rfc3164TimestampLayout)+1:], true
+		}
+	}
+	if field, rest, found := strings.Cut(s, " "); found {
+		if t, err := time.Parse(time.RFC3339Nano, field); err == nil {
+			return t, rest, true
+		}
+	}
+	return time.Time{}, s, false
+}
+
+// parseTag parses the "app[pid]: " tag at the start of s, returning s as is without one.
+func parseTag(s string) (appName, procID, rest string) {
+	end := strings.IndexAny(s, ":[ ")
+	if end <= 0 {
+		return "", "", s
+	}
+	appName, rest = s[:end], s[end:]
+	if strings.HasPrefix(rest, "[") {
+		closing := strings.IndexByte(rest, ']')
+		if closing < 0 {
+			return "", "", s
+		}
+		procID, rest = rest[1:closing], rest[closing+1:]
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return "", "", s
+	}
+	return appName, procID, strings.TrimPrefix(rest[1:], " ")
+}
@@ -0,0 +1,172 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunksyslogreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage(t *testing.T) {
+	now := time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		expected *message
+		name     string
+		data     string
+	}{
+		{
+			name: "rfc5424",
+			data: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 ` +
+				`[exampleSDID@32473 iut="3" eventSource="Application"][examplePriority@32473 class="high"] ` +
+				"\xef\xbb\xbfAn application event",
+			expected: &message{
+				format:    formatRFC5424,
+				priority:  165,
+				timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				hostname:  "mymachine.example.com",
+				appName:   "evntslog",
+				msgID:     "ID47",
+				structuredData: map[string]map[string]string{
+					"exampleSDID@32473":     {"iut": "3", "eventSource": "Application"},
+					"examplePriority@32473": {"class": "high"},
+				},
+				text: "An application event",
+			},
+		},
+		{
+			name: "rfc5424 nil values",
+			data: `<34>1 - - su - - -`,
+			expected: &message{
+				format:   formatRFC5424,
+				priority: 34,
+				appName:  "su",
+			},
+		},
+		{
+			name: "rfc5424 escaped structured data",
+			data: `<34>1 2024-01-20T10:00:00Z host app 42 - [meta quote="a\"b" slash="c\\d" bracket="e\]f" other="g\h"] text`,
+			expected: &message{
+				format:         formatRFC5424,
+				priority:       34,
+				timestamp:      time.Date(2024, 1, 20, 10, 0, 0, 0, time.UTC),
+				hostname:       "host",
+				appName:        "app",
+				procID:         "42",
+				structuredData: map[string]map[string]string{"meta": {"quote": `a"b`, "slash": `c\d`, "bracket": "e]f", "other": `g\h`}},
+				text:           "text",
+			},
+		},
+		{
+			name: "rfc3164",
+			data: "<34>Oct 11 22:14:15 mymachine su[1234]: 'su root' failed for lonvick on /dev/pts/8",
+			expected: &message{
+				format:    formatRFC3164,
+				priority:  34,
+				timestamp: time.Date(2023, 10, 11, 22, 14, 15, 0, time.UTC),
+				hostname:  "mymachine",
+				appName:   "su",
+				procID:    "1234",
+				text:      "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name: "rfc3164 without hostname",
+			data: "<13>Jan  5 08:00:00 cron: job done",
+			expected: &message{
+				format:    formatRFC3164,
+				priority:  13,
+				timestamp: time.Date(2024, 1, 5, 8, 0, 0, 0, time.UTC),
+				appName:   "cron",
+				text:      "job done",
+			},
+		},
+		{
+			name: "rfc3164 rfc3339 timestamp",
+			data: "<13>2024-01-20T11:59:00Z host app: text",
+			expected: &message{
+				format:    formatRFC3164,
+				priority:  13,
+				timestamp: time.Date(2024, 1, 20, 11, 59, 0, 0, time.UTC),
+				hostname:  "host",
+				appName:   "app",
+				text:      "text",
+			},
+		},
+		{
+			name: "rfc3164 tomorrow",
+			data: "<13>Jan 21 01:00:00 host text",
+			expected: &message{
+				format:    formatRFC3164,
+				priority:  13,
+				timestamp: time.Date(2024, 1, 21, 1, 0, 0, 0, time.UTC),
+				hostname:  "host",
+				text:      "text",
+			},
+		},
+		{
+			name: "rfc3164 without timestamp",
+			data: "<11>app: text",
+			expected: &message{
+				format:    formatRFC3164,
+				priority:  11,
+				timestamp: now,
+				appName:   "app",
+				text:      "text",
+			},
+		},
+		{
+			name: "invalid rfc5424",
+			data: "<13>1 apple a day",
+			expected: &message{
+				format:    formatRFC3164,
+				priority:  13,
+				timestamp: now,
+				text:      "1 apple a day",
+			},
+		},
+		{
+			name: "without priority",
+			data: "plain text",
+			expected: &message{
+				format:    formatRFC3164,
+				priority:  defaultPriority,
+				timestamp: now,
+				text:      "plain text",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseMessage([]byte(tt.data), now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestParseMessageInvalidPriority(t *testing.T) {
+	for _, data := range []string{"<>text", "<192>text", "<1a>text", "<12345>text", "<13"} {
+		_, err := parseMessage([]byte(data), time.Now())
+		assert.Error(t, err, data)
+	}
+}
+
+func TestFacilityAndSeverity(t *testing.T) {
+	msg := &message{priority: 165}
+	assert.Equal(t, "local4", msg.facility())
+	assert.Equal(t, "notice", severities[msg.priority%8].text)
+}
@@ -0,0 +1,217 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunksyslogreceiver
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+const (
+	hostNameAttr       = "host.name"
+	sourcetypeAttr     = "com.splunk.sourcetype"
+	indexAttr          = "com.splunk.index"
+	facilityAttr       = "syslog.facility"
+	formatAttr         = "syslog.format"
+	appNameAttr        = "syslog.appname"
+	procIDAttr         = "syslog.procid"
+	msgIDAttr          = "syslog.msgid"
+	structuredDataAttr = "syslog.structured_data"
+	peerIPAttr         = "net.peer.ip"
+)
+
+var _ receiver.Logs = (*syslogReceiver)(nil)
+
+// syslogReceiver accepts syslog messages over TCP.
+type syslogReceiver struct {
+	consumer consumer.Logs
+	config   *Config
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	logger   *zap.Logger
+	now      func() time.Time
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+func newReceiver(settings receiver.Settings, config *Config, consumer consumer.Logs) *syslogReceiver {
+	return &syslogReceiver{
+		consumer: consumer,
+		config:   config,
+		conns:    map[net.Conn]struct{}{},
+		logger:   settings.Logger,
+		now:      time.Now,
+	}
+}
+
+func (r *syslogReceiver) Start(ctx context.Context, _ component.Host) error {
+	listener, err := net.Listen("tcp", r.config.Endpoint)
+	if err != nil {
+		return err
+	}
+	if r.config.TLS != nil {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = r.config.TLS.LoadTLSConfig(ctx); err != nil {
+			_ = listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	r.listener = listener
+	r.wg.Add(1)
+	go r.accept()
+	return nil
+}
+
+func (r *syslogReceiver) Shutdown(context.Context) error {
+	var err error
+	if r.listener != nil {
+		err = r.listener.Close()
+	}
+	r.mu.Lock()
+	for conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (r *syslogReceiver) accept() {
+	defer r.wg.Done()
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				r.logger.Error("Failed accepting syslog connection", zap.Error(err))
+			}
+			return
+		}
+		r.mu.Lock()
+		r.conns[conn] = struct{}{}
+		r.mu.Unlock()
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.handle(conn)
+			r.mu.Lock()
+			delete(r.conns, conn)
+			r.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// handle reads the messages of a sender connection until it's closed. Senders can send messages
+// with either octet counting or newline framing, which is detected per message.
+func (r *syslogReceiver) handle(conn net.Conn) {
+	logger := r.logger.With(zap.String("remote", conn.RemoteAddr().String()))
+	peerIP := ""
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		peerIP = addr.IP.String()
+	}
+	reader := bufio.NewReader(conn)
+	for {
+		frame, err := readFrame(reader, r.config.MaxMessageSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				logger.Warn("Failed reading syslog message", zap.Error(err))
+			}
+			return
+		}
+		if len(frame) == 0 {
+			continue
+		}
+		now := r.now()
+		msg, err := parseMessage(frame, now)
+		if err != nil {
+			logger.Debug("Dropped invalid syslog message", zap.Error(err))
+			continue
+		}
+		if err = r.consumer.ConsumeLogs(context.Background(), r.logs(msg, peerIP, now)); err != nil {
+			logger.Error("Failed consuming syslog messages", zap.Error(err))
+		}
+	}
+}
+
+// logs converts a message to logs, whose host is the sender's address if the message has no hostname.
+func (r *syslogReceiver) logs(msg *message, peerIP string, now time.Time) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	host := msg.hostname
+	if host == "" {
+		host = peerIP
+	}
+	resourceAttrs := rl.Resource().Attributes()
+	for _, attr := range [][2]string{
+		{hostNameAttr, host},
+		{sourcetypeAttr, r.config.Sourcetype},
+		{indexAttr, r.config.Index},
+	} {
+		if attr[1] != "" {
+			resourceAttrs.PutStr(attr[0], attr[1])
+		}
+	}
+
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStr(msg.text)
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(now))
+	if !msg.timestamp.IsZero() {
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(msg.timestamp))
+	}
+	severity := severities[msg.priority%8]
+	lr.SetSeverityNumber(severity.number)
+	lr.SetSeverityText(severity.text)
+
+	attrs := lr.Attributes()
+	for _, attr := range [][2]string{
+		{facilityAttr, msg.facility()},
+		{formatAttr, msg.format},
+		{appNameAttr, msg.appName},
+		{procIDAttr, msg.procID},
+		{msgIDAttr, msg.msgID},
+		{peerIPAttr, peerIP},
+	} {
+		if attr[1] != "" {
+			attrs.PutStr(attr[0], attr[1])
+		}
+	}
+	if len(msg.structuredData) > 0 {
+		sd := attrs.PutEmptyMap(structuredDataAttr)
+		for id, params := range msg.structuredData {
+			element := sd.PutEmptyMap(id)
+			for name, value := range params {
+				element.PutStr(name, value)
+			}
+		}
+	}
+	return logs
+}
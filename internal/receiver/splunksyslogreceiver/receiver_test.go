@@ -0,0 +1,175 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunksyslogreceiver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+var observed = time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+
+func startReceiver(t *testing.T, cfg *Config) (*syslogReceiver, *consumertest.LogsSink) {
+	cfg.Endpoint = "localhost:0"
+	sink := &consumertest.LogsSink{}
+	r := newReceiver(receivertest.NewNopSettings(), cfg, sink)
+	r.now = func() time.Time { return observed }
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, r.Shutdown(context.Background())) })
+	return r, sink
+}
+
+type record struct {
+	resource   map[string]any
+	attributes map[string]any
+	timestamp  time.Time
+	body       string
+	severity   string
+	number     plog.SeverityNumber
+}
+
+func records(sink *consumertest.LogsSink) []record {
+	var out []record
+	for _, logs := range sink.AllLogs() {
+		for i := 0; i < logs.ResourceLogs().Len(); i++ {
+			rl := logs.ResourceLogs().At(i)
+			for j := 0; j < rl.ScopeLogs().Len(); j++ {
+				lrs := rl.ScopeLogs().At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					lr := lrs.At(k)
+					r := record{
+						resource:   rl.Resource().Attributes().AsRaw(),
+						attributes: lr.Attributes().AsRaw(),
+						body:       lr.Body().Str(),
+						severity:   lr.SeverityText(),
+						number:     lr.SeverityNumber(),
+					}
+					if lr.Timestamp() != 0 {
+						r.timestamp = lr.Timestamp().AsTime()
+					}
+					out = append(out, r)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func TestReceiveMessages(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Index = "network"
+	r, sink := startReceiver(t, cfg)
+
+	conn, err := net.Dial("tcp", r.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	rfc5424 := `<165>1 2024-01-20T11:00:00Z router.example.com ifmgr 42 LINK [link@32473 port="3"] port 3 down`
+	_, err = conn.Write([]byte(
+		"<34>Jan 20 11:30:00 web su[1234]: 'su root' failed\n" +
+			"\n" +
+			"<13>unframed hostless text\r\n" +
+			strconv.Itoa(len(rfc5424)) + " " + rfc5424,
+	))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []record{
+		{
+			resource:  map[string]any{"host.name": "web", "com.splunk.sourcetype": "syslog", "com.splunk.index": "network"},
+			body:      "'su root' failed",
+			timestamp: time.Date(2024, 1, 20, 11, 30, 0, 0, time.UTC),
+			severity:  "crit",
+			number:    plog.SeverityNumberError2,
+			attributes: map[string]any{
+				"syslog.facility": "auth",
+				"syslog.format":   "rfc3164",
+				"syslog.appname":  "su",
+				"syslog.procid":   "1234",
+				"net.peer.ip":     "127.0.0.1",
+			},
+		},
+		{
+			resource:  map[string]any{"host.name": "127.0.0.1", "com.splunk.sourcetype": "syslog", "com.splunk.index": "network"},
+			body:      "unframed hostless text",
+			timestamp: observed,
+			severity:  "notice",
+			number:    plog.SeverityNumberInfo2,
+			attributes: map[string]any{
+				"syslog.facility": "user",
+				"syslog.format":   "rfc3164",
+				"net.peer.ip":     "127.0.0.1",
+			},
+		},
+		{
+			resource:  map[string]any{"host.name": "router.example.com", "com.splunk.sourcetype": "syslog", "com.splunk.index": "network"},
+			body:      "port 3 down",
+			timestamp: time.Date(2024, 1, 20, 11, 0, 0, 0, time.UTC),
+			severity:  "notice",
+			number:    plog.SeverityNumberInfo2,
+			attributes: map[string]any{
+				"syslog.facility":        "local4",
+				"syslog.format":          "rfc5424",
+				"syslog.appname":         "ifmgr",
+				"syslog.procid":          "42",
+				"syslog.msgid":           "LINK",
+				"syslog.structured_data": map[string]any{"link@32473": map[string]any{"port": "3"}},
+				"net.peer.ip":            "127.0.0.1",
+			},
+		},
+	}, records(sink))
+}
+
+func TestMessageTooLarge(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxMessageSize = 16
+	r, sink := startReceiver(t, cfg)
+
+	conn, err := net.Dial("tcp", r.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("<13>short\n<13>this message is too large\n<13>never read\n"))
+	require.NoError(t, err)
+
+	// the connection is closed after the oversized message
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	var netErr net.Error
+	require.False(t, errors.As(err, &netErr) && netErr.Timeout())
+	assert.Equal(t, []string{"short"}, bodies(sink))
+}
+
+func bodies(sink *consumertest.LogsSink) []string {
+	var out []string
+	for _, r := range records(sink) {
+		out = append(out, r.body)
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscertificatesreceiver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+var _ component.Config = (*Config)(nil)
+
+func createDefaultConfig() component.Config {
+	scs := scraperhelper.NewDefaultControllerConfig()
+	scs.CollectionInterval = time.Minute
+	return &Config{ControllerConfig: scs}
+}
+
+type Config struct {
+	scraperhelper.ControllerConfig `mapstructure:",squash"`
+	// Certificates are the certificate files, and their keys, of the TLS configs to monitor.
+	Certificates []CertificateConfig `mapstructure:"certificates"`
+}
+
+// CertificateConfig is a certificate file and the optional file of its private key.
+type CertificateConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Certificates) == 0 {
+		return errors.New("certificates must not be empty")
+	}
+	for i, cert := range cfg.Certificates {
+		if cert.CertFile == "" {
+			return fmt.Errorf("certificates[%d]: cert_file must not be empty", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscertificatesreceiver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/zap"
+)
+
+const (
+	timeRemainingMetric = "tls.certificate.time_remaining"
+	loadableMetric      = "tls.certificate.loadable"
+
+	fileAttr    = "tls.certificate.file"
+	subjectAttr = "tls.certificate.subject"
+	issuerAttr  = "tls.certificate.issuer"
+)
+
+// scraper reports the time remaining before the certificates of the configured files expire, and
+// whether the files can be loaded, like the TLS configs reloading them do, so failed certificate
+// rotations are detected before the previously loaded certificates expire.
+type scraper struct {
+	logger *zap.Logger
+	cfg    *Config
+	now    func() time.Time
+}
+
+func newScraper(settings receiver.Settings, cfg *Config) *scraper {
+	return &scraper{
+		logger: settings.Logger,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+func (s *scraper) scrape(context.Context) (pmetric.Metrics, error) {
+	now := s.now()
+	ts := pcommon.NewTimestampFromTime(now)
+
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	remaining := metrics.AppendEmpty()
+	remaining.SetName(timeRemainingMetric)
+	remaining.SetDescription("The time remaining before the certificate expires, negative once it expired.")
+	remaining.SetUnit("s")
+	remainingDps := remaining.SetEmptyGauge().DataPoints()
+	loadable := metrics.AppendEmpty()
+	loadable.SetName(loadableMetric)
+	loadable.SetDescription("Whether the certificate file, and its key, can be loaded.")
+	loadable.SetUnit("1")
+	loadableDps := loadable.SetEmptyGauge().DataPoints()
+
+	for _, cert := range s.cfg.Certificates {
+		certs, err := loadCertificates(cert)
+		dp := loadableDps.AppendEmpty()
+		dp.SetTimestamp(ts)
+		dp.Attributes().PutStr(fileAttr, cert.CertFile)
+		if err != nil {
+			s.logger.Warn("Failed to load certificate", zap.String("cert_file", cert.CertFile), zap.Error(err))
+			dp.SetIntValue(0)
+			continue
+		}
+		dp.SetIntValue(1)
+		for _, c := range certs {
+			dp := remainingDps.AppendEmpty()
+			dp.SetTimestamp(ts)
+			dp.SetIntValue(int64(c.NotAfter.Sub(now) / time.Second))
+			dp.Attributes().PutStr(fileAttr, cert.CertFile)
+			dp.Attributes().PutStr(subjectAttr, c.Subject.String())
+			dp.Attributes().PutStr(issuerAttr, c.Issuer.String())
+		}
+	}
+	return md, nil
+}
+
+// loadCertificates returns the certificates of the file, after checking that they can be loaded
+// with their key like configtls loads them.
+func loadCertificates(cert CertificateConfig) ([]*x509.Certificate, error) {
+	certPEM, err := os.ReadFile(cert.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	if cert.KeyFile != "" {
+		keyPEM, err := os.ReadFile(cert.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			return nil, err
+		}
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(certPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
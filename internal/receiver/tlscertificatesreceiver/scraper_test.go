@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlscertificatesreceiver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+var now = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// writeCertificate writes a self-signed certificate expiring at notAfter and its key.
+func writeCertificate(t *testing.T, dir, name string, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestScrape(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := writeCertificate(t, dir, "server", now.Add(30*24*time.Hour))
+	expiredCert, _ := writeCertificate(t, dir, "expired", now.Add(-time.Minute))
+	_, otherKey := writeCertificate(t, dir, "other", now.Add(time.Hour))
+	missingCert := filepath.Join(dir, "missing.crt")
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Certificates = []CertificateConfig{
+		{CertFile: serverCert, KeyFile: serverKey},
+		{CertFile: expiredCert},
+		// a rotated certificate without its new key
+		{CertFile: serverCert, KeyFile: otherKey},
+		{CertFile: missingCert},
+	}
+	s := newScraper(receivertest.NewNopSettings(), cfg)
+	s.now = func() time.Time { return now }
+
+	md, err := s.scrape(context.Background())
+	require.NoError(t, err)
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+
+	remaining := metrics.At(0)
+	assert.Equal(t, timeRemainingMetric, remaining.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, remaining.Type())
+	dps := remaining.Gauge().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	assert.Equal(t, int64(30*24*60*60), dps.At(0).IntValue())
+	assert.Equal(t, map[string]any{
+		fileAttr:    serverCert,
+		subjectAttr: "CN=server",
+		issuerAttr:  "CN=server",
+	}, dps.At(0).Attributes().AsRaw())
+	assert.Equal(t, int64(-60), dps.At(1).IntValue())
+
+	loadable := metrics.At(1)
+	assert.Equal(t, loadableMetric, loadable.Name())
+	dps = loadable.Gauge().DataPoints()
+	require.Equal(t, 4, dps.Len())
+	for i, expected := range []int64{1, 1, 0, 0} {
+		assert.Equal(t, expected, dps.At(i).IntValue())
+		assert.Equal(t, cfg.Certificates[i].CertFile, dps.At(i).Attributes().AsRaw()[fileAttr])
+	}
+}
+
+func TestLoadCertificatesWithoutCertificate(t *testing.T) {
+	dir := t.TempDir()
+	_, keyFile := writeCertificate(t, dir, "server", now)
+	_, err := loadCertificates(CertificateConfig{CertFile: keyFile})
+	require.EqualError(t, err, "no certificate found")
+}
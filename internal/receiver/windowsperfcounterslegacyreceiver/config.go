@@ -0,0 +1,123 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcounterslegacyreceiver
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+// Config defines the perf counter objects to collect and the plugin
+// dimension value of the Smart Agent monitor whose metrics are reproduced.
+type Config struct {
+	// Plugin is the value of the "plugin" attribute added to every datapoint.
+	// The Smart Agent windows-legacy monitor used "windows-legacy" and the
+	// telegraf/win_perf_counters monitor used "telegraf-win_perf_counters".
+	Plugin string `mapstructure:"plugin"`
+	// Objects are the perf counter objects to collect. Defaults to those of
+	// the Smart Agent windows-legacy monitor.
+	Objects                        []ObjectConfig `mapstructure:"objects"`
+	scraperhelper.ControllerConfig `mapstructure:",squash"`
+}
+
+// ObjectConfig mirrors the Smart Agent telegraf/win_perf_counters monitor object settings.
+type ObjectConfig struct {
+	// Object is the name of the windows performance counter object.
+	Object string `mapstructure:"object"`
+	// Measurement is the metric name prefix of the object's counters.
+	Measurement string `mapstructure:"measurement"`
+	// Counters are the names of the counters to collect from the object.
+	Counters []string `mapstructure:"counters"`
+	// Instances are the object instances to collect. Must be empty for
+	// objects without instances like Memory and System.
+	Instances []string `mapstructure:"instances"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Objects) == 0 {
+		return errors.New("at least one object must be configured")
+	}
+	var errs error
+	for i, obj := range cfg.Objects {
+		if obj.Object == "" {
+			errs = errors.Join(errs, fmt.Errorf("objects[%d]: object must be specified", i))
+		}
+		if obj.Measurement == "" {
+			errs = errors.Join(errs, fmt.Errorf("objects[%d]: measurement must be specified", i))
+		}
+		if len(obj.Counters) == 0 {
+			errs = errors.Join(errs, fmt.Errorf("objects[%d]: at least one counter must be specified", i))
+		}
+	}
+	return errs
+}
+
+// defaultObjects are the perf counters collected by the Smart Agent windows-legacy monitor.
+func defaultObjects() []ObjectConfig {
+	return []ObjectConfig{
+		{
+			Object:      "Processor",
+			Measurement: "processor",
+			Counters:    []string{"% Processor Time", "% Privileged Time", "% User Time", "Interrupts/sec"},
+			Instances:   []string{"*"},
+		},
+		{
+			Object:      "System",
+			Measurement: "system",
+			Counters:    []string{"Processor Queue Length", "System Calls/sec", "Context Switches/sec"},
+		},
+		{
+			Object:      "Memory",
+			Measurement: "memory",
+			Counters:    []string{"Available MBytes", "Pages Input/sec"},
+		},
+		{
+			Object:      "Paging File",
+			Measurement: "paging_file",
+			Counters:    []string{"% Usage", "% Usage Peak"},
+			Instances:   []string{"*"},
+		},
+		{
+			Object:      "PhysicalDisk",
+			Measurement: "physicaldisk",
+			Counters:    []string{"Avg. Disk sec/Write", "Avg. Disk sec/Read", "Avg. Disk sec/Transfer"},
+			Instances:   []string{"*"},
+		},
+		{
+			Object:      "LogicalDisk",
+			Measurement: "logicaldisk",
+			Counters: []string{
+				"Disk Read Bytes/sec", "Disk Write Bytes/sec", "Disk Transfers/sec", "Disk Reads/sec",
+				"Disk Writes/sec", "Free Megabytes", "% Free Space",
+			},
+			Instances: []string{"*"},
+		},
+		{
+			Object:      "Network Interface",
+			Measurement: "network_interface",
+			Counters: []string{
+				"Bytes Total/sec", "Bytes Received/sec", "Bytes Sent/sec", "Current Bandwidth",
+				"Packets Received/sec", "Packets Sent/sec", "Packets Received Errors", "Packets Outbound Errors",
+				"Packets Received Discarded", "Packets Outbound Discarded",
+			},
+			Instances: []string{"*"},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcounterslegacyreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		expected    *Config
+		id          component.ID
+		expectedErr string
+	}{
+		{
+			id:       component.MustNewID(typeStr),
+			expected: createDefaultConfig().(*Config),
+		},
+		{
+			id: component.MustNewIDWithName(typeStr, "custom"),
+			expected: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.CollectionInterval = 30 * time.Second
+				cfg.Plugin = "telegraf-win_perf_counters"
+				cfg.Objects = []ObjectConfig{
+					{
+						Object:      "Processor",
+						Measurement: "win_cpu",
+						Instances:   []string{"*"},
+						Counters:    []string{"% Idle Time", "% Interrupt Time"},
+					},
+					{
+						Object:      "Memory",
+						Measurement: "win_mem",
+						Counters:    []string{"Cache Faults/sec"},
+					},
+				}
+				return cfg
+			}(),
+		},
+		{
+			id:          component.MustNewIDWithName(typeStr, "invalid"),
+			expectedErr: "objects[0]: measurement must be specified\nobjects[0]: at least one counter must be specified",
+		},
+	} {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				require.EqualError(t, cfg.Validate(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateNoObjects(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Objects = nil
+	require.EqualError(t, cfg.Validate(), "at least one object must be configured")
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcounterslegacyreceiver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/windowsperfcountersreceiver"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/scraperhelper"
+)
+
+const typeStr = "windowsperfcounters_legacy"
+
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithMetrics(createMetricsReceiver, component.StabilityLevelDevelopment),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		ControllerConfig: scraperhelper.NewDefaultControllerConfig(),
+		Plugin:           "windows-legacy",
+		Objects:          defaultObjects(),
+	}
+}
+
+// createMetricsReceiver creates a windowsperfcounters receiver for the configured objects whose
+// metrics are translated to the Smart Agent metric names and dimensions.
+func createMetricsReceiver(
+	ctx context.Context,
+	params receiver.Settings,
+	rConf component.Config,
+	next consumer.Metrics,
+) (receiver.Metrics, error) {
+	cfg := rConf.(*Config)
+	factory := windowsperfcountersreceiver.NewFactory()
+	return factory.CreateMetrics(ctx, params, cfg.windowsPerfCountersConfig(), &translator{next: next, plugin: cfg.Plugin})
+}
+
+// windowsPerfCountersConfig returns the windowsperfcounters receiver config collecting
+// every configured counter as a gauge named after its Smart Agent metric.
+func (cfg *Config) windowsPerfCountersConfig() *windowsperfcountersreceiver.Config {
+	wpcCfg := &windowsperfcountersreceiver.Config{
+		ControllerConfig: cfg.ControllerConfig,
+		MetricMetaData:   map[string]windowsperfcountersreceiver.MetricConfig{},
+	}
+	for _, obj := range cfg.Objects {
+		objCfg := windowsperfcountersreceiver.ObjectConfig{
+			Object:    obj.Object,
+			Instances: obj.Instances,
+		}
+		for _, counter := range obj.Counters {
+			name := metricName(obj.Measurement, counter)
+			objCfg.Counters = append(objCfg.Counters, windowsperfcountersreceiver.CounterConfig{
+				Name:      counter,
+				MetricRep: windowsperfcountersreceiver.MetricRep{Name: name},
+			})
+			wpcCfg.MetricMetaData[name] = windowsperfcountersreceiver.MetricConfig{
+				Description: fmt.Sprintf(`\%s\%s`, obj.Object, counter),
+			}
+		}
+		wpcCfg.PerfCounters = append(wpcCfg.PerfCounters, objCfg)
+	}
+	return wpcCfg
+}
@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcounterslegacyreceiver
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/windowsperfcountersreceiver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+	assert.Equal(t, component.MustNewType(typeStr), f.Type())
+	cfg := f.CreateDefaultConfig()
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	require.NoError(t, cfg.(*Config).Validate())
+}
+
+func TestWindowsPerfCountersConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Objects = []ObjectConfig{
+		{
+			Object:      "Processor",
+			Measurement: "processor",
+			Counters:    []string{"% Processor Time"},
+			Instances:   []string{"*"},
+		},
+		{
+			Object:      "Memory",
+			Measurement: "memory",
+			Counters:    []string{"Available MBytes"},
+		},
+	}
+
+	wpcCfg := cfg.windowsPerfCountersConfig()
+	require.NoError(t, wpcCfg.Validate())
+	assert.Equal(t, cfg.ControllerConfig, wpcCfg.ControllerConfig)
+	assert.Equal(t, []windowsperfcountersreceiver.ObjectConfig{
+		{
+			Object:    "Processor",
+			Instances: []string{"*"},
+			Counters: []windowsperfcountersreceiver.CounterConfig{
+				{Name: "% Processor Time", MetricRep: windowsperfcountersreceiver.MetricRep{Name: "processor.pct_processor_time"}},
+			},
+		},
+		{
+			Object: "Memory",
+			Counters: []windowsperfcountersreceiver.CounterConfig{
+				{Name: "Available MBytes", MetricRep: windowsperfcountersreceiver.MetricRep{Name: "memory.available_mbytes"}},
+			},
+		},
+	}, wpcCfg.PerfCounters)
+	assert.Equal(t, map[string]windowsperfcountersreceiver.MetricConfig{
+		"processor.pct_processor_time": {Description: `\Processor\% Processor Time`},
+		"memory.available_mbytes":      {Description: `\Memory\Available MBytes`},
+	}, wpcCfg.MetricMetaData)
+}
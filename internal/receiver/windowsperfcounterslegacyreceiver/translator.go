@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcounterslegacyreceiver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/signalfx/signalfx-agent/pkg/monitors/telegraf/monitors/winperfcounters"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	instanceAttr = "instance"
+	pluginAttr   = "plugin"
+)
+
+// telegrafFieldReplacer sanitizes counter names like the telegraf win_perf_counters
+// plugin the Smart Agent monitors were built on.
+var telegrafFieldReplacer = strings.NewReplacer("/sec", "_persec", "/Sec", "_persec", " ", "_", "%", "Percent", `\`, "")
+
+// pcrNames applies the SignalFx PerfCounterReporter conventions used by the Smart Agent monitors.
+var pcrNames = winperfcounters.NewPCRMetricNamesTransformer()
+
+// metricName returns the Smart Agent metric name of a counter, e.g. "processor.pct_processor_time"
+// for the "% Processor Time" counter of the "processor" measurement.
+func metricName(measurement, counter string) string {
+	return pcrNames(fmt.Sprintf("%s.%s", measurement, telegrafFieldReplacer.Replace(counter)))
+}
+
+// instanceValue returns the Smart Agent "instance" dimension value of an object instance.
+func instanceValue(instance string) string {
+	return pcrNames(instance)
+}
+
+var _ consumer.Metrics = (*translator)(nil)
+
+// translator converts the windowsperfcounters receiver's datapoint attributes to
+// the dimensions of the Smart Agent monitors before passing them on.
+type translator struct {
+	next   consumer.Metrics
+	plugin string
+}
+
+func (t *translator) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (t *translator) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				switch m.Type() {
+				case pmetric.MetricTypeGauge:
+					t.translateDataPoints(m.Gauge().DataPoints())
+				case pmetric.MetricTypeSum:
+					t.translateDataPoints(m.Sum().DataPoints())
+				}
+			}
+		}
+	}
+	return t.next.ConsumeMetrics(ctx, md)
+}
+
+func (t *translator) translateDataPoints(dps pmetric.NumberDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		attrs := dps.At(i).Attributes()
+		if instance, ok := attrs.Get(instanceAttr); ok && instance.Type() == pcommon.ValueTypeStr {
+			attrs.PutStr(instanceAttr, instanceValue(instance.Str()))
+		}
+		if t.plugin != "" {
+			attrs.PutStr(pluginAttr, t.plugin)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windowsperfcounterslegacyreceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestDefaultObjectsMetricNames(t *testing.T) {
+	// the metrics of the Smart Agent windows-legacy monitor
+	expected := []string{
+		"logicaldisk.disk_read_bytes_sec",
+		"logicaldisk.disk_reads_sec",
+		"logicaldisk.disk_transfers_sec",
+		"logicaldisk.disk_write_bytes_sec",
+		"logicaldisk.disk_writes_sec",
+		"logicaldisk.free_megabytes",
+		"logicaldisk.pct_free_space",
+		"memory.available_mbytes",
+		"memory.pages_input_sec",
+		"network_interface.bytes_received_sec",
+		"network_interface.bytes_sent_sec",
+		"network_interface.bytes_total_sec",
+		"network_interface.current_bandwidth",
+		"network_interface.packets_outbound_discarded",
+		"network_interface.packets_outbound_errors",
+		"network_interface.packets_received_discarded",
+		"network_interface.packets_received_errors",
+		"network_interface.packets_received_sec",
+		"network_interface.packets_sent_sec",
+		"paging_file.pct_usage",
+		"paging_file.pct_usage_peak",
+		"physicaldisk.avg_disk_sec_read",
+		"physicaldisk.avg_disk_sec_transfer",
+		"physicaldisk.avg_disk_sec_write",
+		"processor.interrupts_sec",
+		"processor.pct_privileged_time",
+		"processor.pct_processor_time",
+		"processor.pct_user_time",
+		"system.context_switches_sec",
+		"system.processor_queue_length",
+		"system.system_calls_sec",
+	}
+	var names []string
+	for _, obj := range defaultObjects() {
+		for _, counter := range obj.Counters {
+			names = append(names, metricName(obj.Measurement, counter))
+		}
+	}
+	assert.ElementsMatch(t, expected, names)
+}
+
+func TestInstanceValue(t *testing.T) {
+	assert.Equal(t, "_total", instanceValue("_Total"))
+	assert.Equal(t, "0", instanceValue("0"))
+	assert.Equal(t, "intel_r__ethernet_connection", instanceValue("Intel(R) Ethernet Connection"))
+	assert.Equal(t, "c_", instanceValue("C:"))
+}
+
+func TestTranslator(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	tr := &translator{next: sink, plugin: "windows-legacy"}
+
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("processor.pct_processor_time")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetDoubleValue(12.5)
+	dp.Attributes().PutStr("instance", "_Total")
+	sum := ms.AppendEmpty()
+	sum.SetName("memory.available_mbytes")
+	sum.SetEmptySum().DataPoints().AppendEmpty().SetDoubleValue(1024)
+
+	require.NoError(t, tr.ConsumeMetrics(context.Background(), md))
+	require.Len(t, sink.AllMetrics(), 1)
+
+	got := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	assert.Equal(t, map[string]any{"instance": "_total", "plugin": "windows-legacy"},
+		got.At(0).Gauge().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"plugin": "windows-legacy"},
+		got.At(1).Sum().DataPoints().At(0).Attributes().AsRaw())
+}
@@ -0,0 +1,120 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+)
+
+const (
+	// InContainerEnvVar overrides the container detection when set to "true" or "false".
+	InContainerEnvVar = "SPLUNK_IN_CONTAINER"
+	// DefaultHostRootPath is where the host's root filesystem is conventionally mounted
+	// in the collector's container.
+	DefaultHostRootPath = "/hostfs"
+)
+
+// containerRuntimeMarkers are the files container runtimes create in their containers.
+var containerRuntimeMarkers = []string{"/.dockerenv", "/run/.containerenv"}
+
+// cgroupV1Markers identify a container runtime in a cgroup v1 /proc/1/cgroup hierarchy.
+var cgroupV1Markers = []string{"/docker", "/kubepods", "/containerd", "/libpod", "/lxc", "/ecs/", "/crio-"}
+
+// detectContainer determines the container environment relative to root, which is "/" outside of tests.
+func detectContainer(root string) configconverter.ContainerEnvironment {
+	var env configconverter.ContainerEnvironment
+	switch strings.ToLower(os.Getenv(InContainerEnvVar)) {
+	case "true":
+		env.InContainer = true
+	case "false":
+		return env
+	default:
+		env.InContainer = inContainer(root)
+	}
+	if env.InContainer {
+		if info, err := os.Stat(filepath.Join(root, DefaultHostRootPath, "proc")); err == nil && info.IsDir() {
+			env.HostRootPath = DefaultHostRootPath
+		}
+	}
+	return env
+}
+
+func inContainer(root string) bool {
+	if _, ok := os.LookupEnv("KUBERNETES_SERVICE_HOST"); ok {
+		return true
+	}
+	for _, marker := range containerRuntimeMarkers {
+		if _, err := os.Stat(filepath.Join(root, marker)); err == nil {
+			return true
+		}
+	}
+	if cgroupV2(root) {
+		// cgroup v2 namespaces hide the container's cgroup path, so rely on the mounts instead
+		return containerMounts(root)
+	}
+	cgroups, err := os.ReadFile(filepath.Join(root, "proc", "1", "cgroup"))
+	if err != nil {
+		return false
+	}
+	for _, marker := range cgroupV1Markers {
+		if bytes.Contains(cgroups, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+func cgroupV2(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "sys", "fs", "cgroup", "cgroup.controllers"))
+	return err == nil
+}
+
+// containerMounts returns whether the root filesystem is an overlay or any mount originates
+// from a container runtime's or kubelet's state directory.
+func containerMounts(root string) bool {
+	f, err := os.Open(filepath.Join(root, "proc", "self", "mountinfo"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g. 1071 1070 0:52 / / rw,relatime - overlay overlay rw,lowerdir=...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		mountRoot, mountPoint := fields[3], fields[4]
+		if mountPoint == "/" {
+			for i, field := range fields {
+				if field == "-" && i+1 < len(fields) && fields[i+1] == "overlay" {
+					return true
+				}
+			}
+		}
+		for _, dir := range []string{"/docker/containers/", "/containerd/", "/kubelet/pods/", "/containers/storage/"} {
+			if strings.Contains(mountRoot, dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+)
+
+func writeRootFile(t *testing.T, root, path, content string) {
+	path = filepath.Join(root, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestDetectContainer(t *testing.T) {
+	for _, tt := range []struct {
+		files       map[string]string
+		name        string
+		inContainer string
+		expected    configconverter.ContainerEnvironment
+	}{
+		{
+			name: "host",
+			files: map[string]string{
+				"proc/1/cgroup": "12:pids:/init.scope\n11:memory:/init.scope\n",
+			},
+		},
+		{
+			name:     "docker marker",
+			files:    map[string]string{".dockerenv": ""},
+			expected: configconverter.ContainerEnvironment{InContainer: true},
+		},
+		{
+			name: "cgroup v1",
+			files: map[string]string{
+				"proc/1/cgroup":        "12:pids:/kubepods/besteffort/pod1234/abcd\n",
+				"hostfs/proc/1/cgroup": "",
+			},
+			expected: configconverter.ContainerEnvironment{InContainer: true, HostRootPath: "/hostfs"},
+		},
+		{
+			name: "cgroup v2 overlay root",
+			files: map[string]string{
+				"sys/fs/cgroup/cgroup.controllers": "cpu memory",
+				"proc/1/cgroup":                    "0::/\n",
+				"proc/self/mountinfo":              "1071 1070 0:52 / / rw,relatime - overlay overlay rw,lowerdir=/var/lib/docker/overlay2/l/A\n",
+			},
+			expected: configconverter.ContainerEnvironment{InContainer: true},
+		},
+		{
+			name: "cgroup v2 container runtime mount",
+			files: map[string]string{
+				"sys/fs/cgroup/cgroup.controllers": "cpu memory",
+				"proc/self/mountinfo": "29 1 259:2 / / rw,relatime - ext4 /dev/root rw\n" +
+					"31 29 259:2 /var/lib/docker/containers/abcd/hostname /etc/hostname rw,relatime - ext4 /dev/root rw\n",
+			},
+			expected: configconverter.ContainerEnvironment{InContainer: true},
+		},
+		{
+			name: "cgroup v2 host",
+			files: map[string]string{
+				"sys/fs/cgroup/cgroup.controllers": "cpu memory",
+				"proc/1/cgroup":                    "0::/init.scope\n",
+				"proc/self/mountinfo":              "29 1 259:2 / / rw,relatime - ext4 /dev/root rw\n",
+			},
+		},
+		{
+			name:        "forced",
+			inContainer: "true",
+			files:       map[string]string{"hostfs/proc/1/cgroup": ""},
+			expected:    configconverter.ContainerEnvironment{InContainer: true, HostRootPath: "/hostfs"},
+		},
+		{
+			name:        "disabled",
+			inContainer: "false",
+			files:       map[string]string{".dockerenv": ""},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBERNETES_SERVICE_HOST", "")
+			require.NoError(t, os.Unsetenv("KUBERNETES_SERVICE_HOST"))
+			t.Setenv(InContainerEnvVar, tt.inContainer)
+
+			root := t.TempDir()
+			for path, content := range tt.files {
+				writeRootFile(t, root, path, content)
+			}
+			assert.Equal(t, tt.expected, detectContainer(root))
+		})
+	}
+}
+
+func TestDetectContainerKubernetes(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv(InContainerEnvVar, "")
+	assert.Equal(t, configconverter.ContainerEnvironment{InContainer: true}, detectContainer(t.TempDir()))
+}
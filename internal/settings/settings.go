@@ -32,6 +32,7 @@ import (
 
 	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
 	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery"
+	"github.com/signalfx/splunk-otel-collector/internal/realm"
 )
 
 const (
@@ -87,6 +88,7 @@ type Settings struct {
 	configDir                *stringPointerFlagValue
 	confMapProviderFactories []confmap.ProviderFactory
 	discoveryPropertiesFile  *stringPointerFlagValue
+	container                configconverter.ContainerEnvironment
 	setProperties            []string
 	colCoreArgs              []string
 	discoveryProperties      []string
@@ -116,6 +118,11 @@ func New(args []string) (*Settings, error) {
 		return nil, err
 	}
 
+	// the container environment is only used by the config converters
+	if !s.noConvertConfig {
+		s.container = detectContainer("/")
+	}
+
 	return s, nil
 }
 
@@ -206,10 +213,35 @@ func (s *Settings) ConfMapConverterFactories() []confmap.ConverterFactory {
 	if !s.noConvertConfig {
 		confMapConverterFactories = append(
 			confMapConverterFactories,
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupProxy),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupK8sControlPlane),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupGPU),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupVSphere),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupPrometheusAgent),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupClockSkew),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupPIIRedaction),
+			// the wineventlog processor is inserted before the pii_redaction processor, so the
+			// event messages it renders are redacted
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupWindowsEventLog),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupSyslog),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupAPMREDMetrics),
+			// mirror exporters are added once the mirrored pipelines are complete and
+			// before the egress allowlist checks the exporters' endpoints
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupMirror),
+			// tail sampling moves the exporters of the sampled pipeline once the RED metrics connectors
+			// and mirror connectors are added, so the RED metrics are computed from all the spans
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupTailSampling),
+			// reload intervals are set once the exporters and receivers of the other settings are added
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupTLSReload),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupEgress),
+			configconverter.ConverterFactoryFromFunc(configconverter.SetupDataResidency),
 			configconverter.ConverterFactoryFromFunc(configconverter.NormalizeGcp),
 			configconverter.ConverterFactoryFromFunc(configconverter.DisableKubeletUtilizationMetrics),
 			configconverter.ConverterFactoryFromFunc(configconverter.DisableExcessiveInternalMetrics),
 			configconverter.ConverterFactoryFromFunc(configconverter.AddOTLPHistogramAttr),
+			configconverter.ConverterFactoryFromFunc(configconverter.ContainerDefaults(s.container)),
+			configconverter.ConverterFactoryFromFunc(configconverter.TranslateSmartAgentMetricFilters),
+			configconverter.ConverterFactoryFromFunc(configconverter.RotateWindowsLogFiles),
 		)
 	}
 	return confMapConverterFactories
@@ -386,12 +418,13 @@ func setDefaultEnvVars(s *Settings) error {
 		{e: ConfigServerEnabledEnvVar, v: "true"},
 	}
 
-	if realm, ok := os.LookupEnv(RealmEnvVar); ok {
+	if r, ok := os.LookupEnv(RealmEnvVar); ok {
+		endpoints := realm.ForRealm(r)
 		envVars = append(envVars,
-			ev{e: APIURLEnvVar, v: fmt.Sprintf("https://api.%s.signalfx.com", realm)},
-			ev{e: IngestURLEnvVar, v: fmt.Sprintf("https://ingest.%s.signalfx.com", realm)},
-			ev{e: TraceIngestURLEnvVar, v: fmt.Sprintf("https://ingest.%s.signalfx.com/v2/trace", realm)},
-			ev{e: HecLogIngestURLEnvVar, v: fmt.Sprintf("https://ingest.%s.signalfx.com/v1/log", realm)},
+			ev{e: APIURLEnvVar, v: endpoints.API},
+			ev{e: IngestURLEnvVar, v: endpoints.Ingest},
+			ev{e: TraceIngestURLEnvVar, v: endpoints.Trace},
+			ev{e: HecLogIngestURLEnvVar, v: endpoints.HEC},
 		)
 	}
 
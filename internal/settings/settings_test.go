@@ -118,6 +118,7 @@ func TestNewSettingsNoConvertConfig(t *testing.T) {
 	require.NoError(t, err)
 
 	require.True(t, settings.noConvertConfig)
+	require.Zero(t, settings.container)
 
 	require.Equal(t, []string{configPath, anotherConfigPath}, settings.configPaths.value)
 	require.Equal(t, []string{"foo", "bar", "baz"}, settings.setProperties)
@@ -156,7 +157,7 @@ func TestNewSettingsConvertConfig(t *testing.T) {
 	require.Equal(t, []string(nil), settings.discoveryProperties)
 
 	require.Equal(t, []string{configPath, anotherConfigPath}, settings.ResolverURIs())
-	require.Equal(t, 6, len(settings.ConfMapConverterFactories()))
+	require.Equal(t, 24, len(settings.ConfMapConverterFactories()))
 	require.Equal(t, []string{"--feature-gates", "foo", "--feature-gates", "-bar"}, settings.ColCoreArgs())
 }
 
@@ -83,6 +83,12 @@ type CollectdConfig struct {
 	// transient -- there is no value in editing them by hand.  If you want to
 	// add your own collectd config, see the collectd/custom monitor.
 	ConfigDir string `yaml:"configDir" default:"/var/run/signalfx-agent/collectd"`
+	// If true, the monitors of each collectd-based monitor type run in their
+	// own collectd subprocess instead of the global one, so that configuring
+	// or shutting down a monitor only restarts collectd for monitors of the
+	// same type.  Monitors can also be grouped explicitly with their
+	// `collectdPool` option.
+	InstancePerMonitorType bool `yaml:"instancePerMonitorType" default:"false"`
 
 	// The following are propagated from the top-level config
 	BundleDir            string `yaml:"-"`
@@ -92,7 +98,7 @@ type CollectdConfig struct {
 	// A hack to allow custom collectd to easily specify a single monitorID via
 	// query parameter
 	WriteServerQuery string          `yaml:"-"`
-	Logger           log.FieldLogger `yaml:"-"`
+	Logger           log.FieldLogger `yaml:"-" hash:"ignore"`
 }
 
 // Validate the collectd specific config
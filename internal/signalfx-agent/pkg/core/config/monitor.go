@@ -131,6 +131,12 @@ type MonitorConfig struct {
 	// If this is a native collectd plugin-based monitor it will
 	// run its own collectd subprocess. No effect otherwise.
 	IsolatedCollectd bool `yaml:"isolatedCollectd" json:"isolatedCollectd"`
+	// If this is a native collectd plugin-based monitor, the name of the
+	// collectd instance pool it should run in.  Monitors with the same pool
+	// name share a collectd subprocess that is separate from the global one,
+	// so they aren't restarted when monitors outside of the pool change.  No
+	// effect otherwise or if isolatedCollectd is set.
+	CollectdPool string `yaml:"collectdPool" json:"collectdPool"`
 	// OtherConfig is everything else that is custom to a particular monitor
 	OtherConfig map[string]interface{} `yaml:",inline" neverLog:"omit"`
 	Hostname    string                 `yaml:"-" json:"-"`
@@ -152,9 +158,27 @@ func (mc *MonitorConfig) Validate() error {
 	if _, err = mc.MetricNameExprs(); err != nil {
 		return err
 	}
+	if mc.CollectdPool != "" && !collectdPoolRe.MatchString(mc.CollectdPool) {
+		return fmt.Errorf("collectdPool %q must only contain letters, digits, '_', '.' or '-'", mc.CollectdPool)
+	}
 	return nil
 }
 
+var collectdPoolRe = regexp.MustCompile(`^[\w.-]+$`)
+
+// CollectdInstancePool returns the name of the collectd instance pool this
+// monitor should run in, or an empty string if it should use the global
+// collectd instance.
+func (mc *MonitorConfig) CollectdInstancePool(cc *CollectdConfig) string {
+	if mc.CollectdPool != "" {
+		return mc.CollectdPool
+	}
+	if cc != nil && cc.InstancePerMonitorType {
+		return strings.ReplaceAll(mc.Type, "/", "-")
+	}
+	return ""
+}
+
 // FilterSet makes a filter set using the new filter style
 func (mc *MonitorConfig) FilterSet() (*dpfilters.FilterSet, error) {
 	return makeNewFilterSet(mc.DatapointsToExclude)
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectdInstancePool(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		monitor  MonitorConfig
+		collectd *CollectdConfig
+		expected string
+	}{
+		{
+			name:     "global instance by default",
+			monitor:  MonitorConfig{Type: "collectd/redis"},
+			collectd: &CollectdConfig{},
+		},
+		{
+			name:     "explicit pool",
+			monitor:  MonitorConfig{Type: "collectd/redis", CollectdPool: "caches"},
+			collectd: &CollectdConfig{},
+			expected: "caches",
+		},
+		{
+			name:     "pool per monitor type",
+			monitor:  MonitorConfig{Type: "collectd/redis"},
+			collectd: &CollectdConfig{InstancePerMonitorType: true},
+			expected: "collectd-redis",
+		},
+		{
+			name:     "explicit pool takes precedence",
+			monitor:  MonitorConfig{Type: "collectd/redis", CollectdPool: "caches"},
+			collectd: &CollectdConfig{InstancePerMonitorType: true},
+			expected: "caches",
+		},
+		{
+			name:    "no collectd config",
+			monitor: MonitorConfig{Type: "collectd/redis"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.monitor.CollectdInstancePool(tt.collectd))
+		})
+	}
+}
+
+func TestValidateCollectdPool(t *testing.T) {
+	mc := MonitorConfig{Type: "collectd/redis", CollectdPool: "my_pool-1.0"}
+	require.NoError(t, mc.Validate())
+
+	mc.CollectdPool = "../pool"
+	require.EqualError(t, mc.Validate(), `collectdPool "../pool" must only contain letters, digits, '_', '.' or '-'`)
+}
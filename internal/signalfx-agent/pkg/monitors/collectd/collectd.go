@@ -62,10 +62,18 @@ type Manager struct {
 	requestRestart chan struct{}
 
 	logger log.FieldLogger
+	// The name of the instance pool of the manager, if any
+	pool string
 }
 
 var collectdSingleton *Manager
 
+var (
+	// Collectd instance pools by name, see PoolInstance
+	pools     = map[string]*Manager{}
+	poolsLock sync.Mutex
+)
+
 // MainInstance returns the global singleton instance of the collectd manager
 func MainInstance() *Manager {
 	if collectdSingleton == nil {
@@ -118,9 +126,76 @@ func ConfigureMainCollectd(conf *config.CollectdConfig) error {
 		cm.RequestRestart()
 	}
 
+	configurePools(&localConf)
+
 	return nil
 }
 
+// PoolInstance returns the manager of the named collectd instance pool,
+// creating it from the main collectd config and logging with the given logger
+// if it doesn't exist yet.  Each pool runs its own collectd subprocess and write
+// server, so monitors in a pool are not restarted when monitors outside of it
+// are configured or shutdown.  A pool is removed once its last monitor is
+// shutdown.
+func PoolInstance(name string, logger log.FieldLogger) *Manager {
+	poolsLock.Lock()
+	defer poolsLock.Unlock()
+
+	if cm, ok := pools[name]; ok {
+		return cm
+	}
+
+	conf := poolConfig(MainInstance().Config(), name)
+	conf.Logger = logger
+	cm := InitCollectd(conf)
+	cm.pool = name
+	pools[name] = cm
+	return cm
+}
+
+// removePool removes the instance pool of the manager if it has no active
+// monitors.
+func (cm *Manager) removePool() {
+	poolsLock.Lock()
+	defer poolsLock.Unlock()
+
+	cm.configMutex.Lock()
+	defer cm.configMutex.Unlock()
+
+	if pools[cm.pool] == cm && len(cm.activeMonitors) == 0 {
+		delete(pools, cm.pool)
+	}
+}
+
+// configurePools propagates changes to the main collectd config to the
+// instance pools, restarting only the pools that have active monitors.
+func configurePools(mainConf *config.CollectdConfig) {
+	poolsLock.Lock()
+	defer poolsLock.Unlock()
+
+	for name, cm := range pools {
+		conf := poolConfig(mainConf, name)
+
+		cm.configMutex.Lock()
+		conf.Logger = cm.conf.Logger
+		if cm.conf.Hash() != conf.Hash() {
+			cm.conf = conf
+			if len(cm.activeMonitors) > 0 {
+				cm.RequestRestart()
+			}
+		}
+		cm.configMutex.Unlock()
+	}
+}
+
+func poolConfig(mainConf *config.CollectdConfig, name string) *config.CollectdConfig {
+	conf := *mainConf
+	// Each pool needs its own write server
+	conf.WriteServerPort = 0
+	conf.InstanceName = "pool-" + name
+	return &conf
+}
+
 // ConfigureFromMonitor is how monitors notify the collectd manager that they
 // have added a configuration file to managed_config and need a restart. The
 // monitorID is passed in so that we can keep track of what monitors are
@@ -150,13 +225,21 @@ func (cm *Manager) ConfigureFromMonitor(monitorID types.MonitorID, output types.
 // MonitorDidShutdown should be called by any monitor that uses collectd when
 // it is shutdown.
 func (cm *Manager) MonitorDidShutdown(monitorID types.MonitorID) {
+	if cm.monitorDidShutdown(monitorID) && cm.pool != "" {
+		cm.removePool()
+	}
+}
+
+// monitorDidShutdown removes the monitor, returning whether it was the last
+// active one.
+func (cm *Manager) monitorDidShutdown(monitorID types.MonitorID) bool {
 	cm.configMutex.Lock()
 	defer cm.configMutex.Unlock()
 
 	if _, ok := cm.activeMonitors[monitorID]; !ok {
 		// This can happen if a monitor shuts down more than once, which is not
 		// explicitly disallowed.
-		return
+		return false
 	}
 
 	delete(cm.genericJMXUsers, monitorID)
@@ -177,7 +260,9 @@ func (cm *Manager) MonitorDidShutdown(monitorID types.MonitorID) {
 
 	if len(cm.activeMonitors) > 0 {
 		cm.RequestRestart()
+		return false
 	}
+	return true
 }
 
 // RequestRestart should be used to indicate that a configuration in
@@ -0,0 +1,74 @@
+//go:build linux
+// +build linux
+
+package collectd
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/signalfx-agent/pkg/core/config"
+)
+
+func setupMainInstance(t *testing.T) *config.CollectdConfig {
+	conf := &config.CollectdConfig{ConfigDir: t.TempDir(), InstanceName: "global"}
+	collectdSingleton = InitCollectd(conf)
+	t.Cleanup(func() {
+		collectdSingleton = nil
+		pools = map[string]*Manager{}
+	})
+	return conf
+}
+
+// stubPool returns the manager of the named pool without a collectd
+// subprocess, so restart requests are only queued.
+func stubPool(name string) *Manager {
+	cm := PoolInstance(name, log.StandardLogger())
+	cm.terminated = make(chan struct{})
+	cm.stop = make(chan struct{})
+	cm.requestRestart = make(chan struct{}, 10)
+	return cm
+}
+
+func TestPoolsDontRestartEachOther(t *testing.T) {
+	mainConf := setupMainInstance(t)
+	a, b := stubPool("a"), stubPool("b")
+	require.NotSame(t, a, b)
+	require.Same(t, a, PoolInstance("a", nil))
+	assert.Equal(t, "pool-a", a.Config().InstanceName)
+	assert.Equal(t, log.StandardLogger(), a.Config().Logger)
+
+	require.NoError(t, a.ConfigureFromMonitor("monitor-a", nil, false))
+	assert.Len(t, a.requestRestart, 1)
+	assert.Empty(t, b.requestRestart)
+
+	// only the pools with active monitors are restarted on main config changes
+	changed := *mainConf
+	changed.LogLevel = "debug"
+	configurePools(&changed)
+	assert.Len(t, a.requestRestart, 2)
+	assert.Empty(t, b.requestRestart)
+	assert.Equal(t, "debug", b.Config().LogLevel)
+	assert.Equal(t, log.StandardLogger(), a.Config().Logger)
+}
+
+func TestPoolRemovedAfterLastMonitorShutdown(t *testing.T) {
+	setupMainInstance(t)
+	a, b := stubPool("a"), stubPool("b")
+	require.NoError(t, a.ConfigureFromMonitor("monitor-1", nil, false))
+	require.NoError(t, a.ConfigureFromMonitor("monitor-2", nil, false))
+	require.NoError(t, b.ConfigureFromMonitor("monitor-3", nil, false))
+
+	a.MonitorDidShutdown("monitor-1")
+	require.Same(t, a, pools["a"])
+
+	// collectd has terminated once the last monitor of the pool is shutdown
+	close(a.terminated)
+	a.MonitorDidShutdown("monitor-2")
+	assert.NotContains(t, pools, "a")
+	assert.Same(t, b, pools["b"])
+	assert.NotSame(t, a, PoolInstance("a", nil))
+}
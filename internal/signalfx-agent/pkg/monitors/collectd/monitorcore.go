@@ -94,6 +94,9 @@ func (mc *MonitorCore) SetConfigurationAndRun(conf config.MonitorCustomConfig, o
 		cconf.Logger = mc.logger
 		mc.logger.Info(fmt.Sprintf("starting isolated configd instance %q", cconf.InstanceName))
 		mc.SetCollectdInstance(InitCollectd(&cconf))
+	} else if pool := mConf.CollectdInstancePool(MainInstance().Config()); pool != "" {
+		mc.logger.Info(fmt.Sprintf("using collectd instance pool %q", pool))
+		mc.SetCollectdInstance(PoolInstance(pool, mc.logger))
 	}
 
 	mc.config = conf
@@ -1,6 +1,7 @@
 package jmx
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -33,8 +34,12 @@ type Config struct {
 	// information on how to write this script. You can put the Groovy script in a separate file and refer to it here
 	// with the [remote config reference](https://docs.splunk.com/observability/gdi/smart-agent/smart-agent-resources.html#configure-the-smart-agent)
 	// `{"#from": "/path/to/file.groovy", raw: true}`, or you can put it straight in YAML by using the `|` heredoc
-	// syntax.
-	GroovyScript string `yaml:"groovyScript" json:"groovyScript" validate:"required"`
+	// syntax. Either `groovyScript` or `targetSystem` must be specified.
+	GroovyScript string `yaml:"groovyScript" json:"groovyScript"`
+	// The name of a Splunk-curated Groovy script bundled with the monitor to use instead of `groovyScript`.
+	// Supported values are `activemq` for the queues of ActiveMQ Classic brokers and `artemis` for the queues of
+	// ActiveMQ Artemis brokers. See the top-level `jmx` monitor doc for the metrics they send.
+	TargetSystem string `yaml:"targetSystem" json:"targetSystem"`
 	// Username for JMX authentication, if applicable.
 	Username string `yaml:"username" json:"username"`
 	// Password for JMX authentication, if applicable.
@@ -57,6 +62,22 @@ type Config struct {
 	Realm string `yaml:"realm" json:"realm"`
 }
 
+// targetSystems are the target systems of the Groovy scripts bundled in the monitor's jar.
+var targetSystems = map[string]bool{
+	"activemq": true,
+	"artemis":  true,
+}
+
+func (c *Config) Validate() error {
+	if (c.GroovyScript == "") == (c.TargetSystem == "") {
+		return errors.New("exactly one of groovyScript or targetSystem must be specified")
+	}
+	if c.TargetSystem != "" && !targetSystems[c.TargetSystem] {
+		return fmt.Errorf("unsupported targetSystem %q", c.TargetSystem)
+	}
+	return nil
+}
+
 type Monitor struct {
 	*java.Monitor
 }
@@ -74,6 +95,7 @@ func (m *Monitor) Configure(conf *Config) error {
 		CustomConfig: map[string]interface{}{
 			"serviceURL":         serviceURL,
 			"groovyScript":       conf.GroovyScript,
+			"targetSystem":       conf.TargetSystem,
 			"username":           conf.Username,
 			"password":           conf.Password,
 			"keyStorePath":       conf.KeyStorePath,
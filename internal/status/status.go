@@ -0,0 +1,77 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package status holds the parts of the collector's status document reported by components the
+// status extension can't look up from the host, like the endpoints found by discovery receivers.
+package status
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// DiscoveredEndpoint is an endpoint found by a discovery receiver, with the status its receiver
+// was evaluated to.
+type DiscoveredEndpoint struct {
+	ID       string `json:"id"`
+	Target   string `json:"target"`
+	Observer string `json:"observer,omitempty"`
+	Receiver string `json:"receiver"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Discovery returns the endpoints found by a discovery receiver.
+type Discovery func() []DiscoveredEndpoint
+
+type registration struct {
+	discovery Discovery
+}
+
+var (
+	discoveries = map[component.ID]*registration{}
+	mu          sync.Mutex
+)
+
+// RegisterDiscovery registers the endpoints found by the discovery receiver with the id, replacing
+// any previous registration, until the returned function is called.
+func RegisterDiscovery(id component.ID, discovery Discovery) (unregister func()) {
+	r := &registration{discovery: discovery}
+	mu.Lock()
+	defer mu.Unlock()
+	discoveries[id] = r
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if discoveries[id] == r {
+			delete(discoveries, id)
+		}
+	}
+}
+
+// Discoveries returns the endpoints found by each registered discovery receiver, by its id.
+func Discoveries() map[component.ID][]DiscoveredEndpoint {
+	mu.Lock()
+	registered := make(map[component.ID]Discovery, len(discoveries))
+	for id, r := range discoveries {
+		registered[id] = r.discovery
+	}
+	mu.Unlock()
+	endpoints := make(map[component.ID][]DiscoveredEndpoint, len(registered))
+	for id, discovery := range registered {
+		endpoints[id] = discovery()
+	}
+	return endpoints
+}
@@ -0,0 +1,37 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+)
+
+func TestRegisterDiscovery(t *testing.T) {
+	id := component.MustNewIDWithName("discovery", "host")
+	endpoints := []DiscoveredEndpoint{{ID: "(host_observer)[::]-5432-TCP-1", Target: "[::]:5432", Receiver: "postgresql", Status: "successful"}}
+
+	unregister := RegisterDiscovery(id, func() []DiscoveredEndpoint { return endpoints })
+	assert.Equal(t, map[component.ID][]DiscoveredEndpoint{id: endpoints}, Discoveries())
+
+	// a restarted receiver replaces its registration, which the previous one mustn't remove
+	unregisterRestarted := RegisterDiscovery(id, func() []DiscoveredEndpoint { return nil })
+	unregister()
+	assert.Equal(t, map[component.ID][]DiscoveredEndpoint{id: nil}, Discoveries())
+	unregisterRestarted()
+	assert.Empty(t, Discoveries())
+}
@@ -0,0 +1,47 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+// authSenderAttributes are the authentication data attributes identifying the principal of a
+// request, as set by e.g. the basicauth and oidc extensions.
+var authSenderAttributes = []string{"username", "subject"}
+
+// Sender identifies the sender of the request by the header, if set on the request, else by
+// its authenticated principal, else by its remote IP.
+func Sender(r *http.Request, header string) string {
+	if header != "" {
+		if id := r.Header.Get(header); id != "" {
+			return id
+		}
+	}
+	if auth := client.FromContext(r.Context()).Auth; auth != nil {
+		for _, name := range authSenderAttributes {
+			if id, ok := auth.GetAttribute(name).(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
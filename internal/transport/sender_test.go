@@ -0,0 +1,59 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/client"
+)
+
+type testAuthData map[string]string
+
+func (d testAuthData) GetAttribute(name string) any {
+	if v, ok := d[name]; ok {
+		return v
+	}
+	return nil
+}
+
+func (d testAuthData) GetAttributeNames() []string {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestSender(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+	r.RemoteAddr = "10.0.0.1:4567"
+	assert.Equal(t, "10.0.0.1", Sender(r, "X-Sender"))
+
+	r = r.WithContext(client.NewContext(r.Context(), client.Info{Auth: testAuthData{"subject": "prometheus-a"}}))
+	assert.Equal(t, "prometheus-a", Sender(r, "X-Sender"))
+
+	r.Header.Set("X-Sender", "cluster-a")
+	assert.Equal(t, "cluster-a", Sender(r, "X-Sender"))
+	assert.Equal(t, "prometheus-a", Sender(r, ""))
+
+	// remote addresses without a port are used as is
+	r = httptest.NewRequest(http.MethodPost, "/metrics", nil)
+	r.RemoteAddr = "@"
+	assert.Equal(t, "@", Sender(r, ""))
+}
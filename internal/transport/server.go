@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Server serves the routes of a receiver with the Middleware. Requests are authenticated by the
+// auth extension, and their body size limited, as configured by its confighttp.ServerConfig.
+type Server struct {
+	middleware *Middleware
+	mux        *http.ServeMux
+	telemetry  component.TelemetrySettings
+	config     confighttp.ServerConfig
+	server     *http.Server
+	wg         sync.WaitGroup
+}
+
+// NewServer returns a Server without routes whose internal metrics are prefixed with prefix.
+func NewServer(set component.TelemetrySettings, config confighttp.ServerConfig, limits Limits, prefix string) (*Server, error) {
+	middleware, err := NewMiddleware(set, prefix, limits)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{middleware: middleware, mux: http.NewServeMux(), telemetry: set, config: config}, nil
+}
+
+// Handle serves the route with h. It must be called before Start.
+func (s *Server) Handle(route string, h http.Handler) {
+	s.mux.Handle(route, s.middleware.Wrap(route, h))
+}
+
+// Start listens on the endpoint of the server and serves its routes until Shutdown.
+func (s *Server) Start(ctx context.Context, host component.Host, opts ...confighttp.ToServerOption) error {
+	var listener net.Listener
+	var err error
+	if listener, err = s.config.ToListener(ctx); err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", s.config.Endpoint, err)
+	}
+	if s.server, err = s.config.ToServer(ctx, host, s.telemetry, s.mux, opts...); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if serveErr := s.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			componentstatus.ReportStatus(host, componentstatus.NewFatalErrorEvent(serveErr))
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops serving the routes.
+func (s *Server) Shutdown(context.Context) error {
+	var err error
+	if s.server != nil {
+		err = s.server.Close()
+	}
+	s.wg.Wait()
+	return err
+}
@@ -0,0 +1,183 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transport provides the HTTP middleware shared by the internal receivers serving
+// HTTP endpoints: panic recovery, request logging, consistent internal metrics, request limits
+// and sender identification.
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+const scopeName = "github.com/signalfx/splunk-otel-collector/internal/transport"
+
+// Limits are the request limits of a receiver's HTTP endpoints. The size of request bodies is
+// limited by the max_request_body_size of their confighttp.ServerConfig.
+type Limits struct {
+	// MaxConcurrentRequests is the number of requests served concurrently, after which requests
+	// are rejected with 429 Too Many Requests. Requests are unlimited if 0.
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"`
+}
+
+// Validate checks the limits are valid.
+func (l Limits) Validate() error {
+	if l.MaxConcurrentRequests < 0 {
+		return errors.New("max_concurrent_requests must not be negative")
+	}
+	return nil
+}
+
+// Middleware wraps the handlers of a receiver's HTTP endpoints, recovering from their panics,
+// enforcing its limits, logging the requests they serve and recording the
+// <prefix>_http_requests, <prefix>_http_request_duration and <prefix>_http_panics internal
+// metrics by route.
+type Middleware struct {
+	logger   *zap.Logger
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+	panics   metric.Int64Counter
+	// inFlight holds a token per request served if concurrent requests are limited.
+	inFlight chan struct{}
+}
+
+// NewMiddleware returns a Middleware whose internal metrics are prefixed with prefix,
+// e.g. prometheus_remote_write.
+func NewMiddleware(set component.TelemetrySettings, prefix string, limits Limits) (*Middleware, error) {
+	m := &Middleware{logger: set.Logger}
+	if limits.MaxConcurrentRequests > 0 {
+		m.inFlight = make(chan struct{}, limits.MaxConcurrentRequests)
+	}
+	meterProvider := set.MeterProvider
+	if set.LeveledMeterProvider != nil {
+		meterProvider = set.LeveledMeterProvider(configtelemetry.LevelBasic)
+	}
+	meter := meterProvider.Meter(scopeName)
+	var err error
+	if m.requests, err = meter.Int64Counter(
+		prefix+"_http_requests",
+		metric.WithDescription("Number of HTTP requests served per route and status code."),
+		metric.WithUnit("{requests}"),
+	); err != nil {
+		return nil, err
+	}
+	if m.duration, err = meter.Float64Histogram(
+		prefix+"_http_request_duration",
+		metric.WithDescription("Duration of serving HTTP requests per route and status code."),
+		metric.WithUnit("ms"),
+	); err != nil {
+		return nil, err
+	}
+	if m.panics, err = meter.Int64Counter(
+		prefix+"_http_panics",
+		metric.WithDescription("Number of HTTP requests whose handler panicked per route."),
+		metric.WithUnit("{requests}"),
+	); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Wrap returns a handler serving the route with h. Routes should be the registered path, not the
+// request path, to bound the cardinality of the internal metrics.
+func (m *Middleware) Wrap(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			if p := recover(); p != nil {
+				if errors.Is(asError(p), http.ErrAbortHandler) {
+					panic(p)
+				}
+				m.panics.Add(r.Context(), 1, metric.WithAttributes(attribute.String("route", route)))
+				m.logger.Error("HTTP handler panicked",
+					zap.String("route", route),
+					zap.Any("panic", p),
+					zap.ByteString("stack", debug.Stack()))
+				if rec.status == 0 {
+					http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			elapsed := time.Since(start)
+			attrs := metric.WithAttributes(
+				attribute.String("route", route),
+				attribute.String("status_code", strconv.Itoa(status)))
+			m.requests.Add(r.Context(), 1, attrs)
+			m.duration.Record(r.Context(), float64(elapsed)/float64(time.Millisecond), attrs)
+			m.logger.Debug("Served HTTP request",
+				zap.String("route", route),
+				zap.String("method", r.Method),
+				zap.String("remote_addr", r.RemoteAddr),
+				zap.Int("status_code", status),
+				zap.Duration("duration", elapsed))
+		}()
+		if m.inFlight != nil {
+			select {
+			case m.inFlight <- struct{}{}:
+				defer func() { <-m.inFlight }()
+			default:
+				http.Error(rec, "too many concurrent requests", http.StatusTooManyRequests)
+				return
+			}
+		}
+		h.ServeHTTP(rec, r)
+	})
+}
+
+func asError(p any) error {
+	if err, ok := p.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", p)
+}
+
+// statusRecorder records the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to access the wrapped ResponseWriter.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
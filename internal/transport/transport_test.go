@@ -0,0 +1,131 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMiddlewareRecoversPanics(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	set := componenttest.NewNopTelemetrySettings()
+	set.Logger = zap.New(core)
+	m, err := NewMiddleware(set, "test", Limits{})
+	require.NoError(t, err)
+
+	h := m.Wrap("/panic", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	require.Equal(t, 1, logs.FilterMessage("HTTP handler panicked").Len())
+	served := logs.FilterMessage("Served HTTP request").All()
+	require.Len(t, served, 1)
+	assert.Equal(t, int64(http.StatusInternalServerError), served[0].ContextMap()["status_code"])
+}
+
+func TestMiddlewareRecordsStatus(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	set := componenttest.NewNopTelemetrySettings()
+	set.Logger = zap.New(core)
+	m, err := NewMiddleware(set, "test", Limits{})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		handler http.HandlerFunc
+		name    string
+		status  int
+	}{
+		{
+			name:    "implicit",
+			handler: func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("ok")) },
+			status:  http.StatusOK,
+		},
+		{
+			name:    "explicit",
+			handler: func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusAccepted) },
+			status:  http.StatusAccepted,
+		},
+		{
+			name:    "error",
+			handler: func(w http.ResponseWriter, _ *http.Request) { http.Error(w, "bad", http.StatusBadRequest) },
+			status:  http.StatusBadRequest,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			m.Wrap("/metrics", tt.handler).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+			assert.Equal(t, tt.status, rec.Code)
+			served := logs.TakeAll()
+			require.Len(t, served, 1)
+			assert.Equal(t, int64(tt.status), served[0].ContextMap()["status_code"])
+			assert.Equal(t, "/metrics", served[0].ContextMap()["route"])
+		})
+	}
+}
+
+func TestMiddlewareRepanicsAbort(t *testing.T) {
+	m, err := NewMiddleware(componenttest.NewNopTelemetrySettings(), "test", Limits{})
+	require.NoError(t, err)
+	h := m.Wrap("/abort", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
+
+func TestMiddlewareLimitsConcurrentRequests(t *testing.T) {
+	m, err := NewMiddleware(componenttest.NewNopTelemetrySettings(), "test", Limits{MaxConcurrentRequests: 1})
+	require.NoError(t, err)
+
+	served, release := make(chan struct{}), make(chan struct{})
+	h := m.Wrap("/slow", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		served <- struct{}{}
+		<-release
+	}))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/slow", nil))
+	}()
+	<-served
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/slow", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	close(release)
+	<-done
+	go func() { <-served }()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/slow", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestLimitsValidate(t *testing.T) {
+	require.NoError(t, Limits{}.Validate())
+	require.EqualError(t, Limits{MaxConcurrentRequests: -1}.Validate(), "max_concurrent_requests must not be negative")
+}
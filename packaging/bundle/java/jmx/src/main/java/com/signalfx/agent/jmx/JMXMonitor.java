@@ -1,5 +1,6 @@
 package com.signalfx.agent.jmx;
 
+import java.io.IOException;
 import java.net.MalformedURLException;
 import java.util.Timer;
 import java.util.logging.Level;
@@ -21,6 +22,7 @@ public class JMXMonitor implements SignalFxMonitor<JMXMonitor.JMXConfig> {
     public static class JMXConfig extends MonitorConfig {
         public String serviceURL;
         public String groovyScript;
+        public String targetSystem;
         public String username;
         public String password;
         public String keyStorePath;
@@ -41,7 +43,7 @@ public class JMXMonitor implements SignalFxMonitor<JMXMonitor.JMXConfig> {
             throw new ConfigureError("Malformed serviceUrl: ", e);
         }
 
-        GroovyRunner runner = new GroovyRunner(conf.groovyScript, output, client);
+        GroovyRunner runner = new GroovyRunner(groovyScript(conf), output, client);
 
         timer.scheduleAtFixedRate(MonitorUtil.wrapTimerTask(() -> {
             try {
@@ -53,6 +55,23 @@ public class JMXMonitor implements SignalFxMonitor<JMXMonitor.JMXConfig> {
         }), 0, conf.intervalSeconds * 1000);
     }
 
+    // The script of a target system is one of the curated scripts bundled in the jar.
+    private String groovyScript(JMXConfig conf) {
+        if (StringUtils.isBlank(conf.targetSystem)) {
+            return conf.groovyScript;
+        }
+        String script;
+        try {
+            script = GroovyRunner.getResourceFileAsString("groovy/targets/" + conf.targetSystem + ".groovy");
+        } catch (IOException e) {
+            throw new ConfigureError("Could not load the script of targetSystem " + conf.targetSystem, e);
+        }
+        if (script == null) {
+            throw new ConfigureError("Unsupported targetSystem " + conf.targetSystem, null);
+        }
+        return script;
+    }
+
     private void setSystemProperties(JMXConfig conf) {
         if (StringUtils.isNotBlank(conf.keyStorePath)) {
             System.setProperty("javax.net.ssl.keyStore", conf.keyStorePath);
@@ -0,0 +1,18 @@
+// Sends the metrics of the queues of ActiveMQ Classic brokers.
+def dps = []
+util.queryJMX("org.apache.activemq:type=Broker,brokerName=*,destinationType=Queue,destinationName=*").each { queue ->
+    def dims = [
+        jms_provider: "activemq",
+        broker: queue.name().getKeyProperty("brokerName"),
+        queue: queue.name().getKeyProperty("destinationName"),
+    ]
+    dps += [
+        util.makeGauge("jms.queue.depth", queue.QueueSize, dims),
+        util.makeGauge("jms.queue.consumers", queue.ConsumerCount, dims),
+        util.makeGauge("jms.queue.messages.in_flight", queue.InFlightCount, dims),
+        util.makeCumulative("jms.queue.messages.enqueued", queue.EnqueueCount, dims),
+        util.makeCumulative("jms.queue.messages.dequeued", queue.DequeueCount, dims),
+        util.makeCumulative("jms.queue.messages.expired", queue.ExpiredCount, dims),
+    ]
+}
+output.sendDatapoints(dps)
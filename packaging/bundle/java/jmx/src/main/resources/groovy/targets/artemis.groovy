@@ -0,0 +1,26 @@
+// Sends the metrics of the queues of ActiveMQ Artemis brokers.
+import javax.management.ObjectName
+
+// Artemis quotes the values of the keys of its object names.
+def keyProperty(ObjectName name, String key) {
+    def value = name.getKeyProperty(key)
+    return value.startsWith('"') ? ObjectName.unquote(value) : value
+}
+
+def dps = []
+util.queryJMX("org.apache.activemq.artemis:broker=*,component=addresses,address=*,subcomponent=queues,routing-type=*,queue=*").each { queue ->
+    def dims = [
+        jms_provider: "artemis",
+        broker: keyProperty(queue.name(), "broker"),
+        queue: keyProperty(queue.name(), "queue"),
+    ]
+    dps += [
+        util.makeGauge("jms.queue.depth", queue.MessageCount, dims),
+        util.makeGauge("jms.queue.consumers", queue.ConsumerCount, dims),
+        util.makeGauge("jms.queue.messages.in_flight", queue.DeliveringCount, dims),
+        util.makeCumulative("jms.queue.messages.enqueued", queue.MessagesAdded, dims),
+        util.makeCumulative("jms.queue.messages.dequeued", queue.MessagesAcknowledged, dims),
+        util.makeCumulative("jms.queue.messages.expired", queue.MessagesExpired, dims),
+    ]
+}
+output.sendDatapoints(dps)
@@ -98,6 +98,9 @@ trap "docker rm -f $cid; rm -rf $tmpdir; rm -f $output_tar" EXIT
 
 docker export $cid | tar -C ${tmpdir}/${IMAGE_NAME} -xf -
 rm -rf ${tmpdir}/${IMAGE_NAME}/{proc,sys,dev,etc} ${tmpdir}/${IMAGE_NAME}/.dockerenv
+# checksums verified by the smartagent extension and "otelcol verify-bundle", excluding the
+# executables whose interpreter is patched on installation
+(cd ${tmpdir}/${IMAGE_NAME} && find . -type f ! -path './bin/*' ! -path './jre/bin/*' | LC_ALL=C sort | xargs -d '\n' sha256sum > MANIFEST.sha256)
 mkdir -p "$OUTPUT_DIR"
 (cd $tmpdir && tar -zcf ${OUTPUT_DIR}/${OUTPUT} *)
 
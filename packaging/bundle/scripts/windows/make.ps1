@@ -79,6 +79,14 @@ function bundle (
     # clean up empty directories
     remove_empty_directories -buildDir "$buildDir\$BUNDLE_DIR"
 
+    # checksums verified by the smartagent extension and "otelcol verify-bundle"
+    Push-Location "$buildDir\$BUNDLE_DIR"
+    Get-ChildItem -Recurse -File | Sort-Object FullName | ForEach-Object {
+        $path = "./" + (Resolve-Path -Relative $_.FullName).Substring(2).Replace("\", "/")
+        "$((Get-FileHash -Algorithm SHA256 $_.FullName).Hash.ToLower())  $path"
+    } | Set-Content -Encoding ascii "MANIFEST.sha256"
+    Pop-Location
+
     mkdir "$outputDir" -ErrorAction Ignore
     Remove-Item -Force "$outputDir\$BUNDLE_NAME" -ErrorAction Ignore
     zip_file -src "$buildDir\$BUNDLE_DIR" -dest "$outputDir\$BUNDLE_NAME"
@@ -78,13 +78,26 @@ function Confirm-MSI {
 
     $configpath = Resolve-Path "\ProgramData\Splunk\OpenTelemetry Collector\config.yaml"
     echo "Updating $configpath ..."
-    ((Get-Content -path "$configpath" -Raw) -Replace '\${SPLUNK_ACCESS_TOKEN}', 'testing123') | Set-Content -Path "$configpath"
-    ((Get-Content -path "$configpath" -Raw) -Replace '\${SPLUNK_API_URL}', 'https://api.us0.signalfx.com') | Set-Content -Path "$configpath"
-    ((Get-Content -path "$configpath" -Raw) -Replace '\${SPLUNK_HEC_URL}', 'https://ingest.us0.signalfx.com/v1/log') | Set-Content -Path "$configpath"
-    ((Get-Content -path "$configpath" -Raw) -Replace '\${SPLUNK_HEC_TOKEN}', 'testing456') | Set-Content -Path "$configpath"
-    ((Get-Content -path "$configpath" -Raw) -Replace '\${SPLUNK_INGEST_URL}', 'https://ingest.us0.signalfx.com') | Set-Content -Path "$configpath"
-    ((Get-Content -path "$configpath" -Raw) -Replace '\${SPLUNK_TRACE_URL}', 'https://ingest.us0.signalfx.com/v2/trace') | Set-Content -Path "$configpath"
-    ((Get-Content -path "$configpath" -Raw) -Replace '\${SPLUNK_BUNDLE_DIR}', 'C:\Program Files\Splunk\OpenTelemetry Collector\agent-bundle') | Set-Content -Path "$configpath"
+    $otelcol = "$env:ProgramFiles\Splunk\OpenTelemetry Collector\otelcol.exe"
+    $settings = [ordered]@{
+        "exporters::sapm::access_token" = "testing123"
+        "exporters::sapm::endpoint" = "https://ingest.us0.signalfx.com/v2/trace"
+        "exporters::signalfx::access_token" = "testing123"
+        "exporters::signalfx::api_url" = "https://api.us0.signalfx.com"
+        "exporters::signalfx::ingest_url" = "https://ingest.us0.signalfx.com"
+        "exporters::splunk_hec::token" = "testing456"
+        "exporters::splunk_hec::endpoint" = "https://ingest.us0.signalfx.com/v1/log"
+        "exporters::splunk_hec/profiling::token" = "testing123"
+        "exporters::splunk_hec/profiling::endpoint" = "https://ingest.us0.signalfx.com/v1/log"
+        "exporters::otlphttp/entities::logs_endpoint" = "https://ingest.us0.signalfx.com/v3/event"
+        "exporters::otlphttp/entities::headers::X-SF-Token" = "testing123"
+        "extensions::http_forwarder::egress::endpoint" = "https://api.us0.signalfx.com"
+        "extensions::smartagent::bundleDir" = "C:\Program Files\Splunk\OpenTelemetry Collector\agent-bundle"
+    }
+    foreach ($setting in $settings.GetEnumerator()) {
+        & "$otelcol" config set --config "$configpath" $setting.Key $setting.Value
+        if ($LASTEXITCODE -ne 0) { Throw "failed to set $($setting.Key) in $configpath" }
+    }
 
     # start service
     echo "Starting service ..."
@@ -0,0 +1,100 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentextension
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BundleManifest is the file of the agent bundle listing the sha256 checksums of its files
+// in the "sha256sum" format, i.e. "<checksum>  <path relative to the bundle dir>" lines.
+const BundleManifest = "MANIFEST.sha256"
+
+// maxReportedBundleErrors limits the files reported by VerifyBundle so a missing
+// directory doesn't flood the logs.
+const maxReportedBundleErrors = 10
+
+// ErrNoBundleManifest is returned by VerifyBundle for bundles without a manifest,
+// e.g. those built before manifests were introduced.
+var ErrNoBundleManifest = errors.New("agent bundle has no " + BundleManifest + " manifest")
+
+// VerifyBundle validates the files of the agent bundle against the checksums of its manifest,
+// returning an error listing the missing and modified files.
+func VerifyBundle(bundleDir string) error {
+	manifest, err := os.Open(filepath.Join(bundleDir, BundleManifest))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoBundleManifest
+		}
+		return fmt.Errorf("failed to open agent bundle manifest: %w", err)
+	}
+	defer manifest.Close()
+
+	var errs []error
+	var failed int
+	scanner := bufio.NewScanner(manifest)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		checksum, path, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			return fmt.Errorf("invalid agent bundle manifest line %d: %q", line, scanner.Text())
+		}
+		if err = verifyFile(filepath.Join(bundleDir, filepath.FromSlash(strings.TrimPrefix(path, "./"))), checksum); err != nil {
+			if failed++; failed <= maxReportedBundleErrors {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read agent bundle manifest: %w", err)
+	}
+	if failed > maxReportedBundleErrors {
+		errs = append(errs, fmt.Errorf("and %d more files", failed-maxReportedBundleErrors))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("agent bundle %q is corrupted, reinstall it: %w", bundleDir, errors.Join(errs...))
+	}
+	return nil
+}
+
+func verifyFile(path, checksum string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return errors.New("missing")
+		}
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, checksum) {
+		return fmt.Errorf("checksum mismatch, expected %s got %s", checksum, actual)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentextension
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestBundle(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	var manifest strings.Builder
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		sum := sha256.Sum256([]byte(content))
+		manifest.WriteString(fmt.Sprintf("%s  ./%s\n", hex.EncodeToString(sum[:]), name))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, BundleManifest), []byte(manifest.String()), 0o600))
+	return dir
+}
+
+func TestVerifyBundle(t *testing.T) {
+	dir := writeTestBundle(t, map[string]string{
+		"types.db":                         "types",
+		"collectd-python/plugin/plugin.py": "import collectd",
+		"lib/libcollectd.so":               "elf",
+	})
+	require.NoError(t, VerifyBundle(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.db"), []byte("typ"), 0o600))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "collectd-python")))
+	err := VerifyBundle(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is corrupted")
+	assert.Contains(t, err.Error(), "./types.db: checksum mismatch")
+	assert.Contains(t, err.Error(), "./collectd-python/plugin/plugin.py: missing")
+	assert.NotContains(t, err.Error(), "libcollectd.so")
+}
+
+func TestVerifyBundleLimitsReportedFiles(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < maxReportedBundleErrors+5; i++ {
+		files[fmt.Sprintf("lib/file%d", i)] = "content"
+	}
+	dir := writeTestBundle(t, files)
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "lib")))
+	assert.ErrorContains(t, VerifyBundle(dir), "and 5 more files")
+}
+
+func TestVerifyBundleWithoutManifest(t *testing.T) {
+	assert.ErrorIs(t, VerifyBundle(t.TempDir()), ErrNoBundleManifest)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, BundleManifest), []byte("invalid\n"), 0o600))
+	assert.EqualError(t, VerifyBundle(dir), `invalid agent bundle manifest line 1: "invalid"`)
+}
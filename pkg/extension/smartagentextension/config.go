@@ -24,17 +24,30 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+const verifyBundleKey = "verifyBundle"
+
 var _ confmap.Unmarshaler = (*Config)(nil)
 
 type Config struct {
 	// Agent uses yaml, which mapstructure doesn't support.
 	// Custom unmarshaller required for yaml and SFx defaults usage.
 	saconfig.Config `mapstructure:"-,squash"`
+	// VerifyBundle validates the files of the agent bundle against its manifest on start,
+	// failing if any of them is missing or modified. Defaults to true.
+	VerifyBundle bool `mapstructure:"verifyBundle"`
 }
 
 func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 	allSettings := componentParser.ToStringMap()
 
+	if verify, ok := allSettings[verifyBundleKey]; ok {
+		if cfg.VerifyBundle, ok = verify.(bool); !ok {
+			return fmt.Errorf("%s must be a boolean, got %v", verifyBundleKey, verify)
+		}
+		// not a Smart Agent config field
+		delete(allSettings, verifyBundleKey)
+	}
+
 	configDirSet := false
 	if collectd, ok := allSettings["collectd"]; ok {
 		if collectdBlock, ok := collectd.(map[string]any); ok {
@@ -71,8 +71,10 @@ func TestLoadConfig(t *testing.T) {
 		c.Collectd.WriteServerIPAddr = "10.100.12.1"
 		c.Collectd.WriteServerPort = 9090
 		c.Collectd.ConfigDir = "/etc/"
+		c.Collectd.InstancePerMonitorType = true
 		c.Collectd.BundleDir = "/opt/bin/collectd/"
 		c.Collectd.HasGenericJMXMonitor = false
+		c.VerifyBundle = false
 		return &c
 	}(), allSettingsConfig)
 
@@ -120,18 +122,19 @@ func TestSmartAgentConfigProvider(t *testing.T) {
 
 	require.Equal(t, func() saconfig.CollectdConfig {
 		return saconfig.CollectdConfig{
-			Timeout:              40,
-			LogLevel:             "notice",
-			ReadThreads:          1,
-			WriteThreads:         4,
-			WriteQueueLimitHigh:  5,
-			WriteQueueLimitLow:   1,
-			IntervalSeconds:      5,
-			WriteServerIPAddr:    "10.100.12.1",
-			WriteServerPort:      9090,
-			BundleDir:            "/opt/bin/collectd/",
-			ConfigDir:            "/etc/",
-			HasGenericJMXMonitor: false,
+			Timeout:                40,
+			LogLevel:               "notice",
+			ReadThreads:            1,
+			WriteThreads:           4,
+			WriteQueueLimitHigh:    5,
+			WriteQueueLimitLow:     1,
+			IntervalSeconds:        5,
+			WriteServerIPAddr:      "10.100.12.1",
+			WriteServerPort:        9090,
+			BundleDir:              "/opt/bin/collectd/",
+			ConfigDir:              "/etc/",
+			HasGenericJMXMonitor:   false,
+			InstancePerMonitorType: true,
 		}
 	}(), saConfigProvider.SmartAgentConfig().Collectd)
 	require.Equal(t, "/opt/bin/collectd/", saConfigProvider.SmartAgentConfig().BundleDir)
@@ -149,6 +152,7 @@ func TestLoadInvalidConfig(t *testing.T) {
 
 func defaultConfig() Config {
 	return Config{
+		VerifyBundle: true,
 		Config: saconfig.Config{
 			BundleDir: bundleDir,
 			ProcPath:  "/proc",
@@ -16,10 +16,13 @@ package smartagentextension
 
 import (
 	"context"
+	"errors"
+	"os"
 
 	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
 )
 
 // SmartAgentConfigProvider exposes global saconfig.Config to other components
@@ -28,13 +31,27 @@ type SmartAgentConfigProvider interface {
 }
 
 type smartAgentConfigExtension struct {
-	saCfg *saconfig.Config
+	saCfg        *saconfig.Config
+	logger       *zap.Logger
+	verifyBundle bool
 }
 
 var _ SmartAgentConfigProvider = (*smartAgentConfigExtension)(nil)
 
 func (sae *smartAgentConfigExtension) Start(_ context.Context, _ component.Host) error {
-	return nil
+	if !sae.verifyBundle {
+		return nil
+	}
+	if _, err := os.Stat(sae.saCfg.BundleDir); err != nil {
+		// not all installations include the agent bundle
+		return nil
+	}
+	err := VerifyBundle(sae.saCfg.BundleDir)
+	if errors.Is(err, ErrNoBundleManifest) {
+		sae.logger.Warn("Skipping agent bundle verification", zap.String("bundleDir", sae.saCfg.BundleDir), zap.Error(err))
+		return nil
+	}
+	return err
 }
 
 func (sae *smartAgentConfigExtension) Shutdown(_ context.Context) error {
@@ -45,6 +62,9 @@ func (sae *smartAgentConfigExtension) SmartAgentConfig() *saconfig.Config {
 	return sae.saCfg
 }
 
-func newSmartAgentConfigExtension(cfg *Config) (extension.Extension, error) {
-	return &smartAgentConfigExtension{saCfg: &cfg.Config}, nil
+func newSmartAgentConfigExtension(cfg *Config, logger *zap.Logger) (extension.Extension, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &smartAgentConfigExtension{saCfg: &cfg.Config, logger: logger, verifyBundle: cfg.VerifyBundle}, nil
 }
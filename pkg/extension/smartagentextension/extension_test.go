@@ -16,6 +16,8 @@ package smartagentextension
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/signalfx/signalfx-agent/pkg/core/config"
@@ -51,3 +53,26 @@ func TestExtensionLifecycle(t *testing.T) {
 	require.NoError(t, sndExt.Start(ctx, componenttest.NewNopHost()))
 	require.NoError(t, sndExt.Shutdown(ctx))
 }
+
+func TestExtensionVerifiesBundle(t *testing.T) {
+	ctx := context.Background()
+	dir := writeTestBundle(t, map[string]string{"types.db": "types"})
+	cfg := &Config{
+		Config:       config.Config{BundleDir: dir},
+		VerifyBundle: true,
+	}
+
+	ext, err := NewFactory().Create(ctx, extension.Settings{}, cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(ctx, componenttest.NewNopHost()))
+	require.NoError(t, ext.Shutdown(ctx))
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "types.db")))
+	ext, err = NewFactory().Create(ctx, extension.Settings{}, cfg)
+	require.NoError(t, err)
+	require.ErrorContains(t, ext.Start(ctx, componenttest.NewNopHost()), "types.db: missing")
+
+	// bundles without manifest aren't verified
+	require.NoError(t, os.Remove(filepath.Join(dir, BundleManifest)))
+	require.NoError(t, ext.Start(ctx, componenttest.NewNopHost()))
+}
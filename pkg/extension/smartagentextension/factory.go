@@ -63,6 +63,12 @@ var bundleDir = func() string {
 	return dir
 }()
 
+// DefaultBundleDir returns the agent bundle directory used by default, from the
+// SPLUNK_BUNDLE_DIR environment variable or the installation directory.
+func DefaultBundleDir() string {
+	return bundleDir
+}
+
 func createDefaultConfig() component.Config {
 	cfg, _ := smartAgentConfigFromSettingsMap(map[string]any{})
 	if cfg == nil {
@@ -75,14 +81,15 @@ func createDefaultConfig() component.Config {
 	cfg.Collectd.ConfigDir = filepath.Join(bundleDir, "run", "collectd")
 
 	return &Config{
-		Config: *cfg,
+		Config:       *cfg,
+		VerifyBundle: true,
 	}
 }
 
 func createExtension(
 	_ context.Context,
-	_ extension.Settings,
+	set extension.Settings,
 	cfg component.Config,
 ) (extension.Extension, error) {
-	return newSmartAgentConfigExtension(cfg.(*Config))
+	return newSmartAgentConfigExtension(cfg.(*Config), set.Logger)
 }
@@ -53,6 +53,11 @@ type Config struct {
 	// Will expand to MonitorCustomConfig Host and Port values if unset.
 	Endpoint         string   `mapstructure:"endpoint"`
 	DimensionClients []string `mapstructure:"dimensionClients"`
+	// MetricsToExclude and MetricsToInclude are the Smart Agent's top level agent.yaml
+	// datapoint filters, applied to this receiver's monitor only. Entries scoped to another
+	// monitorType are ignored and any datapoint matched by MetricsToInclude is never excluded.
+	MetricsToExclude []saconfig.MetricFilter `mapstructure:"metricsToExclude"`
+	MetricsToInclude []saconfig.MetricFilter `mapstructure:"metricsToInclude"`
 	acceptsEndpoints bool
 }
 
@@ -71,6 +76,12 @@ func (cfg *Config) validate() error {
 	if err := validation.ValidateStruct(cfg.monitorConfig); err != nil {
 		return err
 	}
+	if _, err := newAgentMetricFilters(cfg.MetricsToExclude, monitorConfigCore.Type); err != nil {
+		return fmt.Errorf("invalid metricsToExclude: %w", err)
+	}
+	if _, err := newAgentMetricFilters(cfg.MetricsToInclude, monitorConfigCore.Type); err != nil {
+		return fmt.Errorf("invalid metricsToInclude: %w", err)
+	}
 	return validation.ValidateCustomConfig(cfg.monitorConfig)
 }
 
@@ -98,6 +109,18 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 		return err
 	}
 
+	for _, filters := range []struct {
+		dst *[]saconfig.MetricFilter
+		key string
+	}{
+		{key: "metricsToExclude", dst: &cfg.MetricsToExclude},
+		{key: "metricsToInclude", dst: &cfg.MetricsToInclude},
+	} {
+		if *filters.dst, err = getMetricFiltersFromAllSettings(allSettings, filters.key); err != nil {
+			return err
+		}
+	}
+
 	// monitors.ConfigTemplates is a map that all monitors use to register their custom configs in the Smart Agent.
 	// The values are always pointers to an actual custom config.
 	var customMonitorConfig saconfig.MonitorCustomConfig
@@ -160,6 +183,23 @@ func getStringSliceFromAllSettings(allSettings map[string]any, key string, errTo
 	return items, nil
 }
 
+func getMetricFiltersFromAllSettings(allSettings map[string]any, key string) ([]saconfig.MetricFilter, error) {
+	value, ok := allSettings[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, key)
+	asBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed constructing raw %s block: %w", key, err)
+	}
+	var filters []saconfig.MetricFilter
+	if err = yaml.UnmarshalStrict(asBytes, &filters); err != nil {
+		return nil, fmt.Errorf("%s must be an array of metric filters: %w", key, err)
+	}
+	return filters, nil
+}
+
 // If using the receivercreator, observer-provided endpoints should be used to set
 // the Host and Port fields of monitor config structs.  This can only be done by reflection without
 // making type assertions over all possible monitor types.
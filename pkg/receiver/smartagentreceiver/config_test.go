@@ -512,6 +512,40 @@ func TestInvalidFilteringConfig(t *testing.T) {
 	require.EqualError(t, err, "unexpected end of input")
 }
 
+func TestAgentFilteringConfig(t *testing.T) {
+	cfg, err := confmaptest.LoadConf(path.Join(".", "testdata", "agent_filtering_config.yaml"))
+	require.NoError(t, err)
+
+	cm, err := cfg.Sub(component.MustNewIDWithName(typeStr, "filesystems").String())
+	require.NoError(t, err)
+	fsCfg := CreateDefaultConfig().(*Config)
+	require.NoError(t, cm.Unmarshal(&fsCfg))
+	require.Equal(t, []saconfig.MetricFilter{
+		{
+			MetricNames: []string{"df_complex.*"},
+			Dimensions:  map[string]any{"mountpoint": "/hostfs"},
+		},
+		{
+			MetricName:  "cpu.utilization",
+			MonitorType: "cpu",
+		},
+	}, fsCfg.MetricsToExclude)
+	require.Equal(t, []saconfig.MetricFilter{{MetricName: "df_complex.free"}}, fsCfg.MetricsToInclude)
+	require.NoError(t, fsCfg.validate())
+
+	cm, err = cfg.Sub(component.MustNewIDWithName(typeStr, "invalid").String())
+	require.NoError(t, err)
+	invalidCfg := CreateDefaultConfig().(*Config)
+	err = cm.Unmarshal(&invalidCfg)
+	require.ErrorContains(t, err, "metricsToExclude must be an array of metric filters")
+
+	cm, err = cfg.Sub(component.MustNewIDWithName(typeStr, "invalid_pattern").String())
+	require.NoError(t, err)
+	invalidPatternCfg := CreateDefaultConfig().(*Config)
+	require.NoError(t, cm.Unmarshal(&invalidPatternCfg))
+	require.EqualError(t, invalidPatternCfg.validate(), "invalid metricsToInclude: unexpected end of input")
+}
+
 func TestLoadConfigWithNestedMonitorConfig(t *testing.T) {
 	cfg, err := confmaptest.LoadConf(path.Join(".", "testdata", "nested_monitor_config.yaml"))
 
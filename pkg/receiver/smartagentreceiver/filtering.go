@@ -50,6 +50,22 @@ func newMonitorFiltering(conf config.MonitorCustomConfig, metadata *monitors.Met
 	}, nil
 }
 
+// addAgentMetricFilters adds the top level agent.yaml-style metricsToExclude and metricsToInclude
+// filters to the monitor's filter set. Must be called before any datapoints are sent.
+func (mf *monitorFiltering) addAgentMetricFilters(excludes, includes []config.MetricFilter, monitorType string) error {
+	excludeFilters, err := newAgentMetricFilters(excludes, monitorType)
+	if err != nil {
+		return fmt.Errorf("invalid metricsToExclude: %w", err)
+	}
+	includeFilters, err := newAgentMetricFilters(includes, monitorType)
+	if err != nil {
+		return fmt.Errorf("invalid metricsToInclude: %w", err)
+	}
+	mf.filterSet.ExcludeFilters = append(mf.filterSet.ExcludeFilters, excludeFilters...)
+	mf.filterSet.IncludeFilters = append(mf.filterSet.IncludeFilters, includeFilters...)
+	return nil
+}
+
 // AddDatapointExclusionFilter to the monitor's filter set.  Make sure you do this
 // before any datapoints are sent as it is not thread-safe with SendDatapoint.
 func (mf *monitorFiltering) AddDatapointExclusionFilter(filter dpfilters.DatapointFilter) {
@@ -133,6 +149,33 @@ func buildFilterSet(metadata *monitors.Metadata, conf config.MonitorCustomConfig
 	return filterSet, nil
 }
 
+// newAgentMetricFilters creates datapoint filters from top level agent.yaml filter entries.
+// Unlike monitor datapointsToExclude entries these can be negated, and those scoped to a
+// monitorType other than the provided one are dropped since they would never match.
+func newAgentMetricFilters(filters []config.MetricFilter, monitorType string) ([]dpfilters.DatapointFilter, error) {
+	var datapointFilters []dpfilters.DatapointFilter
+	for _, f := range filters {
+		if f.MonitorType != "" && f.MonitorType != monitorType {
+			continue
+		}
+		metricFilter := f
+		metricFilter.MetricNames = append([]string{}, f.MetricNames...)
+		dimSet, err := metricFilter.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		dpf, err := dpfilters.NewOverridable(metricFilter.MetricNames, dimSet)
+		if err != nil {
+			return nil, err
+		}
+		if f.Negated {
+			dpf = dpfilters.Negate(dpf)
+		}
+		datapointFilters = append(datapointFilters, dpf)
+	}
+	return datapointFilters, nil
+}
+
 var _ dpfilters.DatapointFilter = &extraMetricsFilter{}
 
 // Filter of datapoints based on included status and user configuration of
@@ -262,3 +262,33 @@ func TestNewMonitorFilteringInvalid(t *testing.T) {
 		})
 	}
 }
+
+func TestAgentMetricFilters(t *testing.T) {
+	filtering, err := newMonitorFiltering(&config.MonitorConfig{Type: "test-monitor"}, nil, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, filtering.addAgentMetricFilters(
+		[]config.MetricFilter{
+			{MetricNames: []string{"excluded.*"}},
+			{MetricName: "kept", MonitorType: "another-monitor"},
+			{Dimensions: map[string]any{"env": "prod"}, Negated: true},
+		},
+		[]config.MetricFilter{
+			{MetricName: "excluded.but_included"},
+		},
+		"test-monitor",
+	))
+
+	prod := pcommon.NewMap()
+	prod.PutStr("env", "prod")
+	dev := pcommon.NewMap()
+	dev.PutStr("env", "dev")
+
+	require.True(t, filtering.filterSet.MatchesMetricDataPoint("excluded.metric", prod))
+	require.False(t, filtering.filterSet.MatchesMetricDataPoint("excluded.but_included", prod))
+	require.False(t, filtering.filterSet.MatchesMetricDataPoint("kept", prod))
+	// negated filter excludes everything not from prod
+	require.True(t, filtering.filterSet.MatchesMetricDataPoint("kept", dev))
+
+	err = filtering.addAgentMetricFilters([]config.MetricFilter{{MonitorType: "test-monitor"}}, nil, "test-monitor")
+	require.EqualError(t, err, "invalid metricsToExclude: metric filter must have at least one metric or dimension defined on it")
+}
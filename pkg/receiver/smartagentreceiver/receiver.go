@@ -171,6 +171,9 @@ func (r *receiver) createMonitor(monitorType string, host component.Host) (monit
 	if err != nil {
 		return nil, err
 	}
+	if err = monitorFiltering.addAgentMetricFilters(r.config.MetricsToExclude, r.config.MetricsToInclude, monitorType); err != nil {
+		return nil, err
+	}
 
 	output, err := newOutput(
 		*r.config, monitorFiltering, r.nextMetricsConsumer, r.nextLogsConsumer, r.nextTracesConsumer, host, r.params,